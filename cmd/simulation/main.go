@@ -39,6 +39,7 @@ func main() {
 		},
 		Trading: struct {
 			Symbol                string  `yaml:"symbol"`
+			MarketType            string  `yaml:"market_type"`
 			PriceInterval         float64 `yaml:"price_interval"`
 			OrderQuantity         float64 `yaml:"order_quantity"`
 			MinOrderValue         float64 `yaml:"min_order_value"`
@@ -50,6 +51,10 @@ func main() {
 			MarginLockDurationSec int     `yaml:"margin_lock_duration_seconds"`
 			PositionSafetyCheck   int     `yaml:"position_safety_check"`
 			MinMarginBalance      float64 `yaml:"min_margin_balance"`
+			Leverage              int     `yaml:"leverage"`
+			MarginType            string  `yaml:"margin_type"`
+			PositionMode          string  `yaml:"position_mode"`
+			PriceSource           string  `yaml:"price_source"`
 			DynamicGrid           struct {
 				Enabled       bool    `yaml:"enabled"`
 				ATRPeriod     int     `yaml:"atr_period"`
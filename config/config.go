@@ -21,17 +21,22 @@ type Config struct {
 
 	Trading struct {
 		Symbol                string  `yaml:"symbol"`
+		MarketType            string  `yaml:"market_type"` // 市场类型：futures（U本位永续合约，默认）/spot（现货，无杠杆）/inverse（币本位永续合约）
 		PriceInterval         float64 `yaml:"price_interval"`
-		OrderQuantity         float64 `yaml:"order_quantity"`  // 每单购买金额（USDT/USDC）
-		MinOrderValue         float64 `yaml:"min_order_value"` // 最小订单价值（USDT），默认6U，小于此值不挂单
+		OrderQuantity         float64 `yaml:"order_quantity"`  // 每单购买金额（USDT/USDC）；币本位合约为每单面值（USD），按合约面值换算为张数
+		MinOrderValue         float64 `yaml:"min_order_value"` // 最小订单价值（USDT），小于此值不挂单；0 表示使用交易所最小名义价值，且不会低于交易所要求
 		BuyWindowSize         int     `yaml:"buy_window_size"`
 		SellWindowSize        int     `yaml:"sell_window_size"` // 卖单窗口大小
 		ReconcileInterval     int     `yaml:"reconcile_interval"`
 		OrderCleanupThreshold int     `yaml:"order_cleanup_threshold"`      // 订单清理上限（默认100）
-		CleanupBatchSize      int     `yaml:"cleanup_batch_size"`           // 清理批次大小（默认10）
+		CleanupBatchSize      int     `yaml:"cleanup_batch_size"`           // 清理批次大小（0=按交易所批量撤单上限，不支持时为10）
 		MarginLockDurationSec int     `yaml:"margin_lock_duration_seconds"` // 保证金锁定时间（秒，默认10）
 		PositionSafetyCheck   int     `yaml:"position_safety_check"`        // 持仓安全性检查（默认100，最少能向下持有多少仓）
 		MinMarginBalance      float64 `yaml:"min_margin_balance"`           // 最小保证金余额（USDT），低于此值停止下买单，默认5U
+		Leverage              int     `yaml:"leverage"`                     // 启动时设置的杠杆倍数（0=不修改交易所设置）
+		MarginType            string  `yaml:"margin_type"`                  // 启动时设置的保证金模式：cross/isolated（空=不修改）
+		PositionMode          string  `yaml:"position_mode"`                // 启动时设置的持仓模式：one_way/hedge（空=不修改；hedge 时多空网格分别持仓）
+		PriceSource           string  `yaml:"price_source"`                 // 驱动挂单的价格来源：last（最新成交价，默认）/mid（买一卖一中间价）/bid_ask（买单锚定买一、卖单锚定卖一）
 		// 注意：price_decimals 和 quantity_decimals 已废弃，现在从交易所自动获取
 
 		// 动态网格配置
@@ -110,8 +115,12 @@ type Config struct {
 type ExchangeConfig struct {
 	APIKey     string  `yaml:"api_key"`
 	SecretKey  string  `yaml:"secret_key"`
-	Passphrase string  `yaml:"passphrase"` // Bitget 需要
+	Passphrase string  `yaml:"passphrase"` // Bitget、OKX 需要
 	FeeRate    float64 `yaml:"fee_rate"`   // 手续费率（例如 0.0002 表示 0.02%）
+	// WSOrderEntry 通过 WebSocket 下单（binance/bitget/gate 合约支持），连接不可用时自动改用 REST
+	WSOrderEntry bool `yaml:"ws_order_entry"`
+	// Environment 交易环境：mainnet（实盘，默认）/ testnet（测试网）/ demo（模拟盘），各交易所支持范围不同
+	Environment string `yaml:"environment"`
 }
 
 // LoadConfig 加载配置文件
@@ -185,7 +194,7 @@ func loadEnvFile(filename string) {
 
 // loadFromEnv 从环境变量加载敏感配置
 // 环境变量命名规则：{EXCHANGE}_API_KEY, {EXCHANGE}_SECRET_KEY, {EXCHANGE}_PASSPHRASE
-// 例如：BINANCE_API_KEY, BITGET_SECRET_KEY, GATE_API_KEY
+// 例如：BINANCE_API_KEY, BITGET_SECRET_KEY, GATE_API_KEY, OKX_PASSPHRASE
 func (c *Config) loadFromEnv() {
 	for name, exchangeCfg := range c.Exchanges {
 		// 转换为大写作为环境变量前缀
@@ -232,11 +241,30 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("交易所 %s 的 API 配置不完整", c.App.CurrentExchange)
 	}
 
+	// Bitget、OKX 的 API 还需要 Passphrase
+	switch c.App.CurrentExchange {
+	case "bitget", "okx":
+		if exchangeCfg.Passphrase == "" {
+			return fmt.Errorf("交易所 %s 需要配置 passphrase", c.App.CurrentExchange)
+		}
+	}
+
 	// 验证手续费率配置
 	if exchangeCfg.FeeRate < 0 {
 		return fmt.Errorf("交易所 %s 的手续费率不能为负数", c.App.CurrentExchange)
 	}
 
+	// 验证交易环境（具体交易所是否支持由适配器在创建时检查）
+	switch env := strings.ToLower(exchangeCfg.Environment); env {
+	case "":
+		exchangeCfg.Environment = "mainnet"
+	case "mainnet", "testnet", "demo":
+		exchangeCfg.Environment = env
+	default:
+		return fmt.Errorf("交易所 %s 的交易环境 %s 无效，可选值: mainnet/testnet/demo", c.App.CurrentExchange, exchangeCfg.Environment)
+	}
+	c.Exchanges[c.App.CurrentExchange] = exchangeCfg
+
 	if c.Trading.Symbol == "" {
 		return fmt.Errorf("交易对不能为空")
 	}
@@ -249,17 +277,64 @@ func (c *Config) Validate() error {
 	if c.Trading.SellWindowSize <= 0 {
 		c.Trading.SellWindowSize = c.Trading.BuyWindowSize // 默认与买单窗口相同
 	}
-	if c.Trading.CleanupBatchSize <= 0 {
-		c.Trading.CleanupBatchSize = 10 // 默认10
+	if c.Trading.CleanupBatchSize < 0 {
+		c.Trading.CleanupBatchSize = 0 // 0 表示由订单清理器按交易所批量撤单能力决定
 	}
 	// 注意：price_decimals 和 quantity_decimals 已从配置中移除，现在从交易所自动获取
-	if c.Trading.MinOrderValue <= 0 {
-		c.Trading.MinOrderValue = 20.0 // 默认20U
+	if c.Trading.MinOrderValue < 0 {
+		c.Trading.MinOrderValue = 0 // 0 表示使用交易所返回的最小名义价值
 	}
 	if c.Trading.MinMarginBalance <= 0 {
 		c.Trading.MinMarginBalance = 5.0 // 默认5U，低于此值停止下买单
 	}
 
+	// 验证市场类型
+	switch strings.ToLower(c.Trading.MarketType) {
+	case "", "futures", "swap":
+		c.Trading.MarketType = "futures"
+	case "inverse", "coin", "delivery":
+		c.Trading.MarketType = "inverse"
+	case "spot":
+		c.Trading.MarketType = "spot"
+		// 现货没有杠杆、保证金模式与持仓模式
+		if c.Trading.Leverage != 0 || c.Trading.MarginType != "" || c.Trading.PositionMode != "" {
+			return fmt.Errorf("现货模式不支持 leverage/margin_type/position_mode 配置，请留空")
+		}
+	default:
+		return fmt.Errorf("市场类型 %s 无效，可选值: futures/spot/inverse", c.Trading.MarketType)
+	}
+
+	// 验证账户设置（启动时由程序自动设置到交易所）
+	if c.Trading.Leverage < 0 {
+		return fmt.Errorf("杠杆倍数不能为负数")
+	}
+	switch strings.ToLower(c.Trading.MarginType) {
+	case "":
+	case "cross", "crossed":
+		c.Trading.MarginType = "cross"
+	case "isolated":
+		c.Trading.MarginType = "isolated"
+	default:
+		return fmt.Errorf("保证金模式 %s 无效，可选值: cross/isolated", c.Trading.MarginType)
+	}
+	switch strings.ToLower(c.Trading.PositionMode) {
+	case "":
+	case "one_way", "oneway":
+		c.Trading.PositionMode = "one_way"
+	case "hedge", "dual":
+		c.Trading.PositionMode = "hedge"
+	default:
+		return fmt.Errorf("持仓模式 %s 无效，可选值: one_way/hedge", c.Trading.PositionMode)
+	}
+	switch src := strings.ToLower(c.Trading.PriceSource); src {
+	case "":
+		c.Trading.PriceSource = "last"
+	case "last", "mid", "bid_ask":
+		c.Trading.PriceSource = src
+	default:
+		return fmt.Errorf("价格来源 %s 无效，可选值: last/mid/bid_ask", c.Trading.PriceSource)
+	}
+
 	// 动态网格配置默认值
 	if c.Trading.DynamicGrid.ATRPeriod <= 0 {
 		c.Trading.DynamicGrid.ATRPeriod = 14 // 默认14周期
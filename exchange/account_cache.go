@@ -0,0 +1,268 @@
+package exchange
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"opensqt/logger"
+)
+
+const (
+	// accountRESTTTL 不支持账户推送时，REST 查询结果的缓存时间
+	accountRESTTTL = 5 * time.Second
+	// accountResyncInterval 账户推送模式下定期用 REST 全量校准，修正推送丢失造成的偏差
+	accountResyncInterval = 5 * time.Minute
+)
+
+// AccountCache 本地账户视图（可用余额、基础资产可用余额与持仓）
+// 支持账户推送的交易所由推送实时更新，并定期用 REST 校准；
+// 不支持推送时退化为带短时缓存的 REST 查询，保证金检查与对账不再每次都请求交易所
+type AccountCache struct {
+	ex         IExchange
+	quoteAsset string
+	baseAsset  string
+
+	mu            sync.RWMutex
+	streaming     bool
+	available     float64
+	walletBalance float64
+	balanceTime   time.Time
+	freeBase      float64                // 基础资产可用余额（现货卖单的上限）
+	freeBaseTime  time.Time              // 基础资产余额更新时间，零值表示从未获取
+	freeBaseFresh bool                   // 推送模式下基础资产余额是否由推送维护
+	positions     map[string][]*Position // 规范化交易对 -> 持仓
+	loaded        map[string]bool        // 已从 REST 加载过持仓的交易对
+}
+
+// NewAccountCache 创建账户缓存，可用余额按交易所的保证金资产统计
+// （U本位合约与现货为计价资产，币本位合约为基础资产）
+func NewAccountCache(ex IExchange) *AccountCache {
+	marginAsset := ex.GetQuoteAsset()
+	if ex.Capabilities().Inverse {
+		marginAsset = ex.GetBaseAsset()
+	}
+	return &AccountCache{
+		ex:         ex,
+		quoteAsset: marginAsset,
+		baseAsset:  ex.GetBaseAsset(),
+		positions:  make(map[string][]*Position),
+		loaded:     make(map[string]bool),
+	}
+}
+
+// Start 加载账户快照并启动账户推送，交易所不支持推送时使用 REST 查询
+func (c *AccountCache) Start(ctx context.Context) error {
+	if err := c.refreshBalance(ctx); err != nil {
+		return err
+	}
+
+	if !c.ex.Capabilities().AccountStream {
+		logger.Info("ℹ️ [账户缓存] %s 不支持账户推送，使用 REST 查询（缓存 %v）", c.ex.GetName(), accountRESTTTL)
+		return nil
+	}
+
+	if err := c.ex.StartAccountStream(ctx, c.apply); err != nil {
+		logger.Warn("⚠️ [账户缓存] 启动账户推送失败，改用 REST 查询: %v", err)
+		return nil
+	}
+
+	c.mu.Lock()
+	c.streaming = true
+	available := c.available
+	c.mu.Unlock()
+
+	go c.resyncLoop(ctx)
+	logger.Info("✅ [账户缓存] 已启动账户推送，可用余额: %.2f %s", available, c.quoteAsset)
+	return nil
+}
+
+// AvailableBalance 获取计价资产的可用余额
+func (c *AccountCache) AvailableBalance(ctx context.Context) (float64, error) {
+	c.mu.RLock()
+	fresh := c.streaming || time.Since(c.balanceTime) < accountRESTTTL
+	available := c.available
+	c.mu.RUnlock()
+	if fresh {
+		return available, nil
+	}
+
+	if err := c.refreshBalance(ctx); err != nil {
+		return 0, err
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.available, nil
+}
+
+// FreeBaseBalance 获取基础资产的可用余额（现货模式下卖单的上限）
+// 推送模式下由推送维护，否则使用短时缓存的 REST 查询；
+// 查询失败时沿用上次获取的余额（同样缓存 accountRESTTTL，避免失败期间每次都请求交易所）
+func (c *AccountCache) FreeBaseBalance(ctx context.Context) (float64, error) {
+	c.mu.RLock()
+	fresh := (c.streaming && c.freeBaseFresh) || time.Since(c.freeBaseTime) < accountRESTTTL
+	freeBase := c.freeBase
+	c.mu.RUnlock()
+	if fresh {
+		return freeBase, nil
+	}
+
+	balance, err := c.ex.GetBalance(ctx, c.baseAsset)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err != nil {
+		if c.freeBaseTime.IsZero() {
+			return 0, err
+		}
+		logger.Warn("⚠️ [账户缓存] 查询 %s 可用余额失败，沿用上次余额 %.8f: %v", c.baseAsset, c.freeBase, err)
+		c.freeBaseTime = time.Now()
+		return c.freeBase, nil
+	}
+	c.freeBase = balance
+	c.freeBaseTime = time.Now()
+	c.freeBaseFresh = true
+	return balance, nil
+}
+
+// Positions 获取交易对的持仓（首次查询从 REST 加载，之后由推送更新）
+func (c *AccountCache) Positions(ctx context.Context, symbol string) ([]*Position, error) {
+	key := normalizeSymbol(symbol)
+
+	c.mu.RLock()
+	if c.streaming && c.loaded[key] {
+		positions := copyPositions(c.positions[key])
+		c.mu.RUnlock()
+		return positions, nil
+	}
+	c.mu.RUnlock()
+
+	positions, err := c.ex.GetPositions(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.positions[key] = nonZeroPositions(positions)
+	c.loaded[key] = true
+	c.mu.Unlock()
+	return positions, nil
+}
+
+// apply 合并账户推送
+func (c *AccountCache) apply(update *AccountUpdate) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, b := range update.Balances {
+		if strings.EqualFold(b.Asset, c.baseAsset) {
+			if b.HasAvailable {
+				c.freeBase = b.AvailableBalance
+				c.freeBaseTime = time.Now()
+			}
+			// 只推送钱包余额时无法得到可用余额，下次查询时从 REST 重新加载
+			c.freeBaseFresh = b.HasAvailable
+		}
+		if !strings.EqualFold(b.Asset, c.quoteAsset) {
+			continue
+		}
+		if b.HasAvailable {
+			c.available = b.AvailableBalance
+		} else {
+			// 只推送了钱包余额：按钱包余额的变化量（已实现盈亏、手续费、资金费、划转）调整可用余额
+			c.available += b.WalletBalance - c.walletBalance
+		}
+		c.walletBalance = b.WalletBalance
+		c.balanceTime = time.Now()
+	}
+
+	if update.PositionsSnapshot {
+		for key := range c.positions {
+			c.positions[key] = nil
+		}
+	}
+	changed := make(map[string][]*Position)
+	for _, pos := range update.Positions {
+		key := normalizeSymbol(pos.Symbol)
+		changed[key] = append(changed[key], pos)
+	}
+	for key, positions := range changed {
+		c.positions[key] = mergePositions(c.positions[key], positions)
+		c.loaded[key] = true
+	}
+}
+
+// mergePositions 用推送的持仓替换同方向的缓存持仓
+// 双向持仓模式下推送可能只包含发生变化的一侧，另一侧保留原值
+func mergePositions(cached, pushed []*Position) []*Position {
+	sides := make(map[PositionSide]bool, len(pushed))
+	for _, pos := range pushed {
+		sides[pos.Side] = true
+	}
+	merged := make([]*Position, 0, len(cached)+len(pushed))
+	for _, pos := range cached {
+		if !sides[pos.Side] {
+			merged = append(merged, pos)
+		}
+	}
+	return nonZeroPositions(append(merged, pushed...))
+}
+
+// resyncLoop 定期用 REST 校准余额与已加载的持仓
+func (c *AccountCache) resyncLoop(ctx context.Context) {
+	ticker := time.NewTicker(accountResyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.refreshBalance(ctx); err != nil {
+				logger.Warn("⚠️ [账户缓存] 校准余额失败: %v", err)
+			}
+
+			c.mu.Lock()
+			c.loaded = make(map[string]bool) // 下次查询持仓时重新从 REST 加载
+			c.freeBaseFresh = false
+			c.mu.Unlock()
+		}
+	}
+}
+
+// refreshBalance 通过 REST 刷新余额
+func (c *AccountCache) refreshBalance(ctx context.Context) error {
+	account, err := c.ex.GetAccount(ctx)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.available = account.AvailableBalance
+	c.walletBalance = account.TotalWalletBalance
+	c.balanceTime = time.Now()
+	c.mu.Unlock()
+	return nil
+}
+
+// nonZeroPositions 过滤已平仓（数量为0）的持仓
+func nonZeroPositions(positions []*Position) []*Position {
+	result := make([]*Position, 0, len(positions))
+	for _, pos := range positions {
+		if pos.Size != 0 {
+			result = append(result, pos)
+		}
+	}
+	return result
+}
+
+// copyPositions 复制持仓，避免调用方修改缓存
+func copyPositions(positions []*Position) []*Position {
+	result := make([]*Position, len(positions))
+	for i, pos := range positions {
+		p := *pos
+		result[i] = &p
+	}
+	return result
+}
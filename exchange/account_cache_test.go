@@ -0,0 +1,148 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// accountExchange 支持账户推送的交易所桩，记录 REST 查询次数
+type accountExchange struct {
+	IExchange
+	account       *Account
+	positions     []*Position
+	positionCalls int
+	callback      AccountUpdateCallback
+	baseBalance   float64
+	balanceErr    error
+	balanceCalls  int
+	noStream      bool
+}
+
+func (a *accountExchange) GetName() string       { return "Fake" }
+func (a *accountExchange) GetQuoteAsset() string { return "USDT" }
+func (a *accountExchange) GetBaseAsset() string  { return "ETH" }
+
+func (a *accountExchange) Capabilities() Capabilities {
+	return Capabilities{AccountStream: !a.noStream}
+}
+
+func (a *accountExchange) GetBalance(ctx context.Context, asset string) (float64, error) {
+	a.balanceCalls++
+	return a.baseBalance, a.balanceErr
+}
+
+func (a *accountExchange) GetAccount(ctx context.Context) (*Account, error) {
+	return a.account, nil
+}
+
+func (a *accountExchange) GetPositions(ctx context.Context, symbol string) ([]*Position, error) {
+	a.positionCalls++
+	return a.positions, nil
+}
+
+func (a *accountExchange) StartAccountStream(ctx context.Context, callback AccountUpdateCallback) error {
+	a.callback = callback
+	return nil
+}
+
+func TestAccountCacheAppliesPushUpdates(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ex := &accountExchange{
+		account:   &Account{TotalWalletBalance: 1000, AvailableBalance: 800},
+		positions: []*Position{{Symbol: "ETHUSDT", Size: 0.5}},
+	}
+	cache := NewAccountCache(ex)
+	if err := cache.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// 只推送钱包余额时按变化量调整可用余额（手续费 -2）
+	ex.callback(&AccountUpdate{Balances: []BalanceUpdate{{Asset: "USDT", WalletBalance: 998}}})
+	if available, _ := cache.AvailableBalance(ctx); available != 798 {
+		t.Errorf("可用余额 = %v, want 798", available)
+	}
+	ex.callback(&AccountUpdate{Balances: []BalanceUpdate{{Asset: "USDT", WalletBalance: 998, AvailableBalance: 750, HasAvailable: true}}})
+	if available, _ := cache.AvailableBalance(ctx); available != 750 {
+		t.Errorf("可用余额 = %v, want 750", available)
+	}
+
+	// 首次查询持仓从 REST 加载，之后使用推送结果
+	if positions, _ := cache.Positions(ctx, "ETH_USDT"); len(positions) != 1 || positions[0].Size != 0.5 {
+		t.Fatalf("REST 持仓加载错误: %+v", positions)
+	}
+	ex.callback(&AccountUpdate{Positions: []*Position{{Symbol: "ETHUSDT", Size: 0.8}}})
+	if positions, _ := cache.Positions(ctx, "ETHUSDT"); len(positions) != 1 || positions[0].Size != 0.8 {
+		t.Errorf("推送后持仓应为 0.8: %+v", positions)
+	}
+	if ex.positionCalls != 1 {
+		t.Errorf("REST 持仓查询次数 = %d, want 1", ex.positionCalls)
+	}
+
+	// 快照中未出现的交易对视为已平仓
+	ex.callback(&AccountUpdate{PositionsSnapshot: true})
+	if positions, _ := cache.Positions(ctx, "ETHUSDT"); len(positions) != 0 {
+		t.Errorf("快照后应无持仓: %+v", positions)
+	}
+
+	// 双向持仓：只推送空头一侧时保留多头持仓
+	ex.callback(&AccountUpdate{Positions: []*Position{
+		{Symbol: "ETHUSDT", Size: 0.8, Side: PositionSideLong},
+		{Symbol: "ETHUSDT", Size: -0.3, Side: PositionSideShort},
+	}})
+	ex.callback(&AccountUpdate{Positions: []*Position{{Symbol: "ETHUSDT", Size: -0.5, Side: PositionSideShort}}})
+	if positions, _ := cache.Positions(ctx, "ETHUSDT"); len(positions) != 2 || positions[0].Size != 0.8 || positions[1].Size != -0.5 {
+		t.Errorf("双向持仓合并错误: %+v", positions)
+	}
+}
+
+func TestAccountCacheFreeBaseBalance(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// 推送模式：首次从 REST 加载，之后由推送维护
+	ex := &accountExchange{account: &Account{}, baseBalance: 2}
+	cache := NewAccountCache(ex)
+	if err := cache.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if free, err := cache.FreeBaseBalance(ctx); err != nil || free != 2 {
+			t.Fatalf("基础资产余额 = %v, %v, want 2", free, err)
+		}
+	}
+	ex.callback(&AccountUpdate{Balances: []BalanceUpdate{{Asset: "ETH", WalletBalance: 2, AvailableBalance: 1.5, HasAvailable: true}}})
+	if free, _ := cache.FreeBaseBalance(ctx); free != 1.5 {
+		t.Errorf("推送后基础资产余额 = %v, want 1.5", free)
+	}
+	if ex.balanceCalls != 1 {
+		t.Errorf("REST 余额查询次数 = %d, want 1", ex.balanceCalls)
+	}
+
+	// REST 模式：缓存过期后查询失败时沿用上次余额
+	ex = &accountExchange{account: &Account{}, baseBalance: 3, noStream: true}
+	cache = NewAccountCache(ex)
+	if err := cache.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if free, _ := cache.FreeBaseBalance(ctx); free != 3 {
+		t.Fatalf("基础资产余额 = %v, want 3", free)
+	}
+	ex.balanceErr = errors.New("timeout")
+	cache.freeBaseTime = cache.freeBaseTime.Add(-accountRESTTTL)
+	if free, err := cache.FreeBaseBalance(ctx); err != nil || free != 3 {
+		t.Errorf("查询失败时应沿用上次余额: %v, %v", free, err)
+	}
+	if free, _ := cache.FreeBaseBalance(ctx); free != 3 || ex.balanceCalls != 2 {
+		t.Errorf("查询失败后也应缓存，不应每次都请求交易所: calls=%d", ex.balanceCalls)
+	}
+
+	// 从未获取成功时返回错误
+	ex = &accountExchange{account: &Account{}, balanceErr: errors.New("timeout"), noStream: true}
+	cache = NewAccountCache(ex)
+	if _, err := cache.FreeBaseBalance(ctx); err == nil {
+		t.Error("从未获取到余额时应返回错误")
+	}
+}
@@ -0,0 +1,55 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"opensqt/logger"
+)
+
+// ErrAmendReplaceFailed 撤单重下改单时原订单已撤销但重新下单失败，该挂单已不存在，
+// 调用方应释放或重建对应槽位
+var ErrAmendReplaceFailed = errors.New("撤单重下改单失败，原订单已撤销")
+
+// amendByCancelReplace 撤单重下方式改单（交易所不支持原生改单时使用）
+// req 为原订单的下单请求，重下的订单沿用其全部属性（ReduceOnly/PositionSide/PostOnly/ClientOrderID 等），
+// 仅替换价格与数量；newQty <= 0 时使用原订单未成交数量。重下的订单由交易所分配新订单ID。
+// 原订单撤销成功但重新下单失败时返回 ErrAmendReplaceFailed
+func amendByCancelReplace(ctx context.Context, ex IExchange, req *OrderRequest, orderID int64, newPrice, newQty float64) (*Order, error) {
+	original, err := ex.GetOrder(ctx, req.Symbol, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("查询原订单失败: %w", err)
+	}
+
+	if newQty <= 0 {
+		newQty = original.Quantity - original.ExecutedQty
+	}
+	if newQty <= 0 {
+		return nil, fmt.Errorf("订单 %d 已无未成交数量，无法改单", orderID)
+	}
+
+	if err := ex.CancelOrder(ctx, req.Symbol, orderID); err != nil {
+		return nil, fmt.Errorf("撤销原订单失败: %w", err)
+	}
+
+	replace := *req
+	replace.Side = original.Side
+	replace.Price = newPrice
+	replace.Quantity = newQty
+	if replace.Type == "" {
+		replace.Type = OrderTypeLimit
+	}
+	if replace.TimeInForce == "" {
+		replace.TimeInForce = TimeInForceGTC
+	}
+
+	order, err := ex.PlaceOrder(ctx, &replace)
+	if err != nil {
+		return nil, fmt.Errorf("%w: 订单 %d: %w", ErrAmendReplaceFailed, orderID, err)
+	}
+
+	logger.Info("✅ [%s] 撤单重下改单: %d -> %d, 价格 %.8f -> %.8f",
+		ex.GetName(), orderID, order.OrderID, original.Price, newPrice)
+	return order, nil
+}
@@ -0,0 +1,111 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// replaceExchange 记录撤单与下单调用的交易所桩，未实现的方法由内嵌接口兜底（调用即 panic）
+type replaceExchange struct {
+	IExchange
+	original *Order
+	canceled []int64
+	placed   []*OrderRequest
+	placeErr error
+}
+
+func (r *replaceExchange) GetName() string { return "Fake" }
+
+func (r *replaceExchange) GetOrder(ctx context.Context, symbol string, orderID int64) (*Order, error) {
+	return r.original, nil
+}
+
+func (r *replaceExchange) CancelOrder(ctx context.Context, symbol string, orderID int64) error {
+	r.canceled = append(r.canceled, orderID)
+	return nil
+}
+
+func (r *replaceExchange) PlaceOrder(ctx context.Context, req *OrderRequest) (*Order, error) {
+	r.placed = append(r.placed, req)
+	if r.placeErr != nil {
+		return nil, r.placeErr
+	}
+	return &Order{OrderID: 2, Symbol: req.Symbol, Side: req.Side, Price: req.Price, Quantity: req.Quantity, Status: OrderStatusNew}, nil
+}
+
+func TestAmendByCancelReplace(t *testing.T) {
+	ex := &replaceExchange{original: &Order{OrderID: 1, Side: SideSell, Price: 100, Quantity: 1, ExecutedQty: 0.4}}
+
+	order, err := amendByCancelReplace(context.Background(), ex, &OrderRequest{Symbol: "BTCUSDT", Side: SideSell}, 1, 101, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ex.canceled) != 1 || ex.canceled[0] != 1 {
+		t.Errorf("应先撤销原订单, 实际撤单 %v", ex.canceled)
+	}
+	if len(ex.placed) != 1 {
+		t.Fatalf("应重新下单一次, 实际 %d 次", len(ex.placed))
+	}
+	req := ex.placed[0]
+	if req.Side != SideSell || req.Price != 101 || req.Quantity < 0.6-1e-9 || req.Quantity > 0.6+1e-9 {
+		t.Errorf("重下订单参数错误: %+v（未指定数量时应使用未成交数量 0.6）", req)
+	}
+	if order.OrderID != 2 {
+		t.Errorf("应返回新订单ID 2, 实际 %d", order.OrderID)
+	}
+}
+
+// 双向持仓的只减仓止盈单撤单重下后应保留全部属性，否则会被拒绝或变成开仓单
+func TestAmendByCancelReplaceKeepsOrderAttributes(t *testing.T) {
+	ex := &replaceExchange{original: &Order{OrderID: 1, Side: SideBuy, Price: 100, Quantity: 0.5}}
+	original := &OrderRequest{
+		Symbol:        "ETHUSDT",
+		Side:          SideBuy,
+		Type:          OrderTypeLimit,
+		TimeInForce:   TimeInForceGTC,
+		Price:         100,
+		Quantity:      0.5,
+		PriceDecimals: 2,
+		ReduceOnly:    true,
+		PositionSide:  PositionSideShort,
+		PostOnly:      true,
+		ClientOrderID: "grid_100_1",
+	}
+
+	if _, err := amendByCancelReplace(context.Background(), ex, original, 1, 99.5, 0); err != nil {
+		t.Fatal(err)
+	}
+	if len(ex.placed) != 1 {
+		t.Fatalf("应重新下单一次, 实际 %d 次", len(ex.placed))
+	}
+	req := ex.placed[0]
+	if !req.ReduceOnly || req.PositionSide != PositionSideShort || !req.PostOnly || req.ClientOrderID != "grid_100_1" || req.PriceDecimals != 2 {
+		t.Errorf("重下订单丢失原订单属性: %+v", req)
+	}
+	if req.Price != 99.5 || req.Quantity != 0.5 {
+		t.Errorf("重下订单价格/数量错误: %+v", req)
+	}
+	if original.Price != 100 {
+		t.Errorf("不应修改调用方的原订单请求: %+v", original)
+	}
+}
+
+func TestAmendByCancelReplaceReplaceFailed(t *testing.T) {
+	placeErr := errors.New("rejected")
+	ex := &replaceExchange{
+		original: &Order{OrderID: 1, Side: SideSell, Price: 100, Quantity: 1},
+		placeErr: placeErr,
+	}
+
+	order, err := amendByCancelReplace(context.Background(), ex, &OrderRequest{Symbol: "BTCUSDT", Side: SideSell}, 1, 101, 0)
+	if order != nil {
+		t.Errorf("重新下单失败时不应返回订单: %+v", order)
+	}
+	if !errors.Is(err, ErrAmendReplaceFailed) || !errors.Is(err, placeErr) {
+		t.Errorf("应返回 ErrAmendReplaceFailed 并保留原始错误, 实际 %v", err)
+	}
+	if len(ex.canceled) != 1 {
+		t.Errorf("原订单应已撤销, 实际撤单 %v", ex.canceled)
+	}
+}
@@ -2,14 +2,23 @@ package binance
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"opensqt/exchange/clock"
+	"opensqt/exchange/errs"
+	"opensqt/exchange/orderbook"
+	"opensqt/exchange/wsapi"
 	"opensqt/logger"
 	"opensqt/utils"
 
+	gobinance "github.com/adshao/go-binance/v2"
+	"github.com/adshao/go-binance/v2/delivery"
 	"github.com/adshao/go-binance/v2/futures"
 )
 
@@ -51,7 +60,8 @@ type OrderRequest struct {
 	Quantity      float64
 	Price         float64
 	ReduceOnly    bool
-	PostOnly      bool // 是否只做 Maker（使用 GTX）
+	PositionSide  string // 持仓方向 LONG/SHORT（双向持仓模式），空表示单向持仓
+	PostOnly      bool   // 是否只做 Maker（使用 GTX）
 	PriceDecimals int
 	ClientOrderID string // 自定义订单ID
 }
@@ -80,6 +90,7 @@ type Position struct {
 	Leverage       int
 	MarginType     string
 	IsolatedMargin float64
+	Side           string // 持仓方向 LONG/SHORT（双向持仓模式），单向持仓为空
 }
 
 type Account struct {
@@ -89,6 +100,22 @@ type Account struct {
 	Positions          []*Position
 }
 
+// SymbolInfo 合约交易规则（数量口径为币数量，币本位合约为张数）
+type SymbolInfo struct {
+	Symbol             string
+	BaseAsset          string
+	QuoteAsset         string
+	TickSize           float64 // 价格最小变动单位
+	StepSize           float64 // 数量最小变动单位
+	MinQty             float64 // 最小下单数量
+	MinNotional        float64 // 最小名义价值，0 表示无限制
+	ContractMultiplier float64 // 合约乘数（每张合约对应的币数量）
+	ContractValue      float64 // 币本位合约面值（每张合约对应的 USD 金额），U本位与现货为 0
+	MaxLeverage        int     // 最大杠杆倍数，0 表示未知
+	PriceDecimals      int
+	QuantityDecimals   int
+}
+
 type OrderUpdate struct {
 	OrderID       int64
 	ClientOrderID string
@@ -101,24 +128,129 @@ type OrderUpdate struct {
 	ExecutedQty   float64
 	AvgPrice      float64
 	UpdateTime    int64
+	Fill          *FillEvent // 本次成交明细，无新成交时为 nil
+}
+
+// FillEvent 单笔成交明细
+type FillEvent struct {
+	TradeID         string
+	Price           float64
+	Quantity        float64
+	Commission      float64 // 正数为支出，负数为返佣
+	CommissionAsset string
+	IsMaker         bool
+	Time            int64
 }
 
 type OrderUpdateCallback func(update OrderUpdate)
 
-// BinanceAdapter 币安交易所适配器
-type BinanceAdapter struct {
-	client           *futures.Client
-	symbol           string
-	wsManager        *WebSocketManager
-	klineWSManager   *KlineWebSocketManager
+// BalanceUpdate 资产余额变化
+type BalanceUpdate struct {
+	Asset            string
+	WalletBalance    float64
+	AvailableBalance float64
+	HasAvailable     bool // 交易所是否推送了可用余额
+}
+
+// AccountUpdate 账户推送（余额与持仓变化）
+type AccountUpdate struct {
+	Balances          []BalanceUpdate
+	Positions         []*Position
+	PositionsSnapshot bool // Positions 是否为全部持仓快照
+	UpdateTime        int64
+}
+
+type AccountUpdateCallback func(update *AccountUpdate)
+
+// FundingRate 资金费率与标记价格
+type FundingRate struct {
+	Symbol          string
+	FundingRate     float64
+	MarkPrice       float64
+	IndexPrice      float64
+	NextFundingTime int64
+	Time            int64
+}
+
+type MarkPriceCallback func(update *FundingRate)
+
+// FundingPayment 资金费收付记录
+type FundingPayment struct {
+	ID     string
+	Symbol string
+	Amount float64 // 正数为收入，负数为支出
+	Asset  string
+	Time   int64
+}
+
+// Trade 账户成交记录（成交历史不返回自定义订单ID，ClientOrderID 为空）
+type Trade struct {
+	TradeID         string
+	OrderID         int64
+	ClientOrderID   string
+	Symbol          string
+	Side            Side
+	Price           float64
+	Quantity        float64
+	Commission      float64 // 正数为支出，负数为返佣
+	CommissionAsset string
+	IsMaker         bool
+	Time            int64
+}
+
+// symbolMeta 单个交易对的下单精度信息
+type symbolMeta struct {
 	priceDecimals    int     // 价格精度（小数位数）
 	quantityDecimals int     // 数量精度（小数位数）
 	tickSize         float64 // 最小价格变动单位
 	stepSize         float64 // 最小数量变动单位
+	minNotional      float64 // 最小名义价值（USDT）
 	baseAsset        string  // 基础资产（交易币种），如 BTC
 	quoteAsset       string  // 计价资产（结算币种），如 USDT、USD
 }
 
+// batchOrderSize 批量下单接口每次最多携带的订单数
+const batchOrderSize = 5
+
+// BinanceAdapter 币安交易所适配器
+// 构造时传入的交易对为默认交易对（GetPriceDecimals 等返回它的精度），
+// 其他交易对的精度在首次下单/查询时按需加载并缓存
+type BinanceAdapter struct {
+	client         *futures.Client
+	spot           *gobinance.Client // 现货客户端，非 nil 时为现货模式（见 spot.go）
+	inverse        *delivery.Client  // 币本位合约客户端，非 nil 时为币本位模式（见 inverse.go）
+	symbol         string
+	wsManager      *WebSocketManager
+	klineWSManager *KlineWebSocketManager
+	orderWS        *OrderWebSocketManager // WebSocket 下单（ws-fapi），nil 表示只使用 REST
+	latency        *wsapi.LatencyRecorder
+	clock          *clock.Estimator // 服务器时间偏差（签名请求的时间戳由它生成，见 timestamp.go）
+	limiter        RequestLimiter   // 账户级请求额度（内部发起的 REST 请求使用），nil 表示不限制
+
+	metaMu sync.RWMutex
+	metas  map[string]*symbolMeta // 交易对 -> 精度信息
+}
+
+// useEnvironment 按 environment 配置切换 go-binance 的 REST 与 WebSocket 地址
+// 库内是包级开关，需在创建客户端之前调用（进程内只运行一个交易所适配器）；
+// 币安合约的模拟交易即测试网，因此只支持 mainnet/testnet
+func useEnvironment(env string) (testnet bool, err error) {
+	switch env {
+	case "", "mainnet":
+	case "testnet":
+		testnet = true
+	default:
+		return false, fmt.Errorf("Binance 不支持 environment=%s（可选 mainnet/testnet）", env)
+	}
+	gobinance.UseTestnet = testnet
+	futures.UseTestnet = testnet
+	delivery.UseTestnet = testnet
+	if testnet {
+		logger.Info("ℹ️ [Binance] 使用测试网")
+	}
+	return testnet, nil
+}
+
 // NewBinanceAdapter 创建币安适配器
 func NewBinanceAdapter(cfg map[string]string, symbol string) (*BinanceAdapter, error) {
 	apiKey := cfg["api_key"]
@@ -127,29 +259,42 @@ func NewBinanceAdapter(cfg map[string]string, symbol string) (*BinanceAdapter, e
 	if apiKey == "" || secretKey == "" {
 		return nil, fmt.Errorf("Binance API 配置不完整")
 	}
+	testnet, err := useEnvironment(cfg["environment"])
+	if err != nil {
+		return nil, err
+	}
 
 	client := futures.NewClient(apiKey, secretKey)
 
-	// 同步服务器时间
-	client.NewSetServerTimeService().Do(context.Background())
-
 	wsManager := NewWebSocketManager(apiKey, secretKey)
+	if testnet {
+		wsManager.streamURL = futuresTestnetStreamURL
+	}
 
 	adapter := &BinanceAdapter{
 		client:    client,
 		symbol:    symbol,
 		wsManager: wsManager,
+		latency:   wsapi.NewLatencyRecorder("Binance"),
+		metas:     make(map[string]*symbolMeta),
 	}
+	if cfg["ws_order_entry"] == "true" {
+		adapter.orderWS = NewOrderWebSocketManager(apiKey, secretKey)
+	}
+	adapter.startClock()
 
-	// 获取合约信息（价格精度、数量精度等）
+	// 获取默认交易对的合约信息（价格精度、数量精度等）
 	ctxInit, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	if err := adapter.fetchExchangeInfo(ctxInit); err != nil {
+	if _, err := adapter.symbolMeta(ctxInit, symbol); err != nil {
 		logger.Warn("⚠️ [Binance] 获取合约信息失败: %v，使用默认精度", err)
 		// 使用默认值
-		adapter.priceDecimals = 2
-		adapter.quantityDecimals = 3
+		adapter.metas[symbol] = &symbolMeta{
+			priceDecimals:    2,
+			quantityDecimals: 3,
+			minNotional:      5.0,
+		}
 	}
 
 	return adapter, nil
@@ -160,51 +305,166 @@ func (b *BinanceAdapter) GetName() string {
 	return "Binance"
 }
 
-// fetchExchangeInfo 获取合约信息（价格精度、数量精度等）
-func (b *BinanceAdapter) fetchExchangeInfo(ctx context.Context) error {
+// startClock 创建对时器，立即对时一次后定期对时
+// 签名请求的 timestamp 由 clockTransport 按对时器重新生成，不改写 go-binance 客户端的 TimeOffset
+// （现货用户数据流与 WebSocket 下单也从对时器读取偏差）
+func (b *BinanceAdapter) startClock() {
+	var fetch clock.ServerTimeFunc
+	switch {
+	case b.spot != nil:
+		fetch = func(ctx context.Context) (time.Time, error) {
+			ms, err := b.spot.NewServerTimeService().Do(ctx)
+			return time.UnixMilli(ms), err
+		}
+	case b.inverse != nil:
+		fetch = func(ctx context.Context) (time.Time, error) {
+			ms, err := b.inverse.NewServerTimeService().Do(ctx)
+			return time.UnixMilli(ms), err
+		}
+	default:
+		fetch = func(ctx context.Context) (time.Time, error) {
+			ms, err := b.client.NewServerTimeService().Do(ctx)
+			return time.UnixMilli(ms), err
+		}
+	}
+
+	b.clock = clock.NewEstimator("Binance", fetch)
+	b.setTransport(http.DefaultTransport)
+	if b.wsManager != nil {
+		b.wsManager.clock = b.clock
+	}
+	b.clock.Start(context.Background(), clock.DefaultSyncInterval)
+}
+
+// ClockSkew 本地时钟相对服务器的偏差（服务器时间 - 本地时间）
+func (b *BinanceAdapter) ClockSkew() time.Duration {
+	return b.clock.Offset()
+}
+
+// SyncServerTime 立即与服务器对时
+func (b *BinanceAdapter) SyncServerTime(ctx context.Context) error {
+	return b.clock.Sync(ctx)
+}
+
+// symbolMeta 获取交易对精度信息，首次使用时从交易所加载并缓存
+func (b *BinanceAdapter) symbolMeta(ctx context.Context, symbol string) (*symbolMeta, error) {
+	b.metaMu.RLock()
+	meta, ok := b.metas[symbol]
+	b.metaMu.RUnlock()
+	if ok {
+		return meta, nil
+	}
+
+	info, err := b.GetSymbolInfo(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	meta = &symbolMeta{
+		priceDecimals:    info.PriceDecimals,
+		quantityDecimals: info.QuantityDecimals,
+		tickSize:         info.TickSize,
+		stepSize:         info.StepSize,
+		minNotional:      info.MinNotional,
+		baseAsset:        info.BaseAsset,
+		quoteAsset:       info.QuoteAsset,
+	}
+	if meta.minNotional <= 0 {
+		meta.minNotional = 5.0
+	}
+
+	b.metaMu.Lock()
+	b.metas[symbol] = meta
+	b.metaMu.Unlock()
+
+	logger.Info("ℹ️ [Binance 合约信息] %s - 价格精度:%d (tickSize:%.8f), 数量精度:%d (stepSize:%.8f), 基础币种:%s",
+		symbol, meta.priceDecimals, meta.tickSize, meta.quantityDecimals, meta.stepSize, meta.baseAsset)
+	return meta, nil
+}
+
+// defaultMeta 默认交易对的精度信息（构造时已加载或填入默认值）
+func (b *BinanceAdapter) defaultMeta() *symbolMeta {
+	b.metaMu.RLock()
+	defer b.metaMu.RUnlock()
+	return b.metas[b.symbol]
+}
+
+// GetSymbolInfo 获取合约交易规则
+func (b *BinanceAdapter) GetSymbolInfo(ctx context.Context, symbol string) (*SymbolInfo, error) {
+	if b.spot != nil {
+		return b.spotSymbolInfo(ctx, symbol)
+	}
+	if b.inverse != nil {
+		return b.inverseSymbolInfo(ctx, symbol)
+	}
 	exchangeInfo, err := b.client.NewExchangeInfoService().Do(ctx)
 	if err != nil {
-		return fmt.Errorf("获取交易所信息失败: %w", err)
+		return nil, fmt.Errorf("获取交易所信息失败: %w", err)
 	}
 
 	// 查找指定交易对的信息
-	for _, symbol := range exchangeInfo.Symbols {
-		if symbol.Symbol == b.symbol {
-			b.priceDecimals = symbol.PricePrecision
-			b.quantityDecimals = symbol.QuantityPrecision
-			b.baseAsset = symbol.BaseAsset
-			b.quoteAsset = symbol.QuoteAsset
-
-			// 解析过滤器获取 tickSize 和 stepSize
-			for _, filter := range symbol.Filters {
-				filterType, ok := filter["filterType"].(string)
-				if !ok {
-					continue
+	for _, s := range exchangeInfo.Symbols {
+		if s.Symbol != symbol {
+			continue
+		}
+
+		info := &SymbolInfo{
+			Symbol:             s.Symbol,
+			BaseAsset:          s.BaseAsset,
+			QuoteAsset:         s.QuoteAsset,
+			ContractMultiplier: 1, // U本位合约按币数量下单
+			PriceDecimals:      s.PricePrecision,
+			QuantityDecimals:   s.QuantityPrecision,
+		}
+
+		// 解析过滤器获取 tickSize、stepSize、最小数量与最小名义价值
+		for _, filter := range s.Filters {
+			filterType, ok := filter["filterType"].(string)
+			if !ok {
+				continue
+			}
+
+			switch filterType {
+			case "PRICE_FILTER":
+				if tickSize, ok := filter["tickSize"].(string); ok {
+					info.TickSize, _ = strconv.ParseFloat(tickSize, 64)
+					// 根据 tickSize 重新计算价格精度
+					info.PriceDecimals = countDecimalPlaces(info.TickSize)
+				}
+			case "LOT_SIZE":
+				if stepSize, ok := filter["stepSize"].(string); ok {
+					info.StepSize, _ = strconv.ParseFloat(stepSize, 64)
+					// 根据 stepSize 重新计算数量精度
+					info.QuantityDecimals = countDecimalPlaces(info.StepSize)
+				}
+				if minQty, ok := filter["minQty"].(string); ok {
+					info.MinQty, _ = strconv.ParseFloat(minQty, 64)
+				}
+			case "MIN_NOTIONAL":
+				if notional, ok := filter["notional"].(string); ok {
+					info.MinNotional, _ = strconv.ParseFloat(notional, 64)
 				}
+			}
+		}
 
-				switch filterType {
-				case "PRICE_FILTER":
-					if tickSize, ok := filter["tickSize"].(string); ok {
-						b.tickSize, _ = strconv.ParseFloat(tickSize, 64)
-						// 根据 tickSize 重新计算价格精度
-						b.priceDecimals = countDecimalPlaces(b.tickSize)
-					}
-				case "LOT_SIZE":
-					if stepSize, ok := filter["stepSize"].(string); ok {
-						b.stepSize, _ = strconv.ParseFloat(stepSize, 64)
-						// 根据 stepSize 重新计算数量精度
-						b.quantityDecimals = countDecimalPlaces(b.stepSize)
+		// 杠杆分层需要签名，获取失败不影响其他字段
+		brackets, err := b.client.NewGetLeverageBracketService().Symbol(symbol).Do(ctx)
+		if err != nil {
+			logger.Warn("⚠️ [Binance] 获取 %s 杠杆分层失败: %v", symbol, err)
+		} else {
+			for _, lb := range brackets {
+				for _, bracket := range lb.Brackets {
+					if bracket.InitialLeverage > info.MaxLeverage {
+						info.MaxLeverage = bracket.InitialLeverage
 					}
 				}
 			}
-
-			logger.Info("ℹ️ [Binance 合约信息] %s - 价格精度:%d (tickSize:%.8f), 数量精度:%d (stepSize:%.8f), 基础币种:%s",
-				b.symbol, b.priceDecimals, b.tickSize, b.quantityDecimals, b.stepSize, b.baseAsset)
-			return nil
 		}
+
+		return info, nil
 	}
 
-	return fmt.Errorf("未找到合约信息: %s", b.symbol)
+	return nil, fmt.Errorf("未找到合约信息: %s", symbol)
 }
 
 // countDecimalPlaces 计算小数位数
@@ -235,60 +495,77 @@ func alignToTickSize(value, tickSize float64) float64 {
 
 // PlaceOrder 下单
 func (b *BinanceAdapter) PlaceOrder(ctx context.Context, req *OrderRequest) (*Order, error) {
-	// 对齐价格到 tickSize
-	price := req.Price
-	if b.tickSize > 0 {
-		price = alignToTickSize(price, b.tickSize)
+	if b.spot != nil {
+		return b.spotPlaceOrder(ctx, req)
 	}
-	priceStr := fmt.Sprintf("%.*f", b.priceDecimals, price)
-
-	// 对齐数量到 stepSize
-	quantity := req.Quantity
-	if b.stepSize > 0 {
-		quantity = alignToTickSize(quantity, b.stepSize)
-	}
-	quantityStr := fmt.Sprintf("%.*f", b.quantityDecimals, quantity)
-
-	// 检查最小名义价值（Binance 要求 >= 5 USDT）
-	notional := price * quantity
-	if notional < 5.0 && !req.ReduceOnly {
-		return nil, fmt.Errorf("订单名义价值 %.2f USDT 小于最小要求 5 USDT (价格:%.4f × 数量:%.4f)", notional, price, quantity)
+	if b.inverse != nil {
+		return b.inversePlaceOrder(ctx, req)
 	}
-
-	// 根据 PostOnly 参数选择 TimeInForce
-	timeInForce := futures.TimeInForceTypeGTC
-	if req.PostOnly {
-		timeInForce = futures.TimeInForceTypeGTX // Post Only - 只做 Maker
+	params, err := b.prepareLimitOrder(ctx, req)
+	if err != nil {
+		return nil, err
 	}
 
-	orderService := b.client.NewCreateOrderService().
-		Symbol(req.Symbol).
-		Side(futures.SideType(req.Side)).
-		Type(futures.OrderTypeLimit).
-		TimeInForce(timeInForce).
-		Quantity(quantityStr).
-		Price(priceStr)
-
-	// 设置自定义订单ID（添加返佣标识）
-	clientOrderID := req.ClientOrderID
-	if clientOrderID != "" {
-		// 添加币安返佣前缀 x-zdfVM8vY（合约经纪商ID）
-		clientOrderID = utils.AddBrokerPrefix("binance", clientOrderID)
-		orderService = orderService.NewClientOrderID(clientOrderID)
+	// WebSocket 下单：连接不可用（未发出请求）时改用 REST；
+	// 已发出但超时/断开时先按客户端订单ID查单，确认交易所没有收到再改用 REST
+	if b.orderWS != nil {
+		start := time.Now()
+		resp, err := b.orderWS.PlaceOrder(ctx, params.wsRequest(req), -b.clock.Offset().Milliseconds())
+		if err == nil {
+			b.latency.Record(wsapi.PathWebSocket, time.Since(start))
+			return placedOrder(req, resp), nil
+		}
+		switch {
+		case errors.Is(err, wsapi.ErrNotConnected):
+			logger.Debug("[Binance] WebSocket 下单不可用，改用 REST: %v", err)
+		case errors.Is(err, wsapi.ErrTimeout), errors.Is(err, wsapi.ErrDisconnected):
+			order, lookupErr := b.lookupUnacknowledged(ctx, req.Symbol, params.clientOrderID)
+			if lookupErr != nil {
+				return nil, fmt.Errorf("%w（查单确认失败: %v）", err, lookupErr)
+			}
+			if order != nil {
+				logger.Warn("⚠️ [Binance] WebSocket 下单未收到确认，查单确认已受理: %s", params.clientOrderID)
+				return order, nil
+			}
+			logger.Warn("⚠️ [Binance] WebSocket 下单未送达交易所，改用 REST: %v", err)
+		default:
+			return nil, err
+		}
 	}
 
-	// 币安单向持仓模式：如果是平仓单，需要设置 ReduceOnly
-	// 注意：币安的 ReduceOnly 仅在单向持仓模式下有效
-	if req.ReduceOnly {
-		orderService = orderService.ReduceOnly(true)
+	start := time.Now()
+	resp, err := params.restService(b.client, req).Do(ctx)
+	if err != nil {
+		return nil, classifyError(err)
 	}
+	b.latency.Record(wsapi.PathREST, time.Since(start))
 
-	resp, err := orderService.Do(ctx)
+	return placedOrder(req, resp), nil
+}
 
+// lookupUnacknowledged 按客户端订单ID查询未收到确认的 WebSocket 下单
+// 交易所不存在该订单时返回 (nil, nil)，调用方可以用同一客户端订单ID改走 REST
+// （请求若仍在途中晚于查单到达，REST 重下会因客户端订单ID重复被拒，不会重复挂单）；
+// 没有客户端订单ID时无法确认，返回错误
+func (b *BinanceAdapter) lookupUnacknowledged(ctx context.Context, symbol, clientOrderID string) (*Order, error) {
+	if clientOrderID == "" {
+		return nil, fmt.Errorf("订单没有客户端订单ID，无法确认是否已受理")
+	}
+	order, err := b.client.NewGetOrderService().
+		Symbol(symbol).
+		OrigClientOrderID(clientOrderID).
+		Do(ctx)
 	if err != nil {
+		if err = classifyError(err); errors.Is(err, errs.ErrOrderNotFound) {
+			return nil, nil
+		}
 		return nil, err
 	}
+	return convertOrder(order), nil
+}
 
+// placedOrder 由下单响应构造新订单（价格与数量使用请求值）
+func placedOrder(req *OrderRequest, resp *futures.CreateOrderResponse) *Order {
 	return &Order{
 		OrderID:       resp.OrderID,
 		ClientOrderID: resp.ClientOrderID,
@@ -300,26 +577,215 @@ func (b *BinanceAdapter) PlaceOrder(ctx context.Context, req *OrderRequest) (*Or
 		Status:        OrderStatus(resp.Status),
 		CreatedAt:     time.Now(),
 		UpdateTime:    resp.UpdateTime,
+	}
+}
+
+// limitOrderParams 对齐精度并通过最小名义价值检查的限价单参数
+type limitOrderParams struct {
+	price         string
+	quantity      string
+	timeInForce   futures.TimeInForceType
+	clientOrderID string // 已添加返佣前缀
+}
+
+// buildOrderService 构造下单请求，批量下单使用
+func (b *BinanceAdapter) buildOrderService(ctx context.Context, req *OrderRequest) (*futures.CreateOrderService, error) {
+	params, err := b.prepareLimitOrder(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return params.restService(b.client, req), nil
+}
+
+// restService 构造 REST 下单请求
+func (p *limitOrderParams) restService(client *futures.Client, req *OrderRequest) *futures.CreateOrderService {
+	orderService := client.NewCreateOrderService().
+		Symbol(req.Symbol).
+		Side(futures.SideType(req.Side)).
+		Type(futures.OrderTypeLimit).
+		TimeInForce(p.timeInForce).
+		Quantity(p.quantity).
+		Price(p.price)
+
+	if p.clientOrderID != "" {
+		orderService = orderService.NewClientOrderID(p.clientOrderID)
+	}
+
+	// 币安双向持仓模式：由 positionSide 与买卖方向决定开平仓，不能再传 reduceOnly（否则报 -1106）
+	// 单向持仓模式：如果是平仓单，需要设置 ReduceOnly
+	if req.PositionSide != "" {
+		orderService = orderService.PositionSide(futures.PositionSideType(req.PositionSide))
+	} else if req.ReduceOnly {
+		orderService = orderService.ReduceOnly(true)
+	}
+
+	return orderService
+}
+
+// wsRequest 构造 WebSocket 下单请求（order.place），参数与 REST 下单一致
+func (p *limitOrderParams) wsRequest(req *OrderRequest) *futures.OrderPlaceWsRequest {
+	request := futures.NewOrderPlaceWsRequest().
+		Symbol(req.Symbol).
+		Side(futures.SideType(req.Side)).
+		Type(futures.OrderTypeLimit).
+		TimeInForce(p.timeInForce).
+		Quantity(p.quantity).
+		Price(p.price).
+		NewOrderResponseType(futures.NewOrderRespTypeACK)
+
+	if p.clientOrderID != "" {
+		request = request.NewClientOrderID(p.clientOrderID)
+	}
+
+	if req.PositionSide != "" {
+		request = request.PositionSide(futures.PositionSideType(req.PositionSide))
+	} else if req.ReduceOnly {
+		request = request.ReduceOnly(true)
+	}
+
+	return request
+}
+
+// prepareLimitOrder 对齐精度、检查最小名义价值，单笔下单、批量下单与 WebSocket 下单共用
+func (b *BinanceAdapter) prepareLimitOrder(ctx context.Context, req *OrderRequest) (*limitOrderParams, error) {
+	meta, err := b.symbolMeta(ctx, req.Symbol)
+	if err != nil {
+		return nil, fmt.Errorf("获取 %s 合约信息失败: %w", req.Symbol, err)
+	}
+
+	// 对齐价格到 tickSize
+	price := req.Price
+	if meta.tickSize > 0 {
+		price = alignToTickSize(price, meta.tickSize)
+	}
+	priceStr := fmt.Sprintf("%.*f", meta.priceDecimals, price)
+
+	// 对齐数量到 stepSize
+	quantity := req.Quantity
+	if meta.stepSize > 0 {
+		quantity = alignToTickSize(quantity, meta.stepSize)
+	}
+	quantityStr := fmt.Sprintf("%.*f", meta.quantityDecimals, quantity)
+
+	// 检查最小名义价值（Binance 一般要求 >= 5 USDT，以合约信息为准）
+	notional := price * quantity
+	if notional < meta.minNotional && !req.ReduceOnly {
+		return nil, fmt.Errorf("%w: %.2f USDT 小于最小要求 %.0f USDT (价格:%.4f × 数量:%.4f)", errs.ErrMinNotional, notional, meta.minNotional, price, quantity)
+	}
+
+	// 根据 PostOnly 参数选择 TimeInForce
+	timeInForce := futures.TimeInForceTypeGTC
+	if req.PostOnly {
+		timeInForce = futures.TimeInForceTypeGTX // Post Only - 只做 Maker
+	}
+
+	// 设置自定义订单ID（添加返佣标识）
+	clientOrderID := req.ClientOrderID
+	if clientOrderID != "" {
+		// 添加币安返佣前缀 x-zdfVM8vY（合约经纪商ID）
+		clientOrderID = utils.AddBrokerPrefix("binance", clientOrderID)
+	}
+
+	return &limitOrderParams{
+		price:         priceStr,
+		quantity:      quantityStr,
+		timeInForce:   timeInForce,
+		clientOrderID: clientOrderID,
 	}, nil
 }
 
-// BatchPlaceOrders 批量下单
+// BatchPlaceOrders 批量下单（使用 /fapi/v1/batchOrders，每次最多5个）
+// 每个订单的结果单独判断：成功的订单加入返回列表，失败的订单打印原因，任一订单保证金不足时返回 true
 func (b *BinanceAdapter) BatchPlaceOrders(ctx context.Context, orders []*OrderRequest) ([]*Order, bool) {
+	if b.spot != nil {
+		return b.spotBatchPlaceOrders(ctx, orders)
+	}
+	if b.inverse != nil {
+		return b.inverseBatchPlaceOrders(ctx, orders)
+	}
 	placedOrders := make([]*Order, 0, len(orders))
 	hasMarginError := false
 
-	for _, orderReq := range orders {
-		order, err := b.PlaceOrder(ctx, orderReq)
-		if err != nil {
-			logger.Warn("⚠️ [Binance] 下单失败 %.2f %s: %v",
-				orderReq.Price, orderReq.Side, err)
+	// reportError 记录单个订单失败，并检查是否保证金不足
+	reportError := func(req *OrderRequest, err error) {
+		logger.Warn("⚠️ [Binance] 下单失败 %.2f %s: %v", req.Price, req.Side, err)
+		errs.Report(ctx, err)
+		if errors.Is(err, errs.ErrInsufficientMargin) {
+			hasMarginError = true
+		}
+	}
+
+	for i := 0; i < len(orders); i += batchOrderSize {
+		end := i + batchOrderSize
+		if end > len(orders) {
+			end = len(orders)
+		}
+
+		// 构造批量请求，跳过本地校验失败的订单
+		var batchReqs []*OrderRequest
+		var services []*futures.CreateOrderService
+		for _, req := range orders[i:end] {
+			service, err := b.buildOrderService(ctx, req)
+			if err != nil {
+				reportError(req, err)
+				continue
+			}
+			batchReqs = append(batchReqs, req)
+			services = append(services, service)
+		}
+
+		if len(services) == 0 {
+			continue
+		}
 
-			if strings.Contains(err.Error(), "-2019") || strings.Contains(err.Error(), "insufficient") {
-				hasMarginError = true
+		// 🔥 如果只有1个订单，直接用单个下单接口
+		if len(services) == 1 {
+			order, err := b.PlaceOrder(ctx, batchReqs[0])
+			if err != nil {
+				reportError(batchReqs[0], err)
+				continue
 			}
+			placedOrders = append(placedOrders, order)
 			continue
 		}
-		placedOrders = append(placedOrders, order)
+
+		resp, err := b.client.NewCreateBatchOrdersService().OrderList(services).Do(ctx)
+		if err != nil {
+			// 整个请求失败（网络错误、限频等），本批订单全部视为失败
+			err = classifyError(err)
+			for _, req := range batchReqs {
+				reportError(req, err)
+			}
+			continue
+		}
+
+		// 响应按请求顺序返回：Errors[j] 非空表示第 j 个订单失败，成功的订单依次排列在 Orders 中
+		next := 0
+		for j, req := range batchReqs {
+			if j < len(resp.Errors) && resp.Errors[j] != nil {
+				reportError(req, classifyError(resp.Errors[j]))
+				continue
+			}
+			if next >= len(resp.Orders) {
+				reportError(req, fmt.Errorf("批量下单响应缺少订单结果"))
+				continue
+			}
+			ack := resp.Orders[next]
+			next++
+
+			placedOrders = append(placedOrders, &Order{
+				OrderID:       ack.OrderID,
+				ClientOrderID: ack.ClientOrderID,
+				Symbol:        req.Symbol,
+				Side:          req.Side,
+				Type:          req.Type,
+				Price:         req.Price,
+				Quantity:      req.Quantity,
+				Status:        OrderStatus(ack.Status),
+				CreatedAt:     time.Now(),
+				UpdateTime:    ack.UpdateTime,
+			})
+		}
 	}
 
 	return placedOrders, hasMarginError
@@ -327,14 +793,20 @@ func (b *BinanceAdapter) BatchPlaceOrders(ctx context.Context, orders []*OrderRe
 
 // CancelOrder 取消订单
 func (b *BinanceAdapter) CancelOrder(ctx context.Context, symbol string, orderID int64) error {
+	if b.spot != nil {
+		return b.spotCancelOrder(ctx, symbol, orderID)
+	}
+	if b.inverse != nil {
+		return b.inverseCancelOrder(ctx, symbol, orderID)
+	}
 	_, err := b.client.NewCancelOrderService().
 		Symbol(symbol).
 		OrderID(orderID).
 		Do(ctx)
 
 	if err != nil {
-		errStr := err.Error()
-		if strings.Contains(errStr, "-2011") || strings.Contains(errStr, "Unknown order") {
+		err = classifyError(err)
+		if errors.Is(err, errs.ErrOrderNotFound) {
 			logger.Info("ℹ️ [Binance] 订单 %d 已不存在，跳过取消", orderID)
 			return nil
 		}
@@ -345,11 +817,80 @@ func (b *BinanceAdapter) CancelOrder(ctx context.Context, symbol string, orderID
 	return nil
 }
 
+// AmendOrder 改单（修改挂单的价格/数量，订单ID不变）
+// newQty 为改单后的订单总数量，<= 0 时保持原数量；
+// 币安改单接口要求同时传入方向、数量和价格，因此先查询原订单
+func (b *BinanceAdapter) AmendOrder(ctx context.Context, symbol string, orderID int64, newPrice, newQty float64) (*Order, error) {
+	if b.spot != nil {
+		return nil, errSpotUnsupported("改单")
+	}
+	if b.inverse != nil {
+		return nil, errInverseUnsupported("改单")
+	}
+	original, err := b.GetOrder(ctx, symbol, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("查询原订单失败: %w", err)
+	}
+	if newQty <= 0 {
+		newQty = original.Quantity
+	}
+
+	meta, err := b.symbolMeta(ctx, symbol)
+	if err != nil {
+		return nil, fmt.Errorf("获取 %s 合约信息失败: %w", symbol, err)
+	}
+	if meta.tickSize > 0 {
+		newPrice = alignToTickSize(newPrice, meta.tickSize)
+	}
+	if meta.stepSize > 0 {
+		newQty = alignToTickSize(newQty, meta.stepSize)
+	}
+
+	resp, err := b.client.NewModifyOrderService().
+		Symbol(symbol).
+		OrderID(orderID).
+		Side(futures.SideType(original.Side)).
+		Quantity(fmt.Sprintf("%.*f", meta.quantityDecimals, newQty)).
+		Price(fmt.Sprintf("%.*f", meta.priceDecimals, newPrice)).
+		Do(ctx)
+	if err != nil {
+		return nil, classifyError(err)
+	}
+
+	price, _ := strconv.ParseFloat(resp.Price, 64)
+	quantity, _ := strconv.ParseFloat(resp.OriginalQuantity, 64)
+	executedQty, _ := strconv.ParseFloat(resp.ExecutedQuantity, 64)
+	avgPrice, _ := strconv.ParseFloat(resp.AveragePrice, 64)
+
+	logger.Info("✅ [Binance] 改单成功: %d 价格 %.*f -> %.*f",
+		orderID, meta.priceDecimals, original.Price, meta.priceDecimals, price)
+
+	return &Order{
+		OrderID:       resp.OrderID,
+		ClientOrderID: resp.ClientOrderID,
+		Symbol:        resp.Symbol,
+		Side:          Side(resp.Side),
+		Type:          OrderType(resp.Type),
+		Price:         price,
+		Quantity:      quantity,
+		ExecutedQty:   executedQty,
+		AvgPrice:      avgPrice,
+		Status:        OrderStatus(resp.Status),
+		UpdateTime:    resp.UpdateTime,
+	}, nil
+}
+
 // BatchCancelOrders 批量撤单
 func (b *BinanceAdapter) BatchCancelOrders(ctx context.Context, symbol string, orderIDs []int64) error {
 	if len(orderIDs) == 0 {
 		return nil
 	}
+	if b.spot != nil {
+		return b.spotBatchCancelOrders(ctx, symbol, orderIDs)
+	}
+	if b.inverse != nil {
+		return b.inverseBatchCancelOrders(ctx, symbol, orderIDs)
+	}
 
 	// 🔥 Binance 批量撤单限制：最多10个
 	batchSize := 10
@@ -397,15 +938,25 @@ func (b *BinanceAdapter) BatchCancelOrders(ctx context.Context, symbol string, o
 
 // GetOrder 查询订单
 func (b *BinanceAdapter) GetOrder(ctx context.Context, symbol string, orderID int64) (*Order, error) {
+	if b.spot != nil {
+		return b.spotGetOrder(ctx, symbol, orderID)
+	}
+	if b.inverse != nil {
+		return b.inverseGetOrder(ctx, symbol, orderID)
+	}
 	order, err := b.client.NewGetOrderService().
 		Symbol(symbol).
 		OrderID(orderID).
 		Do(ctx)
 
 	if err != nil {
-		return nil, err
+		return nil, classifyError(err)
 	}
+	return convertOrder(order), nil
+}
 
+// convertOrder 转换查单结果
+func convertOrder(order *futures.Order) *Order {
 	price, _ := strconv.ParseFloat(order.Price, 64)
 	quantity, _ := strconv.ParseFloat(order.OrigQuantity, 64)
 	executedQty, _ := strconv.ParseFloat(order.ExecutedQuantity, 64)
@@ -423,11 +974,17 @@ func (b *BinanceAdapter) GetOrder(ctx context.Context, symbol string, orderID in
 		AvgPrice:      avgPrice,
 		Status:        OrderStatus(order.Status),
 		UpdateTime:    order.UpdateTime,
-	}, nil
+	}
 }
 
 // GetOpenOrders 查询未完成订单
 func (b *BinanceAdapter) GetOpenOrders(ctx context.Context, symbol string) ([]*Order, error) {
+	if b.spot != nil {
+		return b.spotGetOpenOrders(ctx, symbol)
+	}
+	if b.inverse != nil {
+		return b.inverseGetOpenOrders(ctx, symbol)
+	}
 	orders, err := b.client.NewListOpenOrdersService().
 		Symbol(symbol).
 		Do(ctx)
@@ -463,6 +1020,12 @@ func (b *BinanceAdapter) GetOpenOrders(ctx context.Context, symbol string) ([]*O
 
 // GetAccount 获取账户信息（合约账户）
 func (b *BinanceAdapter) GetAccount(ctx context.Context) (*Account, error) {
+	if b.spot != nil {
+		return b.spotGetAccount(ctx)
+	}
+	if b.inverse != nil {
+		return b.inverseGetAccount(ctx)
+	}
 	// 🔥 修复：使用合约账户专用的 API
 	account, err := b.client.NewGetAccountService().Do(ctx)
 	if err != nil {
@@ -524,6 +1087,12 @@ func (b *BinanceAdapter) GetAccount(ctx context.Context) (*Account, error) {
 
 // GetPositions 获取持仓信息（使用PositionRisk API获取准确的杠杆倍数）
 func (b *BinanceAdapter) GetPositions(ctx context.Context, symbol string) ([]*Position, error) {
+	if b.spot != nil {
+		return b.spotGetPositions(ctx, symbol)
+	}
+	if b.inverse != nil {
+		return b.inverseGetPositions(ctx, symbol)
+	}
 	// 🔥 使用 PositionRisk API，可以获取准确的杠杆信息
 	positionRisks, err := b.client.NewGetPositionRiskService().Symbol(symbol).Do(ctx)
 	if err != nil {
@@ -548,14 +1117,26 @@ func (b *BinanceAdapter) GetPositions(ctx context.Context, symbol string) ([]*Po
 			Leverage:       leverage,
 			MarginType:     pos.MarginType,
 			IsolatedMargin: isolatedMargin,
+			Side:           hedgePositionSide(pos.PositionSide),
 		})
 	}
 
 	return result, nil
 }
 
+// hedgePositionSide 转换币安持仓方向，单向持仓（BOTH）返回空
+func hedgePositionSide(side string) string {
+	if side == string(futures.PositionSideTypeBoth) {
+		return ""
+	}
+	return side
+}
+
 // GetBalance 获取余额
 func (b *BinanceAdapter) GetBalance(ctx context.Context, asset string) (float64, error) {
+	if b.spot != nil {
+		return b.spotGetBalance(ctx, asset)
+	}
 	account, err := b.GetAccount(ctx)
 	if err != nil {
 		return 0, err
@@ -580,6 +1161,7 @@ func (b *BinanceAdapter) StartOrderStream(ctx context.Context, callback func(int
 			ExecutedQty   float64
 			AvgPrice      float64
 			UpdateTime    int64
+			Fill          *FillEvent
 		}{
 			OrderID:       update.OrderID,
 			ClientOrderID: update.ClientOrderID, // 🔥 关键：传递 ClientOrderID
@@ -592,14 +1174,30 @@ func (b *BinanceAdapter) StartOrderStream(ctx context.Context, callback func(int
 			ExecutedQty:   update.ExecutedQty,
 			AvgPrice:      update.AvgPrice,
 			UpdateTime:    update.UpdateTime,
+			Fill:          update.Fill,
 		}
 		callback(genericUpdate)
 	}
+	if b.orderWS != nil {
+		b.orderWS.Start(ctx) // WebSocket 下单连接与订单流同时启动
+	}
 	return b.wsManager.Start(ctx, localCallback)
 }
 
+// StartAccountStream 启动账户推送（ACCOUNT_UPDATE：余额与持仓变化）
+// 与订单流共用同一个用户数据流连接，只推送发生变化的资产与持仓，不含可用余额
+func (b *BinanceAdapter) StartAccountStream(ctx context.Context, callback AccountUpdateCallback) error {
+	if b.spot != nil {
+		return errSpotUnsupported("账户推送")
+	}
+	return b.wsManager.StartAccountStream(ctx, callback)
+}
+
 // StopOrderStream 停止订单流
 func (b *BinanceAdapter) StopOrderStream() error {
+	if b.orderWS != nil {
+		b.orderWS.Stop()
+	}
 	b.wsManager.Stop()
 	return nil
 }
@@ -614,7 +1212,7 @@ func (b *BinanceAdapter) StopOrderStream() error {
 func (b *BinanceAdapter) GetLatestPrice(ctx context.Context, symbol string) (float64, error) {
 	// 从 WebSocket 缓存读取价格
 	if b.wsManager != nil {
-		price := b.wsManager.GetLatestPrice()
+		price := b.wsManager.GetLatestPrice(symbol)
 		if price > 0 {
 			return price, nil
 		}
@@ -633,7 +1231,7 @@ func (b *BinanceAdapter) StartPriceStream(ctx context.Context, symbol string, ca
 // StartKlineStream 启动K线流（WebSocket）
 func (b *BinanceAdapter) StartKlineStream(ctx context.Context, symbols []string, interval string, callback func(candle interface{})) error {
 	if b.klineWSManager == nil {
-		b.klineWSManager = NewKlineWebSocketManager()
+		b.klineWSManager = NewKlineWebSocketManager(b.wsManager.streamURL)
 	}
 	return b.klineWSManager.Start(ctx, symbols, interval, callback)
 }
@@ -662,8 +1260,195 @@ func (b *BinanceAdapter) ForceReconnectKlineStream() error {
 	return fmt.Errorf("K线流管理器未初始化")
 }
 
+// SetLeverage 设置交易对杠杆倍数
+func (b *BinanceAdapter) SetLeverage(ctx context.Context, symbol string, leverage int) error {
+	if b.spot != nil {
+		return errSpotUnsupported("设置杠杆")
+	}
+	if b.inverse != nil {
+		return b.inverseSetLeverage(ctx, symbol, leverage)
+	}
+	_, err := b.client.NewChangeLeverageService().Symbol(symbol).Leverage(leverage).Do(ctx)
+	if err != nil {
+		return classifyError(err)
+	}
+	logger.Info("✅ [Binance] %s 杠杆倍数已设置为 %dx", symbol, leverage)
+	return nil
+}
+
+// SetMarginType 设置交易对保证金模式（逐仓/全仓），已是目标模式时视为成功
+func (b *BinanceAdapter) SetMarginType(ctx context.Context, symbol string, isolated bool) error {
+	if b.spot != nil {
+		return errSpotUnsupported("设置保证金模式")
+	}
+	if b.inverse != nil {
+		return b.inverseSetMarginType(ctx, symbol, isolated)
+	}
+	marginType := futures.MarginTypeCrossed
+	if isolated {
+		marginType = futures.MarginTypeIsolated
+	}
+	err := b.client.NewChangeMarginTypeService().Symbol(symbol).MarginType(marginType).Do(ctx)
+	if err != nil && !isAPIErrorCode(err, -4046) { // -4046: No need to change margin type
+		return classifyError(err)
+	}
+	logger.Info("✅ [Binance] %s 保证金模式: %s", symbol, marginType)
+	return nil
+}
+
+// SetPositionMode 设置持仓模式（单向/双向，账户级别），已是目标模式时视为成功
+func (b *BinanceAdapter) SetPositionMode(ctx context.Context, hedge bool) error {
+	if b.spot != nil {
+		return errSpotUnsupported("设置持仓模式")
+	}
+	if b.inverse != nil {
+		return b.inverseSetPositionMode(ctx, hedge)
+	}
+	err := b.client.NewChangePositionModeService().DualSide(hedge).Do(ctx)
+	if err != nil && !isAPIErrorCode(err, -4059) { // -4059: No need to change position side
+		return classifyError(err)
+	}
+	mode := "单向持仓"
+	if hedge {
+		mode = "双向持仓"
+	}
+	logger.Info("✅ [Binance] 持仓模式: %s", mode)
+	return nil
+}
+
+// GetFundingRate 获取当期资金费率（premiumIndex，同时返回标记价格）
+func (b *BinanceAdapter) GetFundingRate(ctx context.Context, symbol string) (*FundingRate, error) {
+	if b.spot != nil {
+		return nil, errSpotUnsupported("资金费率")
+	}
+	if b.inverse != nil {
+		return b.inverseGetFundingRate(ctx, symbol)
+	}
+	indexes, err := b.client.NewPremiumIndexService().Symbol(symbol).Do(ctx)
+	if err != nil {
+		return nil, classifyError(err)
+	}
+	if len(indexes) == 0 {
+		return nil, fmt.Errorf("未获取到 %s 的资金费率", symbol)
+	}
+
+	index := indexes[0]
+	fundingRate, _ := strconv.ParseFloat(index.LastFundingRate, 64)
+	markPrice, _ := strconv.ParseFloat(index.MarkPrice, 64)
+	indexPrice, _ := strconv.ParseFloat(index.IndexPrice, 64)
+	return &FundingRate{
+		Symbol:          index.Symbol,
+		FundingRate:     fundingRate,
+		MarkPrice:       markPrice,
+		IndexPrice:      indexPrice,
+		NextFundingTime: index.NextFundingTime,
+		Time:            index.Time,
+	}, nil
+}
+
+// GetMarkPrice 获取标记价格
+func (b *BinanceAdapter) GetMarkPrice(ctx context.Context, symbol string) (float64, error) {
+	rate, err := b.GetFundingRate(ctx, symbol)
+	if err != nil {
+		return 0, err
+	}
+	return rate.MarkPrice, nil
+}
+
+// StartMarkPriceStream 启动标记价格流（每秒推送标记价格与资金费率）
+func (b *BinanceAdapter) StartMarkPriceStream(ctx context.Context, symbol string, callback MarkPriceCallback) error {
+	if b.spot != nil {
+		return errSpotUnsupported("标记价格")
+	}
+	return b.wsManager.StartMarkPriceStream(ctx, symbol, callback)
+}
+
+// StartBookTickerStream 启动盘口最优价流（U本位、币本位与现货均支持）
+func (b *BinanceAdapter) StartBookTickerStream(ctx context.Context, symbol string, callback func(ticker *orderbook.Ticker)) error {
+	return b.wsManager.StartBookTickerStream(ctx, symbol, callback)
+}
+
+// GetFundingPayments 获取 since 之后的资金费收付记录（收益历史中的 FUNDING_FEE）
+func (b *BinanceAdapter) GetFundingPayments(ctx context.Context, symbol string, since time.Time) ([]*FundingPayment, error) {
+	if b.spot != nil {
+		return nil, errSpotUnsupported("资金费")
+	}
+	if b.inverse != nil {
+		return b.inverseGetFundingPayments(ctx, symbol, since)
+	}
+	incomes, err := b.client.NewGetIncomeHistoryService().
+		Symbol(symbol).
+		IncomeType("FUNDING_FEE").
+		StartTime(since.UnixMilli()).
+		Limit(1000).
+		Do(ctx)
+	if err != nil {
+		return nil, classifyError(err)
+	}
+
+	payments := make([]*FundingPayment, 0, len(incomes))
+	for _, income := range incomes {
+		amount, _ := strconv.ParseFloat(income.Income, 64)
+		payments = append(payments, &FundingPayment{
+			ID:     strconv.FormatInt(income.TranID, 10),
+			Symbol: income.Symbol,
+			Amount: amount,
+			Asset:  income.Asset,
+			Time:   income.Time,
+		})
+	}
+	return payments, nil
+}
+
+// GetMyTrades 获取 since 之后本账户的成交记录（/fapi/v1/userTrades，按时间升序，limit 最大 1000）
+func (b *BinanceAdapter) GetMyTrades(ctx context.Context, symbol string, since time.Time, limit int) ([]*Trade, error) {
+	if limit <= 0 || limit > 1000 {
+		limit = 1000
+	}
+	if b.spot != nil {
+		return b.spotGetMyTrades(ctx, symbol, since, limit)
+	}
+	if b.inverse != nil {
+		return b.inverseGetMyTrades(ctx, symbol, since, limit)
+	}
+	trades, err := b.client.NewListAccountTradeService().
+		Symbol(symbol).
+		StartTime(since.UnixMilli()).
+		Limit(limit).
+		Do(ctx)
+	if err != nil {
+		return nil, classifyError(err)
+	}
+
+	result := make([]*Trade, 0, len(trades))
+	for _, t := range trades {
+		price, _ := strconv.ParseFloat(t.Price, 64)
+		qty, _ := strconv.ParseFloat(t.Quantity, 64)
+		commission, _ := strconv.ParseFloat(t.Commission, 64)
+		result = append(result, &Trade{
+			TradeID:         strconv.FormatInt(t.ID, 10),
+			OrderID:         t.OrderID,
+			Symbol:          t.Symbol,
+			Side:            Side(t.Side),
+			Price:           price,
+			Quantity:        qty,
+			Commission:      commission,
+			CommissionAsset: t.CommissionAsset,
+			IsMaker:         t.Maker,
+			Time:            t.Time,
+		})
+	}
+	return result, nil
+}
+
 // GetHistoricalKlines 获取历史K线数据
 func (b *BinanceAdapter) GetHistoricalKlines(ctx context.Context, symbol string, interval string, limit int) ([]*Candle, error) {
+	if b.spot != nil {
+		return b.spotHistoricalKlines(ctx, symbol, interval, limit)
+	}
+	if b.inverse != nil {
+		return b.inverseHistoricalKlines(ctx, symbol, interval, limit)
+	}
 	klines, err := b.client.NewKlinesService().
 		Symbol(symbol).
 		Interval(interval).
@@ -697,22 +1482,84 @@ func (b *BinanceAdapter) GetHistoricalKlines(ctx context.Context, symbol string,
 	return candles, nil
 }
 
+// GetKlinesBetween 获取开盘时间在 [start, end]（毫秒）内的K线，单次请求最多 1000 根（超过 1000 根权重翻倍），按时间升序
+func (b *BinanceAdapter) GetKlinesBetween(ctx context.Context, symbol string, interval string, start, end int64, limit int) ([]*Candle, error) {
+	if limit <= 0 || limit > 1000 {
+		limit = 1000
+	}
+
+	// 三种市场的K线结构字段相同，统一转换
+	type rawKline struct {
+		OpenTime, CloseTime            int64
+		Open, High, Low, Close, Volume string
+	}
+	var raw []rawKline
+	switch {
+	case b.spot != nil:
+		klines, err := b.spot.NewKlinesService().Symbol(symbol).Interval(interval).StartTime(start).EndTime(end).Limit(limit).Do(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("获取历史K线失败: %w", err)
+		}
+		for _, k := range klines {
+			raw = append(raw, rawKline{k.OpenTime, k.CloseTime, k.Open, k.High, k.Low, k.Close, k.Volume})
+		}
+	case b.inverse != nil:
+		klines, err := b.inverse.NewKlinesService().Symbol(symbol).Interval(interval).StartTime(start).EndTime(end).Limit(limit).Do(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("获取历史K线失败: %w", err)
+		}
+		for _, k := range klines {
+			raw = append(raw, rawKline{k.OpenTime, k.CloseTime, k.Open, k.High, k.Low, k.Close, k.Volume})
+		}
+	default:
+		klines, err := b.client.NewKlinesService().Symbol(symbol).Interval(interval).StartTime(start).EndTime(end).Limit(limit).Do(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("获取历史K线失败: %w", err)
+		}
+		for _, k := range klines {
+			raw = append(raw, rawKline{k.OpenTime, k.CloseTime, k.Open, k.High, k.Low, k.Close, k.Volume})
+		}
+	}
+
+	now := time.Now().UnixMilli()
+	candles := make([]*Candle, 0, len(raw))
+	for _, k := range raw {
+		open, _ := strconv.ParseFloat(k.Open, 64)
+		high, _ := strconv.ParseFloat(k.High, 64)
+		low, _ := strconv.ParseFloat(k.Low, 64)
+		close, _ := strconv.ParseFloat(k.Close, 64)
+		volume, _ := strconv.ParseFloat(k.Volume, 64)
+
+		candles = append(candles, &Candle{
+			Symbol:    symbol,
+			Open:      open,
+			High:      high,
+			Low:       low,
+			Close:     close,
+			Volume:    volume,
+			Timestamp: k.OpenTime,
+			IsClosed:  k.CloseTime < now, // 区间包含当前周期时最后一根尚未完结
+		})
+	}
+	return candles, nil
+}
+
 // GetPriceDecimals 获取价格精度（小数位数）
 func (b *BinanceAdapter) GetPriceDecimals() int {
-	return b.priceDecimals
+	return b.defaultMeta().priceDecimals
 }
 
 // GetQuantityDecimals 获取数量精度（小数位数）
 func (b *BinanceAdapter) GetQuantityDecimals() int {
-	return b.quantityDecimals
+	return b.defaultMeta().quantityDecimals
 }
 
 // GetBaseAsset 获取基础资产（交易币种）
 func (b *BinanceAdapter) GetBaseAsset() string {
-	return b.baseAsset
+	return b.defaultMeta().baseAsset
 }
 
 // GetQuoteAsset 获取计价资产（结算币种）
 func (b *BinanceAdapter) GetQuoteAsset() string {
-	return b.quoteAsset
+	return b.defaultMeta().quoteAsset
 }
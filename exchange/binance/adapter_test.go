@@ -0,0 +1,286 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"opensqt/exchange/errs"
+
+	"github.com/adshao/go-binance/v2/futures"
+	"github.com/gorilla/websocket"
+)
+
+// newTestAdapter 创建指向本地测试服务器的适配器（不请求真实交易所）
+func newTestAdapter(t *testing.T, handler http.HandlerFunc) *BinanceAdapter {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client := futures.NewClient("key", "secret")
+	client.BaseURL = server.URL
+	return &BinanceAdapter{
+		client: client,
+		symbol: "ETHUSDT",
+		metas: map[string]*symbolMeta{
+			"ETHUSDT": {priceDecimals: 2, quantityDecimals: 3, tickSize: 0.01, stepSize: 0.001, minNotional: 5},
+		},
+	}
+}
+
+func TestBatchPlaceOrders(t *testing.T) {
+	var batchSizes []int
+	singleOrders := 0
+	adapter := newTestAdapter(t, func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.URL.Path == "/fapi/v1/order" {
+			singleOrders++
+			json.NewEncoder(w).Encode(map[string]interface{}{"orderId": 2000, "status": "NEW"})
+			return
+		}
+		if r.URL.Path != "/fapi/v1/batchOrders" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		var orders []map[string]interface{}
+		if err := json.Unmarshal([]byte(r.Form.Get("batchOrders")), &orders); err != nil {
+			t.Fatalf("解析 batchOrders 失败: %v", err)
+		}
+		batchSizes = append(batchSizes, len(orders))
+
+		// 第一批第2个订单保证金不足，其余成功
+		resp := make([]interface{}, len(orders))
+		for i, o := range orders {
+			if len(batchSizes) == 1 && i == 1 {
+				resp[i] = map[string]interface{}{"code": -2019, "msg": "Margin is insufficient."}
+				continue
+			}
+			resp[i] = map[string]interface{}{
+				"orderId":       1000 + len(batchSizes)*10 + i,
+				"clientOrderId": o["newClientOrderId"],
+				"status":        "NEW",
+			}
+		}
+		json.NewEncoder(w).Encode(resp)
+	})
+
+	reqs := make([]*OrderRequest, 7)
+	for i := range reqs {
+		reqs[i] = &OrderRequest{
+			Symbol:   "ETHUSDT",
+			Side:     SideBuy,
+			Type:     OrderTypeLimit,
+			Price:    3000 - float64(i),
+			Quantity: 0.01,
+		}
+	}
+	// 名义价值不足的订单在本地被拒，不进入批量请求
+	reqs[6].Quantity = 0.001
+
+	placed, marginErr := adapter.BatchPlaceOrders(context.Background(), reqs)
+
+	// 第一批 5 个走批量接口；第二批只剩 1 个有效订单，走单笔下单接口
+	if len(batchSizes) != 1 || batchSizes[0] != 5 || singleOrders != 1 {
+		t.Errorf("批次大小 = %v, 单笔下单 = %d", batchSizes, singleOrders)
+	}
+	if !marginErr {
+		t.Error("批量中出现保证金不足应返回 true")
+	}
+	if len(placed) != 5 {
+		t.Fatalf("成功订单数 = %d, want 5", len(placed))
+	}
+	if placed[1].Price != 2998 {
+		t.Errorf("失败订单之后的结果错位: 第2个成功订单价格 = %v, want 2998", placed[1].Price)
+	}
+}
+
+func TestPlaceOrderWebSocketWithRESTFallback(t *testing.T) {
+	restCalls := 0
+	upgrader := websocket.Upgrader{}
+	var wsURL string
+	adapter := newTestAdapter(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ws-fapi/v1" {
+			conn, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				t.Errorf("升级 WebSocket 失败: %v", err)
+				return
+			}
+			defer conn.Close()
+			for {
+				var req struct {
+					ID     string                 `json:"id"`
+					Method string                 `json:"method"`
+					Params map[string]interface{} `json:"params"`
+				}
+				if err := conn.ReadJSON(&req); err != nil {
+					return
+				}
+				if req.Method != "order.place" || req.Params["signature"] == nil || req.Params["timeInForce"] != "GTX" {
+					t.Errorf("unexpected order.place request: %+v", req)
+				}
+				if req.Params["price"] == "2000.00" {
+					conn.WriteJSON(map[string]interface{}{
+						"id": req.ID, "status": 400,
+						"error": map[string]interface{}{"code": -2019, "msg": "Margin is insufficient."},
+					})
+					continue
+				}
+				conn.WriteJSON(map[string]interface{}{
+					"id": req.ID, "status": 200,
+					"result": map[string]interface{}{"orderId": 2001, "clientOrderId": req.Params["newClientOrderId"], "status": "NEW"},
+				})
+			}
+		}
+		if r.URL.Path != "/fapi/v1/order" {
+			http.NotFound(w, r)
+			return
+		}
+		restCalls++
+		json.NewEncoder(w).Encode(map[string]interface{}{"orderId": 1001, "status": "NEW"})
+	})
+	wsURL = "ws" + strings.TrimPrefix(adapter.client.BaseURL, "http") + "/ws-fapi/v1"
+
+	adapter.orderWS = NewOrderWebSocketManager("key", "secret")
+	req := &OrderRequest{Symbol: "ETHUSDT", Side: SideBuy, Type: OrderTypeLimit, Price: 3000, Quantity: 0.01, PostOnly: true}
+
+	// 连接未建立：改用 REST
+	order, err := adapter.PlaceOrder(context.Background(), req)
+	if err != nil || order.OrderID != 1001 || restCalls != 1 {
+		t.Fatalf("REST 回退失败: order=%+v err=%v restCalls=%d", order, err, restCalls)
+	}
+
+	connected := make(chan struct{})
+	adapter.orderWS.dial = func() (*websocket.Conn, error) {
+		conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+		close(connected)
+		return conn, err
+	}
+	adapter.orderWS.Start(context.Background())
+	defer adapter.orderWS.Stop()
+	<-connected
+	for i := 0; i < 100 && !adapter.orderWS.connected(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	order, err = adapter.PlaceOrder(context.Background(), req)
+	if err != nil || order.OrderID != 2001 || restCalls != 1 {
+		t.Fatalf("WebSocket 下单失败: order=%+v err=%v restCalls=%d", order, err, restCalls)
+	}
+
+	req.Price = 2000
+	if _, err := adapter.PlaceOrder(context.Background(), req); !errors.Is(err, errs.ErrInsufficientMargin) {
+		t.Errorf("err = %v, want ErrInsufficientMargin", err)
+	}
+	if restCalls != 1 {
+		t.Errorf("交易所拒单不应改用 REST 重下, restCalls = %d", restCalls)
+	}
+}
+
+// 半开连接：请求发出后没有响应。超时后应断开重连，并按客户端订单ID查单决定是否改用 REST
+func TestPlaceOrderWebSocketTimeoutRecovers(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	var wsURL string
+	orderExists := false
+	lookups, restPlaced := 0, 0
+	adapter := newTestAdapter(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/ws-fapi/v1":
+			conn, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			for {
+				// 只读不回，模拟半开连接
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		case r.URL.Path == "/fapi/v1/order" && r.Method == http.MethodGet:
+			lookups++
+			if r.URL.Query().Get("origClientOrderId") == "" {
+				t.Errorf("查单应使用客户端订单ID: %s", r.URL.RawQuery)
+			}
+			if !orderExists {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"code":-2013,"msg":"Order does not exist."}`))
+				return
+			}
+			w.Write([]byte(`{"orderId":3001,"clientOrderId":"x-zdfVM8vYgrid1","symbol":"ETHUSDT","status":"NEW",
+				"price":"3000.00","origQty":"0.010","executedQty":"0","side":"BUY","type":"LIMIT"}`))
+		case r.URL.Path == "/fapi/v1/order" && r.Method == http.MethodPost:
+			restPlaced++
+			json.NewEncoder(w).Encode(map[string]interface{}{"orderId": 1001, "status": "NEW"})
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	wsURL = "ws" + strings.TrimPrefix(adapter.client.BaseURL, "http") + "/ws-fapi/v1"
+
+	adapter.orderWS = NewOrderWebSocketManager("key", "secret")
+	adapter.orderWS.requestTimeout = 100 * time.Millisecond
+	adapter.orderWS.reconnectDelay = 10 * time.Millisecond
+	adapter.orderWS.dial = func() (*websocket.Conn, error) {
+		conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+		return conn, err
+	}
+	adapter.orderWS.Start(context.Background())
+	defer adapter.orderWS.Stop()
+
+	waitConnected := func() {
+		for i := 0; i < 100 && !adapter.orderWS.connected(); i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	req := &OrderRequest{Symbol: "ETHUSDT", Side: SideBuy, Type: OrderTypeLimit, Price: 3000, Quantity: 0.01, ClientOrderID: "grid1"}
+
+	// 交易所没有收到：改用 REST 下单，并断开半开连接
+	waitConnected()
+	order, err := adapter.PlaceOrder(context.Background(), req)
+	if err != nil || order.OrderID != 1001 || lookups != 1 || restPlaced != 1 {
+		t.Fatalf("未送达时应改用 REST: order=%+v err=%v lookups=%d restPlaced=%d", order, err, lookups, restPlaced)
+	}
+
+	// 交易所已受理：返回查到的订单，不再重下
+	orderExists = true
+	waitConnected()
+	order, err = adapter.PlaceOrder(context.Background(), req)
+	if err != nil || order.OrderID != 3001 || lookups != 2 || restPlaced != 1 {
+		t.Fatalf("已受理时应返回查单结果: order=%+v err=%v lookups=%d restPlaced=%d", order, err, lookups, restPlaced)
+	}
+}
+
+func TestGetMyTrades(t *testing.T) {
+	since := time.UnixMilli(1700000000000)
+	adapter := newTestAdapter(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/fapi/v1/userTrades" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		q := r.URL.Query()
+		if q.Get("symbol") != "ETHUSDT" || q.Get("startTime") != "1700000000000" || q.Get("limit") != "1000" {
+			t.Errorf("查询参数错误: %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(`[{"id":11,"orderId":100,"symbol":"ETHUSDT","side":"BUY","price":"3000.5","qty":"0.02",
+			"commission":"0.012","commissionAsset":"USDT","maker":true,"time":1700000001000}]`))
+	})
+
+	trades, err := adapter.GetMyTrades(context.Background(), "ETHUSDT", since, 0)
+	if err != nil {
+		t.Fatalf("查询成交记录失败: %v", err)
+	}
+	if len(trades) != 1 {
+		t.Fatalf("成交记录数 = %d, want 1", len(trades))
+	}
+	got := trades[0]
+	if got.TradeID != "11" || got.OrderID != 100 || got.Side != SideBuy || got.Price != 3000.5 ||
+		got.Quantity != 0.02 || got.Commission != 0.012 || !got.IsMaker || got.Time != 1700000001000 {
+		t.Errorf("成交记录解析错误: %+v", got)
+	}
+}
@@ -0,0 +1,261 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"opensqt/exchange/orderbook"
+	"opensqt/logger"
+
+	"github.com/adshao/go-binance/v2/common"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// depthStreamLevels 深度推送回调的档数
+	depthStreamLevels = 20
+	// depthSnapshotLimit 同步本地订单簿时 REST 快照的档数
+	depthSnapshotLimit = 1000
+	// depthEventBuffer 拉取快照期间缓存的增量事件数（100ms 推送一次）
+	depthEventBuffer = 1000
+	// minResyncDelay 连续重新同步快照时的初始等待时间，每次翻倍
+	minResyncDelay = time.Second
+	// maxResyncDelay 重新同步快照的等待时间上限
+	maxResyncDelay = 30 * time.Second
+	// resyncStableAfter 距上次拉取快照超过该时间视为已稳定，下一次重新同步不再等待
+	resyncStableAfter = time.Minute
+)
+
+// depthLimits 合约深度接口支持的档数
+var depthLimits = []int{5, 10, 20, 50, 100, 500, 1000}
+
+// depthLimit 取不小于 depth 的最小可用档数
+func depthLimit(depth int) int {
+	for _, limit := range depthLimits {
+		if depth <= limit {
+			return limit
+		}
+	}
+	return depthLimits[len(depthLimits)-1]
+}
+
+// GetOrderBook 获取订单簿快照（REST）
+func (b *BinanceAdapter) GetOrderBook(ctx context.Context, symbol string, depth int) (*orderbook.Snapshot, error) {
+	bids, asks, updateID, err := b.depthSnapshot(ctx, symbol, depthLimit(depth))
+	if err != nil {
+		return nil, err
+	}
+	if depth > 0 && len(bids) > depth {
+		bids = bids[:depth]
+	}
+	if depth > 0 && len(asks) > depth {
+		asks = asks[:depth]
+	}
+	return &orderbook.Snapshot{Symbol: symbol, Bids: bids, Asks: asks, UpdateID: updateID, Time: time.Now()}, nil
+}
+
+// depthSnapshot 按当前模式请求深度快照，返回买卖盘与快照序列号（lastUpdateId）
+func (b *BinanceAdapter) depthSnapshot(ctx context.Context, symbol string, limit int) (bids, asks []orderbook.Level, updateID int64, err error) {
+	switch {
+	case b.spot != nil:
+		res, err := b.spot.NewDepthService().Symbol(symbol).Limit(limit).Do(ctx)
+		if err != nil {
+			return nil, nil, 0, classifyError(err)
+		}
+		return priceLevels(res.Bids), priceLevels(res.Asks), res.LastUpdateID, nil
+	case b.inverse != nil:
+		var res struct {
+			LastUpdateID int64      `json:"lastUpdateId"`
+			Bids         [][]string `json:"bids"`
+			Asks         [][]string `json:"asks"`
+		}
+		params := url.Values{"symbol": {symbol}, "limit": {strconv.Itoa(limit)}}
+		if err := b.inverseGet(ctx, "/dapi/v1/depth", params, false, &res); err != nil {
+			return nil, nil, 0, err
+		}
+		return orderbook.ParseLevels(res.Bids), orderbook.ParseLevels(res.Asks), res.LastUpdateID, nil
+	default:
+		res, err := b.client.NewDepthService().Symbol(symbol).Limit(limit).Do(ctx)
+		if err != nil {
+			return nil, nil, 0, classifyError(err)
+		}
+		return priceLevels(res.Bids), priceLevels(res.Asks), res.LastUpdateID, nil
+	}
+}
+
+// priceLevels 转换 SDK 的价位列表
+func priceLevels(levels []common.PriceLevel) []orderbook.Level {
+	raw := make([][]string, len(levels))
+	for i, l := range levels {
+		raw[i] = []string{l.Price, l.Quantity}
+	}
+	return orderbook.ParseLevels(raw)
+}
+
+// StartDepthStream 启动深度推送（<symbol>@depth@100ms），本地维护订单簿，每次更新后回调前 depthStreamLevels 档
+// 同步流程：先连接增量流并缓存事件，再拉取 REST 快照，丢弃早于快照的事件后依次应用；
+// 序列号断档（合约 pu 不等于上一条 u，现货 U 不等于上一条 u+1）时重新拉取快照，断线自动重连；
+// 短时间内反复断档或重连时快照请求按 resyncBackoff 退避，避免耗尽权重额度
+func (b *BinanceAdapter) StartDepthStream(ctx context.Context, symbol string, callback func(book *orderbook.Snapshot)) error {
+	url := fmt.Sprintf("%s/ws/%s@depth@100ms", b.wsManager.streamURL, strings.ToLower(symbol))
+	book := orderbook.NewBook(symbol)
+	backoff := &resyncBackoff{}
+	logger.Info("🔗 [Binance] 启动深度流: %s", symbol)
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				logger.Info("✅ [Binance] 深度流已停止")
+				return
+			default:
+			}
+
+			dialer := websocket.DefaultDialer
+			dialer.HandshakeTimeout = 10 * time.Second
+			conn, _, err := dialer.Dial(url, nil)
+			if err != nil {
+				logger.Error("❌ [Binance] 深度流连接失败: %v，5秒后重试", err)
+				time.Sleep(5 * time.Second)
+				continue
+			}
+
+			b.syncDepth(ctx, conn, symbol, book, backoff, callback)
+			conn.Close()
+			time.Sleep(2 * time.Second)
+		}
+	}()
+
+	return nil
+}
+
+// syncDepth 读取增量事件并维护本地订单簿，连接出错、快照失败或 context 取消时返回
+func (b *BinanceAdapter) syncDepth(ctx context.Context, conn *websocket.Conn, symbol string, book *orderbook.Book, backoff *resyncBackoff, callback func(book *orderbook.Snapshot)) {
+	events := make(chan *orderbook.Diff, depthEventBuffer)
+	done := make(chan struct{})
+	defer close(done)
+
+	// 读取协程：拉取快照期间增量事件在 events 中缓存
+	go func() {
+		defer close(events)
+		for {
+			conn.SetReadDeadline(time.Now().Add(30 * time.Second))
+			_, message, err := conn.ReadMessage()
+			if err != nil {
+				select {
+				case <-ctx.Done():
+				case <-done:
+				default:
+					logger.Warn("⚠️ [Binance] 深度流读取错误: %v，正在重连", err)
+				}
+				return
+			}
+			diff, ok := parseDepthEvent(message)
+			if !ok {
+				continue
+			}
+			select {
+			case events <- diff:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	// 重连后序列号不再连续，重新拉取快照
+	book.Reset(nil, nil, 0)
+	synced := false
+	for {
+		if !synced {
+			if delay := backoff.next(time.Now()); delay > 0 {
+				logger.Warn("⚠️ [Binance] %s 订单簿频繁重新同步，%v 后拉取快照", symbol, delay)
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(delay):
+				}
+			}
+			// 1000 档快照权重 20，与下单共用账户额度
+			if err := b.waitRequest(ctx, "GetOrderBook", depthSnapshotLimit); err != nil {
+				return
+			}
+			snapshotCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+			bids, asks, updateID, err := b.depthSnapshot(snapshotCtx, symbol, depthSnapshotLimit)
+			cancel()
+			if err != nil {
+				logger.Warn("⚠️ [Binance] 获取 %s 深度快照失败: %v，正在重连", symbol, err)
+				return
+			}
+			book.Reset(bids, asks, updateID)
+			synced = true
+			logger.Debug("[Binance] %s 订单簿已同步，快照序列号 %d", symbol, updateID)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case diff, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := book.ApplyDiff(diff); err != nil {
+				logger.Warn("⚠️ [Binance] %s %v，重新同步快照", symbol, err)
+				synced = false
+				continue
+			}
+			callback(book.Snapshot(depthStreamLevels))
+		}
+	}
+}
+
+// resyncBackoff 深度快照重新同步的退避（只在深度流协程内使用）
+type resyncBackoff struct {
+	delay time.Duration
+	last  time.Time
+}
+
+// next 返回本次拉取快照前需要等待的时间：距上次拉取超过 resyncStableAfter 时不等待，
+// 否则从 minResyncDelay 开始翻倍，上限 maxResyncDelay
+func (r *resyncBackoff) next(now time.Time) time.Duration {
+	switch {
+	case r.last.IsZero() || now.Sub(r.last) > resyncStableAfter:
+		r.delay = 0
+	case r.delay == 0:
+		r.delay = minResyncDelay
+	default:
+		r.delay *= 2
+		if r.delay > maxResyncDelay {
+			r.delay = maxResyncDelay
+		}
+	}
+	r.last = now.Add(r.delay)
+	return r.delay
+}
+
+// parseDepthEvent 解析增量深度事件（合约带 pu，现货没有）
+func parseDepthEvent(message []byte) (*orderbook.Diff, bool) {
+	var event struct {
+		EventType  string     `json:"e"`
+		FirstID    int64      `json:"U"`
+		LastID     int64      `json:"u"`
+		PrevLastID int64      `json:"pu"`
+		Bids       [][]string `json:"b"`
+		Asks       [][]string `json:"a"`
+	}
+	if err := json.Unmarshal(message, &event); err != nil || event.EventType != "depthUpdate" {
+		logger.Debug("[Binance] 忽略无法识别的深度消息: %s", string(message))
+		return nil, false
+	}
+	return &orderbook.Diff{
+		FirstID:    event.FirstID,
+		LastID:     event.LastID,
+		PrevLastID: event.PrevLastID,
+		Bids:       orderbook.ParseLevels(event.Bids),
+		Asks:       orderbook.ParseLevels(event.Asks),
+	}, true
+}
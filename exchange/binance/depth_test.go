@@ -0,0 +1,140 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"opensqt/exchange/orderbook"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestDepthStreamResyncOnGap(t *testing.T) {
+	var snapshots atomic.Int32
+	upgrader := websocket.Upgrader{}
+	adapter := newTestAdapter(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/fapi/v1/depth":
+			snapshots.Add(1)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"lastUpdateId": 100,
+				"bids":         [][]string{{"100.0", "1"}, {"99.0", "2"}},
+				"asks":         [][]string{{"101.0", "1"}},
+			})
+		case "/ws/ethusdt@depth@100ms":
+			conn, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				t.Errorf("升级 WebSocket 失败: %v", err)
+				return
+			}
+			defer conn.Close()
+			events := []map[string]interface{}{
+				{"e": "depthUpdate", "U": 98, "u": 101, "pu": 97, "b": [][]string{{"100.0", "0"}}, "a": [][]string{}},
+				{"e": "depthUpdate", "U": 102, "u": 103, "pu": 101, "b": [][]string{}, "a": [][]string{{"100.5", "3"}}},
+				{"e": "depthUpdate", "U": 205, "u": 206, "pu": 200, "b": [][]string{}, "a": [][]string{}}, // 断档
+				{"e": "depthUpdate", "U": 101, "u": 105, "pu": 100, "b": [][]string{{"99.5", "1"}}, "a": [][]string{}},
+			}
+			for _, e := range events {
+				conn.WriteJSON(e)
+			}
+			conn.ReadMessage() // 保持连接直到客户端关闭
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	adapter.wsManager = &WebSocketManager{streamURL: "ws" + strings.TrimPrefix(adapter.client.BaseURL, "http")}
+
+	books := make(chan *orderbook.Snapshot, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := adapter.StartDepthStream(ctx, "ETHUSDT", func(book *orderbook.Snapshot) { books <- book }); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []*orderbook.Snapshot
+	for len(got) < 3 {
+		select {
+		case book := <-books:
+			got = append(got, book)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("只收到 %d 次订单簿更新", len(got))
+		}
+	}
+
+	// 首条更新删除了 100.0 买单，第二条新增 100.5 卖单
+	if bid, ask := got[1].BestBid(), got[1].BestAsk(); bid != 99 || ask != 100.5 {
+		t.Errorf("第二次更新后 bid/ask = %v/%v, want 99/100.5", bid, ask)
+	}
+	// 断档后重新拉取快照，再应用后续更新
+	if n := snapshots.Load(); n != 2 {
+		t.Errorf("快照请求次数 = %d, want 2", n)
+	}
+	if bid, ask, id := got[2].BestBid(), got[2].BestAsk(), got[2].UpdateID; bid != 100 || ask != 101 || id != 105 {
+		t.Errorf("重新同步后 bid/ask/id = %v/%v/%d, want 100/101/105", bid, ask, id)
+	}
+}
+
+func TestResyncBackoff(t *testing.T) {
+	var backoff resyncBackoff
+	now := time.Now()
+	want := []time.Duration{0, time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 16 * time.Second, 30 * time.Second, 30 * time.Second}
+	for i, w := range want {
+		if got := backoff.next(now); got != w {
+			t.Fatalf("第 %d 次重新同步等待 %v, want %v", i+1, got, w)
+		}
+		now = now.Add(backoff.delay)
+	}
+	// 稳定运行一段时间后恢复为立即同步
+	if got := backoff.next(now.Add(resyncStableAfter + time.Second)); got != 0 {
+		t.Errorf("稳定后重新同步等待 %v, want 0", got)
+	}
+}
+
+func TestBookTickerStream(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	adapter := newTestAdapter(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ws/ethusdt@bookTicker" {
+			http.NotFound(w, r)
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("升级 WebSocket 失败: %v", err)
+			return
+		}
+		defer conn.Close()
+		// 合约推送带 e/T，现货推送没有 T
+		conn.WriteJSON(map[string]interface{}{"e": "bookTicker", "u": 1, "s": "ETHUSDT", "b": "2000.10", "B": "3.5", "a": "2000.20", "A": "1.2", "T": 1700000000000})
+		conn.WriteJSON(map[string]interface{}{"u": 2, "s": "ETHUSDT", "b": "2000.00", "B": "1", "a": "2000.30", "A": "2"})
+		conn.ReadMessage()
+	})
+	adapter.wsManager = &WebSocketManager{streamURL: "ws" + strings.TrimPrefix(adapter.client.BaseURL, "http")}
+
+	tickers := make(chan *orderbook.Ticker, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := adapter.StartBookTickerStream(ctx, "ETHUSDT", func(ticker *orderbook.Ticker) { tickers <- ticker }); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []*orderbook.Ticker
+	for len(got) < 2 {
+		select {
+		case ticker := <-tickers:
+			got = append(got, ticker)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("只收到 %d 次盘口推送", len(got))
+		}
+	}
+	if tk := got[0]; tk.BidPrice != 2000.1 || tk.BidQty != 3.5 || tk.AskPrice != 2000.2 || tk.AskQty != 1.2 || tk.Time != 1700000000000 {
+		t.Errorf("合约盘口 = %+v", tk)
+	}
+	if tk := got[1]; tk.Mid() != 2000.15 || tk.Time == 0 {
+		t.Errorf("现货盘口 = %+v, want mid 2000.15 且使用本地时间", tk)
+	}
+}
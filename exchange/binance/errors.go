@@ -0,0 +1,60 @@
+package binance
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"opensqt/exchange/errs"
+
+	"github.com/adshao/go-binance/v2/common"
+)
+
+// classifyError 将 Binance 原生错误码映射为统一错误分类
+// 参考：https://developers.binance.com/docs/derivatives/usds-margined-futures/error-code
+// 现货：https://developers.binance.com/docs/binance-spot-api-docs/errors
+func classifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var apiErr *common.APIError
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+
+	var kind error
+	switch apiErr.Code {
+	case -2019, -2018: // Margin is insufficient / Balance is insufficient
+		kind = errs.ErrInsufficientMargin
+	case -5022: // Post Only order will be rejected
+		kind = errs.ErrPostOnlyWouldCross
+	case -1003, -1015: // Too many requests / Too many new orders
+		kind = errs.ErrRateLimited
+	case -4061: // Order's position side does not match user's setting
+		kind = errs.ErrPositionModeMismatch
+	case -1021: // Timestamp outside of recvWindow
+		kind = errs.ErrClockSkew
+	case -2011, -2013: // Unknown order / Order does not exist
+		kind = errs.ErrOrderNotFound
+	case -4164: // Order's notional must be no smaller than 5
+		kind = errs.ErrMinNotional
+	case -2010: // 现货下单被拒绝，按错误信息区分原因
+		switch {
+		case strings.Contains(apiErr.Message, "insufficient balance"):
+			kind = errs.ErrInsufficientMargin
+		case strings.Contains(apiErr.Message, "immediately match"): // LIMIT_MAKER 会立即成交
+			kind = errs.ErrPostOnlyWouldCross
+		}
+	case -1013: // 现货 Filter failure: NOTIONAL / MIN_NOTIONAL
+		if strings.Contains(apiErr.Message, "NOTIONAL") {
+			kind = errs.ErrMinNotional
+		}
+	}
+	return errs.Wrap(kind, "binance", strconv.FormatInt(apiErr.Code, 10), err)
+}
+
+// isAPIErrorCode 判断是否为指定错误码的 Binance API 错误
+func isAPIErrorCode(err error, code int64) bool {
+	var apiErr *common.APIError
+	return errors.As(err, &apiErr) && apiErr.Code == code
+}
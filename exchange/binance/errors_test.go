@@ -0,0 +1,53 @@
+package binance
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"opensqt/exchange/errs"
+
+	"github.com/adshao/go-binance/v2/common"
+)
+
+func TestClassifyError(t *testing.T) {
+	cases := []struct {
+		code int64
+		want error
+	}{
+		{-2019, errs.ErrInsufficientMargin},
+		{-5022, errs.ErrPostOnlyWouldCross},
+		{-1003, errs.ErrRateLimited},
+		{-4061, errs.ErrPositionModeMismatch},
+		{-1021, errs.ErrClockSkew},
+		{-2011, errs.ErrOrderNotFound},
+		{-4164, errs.ErrMinNotional},
+	}
+	for _, c := range cases {
+		apiErr := &common.APIError{Code: c.code, Message: "test"}
+		err := classifyError(apiErr)
+		if !errors.Is(err, c.want) {
+			t.Errorf("code=%d: got %v, want %v", c.code, err, c.want)
+		}
+		var got *common.APIError
+		if !errors.As(err, &got) || got.Code != c.code {
+			t.Errorf("code=%d: 原始错误丢失", c.code)
+		}
+	}
+
+	// 现货下单拒绝（-2010）按错误信息区分原因
+	spotCases := map[string]error{
+		"Account has insufficient balance for requested action.": errs.ErrInsufficientMargin,
+		"Order would immediately match and take.":                errs.ErrPostOnlyWouldCross,
+	}
+	for msg, want := range spotCases {
+		if err := classifyError(&common.APIError{Code: -2010, Message: msg}); !errors.Is(err, want) {
+			t.Errorf("-2010 %q: got %v, want %v", msg, err, want)
+		}
+	}
+
+	plain := fmt.Errorf("网络错误")
+	if classifyError(plain) != plain {
+		t.Error("非 API 错误应原样返回")
+	}
+}
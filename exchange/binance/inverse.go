@@ -0,0 +1,685 @@
+package binance
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"opensqt/exchange/errs"
+	"opensqt/logger"
+	"opensqt/utils"
+
+	"github.com/adshao/go-binance/v2/common"
+	"github.com/adshao/go-binance/v2/delivery"
+)
+
+// 币本位合约行情流地址
+const (
+	inverseStreamURL        = "wss://dstream.binance.com"
+	inverseTestnetStreamURL = "wss://dstream.binancefuture.com"
+)
+
+// NewBinanceInverseAdapter 创建币安币本位合约适配器（交易对如 BTCUSD_PERP）
+// 币本位合约按张下单，每张面值固定（BTC 为 100 USD，其余一般为 10 USD），
+// 保证金、余额与盈亏均以基础币种计
+func NewBinanceInverseAdapter(cfg map[string]string, symbol string) (*BinanceAdapter, error) {
+	apiKey := cfg["api_key"]
+	secretKey := cfg["secret_key"]
+
+	if apiKey == "" || secretKey == "" {
+		return nil, fmt.Errorf("Binance API 配置不完整")
+	}
+	testnet, err := useEnvironment(cfg["environment"])
+	if err != nil {
+		return nil, err
+	}
+
+	client := delivery.NewClient(apiKey, secretKey)
+
+	wsManager := NewWebSocketManager(apiKey, secretKey)
+	wsManager.inverse = client
+	wsManager.streamURL = inverseStreamURL
+	if testnet {
+		wsManager.streamURL = inverseTestnetStreamURL
+	}
+
+	adapter := &BinanceAdapter{
+		inverse:   client,
+		symbol:    symbol,
+		wsManager: wsManager,
+		metas:     make(map[string]*symbolMeta),
+	}
+	adapter.startClock()
+
+	ctxInit, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := adapter.symbolMeta(ctxInit, symbol); err != nil {
+		logger.Warn("⚠️ [Binance 币本位] 获取合约信息失败: %v，使用默认精度", err)
+		adapter.metas[symbol] = &symbolMeta{
+			priceDecimals:    1,
+			quantityDecimals: 0,
+			stepSize:         1,
+		}
+	}
+
+	return adapter, nil
+}
+
+// IsInverse 是否为币本位合约适配器
+func (b *BinanceAdapter) IsInverse() bool {
+	return b.inverse != nil
+}
+
+// errInverseUnsupported 币本位合约不支持的功能
+func errInverseUnsupported(feature string) error {
+	return fmt.Errorf("Binance 币本位合约不支持%s", feature)
+}
+
+// inversePair 币本位交易对所属的标的（BTCUSD_PERP -> BTCUSD），持仓查询按标的过滤
+func inversePair(symbol string) string {
+	if i := strings.Index(symbol, "_"); i > 0 {
+		return symbol[:i]
+	}
+	return symbol
+}
+
+// inverseSymbolInfo 获取币本位合约交易规则（数量口径为张数）
+func (b *BinanceAdapter) inverseSymbolInfo(ctx context.Context, symbol string) (*SymbolInfo, error) {
+	exchangeInfo, err := b.inverse.NewExchangeInfoService().Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取交易所信息失败: %w", err)
+	}
+
+	for _, s := range exchangeInfo.Symbols {
+		if s.Symbol != symbol {
+			continue
+		}
+
+		info := &SymbolInfo{
+			Symbol:             s.Symbol,
+			BaseAsset:          s.BaseAsset,
+			QuoteAsset:         s.QuoteAsset,
+			ContractMultiplier: 1,
+			ContractValue:      float64(s.ContractSize),
+			PriceDecimals:      s.PricePrecision,
+			QuantityDecimals:   s.QuantityPrecision,
+		}
+
+		for _, filter := range s.Filters {
+			filterType, ok := filter["filterType"].(string)
+			if !ok {
+				continue
+			}
+
+			switch filterType {
+			case "PRICE_FILTER":
+				if tickSize, ok := filter["tickSize"].(string); ok {
+					info.TickSize, _ = strconv.ParseFloat(tickSize, 64)
+					info.PriceDecimals = countDecimalPlaces(info.TickSize)
+				}
+			case "LOT_SIZE":
+				if stepSize, ok := filter["stepSize"].(string); ok {
+					info.StepSize, _ = strconv.ParseFloat(stepSize, 64)
+					info.QuantityDecimals = countDecimalPlaces(info.StepSize)
+				}
+				if minQty, ok := filter["minQty"].(string); ok {
+					info.MinQty, _ = strconv.ParseFloat(minQty, 64)
+				}
+			}
+		}
+
+		if info.ContractValue <= 0 {
+			return nil, fmt.Errorf("%s 合约面值无效: %d", symbol, s.ContractSize)
+		}
+		return info, nil
+	}
+
+	return nil, fmt.Errorf("未找到合约信息: %s", symbol)
+}
+
+// inversePlaceOrder 币本位合约下单（数量为张数，最小下单量由 LOT_SIZE 限制，没有最小名义价值）
+func (b *BinanceAdapter) inversePlaceOrder(ctx context.Context, req *OrderRequest) (*Order, error) {
+	meta, err := b.symbolMeta(ctx, req.Symbol)
+	if err != nil {
+		return nil, fmt.Errorf("获取 %s 合约信息失败: %w", req.Symbol, err)
+	}
+
+	price := req.Price
+	if meta.tickSize > 0 {
+		price = alignToTickSize(price, meta.tickSize)
+	}
+	quantity := req.Quantity
+	if meta.stepSize > 0 {
+		quantity = alignToTickSize(quantity, meta.stepSize)
+	}
+	if quantity <= 0 {
+		return nil, fmt.Errorf("%w: 数量 %.4f 张不足 1 张", errs.ErrMinNotional, req.Quantity)
+	}
+
+	timeInForce := delivery.TimeInForceTypeGTC
+	if req.PostOnly {
+		timeInForce = delivery.TimeInForceTypeGTX
+	}
+
+	orderService := b.inverse.NewCreateOrderService().
+		Symbol(req.Symbol).
+		Side(delivery.SideType(req.Side)).
+		Type(delivery.OrderTypeLimit).
+		TimeInForce(timeInForce).
+		Quantity(fmt.Sprintf("%.*f", meta.quantityDecimals, quantity)).
+		Price(fmt.Sprintf("%.*f", meta.priceDecimals, price))
+	if req.ClientOrderID != "" {
+		orderService = orderService.NewClientOrderID(utils.AddBrokerPrefix("binance", req.ClientOrderID))
+	}
+	if req.PositionSide != "" {
+		orderService = orderService.PositionSide(delivery.PositionSideType(req.PositionSide))
+	} else if req.ReduceOnly {
+		orderService = orderService.ReduceOnly(true)
+	}
+
+	resp, err := orderService.Do(ctx)
+	if err != nil {
+		return nil, classifyError(err)
+	}
+
+	return &Order{
+		OrderID:       resp.OrderID,
+		ClientOrderID: resp.ClientOrderID,
+		Symbol:        req.Symbol,
+		Side:          req.Side,
+		Type:          req.Type,
+		Price:         req.Price,
+		Quantity:      req.Quantity,
+		Status:        OrderStatus(resp.Status),
+		CreatedAt:     time.Now(),
+		UpdateTime:    resp.UpdateTime,
+	}, nil
+}
+
+// inverseBatchPlaceOrders 币本位合约批量下单（逐单下单）
+func (b *BinanceAdapter) inverseBatchPlaceOrders(ctx context.Context, orders []*OrderRequest) ([]*Order, bool) {
+	placedOrders := make([]*Order, 0, len(orders))
+	hasMarginError := false
+
+	for _, req := range orders {
+		order, err := b.inversePlaceOrder(ctx, req)
+		if err != nil {
+			logger.Warn("⚠️ [Binance 币本位] 下单失败 %.2f %s: %v", req.Price, req.Side, err)
+			errs.Report(ctx, err)
+			if errors.Is(err, errs.ErrInsufficientMargin) {
+				hasMarginError = true
+			}
+			continue
+		}
+		placedOrders = append(placedOrders, order)
+	}
+
+	return placedOrders, hasMarginError
+}
+
+// inverseCancelOrder 币本位合约撤单
+func (b *BinanceAdapter) inverseCancelOrder(ctx context.Context, symbol string, orderID int64) error {
+	_, err := b.inverse.NewCancelOrderService().Symbol(symbol).OrderID(orderID).Do(ctx)
+	if err != nil {
+		err = classifyError(err)
+		if errors.Is(err, errs.ErrOrderNotFound) {
+			logger.Info("ℹ️ [Binance 币本位] 订单 %d 已不存在，跳过取消", orderID)
+			return nil
+		}
+		return err
+	}
+
+	logger.Info("✅ [Binance 币本位] 取消订单成功: %d", orderID)
+	return nil
+}
+
+// inverseBatchCancelOrders 币本位合约批量撤单（逐单撤销）
+func (b *BinanceAdapter) inverseBatchCancelOrders(ctx context.Context, symbol string, orderIDs []int64) error {
+	for i, orderID := range orderIDs {
+		if err := b.inverseCancelOrder(ctx, symbol, orderID); err != nil {
+			logger.Warn("⚠️ [Binance 币本位] 取消订单失败 %d: %v", orderID, err)
+		}
+		// 避免限频
+		if i < len(orderIDs)-1 {
+			time.Sleep(50 * time.Millisecond)
+		}
+	}
+	return nil
+}
+
+// convertInverseOrder 转换币本位合约订单
+func convertInverseOrder(order *delivery.Order) *Order {
+	price, _ := strconv.ParseFloat(order.Price, 64)
+	quantity, _ := strconv.ParseFloat(order.OrigQuantity, 64)
+	executedQty, _ := strconv.ParseFloat(order.ExecutedQuantity, 64)
+	avgPrice, _ := strconv.ParseFloat(order.AvgPrice, 64)
+
+	return &Order{
+		OrderID:       order.OrderID,
+		ClientOrderID: order.ClientOrderID,
+		Symbol:        order.Symbol,
+		Side:          Side(order.Side),
+		Type:          OrderType(order.Type),
+		Price:         price,
+		Quantity:      quantity,
+		ExecutedQty:   executedQty,
+		AvgPrice:      avgPrice,
+		Status:        OrderStatus(order.Status),
+		CreatedAt:     time.UnixMilli(order.Time),
+		UpdateTime:    order.UpdateTime,
+	}
+}
+
+// inverseGetOrder 查询币本位合约订单
+func (b *BinanceAdapter) inverseGetOrder(ctx context.Context, symbol string, orderID int64) (*Order, error) {
+	order, err := b.inverse.NewGetOrderService().Symbol(symbol).OrderID(orderID).Do(ctx)
+	if err != nil {
+		return nil, classifyError(err)
+	}
+	return convertInverseOrder(order), nil
+}
+
+// inverseGetOpenOrders 查询币本位合约未完成订单
+func (b *BinanceAdapter) inverseGetOpenOrders(ctx context.Context, symbol string) ([]*Order, error) {
+	orders, err := b.inverse.NewListOpenOrdersService().Symbol(symbol).Do(ctx)
+	if err != nil {
+		return nil, classifyError(err)
+	}
+
+	result := make([]*Order, 0, len(orders))
+	for _, order := range orders {
+		result = append(result, convertInverseOrder(order))
+	}
+	return result, nil
+}
+
+// inverseGetAccount 获取币本位合约账户信息（余额按默认交易对的保证金币种统计）
+func (b *BinanceAdapter) inverseGetAccount(ctx context.Context) (*Account, error) {
+	account, err := b.inverse.NewGetAccountService().Do(ctx)
+	if err != nil {
+		return nil, classifyError(err)
+	}
+
+	marginAsset := b.defaultMeta().baseAsset
+	result := &Account{}
+	for _, asset := range account.Assets {
+		if !strings.EqualFold(asset.Asset, marginAsset) {
+			continue
+		}
+		result.TotalWalletBalance, _ = strconv.ParseFloat(asset.WalletBalance, 64)
+		result.TotalMarginBalance, _ = strconv.ParseFloat(asset.MarginBalance, 64)
+		result.AvailableBalance, _ = strconv.ParseFloat(asset.AvailableBalance, 64)
+	}
+
+	for _, pos := range account.Positions {
+		posAmt, _ := strconv.ParseFloat(pos.PositionAmt, 64)
+		if posAmt == 0 {
+			continue
+		}
+		entryPrice, _ := strconv.ParseFloat(pos.EntryPrice, 64)
+		unrealizedPNL, _ := strconv.ParseFloat(pos.UnrealizedProfit, 64)
+		leverage, _ := strconv.Atoi(pos.Leverage)
+
+		result.Positions = append(result.Positions, &Position{
+			Symbol:        pos.Symbol,
+			Size:          posAmt,
+			EntryPrice:    entryPrice,
+			UnrealizedPNL: unrealizedPNL,
+			Leverage:      leverage,
+			Side:          hedgePositionSide(pos.PositionSide),
+		})
+	}
+
+	return result, nil
+}
+
+// inverseGetPositions 获取币本位合约持仓（持仓风险接口按标的查询，再按交易对过滤）
+func (b *BinanceAdapter) inverseGetPositions(ctx context.Context, symbol string) ([]*Position, error) {
+	positionRisks, err := b.inverse.NewGetPositionRiskService().Pair(inversePair(symbol)).Do(ctx)
+	if err != nil {
+		return nil, classifyError(err)
+	}
+
+	result := make([]*Position, 0)
+	for _, pos := range positionRisks {
+		if pos.Symbol != symbol {
+			continue
+		}
+		posAmt, _ := strconv.ParseFloat(pos.PositionAmt, 64)
+		entryPrice, _ := strconv.ParseFloat(pos.EntryPrice, 64)
+		unrealizedPNL, _ := strconv.ParseFloat(pos.UnRealizedProfit, 64)
+		markPrice, _ := strconv.ParseFloat(pos.MarkPrice, 64)
+		isolatedMargin, _ := strconv.ParseFloat(pos.IsolatedMargin, 64)
+		leverage, _ := strconv.Atoi(pos.Leverage)
+
+		result = append(result, &Position{
+			Symbol:         pos.Symbol,
+			Size:           posAmt,
+			EntryPrice:     entryPrice,
+			MarkPrice:      markPrice,
+			UnrealizedPNL:  unrealizedPNL,
+			Leverage:       leverage,
+			MarginType:     pos.MarginType,
+			IsolatedMargin: isolatedMargin,
+			Side:           hedgePositionSide(pos.PositionSide),
+		})
+	}
+
+	return result, nil
+}
+
+// inverseSetLeverage 设置币本位合约杠杆倍数
+func (b *BinanceAdapter) inverseSetLeverage(ctx context.Context, symbol string, leverage int) error {
+	_, err := b.inverse.NewChangeLeverageService().Symbol(symbol).Leverage(leverage).Do(ctx)
+	if err != nil {
+		return classifyError(err)
+	}
+	logger.Info("✅ [Binance 币本位] %s 杠杆倍数已设置为 %dx", symbol, leverage)
+	return nil
+}
+
+// inverseSetMarginType 设置币本位合约保证金模式，已是目标模式时视为成功
+func (b *BinanceAdapter) inverseSetMarginType(ctx context.Context, symbol string, isolated bool) error {
+	marginType := delivery.MarginTypeCrossed
+	if isolated {
+		marginType = delivery.MarginTypeIsolated
+	}
+	err := b.inverse.NewChangeMarginTypeService().Symbol(symbol).MarginType(marginType).Do(ctx)
+	if err != nil && !isAPIErrorCode(err, -4046) { // -4046: No need to change margin type
+		return classifyError(err)
+	}
+	logger.Info("✅ [Binance 币本位] %s 保证金模式: %s", symbol, marginType)
+	return nil
+}
+
+// inverseSetPositionMode 设置币本位合约持仓模式（与U本位合约分别设置），已是目标模式时视为成功
+func (b *BinanceAdapter) inverseSetPositionMode(ctx context.Context, hedge bool) error {
+	err := b.inverse.NewChangePositionModeService().DualSide(hedge).Do(ctx)
+	if err != nil && !isAPIErrorCode(err, -4059) { // -4059: No need to change position side
+		return classifyError(err)
+	}
+	mode := "单向持仓"
+	if hedge {
+		mode = "双向持仓"
+	}
+	logger.Info("✅ [Binance 币本位] 持仓模式: %s", mode)
+	return nil
+}
+
+// inverseGet 调用 SDK 未封装的币本位接口（premiumIndex、income），signed 为 true 时附加签名
+func (b *BinanceAdapter) inverseGet(ctx context.Context, endpoint string, params url.Values, signed bool, result interface{}) error {
+	header := http.Header{}
+	if signed {
+		params.Set("timestamp", strconv.FormatInt(b.clock.Now().UnixMilli(), 10))
+		mac := hmac.New(sha256.New, []byte(b.inverse.SecretKey))
+		mac.Write([]byte(params.Encode()))
+		params.Set("signature", hex.EncodeToString(mac.Sum(nil)))
+		header.Set("X-MBX-APIKEY", b.inverse.APIKey)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.inverse.BaseURL+endpoint+"?"+params.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header = header
+
+	resp, err := b.inverse.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		apiErr := new(common.APIError)
+		if json.Unmarshal(data, apiErr) != nil || !apiErr.IsValid() {
+			return fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(data))
+		}
+		return classifyError(apiErr)
+	}
+	return json.Unmarshal(data, result)
+}
+
+// inverseGetFundingRate 获取币本位合约当期资金费率（/dapi/v1/premiumIndex）
+func (b *BinanceAdapter) inverseGetFundingRate(ctx context.Context, symbol string) (*FundingRate, error) {
+	var indexes []struct {
+		Symbol          string `json:"symbol"`
+		MarkPrice       string `json:"markPrice"`
+		IndexPrice      string `json:"indexPrice"`
+		LastFundingRate string `json:"lastFundingRate"`
+		NextFundingTime int64  `json:"nextFundingTime"`
+		Time            int64  `json:"time"`
+	}
+	if err := b.inverseGet(ctx, "/dapi/v1/premiumIndex", url.Values{"symbol": {symbol}}, false, &indexes); err != nil {
+		return nil, err
+	}
+	if len(indexes) == 0 {
+		return nil, fmt.Errorf("未获取到 %s 的资金费率", symbol)
+	}
+
+	index := indexes[0]
+	fundingRate, _ := strconv.ParseFloat(index.LastFundingRate, 64)
+	markPrice, _ := strconv.ParseFloat(index.MarkPrice, 64)
+	indexPrice, _ := strconv.ParseFloat(index.IndexPrice, 64)
+	return &FundingRate{
+		Symbol:          index.Symbol,
+		FundingRate:     fundingRate,
+		MarkPrice:       markPrice,
+		IndexPrice:      indexPrice,
+		NextFundingTime: index.NextFundingTime,
+		Time:            index.Time,
+	}, nil
+}
+
+// inverseGetFundingPayments 获取币本位合约资金费收付记录（/dapi/v1/income，金额以基础币种计）
+func (b *BinanceAdapter) inverseGetFundingPayments(ctx context.Context, symbol string, since time.Time) ([]*FundingPayment, error) {
+	var incomes []struct {
+		Symbol string `json:"symbol"`
+		Income string `json:"income"`
+		Asset  string `json:"asset"`
+		Time   int64  `json:"time"`
+		TranID int64  `json:"tranId"`
+	}
+	params := url.Values{
+		"symbol":     {symbol},
+		"incomeType": {"FUNDING_FEE"},
+		"startTime":  {strconv.FormatInt(since.UnixMilli(), 10)},
+		"limit":      {"1000"},
+	}
+	if err := b.inverseGet(ctx, "/dapi/v1/income", params, true, &incomes); err != nil {
+		return nil, err
+	}
+
+	payments := make([]*FundingPayment, 0, len(incomes))
+	for _, income := range incomes {
+		amount, _ := strconv.ParseFloat(income.Income, 64)
+		payments = append(payments, &FundingPayment{
+			ID:     strconv.FormatInt(income.TranID, 10),
+			Symbol: income.Symbol,
+			Amount: amount,
+			Asset:  income.Asset,
+			Time:   income.Time,
+		})
+	}
+	return payments, nil
+}
+
+// inverseGetMyTrades 获取币本位合约成交记录（/dapi/v1/userTrades，数量为张数）
+func (b *BinanceAdapter) inverseGetMyTrades(ctx context.Context, symbol string, since time.Time, limit int) ([]*Trade, error) {
+	var trades []struct {
+		ID              int64  `json:"id"`
+		OrderID         int64  `json:"orderId"`
+		Symbol          string `json:"symbol"`
+		Side            string `json:"side"`
+		Price           string `json:"price"`
+		Qty             string `json:"qty"`
+		Commission      string `json:"commission"`
+		CommissionAsset string `json:"commissionAsset"`
+		Maker           bool   `json:"maker"`
+		Time            int64  `json:"time"`
+	}
+	params := url.Values{
+		"symbol":    {symbol},
+		"startTime": {strconv.FormatInt(since.UnixMilli(), 10)},
+		"limit":     {strconv.Itoa(limit)},
+	}
+	if err := b.inverseGet(ctx, "/dapi/v1/userTrades", params, true, &trades); err != nil {
+		return nil, err
+	}
+
+	result := make([]*Trade, 0, len(trades))
+	for _, t := range trades {
+		price, _ := strconv.ParseFloat(t.Price, 64)
+		qty, _ := strconv.ParseFloat(t.Qty, 64)
+		commission, _ := strconv.ParseFloat(t.Commission, 64)
+		result = append(result, &Trade{
+			TradeID:         strconv.FormatInt(t.ID, 10),
+			OrderID:         t.OrderID,
+			Symbol:          t.Symbol,
+			Side:            Side(t.Side),
+			Price:           price,
+			Quantity:        qty,
+			Commission:      commission,
+			CommissionAsset: t.CommissionAsset,
+			IsMaker:         t.Maker,
+			Time:            t.Time,
+		})
+	}
+	return result, nil
+}
+
+// inverseHistoricalKlines 获取币本位合约历史K线
+func (b *BinanceAdapter) inverseHistoricalKlines(ctx context.Context, symbol string, interval string, limit int) ([]*Candle, error) {
+	klines, err := b.inverse.NewKlinesService().Symbol(symbol).Interval(interval).Limit(limit).Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取历史K线失败: %w", err)
+	}
+
+	candles := make([]*Candle, 0, len(klines))
+	for _, k := range klines {
+		open, _ := strconv.ParseFloat(k.Open, 64)
+		high, _ := strconv.ParseFloat(k.High, 64)
+		low, _ := strconv.ParseFloat(k.Low, 64)
+		close, _ := strconv.ParseFloat(k.Close, 64)
+		volume, _ := strconv.ParseFloat(k.Volume, 64)
+
+		candles = append(candles, &Candle{
+			Symbol:    symbol,
+			Open:      open,
+			High:      high,
+			Low:       low,
+			Close:     close,
+			Volume:    volume,
+			Timestamp: k.OpenTime,
+			IsClosed:  true,
+		})
+	}
+	return candles, nil
+}
+
+// handleInverseUserDataEvent 处理币本位合约用户数据事件（字段与U本位合约一致）
+func (w *WebSocketManager) handleInverseUserDataEvent(event *delivery.WsUserDataEvent) {
+	if event.Event == delivery.UserDataEventTypeAccountUpdate {
+		w.handleInverseAccountUpdate(event)
+		return
+	}
+	if event.Event != delivery.UserDataEventTypeOrderTradeUpdate {
+		return
+	}
+
+	order := event.OrderTradeUpdate
+
+	executedQty, _ := strconv.ParseFloat(order.AccumulatedFilledQty, 64)
+	price, _ := strconv.ParseFloat(order.OriginalPrice, 64)
+	avgPrice, _ := strconv.ParseFloat(order.AveragePrice, 64)
+
+	update := OrderUpdate{
+		OrderID:       order.ID,
+		ClientOrderID: order.ClientOrderID,
+		Symbol:        order.Symbol,
+		Status:        OrderStatus(order.Status),
+		ExecutedQty:   executedQty,
+		Price:         price,
+		AvgPrice:      avgPrice,
+		Side:          Side(order.Side),
+		Type:          OrderType(order.Type),
+		UpdateTime:    order.TradeTime,
+	}
+
+	if order.ExecutionType == delivery.OrderExecutionTypeTrade {
+		lastQty, _ := strconv.ParseFloat(order.LastFilledQty, 64)
+		lastPrice, _ := strconv.ParseFloat(order.LastFilledPrice, 64)
+		commission, _ := strconv.ParseFloat(order.Commission, 64)
+		if lastQty > 0 {
+			update.Fill = &FillEvent{
+				TradeID:         strconv.FormatInt(order.TradeID, 10),
+				Price:           lastPrice,
+				Quantity:        lastQty,
+				Commission:      commission,
+				CommissionAsset: order.CommissionAsset,
+				IsMaker:         order.IsMaker,
+				Time:            order.TradeTime,
+			}
+		}
+	}
+
+	w.dispatchOrderUpdate(update)
+}
+
+// handleInverseAccountUpdate 处理币本位合约账户更新（余额为保证金币种的钱包余额）
+func (w *WebSocketManager) handleInverseAccountUpdate(event *delivery.WsUserDataEvent) {
+	w.mu.RLock()
+	callbacks := w.accountCallbacks
+	w.mu.RUnlock()
+	if len(callbacks) == 0 {
+		return
+	}
+
+	account := event.AccountUpdate
+	update := &AccountUpdate{UpdateTime: event.TransactionTime}
+	for _, b := range account.Balances {
+		walletBalance, _ := strconv.ParseFloat(b.Balance, 64)
+		update.Balances = append(update.Balances, BalanceUpdate{
+			Asset:         b.Asset,
+			WalletBalance: walletBalance,
+		})
+	}
+	for _, p := range account.Positions {
+		size, _ := strconv.ParseFloat(p.Amount, 64)
+		entryPrice, _ := strconv.ParseFloat(p.EntryPrice, 64)
+		markPrice, _ := strconv.ParseFloat(p.MarkPrice, 64)
+		unrealizedPNL, _ := strconv.ParseFloat(p.UnrealizedPnL, 64)
+		isolatedMargin, _ := strconv.ParseFloat(p.IsolatedWallet, 64)
+		update.Positions = append(update.Positions, &Position{
+			Symbol:         p.Symbol,
+			Size:           size,
+			EntryPrice:     entryPrice,
+			MarkPrice:      markPrice,
+			UnrealizedPNL:  unrealizedPNL,
+			MarginType:     string(p.MarginType),
+			IsolatedMargin: isolatedMargin,
+			Side:           hedgePositionSide(string(p.Side)),
+		})
+	}
+
+	logger.Debug("🔍 [Binance 币本位] 账户更新: 原因=%s, 资产 %d 项, 持仓 %d 项",
+		account.Reason, len(update.Balances), len(update.Positions))
+
+	for _, callback := range callbacks {
+		callback(update)
+	}
+}
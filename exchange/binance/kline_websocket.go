@@ -40,11 +40,13 @@ type KlineWebSocketManager struct {
 	pingInterval   time.Duration
 	pongWait       time.Duration
 	isRunning      bool
+	streamURL      string // 行情流地址（合约与现货不同）
 }
 
 // NewKlineWebSocketManager 创建K线WebSocket管理器
-func NewKlineWebSocketManager() *KlineWebSocketManager {
+func NewKlineWebSocketManager(streamURL string) *KlineWebSocketManager {
 	return &KlineWebSocketManager{
+		streamURL:      streamURL,
 		done:           make(chan struct{}),
 		callbacks:      make(map[string]func(candle interface{})),
 		reconnectDelay: 15 * time.Second, // 增加重连延迟，避免频繁重连
@@ -108,7 +110,7 @@ func (k *KlineWebSocketManager) connectLoop(ctx context.Context) {
 		for i, symbol := range k.symbols {
 			streams[i] = fmt.Sprintf("%s@kline_%s", strings.ToLower(symbol), k.interval)
 		}
-		wsURL := fmt.Sprintf("wss://fstream.binance.com/stream?streams=%s", strings.Join(streams, "/"))  // 使用多路复用流
+		wsURL := fmt.Sprintf("%s/stream?streams=%s", k.streamURL, strings.Join(streams, "/"))  // 使用多路复用流
 
 		logger.Info("🔗 正在连接 Binance K线WebSocket...")
 
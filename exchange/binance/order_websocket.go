@@ -0,0 +1,254 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"opensqt/exchange/wsapi"
+	"opensqt/logger"
+
+	"github.com/adshao/go-binance/v2/common"
+	wsapicommon "github.com/adshao/go-binance/v2/common/websocket"
+	"github.com/adshao/go-binance/v2/futures"
+	"github.com/gorilla/websocket"
+)
+
+// OrderWebSocketManager U本位合约 WebSocket 交易接口（ws-fapi）连接管理
+// 每个请求单独签名，不需要登录；断开后自动重连，连接不可用时由适配器改用 REST 下单
+// 连接设置读超时并定时 ping，请求超时时主动断开，避免半开连接让后续下单全部超时
+type OrderWebSocketManager struct {
+	apiKey    string
+	secretKey string
+	dial      func() (*websocket.Conn, error)
+
+	requestTimeout time.Duration // 单个请求等待响应的超时
+	pingInterval   time.Duration
+	readTimeout    time.Duration
+
+	conn    *websocket.Conn
+	mu      sync.RWMutex
+	writeMu sync.Mutex // 串行化写操作（并发下单）
+	pending wsapi.Pending
+
+	ctx            context.Context
+	cancel         context.CancelFunc
+	wg             sync.WaitGroup
+	reconnectDelay time.Duration
+}
+
+// NewOrderWebSocketManager 创建 WebSocket 交易接口管理器
+func NewOrderWebSocketManager(apiKey, secretKey string) *OrderWebSocketManager {
+	return &OrderWebSocketManager{
+		apiKey:         apiKey,
+		secretKey:      secretKey,
+		dial:           futures.WsApiInitReadWriteConn,
+		requestTimeout: wsapi.DefaultTimeout,
+		pingInterval:   wsapi.PingInterval,
+		readTimeout:    wsapi.ReadTimeout,
+		reconnectDelay: 5 * time.Second,
+	}
+}
+
+// Start 启动连接循环（自动重连）
+func (m *OrderWebSocketManager) Start(ctx context.Context) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.ctx != nil {
+		return
+	}
+	m.ctx, m.cancel = context.WithCancel(ctx)
+
+	m.wg.Add(1)
+	go m.connectLoop()
+}
+
+// Stop 关闭连接并停止重连
+func (m *OrderWebSocketManager) Stop() {
+	m.mu.Lock()
+	if m.cancel != nil {
+		m.cancel()
+	}
+	if m.conn != nil {
+		m.conn.Close()
+	}
+	m.mu.Unlock()
+
+	m.wg.Wait()
+
+	m.mu.Lock()
+	m.ctx, m.cancel = nil, nil
+	m.mu.Unlock()
+}
+
+// connectLoop 连接循环：连接后持续读取响应，断开后等待 reconnectDelay 重连
+func (m *OrderWebSocketManager) connectLoop() {
+	defer m.wg.Done()
+
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		default:
+		}
+
+		conn, err := m.dial()
+		if err != nil {
+			logger.Warn("⚠️ [Binance WS下单] 连接失败: %v，%v后重试（期间使用 REST 下单）", err, m.reconnectDelay)
+		} else {
+			m.mu.Lock()
+			m.conn = conn
+			m.mu.Unlock()
+			logger.Info("✅ [Binance WS下单] 已连接")
+
+			done := make(chan struct{})
+			go m.keepAlive(conn, done)
+			m.readLoop(conn)
+			close(done)
+
+			// 已发出未响应的下单请求状态未知，交由调用方对账
+			m.mu.Lock()
+			if m.conn == conn {
+				m.conn = nil
+			}
+			m.mu.Unlock()
+			conn.Close()
+			m.pending.FailAll(wsapi.ErrDisconnected)
+
+			logger.Warn("⚠️ [Binance WS下单] 连接断开，%v后重连...", m.reconnectDelay)
+		}
+
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-time.After(m.reconnectDelay):
+		}
+	}
+}
+
+// readLoop 读取响应并按 id 交给等待中的请求
+// 每收到一条消息、ping 或 pong 都顺延读超时，超时未收到任何数据时读取失败、触发重连
+func (m *OrderWebSocketManager) readLoop(conn *websocket.Conn) {
+	extend := func() {
+		conn.SetReadDeadline(time.Now().Add(m.readTimeout))
+	}
+	extend()
+	conn.SetPongHandler(func(string) error {
+		extend()
+		return nil
+	})
+	conn.SetPingHandler(func(appData string) error {
+		extend()
+		err := conn.WriteControl(websocket.PongMessage, []byte(appData), time.Now().Add(time.Second))
+		if err == websocket.ErrCloseSent {
+			return nil
+		}
+		return err
+	})
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			select {
+			case <-m.ctx.Done():
+			default:
+				logger.Warn("⚠️ [Binance WS下单] 读取消息失败: %v", err)
+			}
+			return
+		}
+
+		extend()
+
+		var resp futures.CreateOrderWsResponse
+		if err := json.Unmarshal(message, &resp); err != nil || resp.Id == "" {
+			logger.Debug("[Binance WS下单] 忽略无法识别的消息: %s", string(message))
+			continue
+		}
+		if resp.Error != nil {
+			m.pending.Resolve(resp.Id, nil, classifyError(resp.Error))
+			continue
+		}
+		result, err := json.Marshal(resp.Result.CreateOrderResponse)
+		m.pending.Resolve(resp.Id, result, err)
+	}
+}
+
+// keepAlive 定时发送 ping，写失败时关闭连接（读取循环随之退出并重连）
+func (m *OrderWebSocketManager) keepAlive(conn *websocket.Conn, done <-chan struct{}) {
+	ticker := time.NewTicker(m.pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(time.Second)); err != nil {
+				logger.Warn("⚠️ [Binance WS下单] Ping 失败: %v", err)
+				conn.Close()
+				return
+			}
+		}
+	}
+}
+
+// dropConn 请求超时说明连接可能已半开：立即停止使用该连接并关闭，由连接循环重连
+func (m *OrderWebSocketManager) dropConn(conn *websocket.Conn) {
+	m.mu.Lock()
+	if m.conn == conn {
+		m.conn = nil
+	}
+	m.mu.Unlock()
+	conn.Close()
+}
+
+// connected 连接是否已建立
+func (m *OrderWebSocketManager) connected() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.conn != nil
+}
+
+// PlaceOrder 通过 order.place 下单，timeOffset 为与服务器的时间差（毫秒）
+// 连接未建立或请求未能发出时返回 wsapi.ErrNotConnected，调用方可改用 REST；
+// 请求超时（非调用方取消）时断开当前连接并返回 wsapi.ErrTimeout，订单是否受理需由调用方查单确认
+func (m *OrderWebSocketManager) PlaceOrder(ctx context.Context, request *futures.OrderPlaceWsRequest, timeOffset int64) (*futures.CreateOrderResponse, error) {
+	m.mu.RLock()
+	conn := m.conn
+	m.mu.RUnlock()
+
+	if conn == nil {
+		return nil, fmt.Errorf("%w: 连接未建立", wsapi.ErrNotConnected)
+	}
+
+	data, err := m.pending.Do(ctx, m.requestTimeout, func(id string) error {
+		rawData, err := wsapicommon.CreateRequest(
+			wsapicommon.NewRequestData(id, m.apiKey, m.secretKey, timeOffset, common.KeyTypeHmac),
+			wsapicommon.OrderPlaceFuturesWsApiMethod,
+			request.GetParams(),
+		)
+		if err != nil {
+			return err
+		}
+
+		m.writeMu.Lock()
+		defer m.writeMu.Unlock()
+		return conn.WriteMessage(websocket.TextMessage, rawData)
+	})
+	if err != nil {
+		if errors.Is(err, wsapi.ErrTimeout) && ctx.Err() == nil {
+			logger.Warn("⚠️ [Binance WS下单] 请求超时，断开连接重连: %v", err)
+			m.dropConn(conn)
+		}
+		return nil, err
+	}
+
+	var resp futures.CreateOrderResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("解析下单响应失败: %w", err)
+	}
+	return &resp, nil
+}
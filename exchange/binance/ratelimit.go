@@ -0,0 +1,66 @@
+package binance
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RateLimitObserver 接收 REST 响应中的限频信息（由账户级请求额度管理实现）
+type RateLimitObserver interface {
+	// ObserveUsedWeight 当前分钟已用权重（X-MBX-USED-WEIGHT-1M）
+	ObserveUsedWeight(used int)
+	// ObserveRateLimited 收到 429（banned=false）或 418（banned=true），retryAfter 为 Retry-After
+	ObserveRateLimited(retryAfter time.Duration, banned bool)
+}
+
+// RequestLimiter 账户级请求额度（由 exchange.RateGovernor 实现）
+// 适配器内部自行发起的 REST 请求（如深度流重同步时拉取快照）不经过外层的额度包装，需在发出前等待
+type RequestLimiter interface {
+	WaitRequest(ctx context.Context, op string, n int) error
+}
+
+// rateLimitTransport 读取币安响应头中的已用权重，遇到 429/418 时按 Retry-After 通知暂停请求
+// 币安要求收到 429 后必须退避，继续请求会升级为 418 封禁 IP
+type rateLimitTransport struct {
+	base     http.RoundTripper
+	observer RateLimitObserver
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+
+	if used, err := strconv.Atoi(resp.Header.Get("X-Mbx-Used-Weight-1m")); err == nil {
+		t.observer.ObserveUsedWeight(used)
+	}
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusTeapot {
+		var retryAfter time.Duration
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			retryAfter = time.Duration(seconds) * time.Second
+		}
+		t.observer.ObserveRateLimited(retryAfter, resp.StatusCode == http.StatusTeapot)
+	}
+	return resp, nil
+}
+
+// SetRateLimitObserver 为当前模式的 REST 客户端接入限频观察
+func (b *BinanceAdapter) SetRateLimitObserver(observer RateLimitObserver) {
+	b.setTransport(&rateLimitTransport{base: http.DefaultTransport, observer: observer})
+}
+
+// SetRequestLimiter 接入账户级请求额度，适配器内部发起的 REST 请求按接口权重等待
+func (b *BinanceAdapter) SetRequestLimiter(limiter RequestLimiter) {
+	b.limiter = limiter
+}
+
+// waitRequest 等待内部请求的额度，未接入额度管理时直接返回
+func (b *BinanceAdapter) waitRequest(ctx context.Context, op string, n int) error {
+	if b.limiter == nil {
+		return nil
+	}
+	return b.limiter.WaitRequest(ctx, op, n)
+}
@@ -0,0 +1,64 @@
+package binance
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+// recordingObserver 记录限频通知
+type recordingObserver struct {
+	used       int
+	retryAfter time.Duration
+	banned     bool
+	limited    int
+}
+
+func (o *recordingObserver) ObserveUsedWeight(used int) { o.used = used }
+
+func (o *recordingObserver) ObserveRateLimited(retryAfter time.Duration, banned bool) {
+	o.limited++
+	o.retryAfter = retryAfter
+	o.banned = banned
+}
+
+func TestRateLimitTransport(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-MBX-USED-WEIGHT-1M", "1234")
+		if status != http.StatusOK {
+			w.Header().Set("Retry-After", "60")
+			w.WriteHeader(status)
+			w.Write([]byte(`{"code":-1003,"msg":"Too many requests"}`))
+			return
+		}
+		w.Write([]byte(`{"serverTime":1700000000000}`))
+	}))
+	defer server.Close()
+
+	observer := &recordingObserver{}
+	client := futures.NewClient("key", "secret")
+	client.BaseURL = server.URL
+	adapter := &BinanceAdapter{client: client}
+	adapter.SetRateLimitObserver(observer)
+
+	if _, err := client.NewServerTimeService().Do(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if observer.used != 1234 || observer.limited != 0 {
+		t.Errorf("应记录已用权重且不暂停, got used=%d limited=%d", observer.used, observer.limited)
+	}
+
+	status = http.StatusTeapot
+	if _, err := client.NewServerTimeService().Do(context.Background()); err == nil {
+		t.Fatal("418 应返回错误")
+	}
+	if observer.limited != 1 || !observer.banned || observer.retryAfter != time.Minute {
+		t.Errorf("418 应按 Retry-After 通知封禁, got limited=%d banned=%v retryAfter=%v",
+			observer.limited, observer.banned, observer.retryAfter)
+	}
+}
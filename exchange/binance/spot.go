@@ -0,0 +1,459 @@
+package binance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"opensqt/exchange/errs"
+	"opensqt/logger"
+	"opensqt/utils"
+
+	gobinance "github.com/adshao/go-binance/v2"
+)
+
+// 现货行情流地址
+const (
+	spotStreamURL        = "wss://stream.binance.com:9443"
+	spotTestnetStreamURL = "wss://stream.testnet.binance.vision"
+)
+
+// NewBinanceSpotAdapter 创建币安现货适配器
+// 现货没有杠杆与持仓：持仓以基础币种钱包余额表示，可用余额为计价币种的可用余额
+func NewBinanceSpotAdapter(cfg map[string]string, symbol string) (*BinanceAdapter, error) {
+	apiKey := cfg["api_key"]
+	secretKey := cfg["secret_key"]
+
+	if apiKey == "" || secretKey == "" {
+		return nil, fmt.Errorf("Binance API 配置不完整")
+	}
+	testnet, err := useEnvironment(cfg["environment"])
+	if err != nil {
+		return nil, err
+	}
+
+	client := gobinance.NewClient(apiKey, secretKey)
+
+	wsManager := NewWebSocketManager(apiKey, secretKey)
+	wsManager.spot = client
+	wsManager.streamURL = spotStreamURL
+	if testnet {
+		wsManager.streamURL = spotTestnetStreamURL
+	}
+
+	adapter := &BinanceAdapter{
+		spot:      client,
+		symbol:    symbol,
+		wsManager: wsManager,
+		metas:     make(map[string]*symbolMeta),
+	}
+	adapter.startClock()
+
+	ctxInit, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := adapter.symbolMeta(ctxInit, symbol); err != nil {
+		logger.Warn("⚠️ [Binance 现货] 获取交易对信息失败: %v，使用默认精度", err)
+		adapter.metas[symbol] = &symbolMeta{
+			priceDecimals:    2,
+			quantityDecimals: 5,
+			minNotional:      5.0,
+		}
+	}
+
+	return adapter, nil
+}
+
+// IsSpot 是否为现货适配器
+func (b *BinanceAdapter) IsSpot() bool {
+	return b.spot != nil
+}
+
+// errSpotUnsupported 现货不支持的合约功能
+func errSpotUnsupported(feature string) error {
+	return fmt.Errorf("Binance 现货不支持%s", feature)
+}
+
+// spotSymbolInfo 获取现货交易规则
+func (b *BinanceAdapter) spotSymbolInfo(ctx context.Context, symbol string) (*SymbolInfo, error) {
+	exchangeInfo, err := b.spot.NewExchangeInfoService().Symbol(symbol).Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取交易所信息失败: %w", err)
+	}
+
+	for _, s := range exchangeInfo.Symbols {
+		if s.Symbol != symbol {
+			continue
+		}
+
+		info := &SymbolInfo{
+			Symbol:             s.Symbol,
+			BaseAsset:          s.BaseAsset,
+			QuoteAsset:         s.QuoteAsset,
+			ContractMultiplier: 1,
+			MaxLeverage:        1,
+			PriceDecimals:      s.QuotePrecision,
+			QuantityDecimals:   s.BaseAssetPrecision,
+		}
+		if f := s.PriceFilter(); f != nil {
+			info.TickSize, _ = strconv.ParseFloat(f.TickSize, 64)
+			info.PriceDecimals = countDecimalPlaces(info.TickSize)
+		}
+		if f := s.LotSizeFilter(); f != nil {
+			info.StepSize, _ = strconv.ParseFloat(f.StepSize, 64)
+			info.QuantityDecimals = countDecimalPlaces(info.StepSize)
+			info.MinQty, _ = strconv.ParseFloat(f.MinQuantity, 64)
+		}
+		if f := s.NotionalFilter(); f != nil {
+			info.MinNotional, _ = strconv.ParseFloat(f.MinNotional, 64)
+		}
+		return info, nil
+	}
+
+	return nil, fmt.Errorf("未找到交易对信息: %s", symbol)
+}
+
+// spotPlaceOrder 现货下单（PostOnly 使用 LIMIT_MAKER 订单类型）
+func (b *BinanceAdapter) spotPlaceOrder(ctx context.Context, req *OrderRequest) (*Order, error) {
+	meta, err := b.symbolMeta(ctx, req.Symbol)
+	if err != nil {
+		return nil, fmt.Errorf("获取 %s 交易对信息失败: %w", req.Symbol, err)
+	}
+
+	price := req.Price
+	if meta.tickSize > 0 {
+		price = alignToTickSize(price, meta.tickSize)
+	}
+	quantity := req.Quantity
+	if meta.stepSize > 0 {
+		quantity = alignToTickSize(quantity, meta.stepSize)
+	}
+
+	notional := price * quantity
+	if notional < meta.minNotional {
+		return nil, fmt.Errorf("%w: %.2f %s 小于最小要求 %.2f (价格:%.4f × 数量:%.4f)", errs.ErrMinNotional, notional, meta.quoteAsset, meta.minNotional, price, quantity)
+	}
+
+	orderService := b.spot.NewCreateOrderService().
+		Symbol(req.Symbol).
+		Side(gobinance.SideType(req.Side)).
+		Quantity(fmt.Sprintf("%.*f", meta.quantityDecimals, quantity)).
+		Price(fmt.Sprintf("%.*f", meta.priceDecimals, price))
+	if req.PostOnly {
+		// LIMIT_MAKER：会立即成交时被拒绝，不能携带 timeInForce
+		orderService = orderService.Type(gobinance.OrderTypeLimitMaker)
+	} else {
+		orderService = orderService.Type(gobinance.OrderTypeLimit).TimeInForce(gobinance.TimeInForceTypeGTC)
+	}
+	if req.ClientOrderID != "" {
+		orderService = orderService.NewClientOrderID(utils.AddBrokerPrefix("binance", req.ClientOrderID))
+	}
+
+	resp, err := orderService.Do(ctx)
+	if err != nil {
+		return nil, classifyError(err)
+	}
+
+	return &Order{
+		OrderID:       resp.OrderID,
+		ClientOrderID: resp.ClientOrderID,
+		Symbol:        req.Symbol,
+		Side:          req.Side,
+		Type:          req.Type,
+		Price:         req.Price,
+		Quantity:      req.Quantity,
+		Status:        OrderStatus(resp.Status),
+		CreatedAt:     time.Now(),
+		UpdateTime:    resp.TransactTime,
+	}, nil
+}
+
+// spotBatchPlaceOrders 现货批量下单（现货没有批量下单接口，逐单下单）
+func (b *BinanceAdapter) spotBatchPlaceOrders(ctx context.Context, orders []*OrderRequest) ([]*Order, bool) {
+	placedOrders := make([]*Order, 0, len(orders))
+	hasMarginError := false
+
+	for _, req := range orders {
+		order, err := b.spotPlaceOrder(ctx, req)
+		if err != nil {
+			logger.Warn("⚠️ [Binance 现货] 下单失败 %.2f %s: %v", req.Price, req.Side, err)
+			errs.Report(ctx, err)
+			if errors.Is(err, errs.ErrInsufficientMargin) {
+				hasMarginError = true
+			}
+			continue
+		}
+		placedOrders = append(placedOrders, order)
+	}
+
+	return placedOrders, hasMarginError
+}
+
+// spotCancelOrder 现货撤单
+func (b *BinanceAdapter) spotCancelOrder(ctx context.Context, symbol string, orderID int64) error {
+	_, err := b.spot.NewCancelOrderService().Symbol(symbol).OrderID(orderID).Do(ctx)
+	if err != nil {
+		err = classifyError(err)
+		if errors.Is(err, errs.ErrOrderNotFound) {
+			logger.Info("ℹ️ [Binance 现货] 订单 %d 已不存在，跳过取消", orderID)
+			return nil
+		}
+		return err
+	}
+
+	logger.Info("✅ [Binance 现货] 取消订单成功: %d", orderID)
+	return nil
+}
+
+// spotBatchCancelOrders 现货批量撤单（逐单撤销）
+func (b *BinanceAdapter) spotBatchCancelOrders(ctx context.Context, symbol string, orderIDs []int64) error {
+	for i, orderID := range orderIDs {
+		if err := b.spotCancelOrder(ctx, symbol, orderID); err != nil {
+			logger.Warn("⚠️ [Binance 现货] 取消订单失败 %d: %v", orderID, err)
+		}
+		// 避免限频
+		if i < len(orderIDs)-1 {
+			time.Sleep(50 * time.Millisecond)
+		}
+	}
+	return nil
+}
+
+// convertSpotOrder 转换现货订单（平均成交价 = 累计成交额 / 累计成交量）
+func convertSpotOrder(order *gobinance.Order) *Order {
+	price, _ := strconv.ParseFloat(order.Price, 64)
+	quantity, _ := strconv.ParseFloat(order.OrigQuantity, 64)
+	executedQty, _ := strconv.ParseFloat(order.ExecutedQuantity, 64)
+	quoteQty, _ := strconv.ParseFloat(order.CummulativeQuoteQuantity, 64)
+
+	avgPrice := 0.0
+	if executedQty > 0 {
+		avgPrice = quoteQty / executedQty
+	}
+
+	return &Order{
+		OrderID:       order.OrderID,
+		ClientOrderID: order.ClientOrderID,
+		Symbol:        order.Symbol,
+		Side:          Side(order.Side),
+		Type:          OrderType(order.Type),
+		Price:         price,
+		Quantity:      quantity,
+		ExecutedQty:   executedQty,
+		AvgPrice:      avgPrice,
+		Status:        spotOrderStatus(string(order.Status)),
+		CreatedAt:     time.UnixMilli(order.Time),
+		UpdateTime:    order.UpdateTime,
+	}
+}
+
+// spotOrderStatus 转换现货订单状态（撮合时因自成交保护过期的订单视为已过期）
+func spotOrderStatus(status string) OrderStatus {
+	if status == "EXPIRED_IN_MATCH" {
+		return OrderStatusExpired
+	}
+	return OrderStatus(status)
+}
+
+// spotGetOrder 查询现货订单
+func (b *BinanceAdapter) spotGetOrder(ctx context.Context, symbol string, orderID int64) (*Order, error) {
+	order, err := b.spot.NewGetOrderService().Symbol(symbol).OrderID(orderID).Do(ctx)
+	if err != nil {
+		return nil, classifyError(err)
+	}
+	return convertSpotOrder(order), nil
+}
+
+// spotGetOpenOrders 查询现货未完成订单
+func (b *BinanceAdapter) spotGetOpenOrders(ctx context.Context, symbol string) ([]*Order, error) {
+	orders, err := b.spot.NewListOpenOrdersService().Symbol(symbol).Do(ctx)
+	if err != nil {
+		return nil, classifyError(err)
+	}
+
+	result := make([]*Order, 0, len(orders))
+	for _, order := range orders {
+		result = append(result, convertSpotOrder(order))
+	}
+	return result, nil
+}
+
+// spotGetMyTrades 获取现货成交记录（/api/v3/myTrades）
+func (b *BinanceAdapter) spotGetMyTrades(ctx context.Context, symbol string, since time.Time, limit int) ([]*Trade, error) {
+	trades, err := b.spot.NewListTradesService().Symbol(symbol).StartTime(since.UnixMilli()).Limit(limit).Do(ctx)
+	if err != nil {
+		return nil, classifyError(err)
+	}
+
+	result := make([]*Trade, 0, len(trades))
+	for _, t := range trades {
+		price, _ := strconv.ParseFloat(t.Price, 64)
+		qty, _ := strconv.ParseFloat(t.Quantity, 64)
+		commission, _ := strconv.ParseFloat(t.Commission, 64)
+		side := SideSell
+		if t.IsBuyer {
+			side = SideBuy
+		}
+		result = append(result, &Trade{
+			TradeID:         strconv.FormatInt(t.ID, 10),
+			OrderID:         t.OrderID,
+			Symbol:          t.Symbol,
+			Side:            side,
+			Price:           price,
+			Quantity:        qty,
+			Commission:      commission,
+			CommissionAsset: t.CommissionAsset,
+			IsMaker:         t.IsMaker,
+			Time:            t.Time,
+		})
+	}
+	return result, nil
+}
+
+// spotBalances 查询现货资产余额（资产 -> 可用、冻结）
+func (b *BinanceAdapter) spotBalances(ctx context.Context) (map[string][2]float64, error) {
+	account, err := b.spot.NewGetAccountService().OmitZeroBalances(true).Do(ctx)
+	if err != nil {
+		return nil, classifyError(err)
+	}
+
+	balances := make(map[string][2]float64, len(account.Balances))
+	for _, balance := range account.Balances {
+		free, _ := strconv.ParseFloat(balance.Free, 64)
+		locked, _ := strconv.ParseFloat(balance.Locked, 64)
+		balances[balance.Asset] = [2]float64{free, locked}
+	}
+	return balances, nil
+}
+
+// spotGetAccount 获取现货账户信息
+// 钱包余额与可用余额均按计价币种统计，持仓为默认交易对基础币种的钱包余额
+func (b *BinanceAdapter) spotGetAccount(ctx context.Context) (*Account, error) {
+	balances, err := b.spotBalances(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := b.defaultMeta()
+	quote := balances[meta.quoteAsset]
+	account := &Account{
+		TotalWalletBalance: quote[0] + quote[1],
+		TotalMarginBalance: quote[0] + quote[1],
+		AvailableBalance:   quote[0],
+	}
+	if base := balances[meta.baseAsset]; base[0]+base[1] > 0 {
+		account.Positions = []*Position{{Symbol: b.symbol, Size: base[0] + base[1], Leverage: 1}}
+	}
+	return account, nil
+}
+
+// spotGetPositions 现货持仓：基础币种的钱包余额（可用 + 挂单冻结）
+func (b *BinanceAdapter) spotGetPositions(ctx context.Context, symbol string) ([]*Position, error) {
+	meta, err := b.symbolMeta(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	balances, err := b.spotBalances(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	base := balances[meta.baseAsset]
+	return []*Position{{Symbol: symbol, Size: base[0] + base[1], Leverage: 1}}, nil
+}
+
+// spotGetBalance 获取现货资产的可用余额（不含挂单冻结部分）
+func (b *BinanceAdapter) spotGetBalance(ctx context.Context, asset string) (float64, error) {
+	balances, err := b.spotBalances(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return balances[strings.ToUpper(asset)][0], nil
+}
+
+// spotHistoricalKlines 获取现货历史K线
+func (b *BinanceAdapter) spotHistoricalKlines(ctx context.Context, symbol string, interval string, limit int) ([]*Candle, error) {
+	klines, err := b.spot.NewKlinesService().Symbol(symbol).Interval(interval).Limit(limit).Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取历史K线失败: %w", err)
+	}
+
+	candles := make([]*Candle, 0, len(klines))
+	for _, k := range klines {
+		open, _ := strconv.ParseFloat(k.Open, 64)
+		high, _ := strconv.ParseFloat(k.High, 64)
+		low, _ := strconv.ParseFloat(k.Low, 64)
+		close, _ := strconv.ParseFloat(k.Close, 64)
+		volume, _ := strconv.ParseFloat(k.Volume, 64)
+
+		candles = append(candles, &Candle{
+			Symbol:    symbol,
+			Open:      open,
+			High:      high,
+			Low:       low,
+			Close:     close,
+			Volume:    volume,
+			Timestamp: k.OpenTime,
+			IsClosed:  true,
+		})
+	}
+	return candles, nil
+}
+
+// handleSpotUserDataEvent 处理现货用户数据事件（executionReport）
+func (w *WebSocketManager) handleSpotUserDataEvent(event *gobinance.WsUserDataEvent) {
+	if event.Event != gobinance.UserDataEventTypeExecutionReport {
+		return
+	}
+
+	order := event.OrderUpdate
+
+	executedQty, _ := strconv.ParseFloat(order.FilledVolume, 64)
+	quoteQty, _ := strconv.ParseFloat(order.FilledQuoteVolume, 64)
+	price, _ := strconv.ParseFloat(order.Price, 64)
+	avgPrice := 0.0
+	if executedQty > 0 {
+		avgPrice = quoteQty / executedQty
+	}
+
+	// 撤单推送中 c 为撤单请求的ID，原订单的自定义ID在 C 中
+	clientOrderID := order.ClientOrderId
+	if order.OrigCustomOrderId != "" {
+		clientOrderID = order.OrigCustomOrderId
+	}
+
+	update := OrderUpdate{
+		OrderID:       order.Id,
+		ClientOrderID: clientOrderID,
+		Symbol:        order.Symbol,
+		Status:        spotOrderStatus(order.Status),
+		ExecutedQty:   executedQty,
+		Price:         price,
+		AvgPrice:      avgPrice,
+		Side:          Side(order.Side),
+		Type:          OrderType(order.Type),
+		UpdateTime:    order.TransactionTime,
+	}
+
+	if order.ExecutionType == "TRADE" {
+		lastQty, _ := strconv.ParseFloat(order.LatestVolume, 64)
+		lastPrice, _ := strconv.ParseFloat(order.LatestPrice, 64)
+		commission, _ := strconv.ParseFloat(order.FeeCost, 64)
+		if lastQty > 0 {
+			update.Fill = &FillEvent{
+				TradeID:         strconv.FormatInt(order.TradeId, 10),
+				Price:           lastPrice,
+				Quantity:        lastQty,
+				Commission:      commission,
+				CommissionAsset: order.FeeAsset,
+				IsMaker:         order.IsMaker,
+				Time:            order.TransactionTime,
+			}
+		}
+	}
+
+	w.dispatchOrderUpdate(update)
+}
@@ -0,0 +1,71 @@
+package binance
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"opensqt/exchange/clock"
+
+	"github.com/adshao/go-binance/v2/common"
+)
+
+// clockTransport 用对时器的时间为签名请求重新打时间戳并签名
+// go-binance 签名时不加锁地读取客户端的 TimeOffset，由对时协程改写会产生数据竞争；
+// 因此 TimeOffset 保持为 0，请求发出前把 timestamp 换成 clock.Now() 再按库的规则重新签名
+// （签名原文 = 按键排序的查询串 + 表单体，signature 追加在查询串末尾）
+type clockTransport struct {
+	base      http.RoundTripper
+	secretKey string
+	clock     *clock.Estimator
+}
+
+func (t *clockTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	query := req.URL.Query()
+	if query.Get("signature") == "" || query.Get("timestamp") == "" {
+		return t.base.RoundTrip(req)
+	}
+
+	var body []byte
+	if req.GetBody != nil {
+		rc, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		body, err = io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	query.Del("signature")
+	query.Set("timestamp", strconv.FormatInt(t.clock.Now().UnixMilli(), 10))
+	queryString := query.Encode()
+	sign, err := common.SignFunc(common.KeyTypeHmac)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := sign(t.secretKey, queryString+string(body))
+	if err != nil {
+		return nil, fmt.Errorf("重新签名失败: %w", err)
+	}
+
+	signed := req.Clone(req.Context())
+	signed.URL.RawQuery = queryString + "&signature=" + *signature
+	return t.base.RoundTrip(signed)
+}
+
+// setTransport 为当前模式的 REST 客户端设置传输层，签名请求统一经过 clockTransport
+// go-binance 默认使用 http.DefaultClient，这里替换为独立的客户端，不影响进程内其他 HTTP 请求
+func (b *BinanceAdapter) setTransport(base http.RoundTripper) {
+	switch {
+	case b.spot != nil:
+		b.spot.HTTPClient = &http.Client{Transport: &clockTransport{base: base, secretKey: b.spot.SecretKey, clock: b.clock}}
+	case b.inverse != nil:
+		b.inverse.HTTPClient = &http.Client{Transport: &clockTransport{base: base, secretKey: b.inverse.SecretKey, clock: b.clock}}
+	default:
+		b.client.HTTPClient = &http.Client{Transport: &clockTransport{base: base, secretKey: b.client.SecretKey, clock: b.clock}}
+	}
+}
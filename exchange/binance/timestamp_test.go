@@ -0,0 +1,72 @@
+package binance
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"opensqt/exchange/clock"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+// 签名请求的时间戳取自对时器，签名按重写后的参数重新计算；客户端的 TimeOffset 不被改写
+func TestClockTransportRestampsSignedRequests(t *testing.T) {
+	serverNow := time.Now().Add(time.Hour)
+	var gotTimestamp int64
+	var signatureOK bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/fapi/v1/time":
+			w.Write([]byte(`{"serverTime":` + strconv.FormatInt(serverNow.UnixMilli(), 10) + `}`))
+		case "/fapi/v1/order":
+			body, _ := io.ReadAll(r.Body)
+			rawQuery := r.URL.RawQuery
+			idx := strings.LastIndex(rawQuery, "&signature=")
+			mac := hmac.New(sha256.New, []byte("secret"))
+			mac.Write([]byte(rawQuery[:idx] + string(body)))
+			signatureOK = idx > 0 && rawQuery[idx+len("&signature="):] == hex.EncodeToString(mac.Sum(nil))
+			gotTimestamp, _ = strconv.ParseInt(r.URL.Query().Get("timestamp"), 10, 64)
+			w.Write([]byte(`{"orderId":1,"status":"NEW"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := futures.NewClient("key", "secret")
+	client.BaseURL = server.URL
+	adapter := &BinanceAdapter{client: client}
+	adapter.clock = clock.NewEstimator("Binance", func(ctx context.Context) (time.Time, error) {
+		ms, err := client.NewServerTimeService().Do(ctx)
+		return time.UnixMilli(ms), err
+	})
+	adapter.setTransport(http.DefaultTransport)
+	if err := adapter.clock.Sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := client.NewCreateOrderService().Symbol("ETHUSDT").Side(futures.SideTypeBuy).
+		Type(futures.OrderTypeLimit).TimeInForce(futures.TimeInForceTypeGTC).
+		Price("3000").Quantity("0.01").Do(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !signatureOK {
+		t.Error("重写时间戳后签名校验失败")
+	}
+	if diff := time.UnixMilli(gotTimestamp).Sub(serverNow); diff < -time.Minute || diff > time.Minute {
+		t.Errorf("timestamp 应接近服务器时间, 偏差 %v", diff)
+	}
+	if client.TimeOffset != 0 {
+		t.Errorf("不应改写 go-binance 的 TimeOffset, got %d", client.TimeOffset)
+	}
+}
@@ -9,15 +9,29 @@ import (
 	"sync"
 	"time"
 
+	"opensqt/exchange/clock"
+	"opensqt/exchange/orderbook"
 	"opensqt/logger"
 
+	gobinance "github.com/adshao/go-binance/v2"
+	"github.com/adshao/go-binance/v2/common"
+	"github.com/adshao/go-binance/v2/delivery"
 	"github.com/adshao/go-binance/v2/futures"
 	"github.com/gorilla/websocket"
 )
 
+// U本位合约行情流地址
+const (
+	futuresStreamURL        = "wss://fstream.binance.com"
+	futuresTestnetStreamURL = "wss://stream.binancefuture.com"
+)
+
 // WebSocketManager 币安 WebSocket 订单流管理器
 type WebSocketManager struct {
 	client    *futures.Client
+	spot      *gobinance.Client // 现货客户端，非 nil 时连接现货用户数据流
+	inverse   *delivery.Client  // 币本位合约客户端，非 nil 时连接币本位用户数据流
+	clock     *clock.Estimator  // 服务器时间偏差（现货签名订阅使用），由适配器设置
 	apiKey    string
 	secretKey string
 	listenKey string
@@ -27,9 +41,13 @@ type WebSocketManager struct {
 	callbacks []OrderUpdateCallback
 	isRunning bool
 
-	// 价格缓存
-	latestPrice float64
-	priceMu     sync.RWMutex
+	// 账户推送回调（与订单流共用用户数据流）
+	accountCallbacks []AccountUpdateCallback
+
+	// 价格缓存（交易对 -> 最新价格，每个交易对一条价格流）
+	latestPrices map[string]float64
+	priceMu      sync.RWMutex
+	streamURL    string // 行情流地址（U本位、币本位与现货不同）
 
 	// 时间配置
 	reconnectDelay    time.Duration
@@ -46,6 +64,8 @@ func NewWebSocketManager(apiKey, secretKey string) *WebSocketManager {
 		doneC:             make(chan struct{}),
 		stopC:             make(chan struct{}),
 		callbacks:         make([]OrderUpdateCallback, 0),
+		latestPrices:      make(map[string]float64),
+		streamURL:         futuresStreamURL,
 		reconnectDelay:    5 * time.Second,
 		keepAliveInterval: 30 * time.Minute,
 		closeTimeout:      10 * time.Second,
@@ -54,25 +74,48 @@ func NewWebSocketManager(apiKey, secretKey string) *WebSocketManager {
 
 // Start 启动WebSocket连接
 func (w *WebSocketManager) Start(ctx context.Context, callback OrderUpdateCallback) error {
+	w.mu.Lock()
+	w.callbacks = append(w.callbacks, callback)
+	w.mu.Unlock()
+
+	return w.startUserStream(ctx)
+}
+
+// StartAccountStream 注册账户推送回调（ACCOUNT_UPDATE），用户数据流未建立时建立连接
+func (w *WebSocketManager) StartAccountStream(ctx context.Context, callback AccountUpdateCallback) error {
+	w.mu.Lock()
+	w.accountCallbacks = append(w.accountCallbacks, callback)
+	w.mu.Unlock()
+
+	return w.startUserStream(ctx)
+}
+
+// startUserStream 建立用户数据流（订单与账户推送共用一个 listenKey），已建立时直接返回
+func (w *WebSocketManager) startUserStream(ctx context.Context) error {
 	w.mu.Lock()
 	if w.isRunning {
 		w.mu.Unlock()
-		return fmt.Errorf("订单流已在运行")
+		return nil
 	}
 	w.isRunning = true
-	w.callbacks = append(w.callbacks, callback)
 	w.mu.Unlock()
 
-	// 获取listenKey
-	listenKey, err := w.client.NewStartUserStreamService().Do(ctx)
-	if err != nil {
-		return fmt.Errorf("获取listenKey失败: %v", err)
-	}
-	w.listenKey = listenKey
-	logger.Debug("✅ [Binance] 已获取订单流listenKey: %s", listenKey)
+	// 现货用户数据流使用签名订阅，不需要 listenKey
+	if w.spot == nil {
+		// 获取listenKey
+		listenKey, err := w.newListenKey(ctx)
+		if err != nil {
+			w.mu.Lock()
+			w.isRunning = false
+			w.mu.Unlock()
+			return fmt.Errorf("获取listenKey失败: %v", err)
+		}
+		w.listenKey = listenKey
+		logger.Debug("✅ [Binance] 已获取订单流listenKey: %s", listenKey)
 
-	// 启动listenKey保活协程
-	go w.keepAliveListenKey(ctx)
+		// 启动listenKey保活协程
+		go w.keepAliveListenKey(ctx)
+	}
 
 	// 启动WebSocket监听
 	go w.listenUserDataStream(ctx)
@@ -86,7 +129,7 @@ func (w *WebSocketManager) StartPriceStream(ctx context.Context, symbol string,
 	// 格式: wss://fstream.binance.com/ws/<symbol>@aggTrade
 
 	symbolLower := strings.ToLower(symbol)
-	url := fmt.Sprintf("wss://fstream.binance.com/ws/%s@aggTrade", symbolLower)
+	url := fmt.Sprintf("%s/ws/%s@aggTrade", w.streamURL, symbolLower)
 
 	// 使用通道等待首个价格
 	firstPriceCh := make(chan struct{})
@@ -182,7 +225,7 @@ func (w *WebSocketManager) StartPriceStream(ctx context.Context, symbol string,
 
 				// 更新价格缓存
 				w.priceMu.Lock()
-				w.latestPrice = price
+				w.latestPrices[strings.ToUpper(symbol)] = price
 				w.priceMu.Unlock()
 
 				// 通知首个价格已接收（只执行一次）
@@ -210,7 +253,157 @@ func (w *WebSocketManager) StartPriceStream(ctx context.Context, symbol string,
 	case <-ctx.Done():
 		return fmt.Errorf("上下文已取消")
 	}
-} // Stop 停止WebSocket
+}
+
+// StartMarkPriceStream 启动标记价格流（<symbol>@markPrice@1s，断线自动重连）
+func (w *WebSocketManager) StartMarkPriceStream(ctx context.Context, symbol string, callback MarkPriceCallback) error {
+	url := fmt.Sprintf("%s/ws/%s@markPrice@1s", w.streamURL, strings.ToLower(symbol))
+	logger.Info("🔗 [Binance] 启动标记价格流: %s", symbol)
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				logger.Info("✅ [Binance] 标记价格流已停止")
+				return
+			default:
+			}
+
+			dialer := websocket.DefaultDialer
+			dialer.HandshakeTimeout = 10 * time.Second
+			conn, _, err := dialer.Dial(url, nil)
+			if err != nil {
+				logger.Error("❌ [Binance] 标记价格流连接失败: %v，5秒后重试", err)
+				time.Sleep(5 * time.Second)
+				continue
+			}
+
+			w.readMarkPrice(ctx, conn, callback)
+			conn.Close()
+			time.Sleep(2 * time.Second)
+		}
+	}()
+
+	return nil
+}
+
+// readMarkPrice 读取标记价格推送，连接出错或 context 取消时返回
+func (w *WebSocketManager) readMarkPrice(ctx context.Context, conn *websocket.Conn, callback MarkPriceCallback) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		conn.SetReadDeadline(time.Now().Add(30 * time.Second))
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			logger.Warn("⚠️ [Binance] 标记价格流读取错误: %v，正在重连", err)
+			return
+		}
+
+		var event futures.WsMarkPriceEvent
+		if err := json.Unmarshal(message, &event); err != nil {
+			logger.Debug("解析标记价格失败: %v", err)
+			continue
+		}
+
+		markPrice, _ := strconv.ParseFloat(event.MarkPrice, 64)
+		indexPrice, _ := strconv.ParseFloat(event.IndexPrice, 64)
+		fundingRate, _ := strconv.ParseFloat(event.FundingRate, 64)
+		if markPrice <= 0 {
+			continue
+		}
+		callback(&FundingRate{
+			Symbol:          event.Symbol,
+			FundingRate:     fundingRate,
+			MarkPrice:       markPrice,
+			IndexPrice:      indexPrice,
+			NextFundingTime: event.NextFundingTime,
+			Time:            event.Time,
+		})
+	}
+}
+
+// StartBookTickerStream 启动盘口最优价流（<symbol>@bookTicker，实时推送，断线自动重连）
+func (w *WebSocketManager) StartBookTickerStream(ctx context.Context, symbol string, callback func(ticker *orderbook.Ticker)) error {
+	url := fmt.Sprintf("%s/ws/%s@bookTicker", w.streamURL, strings.ToLower(symbol))
+	logger.Info("🔗 [Binance] 启动盘口最优价流: %s", symbol)
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				logger.Info("✅ [Binance] 盘口最优价流已停止")
+				return
+			default:
+			}
+
+			dialer := websocket.DefaultDialer
+			dialer.HandshakeTimeout = 10 * time.Second
+			conn, _, err := dialer.Dial(url, nil)
+			if err != nil {
+				logger.Error("❌ [Binance] 盘口最优价流连接失败: %v，5秒后重试", err)
+				time.Sleep(5 * time.Second)
+				continue
+			}
+
+			w.readBookTicker(ctx, conn, callback)
+			conn.Close()
+			time.Sleep(2 * time.Second)
+		}
+	}()
+
+	return nil
+}
+
+// readBookTicker 读取盘口最优价推送，连接出错或 context 取消时返回
+func (w *WebSocketManager) readBookTicker(ctx context.Context, conn *websocket.Conn, callback func(ticker *orderbook.Ticker)) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		conn.SetReadDeadline(time.Now().Add(30 * time.Second))
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			logger.Warn("⚠️ [Binance] 盘口最优价流读取错误: %v，正在重连", err)
+			return
+		}
+
+		// 合约推送带 e/T 字段，现货只有 u/s/b/B/a/A
+		var event struct {
+			Symbol   string `json:"s"`
+			BidPrice string `json:"b"`
+			BidQty   string `json:"B"`
+			AskPrice string `json:"a"`
+			AskQty   string `json:"A"`
+			Time     int64  `json:"T"`
+		}
+		if err := json.Unmarshal(message, &event); err != nil {
+			logger.Debug("解析盘口最优价失败: %v", err)
+			continue
+		}
+
+		ticker := &orderbook.Ticker{Symbol: event.Symbol, Time: event.Time}
+		ticker.BidPrice, _ = strconv.ParseFloat(event.BidPrice, 64)
+		ticker.BidQty, _ = strconv.ParseFloat(event.BidQty, 64)
+		ticker.AskPrice, _ = strconv.ParseFloat(event.AskPrice, 64)
+		ticker.AskQty, _ = strconv.ParseFloat(event.AskQty, 64)
+		if ticker.BidPrice <= 0 || ticker.AskPrice <= 0 {
+			continue
+		}
+		if ticker.Time == 0 {
+			ticker.Time = time.Now().UnixMilli()
+		}
+		callback(ticker)
+	}
+}
+
+// Stop 停止WebSocket
 func (w *WebSocketManager) Stop() {
 	w.mu.Lock()
 	defer w.mu.Unlock()
@@ -244,7 +437,7 @@ func (w *WebSocketManager) keepAliveListenKey(ctx context.Context) {
 		case <-w.stopC:
 			return
 		case <-ticker.C:
-			if err := w.client.NewKeepaliveUserStreamService().ListenKey(w.listenKey).Do(ctx); err != nil {
+			if err := w.keepAlive(ctx); err != nil {
 				logger.Error("❌ [Binance] listenKey保活失败: %v", err)
 			} else {
 				logger.Debug("✅ [Binance] listenKey保活成功")
@@ -253,6 +446,22 @@ func (w *WebSocketManager) keepAliveListenKey(ctx context.Context) {
 	}
 }
 
+// newListenKey 创建用户数据流 listenKey（U本位与币本位合约接口不同）
+func (w *WebSocketManager) newListenKey(ctx context.Context) (string, error) {
+	if w.inverse != nil {
+		return w.inverse.NewStartUserStreamService().Do(ctx)
+	}
+	return w.client.NewStartUserStreamService().Do(ctx)
+}
+
+// keepAlive 延长 listenKey 有效期
+func (w *WebSocketManager) keepAlive(ctx context.Context) error {
+	if w.inverse != nil {
+		return w.inverse.NewKeepaliveUserStreamService().ListenKey(w.listenKey).Do(ctx)
+	}
+	return w.client.NewKeepaliveUserStreamService().ListenKey(w.listenKey).Do(ctx)
+}
+
 // listenUserDataStream 监听用户数据流
 func (w *WebSocketManager) listenUserDataStream(ctx context.Context) {
 	defer close(w.doneC)
@@ -268,7 +477,7 @@ func (w *WebSocketManager) listenUserDataStream(ctx context.Context) {
 
 		logger.Info("🔗 [Binance] 连接WebSocket订单流...")
 
-		doneC, stopC, err := futures.WsUserDataServe(w.listenKey, w.handleUserDataEvent, w.handleError)
+		doneC, stopC, err := w.serveUserData()
 		if err != nil {
 			logger.Error("❌ [Binance] WebSocket连接失败: %v", err)
 			time.Sleep(w.reconnectDelay)
@@ -294,6 +503,10 @@ func (w *WebSocketManager) listenUserDataStream(ctx context.Context) {
 
 // handleUserDataEvent 处理用户数据事件
 func (w *WebSocketManager) handleUserDataEvent(event *futures.WsUserDataEvent) {
+	if event.Event == futures.UserDataEventTypeAccountUpdate {
+		w.handleAccountUpdate(event)
+		return
+	}
 	if event.Event != futures.UserDataEventTypeOrderTradeUpdate {
 		return
 	}
@@ -317,6 +530,41 @@ func (w *WebSocketManager) handleUserDataEvent(event *futures.WsUserDataEvent) {
 		UpdateTime:    order.TradeTime,
 	}
 
+	// 成交事件（x=TRADE）携带本次成交的价格、数量、手续费与 Maker 标记
+	if order.ExecutionType == futures.OrderExecutionTypeTrade {
+		lastQty, _ := strconv.ParseFloat(order.LastFilledQty, 64)
+		lastPrice, _ := strconv.ParseFloat(order.LastFilledPrice, 64)
+		commission, _ := strconv.ParseFloat(order.Commission, 64)
+		if lastQty > 0 {
+			update.Fill = &FillEvent{
+				TradeID:         strconv.FormatInt(order.TradeID, 10),
+				Price:           lastPrice,
+				Quantity:        lastQty,
+				Commission:      commission,
+				CommissionAsset: order.CommissionAsset,
+				IsMaker:         order.IsMaker,
+				Time:            order.TradeTime,
+			}
+		}
+	}
+
+	w.dispatchOrderUpdate(update)
+}
+
+// serveUserData 连接用户数据流（U本位与币本位合约使用 listenKey，现货使用签名订阅）
+func (w *WebSocketManager) serveUserData() (doneC, stopC chan struct{}, err error) {
+	if w.spot != nil {
+		return gobinance.WsUserDataServeSignature(w.apiKey, w.secretKey, common.KeyTypeHmac, -w.clock.Offset().Milliseconds(),
+			w.handleSpotUserDataEvent, w.handleError)
+	}
+	if w.inverse != nil {
+		return delivery.WsUserDataServe(w.listenKey, w.handleInverseUserDataEvent, w.handleError)
+	}
+	return futures.WsUserDataServe(w.listenKey, w.handleUserDataEvent, w.handleError)
+}
+
+// dispatchOrderUpdate 将订单更新分发给所有注册的回调
+func (w *WebSocketManager) dispatchOrderUpdate(update OrderUpdate) {
 	// 🔍 调试日志：记录收到的订单更新
 	logger.Debug("🔍 [WebSocket回调] 收到订单更新: ID=%d, ClientOID=%s, Side=%s, Status=%s, ExecutedQty=%.4f, Price=%.2f",
 		update.OrderID, update.ClientOrderID, update.Side, update.Status, update.ExecutedQty, update.Price)
@@ -331,14 +579,58 @@ func (w *WebSocketManager) handleUserDataEvent(event *futures.WsUserDataEvent) {
 	}
 }
 
+// handleAccountUpdate 处理账户更新（ACCOUNT_UPDATE 只推送发生变化的资产与持仓）
+func (w *WebSocketManager) handleAccountUpdate(event *futures.WsUserDataEvent) {
+	w.mu.RLock()
+	callbacks := w.accountCallbacks
+	w.mu.RUnlock()
+	if len(callbacks) == 0 {
+		return
+	}
+
+	account := event.AccountUpdate
+	update := &AccountUpdate{UpdateTime: event.TransactionTime}
+	for _, b := range account.Balances {
+		walletBalance, _ := strconv.ParseFloat(b.Balance, 64)
+		update.Balances = append(update.Balances, BalanceUpdate{
+			Asset:         b.Asset,
+			WalletBalance: walletBalance,
+		})
+	}
+	for _, p := range account.Positions {
+		size, _ := strconv.ParseFloat(p.Amount, 64)
+		entryPrice, _ := strconv.ParseFloat(p.EntryPrice, 64)
+		markPrice, _ := strconv.ParseFloat(p.MarkPrice, 64)
+		unrealizedPNL, _ := strconv.ParseFloat(p.UnrealizedPnL, 64)
+		isolatedMargin, _ := strconv.ParseFloat(p.IsolatedWallet, 64)
+		update.Positions = append(update.Positions, &Position{
+			Symbol:         p.Symbol,
+			Size:           size,
+			EntryPrice:     entryPrice,
+			MarkPrice:      markPrice,
+			UnrealizedPNL:  unrealizedPNL,
+			MarginType:     string(p.MarginType),
+			IsolatedMargin: isolatedMargin,
+			Side:           hedgePositionSide(string(p.Side)),
+		})
+	}
+
+	logger.Debug("🔍 [Binance] 账户更新: 原因=%s, 资产 %d 项, 持仓 %d 项",
+		account.Reason, len(update.Balances), len(update.Positions))
+
+	for _, callback := range callbacks {
+		callback(update)
+	}
+}
+
 // handleError 处理错误
 func (w *WebSocketManager) handleError(err error) {
 	logger.Error("❌ [Binance] WebSocket错误: %v", err)
 }
 
-// GetLatestPrice 获取最新价格（从缓存读取）
-func (w *WebSocketManager) GetLatestPrice() float64 {
+// GetLatestPrice 获取交易对最新价格（从缓存读取）
+func (w *WebSocketManager) GetLatestPrice(symbol string) float64 {
 	w.priceMu.RLock()
 	defer w.priceMu.RUnlock()
-	return w.latestPrice
+	return w.latestPrices[strings.ToUpper(symbol)]
 }
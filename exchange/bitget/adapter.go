@@ -3,12 +3,21 @@ package bitget
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"opensqt/exchange/clock"
+	"opensqt/exchange/errs"
+	"opensqt/exchange/orderbook"
+	"opensqt/exchange/wsapi"
 	"opensqt/logger"
+	"opensqt/utils"
 )
 
 // 为了避免循环导入，在这里定义需要的接口和类型
@@ -44,7 +53,8 @@ type OrderRequest struct {
 	Quantity      float64
 	Price         float64
 	ReduceOnly    bool
-	PostOnly      bool // 是否只做 Maker（Post Only）
+	PositionSide  string // 持仓方向 LONG/SHORT（双向持仓模式），空表示单向持仓
+	PostOnly      bool   // 是否只做 Maker（Post Only）
 	PriceDecimals int
 	ClientOrderID string // 自定义订单ID
 }
@@ -73,6 +83,7 @@ type Position struct {
 	Leverage       int
 	MarginType     string
 	IsolatedMargin float64
+	Side           string // 持仓方向 LONG/SHORT（双向持仓模式），单向持仓为空
 }
 
 type Account struct {
@@ -84,6 +95,21 @@ type Account struct {
 	AccountLeverage    int    // 账户级别的杠杆倍数
 }
 
+// SymbolInfo 合约交易规则（数量口径为币数量）
+type SymbolInfo struct {
+	Symbol             string
+	BaseAsset          string
+	QuoteAsset         string
+	TickSize           float64 // 价格最小变动单位
+	StepSize           float64 // 数量最小变动单位
+	MinQty             float64 // 最小下单数量
+	MinNotional        float64 // 最小名义价值，0 表示无限制
+	ContractMultiplier float64 // 合约乘数（每张合约对应的币数量）
+	MaxLeverage        int     // 最大杠杆倍数，0 表示未知
+	PriceDecimals      int
+	QuantityDecimals   int
+}
+
 type OrderUpdate struct {
 	OrderID       int64
 	ClientOrderID string
@@ -96,31 +122,127 @@ type OrderUpdate struct {
 	ExecutedQty   float64
 	AvgPrice      float64
 	UpdateTime    int64
+	Fill          *FillEvent // 本次成交明细，无新成交时为 nil
+}
+
+// FillEvent 单笔成交明细
+type FillEvent struct {
+	TradeID         string
+	Price           float64
+	Quantity        float64
+	Commission      float64 // 正数为支出，负数为返佣
+	CommissionAsset string
+	IsMaker         bool
+	Time            int64
 }
 
 type OrderUpdateCallback func(update OrderUpdate)
 
+// BalanceUpdate 资产余额变化
+type BalanceUpdate struct {
+	Asset            string
+	WalletBalance    float64
+	AvailableBalance float64
+	HasAvailable     bool // 交易所是否推送了可用余额
+}
+
+// AccountUpdate 账户推送（余额与持仓变化）
+type AccountUpdate struct {
+	Balances          []BalanceUpdate
+	Positions         []*Position
+	PositionsSnapshot bool // Positions 是否为全部持仓快照
+	UpdateTime        int64
+}
+
+type AccountUpdateCallback func(update *AccountUpdate)
+
+// FundingRate 资金费率与标记价格
+type FundingRate struct {
+	Symbol          string
+	FundingRate     float64
+	MarkPrice       float64
+	IndexPrice      float64
+	NextFundingTime int64
+	Time            int64
+}
+
+type MarkPriceCallback func(update *FundingRate)
+
+// FundingPayment 资金费收付记录
+type FundingPayment struct {
+	ID     string
+	Symbol string
+	Amount float64 // 正数为收入，负数为支出
+	Asset  string
+	Time   int64
+}
+
+// Trade 账户成交记录（成交历史不返回自定义订单ID，ClientOrderID 为空）
+type Trade struct {
+	TradeID         string
+	OrderID         int64
+	ClientOrderID   string
+	Symbol          string
+	Side            Side
+	Price           float64
+	Quantity        float64
+	Commission      float64 // 正数为支出，负数为返佣
+	CommissionAsset string
+	IsMaker         bool
+	Time            int64
+}
+
+// batchOrderSize 批量下单接口单次最多订单数
+const batchOrderSize = 50
+
 // BitgetAdapter Bitget 交易所适配器
 type BitgetAdapter struct {
 	client         *Client
 	wsManager      *WebSocketManager
 	klineWSManager *KlineWebSocketManager
 	symbol         string // 交易对（如 ETHUSDT，V2 API 不带 _UMCBL 后缀）
-	useWebSocket   bool   // 是否使用 WebSocket 下单
+	useWebSocket   bool   // 是否使用 WebSocket 下单（私有频道未就绪时改用 REST）
+	latency        *wsapi.LatencyRecorder
+	clock          *clock.Estimator // 服务器时间偏差（校正签名时间戳）
 
 	// 🔥 新增：订单ID到价格的映射注册回调
 	// 用于在下单成功后立即建立映射，避免 WebSocket 更新先到导致找不到槽位
 	orderMappingCallback func(orderID int64, price float64)
 
-	posMode      string // 持仓模式：hedge_mode 或 one_way_mode
-	productType  string // 合约类型：usdt-futures（U本位）或 coin-futures（币本位）
-	marginCoin   string // 保证金币种：自动从合约信息获取
-	volumePlace  int    // 数量小数位（从合约信息获取）
-	pricePlace   int    // 价格小数位（从合约信息获取）
-	minTradeNum  string // 最小下单数量
-	minTradeUSDT string // 最小下单金额（USDT）
-	baseAsset    string // 基础资产（交易币种），如 BTC
-	quoteAsset   string // 计价资产（结算币种），如 USDT、USD
+	posMode string // 持仓模式：hedge_mode 或 one_way_mode（账户级别）
+	spot    bool   // 现货模式（见 spot.go）
+	demo    bool   // 模拟盘（合约类型带 S 前缀，如 susdt-futures）
+
+	// 合约信息：构造时加载默认交易对，其他交易对首次使用时按需加载
+	metaMu sync.RWMutex
+	metas  map[string]*symbolMeta
+}
+
+// symbolMeta 单个合约的下单参数
+type symbolMeta struct {
+	symbol       string  // Bitget 合约符号（如 ETHUSDT）
+	productType  string  // 合约类型：usdt-futures（U本位）或 coin-futures（币本位）
+	marginCoin   string  // 保证金币种：自动从合约信息获取
+	volumePlace  int     // 数量小数位（从合约信息获取）
+	pricePlace   int     // 价格小数位（从合约信息获取）
+	tickSize     float64 // 价格步长（从合约信息获取）
+	minTradeNum  string  // 最小下单数量
+	minTradeUSDT string  // 最小下单金额（USDT）
+	baseAsset    string  // 基础资产（交易币种），如 BTC
+	quoteAsset   string  // 计价资产（结算币种），如 USDT、USD
+}
+
+// parseEnvironment 解析 environment 配置
+// Bitget 没有测试网，模拟盘（demo）与实盘共用 REST 地址，通过请求头 paptrading 区分
+func parseEnvironment(env string) (demo bool, err error) {
+	switch env {
+	case "", "mainnet":
+		return false, nil
+	case "demo":
+		return true, nil
+	default:
+		return false, fmt.Errorf("bitget 不支持 environment=%s（可选 mainnet/demo）", env)
+	}
 }
 
 // NewBitgetAdapter 创建 Bitget 适配器
@@ -132,32 +254,54 @@ func NewBitgetAdapter(cfg map[string]string, symbol string) (*BitgetAdapter, err
 	if apiKey == "" || secretKey == "" || passphrase == "" {
 		return nil, fmt.Errorf("bitget API 配置不完整")
 	}
+	demo, err := parseEnvironment(cfg["environment"])
+	if err != nil {
+		return nil, err
+	}
 
 	// Bitget V2 合约符号格式：直接使用 ETHUSDT（不带 _UMCBL 后缀）
 	bitgetSymbol := convertToBitgetSymbol(symbol)
 
 	client := NewClient(apiKey, secretKey, passphrase)
 	wsManager := NewWebSocketManager(apiKey, secretKey, passphrase)
+	if demo {
+		client.demo = true
+		wsManager.instType = instTypeFuturesDemo
+		wsManager.publicURL = BitgetWSPublicDemo
+		wsManager.privateURL = BitgetWSPrivateDemo
+		logger.Info("ℹ️ [Bitget] 使用模拟盘（paptrading）")
+	}
 
 	adapter := &BitgetAdapter{
 		client:       client,
 		wsManager:    wsManager,
 		symbol:       bitgetSymbol,
-		useWebSocket: false, // 使用 REST API 下单（混合模式）
+		useWebSocket: cfg["ws_order_entry"] == "true", // 默认使用 REST API 下单（混合模式）
+		latency:      wsapi.NewLatencyRecorder("Bitget"),
+		demo:         demo,
+		metas:        make(map[string]*symbolMeta),
 	}
+	adapter.startClock()
 
 	// 初始化获取合约信息和持仓模式
 	ctxInit, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	// 1. 先获取合约信息（必须先获取，因为需要设置productType和marginCoin）
-	if err := adapter.fetchContractInfo(ctxInit); err != nil {
+	if _, err := adapter.symbolMeta(ctxInit, bitgetSymbol); err != nil {
 		logger.Warn("⚠️ [Bitget] 获取合约信息失败: %v", err)
 		// 使用默认值
-		adapter.volumePlace = 4
-		adapter.pricePlace = 2
-		adapter.productType = "usdt-futures"
-		adapter.marginCoin = "USDT"
+		meta := &symbolMeta{
+			symbol:      bitgetSymbol,
+			volumePlace: 4,
+			pricePlace:  2,
+			productType: "usdt-futures",
+			marginCoin:  "USDT",
+		}
+		if demo {
+			meta.productType, meta.marginCoin = "susdt-futures", "SUSDT"
+		}
+		adapter.metas[bitgetSymbol] = meta
 	}
 
 	// 2. 获取持仓模式和账户信息
@@ -197,32 +341,56 @@ func (b *BitgetAdapter) GetName() string {
 	return "Bitget"
 }
 
-// fetchContractInfo 获取合约信息（数量精度、价格精度等）
-func (b *BitgetAdapter) fetchContractInfo(ctx context.Context) error {
-	// 尝试从多个合约类型中查找（先U本位，再币本位）
+// startClock 创建对时器并注入 REST 与 WebSocket 签名，立即对时一次后定期对时
+func (b *BitgetAdapter) startClock() {
+	b.clock = clock.NewEstimator("Bitget", b.client.ServerTime)
+	b.client.signer.clock = b.clock
+	b.wsManager.clock = b.clock
+	b.clock.Start(context.Background(), clock.DefaultSyncInterval)
+}
+
+// ClockSkew 本地时钟相对服务器的偏差（服务器时间 - 本地时间）
+func (b *BitgetAdapter) ClockSkew() time.Duration {
+	return b.clock.Offset()
+}
+
+// SyncServerTime 立即与服务器对时
+func (b *BitgetAdapter) SyncServerTime(ctx context.Context) error {
+	return b.clock.Sync(ctx)
+}
+
+// bitgetContract 合约信息（/api/v2/mix/market/contracts）
+type bitgetContract struct {
+	Symbol             string   `json:"symbol"`
+	VolumePlace        string   `json:"volumePlace"`        // 数量小数位
+	PricePlace         string   `json:"pricePlace"`         // 价格小数位
+	PriceEndStep       string   `json:"priceEndStep"`       // 价格步长（以价格末位计）
+	SizeMultiplier     string   `json:"sizeMultiplier"`     // 数量步长
+	MinTradeNum        string   `json:"minTradeNum"`        // 最小下单数量
+	MinTradeUSDT       string   `json:"minTradeUSDT"`       // 最小下单金额
+	MaxLever           string   `json:"maxLever"`           // 最大杠杆
+	BaseCoin           string   `json:"baseCoin"`           // 基础币种
+	QuoteCoin          string   `json:"quoteCoin"`          // 计价币种
+	SupportMarginCoins []string `json:"supportMarginCoins"` // 支持的保证金币种
+}
+
+// queryContract 查询合约信息，依次尝试各合约类型（先U本位，再币本位）
+func (b *BitgetAdapter) queryContract(ctx context.Context, symbol string) (*bitgetContract, string, error) {
 	productTypes := []string{"usdt-futures", "coin-futures", "usdc-futures"}
+	if b.demo {
+		productTypes = []string{"susdt-futures", "scoin-futures", "susdc-futures"}
+	}
 	var lastErr error
 
 	for _, pt := range productTypes {
-		path := fmt.Sprintf("/api/v2/mix/market/contracts?productType=%s&symbol=%s", pt, b.symbol)
+		path := fmt.Sprintf("/api/v2/mix/market/contracts?productType=%s&symbol=%s", pt, symbol)
 		resp, err := b.client.DoRequest(ctx, "GET", path, nil)
 		if err != nil {
 			lastErr = err
 			continue
 		}
 
-		// 解析合约信息
-		var dataList []struct {
-			Symbol             string   `json:"symbol"`
-			VolumePlace        string   `json:"volumePlace"`        // 数量小数位
-			PricePlace         string   `json:"pricePlace"`         // 价格小数位
-			MinTradeNum        string   `json:"minTradeNum"`        // 最小下单数量
-			MinTradeUSDT       string   `json:"minTradeUSDT"`       // 最小下单金额
-			BaseCoin           string   `json:"baseCoin"`           // 基础币种
-			QuoteCoin          string   `json:"quoteCoin"`          // 计价币种
-			SupportMarginCoins []string `json:"supportMarginCoins"` // 支持的保证金币种
-		}
-
+		var dataList []bitgetContract
 		if err := json.Unmarshal(resp.Data, &dataList); err != nil {
 			lastErr = fmt.Errorf("解析合约信息失败: %w", err)
 			continue
@@ -231,52 +399,219 @@ func (b *BitgetAdapter) fetchContractInfo(ctx context.Context) error {
 		if len(dataList) == 0 {
 			continue // 尝试下一个productType
 		}
+		return &dataList[0], pt, nil
+	}
 
-		// 找到合约信息
-		contract := dataList[0]
-		b.productType = pt
-		b.volumePlace, _ = strconv.Atoi(contract.VolumePlace)
-		b.pricePlace, _ = strconv.Atoi(contract.PricePlace)
-		b.minTradeNum = contract.MinTradeNum
-		b.minTradeUSDT = contract.MinTradeUSDT
-		b.baseAsset = contract.BaseCoin
-		b.quoteAsset = contract.QuoteCoin
-
-		// 设置保证金币种（优先使用supportMarginCoins的第一个，否则使用quoteCoin）
-		if len(contract.SupportMarginCoins) > 0 {
-			b.marginCoin = contract.SupportMarginCoins[0]
-		} else {
-			b.marginCoin = contract.QuoteCoin
-		}
+	if lastErr != nil {
+		return nil, "", fmt.Errorf("未找到合约信息 %s: %w", symbol, lastErr)
+	}
+	return nil, "", fmt.Errorf("未找到合约信息: %s", symbol)
+}
 
-		// 判断合约类型描述
-		contractTypeDesc := "U本位合约"
-		if pt == "coin-futures" {
-			contractTypeDesc = "币本位合约"
-		} else if pt == "usdc-futures" {
-			contractTypeDesc = "USDC合约"
-		}
+// symbolMeta 获取合约下单参数，首次使用时从交易所加载并缓存
+func (b *BitgetAdapter) symbolMeta(ctx context.Context, symbol string) (*symbolMeta, error) {
+	symbol = convertToBitgetSymbol(symbol)
 
-		logger.Info("ℹ️ [Bitget 合约信息] %s - %s, 数量精度:%d, 价格精度:%d, 基础币种:%s, 计价币种:%s, 保证金:%s",
-			b.symbol, contractTypeDesc, b.volumePlace, b.pricePlace, b.baseAsset, b.quoteAsset, b.marginCoin)
+	b.metaMu.RLock()
+	meta, ok := b.metas[symbol]
+	b.metaMu.RUnlock()
+	if ok {
+		return meta, nil
+	}
+	if b.spot {
+		return b.loadSpotMeta(ctx, symbol)
+	}
 
-		return nil
+	contract, pt, err := b.queryContract(ctx, symbol)
+	if err != nil {
+		return nil, err
 	}
 
-	if lastErr != nil {
-		return fmt.Errorf("未找到合约信息 %s: %w", b.symbol, lastErr)
+	meta = &symbolMeta{
+		symbol:       symbol,
+		productType:  pt,
+		tickSize:     contractTickSize(contract),
+		minTradeNum:  contract.MinTradeNum,
+		minTradeUSDT: contract.MinTradeUSDT,
+		baseAsset:    contract.BaseCoin,
+		quoteAsset:   contract.QuoteCoin,
 	}
-	return fmt.Errorf("未找到合约信息: %s", b.symbol)
+	meta.volumePlace, _ = strconv.Atoi(contract.VolumePlace)
+	meta.pricePlace, _ = strconv.Atoi(contract.PricePlace)
+
+	// 设置保证金币种（优先使用supportMarginCoins的第一个，否则使用quoteCoin）
+	if len(contract.SupportMarginCoins) > 0 {
+		meta.marginCoin = contract.SupportMarginCoins[0]
+	} else {
+		meta.marginCoin = contract.QuoteCoin
+	}
+
+	b.metaMu.Lock()
+	b.metas[symbol] = meta
+	b.metaMu.Unlock()
+
+	// 判断合约类型描述
+	contractTypeDesc := "U本位合约"
+	if strings.HasSuffix(pt, "coin-futures") {
+		contractTypeDesc = "币本位合约"
+	} else if strings.HasSuffix(pt, "usdc-futures") {
+		contractTypeDesc = "USDC合约"
+	}
+
+	logger.Info("ℹ️ [Bitget 合约信息] %s - %s, 数量精度:%d, 价格精度:%d, 基础币种:%s, 计价币种:%s, 保证金:%s",
+		symbol, contractTypeDesc, meta.volumePlace, meta.pricePlace, meta.baseAsset, meta.quoteAsset, meta.marginCoin)
+
+	return meta, nil
+}
+
+// defaultMeta 默认交易对的下单参数（构造时已加载或填入默认值）
+func (b *BitgetAdapter) defaultMeta() *symbolMeta {
+	b.metaMu.RLock()
+	defer b.metaMu.RUnlock()
+	return b.metas[b.symbol]
+}
+
+// GetSymbolInfo 获取合约交易规则
+func (b *BitgetAdapter) GetSymbolInfo(ctx context.Context, symbol string) (*SymbolInfo, error) {
+	if b.spot {
+		return b.spotSymbolInfo(ctx, symbol)
+	}
+	contract, _, err := b.queryContract(ctx, convertToBitgetSymbol(symbol))
+	if err != nil {
+		return nil, err
+	}
+
+	info := &SymbolInfo{
+		Symbol:             contract.Symbol,
+		BaseAsset:          contract.BaseCoin,
+		QuoteAsset:         contract.QuoteCoin,
+		TickSize:           contractTickSize(contract),
+		ContractMultiplier: 1, // Bitget 按币数量下单
+	}
+	info.PriceDecimals, _ = strconv.Atoi(contract.PricePlace)
+	info.QuantityDecimals, _ = strconv.Atoi(contract.VolumePlace)
+	info.StepSize, _ = strconv.ParseFloat(contract.SizeMultiplier, 64)
+	if info.StepSize <= 0 {
+		info.StepSize = math.Pow10(-info.QuantityDecimals)
+	}
+	info.MinQty, _ = strconv.ParseFloat(contract.MinTradeNum, 64)
+	info.MinNotional, _ = strconv.ParseFloat(contract.MinTradeUSDT, 64)
+	info.MaxLeverage, _ = strconv.Atoi(contract.MaxLever)
+	return info, nil
+}
+
+// contractTickSize 计算价格步长：priceEndStep × 10^-pricePlace（如 pricePlace=1, priceEndStep=5 -> 0.5）
+func contractTickSize(contract *bitgetContract) float64 {
+	pricePlace, _ := strconv.Atoi(contract.PricePlace)
+	endStep, _ := strconv.ParseFloat(contract.PriceEndStep, 64)
+	if endStep <= 0 {
+		endStep = 1
+	}
+	return endStep * math.Pow10(-pricePlace)
 }
 
-// PlaceOrder 下单（使用 REST API）
+// PlaceOrder 下单
+// 默认使用 REST API（混合模式）；配置 ws_order_entry 后通过私有频道下单，私有频道未就绪时改用 REST
 func (b *BitgetAdapter) PlaceOrder(ctx context.Context, req *OrderRequest) (*Order, error) {
-	// 混合模式：使用 REST API 下单，更稳定可靠
-	return b.placeOrderViaREST(ctx, req)
+	if b.spot {
+		return b.spotPlaceOrder(ctx, req)
+	}
+
+	meta, err := b.symbolMeta(ctx, req.Symbol)
+	if err != nil {
+		return nil, fmt.Errorf("获取 %s 合约信息失败: %w", req.Symbol, err)
+	}
+
+	// Bitget V2 下单参数（交易对与产品类型由 REST 请求体或 WebSocket arg 携带）
+	body := b.orderParams(meta, req)
+	body["marginMode"] = "crossed"
+	body["marginCoin"] = meta.marginCoin
+
+	// 请求已发出但超时/断开时订单状态未知，不能改用 REST 重下，直接返回错误
+	if b.useWebSocket {
+		start := time.Now()
+		data, err := b.wsManager.PlaceOrder(ctx, strings.ToUpper(meta.productType), meta.symbol, body)
+		if err == nil {
+			b.latency.Record(wsapi.PathWebSocket, time.Since(start))
+			return placedOrder(req, data)
+		}
+		if !errors.Is(err, wsapi.ErrNotConnected) {
+			return nil, err
+		}
+		logger.Debug("[Bitget] WebSocket 下单不可用，改用 REST: %v", err)
+	}
+
+	return b.placeOrderViaREST(ctx, meta, body, req)
 }
 
 // placeOrderViaREST 通过 REST API 下单
-func (b *BitgetAdapter) placeOrderViaREST(ctx context.Context, req *OrderRequest) (*Order, error) {
+func (b *BitgetAdapter) placeOrderViaREST(ctx context.Context, meta *symbolMeta, body map[string]interface{}, req *OrderRequest) (*Order, error) {
+	body["symbol"] = meta.symbol
+	body["productType"] = meta.productType
+
+	// 只请求1次，不重试
+	start := time.Now()
+	resp, err := b.client.DoRequest(ctx, "POST", "/api/v2/mix/order/place-order", body)
+	if err != nil {
+		// 错误已在 client 中按错误码分类（保证金不足、PostOnly 被拒等）
+		return nil, err
+	}
+	b.latency.Record(wsapi.PathREST, time.Since(start))
+
+	// 🔍 添加调试：打印完整响应
+	logger.Debug("🔍 [Bitget REST] 下单响应: %s", string(resp.Data))
+
+	order, err := placedOrder(req, resp.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	// 🔥 诊断：获取当前市场价格，检查订单价格是否合理
+	ctxPrice, cancelPrice := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancelPrice()
+	currentPrice, err := b.GetLatestPrice(ctxPrice, meta.symbol)
+	if err == nil {
+		priceDiff := req.Price - currentPrice
+		priceDiffPercent := (priceDiff / currentPrice) * 100
+		logger.Debug("🔍 [Bitget下单诊断] 订单价格: %.2f, 当前价格: %.2f, 价差: %.2f (%.3f%%)",
+			req.Price, currentPrice, priceDiff, priceDiffPercent)
+	}
+
+	// 注意：不在这里打印日志，由executor统一打印避免重复
+	return order, nil
+}
+
+// placedOrder 由下单响应（orderId、clientOid，REST 与 WebSocket 格式相同）构造新订单
+func placedOrder(req *OrderRequest, data []byte) (*Order, error) {
+	var resp struct {
+		OrderID       string `json:"orderId"`
+		ClientOrderID string `json:"clientOid"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("解析下单响应失败: %w", err)
+	}
+
+	orderID, _ := strconv.ParseInt(resp.OrderID, 10, 64)
+	if orderID == 0 {
+		return nil, fmt.Errorf("下单响应中orderId为空或无效: %s", string(data))
+	}
+
+	return &Order{
+		OrderID:       orderID,
+		ClientOrderID: resp.ClientOrderID,
+		Symbol:        req.Symbol,
+		Side:          req.Side,
+		Type:          req.Type,
+		Price:         req.Price,
+		Quantity:      req.Quantity,
+		Status:        OrderStatusNew,
+		CreatedAt:     time.Now(),
+	}, nil
+}
+
+// orderParams 构造单个订单的下单参数（单笔下单与批量下单共用）
+// 交易对、保证金模式等公共参数由调用方补充
+func (b *BitgetAdapter) orderParams(meta *symbolMeta, req *OrderRequest) map[string]interface{} {
 	// 确定 side 和 tradeSide
 	side := strings.ToLower(string(req.Side))
 	var tradeSide string
@@ -287,7 +622,12 @@ func (b *BitgetAdapter) placeOrderViaREST(ctx context.Context, req *OrderRequest
 	// 开空：side=sell, tradeSide=open
 	// 平空：side=sell, tradeSide=close
 	if b.posMode == "hedge_mode" {
-		if req.ReduceOnly {
+		closing := req.ReduceOnly
+		if req.PositionSide != "" {
+			// 指定了持仓方向时按方向判断：卖出平多、买入平空
+			closing = (req.PositionSide == "LONG") == (req.Side == SideSell)
+		}
+		if closing {
 			// 平仓：保持 side 方向不变，只改 tradeSide
 			// 如果是 SELL（卖出），实际上是要平多仓，需要改为 buy
 			if req.Side == SideSell {
@@ -302,8 +642,13 @@ func (b *BitgetAdapter) placeOrderViaREST(ctx context.Context, req *OrderRequest
 	}
 
 	// 🔥 使用合约信息中的精度格式化数量和价格
-	quantityStr := fmt.Sprintf("%.*f", b.volumePlace, req.Quantity)
-	priceStr := fmt.Sprintf("%.*f", b.pricePlace, req.Price)
+	quantityStr := fmt.Sprintf("%.*f", meta.volumePlace, req.Quantity)
+	price := req.Price
+	if meta.tickSize > 0 {
+		// 对齐到价格步长（部分合约步长不是 1 个最小单位，如 0.5）
+		price = math.Round(price/meta.tickSize) * meta.tickSize
+	}
+	priceStr := fmt.Sprintf("%.*f", meta.pricePlace, price)
 
 	// 根据 PostOnly 参数选择 force 类型
 	forceType := "gtc" // 默认使用 GTC (Good Till Cancel)
@@ -311,17 +656,12 @@ func (b *BitgetAdapter) placeOrderViaREST(ctx context.Context, req *OrderRequest
 		forceType = "post_only" // Post Only - 只做 Maker
 	}
 
-	// Bitget V2 下单参数
 	body := map[string]interface{}{
-		"symbol":      req.Symbol,
-		"productType": b.productType,
-		"marginMode":  "crossed",
-		"marginCoin":  "USDT",
-		"side":        side,
-		"orderType":   "limit",
-		"price":       priceStr,
-		"size":        quantityStr,
-		"force":       forceType,
+		"side":      side,
+		"orderType": "limit",
+		"price":     priceStr,
+		"size":      quantityStr,
+		"force":     forceType,
 	}
 
 	// 设置自定义订单ID
@@ -341,107 +681,279 @@ func (b *BitgetAdapter) placeOrderViaREST(ctx context.Context, req *OrderRequest
 		body["reduceOnly"] = "YES"
 	}
 
-	// 只请求1次，不重试
-	resp, err := b.client.DoRequest(ctx, "POST", "/api/v2/mix/order/place-order", body)
-	if err != nil {
-		// 检查错误类型
-		if strings.Contains(err.Error(), "insufficient balance") || strings.Contains(err.Error(), "40007") {
-			return nil, fmt.Errorf("保证金不足: %w", err)
+	return body
+}
+
+// BatchPlaceOrders 批量下单
+// 按交易对分组，每组按 batchOrderSize 分块调用批量下单接口；
+// 批量结果按 clientOid 对应回请求，没有 clientOid 的订单无法识别，逐单下单
+func (b *BitgetAdapter) BatchPlaceOrders(ctx context.Context, orders []*OrderRequest) ([]*Order, bool) {
+	if b.spot {
+		return b.spotBatchPlaceOrders(ctx, orders)
+	}
+	placedOrders := make([]*Order, 0, len(orders))
+	hasMarginError := false
+
+	reportError := func(req *OrderRequest, err error) {
+		logger.Warn("⚠️ [Bitget] 下单失败 %.2f %s: %v", req.Price, req.Side, err)
+		errs.Report(ctx, err)
+		if errors.Is(err, errs.ErrInsufficientMargin) {
+			hasMarginError = true
 		}
-		return nil, err
 	}
+	addPlaced := func(req *OrderRequest, order *Order) {
+		// 🔥 关键：确保 order.Price 包含请求的价格
+		// 这样调用者就能正确建立 orderID -> price 的映射
+		order.Price = req.Price
 
-	// 解析响应
-	var data struct {
-		OrderID       string `json:"orderId"`
-		ClientOrderID string `json:"clientOid"`
+		// 🔥 新增：立即注册订单ID到价格的映射
+		// 这样可以防止 WebSocket 更新先到导致找不到槽位
+		if b.orderMappingCallback != nil && order.OrderID > 0 {
+			b.orderMappingCallback(order.OrderID, req.Price)
+			logger.Debug("🔍 [Bitget映射] 注册 订单ID=%d -> 价格=%.2f", order.OrderID, req.Price)
+		}
+		placedOrders = append(placedOrders, order)
 	}
-	if err := json.Unmarshal(resp.Data, &data); err != nil {
-		return nil, fmt.Errorf("解析下单响应失败: %w", err)
+
+	// 按交易对分组（保持请求顺序）
+	var symbols []string
+	groups := make(map[string][]*OrderRequest)
+	for _, req := range orders {
+		if req.ClientOrderID == "" {
+			order, err := b.PlaceOrder(ctx, req)
+			if err != nil {
+				reportError(req, err)
+				continue
+			}
+			addPlaced(req, order)
+			continue
+		}
+		if _, ok := groups[req.Symbol]; !ok {
+			symbols = append(symbols, req.Symbol)
+		}
+		groups[req.Symbol] = append(groups[req.Symbol], req)
 	}
 
-	// 🔍 添加调试：打印完整响应
-	logger.Debug("🔍 [Bitget REST] 下单响应: %s", string(resp.Data))
+	for _, symbol := range symbols {
+		group := groups[symbol]
+		meta, err := b.symbolMeta(ctx, symbol)
+		if err != nil {
+			err = fmt.Errorf("获取 %s 合约信息失败: %w", symbol, err)
+			for _, req := range group {
+				reportError(req, err)
+			}
+			continue
+		}
 
-	orderID, _ := strconv.ParseInt(data.OrderID, 10, 64)
-	if orderID == 0 {
-		return nil, fmt.Errorf("下单响应中orderId为空或无效: %s", string(resp.Data))
-	}
+		for i := 0; i < len(group); i += batchOrderSize {
+			end := i + batchOrderSize
+			if end > len(group) {
+				end = len(group)
+			}
+			chunk := group[i:end]
+
+			if len(chunk) == 1 {
+				order, err := b.PlaceOrder(ctx, chunk[0])
+				if err != nil {
+					reportError(chunk[0], err)
+					continue
+				}
+				addPlaced(chunk[0], order)
+				continue
+			}
 
-	order := &Order{
-		OrderID:       orderID,
-		ClientOrderID: data.ClientOrderID,
-		Symbol:        req.Symbol,
-		Side:          req.Side,
-		Type:          req.Type,
-		Price:         req.Price,
-		Quantity:      req.Quantity,
-		Status:        OrderStatusNew,
-		CreatedAt:     time.Now(),
+			results, err := b.placeBatchChunk(ctx, meta, chunk)
+			if err != nil {
+				// 整批请求失败：批次内所有订单均视为失败
+				for _, req := range chunk {
+					reportError(req, err)
+				}
+				continue
+			}
+			for _, req := range chunk {
+				result, ok := results[req.ClientOrderID]
+				if !ok {
+					reportError(req, fmt.Errorf("批量下单响应中缺少订单 %s", req.ClientOrderID))
+					continue
+				}
+				if result.err != nil {
+					reportError(req, result.err)
+					continue
+				}
+				addPlaced(req, &Order{
+					OrderID:       result.orderID,
+					ClientOrderID: req.ClientOrderID,
+					Symbol:        req.Symbol,
+					Side:          req.Side,
+					Type:          req.Type,
+					Price:         req.Price,
+					Quantity:      req.Quantity,
+					Status:        OrderStatusNew,
+					CreatedAt:     time.Now(),
+				})
+			}
+		}
 	}
 
-	// 🔥 诊断：获取当前市场价格，检查订单价格是否合理
-	ctxPrice, cancelPrice := context.WithTimeout(context.Background(), 2*time.Second)
-	defer cancelPrice()
-	currentPrice, err := b.GetLatestPrice(ctxPrice, b.symbol)
-	if err == nil {
-		priceDiff := req.Price - currentPrice
-		priceDiffPercent := (priceDiff / currentPrice) * 100
-		logger.Debug("🔍 [Bitget下单诊断] 订单价格: %.2f, 当前价格: %.2f, 价差: %.2f (%.3f%%)",
-			req.Price, currentPrice, priceDiff, priceDiffPercent)
-	}
+	return placedOrders, hasMarginError
+}
 
-	// 注意：不在这里打印日志，由executor统一打印避免重复
-	return order, nil
+// batchResult 批量下单中单个订单的结果
+type batchResult struct {
+	orderID int64
+	err     error
 }
 
-// BatchPlaceOrders 批量下单
-func (b *BitgetAdapter) BatchPlaceOrders(ctx context.Context, orders []*OrderRequest) ([]*Order, bool) {
-	placedOrders := make([]*Order, 0, len(orders))
-	hasMarginError := false
+// placeBatchChunk 调用批量下单接口（同一交易对），返回 clientOid -> 结果
+func (b *BitgetAdapter) placeBatchChunk(ctx context.Context, meta *symbolMeta, chunk []*OrderRequest) (map[string]batchResult, error) {
+	orderList := make([]map[string]interface{}, len(chunk))
+	for i, req := range chunk {
+		orderList[i] = b.orderParams(meta, req)
+	}
+	body := map[string]interface{}{
+		"symbol":      meta.symbol,
+		"productType": meta.productType,
+		"marginMode":  "crossed",
+		"marginCoin":  meta.marginCoin,
+		"orderList":   orderList,
+	}
 
-	for _, orderReq := range orders {
-		order, err := b.PlaceOrder(ctx, orderReq)
-		if err != nil {
-			logger.Warn("⚠️ [Bitget] 下单失败 %.2f %s: %v",
-				orderReq.Price, orderReq.Side, err)
+	resp, err := b.client.DoRequest(ctx, "POST", "/api/v2/mix/order/batch-place-order", body)
+	if err != nil {
+		return nil, err
+	}
 
-			if strings.Contains(err.Error(), "保证金不足") {
-				hasMarginError = true
-			}
+	var data struct {
+		SuccessList []struct {
+			OrderID   string `json:"orderId"`
+			ClientOid string `json:"clientOid"`
+		} `json:"successList"`
+		FailureList []struct {
+			ClientOid string `json:"clientOid"`
+			ErrorMsg  string `json:"errorMsg"`
+			ErrorCode string `json:"errorCode"`
+		} `json:"failureList"`
+	}
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		return nil, fmt.Errorf("解析批量下单响应失败: %w", err)
+	}
+	logger.Debug("🔍 [Bitget REST] 批量下单响应: %s", string(resp.Data))
+
+	results := make(map[string]batchResult, len(chunk))
+	for _, item := range data.SuccessList {
+		orderID, _ := strconv.ParseInt(item.OrderID, 10, 64)
+		if orderID == 0 {
+			results[item.ClientOid] = batchResult{err: fmt.Errorf("下单响应中orderId为空或无效: %s", item.OrderID)}
 			continue
 		}
+		results[item.ClientOid] = batchResult{orderID: orderID}
+	}
+	for _, item := range data.FailureList {
+		results[item.ClientOid] = batchResult{err: classifyError(item.ErrorCode, item.ErrorMsg,
+			fmt.Errorf("bitget API 错误: code=%s, msg=%s", item.ErrorCode, item.ErrorMsg))}
+	}
+	return results, nil
+}
 
-		// 🔥 关键：确保 order.Price 包含请求的价格
-		// 这样调用者就能正确建立 orderID -> price 的映射
-		order.Price = orderReq.Price
+// AmendOrder 改单（修改挂单的价格/数量）
+// newQty 为改单后的订单总数量，<= 0 时保持原数量；
+// Bitget 要求新价格与新数量同时传入，并为改单后的订单指定新的 clientOid，
+// 改单后订单ID可能变化，以返回值为准
+func (b *BitgetAdapter) AmendOrder(ctx context.Context, symbol string, orderID int64, newPrice, newQty float64) (*Order, error) {
+	if b.spot {
+		return nil, errSpotUnsupported("改单")
+	}
+	meta, err := b.symbolMeta(ctx, symbol)
+	if err != nil {
+		return nil, fmt.Errorf("获取 %s 合约信息失败: %w", symbol, err)
+	}
 
-		// 🔥 新增：立即注册订单ID到价格的映射
-		// 这样可以防止 WebSocket 更新先到导致找不到槽位
-		if b.orderMappingCallback != nil && order.OrderID > 0 {
-			b.orderMappingCallback(order.OrderID, orderReq.Price)
-			logger.Debug("🔍 [Bitget映射] 注册 订单ID=%d -> 价格=%.2f", order.OrderID, orderReq.Price)
-		}
+	original, err := b.GetOrder(ctx, symbol, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("查询原订单失败: %w", err)
+	}
+	if newQty <= 0 {
+		newQty = original.Quantity
+	}
+	if meta.tickSize > 0 {
+		newPrice = math.Round(newPrice/meta.tickSize) * meta.tickSize
+	}
 
-		placedOrders = append(placedOrders, order)
+	newClientOid := utils.GenerateOrderID(newPrice, string(original.Side), meta.pricePlace)
+	body := map[string]interface{}{
+		"orderId":      strconv.FormatInt(orderID, 10),
+		"symbol":       meta.symbol,
+		"productType":  meta.productType,
+		"marginCoin":   meta.marginCoin,
+		"newClientOid": newClientOid,
+		"newPrice":     fmt.Sprintf("%.*f", meta.pricePlace, newPrice),
+		"newSize":      fmt.Sprintf("%.*f", meta.volumePlace, newQty),
 	}
 
-	return placedOrders, hasMarginError
+	resp, err := b.client.DoRequest(ctx, "POST", "/api/v2/mix/order/modify-order", body)
+	if err != nil {
+		return nil, err
+	}
+
+	var data struct {
+		OrderID   string `json:"orderId"`
+		ClientOid string `json:"clientOid"`
+	}
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		return nil, fmt.Errorf("解析改单响应失败: %w", err)
+	}
+
+	newOrderID, _ := strconv.ParseInt(data.OrderID, 10, 64)
+	if newOrderID == 0 {
+		newOrderID = orderID
+	}
+	if data.ClientOid == "" {
+		data.ClientOid = newClientOid
+	}
+
+	// 改单后立即注册新订单ID到价格的映射，避免 WebSocket 更新先到导致找不到槽位
+	if b.orderMappingCallback != nil {
+		b.orderMappingCallback(newOrderID, newPrice)
+	}
+
+	logger.Info("✅ [Bitget] 改单成功: %d -> %d, 价格 %.*f -> %.*f",
+		orderID, newOrderID, meta.pricePlace, original.Price, meta.pricePlace, newPrice)
+
+	return &Order{
+		OrderID:       newOrderID,
+		ClientOrderID: data.ClientOid,
+		Symbol:        symbol,
+		Side:          original.Side,
+		Type:          OrderTypeLimit,
+		Price:         newPrice,
+		Quantity:      newQty,
+		ExecutedQty:   original.ExecutedQty,
+		Status:        OrderStatusNew,
+		CreatedAt:     time.Now(),
+	}, nil
 }
 
 // CancelOrder 取消订单
 func (b *BitgetAdapter) CancelOrder(ctx context.Context, symbol string, orderID int64) error {
+	if b.spot {
+		return b.spotCancelOrder(ctx, symbol, orderID)
+	}
+	meta, err := b.symbolMeta(ctx, symbol)
+	if err != nil {
+		return fmt.Errorf("获取 %s 合约信息失败: %w", symbol, err)
+	}
+
 	body := map[string]interface{}{
-		"symbol":      b.symbol,
-		"productType": b.productType,
-		"marginCoin":  b.marginCoin,
+		"symbol":      meta.symbol,
+		"productType": meta.productType,
+		"marginCoin":  meta.marginCoin,
 		"orderId":     fmt.Sprintf("%d", orderID),
 	}
 
-	_, err := b.client.DoRequest(ctx, "POST", "/api/v2/mix/order/cancel-order", body)
+	_, err = b.client.DoRequest(ctx, "POST", "/api/v2/mix/order/cancel-order", body)
 	if err != nil {
 		// 订单不存在不算错误
-		if strings.Contains(err.Error(), "order does not exist") || strings.Contains(err.Error(), "40029") {
+		if errors.Is(err, errs.ErrOrderNotFound) {
 			logger.Info("ℹ️ [Bitget] 订单 %d 已不存在，跳过取消", orderID)
 			return nil
 		}
@@ -457,6 +969,14 @@ func (b *BitgetAdapter) BatchCancelOrders(ctx context.Context, symbol string, or
 	if len(orderIDs) == 0 {
 		return nil
 	}
+	if b.spot {
+		return b.spotBatchCancelOrders(ctx, symbol, orderIDs)
+	}
+
+	meta, err := b.symbolMeta(ctx, symbol)
+	if err != nil {
+		return fmt.Errorf("获取 %s 合约信息失败: %w", symbol, err)
+	}
 
 	// 🔥 Bitget 批量撤单限制：最多20个，必须传symbol、productType、marginCoin
 	batchSize := 20
@@ -484,10 +1004,10 @@ func (b *BitgetAdapter) BatchCancelOrders(ctx context.Context, symbol string, or
 
 		// 🔥 确保所有必需参数都存在
 		body := map[string]interface{}{
-			"symbol":      b.symbol,      // 必需
-			"productType": b.productType, // 必需：USDT-FUTURES
-			"marginCoin":  b.marginCoin,  // 必需：USDT
-			"orderIdList": orderIDStrs,   // 必需：订单ID列表
+			"symbol":      meta.symbol,      // 必需
+			"productType": meta.productType, // 必需：USDT-FUTURES
+			"marginCoin":  meta.marginCoin,  // 必需：USDT
+			"orderIdList": orderIDStrs,      // 必需：订单ID列表
 		}
 
 		_, err := b.client.DoRequest(ctx, "POST", "/api/v2/mix/order/batch-cancel-orders", body)
@@ -514,9 +1034,13 @@ func (b *BitgetAdapter) BatchCancelOrders(ctx context.Context, symbol string, or
 
 // CancelAllOrders 一键全撤所有订单（Bitget特有功能）
 func (b *BitgetAdapter) CancelAllOrders(ctx context.Context) error {
+	if b.spot {
+		return b.spotCancelAllOrders(ctx)
+	}
+	meta := b.defaultMeta()
 	body := map[string]interface{}{
-		"productType": b.productType, // 必需：USDT-FUTURES
-		"marginCoin":  b.marginCoin,  // 必需：USDT
+		"productType": meta.productType, // 必需：USDT-FUTURES
+		"marginCoin":  meta.marginCoin,  // 必需：USDT
 	}
 
 	resp, err := b.client.DoRequest(ctx, "POST", "/api/v2/mix/order/cancel-all-orders", body)
@@ -555,7 +1079,15 @@ func (b *BitgetAdapter) CancelAllOrders(ctx context.Context) error {
 
 // GetOrder 查询订单
 func (b *BitgetAdapter) GetOrder(ctx context.Context, symbol string, orderID int64) (*Order, error) {
-	path := fmt.Sprintf("/api/v2/mix/order/detail?symbol=%s&productType=%s&orderId=%d", b.symbol, b.productType, orderID)
+	if b.spot {
+		return b.spotGetOrder(ctx, orderID)
+	}
+	meta, err := b.symbolMeta(ctx, symbol)
+	if err != nil {
+		return nil, fmt.Errorf("获取 %s 合约信息失败: %w", symbol, err)
+	}
+
+	path := fmt.Sprintf("/api/v2/mix/order/detail?symbol=%s&productType=%s&orderId=%d", meta.symbol, meta.productType, orderID)
 	resp, err := b.client.DoRequest(ctx, "GET", path, nil)
 	if err != nil {
 		return nil, err
@@ -622,7 +1154,15 @@ func (b *BitgetAdapter) GetOrder(ctx context.Context, symbol string, orderID int
 
 // GetOpenOrders 查询未完成订单
 func (b *BitgetAdapter) GetOpenOrders(ctx context.Context, symbol string) ([]*Order, error) {
-	path := fmt.Sprintf("/api/v2/mix/order/orders-pending?symbol=%s&productType=%s", b.symbol, b.productType)
+	if b.spot {
+		return b.spotGetOpenOrders(ctx, symbol)
+	}
+	meta, err := b.symbolMeta(ctx, symbol)
+	if err != nil {
+		return nil, fmt.Errorf("获取 %s 合约信息失败: %w", symbol, err)
+	}
+
+	path := fmt.Sprintf("/api/v2/mix/order/orders-pending?symbol=%s&productType=%s", meta.symbol, meta.productType)
 	resp, err := b.client.DoRequest(ctx, "GET", path, nil)
 	if err != nil {
 		return nil, err
@@ -704,7 +1244,11 @@ func (b *BitgetAdapter) GetOpenOrders(ctx context.Context, symbol string) ([]*Or
 
 // GetAccount 获取账户信息
 func (b *BitgetAdapter) GetAccount(ctx context.Context) (*Account, error) {
-	path := fmt.Sprintf("/api/v2/mix/account/account?symbol=%s&productType=%s&marginCoin=%s", b.symbol, b.productType, b.marginCoin)
+	if b.spot {
+		return b.spotGetAccount(ctx)
+	}
+	meta := b.defaultMeta()
+	path := fmt.Sprintf("/api/v2/mix/account/account?symbol=%s&productType=%s&marginCoin=%s", meta.symbol, meta.productType, meta.marginCoin)
 	resp, err := b.client.DoRequest(ctx, "GET", path, nil)
 	if err != nil {
 		return nil, err
@@ -769,7 +1313,15 @@ func (b *BitgetAdapter) GetAccount(ctx context.Context) (*Account, error) {
 
 // GetPositions 获取持仓信息
 func (b *BitgetAdapter) GetPositions(ctx context.Context, symbol string) ([]*Position, error) {
-	path := fmt.Sprintf("/api/v2/mix/position/single-position?symbol=%s&productType=%s&marginCoin=%s", b.symbol, b.productType, b.marginCoin)
+	if b.spot {
+		return b.spotGetPositions(ctx, symbol)
+	}
+	meta, err := b.symbolMeta(ctx, symbol)
+	if err != nil {
+		return nil, fmt.Errorf("获取 %s 合约信息失败: %w", symbol, err)
+	}
+
+	path := fmt.Sprintf("/api/v2/mix/position/single-position?symbol=%s&productType=%s&marginCoin=%s", meta.symbol, meta.productType, meta.marginCoin)
 	resp, err := b.client.DoRequest(ctx, "GET", path, nil)
 	if err != nil {
 		return nil, err
@@ -794,6 +1346,7 @@ func (b *BitgetAdapter) GetPositions(ctx context.Context, symbol string) ([]*Pos
 		LiquidationPrice  string `json:"liquidationPrice"`
 		KeepMarginRate    string `json:"keepMarginRate"`
 		MarkPrice         string `json:"markPrice"`
+		PosMode           string `json:"posMode"` // one_way_mode / hedge_mode
 	}
 
 	if err := json.Unmarshal(resp.Data, &dataList); err != nil {
@@ -829,6 +1382,7 @@ func (b *BitgetAdapter) GetPositions(ctx context.Context, symbol string) ([]*Pos
 			Leverage:       leverage,
 			MarginType:     item.MarginMode,
 			IsolatedMargin: margin,
+			Side:           hedgePositionSide(item.PosMode, item.HoldSide),
 		})
 	}
 
@@ -837,6 +1391,9 @@ func (b *BitgetAdapter) GetPositions(ctx context.Context, symbol string) ([]*Pos
 
 // GetBalance 获取余额
 func (b *BitgetAdapter) GetBalance(ctx context.Context, asset string) (float64, error) {
+	if b.spot {
+		return b.spotGetBalance(ctx, asset)
+	}
 	account, err := b.GetAccount(ctx)
 	if err != nil {
 		return 0, err
@@ -876,6 +1433,7 @@ func (b *BitgetAdapter) StartOrderStream(ctx context.Context, callback func(inte
 				ExecutedQty   float64
 				AvgPrice      float64
 				UpdateTime    int64
+				Fill          *FillEvent
 			}{
 				OrderID:       localUpdate.OrderID,
 				ClientOrderID: localUpdate.ClientOrderID, // 🔥 关键：传递 ClientOrderID
@@ -888,6 +1446,7 @@ func (b *BitgetAdapter) StartOrderStream(ctx context.Context, callback func(inte
 				ExecutedQty:   localUpdate.ExecutedQty,
 				AvgPrice:      localUpdate.AvgPrice,
 				UpdateTime:    localUpdate.UpdateTime,
+				Fill:          localUpdate.Fill,
 			}
 			callback(genericUpdate)
 		} else {
@@ -898,6 +1457,15 @@ func (b *BitgetAdapter) StartOrderStream(ctx context.Context, callback func(inte
 	return b.wsManager.Start(ctx, b.symbol, wrappedCallback)
 }
 
+// StartAccountStream 启动账户推送（私有频道 account 与 positions）
+// positions 频道每次推送全部持仓，account 频道推送保证金币种的权益与可用余额
+func (b *BitgetAdapter) StartAccountStream(ctx context.Context, callback AccountUpdateCallback) error {
+	if b.spot {
+		return errSpotUnsupported("账户推送")
+	}
+	return b.wsManager.StartAccountStream(ctx, callback)
+}
+
 // StopOrderStream 停止订单流
 func (b *BitgetAdapter) StopOrderStream() error {
 	b.wsManager.Stop()
@@ -952,7 +1520,7 @@ func (b *BitgetAdapter) StartPriceStream(ctx context.Context, symbol string, cal
 // StartKlineStream 启动K线流（WebSocket）
 func (b *BitgetAdapter) StartKlineStream(ctx context.Context, symbols []string, interval string, callback func(candle interface{})) error {
 	if b.klineWSManager == nil {
-		b.klineWSManager = NewKlineWebSocketManager()
+		b.klineWSManager = NewKlineWebSocketManager(b.wsManager.publicURL, b.wsManager.instType)
 	}
 	return b.klineWSManager.Start(ctx, symbols, interval, callback)
 }
@@ -981,8 +1549,325 @@ func (b *BitgetAdapter) ForceReconnectKlineStream() error {
 	return fmt.Errorf("K线流管理器未初始化")
 }
 
+// SetLeverage 设置交易对杠杆倍数
+func (b *BitgetAdapter) SetLeverage(ctx context.Context, symbol string, leverage int) error {
+	if b.spot {
+		return errSpotUnsupported("杠杆设置")
+	}
+	meta, err := b.symbolMeta(ctx, symbol)
+	if err != nil {
+		return fmt.Errorf("获取 %s 合约信息失败: %w", symbol, err)
+	}
+
+	body := map[string]interface{}{
+		"symbol":      meta.symbol,
+		"productType": meta.productType,
+		"marginCoin":  meta.marginCoin,
+		"leverage":    strconv.Itoa(leverage),
+	}
+	if _, err := b.client.DoRequest(ctx, "POST", "/api/v2/mix/account/set-leverage", body); err != nil {
+		return err
+	}
+	logger.Info("✅ [Bitget] %s 杠杆倍数已设置为 %dx", meta.symbol, leverage)
+	return nil
+}
+
+// SetMarginType 设置交易对保证金模式（逐仓/全仓），有持仓或挂单时交易所会拒绝
+func (b *BitgetAdapter) SetMarginType(ctx context.Context, symbol string, isolated bool) error {
+	if b.spot {
+		return errSpotUnsupported("保证金模式设置")
+	}
+	meta, err := b.symbolMeta(ctx, symbol)
+	if err != nil {
+		return fmt.Errorf("获取 %s 合约信息失败: %w", symbol, err)
+	}
+
+	marginMode := "crossed"
+	if isolated {
+		marginMode = "isolated"
+	}
+	body := map[string]interface{}{
+		"symbol":      meta.symbol,
+		"productType": meta.productType,
+		"marginCoin":  meta.marginCoin,
+		"marginMode":  marginMode,
+	}
+	if _, err := b.client.DoRequest(ctx, "POST", "/api/v2/mix/account/set-margin-mode", body); err != nil {
+		return err
+	}
+	logger.Info("✅ [Bitget] %s 保证金模式: %s", meta.symbol, marginMode)
+	return nil
+}
+
+// SetPositionMode 设置持仓模式（单向/双向，按合约类型生效），有持仓或挂单时交易所会拒绝
+func (b *BitgetAdapter) SetPositionMode(ctx context.Context, hedge bool) error {
+	if b.spot {
+		return errSpotUnsupported("持仓模式设置")
+	}
+	posMode := "one_way_mode"
+	if hedge {
+		posMode = "hedge_mode"
+	}
+	body := map[string]interface{}{
+		"productType": b.defaultMeta().productType,
+		"posMode":     posMode,
+	}
+	if _, err := b.client.DoRequest(ctx, "POST", "/api/v2/mix/account/set-position-mode", body); err != nil {
+		return err
+	}
+	b.posMode = posMode
+	logger.Info("✅ [Bitget] 持仓模式: %s", posMode)
+	return nil
+}
+
+// GetFundingRate 获取当期资金费率（同时查询标记价格与指数价格）
+func (b *BitgetAdapter) GetFundingRate(ctx context.Context, symbol string) (*FundingRate, error) {
+	if b.spot {
+		return nil, errSpotUnsupported("资金费率")
+	}
+	meta, err := b.symbolMeta(ctx, symbol)
+	if err != nil {
+		return nil, fmt.Errorf("获取 %s 合约信息失败: %w", symbol, err)
+	}
+
+	path := fmt.Sprintf("/api/v2/mix/market/current-fund-rate?symbol=%s&productType=%s", meta.symbol, meta.productType)
+	resp, err := b.client.DoRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var rates []struct {
+		Symbol      string `json:"symbol"`
+		FundingRate string `json:"fundingRate"`
+		NextUpdate  string `json:"nextUpdate"` // 下次结算时间（毫秒）
+	}
+	if err := json.Unmarshal(resp.Data, &rates); err != nil {
+		return nil, fmt.Errorf("解析资金费率失败: %w", err)
+	}
+	if len(rates) == 0 {
+		return nil, fmt.Errorf("未获取到 %s 的资金费率", meta.symbol)
+	}
+
+	rate, err := b.symbolPrice(ctx, meta)
+	if err != nil {
+		return nil, err
+	}
+	rate.FundingRate, _ = strconv.ParseFloat(rates[0].FundingRate, 64)
+	rate.NextFundingTime, _ = strconv.ParseInt(rates[0].NextUpdate, 10, 64)
+	return rate, nil
+}
+
+// GetMarkPrice 获取标记价格
+func (b *BitgetAdapter) GetMarkPrice(ctx context.Context, symbol string) (float64, error) {
+	if b.spot {
+		return 0, errSpotUnsupported("标记价格")
+	}
+	meta, err := b.symbolMeta(ctx, symbol)
+	if err != nil {
+		return 0, fmt.Errorf("获取 %s 合约信息失败: %w", symbol, err)
+	}
+	rate, err := b.symbolPrice(ctx, meta)
+	if err != nil {
+		return 0, err
+	}
+	return rate.MarkPrice, nil
+}
+
+// symbolPrice 查询标记价格与指数价格（/api/v2/mix/market/symbol-price）
+func (b *BitgetAdapter) symbolPrice(ctx context.Context, meta *symbolMeta) (*FundingRate, error) {
+	path := fmt.Sprintf("/api/v2/mix/market/symbol-price?symbol=%s&productType=%s", meta.symbol, meta.productType)
+	resp, err := b.client.DoRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var prices []struct {
+		Symbol     string `json:"symbol"`
+		IndexPrice string `json:"indexPrice"`
+		MarkPrice  string `json:"markPrice"`
+		Ts         string `json:"ts"`
+	}
+	if err := json.Unmarshal(resp.Data, &prices); err != nil {
+		return nil, fmt.Errorf("解析标记价格失败: %w", err)
+	}
+	if len(prices) == 0 {
+		return nil, fmt.Errorf("未获取到 %s 的标记价格", meta.symbol)
+	}
+
+	rate := &FundingRate{Symbol: prices[0].Symbol}
+	rate.MarkPrice, _ = strconv.ParseFloat(prices[0].MarkPrice, 64)
+	rate.IndexPrice, _ = strconv.ParseFloat(prices[0].IndexPrice, 64)
+	rate.Time, _ = strconv.ParseInt(prices[0].Ts, 10, 64)
+	return rate, nil
+}
+
+// StartMarkPriceStream 启动标记价格流（复用 ticker 频道，推送中包含标记价格与资金费率）
+// ticker 只订阅了当前交易对，因此只支持当前交易对
+func (b *BitgetAdapter) StartMarkPriceStream(ctx context.Context, symbol string, callback MarkPriceCallback) error {
+	if b.spot {
+		return errSpotUnsupported("标记价格流")
+	}
+	if convertToBitgetSymbol(symbol) != b.symbol {
+		return fmt.Errorf("Bitget 标记价格流只支持当前交易对 %s", b.symbol)
+	}
+
+	b.wsManager.SetMarkPriceCallback(callback)
+
+	// 如果 WebSocket 还没启动，启动公共频道（ticker）
+	if !b.wsManager.IsRunning() {
+		return b.wsManager.Start(ctx, b.symbol, nil)
+	}
+	return nil
+}
+
+// StartBookTickerStream 启动盘口最优价流（复用 ticker 频道，推送中包含买一/卖一价与数量）
+// ticker 只订阅了当前交易对，因此只支持当前交易对
+func (b *BitgetAdapter) StartBookTickerStream(ctx context.Context, symbol string, callback func(ticker *orderbook.Ticker)) error {
+	if convertToBitgetSymbol(symbol) != b.symbol {
+		return fmt.Errorf("Bitget 盘口最优价流只支持当前交易对 %s", b.symbol)
+	}
+
+	b.wsManager.SetBookTickerCallback(callback)
+
+	// 如果 WebSocket 还没启动，启动公共频道（ticker）
+	if !b.wsManager.IsRunning() {
+		return b.wsManager.Start(ctx, b.symbol, nil)
+	}
+	return nil
+}
+
+// GetFundingPayments 获取 since 之后的资金费收付记录（账单中的 contract_settle_fee）
+func (b *BitgetAdapter) GetFundingPayments(ctx context.Context, symbol string, since time.Time) ([]*FundingPayment, error) {
+	if b.spot {
+		return nil, errSpotUnsupported("资金费")
+	}
+	meta, err := b.symbolMeta(ctx, symbol)
+	if err != nil {
+		return nil, fmt.Errorf("获取 %s 合约信息失败: %w", symbol, err)
+	}
+
+	path := fmt.Sprintf("/api/v2/mix/account/bill?productType=%s&symbol=%s&businessType=contract_settle_fee&startTime=%d&limit=100",
+		meta.productType, meta.symbol, since.UnixMilli())
+	resp, err := b.client.DoRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var data struct {
+		Bills []struct {
+			BillID string `json:"billId"`
+			Symbol string `json:"symbol"`
+			Amount string `json:"amount"`
+			Coin   string `json:"coin"`
+			CTime  string `json:"cTime"`
+		} `json:"bills"`
+	}
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		return nil, fmt.Errorf("解析资金费账单失败: %w", err)
+	}
+
+	payments := make([]*FundingPayment, 0, len(data.Bills))
+	for _, bill := range data.Bills {
+		amount, _ := strconv.ParseFloat(bill.Amount, 64)
+		cTime, _ := strconv.ParseInt(bill.CTime, 10, 64)
+		payments = append(payments, &FundingPayment{
+			ID:     bill.BillID,
+			Symbol: bill.Symbol,
+			Amount: amount,
+			Asset:  bill.Coin,
+			Time:   cTime,
+		})
+	}
+	return payments, nil
+}
+
+// bitgetFeeDetail 成交手续费明细（totalFee 为负数表示支出）
+type bitgetFeeDetail struct {
+	FeeCoin  string `json:"feeCoin"`
+	TotalFee string `json:"totalFee"`
+}
+
+// GetMyTrades 获取 since 之后本账户的成交记录（/api/v2/mix/order/fills，按时间升序，limit 最大 100）
+func (b *BitgetAdapter) GetMyTrades(ctx context.Context, symbol string, since time.Time, limit int) ([]*Trade, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 100
+	}
+	if b.spot {
+		return b.spotGetMyTrades(ctx, symbol, since, limit)
+	}
+	meta, err := b.symbolMeta(ctx, symbol)
+	if err != nil {
+		return nil, fmt.Errorf("获取 %s 合约信息失败: %w", symbol, err)
+	}
+
+	path := fmt.Sprintf("/api/v2/mix/order/fills?productType=%s&symbol=%s&startTime=%d&limit=%d",
+		meta.productType, meta.symbol, since.UnixMilli(), limit)
+	resp, err := b.client.DoRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var data struct {
+		FillList []struct {
+			TradeID    string            `json:"tradeId"`
+			OrderID    string            `json:"orderId"`
+			Symbol     string            `json:"symbol"`
+			Side       string            `json:"side"`
+			Price      string            `json:"price"`
+			BaseVolume string            `json:"baseVolume"`
+			FeeDetail  []bitgetFeeDetail `json:"feeDetail"`
+			TradeScope string            `json:"tradeScope"`
+			CTime      string            `json:"cTime"`
+		} `json:"fillList"`
+	}
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		return nil, fmt.Errorf("解析成交记录失败: %w", err)
+	}
+
+	trades := make([]*Trade, 0, len(data.FillList))
+	for _, fill := range data.FillList {
+		orderID, _ := strconv.ParseInt(fill.OrderID, 10, 64)
+		price, _ := strconv.ParseFloat(fill.Price, 64)
+		qty, _ := strconv.ParseFloat(fill.BaseVolume, 64)
+		cTime, _ := strconv.ParseInt(fill.CTime, 10, 64)
+		trade := &Trade{
+			TradeID:  fill.TradeID,
+			OrderID:  orderID,
+			Symbol:   fill.Symbol,
+			Side:     Side(strings.ToUpper(fill.Side)),
+			Price:    price,
+			Quantity: qty,
+			IsMaker:  strings.HasPrefix(strings.ToLower(fill.TradeScope), "m"),
+			Time:     cTime,
+		}
+		for _, fee := range fill.FeeDetail {
+			totalFee, _ := strconv.ParseFloat(fee.TotalFee, 64)
+			trade.Commission -= totalFee
+			trade.CommissionAsset = fee.FeeCoin
+		}
+		trades = append(trades, trade)
+	}
+	sortTrades(trades)
+	return trades, nil
+}
+
+// sortTrades 按成交时间升序排列（Bitget 成交记录按时间倒序返回）
+func sortTrades(trades []*Trade) {
+	sort.Slice(trades, func(i, j int) bool {
+		return trades[i].Time < trades[j].Time
+	})
+}
+
 // GetHistoricalKlines 获取历史K线数据
 func (b *BitgetAdapter) GetHistoricalKlines(ctx context.Context, symbol string, interval string, limit int) ([]*Candle, error) {
+	if b.spot {
+		return b.spotHistoricalKlines(ctx, symbol, interval, limit)
+	}
+	meta, err := b.symbolMeta(ctx, symbol)
+	if err != nil {
+		return nil, fmt.Errorf("获取 %s 合约信息失败: %w", symbol, err)
+	}
+
 	// Bitget 支持的K线周期映射
 	// 1m, 3m, 5m, 15m, 30m, 1H, 4H, 6H, 12H, 1D, 3D, 1W, 1M
 	bitgetInterval := convertToBitgetInterval(interval)
@@ -997,7 +1882,7 @@ func (b *BitgetAdapter) GetHistoricalKlines(ctx context.Context, symbol string,
 	endTime := time.Now().UnixMilli()
 
 	path := fmt.Sprintf("/api/v2/mix/market/candles?symbol=%s&productType=%s&granularity=%s&limit=%d&endTime=%d",
-		b.symbol, b.productType, bitgetInterval, limit, endTime)
+		meta.symbol, meta.productType, bitgetInterval, limit, endTime)
 
 	resp, err := b.client.DoRequest(ctx, "GET", path, nil)
 	if err != nil {
@@ -1044,6 +1929,85 @@ func (b *BitgetAdapter) GetHistoricalKlines(ctx context.Context, symbol string,
 	return candles, nil
 }
 
+// bitgetHistoryCandlesMaxSpan history-candles 接口 startTime 与 endTime 最多相隔 90 天
+const bitgetHistoryCandlesMaxSpan = int64(90 * 24 * time.Hour / time.Millisecond)
+
+// GetKlinesBetween 获取开盘时间在 [start, end]（毫秒）内的K线，按时间升序
+// 使用 history-candles 接口（单次最多 200 根）：合约按 startTime/endTime 查询，跨度超过 90 天时拆分请求；
+// 现货只支持 endTime，返回 endTime 之前的 limit 根，区间外的K线由调用方过滤
+func (b *BitgetAdapter) GetKlinesBetween(ctx context.Context, symbol string, interval string, start, end int64, limit int) ([]*Candle, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 200
+	}
+	if b.spot {
+		path := fmt.Sprintf("/api/v2/spot/market/history-candles?symbol=%s&granularity=%s&endTime=%d&limit=%d",
+			convertToBitgetSymbol(symbol), convertToBitgetSpotInterval(interval), end+1, limit)
+		return b.requestCandles(ctx, symbol, path)
+	}
+
+	meta, err := b.symbolMeta(ctx, symbol)
+	if err != nil {
+		return nil, fmt.Errorf("获取 %s 合约信息失败: %w", symbol, err)
+	}
+	var candles []*Candle
+	for from := start; from <= end; {
+		to := from + bitgetHistoryCandlesMaxSpan - 1
+		if to > end {
+			to = end
+		}
+		path := fmt.Sprintf("/api/v2/mix/market/history-candles?symbol=%s&productType=%s&granularity=%s&startTime=%d&endTime=%d&limit=%d",
+			meta.symbol, meta.productType, convertToBitgetInterval(interval), from, to, limit)
+		page, err := b.requestCandles(ctx, symbol, path)
+		if err != nil {
+			return nil, err
+		}
+		candles = append(candles, page...)
+		from = to + 1
+	}
+	return candles, nil
+}
+
+// requestCandles 请求K线并按时间升序返回
+// 返回格式: [[timestamp, open, high, low, close, baseVolume, ...], ...]
+func (b *BitgetAdapter) requestCandles(ctx context.Context, symbol, path string) ([]*Candle, error) {
+	resp, err := b.client.DoRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("获取历史K线失败: %w", err)
+	}
+	var dataList [][]string
+	if err := json.Unmarshal(resp.Data, &dataList); err != nil {
+		return nil, fmt.Errorf("解析K线数据失败: %w", err)
+	}
+
+	candles := make([]*Candle, 0, len(dataList))
+	for _, item := range dataList {
+		if len(item) < 6 {
+			continue // 跳过无效数据
+		}
+
+		timestamp, _ := strconv.ParseInt(item[0], 10, 64)
+		open, _ := strconv.ParseFloat(item[1], 64)
+		high, _ := strconv.ParseFloat(item[2], 64)
+		low, _ := strconv.ParseFloat(item[3], 64)
+		close, _ := strconv.ParseFloat(item[4], 64)
+		volume, _ := strconv.ParseFloat(item[5], 64)
+
+		candles = append(candles, &Candle{
+			Symbol:    symbol,
+			Open:      open,
+			High:      high,
+			Low:       low,
+			Close:     close,
+			Volume:    volume,
+			Timestamp: timestamp,
+			IsClosed:  true, // 历史K线都是已完结的
+		})
+	}
+
+	sort.Slice(candles, func(i, j int) bool { return candles[i].Timestamp < candles[j].Timestamp })
+	return candles, nil
+}
+
 // convertToBitgetInterval 将标准K线周期转换为 Bitget 格式
 // 输入: 1m, 3m, 5m, 15m, 30m, 1h, 4h, 6h, 12h, 1d, 3d, 1w, 1M
 // 输出: 1m, 3m, 5m, 15m, 30m, 1H, 4H, 6H, 12H, 1D, 3D, 1W, 1M
@@ -1091,6 +2055,14 @@ func convertToBitgetSymbol(symbol string) string {
 	return symbol
 }
 
+// hedgePositionSide 双向持仓模式下将 holdSide 转换为 LONG/SHORT，单向持仓返回空
+func hedgePositionSide(posMode, holdSide string) string {
+	if posMode != "hedge_mode" {
+		return ""
+	}
+	return strings.ToUpper(holdSide)
+}
+
 // getHoldSide 根据持仓数量判断持仓方向
 func getHoldSide(size float64) string {
 	if size > 0 {
@@ -1103,20 +2075,20 @@ func getHoldSide(size float64) string {
 
 // GetPriceDecimals 获取价格精度（小数位数）
 func (b *BitgetAdapter) GetPriceDecimals() int {
-	return b.pricePlace
+	return b.defaultMeta().pricePlace
 }
 
 // GetQuantityDecimals 获取数量精度（小数位数）
 func (b *BitgetAdapter) GetQuantityDecimals() int {
-	return b.volumePlace
+	return b.defaultMeta().volumePlace
 }
 
 // GetBaseAsset 获取基础资产（交易币种）
 func (b *BitgetAdapter) GetBaseAsset() string {
-	return b.baseAsset
+	return b.defaultMeta().baseAsset
 }
 
 // GetQuoteAsset 获取计价资产（结算币种）
 func (b *BitgetAdapter) GetQuoteAsset() string {
-	return b.quoteAsset
+	return b.defaultMeta().quoteAsset
 }
@@ -0,0 +1,363 @@
+package bitget
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"opensqt/exchange/errs"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestBatchPlaceOrders(t *testing.T) {
+	batches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/mix/order/batch-place-order" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		batches++
+		var body struct {
+			MarginCoin string                   `json:"marginCoin"`
+			OrderList  []map[string]interface{} `json:"orderList"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("解析请求体失败: %v", err)
+		}
+		if body.MarginCoin != "USDT" {
+			t.Errorf("marginCoin = %q, want USDT", body.MarginCoin)
+		}
+
+		// 第2个订单保证金不足，其余成功（成功列表顺序与请求不一致）
+		success := []map[string]string{}
+		failure := []map[string]string{}
+		for i := len(body.OrderList) - 1; i >= 0; i-- {
+			oid := body.OrderList[i]["clientOid"].(string)
+			if i == 1 {
+				failure = append(failure, map[string]string{"clientOid": oid, "errorCode": "40762", "errorMsg": "The order amount exceeds the balance"})
+				continue
+			}
+			success = append(success, map[string]string{"clientOid": oid, "orderId": fmt.Sprintf("%d", 1000+i)})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"code": "00000",
+			"data": map[string]interface{}{"successList": success, "failureList": failure},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient("key", "secret", "pass")
+	client.baseURL = server.URL
+	mapped := map[int64]float64{}
+	adapter := &BitgetAdapter{
+		client: client,
+		symbol: "ETHUSDT",
+		metas: map[string]*symbolMeta{
+			"ETHUSDT": {symbol: "ETHUSDT", productType: "usdt-futures", marginCoin: "USDT", volumePlace: 2, pricePlace: 2, tickSize: 0.01},
+		},
+		orderMappingCallback: func(orderID int64, price float64) { mapped[orderID] = price },
+	}
+
+	reqs := make([]*OrderRequest, 4)
+	for i := range reqs {
+		reqs[i] = &OrderRequest{
+			Symbol:        "ETHUSDT",
+			Side:          SideBuy,
+			Type:          OrderTypeLimit,
+			Price:         3000 - float64(i),
+			Quantity:      0.01,
+			ClientOrderID: fmt.Sprintf("300000_B_%d", i),
+		}
+	}
+
+	placed, marginErr := adapter.BatchPlaceOrders(context.Background(), reqs)
+	if batches != 1 {
+		t.Errorf("批量请求次数 = %d, want 1", batches)
+	}
+	if !marginErr {
+		t.Error("批量中出现保证金不足应返回 true")
+	}
+	if len(placed) != 3 {
+		t.Fatalf("成功订单数 = %d, want 3", len(placed))
+	}
+	for _, o := range placed {
+		want := reqs[o.OrderID-1000]
+		if o.ClientOrderID != want.ClientOrderID || o.Price != want.Price {
+			t.Errorf("订单 %d 结果错位: %+v", o.OrderID, o)
+		}
+		if mapped[o.OrderID] != want.Price {
+			t.Errorf("订单 %d 未注册价格映射", o.OrderID)
+		}
+	}
+}
+
+func TestRateLimitedResponseCarriesRetryAfter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "2")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte("Too Many Requests"))
+	}))
+	defer server.Close()
+
+	client := NewClient("key", "secret", "pass")
+	client.baseURL = server.URL
+	_, err := client.DoRequest(context.Background(), "GET", "/api/v2/mix/account/accounts", nil)
+	if !errors.Is(err, errs.ErrRateLimited) {
+		t.Fatalf("非 JSON 的 429 响应也应识别为限频, got %v", err)
+	}
+	if d := errs.RetryAfter(err); d != 2*time.Second {
+		t.Errorf("RetryAfter = %v, want 2s", d)
+	}
+}
+
+func TestGetMyTradesSortsAscending(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/mix/order/fills" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		if q := r.URL.Query(); q.Get("startTime") != "1700000000000" || q.Get("limit") != "100" {
+			t.Errorf("查询参数错误: %s", r.URL.RawQuery)
+		}
+		// 倒序返回，手续费为负数表示支出
+		w.Write([]byte(`{"code":"00000","data":{"fillList":[
+			{"tradeId":"t2","orderId":"101","symbol":"ETHUSDT","side":"sell","price":"3010","baseVolume":"0.01",
+			 "feeDetail":[{"feeCoin":"USDT","totalFee":"-0.006"}],"tradeScope":"maker","cTime":"1700000002000"},
+			{"tradeId":"t1","orderId":"100","symbol":"ETHUSDT","side":"buy","price":"3000","baseVolume":"0.01",
+			 "feeDetail":[{"feeCoin":"USDT","totalFee":"-0.018"}],"tradeScope":"taker","cTime":"1700000001000"}]}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("key", "secret", "pass")
+	client.baseURL = server.URL
+	adapter := &BitgetAdapter{
+		client: client,
+		symbol: "ETHUSDT",
+		metas: map[string]*symbolMeta{
+			"ETHUSDT": {symbol: "ETHUSDT", productType: "usdt-futures", marginCoin: "USDT", volumePlace: 2, pricePlace: 2, tickSize: 0.01},
+		},
+	}
+
+	trades, err := adapter.GetMyTrades(context.Background(), "ETHUSDT", time.UnixMilli(1700000000000), 0)
+	if err != nil {
+		t.Fatalf("查询成交记录失败: %v", err)
+	}
+	if len(trades) != 2 || trades[0].TradeID != "t1" || trades[1].TradeID != "t2" {
+		t.Fatalf("成交记录未按时间升序: %+v", trades)
+	}
+	if got := trades[0]; got.OrderID != 100 || got.Side != SideBuy || got.Commission != 0.018 || got.IsMaker {
+		t.Errorf("成交记录解析错误: %+v", got)
+	}
+	if got := trades[1]; got.Side != SideSell || got.Commission != 0.006 || !got.IsMaker {
+		t.Errorf("成交记录解析错误: %+v", got)
+	}
+}
+
+// 改单同时传入新价格与原数量（按精度格式化），注册改单后的新订单ID到价格的映射
+func TestAmendOrderRegistersNewOrderID(t *testing.T) {
+	var modifyBody map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/mix/order/detail":
+			w.Write([]byte(`{"code":"00000","data":{"symbol":"ETHUSDT","orderId":"100","clientOid":"300000_S_1","size":"0.5","filledQty":"0.1","price":"3000","side":"sell","status":"partial-fill"}}`))
+		case "/api/v2/mix/order/modify-order":
+			if err := json.NewDecoder(r.Body).Decode(&modifyBody); err != nil {
+				t.Fatalf("解析请求体失败: %v", err)
+			}
+			w.Write([]byte(`{"code":"00000","data":{"orderId":"101","clientOid":"new_oid"}}`))
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("key", "secret", "pass")
+	client.baseURL = server.URL
+	mapped := map[int64]float64{}
+	adapter := &BitgetAdapter{
+		client: client,
+		symbol: "ETHUSDT",
+		metas: map[string]*symbolMeta{
+			"ETHUSDT": {symbol: "ETHUSDT", productType: "usdt-futures", marginCoin: "USDT", volumePlace: 2, pricePlace: 2, tickSize: 0.01},
+		},
+		orderMappingCallback: func(orderID int64, price float64) { mapped[orderID] = price },
+	}
+
+	order, err := adapter.AmendOrder(context.Background(), "ETHUSDT", 100, 3010.004, 0)
+	if err != nil {
+		t.Fatalf("改单失败: %v", err)
+	}
+	if modifyBody["orderId"] != "100" || modifyBody["newPrice"] != "3010.00" || modifyBody["newSize"] != "0.50" || modifyBody["newClientOid"] == "" {
+		t.Errorf("改单请求参数错误（未指定数量时应保持原数量）: %v", modifyBody)
+	}
+	if order.OrderID != 101 || order.ClientOrderID != "new_oid" || order.Side != SideSell || order.ExecutedQty != 0.1 {
+		t.Errorf("改单结果解析错误: %+v", order)
+	}
+	if _, ok := mapped[101]; !ok {
+		t.Error("改单后应注册新订单ID的价格映射")
+	}
+}
+
+func TestParseFill(t *testing.T) {
+	fill := parseFill(map[string]interface{}{
+		"tradeId": "123", "baseVolume": "0.01", "fillPrice": "3000.5",
+		"fillFee": "-0.006", "fillFeeCoin": "USDT", "tradeScope": "M", "fillTime": "1700000000000",
+	})
+	if fill == nil {
+		t.Fatal("包含 tradeId 的推送应解析出成交明细")
+	}
+	if fill.Commission != 0.006 || !fill.IsMaker || fill.Price != 3000.5 || fill.Quantity != 0.01 {
+		t.Errorf("成交明细解析错误: %+v（fillFee 负数表示支出，应转换为正数）", fill)
+	}
+	if parseFill(map[string]interface{}{"status": "live"}) != nil {
+		t.Error("没有成交的推送不应产生成交明细")
+	}
+}
+
+func TestPlaceOrderWebSocketWithRESTFallback(t *testing.T) {
+	restCalls := 0
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ws" {
+			conn, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				t.Errorf("升级 WebSocket 失败: %v", err)
+				return
+			}
+			defer conn.Close()
+			for {
+				var req struct {
+					Op   string `json:"op"`
+					Args []struct {
+						ID       string                 `json:"id"`
+						InstType string                 `json:"instType"`
+						Channel  string                 `json:"channel"`
+						Params   map[string]interface{} `json:"params"`
+					} `json:"args"`
+				}
+				if err := conn.ReadJSON(&req); err != nil {
+					return
+				}
+				arg := req.Args[0]
+				if req.Op != "trade" || arg.Channel != "place-order" || arg.InstType != "USDT-FUTURES" {
+					t.Errorf("unexpected trade request: %+v", req)
+				}
+				if arg.Params["clientOid"] == "reject" {
+					conn.WriteJSON(map[string]interface{}{
+						"event": "error", "code": 43012, "msg": "Insufficient balance",
+						"arg": []map[string]interface{}{{"id": arg.ID, "channel": "place-order"}},
+					})
+					continue
+				}
+				conn.WriteJSON(map[string]interface{}{
+					"event": "trade", "code": 0, "msg": "Success",
+					"arg": []map[string]interface{}{{
+						"id": arg.ID, "channel": "place-order",
+						"params": map[string]string{"orderId": "2001", "clientOid": arg.Params["clientOid"].(string)},
+					}},
+				})
+			}
+		}
+		if r.URL.Path != "/api/v2/mix/order/place-order" {
+			http.NotFound(w, r)
+			return
+		}
+		restCalls++
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"code": "00000",
+			"data": map[string]string{"orderId": "1001", "clientOid": "rest"},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient("key", "secret", "pass")
+	client.baseURL = server.URL
+	wsManager := NewWebSocketManager("key", "secret", "pass")
+	adapter := &BitgetAdapter{
+		client:       client,
+		wsManager:    wsManager,
+		symbol:       "ETHUSDT",
+		useWebSocket: true,
+		metas: map[string]*symbolMeta{
+			"ETHUSDT": {symbol: "ETHUSDT", productType: "usdt-futures", marginCoin: "USDT", volumePlace: 2, pricePlace: 2, tickSize: 0.01},
+		},
+	}
+	req := &OrderRequest{Symbol: "ETHUSDT", Side: SideBuy, Type: OrderTypeLimit, Price: 3000, Quantity: 0.01, ClientOrderID: "rest"}
+
+	// 私有频道未连接：改用 REST
+	order, err := adapter.PlaceOrder(context.Background(), req)
+	if err != nil || order.OrderID != 1001 || restCalls != 1 {
+		t.Fatalf("REST 回退失败: order=%+v err=%v restCalls=%d", order, err, restCalls)
+	}
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", nil)
+	if err != nil {
+		t.Fatalf("连接 WebSocket 失败: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	wsManager.ctx = ctx
+	wsManager.privateConn = conn
+	wsManager.tradeReady = true
+	go wsManager.handlePrivateMessages(conn)
+	defer conn.Close()
+
+	req.ClientOrderID = "ws"
+	order, err = adapter.PlaceOrder(context.Background(), req)
+	if err != nil || order.OrderID != 2001 || order.ClientOrderID != "ws" || restCalls != 1 {
+		t.Fatalf("WebSocket 下单失败: order=%+v err=%v restCalls=%d", order, err, restCalls)
+	}
+
+	req.ClientOrderID = "reject"
+	if _, err := adapter.PlaceOrder(context.Background(), req); !errors.Is(err, errs.ErrInsufficientMargin) {
+		t.Errorf("err = %v, want ErrInsufficientMargin", err)
+	}
+	if restCalls != 1 {
+		t.Errorf("交易所拒单不应改用 REST 重下, restCalls = %d", restCalls)
+	}
+}
+
+func TestDemoEnvironmentUsesPaperTrading(t *testing.T) {
+	var header string
+	var productTypes []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("paptrading")
+		productTypes = append(productTypes, r.URL.Query().Get("productType"))
+		json.NewEncoder(w).Encode(map[string]interface{}{"code": "00000", "data": []interface{}{}})
+	}))
+	defer server.Close()
+
+	demo, err := parseEnvironment("demo")
+	if err != nil || !demo {
+		t.Fatalf("parseEnvironment(demo) = %v, %v", demo, err)
+	}
+	if _, err := parseEnvironment("testnet"); err == nil {
+		t.Errorf("bitget 没有测试网，testnet 应返回错误")
+	}
+
+	client := NewClient("key", "secret", "pass")
+	client.baseURL = server.URL
+	client.demo = true
+	adapter := &BitgetAdapter{client: client, demo: true, metas: make(map[string]*symbolMeta)}
+
+	if _, _, err := adapter.queryContract(context.Background(), "SBTCSUSDT"); err == nil {
+		t.Fatalf("空合约列表应返回错误")
+	}
+	if header != "1" {
+		t.Errorf("paptrading 请求头 = %q, want 1", header)
+	}
+	want := []string{"susdt-futures", "scoin-futures", "susdc-futures"}
+	if strings.Join(productTypes, ",") != strings.Join(want, ",") {
+		t.Errorf("productTypes = %v, want %v", productTypes, want)
+	}
+}
@@ -7,7 +7,10 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"time"
+
+	"opensqt/exchange/errs"
 )
 
 const (
@@ -19,6 +22,7 @@ type Client struct {
 	httpClient *http.Client
 	signer     *Signer
 	baseURL    string
+	demo       bool // 模拟盘：请求头携带 paptrading: 1
 }
 
 // NewClient 创建 Bitget 客户端
@@ -71,6 +75,9 @@ func (c *Client) DoRequest(ctx context.Context, method, path string, body interf
 	req.Header.Set("ACCESS-PASSPHRASE", c.signer.GetPassphrase())
 	req.Header.Set("locale", "en-US")
 	req.Header.Set("X-CHANNEL-API-CODE", "3xh1b")
+	if c.demo {
+		req.Header.Set("paptrading", "1")
+	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -83,14 +90,53 @@ func (c *Client) DoRequest(ctx context.Context, method, path string, body interf
 		return nil, fmt.Errorf("读取响应失败: %w", err)
 	}
 
+	// 限频时响应体不一定是标准格式，按状态码识别，并带上 Retry-After 供请求额度管理暂停
+	if resp.StatusCode == http.StatusTooManyRequests {
+		err := classifyError("429", "", fmt.Errorf("bitget API 限频: 状态码=%d, 响应=%s", resp.StatusCode, string(respBody)))
+		return nil, errs.WithRetryAfter(err, errs.ParseRetryAfter(resp.Header))
+	}
+
 	var bitgetResp BitgetResponse
 	if err := json.Unmarshal(respBody, &bitgetResp); err != nil {
 		return nil, fmt.Errorf("解析响应失败: %w, 响应体: %s", err, string(respBody))
 	}
 
 	if bitgetResp.Code != "00000" {
-		return nil, fmt.Errorf("bitget API 错误: code=%s, msg=%s", bitgetResp.Code, bitgetResp.Msg)
+		return nil, classifyError(bitgetResp.Code, bitgetResp.Msg,
+			fmt.Errorf("bitget API 错误: code=%s, msg=%s", bitgetResp.Code, bitgetResp.Msg))
 	}
 
 	return &bitgetResp, nil
 }
+
+// ServerTime 查询服务器时间（公共接口，不签名，本地时钟偏差过大时也能对时）
+func (c *Client) ServerTime(ctx context.Context) (time.Time, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/api/v2/public/time", nil)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("创建请求失败: %w", err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Code string `json:"code"`
+		Msg  string `json:"msg"`
+		Data struct {
+			ServerTime string `json:"serverTime"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return time.Time{}, fmt.Errorf("解析响应失败: %w", err)
+	}
+	if result.Code != "00000" {
+		return time.Time{}, fmt.Errorf("bitget API 错误: code=%s, msg=%s", result.Code, result.Msg)
+	}
+	ms, err := strconv.ParseInt(result.Data.ServerTime, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("解析服务器时间失败: %w", err)
+	}
+	return time.UnixMilli(ms), nil
+}
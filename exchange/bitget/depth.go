@@ -0,0 +1,186 @@
+package bitget
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"opensqt/exchange/orderbook"
+	"opensqt/logger"
+
+	"github.com/gorilla/websocket"
+)
+
+// depthChannel 深度频道：books15 每次推送完整的前 15 档快照，无需本地合并增量
+const depthChannel = "books15"
+
+// depthSnapshot 深度数据（REST 与 WebSocket 格式相同，合约接口价位为数字，现货为字符串）
+type depthSnapshot struct {
+	Asks [][]json.Number `json:"asks"`
+	Bids [][]json.Number `json:"bids"`
+	Seq  int64           `json:"seq"`
+	Ts   json.Number     `json:"ts"`
+}
+
+// depthLevels 转换价位
+func depthLevels(raw [][]json.Number) []orderbook.Level {
+	levels := make([][]string, len(raw))
+	for i, item := range raw {
+		levels[i] = make([]string, len(item))
+		for j, v := range item {
+			levels[i][j] = v.String()
+		}
+	}
+	return orderbook.ParseLevels(levels)
+}
+
+// GetOrderBook 获取订单簿快照（合约 merge-depth，现货 orderbook）
+func (b *BitgetAdapter) GetOrderBook(ctx context.Context, symbol string, depth int) (*orderbook.Snapshot, error) {
+	var path string
+	if b.spot {
+		limit := depth
+		if limit <= 0 || limit > 150 {
+			limit = 150
+		}
+		path = fmt.Sprintf("/api/v2/spot/market/orderbook?symbol=%s&type=step0&limit=%d", convertToBitgetSymbol(symbol), limit)
+	} else {
+		meta, err := b.symbolMeta(ctx, symbol)
+		if err != nil {
+			return nil, fmt.Errorf("获取 %s 合约信息失败: %w", symbol, err)
+		}
+		limit := "max"
+		for _, l := range []int{1, 5, 15, 50} {
+			if depth > 0 && depth <= l {
+				limit = strconv.Itoa(l)
+				break
+			}
+		}
+		path = fmt.Sprintf("/api/v2/mix/market/merge-depth?symbol=%s&productType=%s&limit=%s", meta.symbol, meta.productType, limit)
+	}
+
+	resp, err := b.client.DoRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+	var data depthSnapshot
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		return nil, fmt.Errorf("解析深度数据失败: %w", err)
+	}
+
+	book := orderbook.NewBook(symbol)
+	book.Reset(depthLevels(data.Bids), depthLevels(data.Asks), data.Seq)
+	return book.Snapshot(depth), nil
+}
+
+// StartDepthStream 启动深度推送（books15 频道，独立的公共频道连接），断线自动重连
+// books15 每次推送都是完整快照，直接覆盖本地订单簿；只支持当前交易对
+func (b *BitgetAdapter) StartDepthStream(ctx context.Context, symbol string, callback func(book *orderbook.Snapshot)) error {
+	symbol = convertToBitgetSymbol(symbol)
+	if symbol != b.symbol {
+		return fmt.Errorf("Bitget 深度流只支持当前交易对 %s", b.symbol)
+	}
+	book := orderbook.NewBook(symbol)
+	logger.Info("🔗 [Bitget] 启动深度流: %s", symbol)
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				logger.Info("✅ [Bitget] 深度流已停止")
+				return
+			default:
+			}
+
+			conn, _, err := websocket.DefaultDialer.Dial(b.wsManager.publicURL, nil)
+			if err != nil {
+				logger.Error("❌ [Bitget] 深度流连接失败: %v，5秒后重试", err)
+				time.Sleep(5 * time.Second)
+				continue
+			}
+
+			b.readDepth(ctx, conn, symbol, book, callback)
+			conn.Close()
+			time.Sleep(2 * time.Second)
+		}
+	}()
+
+	return nil
+}
+
+// readDepth 订阅深度频道并读取推送，连接出错或 context 取消时返回
+func (b *BitgetAdapter) readDepth(ctx context.Context, conn *websocket.Conn, symbol string, book *orderbook.Book, callback func(book *orderbook.Snapshot)) {
+	subMsg := map[string]interface{}{
+		"op": "subscribe",
+		"args": []WSSubscribeArg{
+			{
+				InstType: b.wsManager.instType,
+				Channel:  depthChannel,
+				InstId:   symbol,
+			},
+		},
+	}
+	if err := conn.WriteJSON(subMsg); err != nil {
+		logger.Error("❌ [Bitget] 订阅深度频道失败: %v", err)
+		return
+	}
+
+	// 保活：每 15 秒发送 ping，连接关闭后退出
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(15 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				conn.Close()
+				return
+			case <-ticker.C:
+				conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+				if err := conn.WriteMessage(websocket.TextMessage, []byte("ping")); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	for {
+		conn.SetReadDeadline(time.Now().Add(90 * time.Second))
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.Warn("⚠️ [Bitget] 深度流读取错误: %v，正在重连", err)
+			}
+			return
+		}
+		if string(message) == "pong" {
+			continue
+		}
+
+		var msg struct {
+			Event string          `json:"event"`
+			Arg   WSSubscribeArg  `json:"arg"`
+			Data  []depthSnapshot `json:"data"`
+			Msg   string          `json:"msg"`
+		}
+		if err := json.Unmarshal(message, &msg); err != nil {
+			logger.Warn("⚠️ [Bitget] 解析深度消息失败: %v", err)
+			continue
+		}
+		if msg.Event == "error" {
+			logger.Error("❌ [Bitget] 深度频道错误: %s", msg.Msg)
+			continue
+		}
+		if msg.Arg.Channel != depthChannel || len(msg.Data) == 0 {
+			continue
+		}
+
+		data := msg.Data[0]
+		book.Reset(depthLevels(data.Bids), depthLevels(data.Asks), data.Seq)
+		callback(book.Snapshot(0))
+	}
+}
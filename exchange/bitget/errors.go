@@ -0,0 +1,38 @@
+package bitget
+
+import (
+	"strings"
+
+	"opensqt/exchange/errs"
+)
+
+// classifyError 将 Bitget 原生错误码映射为统一错误分类
+// 参考：https://www.bitget.com/api-doc/common/error-code/restapi
+func classifyError(code, msg string, err error) error {
+	var kind error
+	switch code {
+	case "40007", "40754", "40762", "43012": // 余额/保证金不足
+		kind = errs.ErrInsufficientMargin
+	case "429", "40010": // Too Many Requests
+		kind = errs.ErrRateLimited
+	case "40008": // Request timestamp expired
+		kind = errs.ErrClockSkew
+	case "40029", "40768", "43001": // Order does not exist
+		kind = errs.ErrOrderNotFound
+	case "40774": // 单向持仓的订单类型必须与持仓模式一致
+		kind = errs.ErrPositionModeMismatch
+	case "45110": // less than the minimum order amount
+		kind = errs.ErrMinNotional
+	default:
+		lower := strings.ToLower(msg)
+		switch {
+		case strings.Contains(lower, "post only"):
+			kind = errs.ErrPostOnlyWouldCross
+		case strings.Contains(lower, "insufficient balance"):
+			kind = errs.ErrInsufficientMargin
+		case strings.Contains(lower, "order does not exist"):
+			kind = errs.ErrOrderNotFound
+		}
+	}
+	return errs.Wrap(kind, "bitget", code, err)
+}
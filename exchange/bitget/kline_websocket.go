@@ -39,11 +39,15 @@ type KlineWebSocketManager struct {
 	reconnectDelay time.Duration
 	pingInterval   time.Duration
 	isRunning      bool
+	instType       string // 产品类型（USDT-FUTURES、SUSDT-FUTURES 或 SPOT）
+	wsURL          string // 公共频道地址（实盘与模拟盘不同）
 }
 
 // NewKlineWebSocketManager 创建K线WebSocket管理器
-func NewKlineWebSocketManager() *KlineWebSocketManager {
+func NewKlineWebSocketManager(wsURL, instType string) *KlineWebSocketManager {
 	return &KlineWebSocketManager{
+		instType:       instType,
+		wsURL:          wsURL,
 		done:           make(chan struct{}),
 		callbacks:      make(map[string]func(candle interface{})),
 		reconnectDelay: 10 * time.Second, // 增加重连延迟，避免频繁重连
@@ -102,7 +106,7 @@ func (k *KlineWebSocketManager) connectLoop(ctx context.Context) {
 		}
 
 		// Bitget WebSocket URL
-		wsURL := "wss://ws.bitget.com/v2/ws/public"
+		wsURL := k.wsURL
 
 		// 设置连接头部，模拟浏览器行为
 		headers := make(http.Header)
@@ -200,7 +204,7 @@ func (k *KlineWebSocketManager) subscribe(symbols []string, interval string) err
 		// 转换为Bitget格式
 		bitgetSymbol := convertToBitgetSymbol(symbol)
 		args[i] = map[string]string{
-			"instType": "USDT-FUTURES",
+			"instType": k.instType,
 			"channel":  channel,
 			"instId":   bitgetSymbol,
 		}
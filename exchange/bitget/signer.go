@@ -5,7 +5,8 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"fmt"
-	"time"
+
+	"opensqt/exchange/clock"
 )
 
 // Signer Bitget API 签名器
@@ -13,6 +14,7 @@ type Signer struct {
 	apiKey     string
 	secretKey  string
 	passphrase string
+	clock      *clock.Estimator // 服务器时间偏差，nil 时使用本地时间
 }
 
 // NewSigner 创建签名器
@@ -35,7 +37,7 @@ func (s *Signer) Sign(timestamp, method, requestPath, body string) string {
 
 // GetTimestamp 获取当前时间戳（毫秒）
 func (s *Signer) GetTimestamp() string {
-	return fmt.Sprintf("%d", time.Now().UnixMilli())
+	return fmt.Sprintf("%d", s.clock.Now().UnixMilli())
 }
 
 // GetAPIKey 获取 API Key
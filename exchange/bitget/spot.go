@@ -0,0 +1,566 @@
+package bitget
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"opensqt/exchange/errs"
+	"opensqt/logger"
+)
+
+// NewBitgetSpotAdapter 创建 Bitget 现货适配器
+// 现货没有杠杆与持仓：持仓以基础币种钱包余额表示，可用余额为计价币种的可用余额
+func NewBitgetSpotAdapter(cfg map[string]string, symbol string) (*BitgetAdapter, error) {
+	apiKey := cfg["api_key"]
+	secretKey := cfg["secret_key"]
+	passphrase := cfg["passphrase"]
+
+	if apiKey == "" || secretKey == "" || passphrase == "" {
+		return nil, fmt.Errorf("bitget API 配置不完整")
+	}
+	demo, err := parseEnvironment(cfg["environment"])
+	if err != nil {
+		return nil, err
+	}
+	if demo {
+		return nil, fmt.Errorf("bitget 模拟盘仅支持合约，现货请使用 environment=mainnet")
+	}
+
+	bitgetSymbol := convertToBitgetSymbol(symbol)
+
+	wsManager := NewWebSocketManager(apiKey, secretKey, passphrase)
+	wsManager.instType = instTypeSpot
+
+	adapter := &BitgetAdapter{
+		client:    NewClient(apiKey, secretKey, passphrase),
+		wsManager: wsManager,
+		symbol:    bitgetSymbol,
+		spot:      true,
+		metas:     make(map[string]*symbolMeta),
+	}
+	adapter.startClock()
+
+	ctxInit, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := adapter.symbolMeta(ctxInit, bitgetSymbol); err != nil {
+		logger.Warn("⚠️ [Bitget 现货] 获取交易对信息失败: %v，使用默认精度", err)
+		adapter.metas[bitgetSymbol] = &symbolMeta{
+			symbol:      bitgetSymbol,
+			volumePlace: 4,
+			pricePlace:  2,
+		}
+	}
+
+	return adapter, nil
+}
+
+// IsSpot 是否为现货适配器
+func (b *BitgetAdapter) IsSpot() bool {
+	return b.spot
+}
+
+// errSpotUnsupported 现货不支持的合约功能
+func errSpotUnsupported(feature string) error {
+	return fmt.Errorf("Bitget 现货不支持%s", feature)
+}
+
+// bitgetSpotSymbol 现货交易对信息（/api/v2/spot/public/symbols）
+type bitgetSpotSymbol struct {
+	Symbol            string `json:"symbol"`
+	BaseCoin          string `json:"baseCoin"`
+	QuoteCoin         string `json:"quoteCoin"`
+	MinTradeAmount    string `json:"minTradeAmount"`    // 最小下单数量
+	MinTradeUSDT      string `json:"minTradeUSDT"`      // 最小下单金额
+	PricePrecision    string `json:"pricePrecision"`    // 价格小数位
+	QuantityPrecision string `json:"quantityPrecision"` // 数量小数位
+}
+
+// querySpotSymbol 查询现货交易对信息
+func (b *BitgetAdapter) querySpotSymbol(ctx context.Context, symbol string) (*bitgetSpotSymbol, error) {
+	resp, err := b.client.DoRequest(ctx, "GET", "/api/v2/spot/public/symbols?symbol="+symbol, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var dataList []bitgetSpotSymbol
+	if err := json.Unmarshal(resp.Data, &dataList); err != nil {
+		return nil, fmt.Errorf("解析交易对信息失败: %w", err)
+	}
+	if len(dataList) == 0 {
+		return nil, fmt.Errorf("未找到交易对信息: %s", symbol)
+	}
+	return &dataList[0], nil
+}
+
+// loadSpotMeta 加载现货交易对的下单参数并缓存
+func (b *BitgetAdapter) loadSpotMeta(ctx context.Context, symbol string) (*symbolMeta, error) {
+	info, err := b.querySpotSymbol(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := &symbolMeta{
+		symbol:       symbol,
+		minTradeNum:  info.MinTradeAmount,
+		minTradeUSDT: info.MinTradeUSDT,
+		baseAsset:    info.BaseCoin,
+		quoteAsset:   info.QuoteCoin,
+	}
+	meta.volumePlace, _ = strconv.Atoi(info.QuantityPrecision)
+	meta.pricePlace, _ = strconv.Atoi(info.PricePrecision)
+	meta.tickSize = math.Pow10(-meta.pricePlace)
+
+	b.metaMu.Lock()
+	b.metas[symbol] = meta
+	b.metaMu.Unlock()
+
+	logger.Info("ℹ️ [Bitget 现货信息] %s - 数量精度:%d, 价格精度:%d, 基础币种:%s, 计价币种:%s",
+		symbol, meta.volumePlace, meta.pricePlace, meta.baseAsset, meta.quoteAsset)
+
+	return meta, nil
+}
+
+// spotSymbolInfo 获取现货交易规则
+func (b *BitgetAdapter) spotSymbolInfo(ctx context.Context, symbol string) (*SymbolInfo, error) {
+	s, err := b.querySpotSymbol(ctx, convertToBitgetSymbol(symbol))
+	if err != nil {
+		return nil, err
+	}
+
+	info := &SymbolInfo{
+		Symbol:             s.Symbol,
+		BaseAsset:          s.BaseCoin,
+		QuoteAsset:         s.QuoteCoin,
+		ContractMultiplier: 1,
+		MaxLeverage:        1,
+	}
+	info.PriceDecimals, _ = strconv.Atoi(s.PricePrecision)
+	info.QuantityDecimals, _ = strconv.Atoi(s.QuantityPrecision)
+	info.TickSize = math.Pow10(-info.PriceDecimals)
+	info.StepSize = math.Pow10(-info.QuantityDecimals)
+	info.MinQty, _ = strconv.ParseFloat(s.MinTradeAmount, 64)
+	info.MinNotional, _ = strconv.ParseFloat(s.MinTradeUSDT, 64)
+	return info, nil
+}
+
+// spotPlaceOrder 现货限价下单（PostOnly 使用 force=post_only）
+func (b *BitgetAdapter) spotPlaceOrder(ctx context.Context, req *OrderRequest) (*Order, error) {
+	meta, err := b.symbolMeta(ctx, req.Symbol)
+	if err != nil {
+		return nil, fmt.Errorf("获取 %s 交易对信息失败: %w", req.Symbol, err)
+	}
+
+	price := req.Price
+	if meta.tickSize > 0 {
+		price = math.Round(price/meta.tickSize) * meta.tickSize
+	}
+	force := "gtc"
+	if req.PostOnly {
+		force = "post_only"
+	}
+
+	body := map[string]interface{}{
+		"symbol":    meta.symbol,
+		"side":      strings.ToLower(string(req.Side)),
+		"orderType": "limit",
+		"force":     force,
+		"price":     fmt.Sprintf("%.*f", meta.pricePlace, price),
+		"size":      fmt.Sprintf("%.*f", meta.volumePlace, req.Quantity),
+	}
+	if req.ClientOrderID != "" {
+		body["clientOid"] = req.ClientOrderID
+	}
+
+	resp, err := b.client.DoRequest(ctx, "POST", "/api/v2/spot/trade/place-order", body)
+	if err != nil {
+		return nil, err
+	}
+
+	var data struct {
+		OrderID   string `json:"orderId"`
+		ClientOid string `json:"clientOid"`
+	}
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		return nil, fmt.Errorf("解析下单响应失败: %w", err)
+	}
+	orderID, _ := strconv.ParseInt(data.OrderID, 10, 64)
+	if orderID == 0 {
+		return nil, fmt.Errorf("下单响应中orderId为空或无效: %s", string(resp.Data))
+	}
+
+	return &Order{
+		OrderID:       orderID,
+		ClientOrderID: data.ClientOid,
+		Symbol:        req.Symbol,
+		Side:          req.Side,
+		Type:          req.Type,
+		Price:         req.Price,
+		Quantity:      req.Quantity,
+		Status:        OrderStatusNew,
+		CreatedAt:     time.Now(),
+	}, nil
+}
+
+// spotBatchPlaceOrders 现货批量下单（逐单下单，下单成功后立即注册订单ID到价格的映射）
+func (b *BitgetAdapter) spotBatchPlaceOrders(ctx context.Context, orders []*OrderRequest) ([]*Order, bool) {
+	placedOrders := make([]*Order, 0, len(orders))
+	hasMarginError := false
+
+	for _, req := range orders {
+		order, err := b.spotPlaceOrder(ctx, req)
+		if err != nil {
+			logger.Warn("⚠️ [Bitget 现货] 下单失败 %.2f %s: %v", req.Price, req.Side, err)
+			errs.Report(ctx, err)
+			if errors.Is(err, errs.ErrInsufficientMargin) {
+				hasMarginError = true
+			}
+			continue
+		}
+		if b.orderMappingCallback != nil {
+			b.orderMappingCallback(order.OrderID, req.Price)
+		}
+		placedOrders = append(placedOrders, order)
+	}
+
+	return placedOrders, hasMarginError
+}
+
+// spotCancelOrder 现货撤单
+func (b *BitgetAdapter) spotCancelOrder(ctx context.Context, symbol string, orderID int64) error {
+	body := map[string]interface{}{
+		"symbol":  convertToBitgetSymbol(symbol),
+		"orderId": strconv.FormatInt(orderID, 10),
+	}
+
+	if _, err := b.client.DoRequest(ctx, "POST", "/api/v2/spot/trade/cancel-order", body); err != nil {
+		if errors.Is(err, errs.ErrOrderNotFound) {
+			logger.Info("ℹ️ [Bitget 现货] 订单 %d 已不存在，跳过取消", orderID)
+			return nil
+		}
+		return fmt.Errorf("取消订单失败: %w", err)
+	}
+
+	logger.Info("✅ [Bitget 现货] 取消订单成功: %d", orderID)
+	return nil
+}
+
+// spotBatchCancelOrders 现货批量撤单（逐单撤销）
+func (b *BitgetAdapter) spotBatchCancelOrders(ctx context.Context, symbol string, orderIDs []int64) error {
+	for i, orderID := range orderIDs {
+		if err := b.spotCancelOrder(ctx, symbol, orderID); err != nil {
+			logger.Warn("⚠️ [Bitget 现货] 取消订单失败 %d: %v", orderID, err)
+		}
+		// 避免限频
+		if i < len(orderIDs)-1 {
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+	return nil
+}
+
+// spotCancelAllOrders 撤销当前交易对的所有现货挂单
+func (b *BitgetAdapter) spotCancelAllOrders(ctx context.Context) error {
+	body := map[string]interface{}{
+		"symbol": b.symbol,
+	}
+	if _, err := b.client.DoRequest(ctx, "POST", "/api/v2/spot/trade/cancel-symbol-order", body); err != nil {
+		return fmt.Errorf("一键全撤失败: %w", err)
+	}
+	logger.Info("✅ [Bitget 现货] 已撤销 %s 的所有挂单", b.symbol)
+	return nil
+}
+
+// bitgetSpotOrder 现货订单（orderInfo 与 unfilled-orders 共用）
+// 注意：unfilled-orders 中 priceAvg 为委托价格，basePrice 为成交均价
+type bitgetSpotOrder struct {
+	Symbol     string `json:"symbol"`
+	OrderID    string `json:"orderId"`
+	ClientOid  string `json:"clientOid"`
+	Price      string `json:"price"`
+	PriceAvg   string `json:"priceAvg"`
+	BasePrice  string `json:"basePrice"`
+	Size       string `json:"size"`
+	BaseVolume string `json:"baseVolume"`
+	Side       string `json:"side"`
+	Status     string `json:"status"`
+	UTime      string `json:"uTime"`
+}
+
+// convert 转换为通用订单，openOrder 表示来自 unfilled-orders
+func (o *bitgetSpotOrder) convert(openOrder bool) *Order {
+	orderID, _ := strconv.ParseInt(o.OrderID, 10, 64)
+	quantity, _ := strconv.ParseFloat(o.Size, 64)
+	executedQty, _ := strconv.ParseFloat(o.BaseVolume, 64)
+	updateTime, _ := strconv.ParseInt(o.UTime, 10, 64)
+
+	priceStr, avgStr := o.Price, o.PriceAvg
+	if openOrder {
+		priceStr, avgStr = o.PriceAvg, o.BasePrice
+	}
+	price, _ := strconv.ParseFloat(priceStr, 64)
+	avgPrice, _ := strconv.ParseFloat(avgStr, 64)
+
+	side := SideBuy
+	if o.Side == "sell" {
+		side = SideSell
+	}
+
+	return &Order{
+		OrderID:       orderID,
+		ClientOrderID: o.ClientOid,
+		Symbol:        o.Symbol,
+		Side:          side,
+		Type:          OrderTypeLimit,
+		Price:         price,
+		Quantity:      quantity,
+		ExecutedQty:   executedQty,
+		AvgPrice:      avgPrice,
+		Status:        spotOrderStatus(o.Status),
+		UpdateTime:    updateTime,
+	}
+}
+
+// spotOrderStatus 转换现货订单状态
+func spotOrderStatus(status string) OrderStatus {
+	switch status {
+	case "partially_filled":
+		return "PARTIALLY_FILLED"
+	case "filled":
+		return "FILLED"
+	case "cancelled", "canceled":
+		return "CANCELED"
+	default: // init, new, live
+		return OrderStatusNew
+	}
+}
+
+// spotGetOrder 查询现货订单
+func (b *BitgetAdapter) spotGetOrder(ctx context.Context, orderID int64) (*Order, error) {
+	resp, err := b.client.DoRequest(ctx, "GET", fmt.Sprintf("/api/v2/spot/trade/orderInfo?orderId=%d", orderID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var dataList []bitgetSpotOrder
+	if err := json.Unmarshal(resp.Data, &dataList); err != nil {
+		return nil, fmt.Errorf("解析订单详情失败: %w", err)
+	}
+	if len(dataList) == 0 {
+		return nil, fmt.Errorf("%w: %d", errs.ErrOrderNotFound, orderID)
+	}
+	return dataList[0].convert(false), nil
+}
+
+// spotGetOpenOrders 查询现货未完成订单
+func (b *BitgetAdapter) spotGetOpenOrders(ctx context.Context, symbol string) ([]*Order, error) {
+	path := "/api/v2/spot/trade/unfilled-orders?symbol=" + convertToBitgetSymbol(symbol)
+	resp, err := b.client.DoRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var dataList []bitgetSpotOrder
+	if err := json.Unmarshal(resp.Data, &dataList); err != nil {
+		return nil, fmt.Errorf("解析订单列表失败: %w", err)
+	}
+
+	orders := make([]*Order, 0, len(dataList))
+	for i := range dataList {
+		orders = append(orders, dataList[i].convert(true))
+	}
+	return orders, nil
+}
+
+// spotGetMyTrades 获取现货成交记录（/api/v2/spot/trade/fills）
+func (b *BitgetAdapter) spotGetMyTrades(ctx context.Context, symbol string, since time.Time, limit int) ([]*Trade, error) {
+	path := fmt.Sprintf("/api/v2/spot/trade/fills?symbol=%s&startTime=%d&limit=%d",
+		convertToBitgetSymbol(symbol), since.UnixMilli(), limit)
+	resp, err := b.client.DoRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var dataList []struct {
+		TradeID    string          `json:"tradeId"`
+		OrderID    string          `json:"orderId"`
+		Symbol     string          `json:"symbol"`
+		Side       string          `json:"side"`
+		PriceAvg   string          `json:"priceAvg"`
+		Size       string          `json:"size"`
+		FeeDetail  bitgetFeeDetail `json:"feeDetail"`
+		TradeScope string          `json:"tradeScope"`
+		CTime      string          `json:"cTime"`
+	}
+	if err := json.Unmarshal(resp.Data, &dataList); err != nil {
+		return nil, fmt.Errorf("解析成交记录失败: %w", err)
+	}
+
+	trades := make([]*Trade, 0, len(dataList))
+	for _, fill := range dataList {
+		orderID, _ := strconv.ParseInt(fill.OrderID, 10, 64)
+		price, _ := strconv.ParseFloat(fill.PriceAvg, 64)
+		qty, _ := strconv.ParseFloat(fill.Size, 64)
+		totalFee, _ := strconv.ParseFloat(fill.FeeDetail.TotalFee, 64)
+		cTime, _ := strconv.ParseInt(fill.CTime, 10, 64)
+		trades = append(trades, &Trade{
+			TradeID:         fill.TradeID,
+			OrderID:         orderID,
+			Symbol:          fill.Symbol,
+			Side:            Side(strings.ToUpper(fill.Side)),
+			Price:           price,
+			Quantity:        qty,
+			Commission:      -totalFee,
+			CommissionAsset: fill.FeeDetail.FeeCoin,
+			IsMaker:         strings.HasPrefix(strings.ToLower(fill.TradeScope), "m"),
+			Time:            cTime,
+		})
+	}
+	sortTrades(trades)
+	return trades, nil
+}
+
+// spotBalances 查询现货资产余额（币种 -> 可用、冻结）
+func (b *BitgetAdapter) spotBalances(ctx context.Context) (map[string][2]float64, error) {
+	resp, err := b.client.DoRequest(ctx, "GET", "/api/v2/spot/account/assets", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var dataList []struct {
+		Coin      string `json:"coin"`
+		Available string `json:"available"`
+		Frozen    string `json:"frozen"` // 挂单冻结
+		Locked    string `json:"locked"` // 其他业务锁定
+	}
+	if err := json.Unmarshal(resp.Data, &dataList); err != nil {
+		return nil, fmt.Errorf("解析现货资产失败: %w", err)
+	}
+
+	balances := make(map[string][2]float64, len(dataList))
+	for _, item := range dataList {
+		available, _ := strconv.ParseFloat(item.Available, 64)
+		frozen, _ := strconv.ParseFloat(item.Frozen, 64)
+		locked, _ := strconv.ParseFloat(item.Locked, 64)
+		balances[strings.ToUpper(item.Coin)] = [2]float64{available, frozen + locked}
+	}
+	return balances, nil
+}
+
+// spotGetAccount 获取现货账户信息
+// 钱包余额与可用余额均按计价币种统计，持仓为默认交易对基础币种的钱包余额
+func (b *BitgetAdapter) spotGetAccount(ctx context.Context) (*Account, error) {
+	balances, err := b.spotBalances(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := b.defaultMeta()
+	quote := balances[meta.quoteAsset]
+	account := &Account{
+		TotalWalletBalance: quote[0] + quote[1],
+		TotalMarginBalance: quote[0] + quote[1],
+		AvailableBalance:   quote[0],
+		AccountLeverage:    1,
+	}
+	if base := balances[meta.baseAsset]; base[0]+base[1] > 0 {
+		account.Positions = []*Position{{Symbol: b.symbol, Size: base[0] + base[1], Leverage: 1}}
+	}
+	return account, nil
+}
+
+// spotGetPositions 现货持仓：基础币种的钱包余额（可用 + 挂单冻结）
+func (b *BitgetAdapter) spotGetPositions(ctx context.Context, symbol string) ([]*Position, error) {
+	meta, err := b.symbolMeta(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	balances, err := b.spotBalances(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	base := balances[meta.baseAsset]
+	return []*Position{{Symbol: meta.symbol, Size: base[0] + base[1], Leverage: 1}}, nil
+}
+
+// spotGetBalance 获取现货资产的可用余额（不含挂单冻结部分）
+func (b *BitgetAdapter) spotGetBalance(ctx context.Context, asset string) (float64, error) {
+	balances, err := b.spotBalances(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return balances[strings.ToUpper(asset)][0], nil
+}
+
+// spotHistoricalKlines 获取现货历史K线
+func (b *BitgetAdapter) spotHistoricalKlines(ctx context.Context, symbol string, interval string, limit int) ([]*Candle, error) {
+	if limit > 1000 {
+		limit = 1000
+	}
+
+	path := fmt.Sprintf("/api/v2/spot/market/candles?symbol=%s&granularity=%s&limit=%d",
+		convertToBitgetSymbol(symbol), convertToBitgetSpotInterval(interval), limit)
+	resp, err := b.client.DoRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("获取历史K线失败: %w", err)
+	}
+
+	// 返回格式: [[timestamp, open, high, low, close, baseVolume, ...], ...]
+	var dataList [][]string
+	if err := json.Unmarshal(resp.Data, &dataList); err != nil {
+		return nil, fmt.Errorf("解析K线数据失败: %w", err)
+	}
+
+	candles := make([]*Candle, 0, len(dataList))
+	for _, item := range dataList {
+		if len(item) < 6 {
+			continue // 跳过无效数据
+		}
+
+		timestamp, _ := strconv.ParseInt(item[0], 10, 64)
+		open, _ := strconv.ParseFloat(item[1], 64)
+		high, _ := strconv.ParseFloat(item[2], 64)
+		low, _ := strconv.ParseFloat(item[3], 64)
+		close, _ := strconv.ParseFloat(item[4], 64)
+		volume, _ := strconv.ParseFloat(item[5], 64)
+
+		candles = append(candles, &Candle{
+			Symbol:    symbol,
+			Open:      open,
+			High:      high,
+			Low:       low,
+			Close:     close,
+			Volume:    volume,
+			Timestamp: timestamp,
+			IsClosed:  true,
+		})
+	}
+
+	// 按时间升序排列
+	sort.Slice(candles, func(i, j int) bool { return candles[i].Timestamp < candles[j].Timestamp })
+	return candles, nil
+}
+
+// convertToBitgetSpotInterval 将标准K线周期转换为 Bitget 现货格式
+// 输入: 1m, 5m, 15m, 30m, 1h, 4h, 6h, 12h, 1d, 3d, 1w, 1M
+// 输出: 1min, 5min, 15min, 30min, 1h, 4h, 6h, 12h, 1day, 3day, 1week, 1M
+func convertToBitgetSpotInterval(interval string) string {
+	switch interval {
+	case "1m", "3m", "5m", "15m", "30m":
+		return strings.TrimSuffix(interval, "m") + "min"
+	case "1d", "3d":
+		return strings.TrimSuffix(interval, "d") + "day"
+	case "1w":
+		return "1week"
+	default:
+		return interval
+	}
+}
@@ -3,7 +3,8 @@ package bitget
 /*
 Bitget WebSocket 架构说明：
 
-1. **WebSocket下单**：Bitget不支持WebSocket下单，所有下单操作请使用REST API
+1. **WebSocket下单**：配置 ws_order_entry 后通过私有频道 trade（place-order）下单，
+   私有频道未连接时自动改用 REST API（见 PlaceOrder）
 
 2. **WebSocket用途**：
    - 公共频道：订阅价格推送 (ticker)
@@ -32,6 +33,9 @@ import (
 	"sync"
 	"time"
 
+	"opensqt/exchange/clock"
+	"opensqt/exchange/orderbook"
+	"opensqt/exchange/wsapi"
 	"opensqt/logger"
 
 	"github.com/gorilla/websocket"
@@ -42,8 +46,17 @@ const (
 	BitgetWSPrivate = "wss://ws.bitget.com/v2/ws/private"
 	BitgetWSPublic  = "wss://ws.bitget.com/v2/ws/public"
 
+	// 模拟盘（demo）WebSocket 地址
+	BitgetWSPrivateDemo = "wss://wspap.bitget.com/v2/ws/private"
+	BitgetWSPublicDemo  = "wss://wspap.bitget.com/v2/ws/public"
+
 	// API Code - 重要：不要丢失！
 	BitgetAPICode = "3xh1b"
+
+	// 产品类型：U本位合约 / 模拟盘U本位合约 / 现货
+	instTypeFutures     = "USDT-FUTURES"
+	instTypeFuturesDemo = "SUSDT-FUTURES"
+	instTypeSpot        = "SPOT"
 )
 
 // WebSocketManager Bitget WebSocket 管理器
@@ -51,15 +64,27 @@ type WebSocketManager struct {
 	apiKey     string
 	secretKey  string
 	passphrase string
+	instType   string           // 订阅的产品类型（USDT-FUTURES、SUSDT-FUTURES 或 SPOT）
+	publicURL  string           // 公共频道地址（实盘与模拟盘不同）
+	privateURL string           // 私有频道地址
+	clock      *clock.Estimator // 服务器时间偏差（登录签名用），nil 时使用本地时间
 
 	// 连接管理
 	privateConn *websocket.Conn
 	publicConn  *websocket.Conn
 	mu          sync.RWMutex
+	writeMu     sync.Mutex // 串行化私有频道写操作（ping、订阅与下单可能并发）
+
+	// WebSocket 下单：私有频道登录并订阅完成后可用，请求按 id 关联响应
+	tradeReady bool
+	pending    wsapi.Pending
 
 	// 回调函数
-	orderCallback func(interface{})
-	priceCallback func(string, float64) // symbol, price
+	orderCallback   func(interface{})
+	priceCallback   func(string, float64) // symbol, price
+	accountCallback AccountUpdateCallback
+	markCallback    MarkPriceCallback
+	bookCallback    func(ticker *orderbook.Ticker)
 
 	// 控制
 	ctx    context.Context
@@ -88,6 +113,20 @@ func (w *WebSocketManager) SetPriceCallback(callback func(string, float64)) {
 	w.priceCallback = callback
 }
 
+// SetMarkPriceCallback 设置标记价格回调（从 ticker 推送中解析）
+func (w *WebSocketManager) SetMarkPriceCallback(callback MarkPriceCallback) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.markCallback = callback
+}
+
+// SetBookTickerCallback 设置盘口最优价回调（从 ticker 推送中解析买一/卖一）
+func (w *WebSocketManager) SetBookTickerCallback(callback func(ticker *orderbook.Ticker)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.bookCallback = callback
+}
+
 // IsRunning 检查 WebSocket 是否运行中
 func (w *WebSocketManager) IsRunning() bool {
 	w.mu.RLock()
@@ -136,6 +175,7 @@ type WSSubscribeArg struct {
 	InstType string `json:"instType"`
 	Channel  string `json:"channel"`
 	InstId   string `json:"instId,omitempty"`
+	Coin     string `json:"coin,omitempty"`
 }
 
 // NewWebSocketManager 创建 WebSocket 管理器
@@ -144,6 +184,9 @@ func NewWebSocketManager(apiKey, secretKey, passphrase string) *WebSocketManager
 		apiKey:               apiKey,
 		secretKey:            secretKey,
 		passphrase:           passphrase,
+		instType:             instTypeFutures,
+		publicURL:            BitgetWSPublic,
+		privateURL:           BitgetWSPrivate,
 		publicReconnectChan:  make(chan struct{}, 1),
 		privateReconnectChan: make(chan struct{}, 1),
 		reconnectDelay:       5 * time.Second,
@@ -165,7 +208,7 @@ func (w *WebSocketManager) publicConnectLoop() {
 		logger.Info("🔗 [Bitget WS公共] 正在连接...")
 
 		// 连接公共频道
-		conn, _, err := websocket.DefaultDialer.Dial(BitgetWSPublic, nil)
+		conn, _, err := websocket.DefaultDialer.Dial(w.publicURL, nil)
 		if err != nil {
 			logger.Error("❌ [Bitget WS公共] 连接失败: %v，%v后重试", err, w.reconnectDelay)
 			// 使用 select 等待，可以立即响应 context 取消
@@ -278,8 +321,8 @@ func (w *WebSocketManager) privateConnectLoop() {
 		symbol := w.subscribedSymbol
 		w.mu.Unlock()
 
-		// 订阅订单更新
-		if err := w.subscribeOrders(symbol); err != nil {
+		// 订阅订单更新（以及账户推送）
+		if err := w.subscribePrivate(symbol); err != nil {
 			logger.Error("❌ [Bitget WS私有] 订阅失败: %v", err)
 			conn.Close()
 			// 使用 select 等待，可以立即响应 context 取消
@@ -299,9 +342,19 @@ func (w *WebSocketManager) privateConnectLoop() {
 			close(done)
 		}()
 
+		w.mu.Lock()
+		w.tradeReady = true
+		w.mu.Unlock()
+
 		// 启动读取循环（阻塞直到连接断开）
 		w.handlePrivateMessages(conn)
 
+		// 已发出未响应的下单请求状态未知，交由调用方对账
+		w.mu.Lock()
+		w.tradeReady = false
+		w.mu.Unlock()
+		w.pending.FailAll(wsapi.ErrDisconnected)
+
 		// 等待 keepAlive 退出（同时监听 context 取消）
 		select {
 		case <-done:
@@ -378,6 +431,31 @@ func (w *WebSocketManager) Start(ctx context.Context, symbol string, callback fu
 	return nil
 }
 
+// StartAccountStream 注册账户推送回调并订阅 account/positions 频道
+// 私有频道已连接时立即订阅，否则在（重新）连接后统一订阅
+func (w *WebSocketManager) StartAccountStream(ctx context.Context, callback AccountUpdateCallback) error {
+	w.mu.Lock()
+	w.accountCallback = callback
+	if w.ctx == nil {
+		w.ctx, w.cancel = context.WithCancel(ctx)
+	}
+	conn := w.privateConn
+	started := w.privateHandlerStarted
+	w.privateHandlerStarted = true
+	w.mu.Unlock()
+
+	if !started {
+		w.wg.Add(1)
+		go w.privateConnectLoop()
+		logger.Info("✅ [Bitget WebSocket] 启动成功，将订阅账户与持仓推送")
+		return nil
+	}
+	if conn != nil {
+		return w.subscribeAccount(conn)
+	}
+	return nil
+}
+
 // Stop 停止 WebSocket
 func (w *WebSocketManager) Stop() {
 	// 🔥 第一步：取消 context 并关闭连接（需要加锁）
@@ -401,14 +479,14 @@ func (w *WebSocketManager) Stop() {
 
 // connectPrivate 连接私有 WebSocket
 func (w *WebSocketManager) connectPrivate() error {
-	conn, _, err := websocket.DefaultDialer.Dial(BitgetWSPrivate, nil)
+	conn, _, err := websocket.DefaultDialer.Dial(w.privateURL, nil)
 	if err != nil {
 		return err
 	}
 	w.privateConn = conn
 
 	// 发送登录认证
-	timestamp := fmt.Sprintf("%d", time.Now().Unix())
+	timestamp := fmt.Sprintf("%d", w.clock.Now().Unix())
 	sign := w.generateSign(timestamp, "GET", "/user/verify")
 
 	loginMsg := map[string]interface{}{
@@ -444,7 +522,7 @@ func (w *WebSocketManager) connectPrivate() error {
 
 // connectPublic 连接公共 WebSocket
 func (w *WebSocketManager) connectPublic() error {
-	conn, _, err := websocket.DefaultDialer.Dial(BitgetWSPublic, nil)
+	conn, _, err := websocket.DefaultDialer.Dial(w.publicURL, nil)
 	if err != nil {
 		return err
 	}
@@ -453,15 +531,19 @@ func (w *WebSocketManager) connectPublic() error {
 	return nil
 }
 
-// subscribeOrders 订阅订单更新
+// subscribeOrders 订阅订单更新（合约订阅所有交易对，现货订阅当前交易对）
 func (w *WebSocketManager) subscribeOrders(symbol string) error {
+	instID := "default" // 订阅所有交易对
+	if w.instType == instTypeSpot {
+		instID = symbol
+	}
 	subMsg := map[string]interface{}{
 		"op": "subscribe",
 		"args": []WSSubscribeArg{
 			{
-				InstType: "USDT-FUTURES",
+				InstType: w.instType,
 				Channel:  "orders",
-				InstId:   "default", // 订阅所有交易对
+				InstId:   instID,
 			},
 		},
 	}
@@ -470,13 +552,51 @@ func (w *WebSocketManager) subscribeOrders(symbol string) error {
 	return w.privateConn.WriteJSON(subMsg)
 }
 
+// subscribePrivate 订阅私有频道：订单更新，已注册账户回调时同时订阅账户与持仓
+func (w *WebSocketManager) subscribePrivate(symbol string) error {
+	if err := w.subscribeOrders(symbol); err != nil {
+		return err
+	}
+
+	w.mu.RLock()
+	hasAccount := w.accountCallback != nil
+	conn := w.privateConn
+	w.mu.RUnlock()
+	if !hasAccount {
+		return nil
+	}
+	return w.subscribeAccount(conn)
+}
+
+// subscribeAccount 订阅账户（保证金币种余额）与持仓频道
+func (w *WebSocketManager) subscribeAccount(conn *websocket.Conn) error {
+	subMsg := map[string]interface{}{
+		"op": "subscribe",
+		"args": []WSSubscribeArg{
+			{
+				InstType: w.instType,
+				Channel:  "account",
+				Coin:     "default", // 订阅所有保证金币种
+			},
+			{
+				InstType: w.instType,
+				Channel:  "positions",
+				InstId:   "default", // 订阅所有交易对
+			},
+		},
+	}
+
+	logger.Info("📡 [Bitget WS] 订阅私有频道: account, positions")
+	return w.writeJSON(conn, subMsg)
+}
+
 // subscribeTicker 订阅价格更新
 func (w *WebSocketManager) subscribeTicker(symbol string) error {
 	subMsg := map[string]interface{}{
 		"op": "subscribe",
 		"args": []WSSubscribeArg{
 			{
-				InstType: "USDT-FUTURES",
+				InstType: w.instType,
 				Channel:  "ticker",
 				InstId:   symbol,
 			},
@@ -516,6 +636,11 @@ func (w *WebSocketManager) handlePrivateMessages(conn *websocket.Conn) {
 				continue
 			}
 
+			// 下单响应（arg 为数组）按 id 交给等待中的请求
+			if w.handleTradeResponse(message) {
+				continue
+			}
+
 			var msg struct {
 				Event  string          `json:"event"`  // subscribe / error / login
 				Op     string          `json:"op"`     // trade (下单响应)
@@ -553,6 +678,16 @@ func (w *WebSocketManager) handlePrivateMessages(conn *websocket.Conn) {
 				w.handleOrderUpdate(msg.Data)
 				continue
 			}
+
+			// 处理账户与持仓推送
+			if msg.Arg.Channel == "account" && len(msg.Data) > 0 {
+				w.handleAccountUpdate(msg.Data)
+				continue
+			}
+			if msg.Arg.Channel == "positions" && len(msg.Data) > 0 {
+				w.handlePositionsUpdate(msg.Data)
+				continue
+			}
 		}
 	}
 }
@@ -647,6 +782,94 @@ func (w *WebSocketManager) handleOrderUpdate(data json.RawMessage) {
 	}
 }
 
+// handleAccountUpdate 处理账户推送（每个保证金币种一条）
+func (w *WebSocketManager) handleAccountUpdate(data json.RawMessage) {
+	w.mu.RLock()
+	callback := w.accountCallback
+	w.mu.RUnlock()
+	if callback == nil {
+		return
+	}
+
+	var items []struct {
+		MarginCoin string `json:"marginCoin"`
+		Available  string `json:"available"`
+		Equity     string `json:"equity"`
+	}
+	if err := json.Unmarshal(data, &items); err != nil {
+		logger.Warn("⚠️ [Bitget WebSocket] 解析账户推送失败: %v", err)
+		return
+	}
+
+	update := &AccountUpdate{UpdateTime: time.Now().UnixMilli()}
+	for _, item := range items {
+		available, _ := strconv.ParseFloat(item.Available, 64)
+		equity, _ := strconv.ParseFloat(item.Equity, 64)
+		update.Balances = append(update.Balances, BalanceUpdate{
+			Asset:            item.MarginCoin,
+			WalletBalance:    equity, // 与 GetAccount 一致，以权益作为钱包余额
+			AvailableBalance: available,
+			HasAvailable:     true,
+		})
+	}
+	callback(update)
+}
+
+// handlePositionsUpdate 处理持仓推送（每次推送全部持仓，已平仓的交易对不再出现）
+func (w *WebSocketManager) handlePositionsUpdate(data json.RawMessage) {
+	w.mu.RLock()
+	callback := w.accountCallback
+	w.mu.RUnlock()
+	if callback == nil {
+		return
+	}
+
+	var items []struct {
+		InstId       string `json:"instId"`
+		HoldSide     string `json:"holdSide"`
+		Total        string `json:"total"`
+		OpenPriceAvg string `json:"openPriceAvg"`
+		MarkPrice    string `json:"markPrice"`
+		UnrealizedPL string `json:"unrealizedPL"`
+		Leverage     string `json:"leverage"`
+		MarginMode   string `json:"marginMode"`
+		MarginSize   string `json:"marginSize"`
+		PosMode      string `json:"posMode"`
+	}
+	if err := json.Unmarshal(data, &items); err != nil {
+		logger.Warn("⚠️ [Bitget WebSocket] 解析持仓推送失败: %v", err)
+		return
+	}
+
+	update := &AccountUpdate{PositionsSnapshot: true, UpdateTime: time.Now().UnixMilli()}
+	for _, item := range items {
+		total, _ := strconv.ParseFloat(item.Total, 64)
+		entryPrice, _ := strconv.ParseFloat(item.OpenPriceAvg, 64)
+		markPrice, _ := strconv.ParseFloat(item.MarkPrice, 64)
+		unrealizedPNL, _ := strconv.ParseFloat(item.UnrealizedPL, 64)
+		leverage, _ := strconv.Atoi(item.Leverage)
+		margin, _ := strconv.ParseFloat(item.MarginSize, 64)
+
+		// Bitget 使用 holdSide 表示方向，需要转换为正负数
+		size := total
+		if item.HoldSide == "short" {
+			size = -total
+		}
+		update.Positions = append(update.Positions, &Position{
+			Symbol:         item.InstId,
+			Size:           size,
+			EntryPrice:     entryPrice,
+			MarkPrice:      markPrice,
+			UnrealizedPNL:  unrealizedPNL,
+			Leverage:       leverage,
+			MarginType:     item.MarginMode,
+			IsolatedMargin: margin,
+			Side:           hedgePositionSide(item.PosMode, item.HoldSide),
+		})
+	}
+	callback(update)
+}
+
 // handlePriceUpdate 处理价格更新
 func (w *WebSocketManager) handlePriceUpdate(data json.RawMessage) {
 	var updates []map[string]interface{}
@@ -677,6 +900,66 @@ func (w *WebSocketManager) handlePriceUpdate(data json.RawMessage) {
 				}
 			}
 		}
+
+		w.mu.RLock()
+		markCallback := w.markCallback
+		bookCallback := w.bookCallback
+		w.mu.RUnlock()
+		if markCallback != nil {
+			if rate := parseMarkPrice(update); rate != nil {
+				markCallback(rate)
+			}
+		}
+		if bookCallback != nil {
+			if ticker := parseBookTicker(update); ticker != nil {
+				bookCallback(ticker)
+			}
+		}
+	}
+}
+
+// parseBookTicker 从 ticker 推送中解析买一/卖一价与数量
+func parseBookTicker(update map[string]interface{}) *orderbook.Ticker {
+	parse := func(key string) float64 {
+		str, _ := update[key].(string)
+		value, _ := strconv.ParseFloat(str, 64)
+		return value
+	}
+
+	ticker := &orderbook.Ticker{
+		BidPrice: parse("bidPr"),
+		BidQty:   parse("bidSz"),
+		AskPrice: parse("askPr"),
+		AskQty:   parse("askSz"),
+		Time:     int64(parse("ts")),
+	}
+	if ticker.BidPrice <= 0 || ticker.AskPrice <= 0 {
+		return nil
+	}
+	ticker.Symbol, _ = update["instId"].(string)
+	return ticker
+}
+
+// parseMarkPrice 从 ticker 推送中解析标记价格与资金费率
+func parseMarkPrice(update map[string]interface{}) *FundingRate {
+	parse := func(key string) float64 {
+		str, _ := update[key].(string)
+		value, _ := strconv.ParseFloat(str, 64)
+		return value
+	}
+
+	markPrice := parse("markPrice")
+	if markPrice <= 0 {
+		return nil
+	}
+	symbol, _ := update["instId"].(string)
+	return &FundingRate{
+		Symbol:          symbol,
+		FundingRate:     parse("fundingRate"),
+		MarkPrice:       markPrice,
+		IndexPrice:      parse("indexPrice"),
+		NextFundingTime: int64(parse("nextFundingTime")),
+		Time:            int64(parse("ts")),
 	}
 }
 
@@ -728,6 +1011,14 @@ func (w *WebSocketManager) parseOrderUpdate(data map[string]interface{}) *OrderU
 			logger.Warn("⚠️ [Bitget WS] 未知 side 值: %s (tradeSide=%s, posSide=%s), 默认按买单处理", sideStr, tradeSideStr, posSideStr)
 		}
 	}
+	// 双向持仓模式下平仓单的 side 为持仓方向（平多为 buy、平空为 sell），转换为实际买卖方向
+	if strings.ToLower(strings.TrimSpace(tradeSideStr)) == "close" && (lowerSide == "buy" || lowerSide == "sell") {
+		if side == SideBuy {
+			side = SideSell
+		} else {
+			side = SideBuy
+		}
+	}
 
 	// 🔥 关键修复：Bitget V2 WebSocket 订单推送的状态值
 	// 根据官方文档：live=挂单中, partially_filled=部分成交, filled=完全成交, cancelled=已撤销
@@ -759,13 +1050,107 @@ func (w *WebSocketManager) parseOrderUpdate(data map[string]interface{}) *OrderU
 		ExecutedQty:   executedQty,
 		AvgPrice:      avgPrice,
 		UpdateTime:    updateTime,
+		Fill:          parseFill(data),
+	}
+}
+
+// parseFill 解析订单推送中的本次成交明细（tradeId 为空表示本次推送没有新成交）
+// Bitget 的 fillFee 为负数表示支出，这里统一转换为正数表示支出
+func parseFill(data map[string]interface{}) *FillEvent {
+	tradeID, _ := data["tradeId"].(string)
+	fillQtyStr, _ := data["baseVolume"].(string)
+	fillQty, _ := strconv.ParseFloat(fillQtyStr, 64)
+	if tradeID == "" || fillQty <= 0 {
+		return nil
+	}
+
+	fillPriceStr, _ := data["fillPrice"].(string)
+	fillFeeStr, _ := data["fillFee"].(string)
+	feeCoin, _ := data["fillFeeCoin"].(string)
+	tradeScope, _ := data["tradeScope"].(string)
+	fillTimeStr, _ := data["fillTime"].(string)
+
+	fillPrice, _ := strconv.ParseFloat(fillPriceStr, 64)
+	fillFee, _ := strconv.ParseFloat(fillFeeStr, 64)
+	fillTime, _ := strconv.ParseInt(fillTimeStr, 10, 64)
+
+	return &FillEvent{
+		TradeID:         tradeID,
+		Price:           fillPrice,
+		Quantity:        fillQty,
+		Commission:      -fillFee,
+		CommissionAsset: feeCoin,
+		IsMaker:         strings.HasPrefix(strings.ToLower(tradeScope), "m"), // M/maker
+		Time:            fillTime,
+	}
+}
+
+// PlaceOrder 通过私有频道 trade 下单（channel=place-order），返回响应中的 params（orderId、clientOid）
+// 私有频道未就绪或请求未能发出时返回 wsapi.ErrNotConnected，调用方可改用 REST
+func (w *WebSocketManager) PlaceOrder(ctx context.Context, instType, instID string, params map[string]interface{}) ([]byte, error) {
+	w.mu.RLock()
+	conn := w.privateConn
+	ready := w.tradeReady
+	w.mu.RUnlock()
+
+	if conn == nil || !ready {
+		return nil, fmt.Errorf("%w: 私有频道未就绪", wsapi.ErrNotConnected)
 	}
+
+	return w.pending.Do(ctx, wsapi.DefaultTimeout, func(id string) error {
+		tradeMsg := map[string]interface{}{
+			"op": "trade",
+			"args": []map[string]interface{}{
+				{
+					"id":       id,
+					"instType": instType,
+					"instId":   instID,
+					"channel":  "place-order",
+					"params":   params,
+				},
+			},
+		}
+		return w.writeJSON(conn, tradeMsg)
+	})
+}
+
+// handleTradeResponse 处理下单响应，不是下单响应时返回 false
+// 成功: {"event":"trade","arg":[{"id":...,"params":{"orderId":...}}],"code":0}
+// 失败: {"event":"error","arg":[{"id":...}],"code":43012,"msg":...}
+func (w *WebSocketManager) handleTradeResponse(message []byte) bool {
+	var resp struct {
+		Event string `json:"event"`
+		Arg   []struct {
+			ID     string          `json:"id"`
+			Params json.RawMessage `json:"params"`
+		} `json:"arg"`
+		Code json.RawMessage `json:"code"`
+		Msg  string          `json:"msg"`
+	}
+	if err := json.Unmarshal(message, &resp); err != nil || len(resp.Arg) == 0 || resp.Arg[0].ID == "" {
+		return false
+	}
+	if resp.Event != "trade" && resp.Event != "error" {
+		return false
+	}
+
+	code := (&WSMessage{Code: resp.Code}).GetCodeString()
+	if resp.Event == "error" || (code != "0" && code != "") {
+		err := classifyError(code, resp.Msg, fmt.Errorf("Bitget WebSocket 下单失败: code=%s, msg=%s", code, resp.Msg))
+		w.pending.Resolve(resp.Arg[0].ID, nil, err)
+		return true
+	}
+
+	w.pending.Resolve(resp.Arg[0].ID, resp.Arg[0].Params, nil)
+	return true
 }
 
-// PlaceOrderWS 已废弃 - Bitget不支持WebSocket下单，请使用REST API
-// 保留方法签名以兼容旧代码，但返回错误
-func (w *WebSocketManager) PlaceOrderWS(symbol string, side string, price, quantity float64, priceDecimals int) (string, error) {
-	return "", fmt.Errorf("Bitget不支持WebSocket下单，请使用REST API")
+// writeJSON 向连接写入 JSON 消息（串行化并发写，ping 设置的写超时需要刷新）
+func (w *WebSocketManager) writeJSON(conn *websocket.Conn, v interface{}) error {
+	w.writeMu.Lock()
+	defer w.writeMu.Unlock()
+	conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	return conn.WriteJSON(v)
 }
 
 // GetLatestPrice 获取最新价格
@@ -786,8 +1171,10 @@ func (w *WebSocketManager) keepAlive(conn *websocket.Conn, connType string, reco
 			return
 		case <-ticker.C:
 			if conn != nil {
+				w.writeMu.Lock()
 				conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
 				err := conn.WriteMessage(websocket.TextMessage, []byte("ping"))
+				w.writeMu.Unlock()
 				if err != nil {
 					logger.Warn("⚠️ [Bitget WS%s] 发送 ping 失败: %v", connType, err)
 					// 🔥 关键：ping 失败说明连接已断开，触发重连并退出
@@ -0,0 +1,1202 @@
+package bybit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"opensqt/exchange/clock"
+	"opensqt/exchange/errs"
+	"opensqt/exchange/orderbook"
+	"opensqt/exchange/venue"
+	"opensqt/logger"
+)
+
+// 订单、持仓与行情类型与其他交易所共用（定义在 venue 包中，避免与 exchange 包循环导入）
+type (
+	Side                = venue.Side
+	OrderType           = venue.OrderType
+	OrderStatus         = venue.OrderStatus
+	TimeInForce         = venue.TimeInForce
+	OrderRequest        = venue.OrderRequest
+	Order               = venue.Order
+	Position            = venue.Position
+	Account             = venue.Account
+	SymbolInfo          = venue.SymbolInfo
+	OrderUpdate         = venue.OrderUpdate
+	OrderUpdateCallback = venue.OrderUpdateCallback
+	Candle              = venue.Candle
+)
+
+const (
+	SideBuy  = venue.SideBuy
+	SideSell = venue.SideSell
+
+	OrderTypeLimit  = venue.OrderTypeLimit
+	OrderTypeMarket = venue.OrderTypeMarket
+
+	OrderStatusNew             = venue.OrderStatusNew
+	OrderStatusPartiallyFilled = venue.OrderStatusPartiallyFilled
+	OrderStatusFilled          = venue.OrderStatusFilled
+	OrderStatusCanceled        = venue.OrderStatusCanceled
+	OrderStatusRejected        = venue.OrderStatusRejected
+	OrderStatusExpired         = venue.OrderStatusExpired
+
+	TimeInForceGTC = venue.TimeInForceGTC
+)
+
+// Bybit 业务错误码
+const (
+	errCodeOrderNotExist      = 110001 // 订单不存在
+	errCodeInsufficientFunds  = 110007 // 可用余额不足
+	errCodeInsufficientAB     = 110004 // 钱包余额不足
+	errCodeInsufficientMargin = 110012 // 保证金不足
+	errCodeTimestamp          = 10002  // 请求时间戳超出 recv_window
+	errCodeModeNotModified    = 110025 // 持仓模式未变化（已是目标模式）
+	errCodeLeverageNotChanged = 110043 // 杠杆未变化（已是目标杠杆）
+)
+
+// BybitAdapter Bybit USDT 永续合约适配器（V5 API，category=linear）
+type BybitAdapter struct {
+	client         *Client
+	wsManager      *WebSocketManager
+	klineWSManager *KlineWebSocketManager
+	symbol         string
+	category       string           // 产品类型：linear（USDT/USDC 永续）
+	clock          *clock.Estimator // 服务器时间偏差（校正签名时间戳）
+
+	// Bybit 订单ID为 UUID 字符串，这里映射为 int64 以适配通用接口
+	orderIDMu sync.RWMutex
+	orderIDs  map[int64]string
+
+	tickSize         float64 // 价格步长
+	qtyStep          float64 // 数量步长
+	minOrderQty      float64 // 最小下单数量
+	minNotional      float64 // 最小下单金额
+	priceDecimals    int     // 价格小数位（由 tickSize 推导）
+	quantityDecimals int     // 数量小数位（由 qtyStep 推导）
+	maxLeverage      float64 // 最大杠杆
+	baseAsset        string  // 基础资产（交易币种），如 BTC
+	quoteAsset       string  // 计价资产（结算币种），如 USDT
+}
+
+// NewBybitAdapter 创建 Bybit 适配器
+// cfg 键 environment 选择 mainnet/testnet/demo 地址；
+// 可选键 base_url / ws_public_url / ws_private_url 用于指向自定义网关，优先于 environment
+func NewBybitAdapter(cfg map[string]string, symbol string) (*BybitAdapter, error) {
+	apiKey := cfg["api_key"]
+	secretKey := cfg["secret_key"]
+
+	if apiKey == "" || secretKey == "" {
+		return nil, fmt.Errorf("bybit API 配置不完整")
+	}
+
+	client := NewClient(apiKey, secretKey)
+	wsManager := NewWebSocketManager(client.signer)
+
+	switch env := cfg["environment"]; env {
+	case "", "mainnet":
+	case "testnet":
+		client.baseURL = BybitTestnetBaseURL
+		wsManager.publicURL = BybitWSPublicLinearTestnet
+		wsManager.privateURL = BybitWSPrivateTestnet
+		logger.Info("ℹ️ [Bybit] 使用测试网")
+	case "demo":
+		client.baseURL = BybitDemoBaseURL
+		wsManager.privateURL = BybitWSPrivateDemo
+		logger.Info("ℹ️ [Bybit] 使用模拟交易")
+	default:
+		return nil, fmt.Errorf("bybit 不支持 environment=%s（可选 mainnet/testnet/demo）", env)
+	}
+
+	if baseURL := cfg["base_url"]; baseURL != "" {
+		client.baseURL = strings.TrimRight(baseURL, "/")
+	}
+	if u := cfg["ws_public_url"]; u != "" {
+		wsManager.publicURL = u
+	}
+	if u := cfg["ws_private_url"]; u != "" {
+		wsManager.privateURL = u
+	}
+
+	adapter := &BybitAdapter{
+		client:    client,
+		wsManager: wsManager,
+		symbol:    strings.ToUpper(symbol),
+		category:  "linear",
+		orderIDs:  make(map[int64]string),
+	}
+	adapter.startClock()
+	wsManager.orderIDResolver = adapter.registerOrderID
+
+	// 初始化获取合约信息
+	ctxInit, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := adapter.fetchInstrumentInfo(ctxInit); err != nil {
+		logger.Warn("⚠️ [Bybit] 获取合约信息失败: %v", err)
+		// 使用默认值
+		adapter.priceDecimals = 2
+		adapter.quantityDecimals = 3
+		adapter.tickSize = 0.01
+		adapter.qtyStep = 0.001
+		adapter.quoteAsset = "USDT"
+	}
+
+	return adapter, nil
+}
+
+// GetName 获取交易所名称
+func (b *BybitAdapter) GetName() string {
+	return "Bybit"
+}
+
+// startClock 创建对时器并注入签名器（REST 与 WebSocket 共用），立即对时一次后定期对时
+func (b *BybitAdapter) startClock() {
+	b.clock = clock.NewEstimator("Bybit", b.client.ServerTime)
+	b.client.signer.clock = b.clock
+	b.clock.Start(context.Background(), clock.DefaultSyncInterval)
+}
+
+// ClockSkew 本地时钟相对服务器的偏差（服务器时间 - 本地时间）
+func (b *BybitAdapter) ClockSkew() time.Duration {
+	return b.clock.Offset()
+}
+
+// SyncServerTime 立即与服务器对时
+func (b *BybitAdapter) SyncServerTime(ctx context.Context) error {
+	return b.clock.Sync(ctx)
+}
+
+// fetchInstrumentInfo 获取合约信息（价格步长、数量步长等）
+func (b *BybitAdapter) fetchInstrumentInfo(ctx context.Context) error {
+	info, err := b.GetSymbolInfo(ctx, b.symbol)
+	if err != nil {
+		return err
+	}
+
+	b.tickSize = info.TickSize
+	b.qtyStep = info.StepSize
+	b.minOrderQty = info.MinQty
+	b.minNotional = info.MinNotional
+	b.maxLeverage = float64(info.MaxLeverage)
+	b.priceDecimals = info.PriceDecimals
+	b.quantityDecimals = info.QuantityDecimals
+	b.baseAsset = info.BaseAsset
+	b.quoteAsset = info.QuoteAsset
+
+	logger.Info("ℹ️ [Bybit 合约信息] %s, 价格精度:%d, 数量精度:%d, 最小数量:%g, 最小金额:%g, 基础币种:%s, 结算币种:%s",
+		b.symbol, b.priceDecimals, b.quantityDecimals, info.MinQty, info.MinNotional, b.baseAsset, b.quoteAsset)
+
+	return nil
+}
+
+// GetSymbolInfo 获取合约交易规则
+func (b *BybitAdapter) GetSymbolInfo(ctx context.Context, symbol string) (*SymbolInfo, error) {
+	query := fmt.Sprintf("category=%s&symbol=%s", b.category, symbol)
+	resp, err := b.client.DoRequest(ctx, "GET", "/v5/market/instruments-info", query, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		List []struct {
+			Symbol      string `json:"symbol"`
+			BaseCoin    string `json:"baseCoin"`
+			QuoteCoin   string `json:"quoteCoin"`
+			SettleCoin  string `json:"settleCoin"`
+			PriceFilter struct {
+				TickSize string `json:"tickSize"`
+			} `json:"priceFilter"`
+			LotSizeFilter struct {
+				QtyStep          string `json:"qtyStep"`
+				MinOrderQty      string `json:"minOrderQty"`
+				MinNotionalValue string `json:"minNotionalValue"`
+			} `json:"lotSizeFilter"`
+			LeverageFilter struct {
+				MaxLeverage string `json:"maxLeverage"`
+			} `json:"leverageFilter"`
+		} `json:"list"`
+	}
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return nil, fmt.Errorf("解析合约信息失败: %w", err)
+	}
+	if len(result.List) == 0 {
+		return nil, fmt.Errorf("未找到合约信息: %s", symbol)
+	}
+
+	item := result.List[0]
+	info := &SymbolInfo{
+		Symbol:             item.Symbol,
+		BaseAsset:          item.BaseCoin,
+		QuoteAsset:         item.SettleCoin,
+		ContractMultiplier: 1, // Bybit 线性合约按币数量下单
+		PriceDecimals:      countDecimalPlaces(item.PriceFilter.TickSize),
+		QuantityDecimals:   countDecimalPlaces(item.LotSizeFilter.QtyStep),
+	}
+	if info.QuoteAsset == "" {
+		info.QuoteAsset = item.QuoteCoin
+	}
+	info.TickSize, _ = strconv.ParseFloat(item.PriceFilter.TickSize, 64)
+	info.StepSize, _ = strconv.ParseFloat(item.LotSizeFilter.QtyStep, 64)
+	info.MinQty, _ = strconv.ParseFloat(item.LotSizeFilter.MinOrderQty, 64)
+	info.MinNotional, _ = strconv.ParseFloat(item.LotSizeFilter.MinNotionalValue, 64)
+	maxLeverage, _ := strconv.ParseFloat(item.LeverageFilter.MaxLeverage, 64)
+	info.MaxLeverage = int(maxLeverage)
+	return info, nil
+}
+
+// PlaceOrder 下单（使用 REST API）
+func (b *BybitAdapter) PlaceOrder(ctx context.Context, req *OrderRequest) (*Order, error) {
+	body := b.buildOrderBody(req)
+
+	resp, err := b.client.DoRequest(ctx, "POST", "/v5/order/create", "", body)
+	if err != nil {
+		if isInsufficientMarginError(err) {
+			return nil, errs.Wrap(errs.ErrInsufficientMargin, "bybit", "", err)
+		}
+		if isClockSkewError(err) {
+			return nil, errs.Wrap(errs.ErrClockSkew, "bybit", strconv.Itoa(errCodeTimestamp), err)
+		}
+		return nil, err
+	}
+
+	var data struct {
+		OrderID     string `json:"orderId"`
+		OrderLinkID string `json:"orderLinkId"`
+	}
+	if err := json.Unmarshal(resp.Result, &data); err != nil {
+		return nil, fmt.Errorf("解析下单响应失败: %w", err)
+	}
+	if data.OrderID == "" {
+		return nil, fmt.Errorf("下单响应中orderId为空: %s", string(resp.Result))
+	}
+
+	return &Order{
+		OrderID:       b.registerOrderID(data.OrderID),
+		ClientOrderID: data.OrderLinkID,
+		Symbol:        req.Symbol,
+		Side:          req.Side,
+		Type:          req.Type,
+		Price:         req.Price,
+		Quantity:      req.Quantity,
+		Status:        OrderStatusNew,
+		CreatedAt:     time.Now(),
+	}, nil
+}
+
+// buildOrderBody 构造 Bybit V5 下单参数
+func (b *BybitAdapter) buildOrderBody(req *OrderRequest) map[string]interface{} {
+	side := "Buy"
+	if req.Side == SideSell {
+		side = "Sell"
+	}
+
+	orderType := "Limit"
+	if req.Type == OrderTypeMarket {
+		orderType = "Market"
+	}
+
+	// 根据 PostOnly 参数选择 timeInForce
+	timeInForce := "GTC"
+	if req.PostOnly {
+		timeInForce = "PostOnly"
+	}
+
+	priceDecimals := b.priceDecimals
+	if req.PriceDecimals > 0 {
+		priceDecimals = req.PriceDecimals
+	}
+
+	body := map[string]interface{}{
+		"category":    b.category,
+		"symbol":      b.symbol,
+		"side":        side,
+		"orderType":   orderType,
+		"qty":         formatQuantity(req.Quantity, b.qtyStep, b.quantityDecimals),
+		"timeInForce": timeInForce,
+		"positionIdx": positionIdx(req.PositionSide),
+	}
+	if orderType == "Limit" {
+		body["price"] = strconv.FormatFloat(alignToTickSize(req.Price, b.tickSize, priceDecimals), 'f', priceDecimals, 64)
+	}
+	if req.ReduceOnly {
+		body["reduceOnly"] = true
+	}
+	if req.ClientOrderID != "" {
+		body["orderLinkId"] = req.ClientOrderID
+	}
+	return body
+}
+
+// BatchPlaceOrders 批量下单
+func (b *BybitAdapter) BatchPlaceOrders(ctx context.Context, orders []*OrderRequest) ([]*Order, bool) {
+	placedOrders := make([]*Order, 0, len(orders))
+	hasMarginError := false
+
+	for _, orderReq := range orders {
+		order, err := b.PlaceOrder(ctx, orderReq)
+		if err != nil {
+			logger.Warn("⚠️ [Bybit] 下单失败 %.2f %s: %v",
+				orderReq.Price, orderReq.Side, err)
+			errs.Report(ctx, err)
+
+			if errors.Is(err, errs.ErrInsufficientMargin) {
+				hasMarginError = true
+			}
+			continue
+		}
+		placedOrders = append(placedOrders, order)
+	}
+
+	return placedOrders, hasMarginError
+}
+
+// CancelOrder 取消订单
+func (b *BybitAdapter) CancelOrder(ctx context.Context, symbol string, orderID int64) error {
+	bybitID, err := b.resolveOrderID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+
+	body := map[string]interface{}{
+		"category": b.category,
+		"symbol":   b.symbol,
+		"orderId":  bybitID,
+	}
+
+	_, err = b.client.DoRequest(ctx, "POST", "/v5/order/cancel", "", body)
+	if err != nil {
+		// 订单不存在不算错误
+		if isOrderNotFoundError(err) {
+			logger.Info("ℹ️ [Bybit] 订单 %d 已不存在，跳过取消", orderID)
+			return nil
+		}
+		return fmt.Errorf("取消订单失败: %w", err)
+	}
+
+	logger.Info("✅ [Bybit] 取消订单成功: %d", orderID)
+	return nil
+}
+
+// BatchCancelOrders 批量取消订单
+func (b *BybitAdapter) BatchCancelOrders(ctx context.Context, symbol string, orderIDs []int64) error {
+	if len(orderIDs) == 0 {
+		return nil
+	}
+
+	// 🔥 Bybit 线性合约批量撤单限制：每次最多20个
+	batchSize := 20
+	for i := 0; i < len(orderIDs); i += batchSize {
+		end := i + batchSize
+		if end > len(orderIDs) {
+			end = len(orderIDs)
+		}
+		batch := orderIDs[i:end]
+
+		request := make([]map[string]string, 0, len(batch))
+		for _, id := range batch {
+			bybitID, err := b.resolveOrderID(ctx, id)
+			if err != nil {
+				logger.Warn("⚠️ [Bybit] 跳过无法识别的订单 %d: %v", id, err)
+				continue
+			}
+			request = append(request, map[string]string{
+				"symbol":  b.symbol,
+				"orderId": bybitID,
+			})
+		}
+		if len(request) == 0 {
+			continue
+		}
+
+		body := map[string]interface{}{
+			"category": b.category,
+			"request":  request,
+		}
+
+		_, err := b.client.DoRequest(ctx, "POST", "/v5/order/cancel-batch", "", body)
+		if err != nil {
+			logger.Warn("⚠️ [Bybit] 批量撤单失败 (共%d个): %v", len(request), err)
+			logger.Info("🔄 [Bybit] 改为逐个撤单...")
+			for _, orderID := range batch {
+				_ = b.CancelOrder(ctx, symbol, orderID)
+				time.Sleep(100 * time.Millisecond) // 避免限频
+			}
+		} else {
+			logger.Info("✅ [Bybit] 批量撤单成功: %d 个订单", len(request))
+		}
+
+		// 避免限频
+		if i+batchSize < len(orderIDs) {
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+
+	return nil
+}
+
+// CancelAllOrders 撤销交易对的所有订单（Bybit 原生一键全撤）
+func (b *BybitAdapter) CancelAllOrders(ctx context.Context, symbol string) error {
+	body := map[string]interface{}{
+		"category": b.category,
+		"symbol":   b.symbol,
+	}
+
+	resp, err := b.client.DoRequest(ctx, "POST", "/v5/order/cancel-all", "", body)
+	if err != nil {
+		return fmt.Errorf("一键全撤失败: %w", err)
+	}
+
+	var data struct {
+		List []struct {
+			OrderID     string `json:"orderId"`
+			OrderLinkID string `json:"orderLinkId"`
+		} `json:"list"`
+	}
+	if err := json.Unmarshal(resp.Result, &data); err != nil {
+		return fmt.Errorf("解析一键全撤响应失败: %w", err)
+	}
+
+	logger.Info("✅ [Bybit 一键全撤] 已撤销 %d 个订单", len(data.List))
+	return nil
+}
+
+// bybitOrder Bybit 订单结构（REST 与 WebSocket 共用）
+type bybitOrder struct {
+	Symbol      string `json:"symbol"`
+	OrderID     string `json:"orderId"`
+	OrderLinkID string `json:"orderLinkId"`
+	Side        string `json:"side"`
+	OrderType   string `json:"orderType"`
+	Price       string `json:"price"`
+	Qty         string `json:"qty"`
+	CumExecQty  string `json:"cumExecQty"`
+	AvgPrice    string `json:"avgPrice"`
+	OrderStatus string `json:"orderStatus"`
+	CreatedTime string `json:"createdTime"`
+	UpdatedTime string `json:"updatedTime"`
+}
+
+// toOrder 转换为通用订单结构
+func (b *BybitAdapter) toOrder(item *bybitOrder) *Order {
+	price, _ := strconv.ParseFloat(item.Price, 64)
+	quantity, _ := strconv.ParseFloat(item.Qty, 64)
+	executedQty, _ := strconv.ParseFloat(item.CumExecQty, 64)
+	avgPrice, _ := strconv.ParseFloat(item.AvgPrice, 64)
+	createdTime, _ := strconv.ParseInt(item.CreatedTime, 10, 64)
+	updateTime, _ := strconv.ParseInt(item.UpdatedTime, 10, 64)
+
+	orderType := OrderTypeLimit
+	if item.OrderType == "Market" {
+		orderType = OrderTypeMarket
+	}
+
+	return &Order{
+		OrderID:       b.registerOrderID(item.OrderID),
+		ClientOrderID: item.OrderLinkID,
+		Symbol:        item.Symbol,
+		Side:          convertSide(item.Side),
+		Type:          orderType,
+		Price:         price,
+		Quantity:      quantity,
+		ExecutedQty:   executedQty,
+		AvgPrice:      avgPrice,
+		Status:        convertStatus(item.OrderStatus),
+		CreatedAt:     time.UnixMilli(createdTime),
+		UpdateTime:    updateTime,
+	}
+}
+
+// GetOrder 查询订单
+func (b *BybitAdapter) GetOrder(ctx context.Context, symbol string, orderID int64) (*Order, error) {
+	bybitID, err := b.resolveOrderID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 先查实时订单（包含活跃订单与最近完结的订单），查不到再查历史订单
+	for _, path := range []string{"/v5/order/realtime", "/v5/order/history"} {
+		query := fmt.Sprintf("category=%s&orderId=%s&symbol=%s", b.category, bybitID, b.symbol)
+		resp, err := b.client.DoRequest(ctx, "GET", path, query, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var data struct {
+			List []bybitOrder `json:"list"`
+		}
+		if err := json.Unmarshal(resp.Result, &data); err != nil {
+			return nil, fmt.Errorf("解析订单详情失败: %w", err)
+		}
+		if len(data.List) > 0 {
+			return b.toOrder(&data.List[0]), nil
+		}
+	}
+
+	return nil, fmt.Errorf("订单不存在: %d", orderID)
+}
+
+// GetOpenOrders 查询未完成订单（自动翻页）
+func (b *BybitAdapter) GetOpenOrders(ctx context.Context, symbol string) ([]*Order, error) {
+	orders := make([]*Order, 0)
+	cursor := ""
+
+	for page := 0; page < 20; page++ {
+		params := url.Values{}
+		params.Set("category", b.category)
+		params.Set("symbol", b.symbol)
+		params.Set("openOnly", "0")
+		params.Set("limit", "50")
+		if cursor != "" {
+			params.Set("cursor", cursor)
+		}
+
+		resp, err := b.client.DoRequest(ctx, "GET", "/v5/order/realtime", params.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var data struct {
+			List           []bybitOrder `json:"list"`
+			NextPageCursor string       `json:"nextPageCursor"`
+		}
+		if err := json.Unmarshal(resp.Result, &data); err != nil {
+			return nil, fmt.Errorf("解析订单列表失败: %w", err)
+		}
+
+		for i := range data.List {
+			orders = append(orders, b.toOrder(&data.List[i]))
+		}
+
+		if data.NextPageCursor == "" || len(data.List) == 0 {
+			break
+		}
+		cursor = data.NextPageCursor
+	}
+
+	return orders, nil
+}
+
+// GetAccount 获取账户信息（统一交易账户）
+func (b *BybitAdapter) GetAccount(ctx context.Context) (*Account, error) {
+	resp, err := b.client.DoRequest(ctx, "GET", "/v5/account/wallet-balance", "accountType=UNIFIED", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var data struct {
+		List []struct {
+			TotalEquity           string `json:"totalEquity"`
+			TotalWalletBalance    string `json:"totalWalletBalance"`
+			TotalMarginBalance    string `json:"totalMarginBalance"`
+			TotalAvailableBalance string `json:"totalAvailableBalance"`
+		} `json:"list"`
+	}
+	if err := json.Unmarshal(resp.Result, &data); err != nil {
+		return nil, fmt.Errorf("解析账户信息失败: %w", err)
+	}
+	if len(data.List) == 0 {
+		return nil, fmt.Errorf("账户信息为空")
+	}
+
+	item := data.List[0]
+	walletBalance, _ := strconv.ParseFloat(item.TotalWalletBalance, 64)
+	marginBalance, _ := strconv.ParseFloat(item.TotalMarginBalance, 64)
+	available, _ := strconv.ParseFloat(item.TotalAvailableBalance, 64)
+
+	// 获取交易对杠杆（从持仓信息读取，即使持仓为0也会返回杠杆设置）
+	positions, leverage, err := b.fetchPositions(ctx)
+	if err != nil {
+		logger.Warn("⚠️ [Bybit] 获取持仓信息失败: %v", err)
+	}
+
+	return &Account{
+		TotalWalletBalance: walletBalance,
+		TotalMarginBalance: marginBalance,
+		AvailableBalance:   available,
+		Positions:          positions,
+		AccountLeverage:    leverage,
+	}, nil
+}
+
+// GetPositions 获取持仓信息
+func (b *BybitAdapter) GetPositions(ctx context.Context, symbol string) ([]*Position, error) {
+	positions, _, err := b.fetchPositions(ctx)
+	return positions, err
+}
+
+// fetchPositions 查询持仓，返回非空持仓和当前杠杆设置
+func (b *BybitAdapter) fetchPositions(ctx context.Context) ([]*Position, int, error) {
+	query := fmt.Sprintf("category=%s&symbol=%s", b.category, b.symbol)
+	resp, err := b.client.DoRequest(ctx, "GET", "/v5/position/list", query, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var data struct {
+		List []bybitPosition `json:"list"`
+	}
+	if err := json.Unmarshal(resp.Result, &data); err != nil {
+		return nil, 0, fmt.Errorf("解析持仓信息失败: %w", err)
+	}
+
+	leverage := 0
+	positions := make([]*Position, 0, len(data.List))
+	for i := range data.List {
+		pos := data.List[i].toPosition()
+		if leverage == 0 {
+			leverage = pos.Leverage
+		}
+		if pos.Size == 0 {
+			continue // 跳过空持仓
+		}
+		positions = append(positions, pos)
+	}
+
+	return positions, leverage, nil
+}
+
+// bybitPosition Bybit 持仓结构（REST 与 WebSocket 共用）
+type bybitPosition struct {
+	Symbol        string `json:"symbol"`
+	Side          string `json:"side"` // Buy / Sell / 空字符串（无持仓）
+	Size          string `json:"size"`
+	AvgPrice      string `json:"avgPrice"`
+	EntryPrice    string `json:"entryPrice"` // WebSocket 推送使用 entryPrice
+	MarkPrice     string `json:"markPrice"`
+	UnrealisedPnl string `json:"unrealisedPnl"`
+	Leverage      string `json:"leverage"`
+	TradeMode     int    `json:"tradeMode"` // 0: 全仓, 1: 逐仓
+	PositionIM    string `json:"positionIM"`
+	PositionIdx   int    `json:"positionIdx"` // 0: 单向持仓, 1: 双向持仓多头, 2: 双向持仓空头
+}
+
+// toPosition 转换为通用持仓结构（空仓为负数）
+func (p *bybitPosition) toPosition() *Position {
+	size, _ := strconv.ParseFloat(p.Size, 64)
+	if p.Side == "Sell" {
+		size = -size
+	}
+	entryPrice, _ := strconv.ParseFloat(p.AvgPrice, 64)
+	if entryPrice == 0 {
+		entryPrice, _ = strconv.ParseFloat(p.EntryPrice, 64)
+	}
+	markPrice, _ := strconv.ParseFloat(p.MarkPrice, 64)
+	unrealizedPNL, _ := strconv.ParseFloat(p.UnrealisedPnl, 64)
+	leverageF, _ := strconv.ParseFloat(p.Leverage, 64)
+	margin, _ := strconv.ParseFloat(p.PositionIM, 64)
+
+	marginType := "cross"
+	if p.TradeMode == 1 {
+		marginType = "isolated"
+	}
+
+	return &Position{
+		Symbol:         p.Symbol,
+		Size:           size,
+		EntryPrice:     entryPrice,
+		MarkPrice:      markPrice,
+		UnrealizedPNL:  unrealizedPNL,
+		Leverage:       int(leverageF),
+		MarginType:     marginType,
+		IsolatedMargin: margin,
+		Side:           hedgePositionSide(p.PositionIdx),
+	}
+}
+
+// GetBalance 获取余额
+func (b *BybitAdapter) GetBalance(ctx context.Context, asset string) (float64, error) {
+	account, err := b.GetAccount(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return account.AvailableBalance, nil
+}
+
+// SetLeverage 设置交易对杠杆倍数（多空两个方向相同），已是目标杠杆时视为成功
+func (b *BybitAdapter) SetLeverage(ctx context.Context, symbol string, leverage int) error {
+	body := map[string]interface{}{
+		"category":     b.category,
+		"symbol":       b.symbol,
+		"buyLeverage":  strconv.Itoa(leverage),
+		"sellLeverage": strconv.Itoa(leverage),
+	}
+	if _, err := b.client.DoRequest(ctx, "POST", "/v5/position/set-leverage", "", body); err != nil && !isAPIErrorCode(err, errCodeLeverageNotChanged) {
+		return err
+	}
+	logger.Info("✅ [Bybit] %s 杠杆倍数已设置为 %dx", b.symbol, leverage)
+	return nil
+}
+
+// SetMarginType 设置保证金模式（逐仓/全仓）
+// 统一交易账户的保证金模式是账户级别的，对账户下所有交易对生效；有持仓或挂单时交易所会拒绝
+func (b *BybitAdapter) SetMarginType(ctx context.Context, symbol string, isolated bool) error {
+	marginMode := "REGULAR_MARGIN"
+	if isolated {
+		marginMode = "ISOLATED_MARGIN"
+	}
+	body := map[string]interface{}{
+		"setMarginMode": marginMode,
+	}
+	if _, err := b.client.DoRequest(ctx, "POST", "/v5/account/set-margin-mode", "", body); err != nil {
+		return err
+	}
+	logger.Info("✅ [Bybit] 保证金模式: %s", marginMode)
+	return nil
+}
+
+// SetPositionMode 设置持仓模式（单向/双向，按结算币种生效），有持仓或挂单时交易所会拒绝
+func (b *BybitAdapter) SetPositionMode(ctx context.Context, hedge bool) error {
+	mode, modeDesc := 0, "单向持仓"
+	if hedge {
+		mode, modeDesc = 3, "双向持仓"
+	}
+	body := map[string]interface{}{
+		"category": b.category,
+		"coin":     b.quoteAsset,
+		"mode":     mode,
+	}
+	if _, err := b.client.DoRequest(ctx, "POST", "/v5/position/switch-mode", "", body); err != nil && !isAPIErrorCode(err, errCodeModeNotModified) {
+		return err
+	}
+	logger.Info("✅ [Bybit] 持仓模式: %s", modeDesc)
+	return nil
+}
+
+// StartOrderStream 启动订单流（WebSocket 私有频道 order + position）
+func (b *BybitAdapter) StartOrderStream(ctx context.Context, callback func(interface{})) error {
+	logger.Debug("🔗 [Bybit] 启动订单流 WebSocket（私有频道）")
+
+	wrappedCallback := func(update OrderUpdate) {
+		genericUpdate := struct {
+			OrderID       int64
+			ClientOrderID string
+			Symbol        string
+			Side          string
+			Type          string
+			Status        string
+			Price         float64
+			Quantity      float64
+			ExecutedQty   float64
+			AvgPrice      float64
+			UpdateTime    int64
+		}{
+			OrderID:       update.OrderID,
+			ClientOrderID: update.ClientOrderID,
+			Symbol:        update.Symbol,
+			Side:          string(update.Side),
+			Type:          string(update.Type),
+			Status:        string(update.Status),
+			Price:         update.Price,
+			Quantity:      update.Quantity,
+			ExecutedQty:   update.ExecutedQty,
+			AvgPrice:      update.AvgPrice,
+			UpdateTime:    update.UpdateTime,
+		}
+		callback(genericUpdate)
+	}
+
+	return b.wsManager.StartPrivate(ctx, wrappedCallback)
+}
+
+// StopOrderStream 停止订单流
+func (b *BybitAdapter) StopOrderStream() error {
+	b.wsManager.Stop()
+	return nil
+}
+
+// SetPositionCallback 设置持仓推送回调（私有频道 position）
+func (b *BybitAdapter) SetPositionCallback(callback func(*Position)) {
+	b.wsManager.SetPositionCallback(callback)
+}
+
+// GetLatestPrice 获取最新价格（优先 WebSocket 缓存，未就绪时使用 REST）
+func (b *BybitAdapter) GetLatestPrice(ctx context.Context, symbol string) (float64, error) {
+	if price := b.wsManager.GetLatestPrice(); price > 0 {
+		return price, nil
+	}
+
+	query := fmt.Sprintf("category=%s&symbol=%s", b.category, b.symbol)
+	resp, err := b.client.DoRequest(ctx, "GET", "/v5/market/tickers", query, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var data struct {
+		List []struct {
+			LastPrice string `json:"lastPrice"`
+		} `json:"list"`
+	}
+	if err := json.Unmarshal(resp.Result, &data); err != nil {
+		return 0, fmt.Errorf("解析行情失败: %w", err)
+	}
+	if len(data.List) == 0 {
+		return 0, fmt.Errorf("未获取到 %s 的行情", b.symbol)
+	}
+
+	return strconv.ParseFloat(data.List[0].LastPrice, 64)
+}
+
+// StartPriceStream 启动价格流（WebSocket 公共频道 tickers）
+func (b *BybitAdapter) StartPriceStream(ctx context.Context, symbol string, callback func(price float64)) error {
+	return b.wsManager.StartPublic(ctx, b.symbol, callback)
+}
+
+// StartBookTickerStream 启动盘口最优价推送（复用公共频道 tickers 的 bid1/ask1，仅支持当前交易对）
+func (b *BybitAdapter) StartBookTickerStream(ctx context.Context, symbol string, callback func(ticker *orderbook.Ticker)) error {
+	return b.wsManager.StartBookTicker(ctx, b.symbol, callback)
+}
+
+// StartKlineStream 启动K线流（WebSocket）
+func (b *BybitAdapter) StartKlineStream(ctx context.Context, symbols []string, interval string, callback func(candle interface{})) error {
+	if b.klineWSManager == nil {
+		b.klineWSManager = NewKlineWebSocketManager(b.wsManager.publicURL)
+	}
+	return b.klineWSManager.Start(ctx, symbols, interval, callback)
+}
+
+// RegisterKlineCallback 注册K线回调函数（支持多个组件共享K线流）
+func (b *BybitAdapter) RegisterKlineCallback(componentName string, callback func(candle interface{})) error {
+	if b.klineWSManager == nil {
+		return fmt.Errorf("K线流管理器未初始化")
+	}
+	return b.klineWSManager.RegisterCallback(componentName, callback)
+}
+
+// StopKlineStream 停止K线流
+func (b *BybitAdapter) StopKlineStream() error {
+	if b.klineWSManager != nil {
+		b.klineWSManager.Stop()
+	}
+	return nil
+}
+
+// ForceReconnectKlineStream 强制重新连接K线流
+func (b *BybitAdapter) ForceReconnectKlineStream() error {
+	if b.klineWSManager != nil {
+		return b.klineWSManager.ForceReconnect()
+	}
+	return fmt.Errorf("K线流管理器未初始化")
+}
+
+// GetHistoricalKlines 获取历史K线数据
+func (b *BybitAdapter) GetHistoricalKlines(ctx context.Context, symbol string, interval string, limit int) ([]*Candle, error) {
+	// limit: Bybit 最多支持 1000 根K线
+	if limit > 1000 {
+		limit = 1000
+	}
+
+	query := fmt.Sprintf("category=%s&interval=%s&limit=%d&symbol=%s",
+		b.category, convertToBybitInterval(interval), limit, strings.ToUpper(symbol))
+	resp, err := b.client.DoRequest(ctx, "GET", "/v5/market/kline", query, nil)
+	if err != nil {
+		return nil, fmt.Errorf("获取历史K线失败: %w", err)
+	}
+
+	// Bybit 返回格式: {"list": [[startTime, open, high, low, close, volume, turnover], ...]}
+	var data struct {
+		List [][]string `json:"list"`
+	}
+	if err := json.Unmarshal(resp.Result, &data); err != nil {
+		return nil, fmt.Errorf("解析K线数据失败: %w", err)
+	}
+
+	candles := make([]*Candle, 0, len(data.List))
+	for _, item := range data.List {
+		if len(item) < 6 {
+			continue // 跳过无效数据
+		}
+
+		timestamp, _ := strconv.ParseInt(item[0], 10, 64)
+		open, _ := strconv.ParseFloat(item[1], 64)
+		high, _ := strconv.ParseFloat(item[2], 64)
+		low, _ := strconv.ParseFloat(item[3], 64)
+		close, _ := strconv.ParseFloat(item[4], 64)
+		volume, _ := strconv.ParseFloat(item[5], 64)
+
+		candles = append(candles, &Candle{
+			Symbol:    symbol,
+			Open:      open,
+			High:      high,
+			Low:       low,
+			Close:     close,
+			Volume:    volume,
+			Timestamp: timestamp,
+			IsClosed:  true, // 历史K线都是已完结的
+		})
+	}
+
+	// Bybit 返回的K线是倒序的（最新的在前），需要反转
+	for i, j := 0, len(candles)-1; i < j; i, j = i+1, j-1 {
+		candles[i], candles[j] = candles[j], candles[i]
+	}
+
+	return candles, nil
+}
+
+// GetKlinesBetween 获取开盘时间在 [start, end]（毫秒）内的K线，单次请求最多 1000 根，按时间升序
+func (b *BybitAdapter) GetKlinesBetween(ctx context.Context, symbol string, interval string, start, end int64, limit int) ([]*Candle, error) {
+	if limit <= 0 || limit > 1000 {
+		limit = 1000
+	}
+
+	query := fmt.Sprintf("category=%s&end=%d&interval=%s&limit=%d&start=%d&symbol=%s",
+		b.category, end, convertToBybitInterval(interval), limit, start, strings.ToUpper(symbol))
+	resp, err := b.client.DoRequest(ctx, "GET", "/v5/market/kline", query, nil)
+	if err != nil {
+		return nil, fmt.Errorf("获取历史K线失败: %w", err)
+	}
+
+	var data struct {
+		List [][]string `json:"list"`
+	}
+	if err := json.Unmarshal(resp.Result, &data); err != nil {
+		return nil, fmt.Errorf("解析K线数据失败: %w", err)
+	}
+
+	// 倒序返回（最新的在前），逆序遍历转为升序
+	candles := make([]*Candle, 0, len(data.List))
+	for i := len(data.List) - 1; i >= 0; i-- {
+		item := data.List[i]
+		if len(item) < 6 {
+			continue // 跳过无效数据
+		}
+
+		timestamp, _ := strconv.ParseInt(item[0], 10, 64)
+		open, _ := strconv.ParseFloat(item[1], 64)
+		high, _ := strconv.ParseFloat(item[2], 64)
+		low, _ := strconv.ParseFloat(item[3], 64)
+		close, _ := strconv.ParseFloat(item[4], 64)
+		volume, _ := strconv.ParseFloat(item[5], 64)
+
+		candles = append(candles, &Candle{
+			Symbol:    symbol,
+			Open:      open,
+			High:      high,
+			Low:       low,
+			Close:     close,
+			Volume:    volume,
+			Timestamp: timestamp,
+			IsClosed:  true, // 历史K线都是已完结的
+		})
+	}
+	return candles, nil
+}
+
+// GetPriceDecimals 获取价格精度（小数位数）
+func (b *BybitAdapter) GetPriceDecimals() int {
+	return b.priceDecimals
+}
+
+// GetQuantityDecimals 获取数量精度（小数位数）
+func (b *BybitAdapter) GetQuantityDecimals() int {
+	return b.quantityDecimals
+}
+
+// GetBaseAsset 获取基础资产（交易币种）
+func (b *BybitAdapter) GetBaseAsset() string {
+	return b.baseAsset
+}
+
+// GetQuoteAsset 获取计价资产（结算币种）
+func (b *BybitAdapter) GetQuoteAsset() string {
+	return b.quoteAsset
+}
+
+// registerOrderID 将 Bybit 订单ID（UUID）映射为 int64 并记录反向映射
+// 使用 FNV-1a 哈希，保证程序重启后同一订单得到相同的 int64 ID
+func (b *BybitAdapter) registerOrderID(bybitID string) int64 {
+	if bybitID == "" {
+		return 0
+	}
+	h := fnv.New64a()
+	h.Write([]byte(bybitID))
+	id := int64(h.Sum64() & math.MaxInt64)
+	if id == 0 {
+		id = 1
+	}
+
+	b.orderIDMu.Lock()
+	b.orderIDs[id] = bybitID
+	b.orderIDMu.Unlock()
+	return id
+}
+
+// resolveOrderID 将 int64 订单ID 还原为 Bybit 订单ID
+// 如果本地没有映射（例如程序重启后），先拉取一次未完成订单重建映射
+func (b *BybitAdapter) resolveOrderID(ctx context.Context, orderID int64) (string, error) {
+	b.orderIDMu.RLock()
+	bybitID, ok := b.orderIDs[orderID]
+	b.orderIDMu.RUnlock()
+	if ok {
+		return bybitID, nil
+	}
+
+	if _, err := b.GetOpenOrders(ctx, b.symbol); err != nil {
+		return "", fmt.Errorf("重建订单ID映射失败: %w", err)
+	}
+
+	b.orderIDMu.RLock()
+	bybitID, ok = b.orderIDs[orderID]
+	b.orderIDMu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("未知的订单ID: %d", orderID)
+	}
+	return bybitID, nil
+}
+
+// isInsufficientMarginError 判断是否为保证金不足错误
+func isInsufficientMarginError(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.Code {
+		case errCodeInsufficientFunds, errCodeInsufficientAB, errCodeInsufficientMargin:
+			return true
+		}
+	}
+	return strings.Contains(strings.ToLower(err.Error()), "insufficient")
+}
+
+// isAPIErrorCode 判断是否为指定业务错误码
+func isAPIErrorCode(err error, code int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Code == code
+}
+
+// isClockSkewError 判断是否为时间戳不同步错误
+func isClockSkewError(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Code == errCodeTimestamp
+}
+
+// isOrderNotFoundError 判断是否为订单不存在错误
+func isOrderNotFoundError(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.Code == errCodeOrderNotExist {
+		return true
+	}
+	return strings.Contains(err.Error(), "order not exists")
+}
+
+// positionIdx 持仓方向转换为 Bybit positionIdx：双向持仓多头 1、空头 2，单向持仓 0
+// 双向持仓模式下平仓单同样按所平的方向指定（卖出平多为 1，买入平空为 2）
+func positionIdx(positionSide string) int {
+	switch positionSide {
+	case "LONG":
+		return 1
+	case "SHORT":
+		return 2
+	}
+	return 0
+}
+
+// hedgePositionSide 双向持仓模式下将 positionIdx 转换为 LONG/SHORT，单向持仓返回空
+func hedgePositionSide(positionIdx int) string {
+	switch positionIdx {
+	case 1:
+		return "LONG"
+	case 2:
+		return "SHORT"
+	}
+	return ""
+}
+
+// convertSide 转换订单方向
+func convertSide(side string) Side {
+	if side == "Sell" {
+		return SideSell
+	}
+	return SideBuy
+}
+
+// convertStatus 转换订单状态
+func convertStatus(status string) OrderStatus {
+	switch status {
+	case "New", "Created", "Untriggered":
+		return OrderStatusNew
+	case "PartiallyFilled":
+		return OrderStatusPartiallyFilled
+	case "Filled":
+		return OrderStatusFilled
+	case "Cancelled", "PartiallyFilledCanceled", "Deactivated":
+		return OrderStatusCanceled
+	case "Rejected":
+		return OrderStatusRejected
+	default:
+		return OrderStatus(strings.ToUpper(status))
+	}
+}
+
+// convertToBybitInterval 将标准K线周期转换为 Bybit 格式
+// 输入: 1m, 3m, 5m, 15m, 30m, 1h, 2h, 4h, 6h, 12h, 1d, 1w, 1M
+// 输出: 1, 3, 5, 15, 30, 60, 120, 240, 360, 720, D, W, M
+func convertToBybitInterval(interval string) string {
+	switch interval {
+	case "1m":
+		return "1"
+	case "3m":
+		return "3"
+	case "5m":
+		return "5"
+	case "15m":
+		return "15"
+	case "30m":
+		return "30"
+	case "1h":
+		return "60"
+	case "2h":
+		return "120"
+	case "4h":
+		return "240"
+	case "6h":
+		return "360"
+	case "12h":
+		return "720"
+	case "1d":
+		return "D"
+	case "1w":
+		return "W"
+	case "1M":
+		return "M"
+	default:
+		return interval // 如果已经是 Bybit 格式，直接返回
+	}
+}
+
+// countDecimalPlaces 计算步长字符串的小数位数（如 "0.010" -> 2）
+func countDecimalPlaces(step string) int {
+	step = strings.TrimRight(step, "0")
+	idx := strings.Index(step, ".")
+	if idx < 0 {
+		return 0
+	}
+	return len(step) - idx - 1
+}
+
+// alignToTickSize 将价格对齐到价格步长
+func alignToTickSize(price, tickSize float64, decimals int) float64 {
+	if tickSize <= 0 {
+		return price
+	}
+	aligned := math.Round(price/tickSize) * tickSize
+	multiplier := math.Pow(10, float64(decimals))
+	return math.Round(aligned*multiplier) / multiplier
+}
+
+// formatQuantity 将数量向下对齐到数量步长并格式化
+func formatQuantity(quantity, qtyStep float64, decimals int) string {
+	if qtyStep > 0 {
+		// 加上微小偏移，避免 0.3/0.1=2.9999 这类浮点误差导致少一个步长
+		quantity = math.Floor(quantity/qtyStep+1e-9) * qtyStep
+	}
+	return strconv.FormatFloat(quantity, 'f', decimals, 64)
+}
@@ -0,0 +1,460 @@
+package bybit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"opensqt/exchange/errs"
+	"opensqt/exchange/venue/venuetest"
+
+	"github.com/gorilla/websocket"
+)
+
+// ===== 本地模拟 Bybit 服务 =====
+
+const testOrderUUID = "fd4300ae-7847-404e-b947-b46980a4d140"
+
+// newMockServer 模拟 Bybit V5 REST 接口，校验签名（timestamp + apiKey + recvWindow + payload）并记录请求
+func newMockServer(t *testing.T) *venuetest.Server {
+	signer := NewSigner("test-key", "test-secret")
+	mock := venuetest.NewServer(t, func(r *http.Request, body []byte) bool {
+		payload := string(body)
+		if r.Method == http.MethodGet {
+			payload = r.URL.RawQuery
+		}
+		return r.Header.Get("X-BAPI-API-KEY") == "test-key" &&
+			r.Header.Get("X-BAPI-SIGN") == signer.Sign(r.Header.Get("X-BAPI-TIMESTAMP"), payload)
+	}, `{"retCode":10004,"retMsg":"error sign!","result":{}}`)
+
+	mock.HandlePublic("/v5/market/time", func(w http.ResponseWriter, payload string) {
+		writeResult(w, fmt.Sprintf(`{"timeSecond":"%d","timeNano":"%d"}`, time.Now().Unix(), time.Now().UnixNano()))
+	})
+	mock.Handle("/v5/market/instruments-info", func(w http.ResponseWriter, payload string) {
+		writeResult(w, `{"list":[{"symbol":"ETHUSDT","baseCoin":"ETH","quoteCoin":"USDT","settleCoin":"USDT",
+			"priceFilter":{"tickSize":"0.01"},
+			"lotSizeFilter":{"qtyStep":"0.01","minOrderQty":"0.01","minNotionalValue":"5"},
+			"leverageFilter":{"maxLeverage":"100.00"}}]}`)
+	})
+	return mock
+}
+
+func writeResult(w http.ResponseWriter, result string) {
+	w.Write([]byte(`{"retCode":0,"retMsg":"OK","result":` + result + `,"time":1700000000000}`))
+}
+
+func newTestAdapter(t *testing.T, baseURL string, extra map[string]string) *BybitAdapter {
+	cfg := map[string]string{
+		"api_key":    "test-key",
+		"secret_key": "test-secret",
+		"base_url":   baseURL,
+	}
+	for k, v := range extra {
+		cfg[k] = v
+	}
+	adapter, err := NewBybitAdapter(cfg, "ETHUSDT")
+	if err != nil {
+		t.Fatalf("创建适配器失败: %v", err)
+	}
+	return adapter
+}
+
+// ===== 签名 =====
+
+func TestSignerKnownVectors(t *testing.T) {
+	signer := NewSigner("key", "secret")
+
+	got := signer.Sign("1700000000000", "category=linear&symbol=BTCUSDT")
+	want := "3906b813750309cce9879a975510651953382a28592d69104d0b599e3d201f40"
+	if got != want {
+		t.Errorf("REST 签名错误: got %s, want %s", got, want)
+	}
+
+	got = signer.SignWebSocket(1700000000000)
+	want = "9baf584ddf7a063dffe910d97ce4eac0cf7064058356de8b8d92f028e5ad936f"
+	if got != want {
+		t.Errorf("WebSocket 签名错误: got %s, want %s", got, want)
+	}
+}
+
+// ===== REST =====
+
+func TestNewBybitAdapterLoadsInstrumentInfo(t *testing.T) {
+	mock := newMockServer(t)
+	adapter := newTestAdapter(t, mock.URL, nil)
+
+	if adapter.GetPriceDecimals() != 2 || adapter.GetQuantityDecimals() != 2 {
+		t.Errorf("精度错误: price=%d, qty=%d", adapter.GetPriceDecimals(), adapter.GetQuantityDecimals())
+	}
+	if adapter.GetBaseAsset() != "ETH" || adapter.GetQuoteAsset() != "USDT" {
+		t.Errorf("币种错误: base=%s, quote=%s", adapter.GetBaseAsset(), adapter.GetQuoteAsset())
+	}
+	if adapter.minNotional != 5 || adapter.maxLeverage != 100 {
+		t.Errorf("合约限制错误: minNotional=%v, maxLeverage=%v", adapter.minNotional, adapter.maxLeverage)
+	}
+}
+
+func TestPlaceAndCancelOrder(t *testing.T) {
+	mock := newMockServer(t)
+	mock.Handle("/v5/order/create", func(w http.ResponseWriter, payload string) {
+		writeResult(w, `{"orderId":"`+testOrderUUID+`","orderLinkId":"300012_B_1700000000001"}`)
+	})
+	mock.Handle("/v5/order/cancel", func(w http.ResponseWriter, payload string) {
+		writeResult(w, `{"orderId":"`+testOrderUUID+`"}`)
+	})
+	adapter := newTestAdapter(t, mock.URL, nil)
+
+	order, err := adapter.PlaceOrder(context.Background(), &OrderRequest{
+		Symbol:        "ETHUSDT",
+		Side:          SideBuy,
+		Type:          OrderTypeLimit,
+		Quantity:      0.0159,
+		Price:         3000.123,
+		PostOnly:      true,
+		PriceDecimals: 2,
+		ClientOrderID: "300012_B_1700000000001",
+	})
+	if err != nil {
+		t.Fatalf("下单失败: %v", err)
+	}
+	if order.OrderID <= 0 || order.ClientOrderID != "300012_B_1700000000001" {
+		t.Fatalf("订单信息错误: %+v", order)
+	}
+
+	var body map[string]interface{}
+	if err := json.Unmarshal([]byte(mock.LastRequest("/v5/order/create")), &body); err != nil {
+		t.Fatalf("解析下单请求失败: %v", err)
+	}
+	checks := map[string]interface{}{
+		"category":    "linear",
+		"side":        "Buy",
+		"orderType":   "Limit",
+		"price":       "3000.12",
+		"qty":         "0.01", // 向下对齐到 qtyStep
+		"timeInForce": "PostOnly",
+		"orderLinkId": "300012_B_1700000000001",
+	}
+	for k, v := range checks {
+		if body[k] != v {
+			t.Errorf("下单参数 %s 错误: got %v, want %v", k, body[k], v)
+		}
+	}
+
+	// 撤单时 int64 订单ID 必须还原为 Bybit UUID
+	if err := adapter.CancelOrder(context.Background(), "ETHUSDT", order.OrderID); err != nil {
+		t.Fatalf("撤单失败: %v", err)
+	}
+	if !strings.Contains(mock.LastRequest("/v5/order/cancel"), testOrderUUID) {
+		t.Errorf("撤单请求未使用原始订单ID: %s", mock.LastRequest("/v5/order/cancel"))
+	}
+}
+
+// 业务错误码：余额/保证金不足与时间戳超出 recv_window 映射为统一错误分类，
+// 订单不存在（已成交或已撤销）的撤单视为成功
+func TestErrorCodes(t *testing.T) {
+	mock := newMockServer(t)
+	adapter := newTestAdapter(t, mock.URL, nil)
+	ctx := context.Background()
+
+	cases := []struct {
+		code int
+		msg  string
+		want error
+	}{
+		{110007, "ab not enough for new order", errs.ErrInsufficientMargin},
+		{110004, "Wallet balance is insufficient", errs.ErrInsufficientMargin},
+		{110012, "Available balance not enough", errs.ErrInsufficientMargin},
+		{10002, "invalid request, please check your server timestamp or recv_window param", errs.ErrClockSkew},
+	}
+	for _, c := range cases {
+		mock.Handle("/v5/order/create", venuetest.Reply(fmt.Sprintf(`{"retCode":%d,"retMsg":%q,"result":{}}`, c.code, c.msg)))
+		_, err := adapter.PlaceOrder(ctx, &OrderRequest{Symbol: "ETHUSDT", Side: SideBuy, Type: OrderTypeLimit, Quantity: 0.1, Price: 3000})
+		if !errors.Is(err, c.want) {
+			t.Errorf("retCode=%d: err = %v, want %v", c.code, err, c.want)
+		}
+	}
+
+	mock.Handle("/v5/order/cancel", venuetest.Reply(`{"retCode":110001,"retMsg":"order not exists or too late to cancel","result":{}}`))
+	if err := adapter.CancelOrder(ctx, "ETHUSDT", adapter.registerOrderID(testOrderUUID)); err != nil {
+		t.Errorf("订单不存在时不应返回错误: %v", err)
+	}
+}
+
+func TestAccountSettings(t *testing.T) {
+	mock := newMockServer(t)
+	mock.Handle("/v5/position/switch-mode", func(w http.ResponseWriter, payload string) {
+		w.Write([]byte(`{"retCode":110025,"retMsg":"Position mode is not modified","result":{}}`))
+	})
+	mock.Handle("/v5/account/set-margin-mode", func(w http.ResponseWriter, payload string) {
+		writeResult(w, `{"reasons":[]}`)
+	})
+	mock.Handle("/v5/position/set-leverage", func(w http.ResponseWriter, payload string) {
+		w.Write([]byte(`{"retCode":110043,"retMsg":"leverage not modified","result":{}}`))
+	})
+	adapter := newTestAdapter(t, mock.URL, nil)
+	ctx := context.Background()
+
+	// 已是目标设置时交易所返回"未修改"，视为成功
+	if err := adapter.SetPositionMode(ctx, true); err != nil {
+		t.Errorf("设置持仓模式失败: %v", err)
+	}
+	if err := adapter.SetMarginType(ctx, "ETHUSDT", true); err != nil {
+		t.Errorf("设置保证金模式失败: %v", err)
+	}
+	if err := adapter.SetLeverage(ctx, "ETHUSDT", 5); err != nil {
+		t.Errorf("设置杠杆失败: %v", err)
+	}
+
+	if got := mock.LastRequest("/v5/position/switch-mode"); !strings.Contains(got, `"coin":"USDT"`) || !strings.Contains(got, `"mode":3`) {
+		t.Errorf("持仓模式参数错误: %s", got)
+	}
+	if got := mock.LastRequest("/v5/account/set-margin-mode"); !strings.Contains(got, `"setMarginMode":"ISOLATED_MARGIN"`) {
+		t.Errorf("保证金模式参数错误: %s", got)
+	}
+	if got := mock.LastRequest("/v5/position/set-leverage"); !strings.Contains(got, `"buyLeverage":"5"`) || !strings.Contains(got, `"sellLeverage":"5"`) {
+		t.Errorf("杠杆参数错误: %s", got)
+	}
+}
+
+func TestGetOpenOrdersPaginates(t *testing.T) {
+	mock := newMockServer(t)
+	mock.Handle("/v5/order/realtime", func(w http.ResponseWriter, payload string) {
+		if strings.Contains(payload, "cursor=page2") {
+			writeResult(w, `{"list":[{"symbol":"ETHUSDT","orderId":"b","orderLinkId":"2","side":"Sell",
+				"orderType":"Limit","price":"3010","qty":"0.1","cumExecQty":"0.05","avgPrice":"3010",
+				"orderStatus":"PartiallyFilled","createdTime":"1700000000000","updatedTime":"1700000001000"}],"nextPageCursor":""}`)
+			return
+		}
+		writeResult(w, `{"list":[{"symbol":"ETHUSDT","orderId":"a","orderLinkId":"1","side":"Buy",
+			"orderType":"Limit","price":"2990","qty":"0.1","cumExecQty":"0","avgPrice":"",
+			"orderStatus":"New","createdTime":"1700000000000","updatedTime":"1700000000000"}],"nextPageCursor":"page2"}`)
+	})
+	adapter := newTestAdapter(t, mock.URL, nil)
+
+	orders, err := adapter.GetOpenOrders(context.Background(), "ETHUSDT")
+	if err != nil {
+		t.Fatalf("查询挂单失败: %v", err)
+	}
+	if len(orders) != 2 {
+		t.Fatalf("订单数量错误: %d", len(orders))
+	}
+	if orders[0].Side != SideBuy || orders[0].Status != OrderStatusNew {
+		t.Errorf("第一页订单转换错误: %+v", orders[0])
+	}
+	if orders[1].Side != SideSell || orders[1].Status != OrderStatusPartiallyFilled || orders[1].ExecutedQty != 0.05 {
+		t.Errorf("第二页订单转换错误: %+v", orders[1])
+	}
+}
+
+func TestGetPositionsAndAccount(t *testing.T) {
+	mock := newMockServer(t)
+	mock.Handle("/v5/position/list", func(w http.ResponseWriter, payload string) {
+		writeResult(w, `{"list":[{"symbol":"ETHUSDT","side":"Sell","size":"0.5","avgPrice":"3000",
+			"markPrice":"2990","unrealisedPnl":"5","leverage":"10","tradeMode":0,"positionIM":"150"}]}`)
+	})
+	mock.Handle("/v5/account/wallet-balance", func(w http.ResponseWriter, payload string) {
+		writeResult(w, `{"list":[{"totalEquity":"1005","totalWalletBalance":"1000",
+			"totalMarginBalance":"1005","totalAvailableBalance":"800"}]}`)
+	})
+	adapter := newTestAdapter(t, mock.URL, nil)
+
+	positions, err := adapter.GetPositions(context.Background(), "ETHUSDT")
+	if err != nil {
+		t.Fatalf("查询持仓失败: %v", err)
+	}
+	if len(positions) != 1 || positions[0].Size != -0.5 || positions[0].MarginType != "cross" {
+		t.Fatalf("持仓转换错误: %+v", positions)
+	}
+
+	account, err := adapter.GetAccount(context.Background())
+	if err != nil {
+		t.Fatalf("查询账户失败: %v", err)
+	}
+	if account.AvailableBalance != 800 || account.AccountLeverage != 10 {
+		t.Errorf("账户转换错误: %+v", account)
+	}
+}
+
+// 双向持仓：订单按持仓方向指定 positionIdx，持仓按 positionIdx 区分多空
+func TestHedgeModeUsesPositionIdx(t *testing.T) {
+	mock := newMockServer(t)
+	mock.Handle("/v5/order/create", func(w http.ResponseWriter, payload string) {
+		writeResult(w, `{"orderId":"`+testOrderUUID+`","orderLinkId":""}`)
+	})
+	mock.Handle("/v5/position/list", func(w http.ResponseWriter, payload string) {
+		writeResult(w, `{"list":[
+			{"symbol":"ETHUSDT","side":"Buy","size":"0.3","avgPrice":"3000","leverage":"10","positionIdx":1},
+			{"symbol":"ETHUSDT","side":"Sell","size":"0.2","avgPrice":"3100","leverage":"10","positionIdx":2}]}`)
+	})
+	adapter := newTestAdapter(t, mock.URL, nil)
+
+	// 买入平空：方向为 SHORT，positionIdx=2
+	_, err := adapter.PlaceOrder(context.Background(), &OrderRequest{
+		Symbol: "ETHUSDT", Side: SideBuy, Type: OrderTypeLimit, Quantity: 0.1, Price: 3000,
+		ReduceOnly: true, PositionSide: "SHORT",
+	})
+	if err != nil {
+		t.Fatalf("下单失败: %v", err)
+	}
+	var body map[string]interface{}
+	json.Unmarshal([]byte(mock.LastRequest("/v5/order/create")), &body)
+	if body["positionIdx"] != float64(2) || body["reduceOnly"] != true {
+		t.Errorf("双向持仓下单参数错误: %v", body)
+	}
+
+	positions, err := adapter.GetPositions(context.Background(), "ETHUSDT")
+	if err != nil {
+		t.Fatalf("查询持仓失败: %v", err)
+	}
+	if len(positions) != 2 || positions[0].Side != "LONG" || positions[0].Size != 0.3 ||
+		positions[1].Side != "SHORT" || positions[1].Size != -0.2 {
+		t.Errorf("双向持仓转换错误: %+v %+v", positions[0], positions[1])
+	}
+}
+
+func TestGetHistoricalKlinesReversesOrder(t *testing.T) {
+	mock := newMockServer(t)
+	mock.Handle("/v5/market/kline", func(w http.ResponseWriter, payload string) {
+		if !strings.Contains(payload, "interval=5") {
+			t.Errorf("K线周期未转换: %s", payload)
+		}
+		writeResult(w, `{"list":[["1700000300000","2","3","1","2.5","10","25"],["1700000000000","1","2","0.5","2","20","40"]]}`)
+	})
+	adapter := newTestAdapter(t, mock.URL, nil)
+
+	candles, err := adapter.GetHistoricalKlines(context.Background(), "ETHUSDT", "5m", 2)
+	if err != nil {
+		t.Fatalf("获取K线失败: %v", err)
+	}
+	if len(candles) != 2 || candles[0].Timestamp != 1700000000000 || candles[1].Close != 2.5 {
+		t.Errorf("K线顺序或内容错误: %+v %+v", candles[0], candles[1])
+	}
+}
+
+// ===== WebSocket =====
+
+var upgrader = websocket.Upgrader{}
+
+func TestPrivateStreamAuthAndOrderUpdate(t *testing.T) {
+	mock := newMockServer(t)
+
+	wsServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var auth struct {
+			Op   string        `json:"op"`
+			Args []interface{} `json:"args"`
+		}
+		if err := conn.ReadJSON(&auth); err != nil || auth.Op != "auth" || len(auth.Args) != 3 {
+			t.Errorf("鉴权消息错误: %+v, %v", auth, err)
+			return
+		}
+		expires := int64(auth.Args[1].(float64))
+		if auth.Args[2] != NewSigner("test-key", "test-secret").SignWebSocket(expires) {
+			t.Errorf("WebSocket 鉴权签名错误")
+		}
+		conn.WriteJSON(map[string]interface{}{"success": true, "op": "auth"})
+
+		var sub struct {
+			Op   string   `json:"op"`
+			Args []string `json:"args"`
+		}
+		conn.ReadJSON(&sub)
+		if sub.Op != "subscribe" || len(sub.Args) != 2 {
+			t.Errorf("订阅消息错误: %+v", sub)
+		}
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"topic":"order","data":[{"symbol":"ETHUSDT",
+			"orderId":"`+testOrderUUID+`","orderLinkId":"300012_B_1700000000001","side":"Buy","orderType":"Limit",
+			"price":"3000.12","qty":"0.1","cumExecQty":"0.1","avgPrice":"3000.12","orderStatus":"Filled",
+			"updatedTime":"1700000001000"}]}`))
+
+		// 保持连接直到客户端断开
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	defer wsServer.Close()
+
+	adapter := newTestAdapter(t, mock.URL, map[string]string{
+		"ws_private_url": "ws" + strings.TrimPrefix(wsServer.URL, "http"),
+	})
+
+	updates := make(chan OrderUpdate, 1)
+	if err := adapter.wsManager.StartPrivate(context.Background(), func(u OrderUpdate) {
+		updates <- u
+	}); err != nil {
+		t.Fatalf("启动私有流失败: %v", err)
+	}
+	defer adapter.StopOrderStream()
+
+	select {
+	case u := <-updates:
+		if u.Status != OrderStatusFilled || u.ExecutedQty != 0.1 || u.ClientOrderID != "300012_B_1700000000001" {
+			t.Errorf("订单推送转换错误: %+v", u)
+		}
+		if u.OrderID != adapter.registerOrderID(testOrderUUID) {
+			t.Errorf("推送订单ID与下单返回的ID不一致: %d", u.OrderID)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("未收到订单推送")
+	}
+}
+
+func TestKlineStream(t *testing.T) {
+	wsServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var sub struct {
+			Op   string   `json:"op"`
+			Args []string `json:"args"`
+		}
+		conn.ReadJSON(&sub)
+		if len(sub.Args) != 1 || sub.Args[0] != "kline.1.ETHUSDT" {
+			t.Errorf("K线订阅参数错误: %+v", sub)
+		}
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"success":true,"op":"subscribe"}`))
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"topic":"kline.1.ETHUSDT","type":"snapshot","data":[
+			{"start":1700000000000,"open":"1","high":"2","low":"0.5","close":"1.5","volume":"100","confirm":true}]}`))
+
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	defer wsServer.Close()
+
+	manager := NewKlineWebSocketManager("ws" + strings.TrimPrefix(wsServer.URL, "http"))
+	candles := make(chan *Candle, 1)
+	if err := manager.Start(context.Background(), []string{"ethusdt"}, "1m", func(c interface{}) {
+		candles <- c.(*Candle)
+	}); err != nil {
+		t.Fatalf("启动K线流失败: %v", err)
+	}
+	defer manager.Stop()
+
+	select {
+	case c := <-candles:
+		if c.Symbol != "ETHUSDT" || c.Close != 1.5 || !c.IsClosed || c.Timestamp != 1700000000000 {
+			t.Errorf("K线转换错误: %+v", c)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("未收到K线推送")
+	}
+}
@@ -0,0 +1,145 @@
+package bybit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	BybitBaseURL        = "https://api.bybit.com"
+	BybitTestnetBaseURL = "https://api-testnet.bybit.com"
+	BybitDemoBaseURL    = "https://api-demo.bybit.com" // 模拟交易（使用模拟交易账户创建的 API Key）
+)
+
+// Client Bybit HTTP 客户端
+type Client struct {
+	httpClient *http.Client
+	signer     *Signer
+	baseURL    string
+}
+
+// NewClient 创建 Bybit 客户端
+func NewClient(apiKey, secretKey string) *Client {
+	return &Client{
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		signer:     NewSigner(apiKey, secretKey),
+		baseURL:    BybitBaseURL,
+	}
+}
+
+// BybitResponse Bybit V5 API 通用响应结构
+type BybitResponse struct {
+	RetCode int             `json:"retCode"`
+	RetMsg  string          `json:"retMsg"`
+	Result  json.RawMessage `json:"result"`
+	Time    int64           `json:"time"`
+}
+
+// APIError Bybit API 业务错误
+type APIError struct {
+	Code int
+	Msg  string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("bybit API 错误: code=%d, msg=%s", e.Code, e.Msg)
+}
+
+// DoRequest 发送 HTTP 请求（带签名）
+// GET 请求的参数放在 query 中（不含 ?），POST 请求的参数放在 body 中
+func (c *Client) DoRequest(ctx context.Context, method, path, query string, body interface{}) (*BybitResponse, error) {
+	var bodyBytes []byte
+	var err error
+
+	if body != nil {
+		bodyBytes, err = json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("序列化请求体失败: %w", err)
+		}
+	}
+
+	url := c.baseURL + path
+	payload := string(bodyBytes)
+	if method == http.MethodGet {
+		payload = query
+		if query != "" {
+			url += "?" + query
+		}
+	}
+
+	timestamp := c.signer.GetTimestamp()
+	signature := c.signer.Sign(timestamp, payload)
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewBuffer(bodyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+
+	// 添加 Bybit 必需的请求头
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-BAPI-API-KEY", c.signer.GetAPIKey())
+	req.Header.Set("X-BAPI-SIGN", signature)
+	req.Header.Set("X-BAPI-SIGN-TYPE", "2")
+	req.Header.Set("X-BAPI-TIMESTAMP", timestamp)
+	req.Header.Set("X-BAPI-RECV-WINDOW", c.signer.GetRecvWindow())
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取响应失败: %w", err)
+	}
+
+	var bybitResp BybitResponse
+	if err := json.Unmarshal(respBody, &bybitResp); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w, HTTP状态: %d, 响应体: %s", err, resp.StatusCode, string(respBody))
+	}
+
+	if bybitResp.RetCode != 0 {
+		return nil, &APIError{Code: bybitResp.RetCode, Msg: bybitResp.RetMsg}
+	}
+
+	return &bybitResp, nil
+}
+
+// ServerTime 查询服务器时间（公共接口，不签名，本地时钟偏差过大时也能对时）
+func (c *Client) ServerTime(ctx context.Context) (time.Time, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/v5/market/time", nil)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("创建请求失败: %w", err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		RetCode int    `json:"retCode"`
+		RetMsg  string `json:"retMsg"`
+		Result  struct {
+			TimeNano string `json:"timeNano"`
+		} `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return time.Time{}, fmt.Errorf("解析响应失败: %w", err)
+	}
+	if result.RetCode != 0 {
+		return time.Time{}, &APIError{Code: result.RetCode, Msg: result.RetMsg}
+	}
+	ns, err := strconv.ParseInt(result.Result.TimeNano, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("解析服务器时间失败: %w", err)
+	}
+	return time.Unix(0, ns), nil
+}
@@ -0,0 +1,331 @@
+package bybit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"opensqt/logger"
+
+	"github.com/gorilla/websocket"
+)
+
+// KlineWebSocketManager Bybit K线WebSocket管理器
+type KlineWebSocketManager struct {
+	wsURL          string
+	conn           *websocket.Conn
+	mu             sync.RWMutex
+	writeMu        sync.Mutex
+	done           chan struct{}
+	callbacks      map[string]func(candle interface{}) // 支持多个回调函数，key为组件名称
+	symbols        []string
+	interval       string
+	reconnectDelay time.Duration
+	pingInterval   time.Duration
+	isRunning      bool
+}
+
+// NewKlineWebSocketManager 创建K线WebSocket管理器
+func NewKlineWebSocketManager(wsURL string) *KlineWebSocketManager {
+	if wsURL == "" {
+		wsURL = BybitWSPublicLinear
+	}
+	return &KlineWebSocketManager{
+		wsURL:          wsURL,
+		done:           make(chan struct{}),
+		callbacks:      make(map[string]func(candle interface{})),
+		reconnectDelay: 5 * time.Second,
+		pingInterval:   20 * time.Second,
+	}
+}
+
+// Start 启动K线流（带自动重连）
+func (k *KlineWebSocketManager) Start(ctx context.Context, symbols []string, interval string, callback func(candle interface{})) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if k.isRunning {
+		// 如果K线流已经在运行，只注册回调函数
+		k.callbacks["default"] = callback
+		return nil
+	}
+
+	k.callbacks["default"] = callback
+	k.symbols = symbols
+	k.interval = interval
+	k.isRunning = true
+
+	go k.connectLoop(ctx)
+
+	return nil
+}
+
+// RegisterCallback 注册回调函数（支持多个组件共享K线流）
+func (k *KlineWebSocketManager) RegisterCallback(componentName string, callback func(candle interface{})) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if !k.isRunning {
+		return fmt.Errorf("K线流未启动，请先调用Start")
+	}
+
+	k.callbacks[componentName] = callback
+	logger.Info("✅ [Bybit K线] 已注册回调函数: %s", componentName)
+	return nil
+}
+
+// connectLoop 连接循环（自动重连）
+func (k *KlineWebSocketManager) connectLoop(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("✅ Bybit K线WebSocket已停止（上下文取消）")
+			return
+		case <-k.done:
+			logger.Info("✅ Bybit K线WebSocket已停止")
+			return
+		default:
+		}
+
+		logger.Info("🔗 正在连接 Bybit K线WebSocket...")
+		conn, _, err := websocket.DefaultDialer.DialContext(ctx, k.wsURL, nil)
+		if err == nil {
+			k.mu.Lock()
+			k.conn = conn
+			k.mu.Unlock()
+			if err = k.subscribe(k.symbols, k.interval); err != nil {
+				conn.Close()
+			}
+		}
+		if err != nil {
+			logger.Error("❌ Bybit K线WebSocket连接失败: %v，%v后重试", err, k.reconnectDelay)
+			select {
+			case <-ctx.Done():
+				return
+			case <-k.done:
+				return
+			case <-time.After(k.reconnectDelay):
+			}
+			continue
+		}
+
+		logger.Info("✅ Bybit K线WebSocket已连接")
+
+		go k.pingLoop(ctx, conn)
+		k.readLoop(conn)
+
+		k.mu.Lock()
+		if k.conn == conn {
+			k.conn = nil
+		}
+		k.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			logger.Info("✅ Bybit K线WebSocket已停止（上下文取消）")
+			return
+		case <-k.done:
+			logger.Info("✅ Bybit K线WebSocket已停止")
+			return
+		default:
+		}
+
+		logger.Warn("⚠️ Bybit K线WebSocket连接断开，%v后重连...", k.reconnectDelay)
+		select {
+		case <-ctx.Done():
+			return
+		case <-k.done:
+			return
+		case <-time.After(k.reconnectDelay):
+		}
+	}
+}
+
+// subscribe 订阅K线
+// 订阅格式: {"op": "subscribe", "args": ["kline.1.BTCUSDT"]}
+func (k *KlineWebSocketManager) subscribe(symbols []string, interval string) error {
+	bybitInterval := convertToBybitInterval(interval)
+	args := make([]string, len(symbols))
+	for i, symbol := range symbols {
+		args[i] = fmt.Sprintf("kline.%s.%s", bybitInterval, strings.ToUpper(symbol))
+	}
+
+	k.mu.RLock()
+	conn := k.conn
+	k.mu.RUnlock()
+	if conn == nil {
+		return fmt.Errorf("WebSocket连接未建立")
+	}
+
+	k.writeMu.Lock()
+	defer k.writeMu.Unlock()
+	if err := conn.WriteJSON(map[string]interface{}{"op": "subscribe", "args": args}); err != nil {
+		return fmt.Errorf("发送订阅消息失败: %w", err)
+	}
+
+	logger.Debug("已发送K线订阅请求: %d个币种", len(symbols))
+	return nil
+}
+
+// Stop 停止K线流
+func (k *KlineWebSocketManager) Stop() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if !k.isRunning {
+		return
+	}
+
+	k.isRunning = false
+	close(k.done)
+
+	if k.conn != nil {
+		k.conn.Close()
+		k.conn = nil
+	}
+
+	logger.Info("✅ Bybit K线WebSocket已停止")
+}
+
+// pingLoop ping循环
+func (k *KlineWebSocketManager) pingLoop(ctx context.Context, conn *websocket.Conn) {
+	ticker := time.NewTicker(k.pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-k.done:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			k.mu.RLock()
+			currentConn := k.conn
+			k.mu.RUnlock()
+			if currentConn != conn {
+				return
+			}
+
+			k.writeMu.Lock()
+			conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			err := conn.WriteJSON(map[string]string{"op": "ping"})
+			k.writeMu.Unlock()
+			if err != nil {
+				logger.Warn("⚠️ Bybit K线WebSocket发送Ping失败: %v", err)
+				conn.Close()
+				return
+			}
+		}
+	}
+}
+
+// readLoop 读取消息循环
+func (k *KlineWebSocketManager) readLoop(conn *websocket.Conn) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("❌ Bybit K线WebSocket读取协程panic: %v", r)
+		}
+		conn.Close()
+	}()
+
+	readTimeout := 3 * k.pingInterval
+	conn.SetReadDeadline(time.Now().Add(readTimeout))
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				logger.Warn("⚠️ Bybit K线WebSocket异常关闭: %v", err)
+			} else {
+				logger.Debug("Bybit K线WebSocket读取错误: %v", err)
+			}
+			return
+		}
+		conn.SetReadDeadline(time.Now().Add(readTimeout))
+
+		var msg struct {
+			Op    string `json:"op"`
+			Topic string `json:"topic"`
+			Data  []struct {
+				Start   int64  `json:"start"`
+				Open    string `json:"open"`
+				High    string `json:"high"`
+				Low     string `json:"low"`
+				Close   string `json:"close"`
+				Volume  string `json:"volume"`
+				Confirm bool   `json:"confirm"` // K线是否完结
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(message, &msg); err != nil {
+			logger.Debug("解析K线消息失败: %v", err)
+			continue
+		}
+
+		// 跳过订阅确认和 pong 消息
+		if msg.Op != "" || !strings.HasPrefix(msg.Topic, "kline.") {
+			continue
+		}
+
+		// topic 格式: kline.{interval}.{symbol}
+		parts := strings.Split(msg.Topic, ".")
+		if len(parts) != 3 {
+			continue
+		}
+		symbol := parts[2]
+
+		for _, kline := range msg.Data {
+			open, _ := strconv.ParseFloat(kline.Open, 64)
+			high, _ := strconv.ParseFloat(kline.High, 64)
+			low, _ := strconv.ParseFloat(kline.Low, 64)
+			close, _ := strconv.ParseFloat(kline.Close, 64)
+			volume, _ := strconv.ParseFloat(kline.Volume, 64)
+
+			candle := &Candle{
+				Symbol:    symbol,
+				Open:      open,
+				High:      high,
+				Low:       low,
+				Close:     close,
+				Volume:    volume,
+				Timestamp: kline.Start,
+				IsClosed:  kline.Confirm,
+			}
+
+			// 调用所有回调
+			k.mu.RLock()
+			callbacks := make([]func(candle interface{}), 0, len(k.callbacks))
+			for _, cb := range k.callbacks {
+				callbacks = append(callbacks, cb)
+			}
+			k.mu.RUnlock()
+
+			for _, callback := range callbacks {
+				if callback != nil {
+					callback(candle)
+				}
+			}
+		}
+	}
+}
+
+// ForceReconnect 强制重新连接K线流
+// 关闭当前连接后由 connectLoop 自动重连并重新订阅
+func (k *KlineWebSocketManager) ForceReconnect() error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if !k.isRunning {
+		return fmt.Errorf("K线流未启动，无法重新连接")
+	}
+
+	logger.Info("🔄 [Bybit K线] 正在强制重新连接...")
+	if k.conn != nil {
+		k.conn.Close()
+		k.conn = nil
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package bybit
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"time"
+
+	"opensqt/exchange/clock"
+)
+
+// Signer Bybit API 签名器
+type Signer struct {
+	apiKey     string
+	secretKey  string
+	recvWindow string
+	clock      *clock.Estimator // 服务器时间偏差，nil 时使用本地时间
+}
+
+// NewSigner 创建签名器
+func NewSigner(apiKey, secretKey string) *Signer {
+	return &Signer{
+		apiKey:     apiKey,
+		secretKey:  secretKey,
+		recvWindow: "5000",
+	}
+}
+
+// Sign 生成 REST 签名
+// Bybit V5 签名规则: Hex(HMAC_SHA256(timestamp + apiKey + recvWindow + payload, secretKey))
+// payload: GET 请求为 queryString，POST 请求为 JSON body
+func (s *Signer) Sign(timestamp, payload string) string {
+	message := timestamp + s.apiKey + s.recvWindow + payload
+	return s.hmacHex(message)
+}
+
+// SignWebSocket 生成私有 WebSocket 鉴权签名
+// Bybit V5 规则: Hex(HMAC_SHA256("GET/realtime" + expires, secretKey))
+func (s *Signer) SignWebSocket(expires int64) string {
+	return s.hmacHex(fmt.Sprintf("GET/realtime%d", expires))
+}
+
+// hmacHex 计算 HMAC-SHA256 并返回十六进制字符串
+func (s *Signer) hmacHex(message string) string {
+	mac := hmac.New(sha256.New, []byte(s.secretKey))
+	mac.Write([]byte(message))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// GetTimestamp 获取当前时间戳（毫秒）
+func (s *Signer) GetTimestamp() string {
+	return fmt.Sprintf("%d", s.clock.Now().UnixMilli())
+}
+
+// Now 校正后的当前时间（WebSocket 鉴权的过期时间以此为基准）
+func (s *Signer) Now() time.Time {
+	return s.clock.Now()
+}
+
+// GetAPIKey 获取 API Key
+func (s *Signer) GetAPIKey() string {
+	return s.apiKey
+}
+
+// GetRecvWindow 获取接收窗口（毫秒）
+func (s *Signer) GetRecvWindow() string {
+	return s.recvWindow
+}
@@ -0,0 +1,550 @@
+package bybit
+
+/*
+Bybit WebSocket 架构说明：
+
+1. **公共频道** (wss://stream.bybit.com/v5/public/linear)：订阅 tickers.{symbol} 价格推送
+2. **私有频道** (wss://stream.bybit.com/v5/private)：鉴权后订阅 order（订单）和 position（持仓）
+3. 两个连接相互独立，各自带自动重连；心跳使用 {"op":"ping"}，间隔 20 秒
+*/
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"opensqt/exchange/orderbook"
+	"opensqt/logger"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// Bybit V5 WebSocket 地址
+	BybitWSPublicLinear = "wss://stream.bybit.com/v5/public/linear"
+	BybitWSPrivate      = "wss://stream.bybit.com/v5/private"
+
+	// 测试网地址
+	BybitWSPublicLinearTestnet = "wss://stream-testnet.bybit.com/v5/public/linear"
+	BybitWSPrivateTestnet      = "wss://stream-testnet.bybit.com/v5/private"
+
+	// 模拟交易只有私有频道，行情使用实盘公共频道
+	BybitWSPrivateDemo = "wss://stream-demo.bybit.com/v5/private"
+)
+
+// WebSocketManager Bybit WebSocket 管理器
+type WebSocketManager struct {
+	signer     *Signer
+	publicURL  string
+	privateURL string
+
+	mu          sync.RWMutex
+	publicConn  *websocket.Conn
+	privateConn *websocket.Conn
+
+	// 回调函数
+	orderCallback    func(OrderUpdate)
+	positionCallback func(*Position)
+	priceCallback    func(float64)
+	bookCallback     func(*orderbook.Ticker)
+
+	// orderIDResolver 将 Bybit UUID 订单ID 映射为 int64（由适配器注入）
+	orderIDResolver func(string) int64
+
+	// 控制
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	publicStarted  bool
+	privateStarted bool
+	symbol         string
+
+	// 价格缓存
+	latestPrice float64
+	bookTicker  orderbook.Ticker // 买一/卖一（delta 推送按字段合并）
+	priceMu     sync.RWMutex
+
+	reconnectDelay time.Duration
+	pingInterval   time.Duration
+}
+
+// NewWebSocketManager 创建 WebSocket 管理器
+func NewWebSocketManager(signer *Signer) *WebSocketManager {
+	return &WebSocketManager{
+		signer:         signer,
+		publicURL:      BybitWSPublicLinear,
+		privateURL:     BybitWSPrivate,
+		reconnectDelay: 5 * time.Second,
+		pingInterval:   20 * time.Second, // Bybit 官方建议20秒发送一次 ping
+	}
+}
+
+// ensureContext 初始化内部 context（首次启动时）
+func (w *WebSocketManager) ensureContext(ctx context.Context) {
+	if w.ctx == nil || w.ctx.Err() != nil {
+		w.ctx, w.cancel = context.WithCancel(ctx)
+	}
+}
+
+// StartPublic 启动公共频道（价格推送）
+func (w *WebSocketManager) StartPublic(ctx context.Context, symbol string, callback func(float64)) error {
+	w.mu.Lock()
+	w.priceCallback = callback
+	w.symbol = symbol
+	if w.publicStarted {
+		w.mu.Unlock()
+		logger.Debug("✅ [Bybit] 价格流回调已注册（WebSocket已在运行）")
+		return nil
+	}
+	w.ensureContext(ctx)
+	w.publicStarted = true
+	w.mu.Unlock()
+
+	w.wg.Add(1)
+	go w.connectLoop("公共", w.publicURL, w.onPublicConnected, w.handlePublicMessage)
+
+	logger.Info("✅ [Bybit WebSocket] 启动成功，将订阅 %s 的价格更新", symbol)
+	return nil
+}
+
+// StartBookTicker 注册盘口最优价回调（复用 tickers 推送的 bid1/ask1），公共频道未运行时一并启动
+func (w *WebSocketManager) StartBookTicker(ctx context.Context, symbol string, callback func(*orderbook.Ticker)) error {
+	w.mu.Lock()
+	if w.publicStarted && w.symbol != symbol {
+		w.mu.Unlock()
+		return fmt.Errorf("公共频道已订阅 %s，不支持同时订阅 %s", w.symbol, symbol)
+	}
+	w.bookCallback = callback
+	w.symbol = symbol
+	if w.publicStarted {
+		w.mu.Unlock()
+		return nil
+	}
+	w.ensureContext(ctx)
+	w.publicStarted = true
+	w.mu.Unlock()
+
+	w.wg.Add(1)
+	go w.connectLoop("公共", w.publicURL, w.onPublicConnected, w.handlePublicMessage)
+
+	logger.Info("✅ [Bybit WebSocket] 启动成功，将订阅 %s 的盘口最优价", symbol)
+	return nil
+}
+
+// StartPrivate 启动私有频道（订单、持仓推送）
+func (w *WebSocketManager) StartPrivate(ctx context.Context, callback func(OrderUpdate)) error {
+	w.mu.Lock()
+	w.orderCallback = callback
+	if w.privateStarted {
+		w.mu.Unlock()
+		return nil
+	}
+	w.ensureContext(ctx)
+	w.privateStarted = true
+	w.mu.Unlock()
+
+	w.wg.Add(1)
+	go w.connectLoop("私有", w.privateURL, w.onPrivateConnected, w.handlePrivateMessage)
+
+	logger.Info("✅ [Bybit WebSocket] 启动成功，将订阅订单和持仓更新")
+	return nil
+}
+
+// SetPositionCallback 设置持仓推送回调
+func (w *WebSocketManager) SetPositionCallback(callback func(*Position)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.positionCallback = callback
+}
+
+// Stop 停止 WebSocket
+func (w *WebSocketManager) Stop() {
+	w.mu.Lock()
+	if w.cancel != nil {
+		w.cancel()
+	}
+	if w.publicConn != nil {
+		w.publicConn.Close()
+	}
+	if w.privateConn != nil {
+		w.privateConn.Close()
+	}
+	w.publicStarted = false
+	w.privateStarted = false
+	w.mu.Unlock()
+
+	// 等待所有 goroutine 退出（不能持有锁，避免死锁）
+	w.wg.Wait()
+	logger.Info("✅ [Bybit WebSocket] 已停止")
+}
+
+// GetLatestPrice 获取缓存的最新价格
+func (w *WebSocketManager) GetLatestPrice() float64 {
+	w.priceMu.RLock()
+	defer w.priceMu.RUnlock()
+	return w.latestPrice
+}
+
+// connectLoop 连接循环（自动重连）
+// onConnected: 连接建立后执行鉴权/订阅，handler: 处理每条消息
+func (w *WebSocketManager) connectLoop(name, wsURL string, onConnected func(*websocket.Conn) error, handler func([]byte)) {
+	defer w.wg.Done()
+
+	for {
+		select {
+		case <-w.ctx.Done():
+			logger.Info("✅ [Bybit WS%s] 停止连接循环", name)
+			return
+		default:
+		}
+
+		logger.Info("🔗 [Bybit WS%s] 正在连接...", name)
+		conn, _, err := websocket.DefaultDialer.DialContext(w.ctx, wsURL, nil)
+		if err == nil {
+			if err = onConnected(conn); err != nil {
+				conn.Close()
+			}
+		}
+		if err != nil {
+			logger.Error("❌ [Bybit WS%s] 连接失败: %v，%v后重试", name, err, w.reconnectDelay)
+			select {
+			case <-w.ctx.Done():
+				logger.Info("✅ [Bybit WS%s] 停止连接循环", name)
+				return
+			case <-time.After(w.reconnectDelay):
+			}
+			continue
+		}
+
+		logger.Info("✅ [Bybit WS%s] 已连接", name)
+
+		// 启动心跳，读取循环阻塞直到连接断开
+		pingDone := make(chan struct{})
+		go w.pingLoop(conn, pingDone)
+		w.readLoop(conn, handler)
+		close(pingDone)
+
+		w.mu.Lock()
+		if w.publicConn == conn {
+			w.publicConn = nil
+		}
+		if w.privateConn == conn {
+			w.privateConn = nil
+		}
+		w.mu.Unlock()
+		conn.Close()
+
+		select {
+		case <-w.ctx.Done():
+			logger.Info("✅ [Bybit WS%s] 停止连接循环", name)
+			return
+		default:
+		}
+
+		logger.Warn("⚠️ [Bybit WS%s] 连接断开，%v后重连...", name, w.reconnectDelay)
+		select {
+		case <-w.ctx.Done():
+			logger.Info("✅ [Bybit WS%s] 停止连接循环", name)
+			return
+		case <-time.After(w.reconnectDelay):
+		}
+	}
+}
+
+// onPublicConnected 公共频道连接建立后订阅 tickers
+func (w *WebSocketManager) onPublicConnected(conn *websocket.Conn) error {
+	w.mu.Lock()
+	w.publicConn = conn
+	symbol := w.symbol
+	w.mu.Unlock()
+
+	return conn.WriteJSON(map[string]interface{}{
+		"op":   "subscribe",
+		"args": []string{"tickers." + symbol},
+	})
+}
+
+// onPrivateConnected 私有频道连接建立后鉴权并订阅 order / position
+func (w *WebSocketManager) onPrivateConnected(conn *websocket.Conn) error {
+	expires := w.signer.Now().Add(10 * time.Second).UnixMilli()
+	authMsg := map[string]interface{}{
+		"op":   "auth",
+		"args": []interface{}{w.signer.GetAPIKey(), expires, w.signer.SignWebSocket(expires)},
+	}
+	if err := conn.WriteJSON(authMsg); err != nil {
+		return fmt.Errorf("发送鉴权消息失败: %w", err)
+	}
+
+	conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+	var resp struct {
+		Success bool   `json:"success"`
+		RetMsg  string `json:"ret_msg"`
+		Op      string `json:"op"`
+	}
+	if err := conn.ReadJSON(&resp); err != nil {
+		return fmt.Errorf("读取鉴权响应失败: %w", err)
+	}
+	if !resp.Success {
+		return fmt.Errorf("鉴权失败: %s", resp.RetMsg)
+	}
+	logger.Info("✅ [Bybit WebSocket] 私有频道鉴权成功")
+
+	w.mu.Lock()
+	w.privateConn = conn
+	w.mu.Unlock()
+
+	return conn.WriteJSON(map[string]interface{}{
+		"op":   "subscribe",
+		"args": []string{"order.linear", "position.linear"},
+	})
+}
+
+// pingLoop 心跳循环
+func (w *WebSocketManager) pingLoop(conn *websocket.Conn, done chan struct{}) {
+	ticker := time.NewTicker(w.pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-w.ctx.Done():
+			return
+		case <-ticker.C:
+			w.mu.Lock()
+			conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			err := conn.WriteJSON(map[string]string{"op": "ping"})
+			w.mu.Unlock()
+			if err != nil {
+				logger.Warn("⚠️ [Bybit WebSocket] 发送 Ping 失败: %v", err)
+				conn.Close()
+				return
+			}
+		}
+	}
+}
+
+// readLoop 读取消息循环
+func (w *WebSocketManager) readLoop(conn *websocket.Conn, handler func([]byte)) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("❌ [Bybit WebSocket] 读取协程panic: %v", r)
+		}
+	}()
+
+	readTimeout := 3 * w.pingInterval
+	conn.SetReadDeadline(time.Now().Add(readTimeout))
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				logger.Warn("⚠️ [Bybit WebSocket] 异常关闭: %v", err)
+			} else {
+				logger.Debug("Bybit WebSocket 读取错误: %v", err)
+			}
+			return
+		}
+		conn.SetReadDeadline(time.Now().Add(readTimeout))
+		handler(message)
+	}
+}
+
+// wsMessage Bybit WebSocket 推送消息
+type wsMessage struct {
+	Op      string          `json:"op"`
+	Success *bool           `json:"success"`
+	RetMsg  string          `json:"ret_msg"`
+	Topic   string          `json:"topic"`
+	Type    string          `json:"type"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// handlePublicMessage 处理公共频道消息
+func (w *WebSocketManager) handlePublicMessage(message []byte) {
+	var msg wsMessage
+	if err := json.Unmarshal(message, &msg); err != nil {
+		logger.Debug("解析 Bybit 公共消息失败: %v", err)
+		return
+	}
+
+	if msg.Op != "" {
+		if msg.Success != nil && !*msg.Success {
+			logger.Warn("⚠️ [Bybit WS公共] %s 失败: %s", msg.Op, msg.RetMsg)
+		}
+		return
+	}
+
+	if len(msg.Topic) < 8 || msg.Topic[:8] != "tickers." {
+		return
+	}
+
+	// delta 推送中未变化的字段会缺失，只在字段存在时更新
+	var ticker struct {
+		Symbol    string `json:"symbol"`
+		LastPrice string `json:"lastPrice"`
+		Bid1Price string `json:"bid1Price"`
+		Bid1Size  string `json:"bid1Size"`
+		Ask1Price string `json:"ask1Price"`
+		Ask1Size  string `json:"ask1Size"`
+	}
+	if err := json.Unmarshal(msg.Data, &ticker); err != nil {
+		return
+	}
+	w.handleBookTicker(ticker.Symbol, ticker.Bid1Price, ticker.Bid1Size, ticker.Ask1Price, ticker.Ask1Size)
+
+	if ticker.LastPrice == "" {
+		return
+	}
+	price, err := strconv.ParseFloat(ticker.LastPrice, 64)
+	if err != nil || price <= 0 {
+		return
+	}
+
+	w.priceMu.Lock()
+	w.latestPrice = price
+	w.priceMu.Unlock()
+
+	w.mu.RLock()
+	callback := w.priceCallback
+	w.mu.RUnlock()
+	if callback != nil {
+		callback(price)
+	}
+}
+
+// handleBookTicker 合并 tickers 推送中的买一/卖一字段，有变化且两侧均有效时回调
+func (w *WebSocketManager) handleBookTicker(symbol, bidPrice, bidSize, askPrice, askSize string) {
+	if bidPrice == "" && bidSize == "" && askPrice == "" && askSize == "" {
+		return
+	}
+	merge := func(dst *float64, raw string) {
+		if raw == "" {
+			return
+		}
+		if v, err := strconv.ParseFloat(raw, 64); err == nil {
+			*dst = v
+		}
+	}
+
+	w.priceMu.Lock()
+	merge(&w.bookTicker.BidPrice, bidPrice)
+	merge(&w.bookTicker.BidQty, bidSize)
+	merge(&w.bookTicker.AskPrice, askPrice)
+	merge(&w.bookTicker.AskQty, askSize)
+	w.bookTicker.Symbol = symbol
+	w.bookTicker.Time = time.Now().UnixMilli()
+	ticker := w.bookTicker
+	w.priceMu.Unlock()
+
+	if ticker.BidPrice <= 0 || ticker.AskPrice <= 0 {
+		return
+	}
+	w.mu.RLock()
+	callback := w.bookCallback
+	w.mu.RUnlock()
+	if callback != nil {
+		callback(&ticker)
+	}
+}
+
+// handlePrivateMessage 处理私有频道消息
+func (w *WebSocketManager) handlePrivateMessage(message []byte) {
+	var msg wsMessage
+	if err := json.Unmarshal(message, &msg); err != nil {
+		logger.Debug("解析 Bybit 私有消息失败: %v", err)
+		return
+	}
+
+	if msg.Op != "" {
+		if msg.Success != nil && !*msg.Success {
+			logger.Warn("⚠️ [Bybit WS私有] %s 失败: %s", msg.Op, msg.RetMsg)
+		}
+		return
+	}
+
+	switch msg.Topic {
+	case "order", "order.linear":
+		w.handleOrderUpdate(msg.Data)
+	case "position", "position.linear":
+		w.handlePositionUpdate(msg.Data)
+	}
+}
+
+// handleOrderUpdate 处理订单推送
+func (w *WebSocketManager) handleOrderUpdate(data json.RawMessage) {
+	var items []bybitOrder
+	if err := json.Unmarshal(data, &items); err != nil {
+		logger.Warn("⚠️ [Bybit] 解析订单推送失败: %v", err)
+		return
+	}
+
+	w.mu.RLock()
+	callback := w.orderCallback
+	resolver := w.orderIDResolver
+	w.mu.RUnlock()
+	if callback == nil {
+		return
+	}
+
+	for i := range items {
+		item := &items[i]
+		price, _ := strconv.ParseFloat(item.Price, 64)
+		quantity, _ := strconv.ParseFloat(item.Qty, 64)
+		executedQty, _ := strconv.ParseFloat(item.CumExecQty, 64)
+		avgPrice, _ := strconv.ParseFloat(item.AvgPrice, 64)
+		updateTime, _ := strconv.ParseInt(item.UpdatedTime, 10, 64)
+
+		var orderID int64
+		if resolver != nil {
+			orderID = resolver(item.OrderID)
+		}
+
+		orderType := OrderTypeLimit
+		if item.OrderType == "Market" {
+			orderType = OrderTypeMarket
+		}
+
+		update := OrderUpdate{
+			OrderID:       orderID,
+			ClientOrderID: item.OrderLinkID,
+			Symbol:        item.Symbol,
+			Side:          convertSide(item.Side),
+			Type:          orderType,
+			Status:        convertStatus(item.OrderStatus),
+			Price:         price,
+			Quantity:      quantity,
+			ExecutedQty:   executedQty,
+			AvgPrice:      avgPrice,
+			UpdateTime:    updateTime,
+		}
+
+		logger.Debug("🔍 [Bybit] 订单推送: ID=%d, ClientOID=%s, Status=%s, 成交=%.6f",
+			update.OrderID, update.ClientOrderID, update.Status, update.ExecutedQty)
+		callback(update)
+	}
+}
+
+// handlePositionUpdate 处理持仓推送
+func (w *WebSocketManager) handlePositionUpdate(data json.RawMessage) {
+	var items []bybitPosition
+	if err := json.Unmarshal(data, &items); err != nil {
+		logger.Warn("⚠️ [Bybit] 解析持仓推送失败: %v", err)
+		return
+	}
+
+	w.mu.RLock()
+	callback := w.positionCallback
+	w.mu.RUnlock()
+	if callback == nil {
+		return
+	}
+
+	for i := range items {
+		callback(items[i].toPosition())
+	}
+}
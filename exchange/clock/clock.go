@@ -0,0 +1,148 @@
+// Package clock 交易所服务器时间同步
+// 各交易所要求签名时间戳与服务器时间相差在窗口内（如币安 recvWindow 默认 5 秒、Bitget 30 秒），
+// VPS 时钟漂移超出窗口后所有签名请求都会被拒。Estimator 定期请求交易所的服务器时间接口，
+// 取往返时延最小的样本估算偏差，签名器用 Now() 代替本地时间。
+// 独立成包是为了让各交易所子包共用而不产生循环依赖。
+package clock
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"opensqt/logger"
+)
+
+const (
+	// DefaultSyncInterval 默认对时间隔
+	DefaultSyncInterval = 5 * time.Minute
+	// samplesPerSync 每次对时的采样次数（取往返时延最小的样本）
+	samplesPerSync = 3
+	// warnSkew 偏差超过该值时打印警告，提示检查系统时钟
+	warnSkew = time.Second
+)
+
+// ServerTimeFunc 查询交易所服务器时间
+type ServerTimeFunc func(ctx context.Context) (time.Time, error)
+
+// Estimator 本地时钟相对交易所服务器的偏差估计器
+// 零值与 nil 均可用（偏差为 0，即使用本地时间）
+type Estimator struct {
+	name  string // 日志前缀中的交易所名称
+	fetch ServerTimeFunc
+	now   func() time.Time // 本地时钟，测试时可替换
+
+	mu     sync.RWMutex
+	offset time.Duration // 服务器时间 - 本地时间
+
+	startOnce sync.Once
+}
+
+// NewEstimator 创建偏差估计器
+func NewEstimator(name string, fetch ServerTimeFunc) *Estimator {
+	return &Estimator{name: name, fetch: fetch, now: time.Now}
+}
+
+// Now 校正后的当前时间（本地时间 + 偏差）
+func (e *Estimator) Now() time.Time {
+	if e == nil {
+		return time.Now()
+	}
+	return e.localNow().Add(e.Offset())
+}
+
+// Offset 当前偏差（服务器时间 - 本地时间，已扣除半个往返时延），正值表示本地时钟偏慢
+func (e *Estimator) Offset() time.Duration {
+	if e == nil {
+		return 0
+	}
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.offset
+}
+
+// Sync 立即对时：采样 samplesPerSync 次，取往返时延最小的样本
+// 全部采样失败时保留上一次的偏差并返回错误
+func (e *Estimator) Sync(ctx context.Context) error {
+	if e == nil || e.fetch == nil {
+		return nil
+	}
+
+	var (
+		best    time.Duration
+		bestRTT time.Duration = -1
+		lastErr error
+	)
+	for i := 0; i < samplesPerSync; i++ {
+		sent := e.localNow()
+		serverTime, err := e.fetch(ctx)
+		received := e.localNow()
+		if err != nil {
+			lastErr = err
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+		rtt := received.Sub(sent)
+		if bestRTT < 0 || rtt < bestRTT {
+			bestRTT = rtt
+			// 假设请求与响应路径耗时相同，服务器时间对应发出后半个往返时延的本地时间
+			best = serverTime.Sub(sent.Add(rtt / 2))
+		}
+	}
+	if bestRTT < 0 {
+		return fmt.Errorf("%s 对时失败: %w", e.name, lastErr)
+	}
+
+	e.mu.Lock()
+	e.offset = best
+	e.mu.Unlock()
+
+	if best > warnSkew || best < -warnSkew {
+		logger.Warn("⚠️ [%s 对时] 本地时钟偏差 %v（往返 %v），签名时间戳已按服务器时间校正，建议检查系统时钟同步",
+			e.name, best.Round(time.Millisecond), bestRTT.Round(time.Millisecond))
+	} else {
+		logger.Debug("[%s 对时] 偏差 %v，往返 %v", e.name, best.Round(time.Millisecond), bestRTT.Round(time.Millisecond))
+	}
+	return nil
+}
+
+// Start 立即对时一次并启动后台定期对时（重复调用只启动一次），ctx 取消时停止
+func (e *Estimator) Start(ctx context.Context, interval time.Duration) {
+	if e == nil {
+		return
+	}
+	e.startOnce.Do(func() {
+		if interval <= 0 {
+			interval = DefaultSyncInterval
+		}
+		if err := e.Sync(ctx); err != nil {
+			logger.Warn("⚠️ [%s 对时] %v，暂时使用本地时间", e.name, err)
+		}
+
+		go func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					if err := e.Sync(ctx); err != nil {
+						logger.Warn("⚠️ [%s 对时] %v，沿用上次偏差 %v", e.name, err, e.Offset())
+					}
+				}
+			}
+		}()
+	})
+}
+
+// localNow 本地时间
+func (e *Estimator) localNow() time.Time {
+	if e.now == nil {
+		return time.Now()
+	}
+	return e.now()
+}
@@ -0,0 +1,66 @@
+package clock
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSyncUsesLowestRTTSample(t *testing.T) {
+	local := time.Unix(1700000000, 0)
+	// 每次采样的往返时延依次为 400ms、100ms、300ms，服务器时钟比本地快 2 秒
+	rtts := []time.Duration{400 * time.Millisecond, 100 * time.Millisecond, 300 * time.Millisecond}
+	// 服务器返回的时间各带不同的响应延迟误差，RTT 最小的样本最准
+	serverErr := []time.Duration{150 * time.Millisecond, 0, -100 * time.Millisecond}
+	sample := 0
+
+	e := NewEstimator("Test", func(ctx context.Context) (time.Time, error) {
+		server := local.Add(2*time.Second + rtts[sample]/2 + serverErr[sample])
+		local = local.Add(rtts[sample])
+		sample++
+		return server, nil
+	})
+	e.now = func() time.Time { return local }
+
+	if err := e.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync 返回错误: %v", err)
+	}
+	if got := e.Offset(); got != 2*time.Second {
+		t.Errorf("Offset = %v, want 2s", got)
+	}
+	if got := e.Now().Sub(local); got != 2*time.Second {
+		t.Errorf("Now 校正 = %v, want 2s", got)
+	}
+}
+
+func TestSyncFailureKeepsPreviousOffset(t *testing.T) {
+	fail := false
+	e := NewEstimator("Test", func(ctx context.Context) (time.Time, error) {
+		if fail {
+			return time.Time{}, errors.New("timeout")
+		}
+		return time.Now().Add(-time.Minute), nil
+	})
+
+	if err := e.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync 返回错误: %v", err)
+	}
+	before := e.Offset()
+	if before > -59*time.Second || before < -61*time.Second {
+		t.Fatalf("Offset = %v, want about -1m", before)
+	}
+
+	fail = true
+	if err := e.Sync(context.Background()); err == nil {
+		t.Fatalf("全部采样失败时应返回错误")
+	}
+	if e.Offset() != before {
+		t.Errorf("对时失败后偏差应保持 %v, got %v", before, e.Offset())
+	}
+
+	var nilEstimator *Estimator
+	if nilEstimator.Offset() != 0 || nilEstimator.Sync(context.Background()) != nil {
+		t.Errorf("nil Estimator 应使用本地时间")
+	}
+}
@@ -0,0 +1,1080 @@
+package edgex
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"opensqt/exchange/clock"
+	"opensqt/exchange/errs"
+	"opensqt/exchange/venue"
+	"opensqt/logger"
+)
+
+// 订单、持仓与行情类型与其他交易所共用（定义在 venue 包中，避免与 exchange 包循环导入）
+type (
+	Side                = venue.Side
+	OrderType           = venue.OrderType
+	OrderStatus         = venue.OrderStatus
+	TimeInForce         = venue.TimeInForce
+	OrderRequest        = venue.OrderRequest
+	Order               = venue.Order
+	Position            = venue.Position
+	Account             = venue.Account
+	SymbolInfo          = venue.SymbolInfo
+	OrderUpdate         = venue.OrderUpdate
+	OrderUpdateCallback = venue.OrderUpdateCallback
+	Candle              = venue.Candle
+)
+
+const (
+	SideBuy  = venue.SideBuy
+	SideSell = venue.SideSell
+
+	OrderTypeLimit  = venue.OrderTypeLimit
+	OrderTypeMarket = venue.OrderTypeMarket
+
+	OrderStatusNew             = venue.OrderStatusNew
+	OrderStatusPartiallyFilled = venue.OrderStatusPartiallyFilled
+	OrderStatusFilled          = venue.OrderStatusFilled
+	OrderStatusCanceled        = venue.OrderStatusCanceled
+	OrderStatusRejected        = venue.OrderStatusRejected
+	OrderStatusExpired         = venue.OrderStatusExpired
+
+	TimeInForceGTC = venue.TimeInForceGTC
+)
+
+const (
+	// 订单有效期：edgeX 要求 L2 过期时间晚于订单过期时间
+	orderExpireDuration   = 28 * 24 * time.Hour
+	l2ExpireExtraDuration = 14 * 24 * time.Hour
+	// 市价单使用的保护价格偏移（用于计算 L2 签名金额）
+	marketOrderSlippage = 0.05
+)
+
+// edgexContract edgeX 合约元数据
+type edgexContract struct {
+	ContractID          string `json:"contractId"`
+	ContractName        string `json:"contractName"` // 如 ETHUSDT
+	BaseCoinID          string `json:"baseCoinId"`
+	QuoteCoinID         string `json:"quoteCoinId"`
+	TickSize            string `json:"tickSize"`
+	StepSize            string `json:"stepSize"`
+	MinOrderSize        string `json:"minOrderSize"`
+	DefaultTakerFee     string `json:"defaultTakerFeeRate"`
+	DisplayMaxLeverage  string `json:"displayMaxLeverage"`
+	SyntheticAssetID    string `json:"starkExSyntheticAssetId"`
+	SyntheticResolution string `json:"starkExResolution"`
+}
+
+// EdgeXAdapter edgeX（StarkEx 永续合约）适配器
+// 下单需要用 Stark 私钥对 L2 订单（资产ID、链上金额、nonce、过期时间）签名
+type EdgeXAdapter struct {
+	client         *Client
+	signer         *Signer
+	wsManager      *WebSocketManager
+	klineWSManager *KlineWebSocketManager
+	symbol         string
+	clock          *clock.Estimator // 服务器时间偏差（校正签名时间戳和订单过期时间）
+
+	contract  *edgexContract
+	contracts map[string]*edgexContract // contractName -> 合约（K线多币种订阅使用）
+	nameByID  map[string]string         // contractId -> contractName
+	coinNames map[string]string         // coinId -> 币种名称
+
+	syntheticAssetID     *big.Int
+	syntheticResolution  float64
+	collateralAssetID    *big.Int
+	collateralResolution float64
+	feeRate              float64 // L2 签名使用的最大手续费率
+
+	tickSize         float64
+	stepSize         float64
+	minOrderSize     float64
+	priceDecimals    int
+	quantityDecimals int
+	maxLeverage      int
+	baseAsset        string
+	quoteAsset       string
+}
+
+// NewEdgeXAdapter 创建 edgeX 适配器
+// cfg["api_key"] 为 edgeX 账户ID（accountId），cfg["secret_key"] 为 Stark L2 私钥
+// cfg 键 environment 选择 mainnet/testnet；
+// 可选键 base_url / ws_public_url / ws_private_url 用于指向自定义网关，优先于 environment
+func NewEdgeXAdapter(cfg map[string]string, symbol string) (*EdgeXAdapter, error) {
+	accountID := cfg["api_key"]
+	starkPrivateKey := cfg["secret_key"]
+
+	if accountID == "" || starkPrivateKey == "" {
+		return nil, fmt.Errorf("edgeX API 配置不完整")
+	}
+
+	signer, err := NewSigner(accountID, starkPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	client := NewClient(signer)
+	wsManager := NewWebSocketManager(signer)
+
+	switch env := cfg["environment"]; env {
+	case "", "mainnet":
+	case "testnet":
+		client.baseURL = EdgeXTestnetBaseURL
+		wsManager.publicURL = EdgeXWSPublicTestnet
+		wsManager.privateURL = EdgeXWSPrivateTestnet
+		logger.Info("ℹ️ [edgeX] 使用测试网")
+	default:
+		return nil, fmt.Errorf("edgeX 不支持 environment=%s（可选 mainnet/testnet）", env)
+	}
+
+	if baseURL := cfg["base_url"]; baseURL != "" {
+		client.baseURL = strings.TrimRight(baseURL, "/")
+	}
+	if u := cfg["ws_public_url"]; u != "" {
+		wsManager.publicURL = u
+	}
+	if u := cfg["ws_private_url"]; u != "" {
+		wsManager.privateURL = u
+	}
+
+	adapter := &EdgeXAdapter{
+		client:    client,
+		signer:    signer,
+		wsManager: wsManager,
+		symbol:    strings.ToUpper(symbol),
+		contracts: make(map[string]*edgexContract),
+		nameByID:  make(map[string]string),
+	}
+	wsManager.adapter = adapter
+	adapter.klineWSManager = NewKlineWebSocketManager(wsManager.publicURL, adapter.contractIDOf, adapter.symbolOf)
+	adapter.startClock()
+
+	// 初始化获取元数据（资产ID 和精度是 L2 签名的必需参数，获取失败无法下单）
+	ctxInit, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := adapter.fetchMetaData(ctxInit); err != nil {
+		return nil, fmt.Errorf("获取 edgeX 元数据失败: %w", err)
+	}
+
+	return adapter, nil
+}
+
+// GetName 获取交易所名称
+func (e *EdgeXAdapter) GetName() string {
+	return "edgeX"
+}
+
+// startClock 创建对时器并注入签名器（REST、WebSocket 与 L2 订单过期时间共用），立即对时一次后定期对时
+func (e *EdgeXAdapter) startClock() {
+	e.clock = clock.NewEstimator("edgeX", e.client.ServerTime)
+	e.signer.clock = e.clock
+	e.clock.Start(context.Background(), clock.DefaultSyncInterval)
+}
+
+// ClockSkew 本地时钟相对服务器的偏差（服务器时间 - 本地时间）
+func (e *EdgeXAdapter) ClockSkew() time.Duration {
+	return e.clock.Offset()
+}
+
+// SyncServerTime 立即与服务器对时
+func (e *EdgeXAdapter) SyncServerTime(ctx context.Context) error {
+	return e.clock.Sync(ctx)
+}
+
+// fetchMetaData 获取全局元数据（保证金资产、合约列表）
+func (e *EdgeXAdapter) fetchMetaData(ctx context.Context) error {
+	resp, err := e.client.DoRequest(ctx, http.MethodGet, "/api/v1/public/meta/getMetaData", nil, nil)
+	if err != nil {
+		return err
+	}
+
+	var meta struct {
+		Global struct {
+			CollateralCoin struct {
+				CoinID        string `json:"coinId"`
+				CoinName      string `json:"coinName"`
+				StarkAssetID  string `json:"starkExAssetId"`
+				StarkAssetRes string `json:"starkExResolution"`
+			} `json:"starkExCollateralCoin"`
+		} `json:"global"`
+		CoinList []struct {
+			CoinID   string `json:"coinId"`
+			CoinName string `json:"coinName"`
+		} `json:"coinList"`
+		ContractList []*edgexContract `json:"contractList"`
+	}
+	if err := json.Unmarshal(resp.Data, &meta); err != nil {
+		return fmt.Errorf("解析元数据失败: %w", err)
+	}
+
+	for _, c := range meta.ContractList {
+		e.contracts[strings.ToUpper(c.ContractName)] = c
+		e.nameByID[c.ContractID] = strings.ToUpper(c.ContractName)
+	}
+	contract, ok := e.contracts[e.symbol]
+	if !ok {
+		return fmt.Errorf("未找到合约: %s", e.symbol)
+	}
+	e.contract = contract
+
+	collateral := meta.Global.CollateralCoin
+	if e.collateralAssetID, err = parseHexBig(collateral.StarkAssetID); err != nil {
+		return fmt.Errorf("保证金资产ID无效: %w", err)
+	}
+	if e.syntheticAssetID, err = parseHexBig(contract.SyntheticAssetID); err != nil {
+		return fmt.Errorf("合成资产ID无效: %w", err)
+	}
+	e.collateralResolution = parseResolution(collateral.StarkAssetRes)
+	e.syntheticResolution = parseResolution(contract.SyntheticResolution)
+
+	e.tickSize, _ = strconv.ParseFloat(contract.TickSize, 64)
+	e.stepSize, _ = strconv.ParseFloat(contract.StepSize, 64)
+	e.minOrderSize, _ = strconv.ParseFloat(contract.MinOrderSize, 64)
+	e.feeRate, _ = strconv.ParseFloat(contract.DefaultTakerFee, 64)
+	if e.feeRate <= 0 {
+		e.feeRate = 0.001
+	}
+	maxLeverage, _ := strconv.ParseFloat(contract.DisplayMaxLeverage, 64)
+	e.maxLeverage = int(maxLeverage)
+	e.priceDecimals = countDecimalPlaces(contract.TickSize)
+	e.quantityDecimals = countDecimalPlaces(contract.StepSize)
+
+	e.coinNames = make(map[string]string, len(meta.CoinList)+1)
+	for _, coin := range meta.CoinList {
+		e.coinNames[coin.CoinID] = coin.CoinName
+	}
+	e.baseAsset = e.coinNames[contract.BaseCoinID]
+	e.quoteAsset = e.coinNames[contract.QuoteCoinID]
+	if e.quoteAsset == "" {
+		e.quoteAsset = collateral.CoinName
+		e.coinNames[contract.QuoteCoinID] = collateral.CoinName
+	}
+
+	logger.Info("ℹ️ [edgeX 合约信息] %s (contractId=%s), 价格精度:%d, 数量精度:%d, 最小数量:%s, 保证金币种:%s",
+		e.symbol, contract.ContractID, e.priceDecimals, e.quantityDecimals, contract.MinOrderSize, e.quoteAsset)
+
+	return nil
+}
+
+// GetSymbolInfo 获取合约交易规则（来自初始化时缓存的全局元数据）
+func (e *EdgeXAdapter) GetSymbolInfo(ctx context.Context, symbol string) (*SymbolInfo, error) {
+	contract, ok := e.contracts[strings.ToUpper(symbol)]
+	if !ok {
+		return nil, fmt.Errorf("未找到合约: %s", symbol)
+	}
+
+	info := &SymbolInfo{
+		Symbol:             strings.ToUpper(contract.ContractName),
+		BaseAsset:          e.coinNames[contract.BaseCoinID],
+		QuoteAsset:         e.coinNames[contract.QuoteCoinID],
+		ContractMultiplier: 1, // edgeX 按币数量下单
+		PriceDecimals:      countDecimalPlaces(contract.TickSize),
+		QuantityDecimals:   countDecimalPlaces(contract.StepSize),
+	}
+	info.TickSize, _ = strconv.ParseFloat(contract.TickSize, 64)
+	info.StepSize, _ = strconv.ParseFloat(contract.StepSize, 64)
+	info.MinQty, _ = strconv.ParseFloat(contract.MinOrderSize, 64)
+	maxLeverage, _ := strconv.ParseFloat(contract.DisplayMaxLeverage, 64)
+	info.MaxLeverage = int(maxLeverage)
+	return info, nil
+}
+
+// contractIDOf 交易对 -> contractId
+func (e *EdgeXAdapter) contractIDOf(symbol string) (string, bool) {
+	c, ok := e.contracts[strings.ToUpper(symbol)]
+	if !ok {
+		return "", false
+	}
+	return c.ContractID, true
+}
+
+// symbolOf contractId -> 交易对
+func (e *EdgeXAdapter) symbolOf(contractID string) string {
+	if name, ok := e.nameByID[contractID]; ok {
+		return name
+	}
+	return contractID
+}
+
+// PlaceOrder 下单（使用 REST API，附带 L2 签名）
+func (e *EdgeXAdapter) PlaceOrder(ctx context.Context, req *OrderRequest) (*Order, error) {
+	body, err := e.buildOrderBody(req, e.signer.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := e.client.DoRequest(ctx, http.MethodPost, "/api/v1/private/order/createOrder", nil, body)
+	if err != nil {
+		if isInsufficientMarginError(err) {
+			return nil, errs.Wrap(errs.ErrInsufficientMargin, "edgex", "", err)
+		}
+		if isClockSkewError(err) {
+			return nil, errs.Wrap(errs.ErrClockSkew, "edgex", "", err)
+		}
+		return nil, err
+	}
+
+	var result struct {
+		OrderID string `json:"orderId"`
+	}
+	if err := json.Unmarshal(resp.Data, &result); err != nil {
+		return nil, fmt.Errorf("解析下单响应失败: %w", err)
+	}
+	orderID, _ := strconv.ParseInt(result.OrderID, 10, 64)
+	if orderID == 0 {
+		return nil, fmt.Errorf("下单响应中orderId为空或无效: %s", result.OrderID)
+	}
+
+	return &Order{
+		OrderID:       orderID,
+		ClientOrderID: req.ClientOrderID,
+		Symbol:        req.Symbol,
+		Side:          req.Side,
+		Type:          req.Type,
+		Price:         req.Price,
+		Quantity:      req.Quantity,
+		Status:        OrderStatusNew,
+		CreatedAt:     time.Now(),
+	}, nil
+}
+
+// buildOrderBody 构造下单参数并计算 L2 签名
+func (e *EdgeXAdapter) buildOrderBody(req *OrderRequest, now time.Time) (map[string]interface{}, error) {
+	priceDecimals := e.priceDecimals
+	if req.PriceDecimals > 0 {
+		priceDecimals = req.PriceDecimals
+	}
+
+	size := roundToStep(req.Quantity, e.stepSize, e.quantityDecimals)
+	if size <= 0 || size < e.minOrderSize {
+		return nil, fmt.Errorf("下单数量 %.*f 小于最小下单数量 %.*f", e.quantityDecimals, size, e.quantityDecimals, e.minOrderSize)
+	}
+
+	price := alignToTickSize(req.Price, e.tickSize, priceDecimals)
+	orderType := "LIMIT"
+	timeInForce := "GOOD_TIL_CANCEL"
+	// L2 签名使用的价格：市价单使用带滑点的保护价
+	l2Price := price
+	if req.Type == OrderTypeMarket {
+		orderType = "MARKET"
+		timeInForce = "IMMEDIATE_OR_CANCEL"
+		if req.Side == SideBuy {
+			l2Price = alignToTickSize(req.Price*(1+marketOrderSlippage), e.tickSize, priceDecimals)
+		} else {
+			l2Price = alignToTickSize(req.Price*(1-marketOrderSlippage), e.tickSize, priceDecimals)
+		}
+		price = 0
+	} else if req.PostOnly {
+		timeInForce = "POST_ONLY" // Post Only - 只做 Maker
+	}
+	if l2Price <= 0 {
+		return nil, fmt.Errorf("无效的下单价格: %v", req.Price)
+	}
+
+	clientOrderID := req.ClientOrderID
+	if clientOrderID == "" {
+		clientOrderID = strconv.FormatInt(now.UnixNano(), 10)
+	}
+
+	expireTime := now.Add(orderExpireDuration).UnixMilli()
+	l2ExpireTime := expireTime + l2ExpireExtraDuration.Milliseconds()
+
+	value := l2Price * size
+	isBuy := req.Side == SideBuy
+	l2 := &l2Order{
+		IsBuy:             isBuy,
+		SyntheticAssetID:  e.syntheticAssetID,
+		CollateralAssetID: e.collateralAssetID,
+		SyntheticAmount:   toChainAmount(size, e.syntheticResolution, false),
+		// 买入时向上取整（付出的保证金不少于名义价值），卖出时向下取整
+		CollateralAmount: toChainAmount(value, e.collateralResolution, isBuy),
+		MaxFee:           toChainAmount(value*e.feeRate, e.collateralResolution, true),
+		Nonce:            nonceFromClientOrderID(clientOrderID),
+		ExpirationHours:  int64(math.Ceil(float64(l2ExpireTime) / float64(time.Hour.Milliseconds()))),
+	}
+	signature, err := e.signer.SignOrder(l2)
+	if err != nil {
+		return nil, fmt.Errorf("L2 订单签名失败: %w", err)
+	}
+
+	body := map[string]interface{}{
+		"accountId":     e.signer.GetAccountID(),
+		"contractId":    e.contract.ContractID,
+		"side":          string(req.Side),
+		"type":          orderType,
+		"timeInForce":   timeInForce,
+		"size":          strconv.FormatFloat(size, 'f', e.quantityDecimals, 64),
+		"price":         strconv.FormatFloat(price, 'f', priceDecimals, 64),
+		"reduceOnly":    req.ReduceOnly,
+		"clientOrderId": clientOrderID,
+		"expireTime":    strconv.FormatInt(expireTime, 10),
+		"l2Nonce":       strconv.FormatUint(uint64(l2.Nonce), 10),
+		"l2Value":       formatChainAmount(l2.CollateralAmount, e.collateralResolution),
+		"l2Size":        strconv.FormatFloat(size, 'f', e.quantityDecimals, 64),
+		"l2LimitFee":    formatChainAmount(l2.MaxFee, e.collateralResolution),
+		"l2ExpireTime":  strconv.FormatInt(l2ExpireTime, 10),
+		"l2Signature":   signature,
+	}
+	return body, nil
+}
+
+// BatchPlaceOrders 批量下单（edgeX 无批量下单接口，逐个下单）
+func (e *EdgeXAdapter) BatchPlaceOrders(ctx context.Context, orders []*OrderRequest) ([]*Order, bool) {
+	placedOrders := make([]*Order, 0, len(orders))
+	hasMarginError := false
+
+	for _, orderReq := range orders {
+		order, err := e.PlaceOrder(ctx, orderReq)
+		if err != nil {
+			logger.Warn("⚠️ [edgeX] 下单失败 %.*f %s: %v",
+				e.priceDecimals, orderReq.Price, orderReq.Side, err)
+			errs.Report(ctx, err)
+
+			if errors.Is(err, errs.ErrInsufficientMargin) {
+				hasMarginError = true
+			}
+			continue
+		}
+		placedOrders = append(placedOrders, order)
+	}
+
+	return placedOrders, hasMarginError
+}
+
+// CancelOrder 取消订单
+func (e *EdgeXAdapter) CancelOrder(ctx context.Context, symbol string, orderID int64) error {
+	return e.cancelByIDs(ctx, []int64{orderID})
+}
+
+// BatchCancelOrders 批量取消订单（每次最多50个）
+func (e *EdgeXAdapter) BatchCancelOrders(ctx context.Context, symbol string, orderIDs []int64) error {
+	batchSize := 50
+	for i := 0; i < len(orderIDs); i += batchSize {
+		end := i + batchSize
+		if end > len(orderIDs) {
+			end = len(orderIDs)
+		}
+		if err := e.cancelByIDs(ctx, orderIDs[i:end]); err != nil {
+			logger.Warn("⚠️ [edgeX] 批量撤单失败 (共%d个): %v", end-i, err)
+		}
+	}
+	return nil
+}
+
+// cancelByIDs 按订单ID撤单
+func (e *EdgeXAdapter) cancelByIDs(ctx context.Context, orderIDs []int64) error {
+	if len(orderIDs) == 0 {
+		return nil
+	}
+	ids := make([]string, len(orderIDs))
+	for i, id := range orderIDs {
+		ids[i] = strconv.FormatInt(id, 10)
+	}
+
+	body := map[string]interface{}{
+		"accountId":   e.signer.GetAccountID(),
+		"orderIdList": ids,
+	}
+	_, err := e.client.DoRequest(ctx, http.MethodPost, "/api/v1/private/order/cancelOrderById", nil, body)
+	if err != nil {
+		// 订单不存在不算错误
+		if isOrderNotFoundError(err) {
+			logger.Info("ℹ️ [edgeX] 订单 %v 已不存在，跳过取消", orderIDs)
+			return nil
+		}
+		return fmt.Errorf("取消订单失败: %w", err)
+	}
+
+	logger.Info("✅ [edgeX] 取消订单成功: %d 个", len(orderIDs))
+	return nil
+}
+
+// CancelAllOrders 撤销交易对的所有订单（edgeX 原生一键全撤，按合约过滤）
+func (e *EdgeXAdapter) CancelAllOrders(ctx context.Context, symbol string) error {
+	contractID := e.contract.ContractID
+	if id, ok := e.contractIDOf(symbol); ok {
+		contractID = id
+	}
+
+	body := map[string]interface{}{
+		"accountId":            e.signer.GetAccountID(),
+		"filterContractIdList": []string{contractID},
+	}
+	if _, err := e.client.DoRequest(ctx, http.MethodPost, "/api/v1/private/order/cancelAllOrder", nil, body); err != nil {
+		return fmt.Errorf("撤销所有订单失败: %w", err)
+	}
+
+	logger.Info("✅ [edgeX] 已撤销 %s 的所有订单", symbol)
+	return nil
+}
+
+// edgexOrder edgeX 订单结构（REST 与 WebSocket 共用）
+type edgexOrder struct {
+	ID            string `json:"id"`
+	ContractID    string `json:"contractId"`
+	ClientOrderID string `json:"clientOrderId"`
+	Side          string `json:"side"`
+	Type          string `json:"type"`
+	Price         string `json:"price"`
+	Size          string `json:"size"`
+	Status        string `json:"status"`
+	CumFillSize   string `json:"cumFillSize"`
+	CumFillValue  string `json:"cumFillValue"`
+	CreatedTime   string `json:"createdTime"`
+	UpdatedTime   string `json:"updatedTime"`
+}
+
+// toOrder 转换为通用订单结构
+func (e *EdgeXAdapter) toOrder(item *edgexOrder) *Order {
+	orderID, _ := strconv.ParseInt(item.ID, 10, 64)
+	price, _ := strconv.ParseFloat(item.Price, 64)
+	quantity, _ := strconv.ParseFloat(item.Size, 64)
+	executedQty, _ := strconv.ParseFloat(item.CumFillSize, 64)
+	fillValue, _ := strconv.ParseFloat(item.CumFillValue, 64)
+	createdTime, _ := strconv.ParseInt(item.CreatedTime, 10, 64)
+	updateTime, _ := strconv.ParseInt(item.UpdatedTime, 10, 64)
+
+	var avgPrice float64
+	if executedQty > 0 {
+		avgPrice = fillValue / executedQty
+	}
+
+	orderType := OrderTypeLimit
+	if item.Type == "MARKET" {
+		orderType = OrderTypeMarket
+	}
+
+	return &Order{
+		OrderID:       orderID,
+		ClientOrderID: item.ClientOrderID,
+		Symbol:        e.symbolOf(item.ContractID),
+		Side:          Side(item.Side),
+		Type:          orderType,
+		Price:         price,
+		Quantity:      quantity,
+		ExecutedQty:   executedQty,
+		AvgPrice:      avgPrice,
+		Status:        convertStatus(item.Status, executedQty),
+		CreatedAt:     time.UnixMilli(createdTime),
+		UpdateTime:    updateTime,
+	}
+}
+
+// GetOrder 查询订单
+func (e *EdgeXAdapter) GetOrder(ctx context.Context, symbol string, orderID int64) (*Order, error) {
+	query := url.Values{}
+	query.Set("accountId", e.signer.GetAccountID())
+	query.Set("orderIdList", strconv.FormatInt(orderID, 10))
+
+	resp, err := e.client.DoRequest(ctx, http.MethodGet, "/api/v1/private/order/getOrderById", query, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var dataList []edgexOrder
+	if err := json.Unmarshal(resp.Data, &dataList); err != nil {
+		return nil, fmt.Errorf("解析订单详情失败: %w", err)
+	}
+	if len(dataList) == 0 {
+		return nil, fmt.Errorf("订单不存在: %d", orderID)
+	}
+
+	return e.toOrder(&dataList[0]), nil
+}
+
+// GetOpenOrders 查询未完成订单（自动翻页）
+func (e *EdgeXAdapter) GetOpenOrders(ctx context.Context, symbol string) ([]*Order, error) {
+	contractID := e.contract.ContractID
+	if id, ok := e.contractIDOf(symbol); ok {
+		contractID = id
+	}
+
+	orders := make([]*Order, 0)
+	offset := ""
+	for page := 0; page < 20; page++ {
+		query := url.Values{}
+		query.Set("accountId", e.signer.GetAccountID())
+		query.Set("filterContractIdList", contractID)
+		query.Set("size", "100")
+		if offset != "" {
+			query.Set("offsetData", offset)
+		}
+
+		resp, err := e.client.DoRequest(ctx, http.MethodGet, "/api/v1/private/order/getActiveOrderPage", query, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var result struct {
+			DataList           []edgexOrder `json:"dataList"`
+			NextPageOffsetData string       `json:"nextPageOffsetData"`
+		}
+		if err := json.Unmarshal(resp.Data, &result); err != nil {
+			return nil, fmt.Errorf("解析订单列表失败: %w", err)
+		}
+
+		for i := range result.DataList {
+			orders = append(orders, e.toOrder(&result.DataList[i]))
+		}
+
+		if result.NextPageOffsetData == "" {
+			break
+		}
+		offset = result.NextPageOffsetData
+	}
+
+	return orders, nil
+}
+
+// GetAccount 获取账户信息
+func (e *EdgeXAdapter) GetAccount(ctx context.Context) (*Account, error) {
+	query := url.Values{}
+	query.Set("accountId", e.signer.GetAccountID())
+
+	resp, err := e.client.DoRequest(ctx, http.MethodGet, "/api/v1/private/account/getAccountAsset", query, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		CollateralAssetModelList []struct {
+			CoinID          string `json:"coinId"`
+			Amount          string `json:"amount"`
+			TotalEquity     string `json:"totalEquity"`
+			AvailableAmount string `json:"availableAmount"`
+		} `json:"collateralAssetModelList"`
+		PositionAssetList []edgexPosition `json:"positionAssetList"`
+	}
+	if err := json.Unmarshal(resp.Data, &result); err != nil {
+		return nil, fmt.Errorf("解析账户信息失败: %w", err)
+	}
+
+	account := &Account{AccountLeverage: e.maxLeverage}
+	for _, c := range result.CollateralAssetModelList {
+		amount, _ := strconv.ParseFloat(c.Amount, 64)
+		equity, _ := strconv.ParseFloat(c.TotalEquity, 64)
+		available, _ := strconv.ParseFloat(c.AvailableAmount, 64)
+		account.TotalWalletBalance += amount
+		account.TotalMarginBalance += equity
+		account.AvailableBalance += available
+	}
+
+	account.Positions = e.toPositions(result.PositionAssetList)
+	return account, nil
+}
+
+// edgexPosition edgeX 持仓资产（openSize 带符号，空仓为负）
+type edgexPosition struct {
+	ContractID    string `json:"contractId"`
+	OpenSize      string `json:"openSize"`
+	AvgEntryPrice string `json:"avgEntryPrice"`
+	UnrealizePnl  string `json:"unrealizePnl"`
+	PositionValue string `json:"positionValue"`
+	InitialMargin string `json:"initialMarginRequirement"`
+}
+
+// toPositions 转换持仓列表（跳过空持仓）
+func (e *EdgeXAdapter) toPositions(items []edgexPosition) []*Position {
+	positions := make([]*Position, 0, len(items))
+	for _, item := range items {
+		size, _ := strconv.ParseFloat(item.OpenSize, 64)
+		if size == 0 {
+			continue
+		}
+		entryPrice, _ := strconv.ParseFloat(item.AvgEntryPrice, 64)
+		pnl, _ := strconv.ParseFloat(item.UnrealizePnl, 64)
+		margin, _ := strconv.ParseFloat(item.InitialMargin, 64)
+		value, _ := strconv.ParseFloat(item.PositionValue, 64)
+
+		var markPrice float64
+		if size != 0 {
+			markPrice = math.Abs(value / size)
+		}
+
+		positions = append(positions, &Position{
+			Symbol:         e.symbolOf(item.ContractID),
+			Size:           size,
+			EntryPrice:     entryPrice,
+			MarkPrice:      markPrice,
+			UnrealizedPNL:  pnl,
+			Leverage:       e.maxLeverage,
+			MarginType:     "cross",
+			IsolatedMargin: margin,
+		})
+	}
+	return positions
+}
+
+// GetPositions 获取持仓信息
+func (e *EdgeXAdapter) GetPositions(ctx context.Context, symbol string) ([]*Position, error) {
+	account, err := e.GetAccount(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if symbol == "" {
+		return account.Positions, nil
+	}
+	positions := make([]*Position, 0, 1)
+	for _, pos := range account.Positions {
+		if pos.Symbol == strings.ToUpper(symbol) {
+			positions = append(positions, pos)
+		}
+	}
+	return positions, nil
+}
+
+// GetBalance 获取余额
+func (e *EdgeXAdapter) GetBalance(ctx context.Context, asset string) (float64, error) {
+	account, err := e.GetAccount(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return account.AvailableBalance, nil
+}
+
+// StartOrderStream 启动订单流（WebSocket 私有频道）
+func (e *EdgeXAdapter) StartOrderStream(ctx context.Context, callback func(interface{})) error {
+	logger.Debug("🔗 [edgeX] 启动订单流 WebSocket（私有频道）")
+
+	wrappedCallback := func(update OrderUpdate) {
+		genericUpdate := struct {
+			OrderID       int64
+			ClientOrderID string
+			Symbol        string
+			Side          string
+			Type          string
+			Status        string
+			Price         float64
+			Quantity      float64
+			ExecutedQty   float64
+			AvgPrice      float64
+			UpdateTime    int64
+		}{
+			OrderID:       update.OrderID,
+			ClientOrderID: update.ClientOrderID,
+			Symbol:        update.Symbol,
+			Side:          string(update.Side),
+			Type:          string(update.Type),
+			Status:        string(update.Status),
+			Price:         update.Price,
+			Quantity:      update.Quantity,
+			ExecutedQty:   update.ExecutedQty,
+			AvgPrice:      update.AvgPrice,
+			UpdateTime:    update.UpdateTime,
+		}
+		callback(genericUpdate)
+	}
+
+	return e.wsManager.StartPrivate(ctx, wrappedCallback)
+}
+
+// StopOrderStream 停止订单流
+func (e *EdgeXAdapter) StopOrderStream() error {
+	e.wsManager.Stop()
+	return nil
+}
+
+// GetLatestPrice 获取最新价格（优先 WebSocket 缓存，未就绪时使用 REST）
+func (e *EdgeXAdapter) GetLatestPrice(ctx context.Context, symbol string) (float64, error) {
+	if price := e.wsManager.GetLatestPrice(); price > 0 {
+		return price, nil
+	}
+
+	query := url.Values{}
+	query.Set("contractId", e.contract.ContractID)
+	resp, err := e.client.DoRequest(ctx, http.MethodGet, "/api/v1/public/quote/getTicker", query, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var dataList []struct {
+		LastPrice string `json:"lastPrice"`
+	}
+	if err := json.Unmarshal(resp.Data, &dataList); err != nil {
+		return 0, fmt.Errorf("解析行情失败: %w", err)
+	}
+	if len(dataList) == 0 {
+		return 0, fmt.Errorf("未获取到 %s 的行情", e.symbol)
+	}
+
+	return strconv.ParseFloat(dataList[0].LastPrice, 64)
+}
+
+// StartPriceStream 启动价格流（WebSocket 公共频道 ticker）
+func (e *EdgeXAdapter) StartPriceStream(ctx context.Context, symbol string, callback func(price float64)) error {
+	return e.wsManager.StartPublic(ctx, e.contract.ContractID, callback)
+}
+
+// StartKlineStream 启动K线流（WebSocket 公共频道 kline）
+func (e *EdgeXAdapter) StartKlineStream(ctx context.Context, symbols []string, interval string, callback func(candle interface{})) error {
+	return e.klineWSManager.Start(ctx, symbols, interval, callback)
+}
+
+// RegisterKlineCallback 注册K线回调函数（支持多个组件共享K线流）
+func (e *EdgeXAdapter) RegisterKlineCallback(componentName string, callback func(candle interface{})) error {
+	return e.klineWSManager.RegisterCallback(componentName, callback)
+}
+
+// StopKlineStream 停止K线流
+func (e *EdgeXAdapter) StopKlineStream() error {
+	e.klineWSManager.Stop()
+	return nil
+}
+
+// ForceReconnectKlineStream 强制重新连接K线流
+func (e *EdgeXAdapter) ForceReconnectKlineStream() error {
+	return e.klineWSManager.ForceReconnect()
+}
+
+// GetHistoricalKlines 获取历史K线数据
+func (e *EdgeXAdapter) GetHistoricalKlines(ctx context.Context, symbol string, interval string, limit int) ([]*Candle, error) {
+	contractID, ok := e.contractIDOf(symbol)
+	if !ok {
+		return nil, fmt.Errorf("未找到合约: %s", symbol)
+	}
+	if limit > 1000 {
+		limit = 1000
+	}
+
+	query := url.Values{}
+	query.Set("contractId", contractID)
+	query.Set("priceType", "LAST_PRICE")
+	query.Set("klineType", convertToEdgeXKlineType(interval))
+	query.Set("size", strconv.Itoa(limit))
+
+	resp, err := e.client.DoRequest(ctx, http.MethodGet, "/api/v1/public/quote/getKline", query, nil)
+	if err != nil {
+		return nil, fmt.Errorf("获取历史K线失败: %w", err)
+	}
+
+	var result struct {
+		DataList []edgexKline `json:"dataList"`
+	}
+	if err := json.Unmarshal(resp.Data, &result); err != nil {
+		return nil, fmt.Errorf("解析K线数据失败: %w", err)
+	}
+
+	// edgeX 返回的K线是倒序的（最新的在前），需要反转
+	candles := make([]*Candle, 0, len(result.DataList))
+	for i := len(result.DataList) - 1; i >= 0; i-- {
+		candle := result.DataList[i].toCandle(strings.ToUpper(symbol))
+		candle.IsClosed = true
+		candles = append(candles, candle)
+	}
+
+	return candles, nil
+}
+
+// GetKlinesBetween 获取开盘时间在 [start, end]（毫秒）内的K线，单次请求最多 1000 根，按时间升序
+func (e *EdgeXAdapter) GetKlinesBetween(ctx context.Context, symbol string, interval string, start, end int64, limit int) ([]*Candle, error) {
+	contractID, ok := e.contractIDOf(symbol)
+	if !ok {
+		return nil, fmt.Errorf("未找到合约: %s", symbol)
+	}
+	if limit <= 0 || limit > 1000 {
+		limit = 1000
+	}
+
+	query := url.Values{}
+	query.Set("contractId", contractID)
+	query.Set("priceType", "LAST_PRICE")
+	query.Set("klineType", convertToEdgeXKlineType(interval))
+	query.Set("size", strconv.Itoa(limit))
+	query.Set("filterBeginKlineTimeInclusive", strconv.FormatInt(start, 10))
+	query.Set("filterEndKlineTimeExclusive", strconv.FormatInt(end+1, 10))
+
+	resp, err := e.client.DoRequest(ctx, http.MethodGet, "/api/v1/public/quote/getKline", query, nil)
+	if err != nil {
+		return nil, fmt.Errorf("获取历史K线失败: %w", err)
+	}
+
+	var result struct {
+		DataList []edgexKline `json:"dataList"`
+	}
+	if err := json.Unmarshal(resp.Data, &result); err != nil {
+		return nil, fmt.Errorf("解析K线数据失败: %w", err)
+	}
+
+	// edgeX 返回的K线是倒序的（最新的在前），需要反转
+	candles := make([]*Candle, 0, len(result.DataList))
+	for i := len(result.DataList) - 1; i >= 0; i-- {
+		candle := result.DataList[i].toCandle(strings.ToUpper(symbol))
+		candle.IsClosed = true
+		candles = append(candles, candle)
+	}
+	return candles, nil
+}
+
+// GetPriceDecimals 获取价格精度（小数位数）
+func (e *EdgeXAdapter) GetPriceDecimals() int {
+	return e.priceDecimals
+}
+
+// GetQuantityDecimals 获取数量精度（小数位数）
+func (e *EdgeXAdapter) GetQuantityDecimals() int {
+	return e.quantityDecimals
+}
+
+// GetBaseAsset 获取基础资产（交易币种）
+func (e *EdgeXAdapter) GetBaseAsset() string {
+	return e.baseAsset
+}
+
+// GetQuoteAsset 获取计价资产（保证金币种）
+func (e *EdgeXAdapter) GetQuoteAsset() string {
+	return e.quoteAsset
+}
+
+// isInsufficientMarginError 判断是否为保证金不足错误
+func isInsufficientMarginError(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		code := strings.ToUpper(apiErr.Code)
+		return strings.Contains(code, "INSUFFICIENT") || strings.Contains(code, "MARGIN_NOT_ENOUGH")
+	}
+	return false
+}
+
+// isClockSkewError 判断是否为请求时间戳不同步错误
+func isClockSkewError(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && strings.Contains(strings.ToUpper(apiErr.Code), "TIMESTAMP")
+}
+
+// isOrderNotFoundError 判断是否为订单不存在错误
+func isOrderNotFoundError(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		code := strings.ToUpper(apiErr.Code)
+		return strings.Contains(code, "NOT_EXIST") || strings.Contains(code, "NOT_FOUND")
+	}
+	return false
+}
+
+// convertStatus 转换订单状态
+// edgeX 部分成交仍为 OPEN，需要结合成交数量判断
+func convertStatus(status string, executedQty float64) OrderStatus {
+	switch status {
+	case "PENDING", "OPEN", "UNTRIGGERED":
+		if executedQty > 0 {
+			return OrderStatusPartiallyFilled
+		}
+		return OrderStatusNew
+	case "FILLED":
+		return OrderStatusFilled
+	case "CANCELING", "CANCELED":
+		return OrderStatusCanceled
+	default:
+		return OrderStatus(status)
+	}
+}
+
+// convertToEdgeXKlineType 将标准K线周期转换为 edgeX 格式
+// 输入: 1m, 5m, 15m, 30m, 1h, 2h, 4h, 6h, 8h, 12h, 1d, 1w, 1M
+// 输出: MINUTE_1, MINUTE_5, ..., HOUR_1, ..., DAY_1, WEEK_1, MONTH_1
+func convertToEdgeXKlineType(interval string) string {
+	if interval == "" {
+		return "MINUTE_1"
+	}
+	unit := interval[len(interval)-1:]
+	n := interval[:len(interval)-1]
+	switch unit {
+	case "m":
+		return "MINUTE_" + n
+	case "h":
+		return "HOUR_" + n
+	case "d":
+		return "DAY_" + n
+	case "w":
+		return "WEEK_" + n
+	case "M":
+		return "MONTH_" + n
+	default:
+		return "MINUTE_1"
+	}
+}
+
+// parseHexBig 解析十六进制大整数（可带 0x 前缀）
+func parseHexBig(s string) (*big.Int, error) {
+	n, ok := new(big.Int).SetString(strings.TrimPrefix(strings.ToLower(s), "0x"), 16)
+	if !ok {
+		return nil, fmt.Errorf("无效的十六进制数: %q", s)
+	}
+	return n, nil
+}
+
+// parseResolution 解析资产精度（可以是十进制或 0x 十六进制）
+func parseResolution(s string) float64 {
+	if strings.HasPrefix(strings.ToLower(s), "0x") {
+		if n, err := parseHexBig(s); err == nil {
+			f, _ := new(big.Float).SetInt(n).Float64()
+			return f
+		}
+		return 0
+	}
+	f, _ := strconv.ParseFloat(s, 64)
+	return f
+}
+
+// toChainAmount 将数量换算为链上整数单位（roundUp=true 向上取整，否则向下取整）
+func toChainAmount(amount, resolution float64, roundUp bool) *big.Int {
+	scaled := amount * resolution
+	// 消除浮点误差，例如 0.3*1e6 = 299999.99999999994
+	rounded := math.Round(scaled)
+	if math.Abs(scaled-rounded) < 1e-6 {
+		scaled = rounded
+	} else if roundUp {
+		scaled = math.Ceil(scaled)
+	} else {
+		scaled = math.Floor(scaled)
+	}
+	result, _ := new(big.Float).SetFloat64(scaled).Int(nil)
+	return result
+}
+
+// formatChainAmount 将链上整数金额按精度转回十进制字符串
+func formatChainAmount(amount *big.Int, resolution float64) string {
+	decimals := int(math.Round(math.Log10(resolution)))
+	f, _ := new(big.Float).SetInt(amount).Float64()
+	return strconv.FormatFloat(f/resolution, 'f', decimals, 64)
+}
+
+// countDecimalPlaces 计算步长字符串的小数位数（如 "0.010" -> 2）
+func countDecimalPlaces(step string) int {
+	if !strings.Contains(step, ".") {
+		return 0
+	}
+	step = strings.TrimRight(step, "0")
+	return len(step) - strings.Index(step, ".") - 1
+}
+
+// alignToTickSize 将价格对齐到价格步长
+func alignToTickSize(price, tickSize float64, decimals int) float64 {
+	if tickSize <= 0 {
+		return price
+	}
+	aligned := math.Round(price/tickSize) * tickSize
+	multiplier := math.Pow(10, float64(decimals))
+	return math.Round(aligned*multiplier) / multiplier
+}
+
+// roundToStep 将数量向下对齐到数量步长
+func roundToStep(quantity, stepSize float64, decimals int) float64 {
+	if stepSize > 0 {
+		quantity = math.Floor(quantity/stepSize+1e-9) * stepSize
+	}
+	multiplier := math.Pow(10, float64(decimals))
+	return math.Round(quantity*multiplier) / multiplier
+}
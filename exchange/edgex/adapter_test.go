@@ -0,0 +1,398 @@
+package edgex
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"opensqt/exchange/errs"
+	"opensqt/exchange/venue/venuetest"
+
+	"github.com/gorilla/websocket"
+	"golang.org/x/crypto/sha3"
+)
+
+// ===== 本地模拟 edgeX 服务 =====
+
+const (
+	testAccountID  = "542063734238215222"
+	testPrivateKey = "0x3c1e9550e66958296d11b60f8e8e7a7ad990d07fa65d5f7652c4a6c87d4e3cc"
+	testPublicKey  = "77a3b314db07c45076d11f62b6f9e748a39790441823307743cf00d6597ea43"
+)
+
+// verifyRequestSignature 按 edgeX 规则校验请求签名
+func verifyRequestSignature(timestamp, method, path, params, signature string) bool {
+	if len(signature) != 192 {
+		return false
+	}
+	hasher := sha3.NewLegacyKeccak256()
+	hasher.Write([]byte(timestamp + method + path + params))
+	msgHash := new(big.Int).SetBytes(hasher.Sum(nil))
+	msgHash.Mod(msgHash, starkN)
+	return StarkVerify(msgHash, hexToBig(signature[:64]), hexToBig(signature[64:128]), hexToBig(testPublicKey))
+}
+
+// newMockServer 模拟 edgeX REST 接口，校验 Stark 签名（timestamp + method + path + 排序后的参数）并记录请求
+func newMockServer(t *testing.T) *venuetest.Server {
+	mock := venuetest.NewServer(t, func(r *http.Request, body []byte) bool {
+		signParams := r.URL.RawQuery
+		if r.Method == http.MethodPost {
+			var fields map[string]interface{}
+			json.Unmarshal(body, &fields)
+			signParams = buildSignParams(fields)
+		}
+		return verifyRequestSignature(r.Header.Get("X-edgeX-Api-Timestamp"), r.Method, r.URL.Path, signParams, r.Header.Get("X-edgeX-Api-Signature"))
+	}, `{"code":"INVALID_SIGNATURE","msg":"invalid signature"}`)
+
+	mock.HandlePublic("/api/v1/public/meta/getServerTime", func(w http.ResponseWriter, payload string) {
+		writeData(w, fmt.Sprintf(`{"timeMillis":"%d"}`, time.Now().UnixMilli()))
+	})
+	mock.Handle("/api/v1/public/meta/getMetaData", func(w http.ResponseWriter, payload string) {
+		writeData(w, `{
+			"global":{"starkExCollateralCoin":{"coinId":"1000","coinName":"USDT",
+				"starkExAssetId":"0x2ce625e94458d39dd0bf3b45a843544dd4a14b8169045a3a3d15aa564b936c5","starkExResolution":"0xf4240"}},
+			"coinList":[{"coinId":"1000","coinName":"USDT"},{"coinId":"1002","coinName":"ETH"}],
+			"contractList":[{"contractId":"10000002","contractName":"ETHUSDT","baseCoinId":"1002","quoteCoinId":"1000",
+				"tickSize":"0.01","stepSize":"0.001","minOrderSize":"0.01","defaultTakerFeeRate":"0.00038",
+				"displayMaxLeverage":"100","starkExSyntheticAssetId":"0x4554482d3900000000000000000000","starkExResolution":"0x3b9aca00"}]}`)
+	})
+	return mock
+}
+
+func writeData(w http.ResponseWriter, data string) {
+	w.Write([]byte(`{"code":"SUCCESS","msg":null,"data":` + data + `}`))
+}
+
+func newTestAdapter(t *testing.T, baseURL string, extra map[string]string) *EdgeXAdapter {
+	cfg := map[string]string{
+		"api_key":    testAccountID,
+		"secret_key": testPrivateKey,
+		"base_url":   baseURL,
+	}
+	for k, v := range extra {
+		cfg[k] = v
+	}
+	adapter, err := NewEdgeXAdapter(cfg, "ETHUSDT")
+	if err != nil {
+		t.Fatalf("创建适配器失败: %v", err)
+	}
+	return adapter
+}
+
+// ===== REST =====
+
+func TestNewEdgeXAdapterLoadsMetaData(t *testing.T) {
+	mock := newMockServer(t)
+	adapter := newTestAdapter(t, mock.URL, nil)
+
+	if adapter.GetPriceDecimals() != 2 || adapter.GetQuantityDecimals() != 3 {
+		t.Errorf("精度错误: price=%d, qty=%d", adapter.GetPriceDecimals(), adapter.GetQuantityDecimals())
+	}
+	if adapter.GetBaseAsset() != "ETH" || adapter.GetQuoteAsset() != "USDT" {
+		t.Errorf("币种错误: base=%s, quote=%s", adapter.GetBaseAsset(), adapter.GetQuoteAsset())
+	}
+	if adapter.collateralResolution != 1e6 || adapter.syntheticResolution != 1e9 {
+		t.Errorf("资产精度错误: collateral=%v, synthetic=%v", adapter.collateralResolution, adapter.syntheticResolution)
+	}
+	if adapter.signer.GetPublicKey() != "0x"+testPublicKey {
+		t.Errorf("公钥错误: %s", adapter.signer.GetPublicKey())
+	}
+}
+
+func TestPlaceOrderSignsL2Order(t *testing.T) {
+	mock := newMockServer(t)
+	mock.Handle("/api/v1/private/order/createOrder", func(w http.ResponseWriter, payload string) {
+		writeData(w, `{"orderId":"564123456789012345"}`)
+	})
+	adapter := newTestAdapter(t, mock.URL, nil)
+
+	order, err := adapter.PlaceOrder(context.Background(), &OrderRequest{
+		Symbol:        "ETHUSDT",
+		Side:          SideBuy,
+		Type:          OrderTypeLimit,
+		Quantity:      0.3,
+		Price:         3000.123,
+		PostOnly:      true,
+		ClientOrderID: "300012_B_1702468800001",
+	})
+	if err != nil {
+		t.Fatalf("下单失败: %v", err)
+	}
+	if order.OrderID != 564123456789012345 || order.ClientOrderID != "300012_B_1702468800001" {
+		t.Errorf("订单信息错误: %+v", order)
+	}
+
+	var body map[string]interface{}
+	json.Unmarshal([]byte(mock.LastRequest("/api/v1/private/order/createOrder")), &body)
+	if body["accountId"] != testAccountID || body["contractId"] != "10000002" || body["timeInForce"] != "POST_ONLY" {
+		t.Errorf("下单参数错误: %v", body)
+	}
+	// 3000.12 * 0.3 = 900.036 USDT，手续费上限 900.036 * 0.00038 = 0.34201368 -> 向上取整到 0.342014
+	if body["price"] != "3000.12" || body["size"] != "0.300" || body["l2Value"] != "900.036000" || body["l2LimitFee"] != "0.342014" {
+		t.Errorf("L2 金额错误: %v", body)
+	}
+
+	// 用相同参数重新计算订单哈希并验证签名
+	nonce, _ := strconv.ParseUint(body["l2Nonce"].(string), 10, 32)
+	l2ExpireTime, _ := strconv.ParseInt(body["l2ExpireTime"].(string), 10, 64)
+	if uint32(nonce) != nonceFromClientOrderID("300012_B_1702468800001") {
+		t.Errorf("l2Nonce 错误: %v", body["l2Nonce"])
+	}
+	hash, err := adapter.signer.HashOrder(&l2Order{
+		IsBuy:             true,
+		SyntheticAssetID:  adapter.syntheticAssetID,
+		CollateralAssetID: adapter.collateralAssetID,
+		SyntheticAmount:   big.NewInt(300000000),
+		CollateralAmount:  big.NewInt(900036000),
+		MaxFee:            big.NewInt(342014),
+		Nonce:             uint32(nonce),
+		ExpirationHours:   (l2ExpireTime + 3600000 - 1) / 3600000,
+	})
+	if err != nil {
+		t.Fatalf("计算订单哈希失败: %v", err)
+	}
+	signature := body["l2Signature"].(string)
+	if !StarkVerify(hash, hexToBig(signature[:64]), hexToBig(signature[64:128]), hexToBig(testPublicKey)) {
+		t.Error("L2 订单签名验证失败")
+	}
+}
+
+// edgeX 错误码为字符串：含 INSUFFICIENT / MARGIN_NOT_ENOUGH 为保证金不足，含 TIMESTAMP 为时钟偏差；
+// 撤单时 NOT_EXIST / NOT_FOUND 视为订单已结束
+func TestErrorCodes(t *testing.T) {
+	mock := newMockServer(t)
+	adapter := newTestAdapter(t, mock.URL, nil)
+	ctx := context.Background()
+
+	cases := []struct {
+		code string
+		want error
+	}{
+		{"INSUFFICIENT_MARGIN", errs.ErrInsufficientMargin},
+		{"ACCOUNT_MARGIN_NOT_ENOUGH", errs.ErrInsufficientMargin},
+		{"INVALID_TIMESTAMP", errs.ErrClockSkew},
+	}
+	for _, c := range cases {
+		mock.Handle("/api/v1/private/order/createOrder", venuetest.Reply(`{"code":"`+c.code+`","msg":""}`))
+		_, err := adapter.PlaceOrder(ctx, &OrderRequest{Symbol: "ETHUSDT", Side: SideBuy, Type: OrderTypeLimit, Quantity: 0.1, Price: 3000})
+		if !errors.Is(err, c.want) {
+			t.Errorf("code=%s: err = %v, want %v", c.code, err, c.want)
+		}
+	}
+
+	for _, code := range []string{"ORDER_NOT_EXIST", "ORDER_NOT_FOUND"} {
+		mock.Handle("/api/v1/private/order/cancelOrderById", venuetest.Reply(`{"code":"`+code+`","msg":""}`))
+		if err := adapter.CancelOrder(ctx, "ETHUSDT", 11); err != nil {
+			t.Errorf("code=%s 撤单应视为成功: %v", code, err)
+		}
+	}
+	if got := mock.LastRequest("/api/v1/private/order/cancelOrderById"); !strings.Contains(got, `"orderIdList":["11"]`) {
+		t.Errorf("撤单参数错误: %s", got)
+	}
+}
+
+func TestGetOpenOrdersPaginates(t *testing.T) {
+	mock := newMockServer(t)
+	mock.Handle("/api/v1/private/order/getActiveOrderPage", func(w http.ResponseWriter, payload string) {
+		if !strings.Contains(payload, "offsetData=") {
+			writeData(w, `{"dataList":[{"id":"1","contractId":"10000002","clientOrderId":"c1","side":"BUY","type":"LIMIT",
+				"price":"3000","size":"0.1","status":"OPEN","cumFillSize":"0.04","cumFillValue":"120"}],"nextPageOffsetData":"next"}`)
+			return
+		}
+		writeData(w, `{"dataList":[{"id":"2","contractId":"10000002","side":"SELL","type":"LIMIT",
+			"price":"3100","size":"0.1","status":"OPEN","cumFillSize":"0"}],"nextPageOffsetData":""}`)
+	})
+	adapter := newTestAdapter(t, mock.URL, nil)
+
+	orders, err := adapter.GetOpenOrders(context.Background(), "ETHUSDT")
+	if err != nil {
+		t.Fatalf("查询挂单失败: %v", err)
+	}
+	if len(orders) != 2 {
+		t.Fatalf("订单数量错误: %d", len(orders))
+	}
+	if orders[0].Status != OrderStatusPartiallyFilled || orders[0].AvgPrice != 3000 || orders[0].Symbol != "ETHUSDT" {
+		t.Errorf("部分成交订单转换错误: %+v", orders[0])
+	}
+	if orders[1].Status != OrderStatusNew || orders[1].Side != SideSell {
+		t.Errorf("挂单转换错误: %+v", orders[1])
+	}
+}
+
+func TestGetAccountAndPositions(t *testing.T) {
+	mock := newMockServer(t)
+	mock.Handle("/api/v1/private/account/getAccountAsset", func(w http.ResponseWriter, payload string) {
+		writeData(w, `{"collateralAssetModelList":[{"coinId":"1000","amount":"1000","totalEquity":"1012.5","availableAmount":"800"}],
+			"positionAssetList":[{"contractId":"10000002","openSize":"-0.5","avgEntryPrice":"3000","unrealizePnl":"12.5","positionValue":"-1475"}]}`)
+	})
+	adapter := newTestAdapter(t, mock.URL, nil)
+
+	account, err := adapter.GetAccount(context.Background())
+	if err != nil {
+		t.Fatalf("获取账户失败: %v", err)
+	}
+	if account.TotalWalletBalance != 1000 || account.TotalMarginBalance != 1012.5 || account.AvailableBalance != 800 {
+		t.Errorf("账户余额错误: %+v", account)
+	}
+	if len(account.Positions) != 1 || account.Positions[0].Size != -0.5 || account.Positions[0].MarkPrice != 2950 {
+		t.Errorf("持仓错误: %+v", account.Positions)
+	}
+}
+
+func TestGetHistoricalKlinesReversesOrder(t *testing.T) {
+	mock := newMockServer(t)
+	mock.Handle("/api/v1/public/quote/getKline", func(w http.ResponseWriter, payload string) {
+		if !strings.Contains(payload, "klineType=HOUR_1") {
+			t.Errorf("K线周期参数错误: %s", payload)
+		}
+		writeData(w, `{"dataList":[
+			{"contractId":"10000002","klineTime":"7200000","open":"2","high":"3","low":"1","close":"2.5","size":"10"},
+			{"contractId":"10000002","klineTime":"3600000","open":"1","high":"2","low":"0.5","close":"2","size":"5"}]}`)
+	})
+	adapter := newTestAdapter(t, mock.URL, nil)
+
+	candles, err := adapter.GetHistoricalKlines(context.Background(), "ETHUSDT", "1h", 2)
+	if err != nil {
+		t.Fatalf("获取历史K线失败: %v", err)
+	}
+	if len(candles) != 2 || candles[0].Timestamp != 3600000 || candles[1].Close != 2.5 || !candles[1].IsClosed {
+		t.Errorf("K线转换错误: %+v %+v", candles[0], candles[1])
+	}
+}
+
+// ===== WebSocket =====
+
+func TestPrivateStreamAuthAndOrderUpdate(t *testing.T) {
+	mock := newMockServer(t)
+
+	upgrader := websocket.Upgrader{}
+	wsServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("accountId") != testAccountID ||
+			!verifyRequestSignature(r.Header.Get("X-edgeX-Api-Timestamp"), http.MethodGet, r.URL.Path, r.URL.RawQuery, r.Header.Get("X-edgeX-Api-Signature")) {
+			t.Errorf("私有频道鉴权失败: %s", r.URL.String())
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		// 服务端心跳，客户端需回复 pong
+		conn.WriteJSON(map[string]string{"type": "ping", "time": "1700000000000"})
+		var pong map[string]string
+		if err := conn.ReadJSON(&pong); err != nil || pong["type"] != "pong" || pong["time"] != "1700000000000" {
+			t.Errorf("pong 回复错误: %v, err=%v", pong, err)
+		}
+
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"trade-event","content":{"event":"ORDER_UPDATE","data":{"order":[
+			{"id":"555","contractId":"10000002","clientOrderId":"300000_B_1702468800001","side":"BUY","type":"LIMIT",
+			 "price":"3000","size":"0.1","status":"FILLED","cumFillSize":"0.1","cumFillValue":"300","updatedTime":"1702468800500"}]}}}`))
+
+		// 保持连接直到客户端关闭
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	defer wsServer.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(wsServer.URL, "http") + "/api/v1/private/ws"
+	adapter := newTestAdapter(t, mock.URL, map[string]string{"ws_private_url": wsURL})
+
+	updates := make(chan interface{}, 1)
+	if err := adapter.StartOrderStream(context.Background(), func(u interface{}) { updates <- u }); err != nil {
+		t.Fatalf("启动订单流失败: %v", err)
+	}
+	defer adapter.StopOrderStream()
+
+	select {
+	case u := <-updates:
+		raw, _ := json.Marshal(u)
+		var update OrderUpdate
+		json.Unmarshal(raw, &update)
+		if update.OrderID != 555 || update.ClientOrderID != "300000_B_1702468800001" || update.Status != OrderStatusFilled ||
+			update.ExecutedQty != 0.1 || update.AvgPrice != 3000 || update.Symbol != "ETHUSDT" {
+			t.Errorf("订单推送转换错误: %+v", update)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("未收到订单推送")
+	}
+}
+
+func TestKlineStreamEmitsClosedCandle(t *testing.T) {
+	mock := newMockServer(t)
+
+	upgrader := websocket.Upgrader{}
+	wsServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var sub map[string]string
+		if err := conn.ReadJSON(&sub); err != nil || sub["channel"] != "kline.LAST_PRICE.10000002.MINUTE_1" {
+			t.Errorf("订阅消息错误: %v, err=%v", sub, err)
+			return
+		}
+		push := func(klineTime, close string) {
+			conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"quote-event","channel":"kline.LAST_PRICE.10000002.MINUTE_1",
+				"content":{"dataType":"changed","data":[{"contractId":"10000002","klineTime":"`+klineTime+`",
+				"open":"1","high":"2","low":"0.5","close":"`+close+`","size":"3"}]}}`))
+		}
+		push("60000", "1.5")
+		push("60000", "1.8")
+		push("120000", "1.9")
+
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	defer wsServer.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(wsServer.URL, "http")
+	adapter := newTestAdapter(t, mock.URL, map[string]string{"ws_public_url": wsURL})
+
+	candles := make(chan *Candle, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := adapter.StartKlineStream(ctx, []string{"ETHUSDT"}, "1m", func(c interface{}) { candles <- c.(*Candle) }); err != nil {
+		t.Fatalf("启动K线流失败: %v", err)
+	}
+	defer adapter.StopKlineStream()
+
+	var got []*Candle
+	timeout := time.After(5 * time.Second)
+	for len(got) < 4 {
+		select {
+		case c := <-candles:
+			got = append(got, c)
+		case <-timeout:
+			t.Fatalf("K线推送数量不足: %d", len(got))
+		}
+	}
+
+	// 第三条推送开启新周期，应先补发上一根已完结K线（收盘价为最后一次更新的 1.8）
+	if got[0].IsClosed || got[1].IsClosed {
+		t.Error("同一周期内的更新不应标记为完结")
+	}
+	if !got[2].IsClosed || got[2].Timestamp != 60000 || got[2].Close != 1.8 || got[2].Symbol != "ETHUSDT" {
+		t.Errorf("完结K线错误: %+v", got[2])
+	}
+	if got[3].IsClosed || got[3].Timestamp != 120000 {
+		t.Errorf("新周期K线错误: %+v", got[3])
+	}
+}
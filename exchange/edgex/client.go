@@ -0,0 +1,173 @@
+package edgex
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	EdgeXBaseURL        = "https://pro.edgex.exchange"
+	EdgeXTestnetBaseURL = "https://testnet.edgex.exchange"
+)
+
+// Client edgeX HTTP 客户端
+type Client struct {
+	httpClient *http.Client
+	signer     *Signer
+	baseURL    string
+}
+
+// NewClient 创建 edgeX 客户端
+func NewClient(signer *Signer) *Client {
+	return &Client{
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		signer:     signer,
+		baseURL:    EdgeXBaseURL,
+	}
+}
+
+// EdgeXResponse edgeX API 通用响应结构
+type EdgeXResponse struct {
+	Code string          `json:"code"`
+	Msg  string          `json:"msg"`
+	Data json.RawMessage `json:"data"`
+}
+
+// APIError edgeX API 业务错误（code 为字符串错误码，如 INSUFFICIENT_MARGIN）
+type APIError struct {
+	Code string
+	Msg  string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("edgeX API 错误: code=%s, msg=%s", e.Code, e.Msg)
+}
+
+// DoRequest 发送 HTTP 请求（带 Stark 签名）
+// GET 请求参数放在 query 中，POST 请求参数放在 body 中
+func (c *Client) DoRequest(ctx context.Context, method, path string, query url.Values, body map[string]interface{}) (*EdgeXResponse, error) {
+	var bodyBytes []byte
+	var err error
+	var signParams string
+
+	reqURL := c.baseURL + path
+	if method == http.MethodGet {
+		if len(query) > 0 {
+			signParams = query.Encode() // Encode 按 key 排序
+			reqURL += "?" + signParams
+		}
+	} else if body != nil {
+		bodyBytes, err = json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("序列化请求体失败: %w", err)
+		}
+		signParams = buildSignParams(body)
+	}
+
+	timestamp := c.signer.GetTimestamp()
+	signature, err := c.signer.SignRequest(timestamp, method, path, signParams)
+	if err != nil {
+		return nil, fmt.Errorf("签名失败: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, bytes.NewBuffer(bodyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+
+	// 添加 edgeX 必需的请求头
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-edgeX-Api-Timestamp", timestamp)
+	req.Header.Set("X-edgeX-Api-Signature", signature)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取响应失败: %w", err)
+	}
+
+	var edgexResp EdgeXResponse
+	if err := json.Unmarshal(respBody, &edgexResp); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w, HTTP状态: %d, 响应体: %s", err, resp.StatusCode, string(respBody))
+	}
+
+	if edgexResp.Code != "SUCCESS" {
+		return nil, &APIError{Code: edgexResp.Code, Msg: edgexResp.Msg}
+	}
+
+	return &edgexResp, nil
+}
+
+// ServerTime 查询服务器时间（公共接口，不签名，本地时钟偏差过大时也能对时）
+func (c *Client) ServerTime(ctx context.Context) (time.Time, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/api/v1/public/meta/getServerTime", nil)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("创建请求失败: %w", err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Code string `json:"code"`
+		Msg  string `json:"msg"`
+		Data struct {
+			TimeMillis string `json:"timeMillis"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return time.Time{}, fmt.Errorf("解析响应失败: %w", err)
+	}
+	if result.Code != "SUCCESS" {
+		return time.Time{}, &APIError{Code: result.Code, Msg: result.Msg}
+	}
+	ms, err := strconv.ParseInt(result.Data.TimeMillis, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("解析服务器时间失败: %w", err)
+	}
+	return time.UnixMilli(ms), nil
+}
+
+// buildSignParams 将请求体展开为按 key 排序的 k=v&k=v（数组以逗号连接）
+func buildSignParams(body map[string]interface{}) string {
+	keys := make([]string, 0, len(body))
+	for k := range body {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		var value string
+		switch v := body[k].(type) {
+		case []string:
+			value = strings.Join(v, ",")
+		case []interface{}:
+			items := make([]string, len(v))
+			for i, item := range v {
+				items[i] = fmt.Sprint(item)
+			}
+			value = strings.Join(items, ",")
+		default:
+			value = fmt.Sprint(v)
+		}
+		parts = append(parts, k+"="+value)
+	}
+	return strings.Join(parts, "&")
+}
@@ -0,0 +1,353 @@
+package edgex
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"opensqt/logger"
+
+	"github.com/gorilla/websocket"
+)
+
+// edgexKline edgeX K线结构（REST 与 WebSocket 共用）
+type edgexKline struct {
+	ContractID string `json:"contractId"`
+	KlineTime  string `json:"klineTime"`
+	Open       string `json:"open"`
+	High       string `json:"high"`
+	Low        string `json:"low"`
+	Close      string `json:"close"`
+	Size       string `json:"size"` // 成交量（基础币）
+}
+
+func (k *edgexKline) toCandle(symbol string) *Candle {
+	timestamp, _ := strconv.ParseInt(k.KlineTime, 10, 64)
+	open, _ := strconv.ParseFloat(k.Open, 64)
+	high, _ := strconv.ParseFloat(k.High, 64)
+	low, _ := strconv.ParseFloat(k.Low, 64)
+	close, _ := strconv.ParseFloat(k.Close, 64)
+	volume, _ := strconv.ParseFloat(k.Size, 64)
+
+	return &Candle{
+		Symbol:    symbol,
+		Open:      open,
+		High:      high,
+		Low:       low,
+		Close:     close,
+		Volume:    volume,
+		Timestamp: timestamp,
+	}
+}
+
+// KlineWebSocketManager edgeX K线WebSocket管理器
+// edgeX 的K线推送不带"是否完结"标记：收到新周期的K线时，将上一根缓存的K线以 IsClosed=true 补发
+type KlineWebSocketManager struct {
+	wsURL          string
+	conn           *websocket.Conn
+	mu             sync.RWMutex
+	writeMu        sync.Mutex
+	done           chan struct{}
+	callbacks      map[string]func(candle interface{}) // 支持多个回调函数，key为组件名称
+	symbols        []string
+	interval       string
+	reconnectDelay time.Duration
+	readTimeout    time.Duration
+	isRunning      bool
+
+	contractIDOf func(symbol string) (string, bool) // 交易对 -> contractId
+	symbolOf     func(contractID string) string     // contractId -> 交易对
+	lastCandles  map[string]*Candle                 // 每个交易对最近一根未完结K线
+}
+
+// NewKlineWebSocketManager 创建K线WebSocket管理器
+func NewKlineWebSocketManager(wsURL string, contractIDOf func(string) (string, bool), symbolOf func(string) string) *KlineWebSocketManager {
+	if wsURL == "" {
+		wsURL = EdgeXWSPublic
+	}
+	return &KlineWebSocketManager{
+		wsURL:          wsURL,
+		done:           make(chan struct{}),
+		callbacks:      make(map[string]func(candle interface{})),
+		reconnectDelay: 5 * time.Second,
+		readTimeout:    60 * time.Second,
+		contractIDOf:   contractIDOf,
+		symbolOf:       symbolOf,
+		lastCandles:    make(map[string]*Candle),
+	}
+}
+
+// Start 启动K线流（带自动重连）
+func (k *KlineWebSocketManager) Start(ctx context.Context, symbols []string, interval string, callback func(candle interface{})) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if k.isRunning {
+		// 如果K线流已经在运行，只注册回调函数
+		k.callbacks["default"] = callback
+		return nil
+	}
+
+	k.callbacks["default"] = callback
+	k.symbols = symbols
+	k.interval = interval
+	k.isRunning = true
+
+	go k.connectLoop(ctx)
+
+	return nil
+}
+
+// RegisterCallback 注册回调函数（支持多个组件共享K线流）
+func (k *KlineWebSocketManager) RegisterCallback(componentName string, callback func(candle interface{})) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if !k.isRunning {
+		return fmt.Errorf("K线流未启动，请先调用Start")
+	}
+
+	k.callbacks[componentName] = callback
+	logger.Info("✅ [edgeX K线] 已注册回调函数: %s", componentName)
+	return nil
+}
+
+// connectLoop 连接循环（自动重连）
+func (k *KlineWebSocketManager) connectLoop(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("✅ edgeX K线WebSocket已停止（上下文取消）")
+			return
+		case <-k.done:
+			logger.Info("✅ edgeX K线WebSocket已停止")
+			return
+		default:
+		}
+
+		logger.Info("🔗 正在连接 edgeX K线WebSocket...")
+		conn, _, err := websocket.DefaultDialer.DialContext(ctx, k.wsURL, nil)
+		if err == nil {
+			k.mu.Lock()
+			k.conn = conn
+			k.mu.Unlock()
+			if err = k.subscribe(k.symbols, k.interval); err != nil {
+				conn.Close()
+			}
+		}
+		if err != nil {
+			logger.Error("❌ edgeX K线WebSocket连接失败: %v，%v后重试", err, k.reconnectDelay)
+			select {
+			case <-ctx.Done():
+				return
+			case <-k.done:
+				return
+			case <-time.After(k.reconnectDelay):
+			}
+			continue
+		}
+
+		logger.Info("✅ edgeX K线WebSocket已连接")
+
+		k.readLoop(conn)
+
+		k.mu.Lock()
+		if k.conn == conn {
+			k.conn = nil
+		}
+		k.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			logger.Info("✅ edgeX K线WebSocket已停止（上下文取消）")
+			return
+		case <-k.done:
+			logger.Info("✅ edgeX K线WebSocket已停止")
+			return
+		default:
+		}
+
+		logger.Warn("⚠️ edgeX K线WebSocket连接断开，%v后重连...", k.reconnectDelay)
+		select {
+		case <-ctx.Done():
+			return
+		case <-k.done:
+			return
+		case <-time.After(k.reconnectDelay):
+		}
+	}
+}
+
+// subscribe 订阅K线
+// 订阅格式: {"type": "subscribe", "channel": "kline.LAST_PRICE.10000001.MINUTE_1"}
+func (k *KlineWebSocketManager) subscribe(symbols []string, interval string) error {
+	klineType := convertToEdgeXKlineType(interval)
+
+	k.mu.RLock()
+	conn := k.conn
+	k.mu.RUnlock()
+	if conn == nil {
+		return fmt.Errorf("WebSocket连接未建立")
+	}
+
+	k.writeMu.Lock()
+	defer k.writeMu.Unlock()
+
+	count := 0
+	for _, symbol := range symbols {
+		contractID, ok := k.contractIDOf(symbol)
+		if !ok {
+			logger.Warn("⚠️ [edgeX K线] 未找到合约 %s，跳过订阅", symbol)
+			continue
+		}
+		channel := fmt.Sprintf("kline.LAST_PRICE.%s.%s", contractID, klineType)
+		if err := conn.WriteJSON(map[string]string{"type": "subscribe", "channel": channel}); err != nil {
+			return fmt.Errorf("发送订阅消息失败: %w", err)
+		}
+		count++
+	}
+
+	logger.Debug("已发送K线订阅请求: %d个币种", count)
+	return nil
+}
+
+// Stop 停止K线流
+func (k *KlineWebSocketManager) Stop() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if !k.isRunning {
+		return
+	}
+
+	k.isRunning = false
+	close(k.done)
+
+	if k.conn != nil {
+		k.conn.Close()
+		k.conn = nil
+	}
+
+	logger.Info("✅ edgeX K线WebSocket已停止")
+}
+
+// readLoop 读取消息循环
+func (k *KlineWebSocketManager) readLoop(conn *websocket.Conn) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("❌ edgeX K线WebSocket读取协程panic: %v", r)
+		}
+		conn.Close()
+	}()
+
+	conn.SetReadDeadline(time.Now().Add(k.readTimeout))
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				logger.Warn("⚠️ edgeX K线WebSocket异常关闭: %v", err)
+			} else {
+				logger.Debug("edgeX K线WebSocket读取错误: %v", err)
+			}
+			return
+		}
+		conn.SetReadDeadline(time.Now().Add(k.readTimeout))
+
+		// 服务端心跳
+		if pong, ok := pongFor(message); ok {
+			k.writeMu.Lock()
+			conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			err := conn.WriteJSON(pong)
+			k.writeMu.Unlock()
+			if err != nil {
+				logger.Warn("⚠️ edgeX K线WebSocket回复Pong失败: %v", err)
+				return
+			}
+			continue
+		}
+
+		var msg struct {
+			Type    string `json:"type"`
+			Channel string `json:"channel"`
+			Content struct {
+				Data []edgexKline `json:"data"`
+			} `json:"content"`
+		}
+		if err := json.Unmarshal(message, &msg); err != nil {
+			logger.Debug("解析K线消息失败: %v", err)
+			continue
+		}
+
+		// 跳过订阅确认等消息
+		if msg.Type != "quote-event" || !strings.HasPrefix(msg.Channel, "kline.") {
+			continue
+		}
+
+		for i := range msg.Content.Data {
+			kline := &msg.Content.Data[i]
+			candle := kline.toCandle(k.symbolOf(kline.ContractID))
+
+			for _, c := range k.trackCandle(candle) {
+				k.dispatch(c)
+			}
+		}
+	}
+}
+
+// trackCandle 记录最新K线，返回需要派发的K线（新周期开始时先补发上一根已完结的K线）
+func (k *KlineWebSocketManager) trackCandle(candle *Candle) []*Candle {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	last := k.lastCandles[candle.Symbol]
+	if last != nil && candle.Timestamp < last.Timestamp {
+		return nil // 过期推送
+	}
+	k.lastCandles[candle.Symbol] = candle
+
+	if last != nil && candle.Timestamp > last.Timestamp {
+		closed := *last
+		closed.IsClosed = true
+		return []*Candle{&closed, candle}
+	}
+	return []*Candle{candle}
+}
+
+// dispatch 调用所有回调
+func (k *KlineWebSocketManager) dispatch(candle *Candle) {
+	k.mu.RLock()
+	callbacks := make([]func(candle interface{}), 0, len(k.callbacks))
+	for _, cb := range k.callbacks {
+		callbacks = append(callbacks, cb)
+	}
+	k.mu.RUnlock()
+
+	for _, callback := range callbacks {
+		if callback != nil {
+			callback(candle)
+		}
+	}
+}
+
+// ForceReconnect 强制重新连接K线流
+// 关闭当前连接后由 connectLoop 自动重连并重新订阅
+func (k *KlineWebSocketManager) ForceReconnect() error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if !k.isRunning {
+		return fmt.Errorf("K线流未启动，无法重新连接")
+	}
+
+	logger.Info("🔄 [edgeX K线] 正在强制重新连接...")
+	if k.conn != nil {
+		k.conn.Close()
+		k.conn = nil
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package edgex
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"opensqt/exchange/clock"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// limitOrderWithFeesType StarkEx 永续合约限价单类型（LIMIT_ORDER_WITH_FEES）
+const limitOrderWithFeesType = 3
+
+// Signer edgeX 签名器
+// edgeX 没有传统的 API Secret：REST/WS 鉴权和下单都使用账户的 Stark L2 私钥签名
+type Signer struct {
+	accountID  string
+	positionID *big.Int // StarkEx positionId，等于 accountId
+	privateKey *big.Int
+	publicX    *big.Int
+	publicY    *big.Int
+	clock      *clock.Estimator // 服务器时间偏差，nil 时使用本地时间
+}
+
+// NewSigner 创建签名器
+// starkPrivateKey 为十六进制字符串（可带 0x 前缀）
+func NewSigner(accountID, starkPrivateKey string) (*Signer, error) {
+	positionID, ok := new(big.Int).SetString(accountID, 10)
+	if !ok || positionID.Sign() <= 0 {
+		return nil, fmt.Errorf("edgeX accountId 无效: %s", accountID)
+	}
+
+	privateKey, ok := new(big.Int).SetString(strings.TrimPrefix(strings.ToLower(starkPrivateKey), "0x"), 16)
+	if !ok {
+		return nil, fmt.Errorf("edgeX Stark 私钥格式无效")
+	}
+	x, y, err := PrivateKeyToPublicKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Signer{
+		accountID:  accountID,
+		positionID: positionID,
+		privateKey: privateKey,
+		publicX:    x,
+		publicY:    y,
+	}, nil
+}
+
+// GetAccountID 获取账户ID
+func (s *Signer) GetAccountID() string {
+	return s.accountID
+}
+
+// GetPublicKey 获取 Stark 公钥（x 坐标，十六进制）
+func (s *Signer) GetPublicKey() string {
+	return "0x" + s.publicX.Text(16)
+}
+
+// Now 按服务器时间校正后的当前时间
+func (s *Signer) Now() time.Time {
+	return s.clock.Now()
+}
+
+// GetTimestamp 获取时间戳（毫秒）
+func (s *Signer) GetTimestamp() string {
+	return strconv.FormatInt(s.Now().UnixMilli(), 10)
+}
+
+// SignRequest 生成 API 请求签名（X-edgeX-Api-Signature）
+// 签名内容: timestamp + method + path + params，params 为按 key 排序的 k=v&k=v（GET 为查询串，POST 为请求体展开）
+// 消息哈希: keccak256(内容) mod N，签名格式: r + s + y（各 64 位十六进制）
+func (s *Signer) SignRequest(timestamp, method, path, params string) (string, error) {
+	hasher := sha3.NewLegacyKeccak256()
+	hasher.Write([]byte(timestamp + method + path + params))
+	msgHash := new(big.Int).SetBytes(hasher.Sum(nil))
+	msgHash.Mod(msgHash, starkN)
+
+	r, sig, err := StarkSign(msgHash, s.privateKey)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%064x%064x%064x", r, sig, s.publicY), nil
+}
+
+// l2Order StarkEx 永续限价单参数（均为链上整数单位）
+type l2Order struct {
+	IsBuy             bool
+	SyntheticAssetID  *big.Int
+	CollateralAssetID *big.Int
+	SyntheticAmount   *big.Int // 合约数量 * 合成资产精度
+	CollateralAmount  *big.Int // 名义价值 * 保证金资产精度
+	MaxFee            *big.Int // 最大手续费 * 保证金资产精度
+	Nonce             uint32
+	ExpirationHours   int64 // 过期时间（Unix 小时）
+}
+
+// HashOrder 计算 StarkEx 永续限价单哈希
+//
+//	msg = H(H(H(H(assetSell, assetBuy), assetFee), packed0), packed1)
+//	packed0 = amountSell(64) | amountBuy(64) | maxFee(64) | nonce(32)
+//	packed1 = 3 | positionId(64) | positionId(64) | positionId(64) | expiration(32) | padding(17)
+func (s *Signer) HashOrder(order *l2Order) (*big.Int, error) {
+	assetSell, assetBuy := order.SyntheticAssetID, order.CollateralAssetID
+	amountSell, amountBuy := order.SyntheticAmount, order.CollateralAmount
+	if order.IsBuy {
+		assetSell, assetBuy = assetBuy, assetSell
+		amountSell, amountBuy = amountBuy, amountSell
+	}
+
+	msg, err := PedersenHash(assetSell, assetBuy)
+	if err != nil {
+		return nil, err
+	}
+	if msg, err = PedersenHash(msg, order.CollateralAssetID); err != nil {
+		return nil, err
+	}
+
+	packed0 := new(big.Int).Set(amountSell)
+	packed0 = shiftAdd(packed0, 64, amountBuy)
+	packed0 = shiftAdd(packed0, 64, order.MaxFee)
+	packed0 = shiftAdd(packed0, 32, big.NewInt(int64(order.Nonce)))
+	if msg, err = PedersenHash(msg, packed0); err != nil {
+		return nil, err
+	}
+
+	packed1 := big.NewInt(limitOrderWithFeesType)
+	packed1 = shiftAdd(packed1, 64, s.positionID)
+	packed1 = shiftAdd(packed1, 64, s.positionID)
+	packed1 = shiftAdd(packed1, 64, s.positionID)
+	packed1 = shiftAdd(packed1, 32, big.NewInt(order.ExpirationHours))
+	packed1.Lsh(packed1, 17) // padding
+	return PedersenHash(msg, packed1)
+}
+
+// SignOrder 对限价单签名，返回 r + s + y（各 64 位十六进制）
+func (s *Signer) SignOrder(order *l2Order) (string, error) {
+	hash, err := s.HashOrder(order)
+	if err != nil {
+		return "", err
+	}
+	r, sig, err := StarkSign(hash, s.privateKey)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%064x%064x%064x", r, sig, s.publicY), nil
+}
+
+// nonceFromClientOrderID 由自定义订单ID 推导 32 位 L2 nonce（sha256 前 4 字节）
+func nonceFromClientOrderID(clientOrderID string) uint32 {
+	sum := sha256.Sum256([]byte(clientOrderID))
+	return binary.BigEndian.Uint32(sum[:4])
+}
+
+func shiftAdd(x *big.Int, bits uint, v *big.Int) *big.Int {
+	x.Lsh(x, bits)
+	return x.Add(x, v)
+}
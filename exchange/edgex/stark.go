@@ -0,0 +1,300 @@
+package edgex
+
+/*
+StarkEx 签名原语（Stark 曲线 ECDSA + Pedersen 哈希）
+
+曲线: y² = x³ + α·x + β (mod P)，α = 1
+  P = 2^251 + 17·2^192 + 1
+  N = 曲线阶（签名使用的模数）
+Pedersen 哈希: H(a, b) = [P0 + a_low·P1 + a_high·P2 + b_low·P3 + b_high·P4].x
+  其中 low 为低 248 位，high 为高 4 位
+签名: 标准 ECDSA，k 按 RFC6979（HMAC-SHA256）确定性生成，与 starkware 官方实现一致
+
+域运算、点运算与 Pedersen 哈希使用 gnark-crypto 的 stark-curve 实现；
+私钥与 k 参与的标量乘走固定轮数的 Montgomery ladder（见 secretMulBase），不随标量取值走不同分支
+*/
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+
+	starkcurve "github.com/consensys/gnark-crypto/ecc/stark-curve"
+	"github.com/consensys/gnark-crypto/ecc/stark-curve/fp"
+	"github.com/consensys/gnark-crypto/ecc/stark-curve/fr"
+	pedersenhash "github.com/consensys/gnark-crypto/ecc/stark-curve/pedersen-hash"
+)
+
+func hexToBig(s string) *big.Int {
+	n, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		panic("invalid hex constant: " + s)
+	}
+	return n
+}
+
+var (
+	starkP = fp.Modulus()
+	starkN = fr.Modulus()
+
+	// 2^251：消息哈希、r、w 的上界
+	maxECDSAValue = new(big.Int).Lsh(big.NewInt(1), 251)
+	// 2N：secretMulBase 中把标量平移到固定位长
+	twoStarkN = new(big.Int).Lsh(starkN, 1)
+)
+
+// secretMulBase 常量时间标量乘 [k]G，用于私钥和签名随机数 k（要求 0 < k < N）
+//
+// 先把 k 平移为 k+2N（[k+2N]G = [k]G），N > 2^251 保证其最高位恒为第 252 位，
+// 再做 Montgomery ladder：每一位都固定执行一次点加和一次倍点，两点用 Select 按位条件交换，
+// 循环次数与运算序列都与 k 的取值无关
+func secretMulBase(k *big.Int) starkcurve.G1Affine {
+	scalar := new(big.Int).Add(k, twoStarkN)
+	gen, _ := starkcurve.Generators()
+
+	// 不变式: r1 - r0 = G，最高位已隐含在 r0 = G 中
+	var r0, r1 starkcurve.G1Jac
+	r0.Set(&gen)
+	r1.Double(&gen)
+	for i := scalar.BitLen() - 2; i >= 0; i-- {
+		bit := int(scalar.Bit(i))
+		condSwap(&r0, &r1, bit)
+		r1.AddAssign(&r0)
+		r0.DoubleAssign()
+		condSwap(&r0, &r1, bit)
+	}
+
+	var result starkcurve.G1Affine
+	result.FromJacobian(&r0)
+	return result
+}
+
+// condSwap c 为 1 时交换 a、b（常量时间）
+func condSwap(a, b *starkcurve.G1Jac, c int) {
+	var ta, tb starkcurve.G1Jac
+	ta.X.Select(c, &a.X, &b.X)
+	ta.Y.Select(c, &a.Y, &b.Y)
+	ta.Z.Select(c, &a.Z, &b.Z)
+	tb.X.Select(c, &b.X, &a.X)
+	tb.Y.Select(c, &b.Y, &a.Y)
+	tb.Z.Select(c, &b.Z, &a.Z)
+	*a, *b = ta, tb
+}
+
+// toFieldElement 校验并转换为域元素（必须小于 P）
+func toFieldElement(x *big.Int) (*fp.Element, error) {
+	if x.Sign() < 0 || x.Cmp(starkP) >= 0 {
+		return nil, fmt.Errorf("pedersen 输入超出范围: %s", x.Text(16))
+	}
+	return new(fp.Element).SetBigInt(x), nil
+}
+
+// PedersenHash 计算 StarkEx Pedersen 哈希 H(a, b)
+// a、b 必须小于域素数 P
+func PedersenHash(a, b *big.Int) (*big.Int, error) {
+	fa, err := toFieldElement(a)
+	if err != nil {
+		return nil, err
+	}
+	fb, err := toFieldElement(b)
+	if err != nil {
+		return nil, err
+	}
+	hash := pedersenhash.Pedersen(fa, fb)
+	return hash.BigInt(new(big.Int)), nil
+}
+
+// PedersenHashArray 计算数组哈希: H(H(...H(H(0, e1), e2)...), len)
+func PedersenHashArray(elements ...*big.Int) (*big.Int, error) {
+	hash := big.NewInt(0)
+	var err error
+	for _, e := range elements {
+		if hash, err = PedersenHash(hash, e); err != nil {
+			return nil, err
+		}
+	}
+	return PedersenHash(hash, big.NewInt(int64(len(elements))))
+}
+
+// PrivateKeyToPublicKey 由私钥计算公钥点（Stark 公钥即 x 坐标）
+func PrivateKeyToPublicKey(privateKey *big.Int) (x, y *big.Int, err error) {
+	if privateKey.Sign() <= 0 || privateKey.Cmp(starkN) >= 0 {
+		return nil, nil, fmt.Errorf("stark 私钥超出范围")
+	}
+	p := secretMulBase(privateKey)
+	return p.X.BigInt(new(big.Int)), p.Y.BigInt(new(big.Int)), nil
+}
+
+// StarkSign 对消息哈希签名，返回 (r, s)
+// 与 starkware 官方 sign() 一致：k 由 RFC6979 生成，不满足范围要求时以递增 seed 重试
+func StarkSign(msgHash, privateKey *big.Int) (r, s *big.Int, err error) {
+	if msgHash.Sign() < 0 || msgHash.Cmp(maxECDSAValue) >= 0 {
+		return nil, nil, fmt.Errorf("消息哈希超出范围: %s", msgHash.Text(16))
+	}
+	if privateKey.Sign() <= 0 || privateKey.Cmp(starkN) >= 0 {
+		return nil, nil, fmt.Errorf("stark 私钥超出范围")
+	}
+
+	var seed *big.Int
+	for attempt := 0; attempt < 100; attempt++ {
+		k := generateKRFC6979(msgHash, privateKey, seed)
+		if seed == nil {
+			seed = big.NewInt(1)
+		} else {
+			seed = new(big.Int).Add(seed, big.NewInt(1))
+		}
+
+		point := secretMulBase(k)
+		r = point.X.BigInt(new(big.Int))
+		if r.Sign() <= 0 || r.Cmp(maxECDSAValue) >= 0 {
+			continue
+		}
+
+		// s = (msgHash + r·priv) / k mod N
+		sum := new(big.Int).Mul(r, privateKey)
+		sum.Add(sum, msgHash)
+		sum.Mod(sum, starkN)
+		if sum.Sign() == 0 {
+			continue
+		}
+		w := new(big.Int).Mul(k, new(big.Int).ModInverse(sum, starkN))
+		w.Mod(w, starkN)
+		if w.Sign() <= 0 || w.Cmp(maxECDSAValue) >= 0 {
+			continue
+		}
+
+		s = new(big.Int).ModInverse(w, starkN)
+		return r, s, nil
+	}
+
+	return nil, nil, fmt.Errorf("stark 签名失败：无法生成有效的 k")
+}
+
+// StarkVerify 验证签名
+func StarkVerify(msgHash, r, s, publicKeyX *big.Int) bool {
+	if r.Sign() <= 0 || r.Cmp(maxECDSAValue) >= 0 || s.Sign() <= 0 || s.Cmp(starkN) >= 0 {
+		return false
+	}
+	if msgHash.Sign() < 0 || msgHash.Cmp(maxECDSAValue) >= 0 {
+		return false
+	}
+
+	// 由 x 恢复 y（两个候选 y 任一通过即可）
+	pub, ok := pointFromX(publicKeyX)
+	if !ok {
+		return false
+	}
+
+	w := new(big.Int).ModInverse(s, starkN)
+	u1 := new(big.Int).Mul(msgHash, w)
+	u1.Mod(u1, starkN)
+	u2 := new(big.Int).Mul(r, w)
+	u2.Mod(u2, starkN)
+
+	// 验签只涉及公开数据，直接用 gnark 的双标量乘
+	for _, candidate := range []starkcurve.G1Affine{pub, *new(starkcurve.G1Affine).Neg(&pub)} {
+		var sum starkcurve.G1Jac
+		sum.JointScalarMultiplicationBase(&candidate, u1, u2)
+		var point starkcurve.G1Affine
+		point.FromJacobian(&sum)
+		if point.IsInfinity() {
+			continue
+		}
+		if new(big.Int).Mod(point.X.BigInt(new(big.Int)), starkN).Cmp(r) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// pointFromX 由 x 坐标还原曲线点（y 取任一平方根）
+func pointFromX(x *big.Int) (starkcurve.G1Affine, bool) {
+	var point starkcurve.G1Affine
+	if x.Sign() < 0 || x.Cmp(starkP) >= 0 {
+		return point, false
+	}
+	point.X.SetBigInt(x)
+
+	// y² = x³ + α·x + β
+	_, beta := starkcurve.CurveCoefficients()
+	var rhs fp.Element
+	rhs.Square(&point.X).Mul(&rhs, &point.X).Add(&rhs, &point.X).Add(&rhs, &beta)
+	if point.Y.Sqrt(&rhs) == nil {
+		return point, false
+	}
+	return point, true
+}
+
+// generateKRFC6979 按 RFC6979 生成确定性 k（HMAC-SHA256）
+// 与 starkware generate_k_rfc6979 保持一致：长度为 248~251 位且缺半个字节的哈希先左移 4 位
+func generateKRFC6979(msgHash, privateKey, seed *big.Int) *big.Int {
+	hash := new(big.Int).Set(msgHash)
+	bitLen := hash.BitLen()
+	if bitLen >= 248 && bitLen%8 >= 1 && bitLen%8 <= 4 {
+		hash.Lsh(hash, 4)
+	}
+
+	qlen := starkN.BitLen()
+	rolen := (qlen + 7) / 8
+
+	// bits2octets(hash)
+	hashBytes := hash.Bytes()
+	z := bits2int(hashBytes, qlen)
+	if z.Cmp(starkN) >= 0 {
+		z.Sub(z, starkN)
+	}
+
+	bx := append(intToOctets(privateKey, rolen), intToOctets(z, rolen)...)
+	if seed != nil {
+		bx = append(bx, seed.Bytes()...)
+	}
+
+	v := make([]byte, sha256.Size)
+	k := make([]byte, sha256.Size)
+	for i := range v {
+		v[i] = 0x01
+	}
+
+	k = hmacSHA256(k, v, []byte{0x00}, bx)
+	v = hmacSHA256(k, v)
+	k = hmacSHA256(k, v, []byte{0x01}, bx)
+	v = hmacSHA256(k, v)
+
+	for {
+		var t []byte
+		for len(t) < rolen {
+			v = hmacSHA256(k, v)
+			t = append(t, v...)
+		}
+		secret := bits2int(t, qlen)
+		if secret.Sign() > 0 && secret.Cmp(starkN) < 0 {
+			return secret
+		}
+		k = hmacSHA256(k, v, []byte{0x00})
+		v = hmacSHA256(k, v)
+	}
+}
+
+// bits2int 取字节串的高 qlen 位作为整数
+func bits2int(data []byte, qlen int) *big.Int {
+	x := new(big.Int).SetBytes(data)
+	if l := len(data) * 8; l > qlen {
+		x.Rsh(x, uint(l-qlen))
+	}
+	return x
+}
+
+// intToOctets 大端定长编码
+func intToOctets(x *big.Int, length int) []byte {
+	out := make([]byte, length)
+	return x.FillBytes(out)
+}
+
+func hmacSHA256(key []byte, parts ...[]byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	for _, p := range parts {
+		mac.Write(p)
+	}
+	return mac.Sum(nil)
+}
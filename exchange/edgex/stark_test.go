@@ -0,0 +1,150 @@
+package edgex
+
+import (
+	"math/big"
+	"testing"
+
+	starkcurve "github.com/consensys/gnark-crypto/ecc/stark-curve"
+)
+
+// 以下向量取自 starkware 官方实现（cairo-lang / starkex-resources）及 starknet.go 的测试数据
+
+func TestCurveConstants(t *testing.T) {
+	// P = 2^251 + 17·2^192 + 1
+	p := new(big.Int).Lsh(big.NewInt(1), 251)
+	p.Add(p, new(big.Int).Lsh(big.NewInt(17), 192))
+	p.Add(p, big.NewInt(1))
+	if starkP.Cmp(p) != 0 {
+		t.Errorf("域素数错误: %x", starkP)
+	}
+	if want := "800000000000010ffffffffffffffffb781126dcae7b2321e66a241adc64d2f"; starkN.Text(16) != want {
+		t.Errorf("曲线阶错误: %x", starkN)
+	}
+
+	_, gen := starkcurve.Generators()
+	if got := gen.X.BigInt(new(big.Int)).Text(16); got != "1ef15c18599971b7beced415a40f0c7deacfd9b0d1819e03d723d8bc943cfca" {
+		t.Errorf("生成元 x 错误: %s", got)
+	}
+	if got := gen.Y.BigInt(new(big.Int)).Text(16); got != "5668060aa49730b7be4801df46ec62de53ecd11abe43a32873000c36e8dc1f" {
+		t.Errorf("生成元 y 错误: %s", got)
+	}
+}
+
+// secretMulBase 与 gnark 的（非常量时间）标量乘结果一致，覆盖边界标量
+func TestSecretMulBaseMatchesReference(t *testing.T) {
+	scalars := []*big.Int{
+		big.NewInt(1),
+		big.NewInt(2),
+		new(big.Int).Sub(starkN, big.NewInt(1)),
+		new(big.Int).Sub(maxECDSAValue, big.NewInt(1)),
+		hexToBig("3c1e9550e66958296d11b60f8e8e7a7ad990d07fa65d5f7652c4a6c87d4e3cc"),
+	}
+	for _, k := range scalars {
+		got := secretMulBase(k)
+		var want starkcurve.G1Affine
+		want.ScalarMultiplicationBase(k)
+		if !got.Equal(&want) {
+			t.Errorf("[%x]G 结果不一致", k)
+		}
+	}
+}
+
+func TestPedersenKnownVectors(t *testing.T) {
+	// 空数组: H(0, 0) = P0.x
+	got, err := PedersenHashArray()
+	if err != nil {
+		t.Fatalf("计算哈希失败: %v", err)
+	}
+	if want := "49ee3eba8c1600700ee1b87eb599f16716b0b1022947733551fde4050ca6804"; got.Text(16) != want {
+		t.Errorf("空数组哈希错误: got %x, want %s", got, want)
+	}
+
+	got, err = PedersenHashArray(big.NewInt(123782376), big.NewInt(213984), big.NewInt(128763521321))
+	if err != nil {
+		t.Fatalf("计算哈希失败: %v", err)
+	}
+	if want := "7b422405da6571242dfc245a43de3b0fe695e7021c148b918cd9cdb462cac59"; got.Text(16) != want {
+		t.Errorf("数组哈希错误: got %x, want %s", got, want)
+	}
+
+	if _, err := PedersenHash(starkP, big.NewInt(0)); err == nil {
+		t.Error("输入超出域范围时应返回错误")
+	}
+}
+
+func TestPrivateKeyToPublicKeyKnownVectors(t *testing.T) {
+	cases := []struct {
+		privateKey string
+		publicKey  string
+	}{
+		{"2", "759ca09377679ecd535a81e83039658bf40959283187c654c5416f439403cf5"},
+		{"85b0ed141c12d4297a9f6fa3032b9757", "43135f5e8e5e73d9750659bb5cccc803bc63318584933d584f9b5372ee8ffa6"},
+		{"b3de4d1a7a54cb19e2fbf0897cdaa555", "6591082275c7da568b1542044eb08c2fcf3e0c75121a5275dce4960367f2bb8"},
+		{"522e4cd212156cf8ef4052615570ad8f", "6a78b5ad5abdb109d4d362c14895efbd45a111d5f80157f669fd127ad0c0fd"},
+		{"baa0de5814f3b01f797c26b8e4e15c5", "21a2016d43180337d76210eb85a016d2e28e315330c70fc66151c60981b0a18"},
+		{"e2f6c88bd587ea90e65db1cda8a90918", "3796f3fbe494243b1afeb7a0921500082f6c3c4cfb2af7f963700eb4e0a6c89"},
+		{"3c1e9550e66958296d11b60f8e8e7a7ad990d07fa65d5f7652c4a6c87d4e3cc", "77a3b314db07c45076d11f62b6f9e748a39790441823307743cf00d6597ea43"},
+	}
+
+	for _, c := range cases {
+		x, _, err := PrivateKeyToPublicKey(hexToBig(c.privateKey))
+		if err != nil {
+			t.Fatalf("计算公钥失败: %v", err)
+		}
+		if x.Text(16) != c.publicKey {
+			t.Errorf("公钥错误: priv=%s, got %x, want %s", c.privateKey, x, c.publicKey)
+		}
+	}
+}
+
+func TestStarkVerifyKnownVector(t *testing.T) {
+	msgHash := hexToBig("2789daed76c8b750d5a609a706481034db9dc8b63ae01f505d21e75a8fc2336")
+	r := hexToBig("13e4e383af407f7ccc1f13195ff31a58cad97bbc6cf1d532798b8af616999d4")
+	s := hexToBig("44dd06cf67b2ba7ea4af346d80b0b439e02a0b5893c6e4dfda9ee204211c879")
+	publicKey := hexToBig("6c7c4408e178b2999cef9a5b3fa2a3dffc876892ad6a6bd19d1451a2256906c")
+
+	if !StarkVerify(msgHash, r, s, publicKey) {
+		t.Error("有效签名验证失败")
+	}
+	wrongHash := new(big.Int).Add(msgHash, big.NewInt(1))
+	if StarkVerify(wrongHash, r, s, publicKey) {
+		t.Error("错误的消息哈希不应通过验证")
+	}
+}
+
+// starkex-resources signature.test.js 中的 RFC6979 签名向量
+func TestStarkSignKnownVector(t *testing.T) {
+	privateKey := hexToBig("2dccce1da22003777062ee0870e9881b460a8b7eca276870f57c601f182136c")
+	msgHash := hexToBig("c465dd6b1bbffdb05442eb17f5ca38ad1aa78a6f56bf4415bdee219114a47")
+
+	r, s, err := StarkSign(msgHash, privateKey)
+	if err != nil {
+		t.Fatalf("签名失败: %v", err)
+	}
+	if r.Text(16) != "5f496f6f210b5810b2711c74c15c05244dad43d18ecbbdbe6ed55584bc3b0a2" ||
+		s.Text(16) != "4e8657b153787f741a67c0666bad6426c3741b478c8eaa3155196fc571416f3" {
+		t.Errorf("签名错误: r=%x, s=%x", r, s)
+	}
+}
+
+func TestStarkSignIsDeterministic(t *testing.T) {
+	privateKey := hexToBig("2dccce1da22003777062ee0870e9881b460a8b7eca276870f57c601f182136c")
+	msgHash := hexToBig("6fea80189363a786037ed3e7ba546dad0ef7de49fccae0e31eb658b7dd4ea76")
+	publicKey, _, _ := PrivateKeyToPublicKey(privateKey)
+
+	r1, s1, err := StarkSign(msgHash, privateKey)
+	if err != nil {
+		t.Fatalf("签名失败: %v", err)
+	}
+	r2, s2, _ := StarkSign(msgHash, privateKey)
+	if r1.Cmp(r2) != 0 || s1.Cmp(s2) != 0 {
+		t.Error("RFC6979 签名应当是确定性的")
+	}
+	if !StarkVerify(msgHash, r1, s1, publicKey) {
+		t.Error("签名无法通过验证")
+	}
+
+	if _, _, err := StarkSign(maxECDSAValue, privateKey); err == nil {
+		t.Error("消息哈希超出 2^251 时应返回错误")
+	}
+}
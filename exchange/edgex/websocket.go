@@ -0,0 +1,419 @@
+package edgex
+
+/*
+edgeX WebSocket 架构说明：
+
+1. **公共频道** (wss://quote.edgex.exchange/api/v1/public/ws)：订阅 ticker.{contractId} 价格推送、kline K线推送
+2. **私有频道** (wss://quote.edgex.exchange/api/v1/private/ws?accountId=xxx)：握手时通过请求头携带 Stark 签名鉴权，
+   连接后自动推送订单、持仓、资产变化（trade-event），无需订阅
+3. 心跳由服务端发起：收到 {"type":"ping","time":"..."} 后需回复 {"type":"pong","time":"..."}
+*/
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"opensqt/logger"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// edgeX WebSocket 地址
+	EdgeXWSPublic  = "wss://quote.edgex.exchange/api/v1/public/ws"
+	EdgeXWSPrivate = "wss://quote.edgex.exchange/api/v1/private/ws"
+
+	// 测试网地址
+	EdgeXWSPublicTestnet  = "wss://quote-testnet.edgex.exchange/api/v1/public/ws"
+	EdgeXWSPrivateTestnet = "wss://quote-testnet.edgex.exchange/api/v1/private/ws"
+)
+
+// WebSocketManager edgeX WebSocket 管理器
+type WebSocketManager struct {
+	signer     *Signer
+	publicURL  string
+	privateURL string
+
+	// adapter 用于 contractId 与交易对的转换（由适配器注入）
+	adapter *EdgeXAdapter
+
+	mu          sync.RWMutex
+	writeMu     sync.Mutex
+	publicConn  *websocket.Conn
+	privateConn *websocket.Conn
+
+	// 回调函数
+	orderCallback func(OrderUpdate)
+	priceCallback func(float64)
+
+	// 控制
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	publicStarted  bool
+	privateStarted bool
+	contractID     string
+
+	// 价格缓存
+	latestPrice float64
+	priceMu     sync.RWMutex
+
+	reconnectDelay time.Duration
+	readTimeout    time.Duration
+}
+
+// NewWebSocketManager 创建 WebSocket 管理器
+func NewWebSocketManager(signer *Signer) *WebSocketManager {
+	return &WebSocketManager{
+		signer:         signer,
+		publicURL:      EdgeXWSPublic,
+		privateURL:     EdgeXWSPrivate,
+		reconnectDelay: 5 * time.Second,
+		readTimeout:    60 * time.Second, // 服务端定期发送 ping，超时未收到任何消息视为断线
+	}
+}
+
+// ensureContext 初始化内部 context（首次启动时）
+func (w *WebSocketManager) ensureContext(ctx context.Context) {
+	if w.ctx == nil || w.ctx.Err() != nil {
+		w.ctx, w.cancel = context.WithCancel(ctx)
+	}
+}
+
+// StartPublic 启动公共频道（价格推送）
+func (w *WebSocketManager) StartPublic(ctx context.Context, contractID string, callback func(float64)) error {
+	w.mu.Lock()
+	w.priceCallback = callback
+	w.contractID = contractID
+	if w.publicStarted {
+		w.mu.Unlock()
+		logger.Debug("✅ [edgeX] 价格流回调已注册（WebSocket已在运行）")
+		return nil
+	}
+	w.ensureContext(ctx)
+	w.publicStarted = true
+	w.mu.Unlock()
+
+	w.wg.Add(1)
+	go w.connectLoop("公共", w.dialPublic, w.handlePublicMessage)
+
+	logger.Info("✅ [edgeX WebSocket] 启动成功，将订阅合约 %s 的价格更新", contractID)
+	return nil
+}
+
+// StartPrivate 启动私有频道（订单推送）
+func (w *WebSocketManager) StartPrivate(ctx context.Context, callback func(OrderUpdate)) error {
+	w.mu.Lock()
+	w.orderCallback = callback
+	if w.privateStarted {
+		w.mu.Unlock()
+		return nil
+	}
+	w.ensureContext(ctx)
+	w.privateStarted = true
+	w.mu.Unlock()
+
+	w.wg.Add(1)
+	go w.connectLoop("私有", w.dialPrivate, w.handlePrivateMessage)
+
+	logger.Info("✅ [edgeX WebSocket] 启动成功，将接收订单更新")
+	return nil
+}
+
+// Stop 停止 WebSocket
+func (w *WebSocketManager) Stop() {
+	w.mu.Lock()
+	if w.cancel != nil {
+		w.cancel()
+	}
+	if w.publicConn != nil {
+		w.publicConn.Close()
+	}
+	if w.privateConn != nil {
+		w.privateConn.Close()
+	}
+	w.publicStarted = false
+	w.privateStarted = false
+	w.mu.Unlock()
+
+	// 等待所有 goroutine 退出（不能持有锁，避免死锁）
+	w.wg.Wait()
+	logger.Info("✅ [edgeX WebSocket] 已停止")
+}
+
+// GetLatestPrice 获取缓存的最新价格
+func (w *WebSocketManager) GetLatestPrice() float64 {
+	w.priceMu.RLock()
+	defer w.priceMu.RUnlock()
+	return w.latestPrice
+}
+
+// connectLoop 连接循环（自动重连）
+// dial: 建立连接并完成订阅，handler: 处理每条业务消息
+func (w *WebSocketManager) connectLoop(name string, dial func() (*websocket.Conn, error), handler func([]byte)) {
+	defer w.wg.Done()
+
+	for {
+		select {
+		case <-w.ctx.Done():
+			logger.Info("✅ [edgeX WS%s] 停止连接循环", name)
+			return
+		default:
+		}
+
+		logger.Info("🔗 [edgeX WS%s] 正在连接...", name)
+		conn, err := dial()
+		if err != nil {
+			logger.Error("❌ [edgeX WS%s] 连接失败: %v，%v后重试", name, err, w.reconnectDelay)
+			select {
+			case <-w.ctx.Done():
+				logger.Info("✅ [edgeX WS%s] 停止连接循环", name)
+				return
+			case <-time.After(w.reconnectDelay):
+			}
+			continue
+		}
+
+		logger.Info("✅ [edgeX WS%s] 已连接", name)
+		w.readLoop(conn, handler)
+
+		w.mu.Lock()
+		if w.publicConn == conn {
+			w.publicConn = nil
+		}
+		if w.privateConn == conn {
+			w.privateConn = nil
+		}
+		w.mu.Unlock()
+		conn.Close()
+
+		select {
+		case <-w.ctx.Done():
+			logger.Info("✅ [edgeX WS%s] 停止连接循环", name)
+			return
+		default:
+		}
+
+		logger.Warn("⚠️ [edgeX WS%s] 连接断开，%v后重连...", name, w.reconnectDelay)
+		select {
+		case <-w.ctx.Done():
+			logger.Info("✅ [edgeX WS%s] 停止连接循环", name)
+			return
+		case <-time.After(w.reconnectDelay):
+		}
+	}
+}
+
+// dialPublic 连接公共频道并订阅 ticker
+func (w *WebSocketManager) dialPublic() (*websocket.Conn, error) {
+	conn, _, err := websocket.DefaultDialer.DialContext(w.ctx, w.publicURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	w.mu.Lock()
+	w.publicConn = conn
+	contractID := w.contractID
+	w.mu.Unlock()
+
+	if err := w.writeJSON(conn, map[string]string{"type": "subscribe", "channel": "ticker." + contractID}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// dialPrivate 连接私有频道（握手请求头携带签名）
+// 签名内容: timestamp + "GET" + path + "accountId=xxx"
+func (w *WebSocketManager) dialPrivate() (*websocket.Conn, error) {
+	u, err := url.Parse(w.privateURL)
+	if err != nil {
+		return nil, err
+	}
+	query := url.Values{}
+	query.Set("accountId", w.signer.GetAccountID())
+	u.RawQuery = query.Encode()
+
+	timestamp := w.signer.GetTimestamp()
+	signature, err := w.signer.SignRequest(timestamp, http.MethodGet, u.Path, u.RawQuery)
+	if err != nil {
+		return nil, err
+	}
+	header := http.Header{}
+	header.Set("X-edgeX-Api-Timestamp", timestamp)
+	header.Set("X-edgeX-Api-Signature", signature)
+
+	conn, _, err := websocket.DefaultDialer.DialContext(w.ctx, u.String(), header)
+	if err != nil {
+		return nil, err
+	}
+
+	w.mu.Lock()
+	w.privateConn = conn
+	w.mu.Unlock()
+	return conn, nil
+}
+
+// writeJSON 串行写入（gorilla/websocket 不支持并发写）
+func (w *WebSocketManager) writeJSON(conn *websocket.Conn, v interface{}) error {
+	w.writeMu.Lock()
+	defer w.writeMu.Unlock()
+	conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	return conn.WriteJSON(v)
+}
+
+// readLoop 读取消息循环（处理服务端 ping）
+func (w *WebSocketManager) readLoop(conn *websocket.Conn, handler func([]byte)) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("❌ [edgeX WebSocket] 读取协程panic: %v", r)
+		}
+	}()
+
+	conn.SetReadDeadline(time.Now().Add(w.readTimeout))
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				logger.Warn("⚠️ [edgeX WebSocket] 异常关闭: %v", err)
+			} else {
+				logger.Debug("edgeX WebSocket 读取错误: %v", err)
+			}
+			return
+		}
+		conn.SetReadDeadline(time.Now().Add(w.readTimeout))
+
+		if pong, ok := pongFor(message); ok {
+			if err := w.writeJSON(conn, pong); err != nil {
+				logger.Warn("⚠️ [edgeX WebSocket] 回复 Pong 失败: %v", err)
+				return
+			}
+			continue
+		}
+		handler(message)
+	}
+}
+
+// pongFor 若消息为服务端 ping，返回对应的 pong
+func pongFor(message []byte) (map[string]string, bool) {
+	var msg struct {
+		Type string `json:"type"`
+		Time string `json:"time"`
+	}
+	if json.Unmarshal(message, &msg) != nil || msg.Type != "ping" {
+		return nil, false
+	}
+	return map[string]string{"type": "pong", "time": msg.Time}, true
+}
+
+// wsMessage edgeX WebSocket 推送消息
+type wsMessage struct {
+	Type    string `json:"type"`
+	Channel string `json:"channel"`
+	Content struct {
+		DataType string          `json:"dataType"`
+		Event    string          `json:"event"`
+		Data     json.RawMessage `json:"data"`
+	} `json:"content"`
+}
+
+// handlePublicMessage 处理公共频道消息
+func (w *WebSocketManager) handlePublicMessage(message []byte) {
+	var msg wsMessage
+	if err := json.Unmarshal(message, &msg); err != nil {
+		logger.Debug("解析 edgeX 公共消息失败: %v", err)
+		return
+	}
+
+	if msg.Type == "error" {
+		logger.Warn("⚠️ [edgeX WS公共] 错误: %s", string(message))
+		return
+	}
+	if msg.Type != "quote-event" || !strings.HasPrefix(msg.Channel, "ticker.") {
+		return
+	}
+
+	var tickers []struct {
+		ContractID string `json:"contractId"`
+		LastPrice  string `json:"lastPrice"`
+	}
+	if err := json.Unmarshal(msg.Content.Data, &tickers); err != nil || len(tickers) == 0 {
+		return
+	}
+	price, err := strconv.ParseFloat(tickers[0].LastPrice, 64)
+	if err != nil || price <= 0 {
+		return
+	}
+
+	w.priceMu.Lock()
+	w.latestPrice = price
+	w.priceMu.Unlock()
+
+	w.mu.RLock()
+	callback := w.priceCallback
+	w.mu.RUnlock()
+	if callback != nil {
+		callback(price)
+	}
+}
+
+// handlePrivateMessage 处理私有频道消息
+func (w *WebSocketManager) handlePrivateMessage(message []byte) {
+	var msg wsMessage
+	if err := json.Unmarshal(message, &msg); err != nil {
+		logger.Debug("解析 edgeX 私有消息失败: %v", err)
+		return
+	}
+
+	if msg.Type == "error" {
+		logger.Warn("⚠️ [edgeX WS私有] 错误: %s", string(message))
+		return
+	}
+	if msg.Type != "trade-event" {
+		return
+	}
+
+	var data struct {
+		Order []edgexOrder `json:"order"`
+	}
+	if err := json.Unmarshal(msg.Content.Data, &data); err != nil {
+		logger.Warn("⚠️ [edgeX] 解析订单推送失败: %v", err)
+		return
+	}
+
+	w.mu.RLock()
+	callback := w.orderCallback
+	w.mu.RUnlock()
+	if callback == nil || w.adapter == nil {
+		return
+	}
+
+	for i := range data.Order {
+		order := w.adapter.toOrder(&data.Order[i])
+		update := OrderUpdate{
+			OrderID:       order.OrderID,
+			ClientOrderID: order.ClientOrderID,
+			Symbol:        order.Symbol,
+			Side:          order.Side,
+			Type:          order.Type,
+			Status:        order.Status,
+			Price:         order.Price,
+			Quantity:      order.Quantity,
+			ExecutedQty:   order.ExecutedQty,
+			AvgPrice:      order.AvgPrice,
+			UpdateTime:    order.UpdateTime,
+		}
+
+		logger.Debug("🔍 [edgeX] 订单推送: ID=%d, ClientOID=%s, Status=%s, 成交=%.6f",
+			update.OrderID, update.ClientOrderID, update.Status, update.ExecutedQty)
+		callback(update)
+	}
+}
@@ -0,0 +1,17 @@
+package exchange
+
+import "opensqt/exchange/errs"
+
+// 交易所错误分类，各适配器已将原生错误码映射为以下错误，调用方使用 errors.Is 判断
+var (
+	ErrInsufficientMargin   = errs.ErrInsufficientMargin   // 保证金不足，不应重试
+	ErrPostOnlyWouldCross   = errs.ErrPostOnlyWouldCross   // PostOnly 订单会立即成交被拒
+	ErrRateLimited          = errs.ErrRateLimited          // 触发速率限制，等待后重试
+	ErrPositionModeMismatch = errs.ErrPositionModeMismatch // 持仓模式（单向/双向）不匹配
+	ErrClockSkew            = errs.ErrClockSkew            // 本地时间与服务器不同步
+	ErrOrderNotFound        = errs.ErrOrderNotFound        // 订单不存在（已成交或已撤销）
+	ErrMinNotional          = errs.ErrMinNotional          // 订单名义价值低于最小要求
+)
+
+// ExchangeError 已分类的交易所错误，可通过 errors.As 获取原生错误码
+type ExchangeError = errs.Error
@@ -0,0 +1,85 @@
+// Package errs 交易所错误分类
+// 各交易所适配器将原生错误码映射为这里的哨兵错误，上层（订单执行器等）统一用 errors.Is 判断，
+// 不再按字符串匹配各家错误码。独立成包是为了让 exchange 与各交易所子包都能引用而不产生循环依赖。
+package errs
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrInsufficientMargin   = errors.New("保证金不足")
+	ErrPostOnlyWouldCross   = errors.New("PostOnly订单会立即成交")
+	ErrRateLimited          = errors.New("触发速率限制")
+	ErrPositionModeMismatch = errors.New("持仓模式不匹配")
+	ErrClockSkew            = errors.New("时间戳不同步")
+	ErrOrderNotFound        = errors.New("订单不存在")
+	ErrMinNotional          = errors.New("订单名义价值过小")
+)
+
+// Error 已分类的交易所错误，保留原生错误码与原始错误
+type Error struct {
+	Kind     error  // 错误分类（上面的哨兵错误之一）
+	Exchange string // 交易所名称
+	Code     string // 交易所原生错误码/标签
+	Err      error  // 原始错误
+
+	RetryAfter time.Duration // 限频时交易所给出的等待时间（Retry-After 等响应头），0 表示未给出
+}
+
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return e.Kind.Error()
+	}
+	return fmt.Sprintf("%v: %v", e.Kind, e.Err)
+}
+
+// Unwrap 同时暴露分类与原始错误，errors.Is/As 对两者均可命中
+func (e *Error) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+// Wrap 将原始错误包装为指定分类，kind 为 nil 时原样返回
+func Wrap(kind error, exchange, code string, err error) error {
+	if kind == nil || err == nil {
+		return err
+	}
+	return &Error{Kind: kind, Exchange: exchange, Code: code, Err: err}
+}
+
+// WithRetryAfter 为限频错误附加交易所给出的等待时间，其他错误或 d <= 0 时原样返回
+func WithRetryAfter(err error, d time.Duration) error {
+	var e *Error
+	if d > 0 && errors.As(err, &e) && e.Kind == ErrRateLimited {
+		e.RetryAfter = d
+	}
+	return err
+}
+
+// RetryAfter 错误携带的等待时间，没有时返回 0
+func RetryAfter(err error) time.Duration {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.RetryAfter
+	}
+	return 0
+}
+
+// ParseRetryAfter 解析 Retry-After 响应头（秒数或 HTTP 日期），缺失或无效时返回 0
+func ParseRetryAfter(header http.Header) time.Duration {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return time.Until(at)
+	}
+	return 0
+}
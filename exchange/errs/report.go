@@ -0,0 +1,21 @@
+package errs
+
+import "context"
+
+type reporterKey struct{}
+
+// WithReporter 在 ctx 中附加错误上报回调
+// 批量下单等接口逐单处理错误、不通过返回值传出，适配器用 Report 把这些错误交给调用方（如请求额度管理识别限频）
+func WithReporter(ctx context.Context, report func(error)) context.Context {
+	return context.WithValue(ctx, reporterKey{}, report)
+}
+
+// Report 上报错误，ctx 未附加回调或 err 为 nil 时忽略
+func Report(ctx context.Context, err error) {
+	if err == nil {
+		return
+	}
+	if report, ok := ctx.Value(reporterKey{}).(func(error)); ok {
+		report(err)
+	}
+}
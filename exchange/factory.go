@@ -2,16 +2,41 @@ package exchange
 
 import (
 	"fmt"
+	"strconv"
+
 	"opensqt/config"
 	"opensqt/exchange/binance"
 	"opensqt/exchange/bitget"
+	"opensqt/exchange/bybit"
+	"opensqt/exchange/edgex"
 	"opensqt/exchange/gate"
+	"opensqt/exchange/hyperliquid"
+	"opensqt/exchange/okx"
 )
 
 // NewExchange 创建交易所实例
+// 返回的实例已接入账户级请求额度管理，同一交易所同一 API Key 的实例共用额度；
+// 同一账户凭证多次调用共用一个交易实例与一条订单流连接（见 registry.go），可在一个 API Key 上运行多个交易对的网格
 func NewExchange(cfg *config.Config) (IExchange, error) {
+	return defaultRegistry.get(cfg)
+}
+
+// newExchange 按配置创建交易所实例，symbol 为适配器默认交易对
+func newExchange(cfg *config.Config, symbol string) (IExchange, error) {
 	exchangeName := cfg.App.CurrentExchange
 
+	// 现货模式：仅 binance/bitget/gate 支持，适配器内部切换到现货接口
+	spot := cfg.Trading.MarketType == "spot"
+	if spot && exchangeName != "binance" && exchangeName != "bitget" && exchangeName != "gate" {
+		return nil, fmt.Errorf("%s 暂不支持现货网格（支持 binance/bitget/gate）", exchangeName)
+	}
+
+	// 币本位合约：binance 使用币本位接口（dapi），gate 使用 BTC 结算合约
+	inverse := cfg.Trading.MarketType == "inverse"
+	if inverse && exchangeName != "binance" && exchangeName != "gate" {
+		return nil, fmt.Errorf("%s 暂不支持币本位合约（支持 binance/gate）", exchangeName)
+	}
+
 	switch exchangeName {
 	case "bitget":
 		exchangeCfg, exists := cfg.Exchanges["bitget"]
@@ -20,15 +45,21 @@ func NewExchange(cfg *config.Config) (IExchange, error) {
 		}
 		// 将 ExchangeConfig 转换为 map[string]string
 		cfgMap := map[string]string{
-			"api_key":    exchangeCfg.APIKey,
-			"secret_key": exchangeCfg.SecretKey,
-			"passphrase": exchangeCfg.Passphrase,
+			"api_key":        exchangeCfg.APIKey,
+			"secret_key":     exchangeCfg.SecretKey,
+			"passphrase":     exchangeCfg.Passphrase,
+			"ws_order_entry": strconv.FormatBool(exchangeCfg.WSOrderEntry),
+			"environment":    exchangeCfg.Environment,
+		}
+		newAdapter := bitget.NewBitgetAdapter
+		if spot {
+			newAdapter = bitget.NewBitgetSpotAdapter
 		}
-		adapter, err := bitget.NewBitgetAdapter(cfgMap, cfg.Trading.Symbol)
+		adapter, err := newAdapter(cfgMap, symbol)
 		if err != nil {
 			return nil, err
 		}
-		return &bitgetWrapper{adapter: adapter}, nil
+		return newGovernedExchange(&bitgetWrapper{adapter: adapter}, governorFor("bitget", exchangeCfg.APIKey)), nil
 
 	case "binance":
 		exchangeCfg, exists := cfg.Exchanges["binance"]
@@ -36,14 +67,25 @@ func NewExchange(cfg *config.Config) (IExchange, error) {
 			return nil, fmt.Errorf("binance 配置不存在")
 		}
 		cfgMap := map[string]string{
-			"api_key":    exchangeCfg.APIKey,
-			"secret_key": exchangeCfg.SecretKey,
+			"api_key":        exchangeCfg.APIKey,
+			"secret_key":     exchangeCfg.SecretKey,
+			"ws_order_entry": strconv.FormatBool(exchangeCfg.WSOrderEntry),
+			"environment":    exchangeCfg.Environment,
+		}
+		newAdapter := binance.NewBinanceAdapter
+		if spot {
+			newAdapter = binance.NewBinanceSpotAdapter
+		} else if inverse {
+			newAdapter = binance.NewBinanceInverseAdapter
 		}
-		adapter, err := binance.NewBinanceAdapter(cfgMap, cfg.Trading.Symbol)
+		adapter, err := newAdapter(cfgMap, symbol)
 		if err != nil {
 			return nil, err
 		}
-		return &binanceWrapper{adapter: adapter}, nil
+		governor := governorFor("binance", exchangeCfg.APIKey)
+		adapter.SetRateLimitObserver(governor)
+		adapter.SetRequestLimiter(governor)
+		return newGovernedExchange(&binanceWrapper{adapter: adapter}, governor), nil
 
 	case "gate":
 		exchangeCfg, exists := cfg.Exchanges["gate"]
@@ -51,21 +93,95 @@ func NewExchange(cfg *config.Config) (IExchange, error) {
 			return nil, fmt.Errorf("gate 配置不存在")
 		}
 		cfgMap := map[string]string{
-			"api_key":    exchangeCfg.APIKey,
-			"secret_key": exchangeCfg.SecretKey,
-			"settle":     "usdt", // 默认 USDT 永续合约
+			"api_key":        exchangeCfg.APIKey,
+			"secret_key":     exchangeCfg.SecretKey,
+			"settle":         "usdt", // 默认 USDT 永续合约
+			"ws_order_entry": strconv.FormatBool(exchangeCfg.WSOrderEntry),
+			"environment":    exchangeCfg.Environment,
+		}
+		if inverse {
+			cfgMap["settle"] = "btc" // 币本位合约以 BTC 结算
+		}
+		newAdapter := gate.NewGateAdapter
+		if spot {
+			newAdapter = gate.NewGateSpotAdapter
 		}
-		adapter, err := gate.NewGateAdapter(cfgMap, cfg.Trading.Symbol)
+		adapter, err := newAdapter(cfgMap, symbol)
 		if err != nil {
 			return nil, err
 		}
-		return &gateWrapper{adapter: adapter}, nil
+		governor := governorFor("gate", exchangeCfg.APIKey)
+		adapter.SetRequestLimiter(governor)
+		return newGovernedExchange(&gateWrapper{adapter: adapter}, governor), nil
 
 	case "bybit":
-		return nil, fmt.Errorf("bybit 尚未实现")
+		exchangeCfg, exists := cfg.Exchanges["bybit"]
+		if !exists {
+			return nil, fmt.Errorf("bybit 配置不存在")
+		}
+		cfgMap := map[string]string{
+			"api_key":     exchangeCfg.APIKey,
+			"secret_key":  exchangeCfg.SecretKey,
+			"environment": exchangeCfg.Environment,
+		}
+		adapter, err := bybit.NewBybitAdapter(cfgMap, symbol)
+		if err != nil {
+			return nil, err
+		}
+		return newGovernedExchange(newBybitWrapper(adapter), governorFor("bybit", exchangeCfg.APIKey)), nil
+
+	case "okx":
+		exchangeCfg, exists := cfg.Exchanges["okx"]
+		if !exists {
+			return nil, fmt.Errorf("okx 配置不存在")
+		}
+		cfgMap := map[string]string{
+			"api_key":     exchangeCfg.APIKey,
+			"secret_key":  exchangeCfg.SecretKey,
+			"passphrase":  exchangeCfg.Passphrase,
+			"environment": exchangeCfg.Environment,
+		}
+		adapter, err := okx.NewOKXAdapter(cfgMap, symbol)
+		if err != nil {
+			return nil, err
+		}
+		governor := governorFor("okx", exchangeCfg.APIKey)
+		adapter.SetRequestLimiter(governor)
+		return newGovernedExchange(newOKXWrapper(adapter), governor), nil
 
 	case "edgex":
-		return nil, fmt.Errorf("edgeX 尚未实现")
+		exchangeCfg, exists := cfg.Exchanges["edgex"]
+		if !exists {
+			return nil, fmt.Errorf("edgex 配置不存在")
+		}
+		// edgeX: api_key 为账户ID（accountId），secret_key 为 Stark L2 私钥
+		cfgMap := map[string]string{
+			"api_key":     exchangeCfg.APIKey,
+			"secret_key":  exchangeCfg.SecretKey,
+			"environment": exchangeCfg.Environment,
+		}
+		adapter, err := edgex.NewEdgeXAdapter(cfgMap, symbol)
+		if err != nil {
+			return nil, err
+		}
+		return newGovernedExchange(newEdgeXWrapper(adapter), governorFor("edgex", exchangeCfg.APIKey)), nil
+
+	case "hyperliquid":
+		exchangeCfg, exists := cfg.Exchanges["hyperliquid"]
+		if !exists {
+			return nil, fmt.Errorf("hyperliquid 配置不存在")
+		}
+		// Hyperliquid: api_key 为主账户钱包地址，secret_key 为 API（agent）钱包私钥
+		cfgMap := map[string]string{
+			"api_key":     exchangeCfg.APIKey,
+			"secret_key":  exchangeCfg.SecretKey,
+			"environment": exchangeCfg.Environment,
+		}
+		adapter, err := hyperliquid.NewHyperliquidAdapter(cfgMap, symbol)
+		if err != nil {
+			return nil, err
+		}
+		return newGovernedExchange(newHyperliquidWrapper(adapter), governorFor("hyperliquid", exchangeCfg.APIKey)), nil
 
 	default:
 		return nil, fmt.Errorf("不支持的交易所: %s", exchangeName)
@@ -2,12 +2,18 @@ package gate
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"opensqt/exchange/clock"
+	"opensqt/exchange/errs"
+	"opensqt/exchange/wsapi"
 	"opensqt/logger"
 	"opensqt/utils"
 )
@@ -22,23 +28,37 @@ type GateAdapter struct {
 	symbol         string // 交易对（如 BTCUSDT）
 	gateSymbol     string // Gate格式（如 BTC_USDT）
 	settle         string // 结算币种：usdt 或 btc
-	useWebSocket   bool   // 是否使用 WebSocket 下单
+	useWebSocket   bool   // 是否使用 WebSocket 下单（连接不可用时改用 REST）
+	latency        *wsapi.LatencyRecorder
+	clock          *clock.Estimator // 服务器时间偏差（校正签名时间戳）
+	limiter        RequestLimiter   // 账户级请求额度（内部发起的 REST 请求使用），nil 表示不限制
 
 	// 订单ID到价格的映射注册回调
 	orderMappingCallback func(orderID int64, price float64)
 
-	posMode          string  // 持仓模式：dual_long_short 或 single
-	quantoMultiplier float64 // 合约乘数
-	orderPriceRound  int     // 价格精度
-	orderSizeMin     float64 // 最小下单数量
-	volumePlace      int     // 数量小数位
-	pricePlace       int     // 价格小数位
+	posMode string // 持仓模式：dual_long_short 或 single（账户级别）
+	spot    bool   // 现货模式（见 spot.go）
+	testnet bool   // 合约测试网
+
+	// 合约信息：构造时加载默认交易对，其他交易对首次使用时按需加载
+	metaMu sync.RWMutex
+	metas  map[string]*symbolMeta // 标准交易对（如 BTCUSDT） -> 合约信息
 
 	priceCacheMu   sync.RWMutex
 	priceCache     float64
 	priceCacheTime time.Time
 }
 
+// symbolMeta 单个合约的下单参数
+type symbolMeta struct {
+	symbol           string  // 标准格式（如 BTCUSDT）
+	gateSymbol       string  // Gate格式（如 BTC_USDT）
+	quantoMultiplier float64 // 合约乘数
+	orderSizeMin     float64 // 最小下单张数
+	volumePlace      int     // 数量小数位
+	pricePlace       int     // 价格小数位
+}
+
 // NewGateAdapter 创建 Gate.io 适配器
 func NewGateAdapter(cfg map[string]string, symbol string) (*GateAdapter, error) {
 	apiKey := cfg["api_key"]
@@ -52,12 +72,22 @@ func NewGateAdapter(cfg map[string]string, symbol string) (*GateAdapter, error)
 	if settle == "" {
 		settle = "usdt" // 默认 USDT 永续合约
 	}
+	testnet, err := parseEnvironment(cfg["environment"])
+	if err != nil {
+		return nil, err
+	}
 
 	// 转换交易对格式
 	gateSymbol := convertToGateSymbol(symbol)
 
 	client := NewClient(apiKey, secretKey)
 	wsManager := NewWebSocketManager(apiKey, secretKey, settle)
+	wsManager.orderEntry = cfg["ws_order_entry"] == "true"
+	if testnet {
+		client.baseURL = GateTestnetBaseURL
+		wsManager.testnet = true
+		logger.Info("ℹ️ [Gate] 使用合约测试网")
+	}
 
 	adapter := &GateAdapter{
 		client:       client,
@@ -65,20 +95,28 @@ func NewGateAdapter(cfg map[string]string, symbol string) (*GateAdapter, error)
 		symbol:       symbol,
 		gateSymbol:   gateSymbol,
 		settle:       settle,
-		useWebSocket: false, // 默认使用 REST API 下单
+		testnet:      testnet,
+		useWebSocket: wsManager.orderEntry, // 默认使用 REST API 下单，配置 ws_order_entry 后使用 WebSocket
+		latency:      wsapi.NewLatencyRecorder("Gate"),
+		metas:        make(map[string]*symbolMeta),
 	}
+	adapter.startClock()
 
 	// 初始化获取合约信息和持仓模式
 	ctxInit, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	// 1. 获取合约信息
-	if err := adapter.fetchContractInfo(ctxInit); err != nil {
+	if _, err := adapter.symbolMeta(ctxInit, symbol); err != nil {
 		logger.Warn("⚠️ [Gate] 获取合约信息失败: %v", err)
 		// 使用默认值
-		adapter.volumePlace = 0
-		adapter.pricePlace = 2
-		adapter.orderSizeMin = 1
+		adapter.metas[convertFromGateSymbol(symbol)] = &symbolMeta{
+			symbol:       convertFromGateSymbol(symbol),
+			gateSymbol:   gateSymbol,
+			volumePlace:  0,
+			pricePlace:   2,
+			orderSizeMin: 1,
+		}
 	}
 
 	// 2. 获取账户信息（判断持仓模式）
@@ -108,84 +146,207 @@ func (g *GateAdapter) GetName() string {
 	return "Gate.io"
 }
 
+// startClock 创建对时器并注入 REST 与 WebSocket 签名，立即对时一次后定期对时
+func (g *GateAdapter) startClock() {
+	g.clock = clock.NewEstimator("Gate", g.client.ServerTime)
+	g.client.signer.clock = g.clock
+	g.wsManager.signer.clock = g.clock
+	g.clock.Start(context.Background(), clock.DefaultSyncInterval)
+}
+
+// ClockSkew 本地时钟相对服务器的偏差（服务器时间 - 本地时间）
+func (g *GateAdapter) ClockSkew() time.Duration {
+	return g.clock.Offset()
+}
+
+// SyncServerTime 立即与服务器对时
+func (g *GateAdapter) SyncServerTime(ctx context.Context) error {
+	return g.clock.Sync(ctx)
+}
+
 // GetPriceDecimals 获取价格精度
 func (g *GateAdapter) GetPriceDecimals() int {
-	return g.pricePlace
+	return g.defaultMeta().pricePlace
 }
 
 // GetQuantityDecimals 获取数量精度
 func (g *GateAdapter) GetQuantityDecimals() int {
-	return g.volumePlace
+	return g.defaultMeta().volumePlace
 }
 
-// fetchContractInfo 获取合约信息
-func (g *GateAdapter) fetchContractInfo(ctx context.Context) error {
-	contract, err := g.client.GetContract(ctx, g.settle, g.gateSymbol)
-	if err != nil {
-		return fmt.Errorf("获取合约信息失败: %w", err)
+// IsInverse 是否为币本位合约（BTC 结算）
+func (g *GateAdapter) IsInverse() bool {
+	return !g.spot && g.settle == "btc"
+}
+
+// symbolMeta 获取合约下单参数，首次使用时从交易所加载并缓存
+func (g *GateAdapter) symbolMeta(ctx context.Context, symbol string) (*symbolMeta, error) {
+	symbol = convertFromGateSymbol(symbol)
+
+	g.metaMu.RLock()
+	meta, ok := g.metas[symbol]
+	g.metaMu.RUnlock()
+	if ok {
+		return meta, nil
 	}
 
-	// 解析合约乘数
-	if contract.QuantoMultiplier != "" {
-		g.quantoMultiplier, _ = strconv.ParseFloat(contract.QuantoMultiplier, 64)
+	info, err := g.GetSymbolInfo(ctx, symbol)
+	if err != nil {
+		return nil, err
 	}
 
-	// 解析价格精度（如 "0.1" -> 1位小数）
-	if contract.OrderPriceRound != "" {
-		priceRound, _ := strconv.ParseFloat(contract.OrderPriceRound, 64)
-		g.pricePlace = calculateDecimalPlaces(priceRound)
+	meta = &symbolMeta{
+		symbol:           symbol,
+		gateSymbol:       convertToGateSymbol(symbol),
+		quantoMultiplier: info.ContractMultiplier,
+		pricePlace:       info.PriceDecimals,
+		// Gate.io 按张下单，数量精度由合约乘数决定（如每张 0.0001 BTC -> 4位小数）
+		volumePlace:  info.QuantityDecimals,
+		orderSizeMin: info.MinQty / info.ContractMultiplier,
 	}
 
-	// 解析数量精度
-	// Gate.io 的 order_size_round 字段可能为空,需要推断精度
-	if contract.OrderSizeRound != "" {
-		sizeRound, _ := strconv.ParseFloat(contract.OrderSizeRound, 64)
-		g.volumePlace = calculateDecimalPlaces(sizeRound)
-	} else {
-		// 如果 order_size_round 为空,根据 order_size_min 推断
-		// 对于 USDT 永续合约,通常支持小数下单
-		// ETH_USDT 等主流币种一般支持 0.01 精度(2位小数)
-		minSize := contract.OrderSizeMin
-		if minSize >= 1 {
-			// 最小量 >= 1,通常是整数合约(如 BTC)
-			// 但也可能支持小数,使用 0.01 精度较安全
-			g.volumePlace = 2 // 默认2位小数
-		} else {
-			// 最小量 < 1,根据最小量计算精度
-			g.volumePlace = calculateDecimalPlaces(minSize)
-		}
+	g.metaMu.Lock()
+	g.metas[symbol] = meta
+	g.metaMu.Unlock()
+
+	logger.Info("ℹ️ [Gate 合约信息] %s, 每张合约:%g, 价格精度:%d, 数量精度:%d, 最小下单量:%g (%.0f张)",
+		meta.gateSymbol, meta.quantoMultiplier, meta.pricePlace, meta.volumePlace, info.MinQty, meta.orderSizeMin)
+
+	return meta, nil
+}
+
+// contractsToQty 合约张数换算为币数量（与下单、订单推送的数量口径一致）
+func (m *symbolMeta) contractsToQty(contracts int64) float64 {
+	qty := abs(float64(contracts))
+	if m.quantoMultiplier > 0 {
+		qty *= m.quantoMultiplier
 	}
+	return qty
+}
 
-	// 最小下单数量
-	g.orderSizeMin = contract.OrderSizeMin
+// defaultMeta 默认交易对的下单参数（构造时已加载或填入默认值）
+func (g *GateAdapter) defaultMeta() *symbolMeta {
+	g.metaMu.RLock()
+	defer g.metaMu.RUnlock()
+	return g.metas[convertFromGateSymbol(g.symbol)]
+}
 
-	// 计算实际最小下单量(张数 × 乘数 = 实际币数量)
-	actualMinSize := g.orderSizeMin * g.quantoMultiplier
-	if actualMinSize == 0 {
-		actualMinSize = g.orderSizeMin // 如果乘数为0,直接用张数
+// GetSymbolInfo 获取合约交易规则（数量已按合约乘数换算为币数量）
+func (g *GateAdapter) GetSymbolInfo(ctx context.Context, symbol string) (*SymbolInfo, error) {
+	if g.spot {
+		return g.spotSymbolInfo(ctx, symbol)
+	}
+	contract, err := g.client.GetContract(ctx, g.settle, convertToGateSymbol(symbol))
+	if err != nil {
+		return nil, fmt.Errorf("获取合约信息失败: %w", err)
 	}
 
-	logger.Info("ℹ️ [Gate 合约信息] %s, 每张合约:%.2f, 价格精度:%d, 数量精度:%d, 最小下单量:%.2f (%.0f张)",
-		g.gateSymbol, g.quantoMultiplier, g.pricePlace, g.volumePlace, actualMinSize, g.orderSizeMin)
+	multiplier, _ := strconv.ParseFloat(contract.QuantoMultiplier, 64)
+	if multiplier <= 0 {
+		multiplier = 1 // 无乘数时按数量直接下单
+	}
+	// order_size_round 为空时合约张数必须为整数
+	sizeRound, _ := strconv.ParseFloat(contract.OrderSizeRound, 64)
+	if sizeRound <= 0 {
+		sizeRound = 1
+	}
 
-	return nil
+	info := &SymbolInfo{
+		Symbol:             symbol,
+		StepSize:           sizeRound * multiplier,
+		MinQty:             contract.OrderSizeMin * multiplier,
+		ContractMultiplier: multiplier,
+	}
+	if parts := strings.SplitN(contract.Name, "_", 2); len(parts) == 2 {
+		info.BaseAsset, info.QuoteAsset = parts[0], parts[1]
+	}
+	if contract.Type == "inverse" {
+		// 币本位合约（BTC 结算，如 BTC_USD）没有合约乘数，每张面值 1 USD，数量口径为张数
+		info.ContractValue = 1
+	}
+	info.TickSize, _ = strconv.ParseFloat(contract.OrderPriceRound, 64)
+	info.MaxLeverage, _ = strconv.Atoi(contract.LeverageMax)
+	info.PriceDecimals = calculateDecimalPlaces(info.TickSize)
+	info.QuantityDecimals = calculateDecimalPlaces(info.StepSize)
+	return info, nil
 }
 
 // PlaceOrder 下单
 func (g *GateAdapter) PlaceOrder(ctx context.Context, req *OrderRequest) (*Order, error) {
-	// 使用 REST API 下单（更可靠）
-	return g.placeOrderViaREST(ctx, req)
+	if g.spot {
+		return g.spotPlaceOrder(ctx, req)
+	}
+
+	meta, err := g.symbolMeta(ctx, req.Symbol)
+	if err != nil {
+		return nil, fmt.Errorf("获取 %s 合约信息失败: %w", req.Symbol, err)
+	}
+	order, err := g.buildFuturesOrder(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// WebSocket 下单：连接不可用（未发出请求）时改用 REST；
+	// 已发出但超时/断开时先按自定义订单ID（text）查单，确认交易所没有收到再改用 REST
+	if g.useWebSocket {
+		start := time.Now()
+		futuresOrder, err := g.wsManager.PlaceOrder(ctx, order)
+		if err == nil {
+			g.latency.Record(wsapi.PathWebSocket, time.Since(start))
+			return convertFuturesOrder(meta, futuresOrder, req.Price), nil
+		}
+		switch {
+		case errors.Is(err, wsapi.ErrNotConnected):
+			logger.Debug("[Gate] WebSocket 下单不可用，改用 REST: %v", err)
+		case errors.Is(err, wsapi.ErrTimeout), errors.Is(err, wsapi.ErrDisconnected):
+			text, _ := order["text"].(string)
+			if text == "" {
+				return nil, fmt.Errorf("%w（订单没有自定义ID，无法确认是否已受理）", err)
+			}
+			futuresOrder, lookupErr := g.client.GetOrder(ctx, g.settle, text)
+			if lookupErr == nil {
+				logger.Warn("⚠️ [Gate] WebSocket 下单未收到确认，查单确认已受理: %s", text)
+				return convertFuturesOrder(meta, futuresOrder, req.Price), nil
+			}
+			if !errors.Is(lookupErr, errs.ErrOrderNotFound) {
+				return nil, fmt.Errorf("%w（查单确认失败: %v）", err, lookupErr)
+			}
+			logger.Warn("⚠️ [Gate] WebSocket 下单未送达交易所，改用 REST: %v", err)
+		default:
+			return nil, err
+		}
+	}
+
+	return g.placeOrderViaREST(ctx, meta, order, req.Price)
 }
 
 // placeOrderViaREST 通过 REST API 下单
-func (g *GateAdapter) placeOrderViaREST(ctx context.Context, req *OrderRequest) (*Order, error) {
+func (g *GateAdapter) placeOrderViaREST(ctx context.Context, meta *symbolMeta, order map[string]interface{}, price float64) (*Order, error) {
+	start := time.Now()
+	futuresOrder, err := g.client.PlaceOrder(ctx, g.settle, order)
+	if err != nil {
+		// 错误已在 client 中按标签分类（保证金不足、PostOnly 被拒等）
+		return nil, err
+	}
+	g.latency.Record(wsapi.PathREST, time.Since(start))
+
+	return convertFuturesOrder(meta, futuresOrder, price), nil
+}
+
+// buildFuturesOrder 构造合约下单参数（REST 与 WebSocket 下单共用）
+func (g *GateAdapter) buildFuturesOrder(ctx context.Context, req *OrderRequest) (map[string]interface{}, error) {
+	meta, err := g.symbolMeta(ctx, req.Symbol)
+	if err != nil {
+		return nil, fmt.Errorf("获取 %s 合约信息失败: %w", req.Symbol, err)
+	}
+
 	// Gate.io 的 size 是张数,需要从实际币数量换算
 	// 如果合约乘数为 0,则直接使用数量
 	var contractSize int64
-	if g.quantoMultiplier > 0 {
+	if meta.quantoMultiplier > 0 {
 		// 计算张数 = 实际数量 / 每张合约数量
-		contracts := req.Quantity / g.quantoMultiplier
-		contractSize = int64(contracts)
+		contracts := req.Quantity / meta.quantoMultiplier
+		contractSize = int64(contracts + 1e-9) // 容忍浮点误差（如 0.0003/0.0001=2.9999999）
 		// 如果小于1张,至少下1张
 		if contractSize == 0 && req.Quantity > 0 {
 			contractSize = 1
@@ -206,7 +367,7 @@ func (g *GateAdapter) placeOrderViaREST(ctx context.Context, req *OrderRequest)
 	}
 
 	// 格式化价格
-	priceStr := fmt.Sprintf("%.*f", g.pricePlace, req.Price)
+	priceStr := fmt.Sprintf("%.*f", meta.pricePlace, req.Price)
 
 	// Gate.io 要求 text 字段必须以 "t-" 开头,且长度不超过30个字符
 	// 使用统一的 utils 包添加返佣前缀（会自动处理长度限制）
@@ -217,7 +378,7 @@ func (g *GateAdapter) placeOrderViaREST(ctx context.Context, req *OrderRequest)
 
 	// 构造订单参数
 	order := map[string]interface{}{
-		"contract": g.gateSymbol,
+		"contract": meta.gateSymbol,
 		"size":     size,
 		"price":    priceStr,
 		"tif":      "gtc", // Good Till Cancel
@@ -225,7 +386,12 @@ func (g *GateAdapter) placeOrderViaREST(ctx context.Context, req *OrderRequest)
 	}
 
 	// 只减仓标记 (Gate.io 使用 reduce_only,不需要 close 标记)
-	if req.ReduceOnly {
+	// 双向持仓模式按持仓方向判断开平仓：卖出平多、买入平空，平仓单必须带 reduce_only
+	reduceOnly := req.ReduceOnly
+	if req.PositionSide != "" {
+		reduceOnly = (req.PositionSide == "LONG") == (req.Side == SideSell)
+	}
+	if reduceOnly {
 		order["reduce_only"] = true
 	}
 
@@ -234,26 +400,20 @@ func (g *GateAdapter) placeOrderViaREST(ctx context.Context, req *OrderRequest)
 		order["tif"] = "poc" // Post Only
 	}
 
-	// 发送下单请求
-	futuresOrder, err := g.client.PlaceOrder(ctx, g.settle, order)
-	if err != nil {
-		// 检查是否保证金不足
-		if strings.Contains(err.Error(), "insufficient") || strings.Contains(err.Error(), "balance") {
-			return nil, fmt.Errorf("保证金不足: %w", err)
-		}
-		return nil, err
-	}
+	return order, nil
+}
 
-	// 转换为标准订单格式
+// convertFuturesOrder 将下单响应转换为标准订单格式（价格使用委托价，数量换算为币数量）
+func convertFuturesOrder(meta *symbolMeta, futuresOrder *FuturesOrder, price float64) *Order {
 	result := &Order{
 		OrderID:       futuresOrder.ID,
 		ClientOrderID: futuresOrder.Text,
-		Symbol:        g.symbol,
+		Symbol:        convertFromGateSymbol(futuresOrder.Contract),
 		Side:          convertSide(float64(futuresOrder.Size)),
 		Type:          OrderTypeLimit,
-		Price:         req.Price,
-		Quantity:      abs(float64(futuresOrder.Size)),
-		ExecutedQty:   abs(float64(futuresOrder.FillSize)),
+		Price:         price,
+		Quantity:      meta.contractsToQty(futuresOrder.Size),
+		ExecutedQty:   meta.contractsToQty(futuresOrder.FillSize),
 		Status:        convertStatus(futuresOrder.Status),
 		CreatedAt:     time.Unix(int64(futuresOrder.CreateTime), 0),
 		UpdateTime:    int64(futuresOrder.FinishTime * 1000),
@@ -264,11 +424,14 @@ func (g *GateAdapter) placeOrderViaREST(ctx context.Context, req *OrderRequest)
 		result.AvgPrice, _ = strconv.ParseFloat(futuresOrder.FillPrice, 64)
 	}
 
-	return result, nil
+	return result
 }
 
 // BatchPlaceOrders 批量下单
 func (g *GateAdapter) BatchPlaceOrders(ctx context.Context, orders []*OrderRequest) ([]*Order, bool) {
+	if g.spot {
+		return g.spotBatchPlaceOrders(ctx, orders)
+	}
 	placedOrders := make([]*Order, 0, len(orders))
 	hasMarginError := false
 
@@ -277,8 +440,9 @@ func (g *GateAdapter) BatchPlaceOrders(ctx context.Context, orders []*OrderReque
 		if err != nil {
 			logger.Warn("⚠️ [Gate] 下单失败 %.2f %s: %v",
 				orderReq.Price, orderReq.Side, err)
+			errs.Report(ctx, err)
 
-			if strings.Contains(err.Error(), "保证金不足") {
+			if errors.Is(err, errs.ErrInsufficientMargin) {
 				hasMarginError = true
 			}
 			continue
@@ -301,11 +465,14 @@ func (g *GateAdapter) BatchPlaceOrders(ctx context.Context, orders []*OrderReque
 
 // CancelOrder 取消订单
 func (g *GateAdapter) CancelOrder(ctx context.Context, symbol string, orderID int64) error {
+	if g.spot {
+		return g.spotCancelOrder(ctx, symbol, orderID)
+	}
 	orderIDStr := strconv.FormatInt(orderID, 10)
 	_, err := g.client.CancelOrder(ctx, g.settle, orderIDStr)
 	if err != nil {
 		// 订单不存在不算错误
-		if strings.Contains(err.Error(), "ORDER_NOT_FOUND") || strings.Contains(err.Error(), "not found") {
+		if errors.Is(err, errs.ErrOrderNotFound) {
 			logger.Info("ℹ️ [Gate] 订单 %d 已不存在，跳过取消", orderID)
 			return nil
 		}
@@ -316,11 +483,76 @@ func (g *GateAdapter) CancelOrder(ctx context.Context, symbol string, orderID in
 	return nil
 }
 
+// AmendOrder 改单（修改挂单的价格/数量，订单ID不变）
+// newQty 为改单后的订单总数量（币数量，含已成交部分），<= 0 时只改价格；
+// Gate.io 的 size 为带方向的张数，因此先查询原订单确定方向
+func (g *GateAdapter) AmendOrder(ctx context.Context, symbol string, orderID int64, newPrice, newQty float64) (*Order, error) {
+	if g.spot {
+		return nil, errSpotUnsupported("改单")
+	}
+	meta, err := g.symbolMeta(ctx, symbol)
+	if err != nil {
+		return nil, fmt.Errorf("获取 %s 合约信息失败: %w", symbol, err)
+	}
+
+	amend := map[string]interface{}{
+		"price": fmt.Sprintf("%.*f", meta.pricePlace, newPrice),
+	}
+	if newQty > 0 {
+		if err := g.waitRequest(ctx, "GetOrder", 1); err != nil {
+			return nil, err
+		}
+		original, err := g.GetOrder(ctx, symbol, orderID)
+		if err != nil {
+			return nil, fmt.Errorf("查询原订单失败: %w", err)
+		}
+		contractSize := int64(newQty)
+		if meta.quantoMultiplier > 0 {
+			contractSize = int64(newQty/meta.quantoMultiplier + 1e-9)
+		}
+		if contractSize == 0 {
+			contractSize = 1
+		}
+		if original.Side == SideSell {
+			contractSize = -contractSize
+		}
+		amend["size"] = contractSize
+	}
+
+	futuresOrder, err := g.client.AmendOrder(ctx, g.settle, strconv.FormatInt(orderID, 10), amend)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Order{
+		OrderID:       futuresOrder.ID,
+		ClientOrderID: futuresOrder.Text,
+		Symbol:        convertFromGateSymbol(futuresOrder.Contract),
+		Side:          convertSide(float64(futuresOrder.Size)),
+		Type:          OrderTypeLimit,
+		Price:         newPrice,
+		Quantity:      meta.contractsToQty(futuresOrder.Size),
+		ExecutedQty:   meta.contractsToQty(futuresOrder.FillSize),
+		Status:        convertStatus(futuresOrder.Status),
+		CreatedAt:     time.Unix(int64(futuresOrder.CreateTime), 0),
+		UpdateTime:    int64(futuresOrder.FinishTime * 1000),
+	}
+	if futuresOrder.Price != "" {
+		result.Price, _ = strconv.ParseFloat(futuresOrder.Price, 64)
+	}
+
+	logger.Info("✅ [Gate] 改单成功: %d 价格 -> %s", orderID, amend["price"])
+	return result, nil
+}
+
 // BatchCancelOrders 批量取消订单
 func (g *GateAdapter) BatchCancelOrders(ctx context.Context, symbol string, orderIDs []int64) error {
 	if len(orderIDs) == 0 {
 		return nil
 	}
+	if g.spot {
+		return g.spotBatchCancelOrders(ctx, symbol, orderIDs)
+	}
 
 	// Gate.io 批量撤单API一次最多20个
 	for i := 0; i < len(orderIDs); i += 20 {
@@ -379,6 +611,13 @@ func (g *GateAdapter) BatchCancelOrders(ctx context.Context, symbol string, orde
 
 // GetOrder 查询订单
 func (g *GateAdapter) GetOrder(ctx context.Context, symbol string, orderID int64) (*Order, error) {
+	if g.spot {
+		return g.spotGetOrder(ctx, symbol, orderID)
+	}
+	meta, err := g.symbolMeta(ctx, symbol)
+	if err != nil {
+		return nil, fmt.Errorf("获取 %s 合约信息失败: %w", symbol, err)
+	}
 	orderIDStr := strconv.FormatInt(orderID, 10)
 	futuresOrder, err := g.client.GetOrder(ctx, g.settle, orderIDStr)
 	if err != nil {
@@ -389,11 +628,11 @@ func (g *GateAdapter) GetOrder(ctx context.Context, symbol string, orderID int64
 	order := &Order{
 		OrderID:       futuresOrder.ID,
 		ClientOrderID: futuresOrder.Text,
-		Symbol:        g.symbol,
+		Symbol:        convertFromGateSymbol(futuresOrder.Contract),
 		Side:          convertSide(float64(futuresOrder.Size)),
 		Type:          OrderTypeLimit,
-		Quantity:      abs(float64(futuresOrder.Size)),
-		ExecutedQty:   abs(float64(futuresOrder.FillSize)),
+		Quantity:      meta.contractsToQty(futuresOrder.Size),
+		ExecutedQty:   meta.contractsToQty(futuresOrder.FillSize),
 		Status:        convertStatus(futuresOrder.Status),
 		CreatedAt:     time.Unix(int64(futuresOrder.CreateTime), 0),
 		UpdateTime:    int64(futuresOrder.FinishTime * 1000),
@@ -414,7 +653,15 @@ func (g *GateAdapter) GetOrder(ctx context.Context, symbol string, orderID int64
 
 // GetOpenOrders 查询未完成订单
 func (g *GateAdapter) GetOpenOrders(ctx context.Context, symbol string) ([]*Order, error) {
-	futuresOrders, err := g.client.GetOpenOrders(ctx, g.settle, g.gateSymbol)
+	if g.spot {
+		return g.spotGetOpenOrders(ctx, symbol)
+	}
+	meta, err := g.symbolMeta(ctx, symbol)
+	if err != nil {
+		return nil, fmt.Errorf("获取 %s 合约信息失败: %w", symbol, err)
+	}
+
+	futuresOrders, err := g.client.GetOpenOrders(ctx, g.settle, meta.gateSymbol)
 	if err != nil {
 		return nil, err
 	}
@@ -424,11 +671,11 @@ func (g *GateAdapter) GetOpenOrders(ctx context.Context, symbol string) ([]*Orde
 		order := &Order{
 			OrderID:       fo.ID,
 			ClientOrderID: fo.Text,
-			Symbol:        g.symbol,
+			Symbol:        meta.symbol,
 			Side:          convertSide(float64(fo.Size)),
 			Type:          OrderTypeLimit,
-			Quantity:      abs(float64(fo.Size)),
-			ExecutedQty:   abs(float64(fo.FillSize)),
+			Quantity:      meta.contractsToQty(fo.Size),
+			ExecutedQty:   meta.contractsToQty(fo.FillSize),
 			Status:        convertStatus(fo.Status),
 			CreatedAt:     time.Unix(int64(fo.CreateTime), 0),
 			UpdateTime:    int64(fo.FinishTime * 1000),
@@ -452,6 +699,9 @@ func (g *GateAdapter) GetOpenOrders(ctx context.Context, symbol string) ([]*Orde
 
 // GetAccount 获取账户信息
 func (g *GateAdapter) GetAccount(ctx context.Context) (*Account, error) {
+	if g.spot {
+		return g.spotGetAccount(ctx)
+	}
 	futuresAcc, err := g.client.GetAccount(ctx, g.settle)
 	if err != nil {
 		return nil, err
@@ -469,7 +719,7 @@ func (g *GateAdapter) GetAccount(ctx context.Context) (*Account, error) {
 
 	// 获取当前合约的杠杆设置
 	leverage := 1 // 默认1倍
-	if fp, err := g.client.GetPosition(ctx, g.settle, g.gateSymbol); err == nil {
+	if fp, err := g.client.GetPosition(ctx, g.settle, g.defaultMeta().gateSymbol); err == nil {
 		// 检查是否为逐仓模式
 		leverageValue, _ := strconv.Atoi(fp.Leverage)
 		if leverageValue != 0 {
@@ -497,54 +747,92 @@ func (g *GateAdapter) GetAccount(ctx context.Context) (*Account, error) {
 }
 
 // GetPositions 获取持仓信息
+// 双向持仓模式下分别返回多头与空头持仓（空头数量为负数）
 func (g *GateAdapter) GetPositions(ctx context.Context, symbol string) ([]*Position, error) {
-	// 使用单个持仓查询接口获取更详细的信息
-	fp, err := g.client.GetPosition(ctx, g.settle, g.gateSymbol)
+	if g.spot {
+		return g.spotGetPositions(ctx, symbol)
+	}
+	meta, err := g.symbolMeta(ctx, symbol)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("获取 %s 合约信息失败: %w", symbol, err)
 	}
 
-	positions := make([]*Position, 0)
-
-	// 跳过空仓
-	if fp.Size == 0 {
-		return positions, nil
+	var fps []*FuturesPosition
+	if g.posMode == "dual_long_short" {
+		fps, err = g.client.GetDualPositions(ctx, g.settle, meta.gateSymbol)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		// 使用单个持仓查询接口获取更详细的信息
+		fp, err := g.client.GetPosition(ctx, g.settle, meta.gateSymbol)
+		if err != nil {
+			return nil, err
+		}
+		fps = []*FuturesPosition{fp}
 	}
 
-	// 检查是否为逐仓模式
-	leverage, _ := strconv.Atoi(fp.Leverage)
-	if leverage != 0 {
-		logger.Warn("⚠️ [Gate] 当前为逐仓模式(杠杆倍数=%dx),本系统仅支持全仓模式。请在 Gate.io 网站将持仓模式改为全仓", leverage)
-		return nil, fmt.Errorf("不支持逐仓模式,请改为全仓模式")
-	}
+	positions := make([]*Position, 0, len(fps))
+	for _, fp := range fps {
+		// 跳过空仓
+		if fp.Size == 0 {
+			continue
+		}
 
-	// 全仓模式下,从 CrossLeverageLimit 获取杠杆倍数
-	crossLeverage, _ := strconv.Atoi(fp.CrossLeverageLimit)
-	if crossLeverage == 0 {
-		crossLeverage = 1 // 默认1倍
-	}
+		// leverage 为 0 表示全仓，此时杠杆倍数取 cross_leverage_limit
+		leverage, _ := strconv.Atoi(fp.Leverage)
+		marginType := "isolated"
+		if leverage == 0 {
+			marginType = "crossed"
+			leverage, _ = strconv.Atoi(fp.CrossLeverageLimit)
+			if leverage == 0 {
+				leverage = 1 // 默认1倍
+			}
+		}
 
-	entryPrice, _ := strconv.ParseFloat(fp.EntryPrice, 64)
-	markPrice, _ := strconv.ParseFloat(fp.MarkPrice, 64)
-	unrealisedPnl, _ := strconv.ParseFloat(fp.UnrealisedPnl, 64)
+		entryPrice, _ := strconv.ParseFloat(fp.EntryPrice, 64)
+		markPrice, _ := strconv.ParseFloat(fp.MarkPrice, 64)
+		unrealisedPnl, _ := strconv.ParseFloat(fp.UnrealisedPnl, 64)
+		margin, _ := strconv.ParseFloat(fp.Margin, 64)
 
-	position := &Position{
-		Symbol:        g.symbol,
-		Size:          float64(fp.Size),
-		EntryPrice:    entryPrice,
-		MarkPrice:     markPrice,
-		UnrealizedPNL: unrealisedPnl,
-		Leverage:      crossLeverage,
-		MarginType:    "crossed", // 全仓模式
-	}
+		// Gate.io 返回的是合约张数，需要乘以 quanto_multiplier 转换为币数量
+		size := float64(fp.Size)
+		if meta.quantoMultiplier > 0 {
+			size = size * meta.quantoMultiplier
+		}
 
-	positions = append(positions, position)
+		positions = append(positions, &Position{
+			Symbol:         meta.symbol,
+			Size:           size,
+			EntryPrice:     entryPrice,
+			MarkPrice:      markPrice,
+			UnrealizedPNL:  unrealisedPnl,
+			Leverage:       leverage,
+			MarginType:     marginType,
+			IsolatedMargin: margin,
+			Side:           dualPositionSide(fp.Mode),
+		})
+	}
 
 	return positions, nil
 }
 
+// dualPositionSide 将 Gate.io 持仓模式（dual_long/dual_short/single）转换为持仓方向，单向持仓返回空
+func dualPositionSide(mode string) string {
+	switch mode {
+	case "dual_long":
+		return "LONG"
+	case "dual_short":
+		return "SHORT"
+	}
+	return ""
+}
+
 // GetBalance 获取余额
 func (g *GateAdapter) GetBalance(ctx context.Context, asset string) (float64, error) {
+	if g.spot {
+		return g.spotGetBalance(ctx, asset)
+	}
 	acc, err := g.GetAccount(ctx)
 	if err != nil {
 		return 0, err
@@ -554,13 +842,21 @@ func (g *GateAdapter) GetBalance(ctx context.Context, asset string) (float64, er
 
 // StartOrderStream 启动订单流
 func (g *GateAdapter) StartOrderStream(ctx context.Context, callback func(interface{})) error {
-	// 包装回调函数,将合约张数转换为币数量
+	// 包装回调函数,将合约张数转换为币数量（订单流推送账户下所有合约，按各自的合约乘数换算）
 	wrappedCallback := func(update interface{}) {
 		if orderUpdate, ok := update.(OrderUpdate); ok {
-			// Gate.io返回的是合约张数,需要乘以quanto_multiplier转换为币数量
-			if g.quantoMultiplier > 0 {
-				orderUpdate.Quantity = orderUpdate.Quantity * g.quantoMultiplier
-				orderUpdate.ExecutedQty = orderUpdate.ExecutedQty * g.quantoMultiplier
+			metaCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			meta, err := g.symbolMeta(metaCtx, orderUpdate.Symbol)
+			cancel()
+			if err != nil {
+				logger.Warn("⚠️ [Gate] 获取 %s 合约信息失败，订单数量按张数推送: %v", orderUpdate.Symbol, err)
+			} else if meta.quantoMultiplier > 0 {
+				// Gate.io返回的是合约张数,需要乘以quanto_multiplier转换为币数量
+				orderUpdate.Quantity = orderUpdate.Quantity * meta.quantoMultiplier
+				orderUpdate.ExecutedQty = orderUpdate.ExecutedQty * meta.quantoMultiplier
+				if orderUpdate.Fill != nil {
+					orderUpdate.Fill.Quantity = orderUpdate.Fill.Quantity * meta.quantoMultiplier
+				}
 			}
 			callback(orderUpdate)
 		} else {
@@ -578,6 +874,33 @@ func (g *GateAdapter) StartOrderStream(ctx context.Context, callback func(interf
 	return nil
 }
 
+// StartAccountStream 启动账户推送（futures.balances 与 futures.positions）
+// 持仓数量由合约张数换算为币数量，余额推送不含可用余额
+func (g *GateAdapter) StartAccountStream(ctx context.Context, callback AccountUpdateCallback) error {
+	if g.spot {
+		return errSpotUnsupported("账户推送")
+	}
+	g.wsManager.SetAccountCallback(func(update *AccountUpdate) {
+		for _, pos := range update.Positions {
+			metaCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			meta, err := g.symbolMeta(metaCtx, pos.Symbol)
+			cancel()
+			if err != nil {
+				logger.Warn("⚠️ [Gate] 获取 %s 合约信息失败，持仓数量按张数推送: %v", pos.Symbol, err)
+			} else if meta.quantoMultiplier > 0 {
+				pos.Size = pos.Size * meta.quantoMultiplier
+			}
+		}
+		callback(update)
+	})
+
+	// 订单流或价格流已启动连接循环时复用，持仓频道在连接建立时统一订阅
+	if g.wsManager.IsStarted() {
+		return nil
+	}
+	return g.wsManager.Start(ctx, g.symbol)
+}
+
 // StopOrderStream 停止订单流
 func (g *GateAdapter) StopOrderStream() error {
 	return g.wsManager.Stop()
@@ -620,8 +943,207 @@ func (g *GateAdapter) SetOrderMappingCallback(callback func(orderID int64, price
 	g.orderMappingCallback = callback
 }
 
+// SetLeverage 设置交易对杠杆倍数（保持当前的全仓/逐仓模式）
+func (g *GateAdapter) SetLeverage(ctx context.Context, symbol string, leverage int) error {
+	if g.spot {
+		return errSpotUnsupported("杠杆设置")
+	}
+	contract := convertToGateSymbol(symbol)
+	fp, err := g.client.GetPosition(ctx, g.settle, contract)
+	if err != nil {
+		return err
+	}
+
+	// Gate.io 的 leverage 为 0 表示全仓，全仓杠杆由 cross_leverage_limit 决定
+	if fp.Leverage == "0" {
+		err = g.client.UpdatePositionLeverage(ctx, g.settle, contract, 0, leverage)
+	} else {
+		err = g.client.UpdatePositionLeverage(ctx, g.settle, contract, leverage, 0)
+	}
+	if err != nil {
+		return err
+	}
+	logger.Info("✅ [Gate] %s 杠杆倍数已设置为 %dx", contract, leverage)
+	return nil
+}
+
+// SetMarginType 设置交易对保证金模式（逐仓/全仓），切换时沿用当前杠杆倍数
+func (g *GateAdapter) SetMarginType(ctx context.Context, symbol string, isolated bool) error {
+	if g.spot {
+		return errSpotUnsupported("保证金模式设置")
+	}
+	contract := convertToGateSymbol(symbol)
+	fp, err := g.client.GetPosition(ctx, g.settle, contract)
+	if err != nil {
+		return err
+	}
+
+	leverage, _ := strconv.Atoi(fp.Leverage)
+	crossLeverage, _ := strconv.Atoi(fp.CrossLeverageLimit)
+	switch {
+	case isolated && leverage == 0:
+		if crossLeverage <= 0 {
+			crossLeverage = 1
+		}
+		err = g.client.UpdatePositionLeverage(ctx, g.settle, contract, crossLeverage, 0)
+	case !isolated && leverage != 0:
+		err = g.client.UpdatePositionLeverage(ctx, g.settle, contract, 0, leverage)
+	}
+	if err != nil {
+		return err
+	}
+
+	marginMode := "crossed"
+	if isolated {
+		marginMode = "isolated"
+	}
+	logger.Info("✅ [Gate] %s 保证金模式: %s", contract, marginMode)
+	return nil
+}
+
+// SetPositionMode 设置持仓模式（单向/双向，账户级别），有持仓或挂单时交易所会拒绝
+func (g *GateAdapter) SetPositionMode(ctx context.Context, hedge bool) error {
+	if g.spot {
+		return errSpotUnsupported("持仓模式设置")
+	}
+	if err := g.client.SetDualMode(ctx, g.settle, hedge); err != nil {
+		return err
+	}
+
+	g.posMode = "single"
+	if hedge {
+		g.posMode = "dual_long_short"
+	}
+	logger.Info("✅ [Gate] 持仓模式: %s", g.posMode)
+	return nil
+}
+
+// GetFundingRate 获取当期资金费率（合约信息中包含标记价格与下次结算时间）
+func (g *GateAdapter) GetFundingRate(ctx context.Context, symbol string) (*FundingRate, error) {
+	if g.spot {
+		return nil, errSpotUnsupported("资金费率")
+	}
+	contract, err := g.client.GetContract(ctx, g.settle, convertToGateSymbol(symbol))
+	if err != nil {
+		return nil, err
+	}
+
+	fundingRate, _ := strconv.ParseFloat(contract.FundingRate, 64)
+	markPrice, _ := strconv.ParseFloat(contract.MarkPrice, 64)
+	indexPrice, _ := strconv.ParseFloat(contract.IndexPrice, 64)
+	return &FundingRate{
+		Symbol:          convertFromGateSymbol(contract.Name),
+		FundingRate:     fundingRate,
+		MarkPrice:       markPrice,
+		IndexPrice:      indexPrice,
+		NextFundingTime: int64(contract.FundingNextApply * 1000),
+		Time:            time.Now().UnixMilli(),
+	}, nil
+}
+
+// GetMarkPrice 获取标记价格
+func (g *GateAdapter) GetMarkPrice(ctx context.Context, symbol string) (float64, error) {
+	rate, err := g.GetFundingRate(ctx, symbol)
+	if err != nil {
+		return 0, err
+	}
+	return rate.MarkPrice, nil
+}
+
+// StartMarkPriceStream 启动标记价格流（复用 futures.tickers 频道）
+// ticker 只订阅了当前交易对，因此只支持当前交易对
+func (g *GateAdapter) StartMarkPriceStream(ctx context.Context, symbol string, callback MarkPriceCallback) error {
+	if g.spot {
+		return errSpotUnsupported("标记价格流")
+	}
+	if convertToGateSymbol(symbol) != g.gateSymbol {
+		return fmt.Errorf("Gate.io 标记价格流只支持当前交易对 %s", g.symbol)
+	}
+
+	g.wsManager.SetMarkPriceCallback(callback)
+
+	if g.wsManager.IsStarted() {
+		return nil
+	}
+	return g.wsManager.Start(ctx, g.symbol)
+}
+
+// GetFundingPayments 获取 since 之后的资金费收付记录（账户流水中的 fund 类型）
+func (g *GateAdapter) GetFundingPayments(ctx context.Context, symbol string, since time.Time) ([]*FundingPayment, error) {
+	if g.spot {
+		return nil, errSpotUnsupported("资金费")
+	}
+	entries, err := g.client.GetAccountBook(ctx, g.settle, convertToGateSymbol(symbol), "fund", since.Unix())
+	if err != nil {
+		return nil, err
+	}
+
+	payments := make([]*FundingPayment, 0, len(entries))
+	for _, entry := range entries {
+		amount, _ := strconv.ParseFloat(entry.Change, 64)
+		payments = append(payments, &FundingPayment{
+			ID:     entry.ID.String(),
+			Symbol: convertFromGateSymbol(entry.Contract),
+			Amount: amount,
+			Asset:  strings.ToUpper(g.settle),
+			Time:   int64(entry.Time * 1000),
+		})
+	}
+	return payments, nil
+}
+
+// GetMyTrades 获取 since 之后本账户的成交记录（按时间升序，limit 最大 1000，合约数量已换算为币数量）
+func (g *GateAdapter) GetMyTrades(ctx context.Context, symbol string, since time.Time, limit int) ([]*Trade, error) {
+	if limit <= 0 || limit > 1000 {
+		limit = 1000
+	}
+	if g.spot {
+		return g.spotGetMyTrades(ctx, symbol, since, limit)
+	}
+	meta, err := g.symbolMeta(ctx, symbol)
+	if err != nil {
+		return nil, fmt.Errorf("获取 %s 合约信息失败: %w", symbol, err)
+	}
+	futuresTrades, err := g.client.GetMyTrades(ctx, g.settle, meta.gateSymbol, since.Unix(), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	trades := make([]*Trade, 0, len(futuresTrades))
+	for _, ft := range futuresTrades {
+		orderID, _ := strconv.ParseInt(ft.OrderID, 10, 64)
+		price, _ := strconv.ParseFloat(ft.Price, 64)
+		fee, _ := strconv.ParseFloat(ft.Fee, 64)
+		trades = append(trades, &Trade{
+			TradeID:         ft.TradeID.String(),
+			OrderID:         orderID,
+			ClientOrderID:   utils.RemoveBrokerPrefix("gate", ft.Text),
+			Symbol:          convertFromGateSymbol(ft.Contract),
+			Side:            convertSide(float64(ft.Size)),
+			Price:           price,
+			Quantity:        meta.contractsToQty(ft.Size),
+			Commission:      fee,
+			CommissionAsset: strings.ToUpper(g.settle),
+			IsMaker:         ft.Role == "maker",
+			Time:            int64(ft.CreateTime * 1000),
+		})
+	}
+	sortTrades(trades)
+	return trades, nil
+}
+
+// sortTrades 按成交时间升序排列（Gate.io 成交记录按时间倒序返回）
+func sortTrades(trades []*Trade) {
+	sort.Slice(trades, func(i, j int) bool {
+		return trades[i].Time < trades[j].Time
+	})
+}
+
 // GetHistoricalKlines 获取历史K线数据
 func (g *GateAdapter) GetHistoricalKlines(ctx context.Context, symbol string, interval string, limit int) ([]*Candle, error) {
+	if g.spot {
+		return g.spotHistoricalKlines(ctx, symbol, interval, limit)
+	}
 	// 转换交易对格式
 	gateSymbol := convertToGateSymbol(symbol)
 
@@ -666,10 +1188,75 @@ func (g *GateAdapter) GetHistoricalKlines(ctx context.Context, symbol string, in
 	return candles, nil
 }
 
+// GetKlinesBetween 获取开盘时间在 [start, end]（毫秒）内的K线，按时间升序，时间戳统一为毫秒
+// Gate 按秒级 from/to 查询（不能同时指定 limit），合约单次最多 2000 根，现货最多 1000 根，由调用方按上限切分区间
+func (g *GateAdapter) GetKlinesBetween(ctx context.Context, symbol string, interval string, start, end int64) ([]*Candle, error) {
+	gateSymbol := convertToGateSymbol(symbol)
+	from, to := start/1000, end/1000
+
+	var candles []*Candle
+	if g.spot {
+		candlesticks, err := g.client.GetSpotCandlesticksRange(ctx, gateSymbol, interval, from, to)
+		if err != nil {
+			return nil, fmt.Errorf("获取历史K线失败: %w", err)
+		}
+		// 现货返回格式: [时间戳(秒), 成交额, 收盘价, 最高价, 最低价, 开盘价, 成交量, 是否完结]
+		for _, cs := range candlesticks {
+			if len(cs) < 7 {
+				continue // 跳过无效数据
+			}
+			timestamp, _ := strconv.ParseInt(cs[0], 10, 64)
+			close, _ := strconv.ParseFloat(cs[2], 64)
+			high, _ := strconv.ParseFloat(cs[3], 64)
+			low, _ := strconv.ParseFloat(cs[4], 64)
+			open, _ := strconv.ParseFloat(cs[5], 64)
+			volume, _ := strconv.ParseFloat(cs[6], 64)
+			isClosed := len(cs) < 8 || cs[7] == "true"
+
+			candles = append(candles, &Candle{
+				Symbol:    symbol,
+				Open:      open,
+				High:      high,
+				Low:       low,
+				Close:     close,
+				Volume:    volume,
+				Timestamp: timestamp * 1000,
+				IsClosed:  isClosed,
+			})
+		}
+		return candles, nil
+	}
+
+	candlesticks, err := g.client.GetCandlesticksRange(ctx, g.settle, gateSymbol, interval, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("获取历史K线失败: %w", err)
+	}
+	for _, cs := range candlesticks {
+		open, _ := parseFloat(cs.Open)
+		high, _ := parseFloat(cs.High)
+		low, _ := parseFloat(cs.Low)
+		close, _ := parseFloat(cs.Close)
+
+		candles = append(candles, &Candle{
+			Symbol:    symbol,
+			Open:      open,
+			High:      high,
+			Low:       low,
+			Close:     close,
+			Volume:    float64(cs.Volume),
+			Timestamp: cs.Timestamp * 1000,
+			IsClosed:  true, // 历史K线都是已完结的
+		})
+	}
+	return candles, nil
+}
+
 // StartKlineStream 启动K线流
 func (g *GateAdapter) StartKlineStream(ctx context.Context, symbols []string, interval string, callback func(interface{})) error {
 	if g.klineWSManager == nil {
 		g.klineWSManager = NewKlineWebSocketManager(g.settle)
+		g.klineWSManager.spot = g.spot
+		g.klineWSManager.testnet = g.testnet
 	}
 	return g.klineWSManager.Start(ctx, symbols, interval, callback)
 }
@@ -697,26 +1284,18 @@ func (g *GateAdapter) ForceReconnectKlineStream() error {
 	return fmt.Errorf("K线流管理器未初始化")
 }
 
-// calculateDecimalPlaces 计算小数位数
+// calculateDecimalPlaces 计算步长的小数位数（如 0.25 -> 2, 0.0001 -> 4, 1 -> 0）
 func calculateDecimalPlaces(value float64) int {
-	if value >= 1 {
+	if value <= 0 || value >= 1 && value == math.Trunc(value) {
 		return 0
 	}
 
-	str := fmt.Sprintf("%.10f", value)
+	str := strconv.FormatFloat(value, 'f', -1, 64)
 	parts := strings.Split(str, ".")
 	if len(parts) != 2 {
 		return 0
 	}
-
-	// 计算小数点后第一个非零数字的位置
-	for i, c := range parts[1] {
-		if c != '0' {
-			return i + 1
-		}
-	}
-
-	return 0
+	return len(strings.TrimRight(parts[1], "0"))
 }
 
 // convertToBitgetSymbol 转换交易对格式（兼容性函数）
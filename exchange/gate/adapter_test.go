@@ -0,0 +1,206 @@
+package gate
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"opensqt/exchange/errs"
+
+	"github.com/gorilla/websocket"
+)
+
+// newTestAdapter 创建指向本地测试服务器的合约适配器，ETHUSDT 每张合约 0.01 ETH
+func newTestAdapter(t *testing.T, handler http.HandlerFunc) *GateAdapter {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client := NewClient("key", "secret")
+	client.baseURL = server.URL
+	return &GateAdapter{
+		client: client,
+		settle: "usdt",
+		symbol: "ETHUSDT",
+		metas: map[string]*symbolMeta{
+			"ETHUSDT": {symbol: "ETHUSDT", gateSymbol: "ETH_USDT", quantoMultiplier: 0.01, pricePlace: 2},
+		},
+	}
+}
+
+func TestGetMyTradesConvertsContracts(t *testing.T) {
+	adapter := newTestAdapter(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/futures/usdt/my_trades_timerange" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		if q := r.URL.Query(); q.Get("contract") != "ETH_USDT" || q.Get("from") != "1700000000" {
+			t.Errorf("查询参数错误: %s", r.URL.RawQuery)
+		}
+		// 倒序返回，size 为带方向的张数
+		w.Write([]byte(`[
+			{"trade_id":"22","create_time":1700000002.5,"contract":"ETH_USDT","order_id":"101","size":-30,"price":"3010","role":"maker","text":"t-300000_S_1","fee":"-0.001"},
+			{"trade_id":"21","create_time":1700000001.25,"contract":"ETH_USDT","order_id":"100","size":150,"price":"3000","role":"taker","text":"t-300000_B_1","fee":"0.09"}]`))
+	})
+
+	trades, err := adapter.GetMyTrades(context.Background(), "ETHUSDT", time.UnixMilli(1700000000000), 0)
+	if err != nil {
+		t.Fatalf("查询成交记录失败: %v", err)
+	}
+	if len(trades) != 2 || trades[0].TradeID != "21" || trades[1].TradeID != "22" {
+		t.Fatalf("成交记录未按时间升序: %+v", trades)
+	}
+	buy, sell := trades[0], trades[1]
+	if buy.Side != SideBuy || buy.Quantity != 1.5 || buy.ClientOrderID != "300000_B_1" || buy.Time != 1700000001250 || buy.IsMaker {
+		t.Errorf("买入成交解析错误（150 张应换算为 1.5 ETH）: %+v", buy)
+	}
+	if sell.Side != SideSell || sell.Quantity != 0.3 || sell.Commission != -0.001 || !sell.IsMaker {
+		t.Errorf("卖出成交解析错误（30 张应换算为 0.3 ETH）: %+v", sell)
+	}
+}
+
+func TestGetOrderConvertsContracts(t *testing.T) {
+	adapter := newTestAdapter(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/futures/usdt/orders/100" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"id":100,"contract":"ETH_USDT","size":-200,"fill_size":-150,"left":-50,"price":"3000","fill_price":"3000","status":"open","text":"t-300000_S_1"}`))
+	})
+
+	order, err := adapter.GetOrder(context.Background(), "ETHUSDT", 100)
+	if err != nil {
+		t.Fatalf("查询订单失败: %v", err)
+	}
+	if order.Side != SideSell || order.Quantity != 2 || order.ExecutedQty != 1.5 {
+		t.Errorf("订单数量应换算为币数量 2/1.5, 实际 %+v", order)
+	}
+}
+
+// 限频响应携带额度重置时间，改单前的查单经过请求额度管理
+func TestAmendOrderRateLimited(t *testing.T) {
+	resetAt := time.Now().Add(3 * time.Second)
+	adapter := newTestAdapter(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/futures/usdt/orders/100" && r.Method == http.MethodGet {
+			w.Write([]byte(`{"id":100,"contract":"ETH_USDT","size":-200,"price":"3000","status":"open"}`))
+			return
+		}
+		w.Header().Set("X-Gate-RateLimit-Reset-Timestamp", strconv.FormatInt(resetAt.UnixMilli(), 10))
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"label":"TOO_MANY_REQUESTS","message":"Request Rate limit Exceeded"}`))
+	})
+	limiter := &countingLimiter{}
+	adapter.SetRequestLimiter(limiter)
+
+	_, err := adapter.AmendOrder(context.Background(), "ETHUSDT", 100, 3001, 2)
+	if !errors.Is(err, errs.ErrRateLimited) {
+		t.Fatalf("err = %v, want ErrRateLimited", err)
+	}
+	if d := errs.RetryAfter(err); d <= 0 || d > 3*time.Second {
+		t.Errorf("应按额度重置时间等待, got %v", d)
+	}
+	if len(limiter.ops) != 1 || limiter.ops[0] != "GetOrder" {
+		t.Errorf("改单前的查单应等待额度, got %v", limiter.ops)
+	}
+}
+
+// 改单按原订单方向换算为带符号的张数，订单ID不变
+func TestAmendOrderConvertsContracts(t *testing.T) {
+	var amendBody string
+	adapter := newTestAdapter(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/futures/usdt/orders/100":
+			w.Write([]byte(`{"id":100,"contract":"ETH_USDT","size":-200,"price":"3000","status":"open"}`))
+		case r.Method == http.MethodPut && r.URL.Path == "/futures/usdt/orders/100":
+			body, _ := io.ReadAll(r.Body)
+			amendBody = string(body)
+			w.Write([]byte(`{"id":100,"contract":"ETH_USDT","size":-150,"fill_size":0,"price":"3001.5","status":"open","text":"t-grid1"}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+		}
+	})
+
+	order, err := adapter.AmendOrder(context.Background(), "ETHUSDT", 100, 3001.5, 1.5)
+	if err != nil {
+		t.Fatalf("改单失败: %v", err)
+	}
+	if !strings.Contains(amendBody, `"size":-150`) || !strings.Contains(amendBody, `"price":"3001.50"`) {
+		t.Errorf("卖单 1.5 ETH 应换算为 -150 张: %s", amendBody)
+	}
+	if order.OrderID != 100 || order.Side != SideSell || order.Quantity != 1.5 || order.Price != 3001.5 || order.ClientOrderID != "t-grid1" {
+		t.Errorf("改单结果解析错误: %+v", order)
+	}
+}
+
+// countingLimiter 记录等待额度的接口
+type countingLimiter struct {
+	ops []string
+}
+
+func (l *countingLimiter) WaitRequest(ctx context.Context, op string, n int) error {
+	l.ops = append(l.ops, op)
+	return nil
+}
+
+// 半开连接：WebSocket 下单发出后没有响应。超时后应断开连接，按自定义订单ID查单，确认未送达再改用 REST
+func TestPlaceOrderWebSocketTimeoutFallsBackToREST(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	var lookupPath string
+	restPlaced := 0
+	adapter := newTestAdapter(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/ws":
+			conn, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			for {
+				// 只读不回，模拟半开连接
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/futures/usdt/orders/"):
+			lookupPath = r.URL.Path
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"label":"ORDER_NOT_FOUND","message":"Order not found"}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/futures/usdt/orders":
+			restPlaced++
+			w.Write([]byte(`{"id":501,"contract":"ETH_USDT","size":100,"price":"3000","status":"open","text":"t-grid1"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(adapter.client.baseURL, "http")+"/ws", nil)
+	if err != nil {
+		t.Fatalf("连接测试 WebSocket 失败: %v", err)
+	}
+	ws := NewWebSocketManager("key", "secret", "usdt")
+	ws.requestTimeout = 100 * time.Millisecond
+	ws.conn = conn
+	ws.isAuthenticated = true
+	adapter.wsManager = ws
+	adapter.useWebSocket = true
+
+	req := &OrderRequest{Symbol: "ETHUSDT", Side: SideBuy, Type: OrderTypeLimit, Price: 3000, Quantity: 1, ClientOrderID: "grid1"}
+	order, err := adapter.PlaceOrder(context.Background(), req)
+	if err != nil || order.OrderID != 501 || restPlaced != 1 {
+		t.Fatalf("未送达时应改用 REST: order=%+v err=%v restPlaced=%d", order, err, restPlaced)
+	}
+	if !strings.HasPrefix(lookupPath, "/futures/usdt/orders/t-") {
+		t.Errorf("应按自定义订单ID查单: %s", lookupPath)
+	}
+	if ws.IsRunning() {
+		t.Error("请求超时后应断开半开连接")
+	}
+}
@@ -0,0 +1,160 @@
+package gate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"opensqt/exchange/orderbook"
+	"opensqt/logger"
+
+	"github.com/gorilla/websocket"
+)
+
+// bookTickerEvent 盘口最优价推送（futures.book_ticker 数量为张数，spot.book_ticker 为币数量）
+type bookTickerEvent struct {
+	Time     int64       `json:"t"`
+	Symbol   string      `json:"s"`
+	BidPrice json.Number `json:"b"`
+	BidSize  json.Number `json:"B"`
+	AskPrice json.Number `json:"a"`
+	AskSize  json.Number `json:"A"`
+}
+
+// StartBookTickerStream 启动盘口最优价推送（独立连接，合约 futures.book_ticker，现货 spot.book_ticker），断线自动重连
+// 合约数量已按合约乘数换算为币数量
+func (g *GateAdapter) StartBookTickerStream(ctx context.Context, symbol string, callback func(ticker *orderbook.Ticker)) error {
+	meta, err := g.symbolMeta(ctx, symbol)
+	if err != nil {
+		return fmt.Errorf("获取 %s 合约信息失败: %w", symbol, err)
+	}
+	wsURL := futuresWSURL(g.settle, g.testnet)
+	if g.spot {
+		wsURL = GateSpotWSURL
+	}
+	logger.Info("🔗 [Gate] 启动盘口最优价流: %s", meta.gateSymbol)
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				logger.Info("✅ [Gate] 盘口最优价流已停止")
+				return
+			default:
+			}
+
+			conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+			if err != nil {
+				logger.Error("❌ [Gate] 盘口最优价流连接失败: %v，5秒后重试", err)
+				time.Sleep(5 * time.Second)
+				continue
+			}
+
+			g.readBookTicker(ctx, conn, meta, callback)
+			conn.Close()
+			time.Sleep(2 * time.Second)
+		}
+	}()
+
+	return nil
+}
+
+// readBookTicker 订阅盘口最优价频道并读取推送，连接出错或 context 取消时返回
+func (g *GateAdapter) readBookTicker(ctx context.Context, conn *websocket.Conn, meta *symbolMeta, callback func(ticker *orderbook.Ticker)) {
+	channel, pingChannel := "futures.book_ticker", "futures.ping"
+	if g.spot {
+		channel, pingChannel = "spot.book_ticker", "spot.ping"
+	}
+
+	var writeMu sync.Mutex // 串行化订阅与 ping 写操作
+	write := func(v interface{}) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+		return conn.WriteJSON(v)
+	}
+
+	subMsg := map[string]interface{}{
+		"time":    time.Now().Unix(),
+		"channel": channel,
+		"event":   "subscribe",
+		"payload": []string{meta.gateSymbol},
+	}
+	if err := write(subMsg); err != nil {
+		logger.Error("❌ [Gate] 订阅盘口最优价频道失败: %v", err)
+		return
+	}
+
+	// 保活
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(15 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				conn.Close()
+				return
+			case <-ticker.C:
+				if err := write(map[string]interface{}{"time": time.Now().Unix(), "channel": pingChannel}); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	multiplier := g.depthMultiplier(meta)
+	for {
+		conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.Warn("⚠️ [Gate] 盘口最优价流读取错误: %v，正在重连", err)
+			}
+			return
+		}
+
+		var msg struct {
+			Channel string          `json:"channel"`
+			Event   string          `json:"event"`
+			Error   json.RawMessage `json:"error"`
+			Result  json.RawMessage `json:"result"`
+		}
+		if err := json.Unmarshal(message, &msg); err != nil || msg.Channel != channel {
+			continue
+		}
+		if len(msg.Error) > 0 && string(msg.Error) != "null" {
+			logger.Error("❌ [Gate] 盘口最优价频道错误: %s", string(msg.Error))
+			continue
+		}
+		if msg.Event != "update" {
+			continue
+		}
+
+		var event bookTickerEvent
+		if err := json.Unmarshal(msg.Result, &event); err != nil {
+			logger.Debug("解析 Gate 盘口最优价失败: %v", err)
+			continue
+		}
+		bid, _ := event.BidPrice.Float64()
+		ask, _ := event.AskPrice.Float64()
+		if bid <= 0 || ask <= 0 {
+			continue
+		}
+		bidSize, _ := event.BidSize.Float64()
+		askSize, _ := event.AskSize.Float64()
+		callback(&orderbook.Ticker{
+			Symbol:   meta.symbol,
+			BidPrice: bid,
+			BidQty:   bidSize * multiplier,
+			AskPrice: ask,
+			AskQty:   askSize * multiplier,
+			Time:     event.Time,
+		})
+	}
+}
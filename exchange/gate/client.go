@@ -9,6 +9,8 @@ import (
 	"net/http"
 	"strconv"
 	"time"
+
+	"opensqt/exchange/errs"
 )
 
 // Client Gate.io HTTP 客户端
@@ -91,16 +93,42 @@ func (c *Client) DoRequest(ctx context.Context, method, path, queryString string
 			case "INVALID_KEY":
 				return nil, fmt.Errorf("Gate.io API Key 无效: %s。请检查配置文件中的 api_key", gateResp.Message)
 			default:
-				return nil, fmt.Errorf("Gate.io API 错误: [%s] %s (状态码: %d)",
-					gateResp.Label, gateResp.Message, resp.StatusCode)
+				err := classifyError(resp.StatusCode, gateResp.Label, fmt.Errorf("Gate.io API 错误: [%s] %s (状态码: %d)",
+					gateResp.Label, gateResp.Message, resp.StatusCode))
+				return nil, errs.WithRetryAfter(err, retryAfter(resp.Header))
 			}
 		}
-		return nil, fmt.Errorf("Gate.io API 错误: 状态码=%d, 响应=%s", resp.StatusCode, string(respBody))
+		err := classifyError(resp.StatusCode, "", fmt.Errorf("Gate.io API 错误: 状态码=%d, 响应=%s", resp.StatusCode, string(respBody)))
+		return nil, errs.WithRetryAfter(err, retryAfter(resp.Header))
 	}
 
 	return respBody, nil
 }
 
+// ServerTime 查询服务器时间（公共接口，不签名，本地时钟偏差过大时也能对时）
+func (c *Client) ServerTime(ctx context.Context) (time.Time, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/spot/time", nil)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("创建请求失败: %w", err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return time.Time{}, fmt.Errorf("Gate.io API 错误: 状态码=%d", resp.StatusCode)
+	}
+	var result struct {
+		ServerTime int64 `json:"server_time"` // 毫秒
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return time.Time{}, fmt.Errorf("解析响应失败: %w", err)
+	}
+	return time.UnixMilli(result.ServerTime), nil
+}
+
 // GetContract 获取合约信息
 func (c *Client) GetContract(ctx context.Context, settle, contract string) (*ContractInfo, error) {
 	path := fmt.Sprintf("/futures/%s/contracts/%s", settle, contract)
@@ -176,6 +204,24 @@ func (c *Client) GetPosition(ctx context.Context, settle, contract string) (*Fut
 	return &position, nil
 }
 
+// GetDualPositions 获取双向持仓模式下指定合约的多空持仓
+// GET /futures/{settle}/dual_comp/positions/{contract}
+func (c *Client) GetDualPositions(ctx context.Context, settle, contract string) ([]*FuturesPosition, error) {
+	path := fmt.Sprintf("/futures/%s/dual_comp/positions/%s", settle, contract)
+
+	respBody, err := c.DoRequest(ctx, "GET", path, "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var positions []*FuturesPosition
+	if err := json.Unmarshal(respBody, &positions); err != nil {
+		return nil, fmt.Errorf("解析双向持仓信息失败: %w", err)
+	}
+
+	return positions, nil
+}
+
 // PlaceOrder 通过 REST API 下单
 func (c *Client) PlaceOrder(ctx context.Context, settle string, order map[string]interface{}) (*FuturesOrder, error) {
 	path := fmt.Sprintf("/futures/%s/orders", settle)
@@ -210,6 +256,85 @@ func (c *Client) GetOrder(ctx context.Context, settle, orderID string) (*Futures
 	return &order, nil
 }
 
+// AmendOrder 修改订单（价格/数量）
+func (c *Client) AmendOrder(ctx context.Context, settle, orderID string, amend map[string]interface{}) (*FuturesOrder, error) {
+	path := fmt.Sprintf("/futures/%s/orders/%s", settle, orderID)
+
+	respBody, err := c.DoRequest(ctx, "PUT", path, "", amend)
+	if err != nil {
+		return nil, err
+	}
+
+	var order FuturesOrder
+	if err := json.Unmarshal(respBody, &order); err != nil {
+		return nil, fmt.Errorf("解析改单响应失败: %w", err)
+	}
+
+	return &order, nil
+}
+
+// UpdatePositionLeverage 修改持仓杠杆
+// POST /futures/{settle}/positions/{contract}/leverage
+// leverage 为 0 表示全仓，此时 crossLeverageLimit 为全仓杠杆倍数
+func (c *Client) UpdatePositionLeverage(ctx context.Context, settle, contract string, leverage, crossLeverageLimit int) error {
+	path := fmt.Sprintf("/futures/%s/positions/%s/leverage", settle, contract)
+	query := fmt.Sprintf("leverage=%d", leverage)
+	if leverage == 0 {
+		query += fmt.Sprintf("&cross_leverage_limit=%d", crossLeverageLimit)
+	}
+
+	_, err := c.DoRequest(ctx, "POST", path, query, nil)
+	return err
+}
+
+// SetDualMode 设置持仓模式（true 为双向持仓）
+// POST /futures/{settle}/dual_mode
+func (c *Client) SetDualMode(ctx context.Context, settle string, dualMode bool) error {
+	path := fmt.Sprintf("/futures/%s/dual_mode", settle)
+	query := fmt.Sprintf("dual_mode=%t", dualMode)
+
+	_, err := c.DoRequest(ctx, "POST", path, query, nil)
+	return err
+}
+
+// GetAccountBook 查询合约账户流水
+// GET /futures/{settle}/account_book
+func (c *Client) GetAccountBook(ctx context.Context, settle, contract, bookType string, from int64) ([]*AccountBookEntry, error) {
+	path := fmt.Sprintf("/futures/%s/account_book", settle)
+	query := fmt.Sprintf("contract=%s&type=%s&from=%d&limit=100", contract, bookType, from)
+
+	respBody, err := c.DoRequest(ctx, "GET", path, query, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*AccountBookEntry
+	if err := json.Unmarshal(respBody, &entries); err != nil {
+		return nil, fmt.Errorf("解析账户流水失败: %w", err)
+	}
+
+	return entries, nil
+}
+
+// GetMyTrades 按时间区间查询合约成交记录（from 为秒，单次最多 1000 条）
+// GET /futures/{settle}/my_trades_timerange
+func (c *Client) GetMyTrades(ctx context.Context, settle, contract string, from int64, limit int) ([]*FuturesTrade, error) {
+	path := fmt.Sprintf("/futures/%s/my_trades_timerange", settle)
+	query := fmt.Sprintf("contract=%s&from=%d&limit=%d", contract, from, limit)
+
+	respBody, err := c.DoRequest(ctx, "GET", path, query, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var trades []*FuturesTrade
+	if err := json.Unmarshal(respBody, &trades); err != nil {
+		return nil, fmt.Errorf("解析成交记录失败: %w", err)
+	}
+
+	return trades, nil
+}
+
 // BatchCancelOrders 批量取消订单
 // POST /futures/{settle}/batch_cancel_orders
 // 一次最多撤销20个订单
@@ -285,6 +410,25 @@ func (c *Client) GetCandlesticks(ctx context.Context, settle, contract, interval
 	return candlesticks, nil
 }
 
+// GetCandlesticksRange 按时间区间获取K线（from/to 为秒，单次最多 2000 根，不能与 limit 同时使用）
+// GET /futures/{settle}/candlesticks
+func (c *Client) GetCandlesticksRange(ctx context.Context, settle, contract, interval string, from, to int64) ([]CandlestickData, error) {
+	path := fmt.Sprintf("/futures/%s/candlesticks", settle)
+	query := fmt.Sprintf("contract=%s&interval=%s&from=%d&to=%d", contract, interval, from, to)
+
+	resp, err := c.DoRequest(ctx, "GET", path, query, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var candlesticks []CandlestickData
+	if err := json.Unmarshal(resp, &candlesticks); err != nil {
+		return nil, fmt.Errorf("解析K线数据失败: %w", err)
+	}
+
+	return candlesticks, nil
+}
+
 // GetOpenOrders 获取未完成订单
 func (c *Client) GetOpenOrders(ctx context.Context, settle, contract string) ([]*FuturesOrder, error) {
 	path := fmt.Sprintf("/futures/%s/orders", settle)
@@ -1,5 +1,7 @@
 package gate
 
+import "fmt"
+
 const (
 	// Gate.io API v4 基础 URL
 	GateBaseURL = "https://api.gateio.ws/api/v4"
@@ -7,6 +9,33 @@ const (
 	// Gate.io WebSocket URL (USDT永续合约)
 	GateWSURL = "wss://fx-ws.gateio.ws/v4/ws/usdt"
 
+	// Gate.io WebSocket URL (现货)
+	GateSpotWSURL = "wss://api.gateio.ws/ws/v4/"
+
+	// Gate.io 合约测试网（现货没有测试网）
+	GateTestnetBaseURL = "https://fx-api-testnet.gateio.ws/api/v4"
+	GateTestnetWSURL   = "wss://fx-ws-testnet.gateio.ws/v4/ws/usdt"
+
 	// 渠道标识
 	GateChannelID = "opensqt"
 )
+
+// parseEnvironment 解析 environment 配置，返回是否使用合约测试网
+func parseEnvironment(env string) (testnet bool, err error) {
+	switch env {
+	case "", "mainnet":
+		return false, nil
+	case "testnet":
+		return true, nil
+	default:
+		return false, fmt.Errorf("Gate.io 不支持 environment=%s（可选 mainnet/testnet）", env)
+	}
+}
+
+// futuresWSURL 合约 WebSocket 地址（按结算币种区分）
+func futuresWSURL(settle string, testnet bool) string {
+	if testnet {
+		return fmt.Sprintf("wss://fx-ws-testnet.gateio.ws/v4/ws/%s", settle)
+	}
+	return fmt.Sprintf("wss://fx-ws.gateio.ws/v4/ws/%s", settle)
+}
@@ -0,0 +1,310 @@
+package gate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"opensqt/exchange/orderbook"
+	"opensqt/logger"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// depthStreamLevels 合约增量深度订阅的档数（快照档数与之相同）
+	depthStreamLevels = 20
+	// spotDepthSnapshotLimit 现货同步订单簿时 REST 快照的档数
+	spotDepthSnapshotLimit = 100
+	// depthEventBuffer 拉取快照期间缓存的增量事件数
+	depthEventBuffer = 1000
+)
+
+// depthLevel 盘口价位：合约为 {"p":"价格","s":张数}，现货为 ["价格","数量"]
+type depthLevel struct {
+	Price float64
+	Size  float64
+}
+
+// UnmarshalJSON 兼容合约与现货两种价位格式
+func (l *depthLevel) UnmarshalJSON(data []byte) error {
+	var price, size json.Number
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		var pair []json.Number
+		if err := json.Unmarshal(data, &pair); err != nil {
+			return err
+		}
+		if len(pair) < 2 {
+			return fmt.Errorf("价位格式错误: %s", string(data))
+		}
+		price, size = pair[0], pair[1]
+	} else {
+		var obj struct {
+			P json.Number `json:"p"`
+			S json.Number `json:"s"`
+		}
+		if err := json.Unmarshal(data, &obj); err != nil {
+			return err
+		}
+		price, size = obj.P, obj.S
+	}
+	var err error
+	if l.Price, err = strconv.ParseFloat(price.String(), 64); err != nil {
+		return err
+	}
+	l.Size, err = strconv.ParseFloat(size.String(), 64)
+	return err
+}
+
+// depthLevels 转换价位，multiplier 为合约乘数（张数换算为币数量），现货传 1
+func depthLevels(raw []depthLevel, multiplier float64) []orderbook.Level {
+	result := make([]orderbook.Level, len(raw))
+	for i, l := range raw {
+		result[i] = orderbook.Level{Price: l.Price, Quantity: l.Size * multiplier}
+	}
+	return result
+}
+
+// depthUpdate 增量深度推送（futures.order_book_update / spot.order_book_update）
+type depthUpdate struct {
+	FirstID int64        `json:"U"`
+	LastID  int64        `json:"u"`
+	Bids    []depthLevel `json:"b"`
+	Asks    []depthLevel `json:"a"`
+}
+
+// depthMultiplier 数量换算系数：合约按张推送，需乘以合约乘数
+func (g *GateAdapter) depthMultiplier(meta *symbolMeta) float64 {
+	if g.spot || meta.quantoMultiplier <= 0 {
+		return 1
+	}
+	return meta.quantoMultiplier
+}
+
+// depthSnapshot 请求深度快照（with_id=true 返回快照序列号，用于衔接增量推送）
+func (g *GateAdapter) depthSnapshot(ctx context.Context, meta *symbolMeta, limit int) (bids, asks []orderbook.Level, id int64, err error) {
+	var path, query string
+	if g.spot {
+		path = "/spot/order_book"
+		query = fmt.Sprintf("currency_pair=%s&limit=%d&with_id=true", meta.gateSymbol, limit)
+	} else {
+		path = fmt.Sprintf("/futures/%s/order_book", g.settle)
+		query = fmt.Sprintf("contract=%s&limit=%d&with_id=true", meta.gateSymbol, limit)
+	}
+
+	respBody, err := g.client.DoRequest(ctx, "GET", path, query, nil)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	var res struct {
+		ID   int64        `json:"id"`
+		Bids []depthLevel `json:"bids"`
+		Asks []depthLevel `json:"asks"`
+	}
+	if err := json.Unmarshal(respBody, &res); err != nil {
+		return nil, nil, 0, fmt.Errorf("解析深度数据失败: %w", err)
+	}
+	multiplier := g.depthMultiplier(meta)
+	return depthLevels(res.Bids, multiplier), depthLevels(res.Asks, multiplier), res.ID, nil
+}
+
+// GetOrderBook 获取订单簿快照（数量已按合约乘数换算为币数量）
+func (g *GateAdapter) GetOrderBook(ctx context.Context, symbol string, depth int) (*orderbook.Snapshot, error) {
+	meta, err := g.symbolMeta(ctx, symbol)
+	if err != nil {
+		return nil, fmt.Errorf("获取 %s 合约信息失败: %w", symbol, err)
+	}
+	limit := depth
+	if limit <= 0 || limit > 100 {
+		limit = 100
+	}
+	bids, asks, id, err := g.depthSnapshot(ctx, meta, limit)
+	if err != nil {
+		return nil, err
+	}
+	return &orderbook.Snapshot{Symbol: meta.symbol, Bids: bids, Asks: asks, UpdateID: id, Time: time.Now()}, nil
+}
+
+// StartDepthStream 启动增量深度推送（独立连接），本地维护订单簿并在每次更新后回调前 depthStreamLevels 档
+// 合约订阅 futures.order_book_update（100ms，20 档），现货订阅 spot.order_book_update（100ms）；
+// 先缓存推送再拉取 with_id 快照，丢弃早于快照的推送，U 与上一条 u+1 不连续时重新拉取快照
+func (g *GateAdapter) StartDepthStream(ctx context.Context, symbol string, callback func(book *orderbook.Snapshot)) error {
+	meta, err := g.symbolMeta(ctx, symbol)
+	if err != nil {
+		return fmt.Errorf("获取 %s 合约信息失败: %w", symbol, err)
+	}
+	wsURL := futuresWSURL(g.settle, g.testnet)
+	if g.spot {
+		wsURL = GateSpotWSURL
+	}
+	book := orderbook.NewBook(meta.symbol)
+	logger.Info("🔗 [Gate] 启动深度流: %s", meta.gateSymbol)
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				logger.Info("✅ [Gate] 深度流已停止")
+				return
+			default:
+			}
+
+			conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+			if err != nil {
+				logger.Error("❌ [Gate] 深度流连接失败: %v，5秒后重试", err)
+				time.Sleep(5 * time.Second)
+				continue
+			}
+
+			g.syncDepth(ctx, conn, meta, book, callback)
+			conn.Close()
+			time.Sleep(2 * time.Second)
+		}
+	}()
+
+	return nil
+}
+
+// syncDepth 订阅增量深度并维护本地订单簿，连接出错、快照失败或 context 取消时返回
+func (g *GateAdapter) syncDepth(ctx context.Context, conn *websocket.Conn, meta *symbolMeta, book *orderbook.Book, callback func(book *orderbook.Snapshot)) {
+	channel, pingChannel := "futures.order_book_update", "futures.ping"
+	payload := []string{meta.gateSymbol, "100ms", strconv.Itoa(depthStreamLevels)}
+	snapshotLimit := depthStreamLevels
+	if g.spot {
+		channel, pingChannel = "spot.order_book_update", "spot.ping"
+		payload = []string{meta.gateSymbol, "100ms"}
+		snapshotLimit = spotDepthSnapshotLimit
+	}
+
+	var writeMu sync.Mutex // 串行化订阅与 ping 写操作
+	write := func(v interface{}) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+		return conn.WriteJSON(v)
+	}
+
+	subMsg := map[string]interface{}{
+		"time":    time.Now().Unix(),
+		"channel": channel,
+		"event":   "subscribe",
+		"payload": payload,
+	}
+	if err := write(subMsg); err != nil {
+		logger.Error("❌ [Gate] 订阅深度频道失败: %v", err)
+		return
+	}
+
+	events := make(chan *orderbook.Diff, depthEventBuffer)
+	done := make(chan struct{})
+	defer close(done)
+
+	// 保活
+	go func() {
+		ticker := time.NewTicker(15 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				conn.Close()
+				return
+			case <-ticker.C:
+				if err := write(map[string]interface{}{"time": time.Now().Unix(), "channel": pingChannel}); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	// 读取协程：拉取快照期间增量推送在 events 中缓存
+	multiplier := g.depthMultiplier(meta)
+	go func() {
+		defer close(events)
+		for {
+			conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+			_, message, err := conn.ReadMessage()
+			if err != nil {
+				select {
+				case <-ctx.Done():
+				case <-done:
+				default:
+					logger.Warn("⚠️ [Gate] 深度流读取错误: %v，正在重连", err)
+				}
+				return
+			}
+
+			var msg struct {
+				Channel string          `json:"channel"`
+				Event   string          `json:"event"`
+				Error   json.RawMessage `json:"error"`
+				Result  json.RawMessage `json:"result"`
+			}
+			if err := json.Unmarshal(message, &msg); err != nil || msg.Channel != channel {
+				continue
+			}
+			if len(msg.Error) > 0 && string(msg.Error) != "null" {
+				logger.Error("❌ [Gate] 深度频道错误: %s", string(msg.Error))
+				continue
+			}
+			if msg.Event != "update" {
+				continue
+			}
+			var update depthUpdate
+			if err := json.Unmarshal(msg.Result, &update); err != nil {
+				logger.Warn("⚠️ [Gate] 解析深度推送失败: %v", err)
+				continue
+			}
+			diff := &orderbook.Diff{
+				FirstID: update.FirstID,
+				LastID:  update.LastID,
+				Bids:    depthLevels(update.Bids, multiplier),
+				Asks:    depthLevels(update.Asks, multiplier),
+			}
+			select {
+			case events <- diff:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	synced := false
+	for {
+		if !synced {
+			if err := g.waitRequest(ctx, "GetOrderBook", snapshotLimit); err != nil {
+				return
+			}
+			snapshotCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+			bids, asks, id, err := g.depthSnapshot(snapshotCtx, meta, snapshotLimit)
+			cancel()
+			if err != nil {
+				logger.Warn("⚠️ [Gate] 获取 %s 深度快照失败: %v，正在重连", meta.gateSymbol, err)
+				return
+			}
+			book.Reset(bids, asks, id)
+			synced = true
+			logger.Debug("[Gate] %s 订单簿已同步，快照序列号 %d", meta.gateSymbol, id)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case diff, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := book.ApplyDiff(diff); err != nil {
+				logger.Warn("⚠️ [Gate] %s %v，重新同步快照", meta.gateSymbol, err)
+				synced = false
+				continue
+			}
+			callback(book.Snapshot(depthStreamLevels))
+		}
+	}
+}
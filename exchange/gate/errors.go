@@ -0,0 +1,41 @@
+package gate
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"opensqt/exchange/errs"
+)
+
+// classifyError 将 Gate.io 错误标签映射为统一错误分类
+// 参考：https://www.gate.io/docs/developers/apiv4/#label-list
+func classifyError(statusCode int, label string, err error) error {
+	var kind error
+	switch {
+	case label == "ORDER_POC_IMMEDIATE":
+		kind = errs.ErrPostOnlyWouldCross
+	case label == "ORDER_NOT_FOUND" || label == "ORDER_FINISHED":
+		kind = errs.ErrOrderNotFound
+	case label == "TOO_MANY_REQUESTS" || statusCode == http.StatusTooManyRequests:
+		kind = errs.ErrRateLimited
+	case label == "REQUEST_EXPIRED":
+		kind = errs.ErrClockSkew
+	case strings.Contains(label, "INSUFFICIENT") || strings.Contains(label, "BALANCE_NOT_ENOUGH"):
+		kind = errs.ErrInsufficientMargin
+	}
+	return errs.Wrap(kind, "gate", label, err)
+}
+
+// retryAfter 限频响应给出的等待时间：优先取 Retry-After，
+// 否则取 X-Gate-RateLimit-Reset-Timestamp（额度重置的毫秒时间戳）
+func retryAfter(header http.Header) time.Duration {
+	if d := errs.ParseRetryAfter(header); d > 0 {
+		return d
+	}
+	if ms, err := strconv.ParseInt(header.Get("X-Gate-RateLimit-Reset-Timestamp"), 10, 64); err == nil && ms > 0 {
+		return time.Until(time.UnixMilli(ms))
+	}
+	return 0
+}
@@ -7,6 +7,7 @@ import (
 	"net"
 	"net/http"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -27,6 +28,8 @@ type KlineWebSocketManager struct {
 	pingInterval   time.Duration
 	isRunning      bool
 	settle         string // usdt 或 btc
+	spot           bool   // 现货模式：连接现货地址并订阅 spot.candlesticks
+	testnet        bool   // 连接合约测试网
 }
 
 // NewKlineWebSocketManager 创建K线WebSocket管理器
@@ -94,7 +97,10 @@ func (k *KlineWebSocketManager) connectLoop(ctx context.Context) {
 		}
 
 		// Gate.io WebSocket URL
-		wsURL := fmt.Sprintf("wss://fx-ws.gateio.ws/v4/ws/%s", k.settle)
+		wsURL := futuresWSURL(k.settle, k.testnet)
+		if k.spot {
+			wsURL = GateSpotWSURL
+		}
 
 		// 设置连接头部，模拟浏览器行为
 		headers := make(http.Header)
@@ -179,6 +185,14 @@ func (k *KlineWebSocketManager) connectLoop(ctx context.Context) {
 	}
 }
 
+// channel K线频道名称
+func (k *KlineWebSocketManager) channel() string {
+	if k.spot {
+		return "spot.candlesticks"
+	}
+	return "futures.candlesticks"
+}
+
 // subscribe 订阅K线
 func (k *KlineWebSocketManager) subscribe(symbols []string, interval string) error {
 	// Gate.io K线订阅格式: 每个交易对单独订阅
@@ -198,7 +212,7 @@ func (k *KlineWebSocketManager) subscribe(symbols []string, interval string) err
 
 		subMsg := map[string]interface{}{
 			"time":    time.Now().Unix(),
-			"channel": "futures.candlesticks",
+			"channel": k.channel(),
 			"event":   "subscribe",
 			"payload": []string{interval, gateSymbol},
 		}
@@ -348,7 +362,7 @@ func (k *KlineWebSocketManager) handleMessage(message []byte) {
 
 	case "update":
 		// K线数据更新
-		if channel == "futures.candlesticks" {
+		if channel == k.channel() {
 			k.handleCandleUpdate(msg)
 		}
 
@@ -357,7 +371,7 @@ func (k *KlineWebSocketManager) handleMessage(message []byte) {
 
 	default:
 		// 空事件可能是正常的update消息，检查channel
-		if channel == "futures.candlesticks" {
+		if channel == k.channel() {
 			k.handleCandleUpdate(msg)
 		} else if event != "" {
 			// 有事件但不认识才打印
@@ -368,18 +382,22 @@ func (k *KlineWebSocketManager) handleMessage(message []byte) {
 
 // handleCandleUpdate 处理K线更新
 func (k *KlineWebSocketManager) handleCandleUpdate(msg map[string]interface{}) {
-	// Gate.io返回的result是数组: result: [{"t": ..., "o": ..., "n": "1m_ETH_USDT", ...}]
-	resultArray, ok := msg["result"].([]interface{})
-	if !ok || len(resultArray) == 0 {
-		logger.Warn("⚠️ [Gate K线] result字段不是数组或为空")
-		return
-	}
-
-	// 取第一个元素
-	result, ok := resultArray[0].(map[string]interface{})
+	// Gate.io合约返回的result是数组: result: [{"t": ..., "o": ..., "n": "1m_ETH_USDT", ...}]
+	// 现货返回的result是单个对象
+	result, ok := msg["result"].(map[string]interface{})
 	if !ok {
-		logger.Warn("⚠️ [Gate K线] result[0]不是对象")
-		return
+		resultArray, ok := msg["result"].([]interface{})
+		if !ok || len(resultArray) == 0 {
+			logger.Warn("⚠️ [Gate K线] result字段不是数组或为空")
+			return
+		}
+
+		// 取第一个元素
+		result, ok = resultArray[0].(map[string]interface{})
+		if !ok {
+			logger.Warn("⚠️ [Gate K线] result[0]不是对象")
+			return
+		}
 	}
 
 	// Gate.io K线数据格式:
@@ -512,6 +530,10 @@ func convertToGateSymbol(symbol string) string {
 	if len(symbol) > 4 && symbol[len(symbol)-4:] == "USDT" {
 		return symbol[:len(symbol)-4] + "_" + symbol[len(symbol)-4:]
 	}
+	// 币本位合约：BTCUSD -> BTC_USD
+	if len(symbol) > 3 && !strings.Contains(symbol, "_") && symbol[len(symbol)-3:] == "USD" {
+		return symbol[:len(symbol)-3] + "_" + symbol[len(symbol)-3:]
+	}
 	return symbol
 }
 
@@ -0,0 +1,22 @@
+package gate
+
+import "context"
+
+// RequestLimiter 账户级请求额度（由 exchange.RateGovernor 实现）
+// 适配器内部额外发起的 REST 请求（改单前查询原订单、深度流重同步时拉取快照）不经过外层的额度包装，需在发出前等待
+type RequestLimiter interface {
+	WaitRequest(ctx context.Context, op string, n int) error
+}
+
+// SetRequestLimiter 接入账户级请求额度，适配器内部发起的 REST 请求按接口权重等待
+func (g *GateAdapter) SetRequestLimiter(limiter RequestLimiter) {
+	g.limiter = limiter
+}
+
+// waitRequest 等待内部请求的额度，未接入额度管理时直接返回
+func (g *GateAdapter) waitRequest(ctx context.Context, op string, n int) error {
+	if g.limiter == nil {
+		return nil
+	}
+	return g.limiter.WaitRequest(ctx, op, n)
+}
@@ -5,13 +5,15 @@ import (
 	"crypto/sha512"
 	"encoding/hex"
 	"fmt"
-	"time"
+
+	"opensqt/exchange/clock"
 )
 
 // Signer Gate.io API v4 签名器
 type Signer struct {
 	apiKey    string
 	secretKey string
+	clock     *clock.Estimator // 服务器时间偏差，nil 时使用本地时间
 }
 
 // NewSigner 创建签名器
@@ -69,9 +71,19 @@ func (s *Signer) SignWebSocket(channel, event string, timestamp int64) string {
 	return signature
 }
 
+// SignWebSocketAPI 生成 WebSocket 交易接口（event=api）签名
+// 待签名字符串: "api\n" + channel + "\n" + req_param + "\n" + timestamp，登录时 req_param 为空
+func (s *Signer) SignWebSocketAPI(channel, reqParam string, timestamp int64) string {
+	message := fmt.Sprintf("api\n%s\n%s\n%d", channel, reqParam, timestamp)
+
+	mac := hmac.New(sha512.New, []byte(s.secretKey))
+	mac.Write([]byte(message))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
 // GetTimestamp 获取当前时间戳（秒）
 func (s *Signer) GetTimestamp() int64 {
-	return time.Now().Unix()
+	return s.clock.Now().Unix()
 }
 
 // GetAPIKey 获取 API Key
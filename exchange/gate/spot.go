@@ -0,0 +1,773 @@
+package gate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"opensqt/exchange/errs"
+	"opensqt/logger"
+	"opensqt/utils"
+)
+
+// NewGateSpotAdapter 创建 Gate.io 现货适配器
+// 现货没有杠杆与持仓：持仓以基础币种钱包余额表示，可用余额为计价币种的可用余额
+func NewGateSpotAdapter(cfg map[string]string, symbol string) (*GateAdapter, error) {
+	apiKey := cfg["api_key"]
+	secretKey := cfg["secret_key"]
+
+	if apiKey == "" || secretKey == "" {
+		return nil, fmt.Errorf("Gate.io API 配置不完整")
+	}
+	testnet, err := parseEnvironment(cfg["environment"])
+	if err != nil {
+		return nil, err
+	}
+	if testnet {
+		return nil, fmt.Errorf("Gate.io 测试网仅支持合约，现货请使用 environment=mainnet")
+	}
+
+	gateSymbol := convertToGateSymbol(symbol)
+
+	wsManager := NewWebSocketManager(apiKey, secretKey, "")
+	wsManager.spot = true
+
+	adapter := &GateAdapter{
+		client:     NewClient(apiKey, secretKey),
+		wsManager:  wsManager,
+		symbol:     symbol,
+		gateSymbol: gateSymbol,
+		spot:       true,
+		metas:      make(map[string]*symbolMeta),
+	}
+	adapter.startClock()
+
+	ctxInit, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := adapter.symbolMeta(ctxInit, symbol); err != nil {
+		logger.Warn("⚠️ [Gate 现货] 获取交易对信息失败: %v，使用默认精度", err)
+		adapter.metas[convertFromGateSymbol(symbol)] = &symbolMeta{
+			symbol:           convertFromGateSymbol(symbol),
+			gateSymbol:       gateSymbol,
+			quantoMultiplier: 1,
+			volumePlace:      4,
+			pricePlace:       2,
+		}
+	}
+
+	return adapter, nil
+}
+
+// IsSpot 是否为现货适配器
+func (g *GateAdapter) IsSpot() bool {
+	return g.spot
+}
+
+// errSpotUnsupported 现货不支持的合约功能
+func errSpotUnsupported(feature string) error {
+	return fmt.Errorf("Gate.io 现货不支持%s", feature)
+}
+
+// SpotCurrencyPair 现货交易对信息
+type SpotCurrencyPair struct {
+	ID              string `json:"id"`
+	Base            string `json:"base"`
+	Quote           string `json:"quote"`
+	MinBaseAmount   string `json:"min_base_amount"`
+	MinQuoteAmount  string `json:"min_quote_amount"`
+	AmountPrecision int    `json:"amount_precision"`
+	Precision       int    `json:"precision"` // 价格精度
+}
+
+// SpotOrder 现货订单
+type SpotOrder struct {
+	ID           string `json:"id"`
+	Text         string `json:"text"`
+	CurrencyPair string `json:"currency_pair"`
+	Status       string `json:"status"` // open, closed, cancelled
+	Side         string `json:"side"`   // buy, sell
+	Amount       string `json:"amount"`
+	Price        string `json:"price"`
+	Left         string `json:"left"`
+	FilledAmount string `json:"filled_amount"`
+	AvgDealPrice string `json:"avg_deal_price"`
+	CreateTimeMs int64  `json:"create_time_ms"`
+	UpdateTimeMs int64  `json:"update_time_ms"`
+}
+
+// SpotTrade 现货成交记录
+type SpotTrade struct {
+	ID           string `json:"id"`
+	CreateTimeMs string `json:"create_time_ms"` // 毫秒，含小数
+	CurrencyPair string `json:"currency_pair"`
+	Side         string `json:"side"` // buy, sell
+	Role         string `json:"role"` // taker, maker
+	Amount       string `json:"amount"`
+	Price        string `json:"price"`
+	OrderID      string `json:"order_id"`
+	Fee          string `json:"fee"`
+	FeeCurrency  string `json:"fee_currency"`
+	Text         string `json:"text"`
+}
+
+// SpotBalance 现货账户余额
+type SpotBalance struct {
+	Currency  string `json:"currency"`
+	Available string `json:"available"`
+	Locked    string `json:"locked"`
+}
+
+// GetSpotCurrencyPair 获取现货交易对信息
+func (c *Client) GetSpotCurrencyPair(ctx context.Context, pair string) (*SpotCurrencyPair, error) {
+	respBody, err := c.DoRequest(ctx, "GET", "/spot/currency_pairs/"+pair, "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var info SpotCurrencyPair
+	if err := json.Unmarshal(respBody, &info); err != nil {
+		return nil, fmt.Errorf("解析交易对信息失败: %w", err)
+	}
+	return &info, nil
+}
+
+// PlaceSpotOrder 现货下单
+func (c *Client) PlaceSpotOrder(ctx context.Context, order map[string]interface{}) (*SpotOrder, error) {
+	respBody, err := c.DoRequest(ctx, "POST", "/spot/orders", "", order)
+	if err != nil {
+		return nil, err
+	}
+
+	var spotOrder SpotOrder
+	if err := json.Unmarshal(respBody, &spotOrder); err != nil {
+		return nil, fmt.Errorf("解析订单响应失败: %w", err)
+	}
+	return &spotOrder, nil
+}
+
+// GetSpotOrder 查询现货订单
+func (c *Client) GetSpotOrder(ctx context.Context, pair, orderID string) (*SpotOrder, error) {
+	respBody, err := c.DoRequest(ctx, "GET", "/spot/orders/"+orderID, "currency_pair="+pair, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var spotOrder SpotOrder
+	if err := json.Unmarshal(respBody, &spotOrder); err != nil {
+		return nil, fmt.Errorf("解析订单信息失败: %w", err)
+	}
+	return &spotOrder, nil
+}
+
+// CancelSpotOrder 取消现货订单
+func (c *Client) CancelSpotOrder(ctx context.Context, pair, orderID string) error {
+	_, err := c.DoRequest(ctx, "DELETE", "/spot/orders/"+orderID, "currency_pair="+pair, nil)
+	return err
+}
+
+// GetSpotOpenOrders 获取现货未完成订单
+func (c *Client) GetSpotOpenOrders(ctx context.Context, pair string) ([]*SpotOrder, error) {
+	respBody, err := c.DoRequest(ctx, "GET", "/spot/orders", fmt.Sprintf("currency_pair=%s&status=open", pair), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var orders []*SpotOrder
+	if err := json.Unmarshal(respBody, &orders); err != nil {
+		return nil, fmt.Errorf("解析订单列表失败: %w", err)
+	}
+	return orders, nil
+}
+
+// GetSpotAccounts 获取现货账户余额
+func (c *Client) GetSpotAccounts(ctx context.Context) ([]*SpotBalance, error) {
+	respBody, err := c.DoRequest(ctx, "GET", "/spot/accounts", "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var balances []*SpotBalance
+	if err := json.Unmarshal(respBody, &balances); err != nil {
+		return nil, fmt.Errorf("解析现货账户失败: %w", err)
+	}
+	return balances, nil
+}
+
+// GetSpotCandlesticks 获取现货历史K线
+// 返回格式: [[时间(秒), 成交额, 收盘价, 最高价, 最低价, 开盘价, 成交量, 是否完结], ...]
+func (c *Client) GetSpotCandlesticks(ctx context.Context, pair, interval string, limit int) ([][]string, error) {
+	query := fmt.Sprintf("currency_pair=%s&interval=%s&limit=%d", pair, interval, limit)
+	respBody, err := c.DoRequest(ctx, "GET", "/spot/candlesticks", query, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var candlesticks [][]string
+	if err := json.Unmarshal(respBody, &candlesticks); err != nil {
+		return nil, fmt.Errorf("解析K线数据失败: %w", err)
+	}
+	return candlesticks, nil
+}
+
+// GetSpotCandlesticksRange 按时间区间获取现货K线（from/to 为秒，单次最多 1000 根）
+func (c *Client) GetSpotCandlesticksRange(ctx context.Context, pair, interval string, from, to int64) ([][]string, error) {
+	query := fmt.Sprintf("currency_pair=%s&interval=%s&from=%d&to=%d", pair, interval, from, to)
+	respBody, err := c.DoRequest(ctx, "GET", "/spot/candlesticks", query, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var candlesticks [][]string
+	if err := json.Unmarshal(respBody, &candlesticks); err != nil {
+		return nil, fmt.Errorf("解析K线数据失败: %w", err)
+	}
+	return candlesticks, nil
+}
+
+// GetSpotMyTrades 查询现货成交记录（from 为秒，单次最多 1000 条）
+func (c *Client) GetSpotMyTrades(ctx context.Context, pair string, from int64, limit int) ([]*SpotTrade, error) {
+	query := fmt.Sprintf("currency_pair=%s&from=%d&limit=%d", pair, from, limit)
+	respBody, err := c.DoRequest(ctx, "GET", "/spot/my_trades", query, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var trades []*SpotTrade
+	if err := json.Unmarshal(respBody, &trades); err != nil {
+		return nil, fmt.Errorf("解析成交记录失败: %w", err)
+	}
+	return trades, nil
+}
+
+// spotSymbolInfo 获取现货交易规则
+func (g *GateAdapter) spotSymbolInfo(ctx context.Context, symbol string) (*SymbolInfo, error) {
+	pair, err := g.client.GetSpotCurrencyPair(ctx, convertToGateSymbol(symbol))
+	if err != nil {
+		return nil, fmt.Errorf("获取交易对信息失败: %w", err)
+	}
+
+	info := &SymbolInfo{
+		Symbol:             symbol,
+		BaseAsset:          pair.Base,
+		QuoteAsset:         pair.Quote,
+		ContractMultiplier: 1,
+		MaxLeverage:        1,
+		PriceDecimals:      pair.Precision,
+		QuantityDecimals:   pair.AmountPrecision,
+	}
+	info.TickSize = math.Pow10(-pair.Precision)
+	info.StepSize = math.Pow10(-pair.AmountPrecision)
+	info.MinQty, _ = strconv.ParseFloat(pair.MinBaseAmount, 64)
+	info.MinNotional, _ = strconv.ParseFloat(pair.MinQuoteAmount, 64)
+	return info, nil
+}
+
+// spotPlaceOrder 现货限价下单（PostOnly 使用 time_in_force=poc）
+func (g *GateAdapter) spotPlaceOrder(ctx context.Context, req *OrderRequest) (*Order, error) {
+	meta, err := g.symbolMeta(ctx, req.Symbol)
+	if err != nil {
+		return nil, fmt.Errorf("获取 %s 交易对信息失败: %w", req.Symbol, err)
+	}
+
+	order := map[string]interface{}{
+		"currency_pair": meta.gateSymbol,
+		"type":          "limit",
+		"account":       "spot",
+		"side":          strings.ToLower(string(req.Side)),
+		"amount":        fmt.Sprintf("%.*f", meta.volumePlace, req.Quantity),
+		"price":         fmt.Sprintf("%.*f", meta.pricePlace, req.Price),
+		"time_in_force": "gtc",
+	}
+	if req.PostOnly {
+		order["time_in_force"] = "poc" // Post Only
+	}
+	if req.ClientOrderID != "" {
+		order["text"] = utils.AddBrokerPrefix("gate", req.ClientOrderID)
+	}
+
+	spotOrder, err := g.client.PlaceSpotOrder(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+
+	result := convertSpotOrder(spotOrder)
+	result.Price = req.Price
+	return result, nil
+}
+
+// spotBatchPlaceOrders 现货批量下单（逐单下单，下单成功后立即注册订单ID到价格的映射）
+func (g *GateAdapter) spotBatchPlaceOrders(ctx context.Context, orders []*OrderRequest) ([]*Order, bool) {
+	placedOrders := make([]*Order, 0, len(orders))
+	hasMarginError := false
+
+	for _, req := range orders {
+		order, err := g.spotPlaceOrder(ctx, req)
+		if err != nil {
+			logger.Warn("⚠️ [Gate 现货] 下单失败 %.2f %s: %v", req.Price, req.Side, err)
+			errs.Report(ctx, err)
+			if errors.Is(err, errs.ErrInsufficientMargin) {
+				hasMarginError = true
+			}
+			continue
+		}
+		if g.orderMappingCallback != nil && order.OrderID > 0 {
+			g.orderMappingCallback(order.OrderID, req.Price)
+		}
+		placedOrders = append(placedOrders, order)
+	}
+
+	return placedOrders, hasMarginError
+}
+
+// spotCancelOrder 现货撤单
+func (g *GateAdapter) spotCancelOrder(ctx context.Context, symbol string, orderID int64) error {
+	if err := g.client.CancelSpotOrder(ctx, convertToGateSymbol(symbol), strconv.FormatInt(orderID, 10)); err != nil {
+		if errors.Is(err, errs.ErrOrderNotFound) {
+			logger.Info("ℹ️ [Gate 现货] 订单 %d 已不存在，跳过取消", orderID)
+			return nil
+		}
+		return fmt.Errorf("取消订单失败: %w", err)
+	}
+
+	logger.Info("✅ [Gate 现货] 取消订单成功: %d", orderID)
+	return nil
+}
+
+// spotBatchCancelOrders 现货批量撤单（逐单撤销）
+func (g *GateAdapter) spotBatchCancelOrders(ctx context.Context, symbol string, orderIDs []int64) error {
+	for i, orderID := range orderIDs {
+		if err := g.spotCancelOrder(ctx, symbol, orderID); err != nil {
+			logger.Warn("⚠️ [Gate 现货] 取消订单失败 %d: %v", orderID, err)
+		}
+		// 避免限频
+		if i < len(orderIDs)-1 {
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+	return nil
+}
+
+// spotGetOrder 查询现货订单
+func (g *GateAdapter) spotGetOrder(ctx context.Context, symbol string, orderID int64) (*Order, error) {
+	spotOrder, err := g.client.GetSpotOrder(ctx, convertToGateSymbol(symbol), strconv.FormatInt(orderID, 10))
+	if err != nil {
+		return nil, err
+	}
+	return convertSpotOrder(spotOrder), nil
+}
+
+// spotGetOpenOrders 查询现货未完成订单
+func (g *GateAdapter) spotGetOpenOrders(ctx context.Context, symbol string) ([]*Order, error) {
+	spotOrders, err := g.client.GetSpotOpenOrders(ctx, convertToGateSymbol(symbol))
+	if err != nil {
+		return nil, err
+	}
+
+	orders := make([]*Order, 0, len(spotOrders))
+	for _, so := range spotOrders {
+		orders = append(orders, convertSpotOrder(so))
+	}
+	return orders, nil
+}
+
+// convertSpotOrder 转换现货订单
+func convertSpotOrder(so *SpotOrder) *Order {
+	orderID, _ := strconv.ParseInt(so.ID, 10, 64)
+	price, _ := strconv.ParseFloat(so.Price, 64)
+	quantity, _ := strconv.ParseFloat(so.Amount, 64)
+	executedQty, _ := strconv.ParseFloat(so.FilledAmount, 64)
+	avgPrice, _ := strconv.ParseFloat(so.AvgDealPrice, 64)
+
+	side := SideBuy
+	if so.Side == "sell" {
+		side = SideSell
+	}
+
+	return &Order{
+		OrderID:       orderID,
+		ClientOrderID: utils.RemoveBrokerPrefix("gate", so.Text),
+		Symbol:        convertFromGateSymbol(so.CurrencyPair),
+		Side:          side,
+		Type:          OrderTypeLimit,
+		Price:         price,
+		Quantity:      quantity,
+		ExecutedQty:   executedQty,
+		AvgPrice:      avgPrice,
+		Status:        convertSpotStatus(so.Status, executedQty),
+		CreatedAt:     time.UnixMilli(so.CreateTimeMs),
+		UpdateTime:    so.UpdateTimeMs,
+	}
+}
+
+// convertSpotStatus 转换现货订单状态（open 且有成交时为部分成交）
+func convertSpotStatus(status string, executedQty float64) OrderStatus {
+	switch status {
+	case "closed":
+		return "FILLED"
+	case "cancelled":
+		return "CANCELED"
+	default:
+		if executedQty > 0 {
+			return "PARTIALLY_FILLED"
+		}
+		return OrderStatusNew
+	}
+}
+
+// spotBalances 查询现货资产余额（币种 -> 可用、冻结）
+func (g *GateAdapter) spotBalances(ctx context.Context) (map[string][2]float64, error) {
+	accounts, err := g.client.GetSpotAccounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	balances := make(map[string][2]float64, len(accounts))
+	for _, acc := range accounts {
+		available, _ := strconv.ParseFloat(acc.Available, 64)
+		locked, _ := strconv.ParseFloat(acc.Locked, 64)
+		balances[strings.ToUpper(acc.Currency)] = [2]float64{available, locked}
+	}
+	return balances, nil
+}
+
+// spotAssets 默认交易对的基础币种与计价币种（如 ETH_USDT -> ETH, USDT）
+func (g *GateAdapter) spotAssets() (string, string) {
+	parts := strings.SplitN(g.gateSymbol, "_", 2)
+	if len(parts) != 2 {
+		return g.gateSymbol, ""
+	}
+	return parts[0], parts[1]
+}
+
+// GetBaseAsset 获取基础资产（交易币种）
+func (g *GateAdapter) GetBaseAsset() string {
+	base, _ := g.spotAssets()
+	return base
+}
+
+// GetQuoteAsset 获取计价资产（如 USDT；币本位合约为 USD）
+func (g *GateAdapter) GetQuoteAsset() string {
+	_, quote := g.spotAssets()
+	return quote
+}
+
+// spotGetAccount 获取现货账户信息
+// 钱包余额与可用余额均按计价币种统计，持仓为默认交易对基础币种的钱包余额
+func (g *GateAdapter) spotGetAccount(ctx context.Context) (*Account, error) {
+	balances, err := g.spotBalances(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	baseAsset, quoteAsset := g.spotAssets()
+	quote := balances[quoteAsset]
+	account := &Account{
+		TotalWalletBalance: quote[0] + quote[1],
+		TotalMarginBalance: quote[0] + quote[1],
+		AvailableBalance:   quote[0],
+		AccountLeverage:    1,
+	}
+	if base := balances[baseAsset]; base[0]+base[1] > 0 {
+		account.Positions = []*Position{{Symbol: g.symbol, Size: base[0] + base[1], Leverage: 1}}
+	}
+	return account, nil
+}
+
+// spotGetPositions 现货持仓：基础币种的钱包余额（可用 + 挂单冻结）
+func (g *GateAdapter) spotGetPositions(ctx context.Context, symbol string) ([]*Position, error) {
+	balances, err := g.spotBalances(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	baseAsset := strings.SplitN(convertToGateSymbol(symbol), "_", 2)[0]
+	base := balances[baseAsset]
+	return []*Position{{Symbol: convertFromGateSymbol(symbol), Size: base[0] + base[1], Leverage: 1}}, nil
+}
+
+// spotGetBalance 获取现货资产的可用余额（不含挂单冻结部分）
+func (g *GateAdapter) spotGetBalance(ctx context.Context, asset string) (float64, error) {
+	balances, err := g.spotBalances(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return balances[strings.ToUpper(asset)][0], nil
+}
+
+// spotGetMyTrades 获取现货成交记录
+func (g *GateAdapter) spotGetMyTrades(ctx context.Context, symbol string, since time.Time, limit int) ([]*Trade, error) {
+	spotTrades, err := g.client.GetSpotMyTrades(ctx, convertToGateSymbol(symbol), since.Unix(), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	trades := make([]*Trade, 0, len(spotTrades))
+	for _, st := range spotTrades {
+		orderID, _ := strconv.ParseInt(st.OrderID, 10, 64)
+		amount, _ := strconv.ParseFloat(st.Amount, 64)
+		price, _ := strconv.ParseFloat(st.Price, 64)
+		fee, _ := strconv.ParseFloat(st.Fee, 64)
+		createTimeMs, _ := strconv.ParseFloat(st.CreateTimeMs, 64)
+		trades = append(trades, &Trade{
+			TradeID:         st.ID,
+			OrderID:         orderID,
+			ClientOrderID:   utils.RemoveBrokerPrefix("gate", st.Text),
+			Symbol:          convertFromGateSymbol(st.CurrencyPair),
+			Side:            spotSide(st.Side),
+			Price:           price,
+			Quantity:        amount,
+			Commission:      fee,
+			CommissionAsset: strings.ToUpper(st.FeeCurrency),
+			IsMaker:         st.Role == "maker",
+			Time:            int64(createTimeMs),
+		})
+	}
+	sortTrades(trades)
+	return trades, nil
+}
+
+// spotHistoricalKlines 获取现货历史K线
+func (g *GateAdapter) spotHistoricalKlines(ctx context.Context, symbol string, interval string, limit int) ([]*Candle, error) {
+	candlesticks, err := g.client.GetSpotCandlesticks(ctx, convertToGateSymbol(symbol), interval, limit)
+	if err != nil {
+		return nil, fmt.Errorf("获取历史K线失败: %w", err)
+	}
+
+	candles := make([]*Candle, 0, len(candlesticks))
+	for _, cs := range candlesticks {
+		if len(cs) < 7 {
+			continue // 跳过无效数据
+		}
+
+		timestamp, _ := strconv.ParseInt(cs[0], 10, 64)
+		close, _ := strconv.ParseFloat(cs[2], 64)
+		high, _ := strconv.ParseFloat(cs[3], 64)
+		low, _ := strconv.ParseFloat(cs[4], 64)
+		open, _ := strconv.ParseFloat(cs[5], 64)
+		volume, _ := strconv.ParseFloat(cs[6], 64)
+
+		candles = append(candles, &Candle{
+			Symbol:    symbol,
+			Open:      open,
+			High:      high,
+			Low:       low,
+			Close:     close,
+			Volume:    volume,
+			Timestamp: timestamp,
+			IsClosed:  true,
+		})
+	}
+	return candles, nil
+}
+
+// subscribeSpotChannels 订阅现货频道：订单、成交明细（私有，需要认证）与 ticker
+func (w *WebSocketManager) subscribeSpotChannels(symbol string) error {
+	gateSymbol := convertToGateSymbol(symbol)
+	timestamp := w.signer.GetTimestamp()
+
+	privateMsg := func(channel string, ts int64) map[string]interface{} {
+		return map[string]interface{}{
+			"time":    ts,
+			"channel": channel,
+			"event":   "subscribe",
+			"auth": map[string]interface{}{
+				"method": "api_key",
+				"KEY":    w.apiKey,
+				"SIGN":   w.signer.SignWebSocket(channel, "subscribe", ts),
+			},
+			"req_header": map[string]string{
+				"X-Gate-Channel-Id": GateChannelID,
+			},
+			"payload": []string{gateSymbol},
+		}
+	}
+	tickerMsg := map[string]interface{}{
+		"time":    timestamp + 2,
+		"channel": "spot.tickers",
+		"event":   "subscribe",
+		"payload": []string{gateSymbol},
+	}
+
+	w.mu.RLock()
+	conn := w.conn
+	w.mu.RUnlock()
+
+	if conn == nil {
+		return fmt.Errorf("连接未建立")
+	}
+
+	if err := conn.WriteJSON(privateMsg("spot.orders", timestamp)); err != nil {
+		return fmt.Errorf("订阅订单频道失败: %w", err)
+	}
+	if err := conn.WriteJSON(privateMsg("spot.usertrades", timestamp+1)); err != nil {
+		return fmt.Errorf("订阅成交频道失败: %w", err)
+	}
+	if err := conn.WriteJSON(tickerMsg); err != nil {
+		return fmt.Errorf("订阅价格频道失败: %w", err)
+	}
+
+	logger.Info("✅ [Gate WS] 已订阅现货频道: orders, usertrades, tickers")
+	return nil
+}
+
+// handleSpotOrderUpdate 处理现货订单推送
+// event 为 finish 时订单结束，finish_as 为 filled 表示完全成交，其余均视为撤销
+func (w *WebSocketManager) handleSpotOrderUpdate(msg map[string]interface{}) {
+	result, ok := msg["result"].([]interface{})
+	if !ok || len(result) == 0 {
+		return
+	}
+
+	for _, item := range result {
+		data, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		idStr, _ := data["id"].(string)
+		orderID, _ := strconv.ParseInt(idStr, 10, 64)
+		pair, _ := data["currency_pair"].(string)
+		side, _ := data["side"].(string)
+		text, _ := data["text"].(string)
+		event, _ := data["event"].(string)
+		finishAs, _ := data["finish_as"].(string)
+		amount, _ := parseFloat(data["amount"])
+		left, _ := parseFloat(data["left"])
+		price, _ := parseFloat(data["price"])
+		avgPrice, _ := parseFloat(data["avg_deal_price"])
+		updateTime, _ := parseFloat(data["update_time_ms"])
+
+		executedQty := amount - left
+		if executedQty < 0 {
+			executedQty = 0
+		}
+
+		var status OrderStatus
+		switch {
+		case event == "finish" && finishAs == "filled":
+			status = "FILLED"
+		case event == "finish":
+			status = "CANCELED"
+		case executedQty > 0:
+			status = "PARTIALLY_FILLED"
+		default:
+			status = OrderStatusNew
+		}
+
+		update := OrderUpdate{
+			OrderID:       orderID,
+			ClientOrderID: utils.RemoveBrokerPrefix("gate", text),
+			Symbol:        convertFromGateSymbol(pair),
+			Side:          spotSide(side),
+			Type:          OrderTypeLimit,
+			Status:        status,
+			Price:         price,
+			Quantity:      amount,
+			ExecutedQty:   executedQty,
+			AvgPrice:      avgPrice,
+			UpdateTime:    int64(updateTime),
+		}
+
+		w.mu.RLock()
+		callback := w.orderCallback
+		w.mu.RUnlock()
+
+		if callback != nil {
+			callback(update)
+		}
+	}
+}
+
+// handleSpotUserTrades 处理现货成交明细推送（只携带 Fill，Status 为空）
+// 手续费币种：买入通常以基础币种扣除，卖出以计价币种扣除
+func (w *WebSocketManager) handleSpotUserTrades(msg map[string]interface{}) {
+	result, ok := msg["result"].([]interface{})
+	if !ok || len(result) == 0 {
+		return
+	}
+
+	for _, item := range result {
+		trade, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		tradeID, _ := parseFloat(trade["id"])
+		orderIDStr, _ := trade["order_id"].(string)
+		pair, _ := trade["currency_pair"].(string)
+		side, _ := trade["side"].(string)
+		text, _ := trade["text"].(string)
+		role, _ := trade["role"].(string)
+		feeCurrency, _ := trade["fee_currency"].(string)
+		amount, _ := parseFloat(trade["amount"])
+		price, _ := parseFloat(trade["price"])
+		fee, _ := parseFloat(trade["fee"])
+		createTimeMs, _ := parseFloat(trade["create_time_ms"])
+
+		orderID, _ := strconv.ParseInt(orderIDStr, 10, 64)
+		update := OrderUpdate{
+			OrderID:       orderID,
+			ClientOrderID: utils.RemoveBrokerPrefix("gate", text),
+			Symbol:        convertFromGateSymbol(pair),
+			Side:          spotSide(side),
+			Type:          OrderTypeLimit,
+			Fill: &FillEvent{
+				TradeID:         strconv.FormatInt(int64(tradeID), 10),
+				Price:           price,
+				Quantity:        amount,
+				Commission:      fee,
+				CommissionAsset: strings.ToUpper(feeCurrency),
+				IsMaker:         role == "maker",
+				Time:            int64(createTimeMs),
+			},
+		}
+
+		w.mu.RLock()
+		callback := w.orderCallback
+		w.mu.RUnlock()
+
+		if callback != nil {
+			callback(update)
+		}
+	}
+}
+
+// handleSpotTickerUpdate 处理现货价格推送（result 为单个对象）
+func (w *WebSocketManager) handleSpotTickerUpdate(msg map[string]interface{}) {
+	ticker, ok := msg["result"].(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	pair, _ := ticker["currency_pair"].(string)
+	last, _ := parseFloat(ticker["last"])
+	if last <= 0 {
+		return
+	}
+
+	w.priceMu.Lock()
+	w.latestPrice = last
+	w.priceMu.Unlock()
+
+	w.mu.RLock()
+	callback := w.priceCallback
+	w.mu.RUnlock()
+
+	if callback != nil {
+		callback(convertFromGateSymbol(pair), last)
+	}
+}
+
+// spotSide 转换现货买卖方向
+func spotSide(side string) Side {
+	if side == "sell" {
+		return SideSell
+	}
+	return SideBuy
+}
@@ -1,6 +1,9 @@
 package gate
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // 为了避免循环导入，在这里定义需要的接口和类型
 // 这些类型应该与 exchange/types.go 中的定义保持一致
@@ -35,7 +38,8 @@ type OrderRequest struct {
 	Quantity      float64
 	Price         float64
 	ReduceOnly    bool
-	PostOnly      bool // 是否只做 Maker（Post Only）
+	PositionSide  string // 持仓方向 LONG/SHORT（双向持仓模式），空表示单向持仓
+	PostOnly      bool   // 是否只做 Maker（Post Only）
 	PriceDecimals int
 	ClientOrderID string // 自定义订单ID
 }
@@ -64,6 +68,7 @@ type Position struct {
 	Leverage       int
 	MarginType     string
 	IsolatedMargin float64
+	Side           string // 持仓方向 LONG/SHORT（双向持仓模式），单向持仓为空
 }
 
 type Account struct {
@@ -75,6 +80,22 @@ type Account struct {
 	AccountLeverage    int    // 账户级别的杠杆倍数
 }
 
+// SymbolInfo 合约交易规则（数量口径为币数量）
+type SymbolInfo struct {
+	Symbol             string
+	BaseAsset          string
+	QuoteAsset         string
+	TickSize           float64 // 价格最小变动单位
+	StepSize           float64 // 数量最小变动单位
+	MinQty             float64 // 最小下单数量
+	MinNotional        float64 // 最小名义价值，0 表示无限制
+	ContractMultiplier float64 // 合约乘数（每张合约对应的币数量）
+	ContractValue      float64 // 币本位合约面值（每张合约对应的 USD 金额），U本位与现货为 0
+	MaxLeverage        int     // 最大杠杆倍数，0 表示未知
+	PriceDecimals      int
+	QuantityDecimals   int
+}
+
 type OrderUpdate struct {
 	OrderID       int64
 	ClientOrderID string
@@ -87,6 +108,72 @@ type OrderUpdate struct {
 	ExecutedQty   float64
 	AvgPrice      float64
 	UpdateTime    int64
+	Fill          *FillEvent // 成交明细（来自 futures.usertrades 单独推送，此时 Status 为空）
+}
+
+// FillEvent 单笔成交明细
+type FillEvent struct {
+	TradeID         string
+	Price           float64
+	Quantity        float64
+	Commission      float64 // 正数为支出，负数为返佣
+	CommissionAsset string
+	IsMaker         bool
+	Time            int64
+}
+
+// BalanceUpdate 资产余额变化
+type BalanceUpdate struct {
+	Asset            string
+	WalletBalance    float64
+	AvailableBalance float64
+	HasAvailable     bool // 交易所是否推送了可用余额
+}
+
+// AccountUpdate 账户推送（余额与持仓变化）
+type AccountUpdate struct {
+	Balances          []BalanceUpdate
+	Positions         []*Position
+	PositionsSnapshot bool // Positions 是否为全部持仓快照
+	UpdateTime        int64
+}
+
+type AccountUpdateCallback func(update *AccountUpdate)
+
+// FundingRate 资金费率与标记价格
+type FundingRate struct {
+	Symbol          string
+	FundingRate     float64
+	MarkPrice       float64
+	IndexPrice      float64
+	NextFundingTime int64
+	Time            int64
+}
+
+type MarkPriceCallback func(update *FundingRate)
+
+// FundingPayment 资金费收付记录
+type FundingPayment struct {
+	ID     string
+	Symbol string
+	Amount float64 // 正数为收入，负数为支出
+	Asset  string
+	Time   int64
+}
+
+// Trade 账户成交记录（合约数量已按合约乘数换算为币数量，与订单推送口径一致）
+type Trade struct {
+	TradeID         string
+	OrderID         int64
+	ClientOrderID   string
+	Symbol          string
+	Side            Side
+	Price           float64
+	Quantity        float64
+	Commission      float64 // 正数为支出，负数为返佣
+	CommissionAsset string
+	IsMaker         bool
+	Time            int64
 }
 
 // Candle K线数据
@@ -119,11 +206,40 @@ type ContractInfo struct {
 	OrderSizeMin      float64 `json:"order_size_min"`      // 最小下单数量
 	OrderSizeMax      float64 `json:"order_size_max"`      // 最大下单数量
 	OrderSizeRound    string  `json:"order_size_round"`    // 数量精度
+	LeverageMax       string  `json:"leverage_max"`        // 最大杠杆
 	OrderPriceDeviate string  `json:"order_price_deviate"` // 价格偏离百分比
 	RefDiscountRate   string  `json:"ref_discount_rate"`   // 推荐返佣率
 	OrderbookID       int64   `json:"orderbook_id"`        // 订单簿ID
 	TradeSize         float64 `json:"trade_size"`          // 最小交易张数
 	MarkPriceRound    string  `json:"mark_price_round"`    // 标记价格精度
+	MarkPrice         string  `json:"mark_price"`          // 标记价格
+	IndexPrice        string  `json:"index_price"`         // 指数价格
+	FundingRate       string  `json:"funding_rate"`        // 当期资金费率
+	FundingNextApply  float64 `json:"funding_next_apply"`  // 下次结算时间（秒）
+}
+
+// AccountBookEntry 合约账户流水
+type AccountBookEntry struct {
+	ID       json.Number `json:"id"`       // 流水ID（不同版本为字符串或数字）
+	Time     float64     `json:"time"`     // 时间（秒，含小数）
+	Change   string      `json:"change"`   // 变动金额
+	Balance  string      `json:"balance"`  // 变动后余额
+	Type     string      `json:"type"`     // 流水类型（fund 为资金费）
+	Text     string      `json:"text"`     // 备注
+	Contract string      `json:"contract"` // 合约名称
+}
+
+// FuturesTrade 合约成交记录（my_trades_timerange）
+type FuturesTrade struct {
+	TradeID    json.Number `json:"trade_id"`    // 成交ID
+	CreateTime float64     `json:"create_time"` // 成交时间（秒，含小数）
+	Contract   string      `json:"contract"`    // 合约名称
+	OrderID    string      `json:"order_id"`    // 订单ID
+	Size       int64       `json:"size"`        // 成交数量（正数买入，负数卖出）
+	Price      string      `json:"price"`       // 成交价格
+	Role       string      `json:"role"`        // taker/maker
+	Text       string      `json:"text"`        // 订单自定义信息
+	Fee        string      `json:"fee"`         // 手续费
 }
 
 // FuturesAccount Gate.io 合约账户信息
@@ -3,10 +3,14 @@ package gate
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"opensqt/exchange/wsapi"
 	"opensqt/logger"
 	"opensqt/utils"
 
@@ -20,12 +24,15 @@ type WebSocketManager struct {
 	signer    *Signer
 
 	// 连接管理
-	conn *websocket.Conn
-	mu   sync.RWMutex
+	conn    *websocket.Conn
+	mu      sync.RWMutex
+	writeMu sync.Mutex // 串行化写操作（ping、登录与下单可能并发）
 
 	// 回调函数
-	orderCallback func(interface{})
-	priceCallback func(string, float64) // symbol, price
+	orderCallback   func(interface{})
+	priceCallback   func(string, float64) // symbol, price
+	accountCallback AccountUpdateCallback
+	markCallback    MarkPriceCallback
 
 	// 控制
 	ctx    context.Context
@@ -42,6 +49,14 @@ type WebSocketManager struct {
 	subscribedSymbol string // 记录订阅的交易对，用于重连后重新订阅
 	settle           string // usdt 或 btc
 	isAuthenticated  bool   // 标记是否已认证
+	spot             bool   // 现货模式：连接现货地址并订阅 spot.* 频道（见 spot.go）
+	testnet          bool   // 连接合约测试网
+
+	// WebSocket 下单：连接后通过 futures.login 登录，下单请求按 req_id 关联响应
+	orderEntry     bool
+	pending        wsapi.Pending
+	requestTimeout time.Duration // 单个下单请求等待响应的超时
+	readTimeout    time.Duration // 超过该时间未收到任何消息（含 pong）视为半开连接
 }
 
 // NewWebSocketManager 创建 WebSocket 管理器
@@ -56,6 +71,8 @@ func NewWebSocketManager(apiKey, secretKey, settle string) *WebSocketManager {
 		reconnectChan:  make(chan struct{}, 1),
 		reconnectDelay: 5 * time.Second,
 		settle:         settle,
+		requestTimeout: wsapi.DefaultTimeout,
+		readTimeout:    wsapi.ReadTimeout,
 	}
 }
 
@@ -73,6 +90,20 @@ func (w *WebSocketManager) SetOrderCallback(callback func(interface{})) {
 	w.orderCallback = callback
 }
 
+// SetAccountCallback 设置账户推送回调（余额与持仓）
+func (w *WebSocketManager) SetAccountCallback(callback AccountUpdateCallback) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.accountCallback = callback
+}
+
+// SetMarkPriceCallback 设置标记价格回调（从 ticker 推送中解析）
+func (w *WebSocketManager) SetMarkPriceCallback(callback MarkPriceCallback) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.markCallback = callback
+}
+
 // IsRunning 检查 WebSocket 是否运行中
 func (w *WebSocketManager) IsRunning() bool {
 	w.mu.RLock()
@@ -80,6 +111,13 @@ func (w *WebSocketManager) IsRunning() bool {
 	return w.conn != nil
 }
 
+// IsStarted 检查连接循环是否已启动（可能仍在连接中）
+func (w *WebSocketManager) IsStarted() bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.ctx != nil
+}
+
 // GetLatestPrice 获取最新价格（从缓存）
 func (w *WebSocketManager) GetLatestPrice() float64 {
 	w.priceMu.RLock()
@@ -119,7 +157,10 @@ func (w *WebSocketManager) connectLoop() {
 		logger.Info("🔗 [Gate WS] 正在连接...")
 
 		// 连接 Gate.io WebSocket
-		wsURL := fmt.Sprintf("wss://fx-ws.gateio.ws/v4/ws/%s", w.settle)
+		wsURL := futuresWSURL(w.settle, w.testnet)
+		if w.spot {
+			wsURL = GateSpotWSURL
+		}
 		conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
 		if err != nil {
 			logger.Error("❌ [Gate WS] 连接失败: %v，%v后重试", err, w.reconnectDelay)
@@ -151,69 +192,85 @@ func (w *WebSocketManager) connectLoop() {
 			close(done)
 		}()
 
+		// 下单需要先登录，登录响应由读取循环处理
+		if w.orderEntry && !w.spot {
+			go w.login(conn)
+		}
+
 		// 启动读取循环（阻塞直到连接断开）
 		w.handleMessages(conn)
 
 		// 等待 keepAlive 退出
 		<-done
 
-		// 连接断开，清理
+		// 连接断开，清理；已发出未响应的下单请求状态未知，交由调用方对账
 		w.mu.Lock()
 		if w.conn == conn {
 			w.conn = nil
 			w.isAuthenticated = false
 		}
 		w.mu.Unlock()
+		w.pending.FailAll(wsapi.ErrDisconnected)
 
 		logger.Warn("⚠️ [Gate WS] 连接断开，%v后重连...", w.reconnectDelay)
 		time.Sleep(w.reconnectDelay)
 	}
 }
 
-// login 登录认证
-func (w *WebSocketManager) login() error {
-	timestamp := time.Now().Unix()
+// login 登录 WebSocket 交易接口（futures.login），登录成功后才能通过 futures.order_place 下单
+// 登录失败不影响订阅推送，下单自动使用 REST
+func (w *WebSocketManager) login(conn *websocket.Conn) {
 	channel := "futures.login"
-	event := "api"
 
-	// 生成签名
-	signature := w.signer.SignWebSocket(channel, event, timestamp)
-
-	// 根据 Gate.io 官方文档,认证信息应该在 auth 字段中
-	loginMsg := map[string]interface{}{
-		"time":    timestamp,
-		"channel": channel,
-		"event":   event,
-		"auth": map[string]interface{}{
-			"method": "api_key",
-			"KEY":    w.apiKey,
-			"SIGN":   signature,
-		},
-		"req_header": map[string]string{
-			"X-Gate-Channel-Id": GateChannelID, // 渠道返佣 ID
-		},
-	}
-
-	w.mu.RLock()
-	conn := w.conn
-	w.mu.RUnlock()
-
-	if conn == nil {
-		return fmt.Errorf("连接未建立")
+	ctx, cancel := context.WithTimeout(w.ctx, wsapi.DefaultTimeout)
+	defer cancel()
+
+	_, err := w.pending.Do(ctx, wsapi.DefaultTimeout, func(reqID string) error {
+		timestamp := w.signer.GetTimestamp()
+		loginMsg := map[string]interface{}{
+			"time":    timestamp,
+			"channel": channel,
+			"event":   "api",
+			"payload": map[string]interface{}{
+				"api_key":   w.apiKey,
+				"signature": w.signer.SignWebSocketAPI(channel, "", timestamp),
+				"timestamp": strconv.FormatInt(timestamp, 10),
+				"req_id":    reqID,
+				"req_header": map[string]string{
+					"X-Gate-Channel-Id": GateChannelID, // 渠道返佣 ID
+				},
+			},
+		}
+		return w.writeJSON(conn, loginMsg)
+	})
+	if err != nil {
+		logger.Warn("⚠️ [Gate WS] 下单通道登录失败: %v，下单将使用 REST", err)
+		return
 	}
 
-	if err := conn.WriteJSON(loginMsg); err != nil {
-		return fmt.Errorf("发送登录消息失败: %w", err)
+	w.mu.Lock()
+	if w.conn == conn {
+		w.isAuthenticated = true
 	}
+	w.mu.Unlock()
+	logger.Info("✅ [Gate WS] 下单通道登录成功")
+}
 
-	logger.Info("✅ [Gate WS] 已发送登录请求")
-	return nil
+// writeJSON 向连接写入 JSON 消息（串行化并发写）
+func (w *WebSocketManager) writeJSON(conn *websocket.Conn, v interface{}) error {
+	w.writeMu.Lock()
+	defer w.writeMu.Unlock()
+	return conn.WriteJSON(v)
 }
 
 // subscribeChannels 订阅频道
 func (w *WebSocketManager) subscribeChannels(symbol string) error {
+	if w.spot {
+		return w.subscribeSpotChannels(symbol)
+	}
+
 	gateSymbol := convertToGateSymbol(symbol)
-	timestamp := time.Now().Unix()
+	timestamp := w.signer.GetTimestamp()
 
 	// 订阅订单更新（私有频道需要认证）
 	ordersSign := w.signer.SignWebSocket("futures.orders", "subscribe", timestamp)
@@ -229,7 +286,7 @@ func (w *WebSocketManager) subscribeChannels(symbol string) error {
 		"req_header": map[string]string{
 			"X-Gate-Channel-Id": GateChannelID,
 		},
-		"payload": []string{w.apiKey, gateSymbol},
+		"payload": []string{w.apiKey, "!all"}, // 订阅账户下所有合约，由上层按交易对分发
 	}
 
 	// 订阅余额更新（私有频道需要认证）
@@ -249,6 +306,40 @@ func (w *WebSocketManager) subscribeChannels(symbol string) error {
 		"payload": []string{w.apiKey},
 	}
 
+	// 订阅成交明细（私有频道需要认证），用于获取成交价、手续费与 Maker/Taker 标记
+	tradesSign := w.signer.SignWebSocket("futures.usertrades", "subscribe", timestamp+3)
+	tradesMsg := map[string]interface{}{
+		"time":    timestamp + 3,
+		"channel": "futures.usertrades",
+		"event":   "subscribe",
+		"auth": map[string]interface{}{
+			"method": "api_key",
+			"KEY":    w.apiKey,
+			"SIGN":   tradesSign,
+		},
+		"req_header": map[string]string{
+			"X-Gate-Channel-Id": GateChannelID,
+		},
+		"payload": []string{w.apiKey, "!all"},
+	}
+
+	// 订阅持仓更新（私有频道需要认证），每次推送发生变化的合约
+	positionsSign := w.signer.SignWebSocket("futures.positions", "subscribe", timestamp+4)
+	positionsMsg := map[string]interface{}{
+		"time":    timestamp + 4,
+		"channel": "futures.positions",
+		"event":   "subscribe",
+		"auth": map[string]interface{}{
+			"method": "api_key",
+			"KEY":    w.apiKey,
+			"SIGN":   positionsSign,
+		},
+		"req_header": map[string]string{
+			"X-Gate-Channel-Id": GateChannelID,
+		},
+		"payload": []string{w.apiKey, "!all"},
+	}
+
 	// 订阅价格更新（ticker）
 	tickerMsg := map[string]interface{}{
 		"time":    timestamp + 2,
@@ -270,15 +361,23 @@ func (w *WebSocketManager) subscribeChannels(symbol string) error {
 		return fmt.Errorf("订阅订单频道失败: %w", err)
 	}
 
+	if err := conn.WriteJSON(tradesMsg); err != nil {
+		return fmt.Errorf("订阅成交频道失败: %w", err)
+	}
+
 	if err := conn.WriteJSON(balanceMsg); err != nil {
 		return fmt.Errorf("订阅余额频道失败: %w", err)
 	}
 
+	if err := conn.WriteJSON(positionsMsg); err != nil {
+		return fmt.Errorf("订阅持仓频道失败: %w", err)
+	}
+
 	if err := conn.WriteJSON(tickerMsg); err != nil {
 		return fmt.Errorf("订阅价格频道失败: %w", err)
 	}
 
-	logger.Info("✅ [Gate WS] 已订阅频道: orders, balances, tickers")
+	logger.Info("✅ [Gate WS] 已订阅频道: orders, usertrades, balances, positions, tickers")
 	return nil
 }
 
@@ -301,12 +400,16 @@ func (w *WebSocketManager) keepAlive(conn *websocket.Conn) {
 			}
 
 			// Gate.io 使用 ping 消息
+			pingChannel := "futures.ping"
+			if w.spot {
+				pingChannel = "spot.ping"
+			}
 			pingMsg := map[string]interface{}{
 				"time":    time.Now().Unix(),
-				"channel": "futures.ping",
+				"channel": pingChannel,
 			}
 
-			if err := conn.WriteJSON(pingMsg); err != nil {
+			if err := w.writeJSON(conn, pingMsg); err != nil {
 				logger.Warn("⚠️ [Gate WS] Ping 失败: %v", err)
 				return
 			}
@@ -315,6 +418,7 @@ func (w *WebSocketManager) keepAlive(conn *websocket.Conn) {
 }
 
 // handleMessages 处理消息循环
+// 每条消息（含 keepAlive 的 pong）都顺延读超时，半开连接在 readTimeout 后读取失败并重连
 func (w *WebSocketManager) handleMessages(conn *websocket.Conn) {
 	for {
 		select {
@@ -323,6 +427,7 @@ func (w *WebSocketManager) handleMessages(conn *websocket.Conn) {
 		default:
 		}
 
+		conn.SetReadDeadline(time.Now().Add(w.readTimeout))
 		_, message, err := conn.ReadMessage()
 		if err != nil {
 			logger.Warn("⚠️ [Gate WS] 读取消息失败: %v", err)
@@ -341,6 +446,12 @@ func (w *WebSocketManager) handleMessage(message []byte) {
 		return
 	}
 
+	// 交易接口（event=api）的响应携带 request_id，交给等待中的请求
+	if reqID, ok := msg["request_id"].(string); ok && reqID != "" {
+		w.handleAPIResponse(reqID, msg)
+		return
+	}
+
 	// 检查错误
 	if errObj, ok := msg["error"].(map[string]interface{}); ok {
 		logger.Error("❌ [Gate WS] 错误: %v", errObj)
@@ -365,45 +476,56 @@ func (w *WebSocketManager) handleMessage(message []byte) {
 		switch channel {
 		case "futures.orders":
 			w.handleOrderUpdate(msg)
+		case "futures.usertrades":
+			w.handleUserTrades(msg)
 		case "futures.balances":
-			// 余额更新（可选实现）
-			logger.Debug("[Gate WS] 余额更新")
+			w.handleBalanceUpdate(msg)
+		case "futures.positions":
+			w.handlePositionUpdate(msg)
 		case "futures.tickers":
 			w.handleTickerUpdate(msg)
+		case "spot.orders":
+			w.handleSpotOrderUpdate(msg)
+		case "spot.usertrades":
+			w.handleSpotUserTrades(msg)
+		case "spot.tickers":
+			w.handleSpotTickerUpdate(msg)
 		}
 
 	case "pong":
 		// Pong 响应（静默处理）
 
 	default:
-		// 检查是否是登录响应
-		if channel == "futures.login" {
-			// Gate.io 登录响应在 header.status 中
-			if header, ok := msg["header"].(map[string]interface{}); ok {
-				status, _ := header["status"].(string)
-				if status == "200" {
-					w.mu.Lock()
-					w.isAuthenticated = true
-					w.mu.Unlock()
-					logger.Info("✅ [Gate WS] 登录成功")
-				} else {
-					// 解析错误信息
-					errMsg := status
-					if data, ok := msg["data"].(map[string]interface{}); ok {
-						if errs, ok := data["errs"].(map[string]interface{}); ok {
-							if message, ok := errs["message"].(string); ok {
-								errMsg = message
-							}
-						}
-					}
-					logger.Warn("⚠️ [Gate WS] 登录失败: %s", errMsg)
-				}
-			}
-		} else {
-			// 打印未处理的事件用于调试
-			logger.Debug("[Gate WS] 未处理的事件: event=%s, channel=%s", event, channel)
+		// 打印未处理的事件用于调试
+		logger.Debug("[Gate WS] 未处理的事件: event=%s, channel=%s", event, channel)
+	}
+}
+
+// handleAPIResponse 处理交易接口响应
+// 下单请求先返回 ack=true 的确认消息，再返回携带订单的结果消息；错误在 header.status 与 data.errs 中
+func (w *WebSocketManager) handleAPIResponse(reqID string, msg map[string]interface{}) {
+	if ack, _ := msg["ack"].(bool); ack {
+		return
+	}
+
+	header, _ := msg["header"].(map[string]interface{})
+	status, _ := header["status"].(string)
+	data, _ := msg["data"].(map[string]interface{})
+
+	if status != "200" {
+		label, message := "", status
+		if errObj, ok := data["errs"].(map[string]interface{}); ok {
+			label, _ = errObj["label"].(string)
+			message, _ = errObj["message"].(string)
 		}
+		statusCode, _ := strconv.Atoi(status)
+		err := classifyError(statusCode, label, fmt.Errorf("Gate.io WebSocket 请求失败: %s %s", label, message))
+		w.pending.Resolve(reqID, nil, err)
+		return
 	}
+
+	result, err := json.Marshal(data["result"])
+	w.pending.Resolve(reqID, result, err)
 }
 
 // handleOrderUpdate 处理订单更新
@@ -463,6 +585,138 @@ func (w *WebSocketManager) handleOrderUpdate(msg map[string]interface{}) {
 	}
 }
 
+// handleUserTrades 处理成交明细推送
+// 成交明细与订单状态分开推送，这里只携带 Fill（Status 为空），数量单位为合约张数
+func (w *WebSocketManager) handleUserTrades(msg map[string]interface{}) {
+	result, ok := msg["result"].([]interface{})
+	if !ok || len(result) == 0 {
+		return
+	}
+
+	for _, item := range result {
+		trade, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		tradeID, _ := trade["id"].(string)
+		orderIDStr, _ := trade["order_id"].(string)
+		contract, _ := trade["contract"].(string)
+		size, _ := trade["size"].(float64)
+		price, _ := parseFloat(trade["price"])
+		fee, _ := parseFloat(trade["fee"])
+		role, _ := trade["role"].(string)
+		text, _ := trade["text"].(string)
+		createTimeMs, _ := trade["create_time_ms"].(float64)
+
+		orderID, _ := strconv.ParseInt(orderIDStr, 10, 64)
+		update := OrderUpdate{
+			OrderID:       orderID,
+			ClientOrderID: utils.RemoveBrokerPrefix("gate", text),
+			Symbol:        convertFromGateSymbol(contract),
+			Side:          convertSide(size),
+			Type:          OrderTypeLimit,
+			Fill: &FillEvent{
+				TradeID:         tradeID,
+				Price:           price,
+				Quantity:        abs(size),
+				Commission:      fee,
+				CommissionAsset: strings.ToUpper(w.settle),
+				IsMaker:         role == "maker",
+				Time:            int64(createTimeMs),
+			},
+		}
+
+		w.mu.RLock()
+		callback := w.orderCallback
+		w.mu.RUnlock()
+
+		if callback != nil {
+			callback(update)
+		}
+	}
+}
+
+// handleBalanceUpdate 处理余额推送（balance 为变动后的钱包余额，不含可用余额）
+func (w *WebSocketManager) handleBalanceUpdate(msg map[string]interface{}) {
+	w.mu.RLock()
+	callback := w.accountCallback
+	w.mu.RUnlock()
+
+	result, ok := msg["result"].([]interface{})
+	if callback == nil || !ok || len(result) == 0 {
+		return
+	}
+
+	update := &AccountUpdate{}
+	for _, item := range result {
+		data, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		balance, _ := parseFloat(data["balance"])
+		currency, _ := data["currency"].(string)
+		timeMs, _ := data["time_ms"].(float64)
+		if currency == "" {
+			currency = w.settle
+		}
+
+		update.Balances = append(update.Balances, BalanceUpdate{
+			Asset:         strings.ToUpper(currency),
+			WalletBalance: balance,
+		})
+		update.UpdateTime = int64(timeMs)
+	}
+	callback(update)
+}
+
+// handlePositionUpdate 处理持仓推送（只推送发生变化的合约，数量单位为合约张数）
+func (w *WebSocketManager) handlePositionUpdate(msg map[string]interface{}) {
+	w.mu.RLock()
+	callback := w.accountCallback
+	w.mu.RUnlock()
+
+	result, ok := msg["result"].([]interface{})
+	if callback == nil || !ok || len(result) == 0 {
+		return
+	}
+
+	update := &AccountUpdate{}
+	for _, item := range result {
+		data, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		contract, _ := data["contract"].(string)
+		mode, _ := data["mode"].(string)
+		size, _ := parseFloat(data["size"])
+		entryPrice, _ := parseFloat(data["entry_price"])
+		margin, _ := parseFloat(data["margin"])
+		leverage, _ := parseFloat(data["leverage"])
+		crossLeverage, _ := parseFloat(data["cross_leverage_limit"])
+		timeMs, _ := data["time_ms"].(float64)
+
+		// leverage 为 0 表示全仓，此时杠杆倍数取 cross_leverage_limit
+		marginType := "isolated"
+		if leverage == 0 {
+			marginType = "crossed"
+			leverage = crossLeverage
+		}
+
+		update.Positions = append(update.Positions, &Position{
+			Symbol:         convertFromGateSymbol(contract),
+			Size:           size,
+			EntryPrice:     entryPrice,
+			Leverage:       int(leverage),
+			MarginType:     marginType,
+			IsolatedMargin: margin,
+			Side:           dualPositionSide(mode),
+		})
+		update.UpdateTime = int64(timeMs)
+	}
+	callback(update)
+}
+
 // handleTickerUpdate 处理价格更新
 func (w *WebSocketManager) handleTickerUpdate(msg map[string]interface{}) {
 	result, ok := msg["result"].([]interface{})
@@ -494,47 +748,84 @@ func (w *WebSocketManager) handleTickerUpdate(msg map[string]interface{}) {
 		if callback != nil {
 			callback(symbol, last)
 		}
-	}
-}
-
-// PlaceOrder 通过 WebSocket 下单（带渠道码）
-func (w *WebSocketManager) PlaceOrder(order map[string]interface{}) error {
-	timestamp := time.Now().Unix()
 
-	// 🔥 重要：构造带渠道码的 Payload
-	payload := map[string]interface{}{
-		"req_header": map[string]string{
-			"X-Gate-Channel-Id": GateChannelID, // 渠道返佣标识
-		},
-		"req_id":    fmt.Sprintf("order_%d", timestamp),
-		"req_param": order,
-	}
+		// ticker 推送同时携带标记价格与资金费率
+		w.mu.RLock()
+		markCallback := w.markCallback
+		w.mu.RUnlock()
 
-	orderMsg := map[string]interface{}{
-		"time":    timestamp,
-		"channel": "futures.order_place",
-		"event":   "api",
-		"payload": payload,
+		markPrice, _ := parseFloat(tickerData["mark_price"])
+		if markCallback != nil && markPrice > 0 {
+			indexPrice, _ := parseFloat(tickerData["index_price"])
+			fundingRate, _ := parseFloat(tickerData["funding_rate"])
+			timeMs, _ := msg["time_ms"].(float64)
+			markCallback(&FundingRate{
+				Symbol:      symbol,
+				FundingRate: fundingRate,
+				MarkPrice:   markPrice,
+				IndexPrice:  indexPrice,
+				Time:        int64(timeMs),
+			})
+		}
 	}
+}
 
+// PlaceOrder 通过 WebSocket 下单（带渠道码），等待交易所返回订单
+// 连接未建立或未登录时返回 wsapi.ErrNotConnected，调用方可改用 REST；
+// 请求超时（非调用方取消）时断开当前连接并返回 wsapi.ErrTimeout，订单是否受理需由调用方查单确认
+func (w *WebSocketManager) PlaceOrder(ctx context.Context, order map[string]interface{}) (*FuturesOrder, error) {
 	w.mu.RLock()
 	conn := w.conn
 	authenticated := w.isAuthenticated
 	w.mu.RUnlock()
 
 	if conn == nil {
-		return fmt.Errorf("连接未建立")
+		return nil, fmt.Errorf("%w: 连接未建立", wsapi.ErrNotConnected)
 	}
-
 	if !authenticated {
-		return fmt.Errorf("未认证")
+		return nil, fmt.Errorf("%w: 未登录", wsapi.ErrNotConnected)
+	}
+
+	data, err := w.pending.Do(ctx, w.requestTimeout, func(reqID string) error {
+		// 🔥 重要：构造带渠道码的 Payload
+		orderMsg := map[string]interface{}{
+			"time":    w.signer.GetTimestamp(),
+			"channel": "futures.order_place",
+			"event":   "api",
+			"payload": map[string]interface{}{
+				"req_header": map[string]string{
+					"X-Gate-Channel-Id": GateChannelID, // 渠道返佣标识
+				},
+				"req_id":    reqID,
+				"req_param": order,
+			},
+		}
+		return w.writeJSON(conn, orderMsg)
+	})
+	if err != nil {
+		if errors.Is(err, wsapi.ErrTimeout) && ctx.Err() == nil {
+			logger.Warn("⚠️ [Gate WS] 下单请求超时，断开连接重连: %v", err)
+			w.dropConn(conn)
+		}
+		return nil, err
 	}
 
-	if err := conn.WriteJSON(orderMsg); err != nil {
-		return fmt.Errorf("发送下单消息失败: %w", err)
+	var futuresOrder FuturesOrder
+	if err := json.Unmarshal(data, &futuresOrder); err != nil {
+		return nil, fmt.Errorf("解析订单响应失败: %w", err)
 	}
+	return &futuresOrder, nil
+}
 
-	return nil
+// dropConn 请求超时说明连接可能已半开：立即停止使用该连接并关闭，由连接循环重连
+func (w *WebSocketManager) dropConn(conn *websocket.Conn) {
+	w.mu.Lock()
+	if w.conn == conn {
+		w.conn = nil
+		w.isAuthenticated = false
+	}
+	w.mu.Unlock()
+	conn.Close()
 }
 
 // Stop 停止 WebSocket
@@ -0,0 +1,258 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"opensqt/exchange/errs"
+)
+
+// governedExchange 为交易所实例接入账户级请求额度管理
+// REST 请求在发出前按优先级与接口权重等待额度，返回速率限制错误时通知 RateGovernor 暂停；
+// WebSocket 订阅与本地缓存读取（精度、最新价格等）不占用额度，直接转发
+type governedExchange struct {
+	IExchange
+	governor *RateGovernor
+}
+
+// newGovernedExchange 包装交易所实例
+func newGovernedExchange(ex IExchange, governor *RateGovernor) IExchange {
+	return &governedExchange{IExchange: ex, governor: governor}
+}
+
+// wait 按接口的优先级与权重等待额度，n 为接口的规模参数（见 venueLimits.weight）
+func (g *governedExchange) wait(ctx context.Context, op string, n int) error {
+	return g.governor.WaitRequest(ctx, op, n)
+}
+
+// observe 请求返回速率限制错误时暂停后续请求（错误携带 Retry-After 时按其等待，否则按次数退避）
+func (g *governedExchange) observe(err error) {
+	if errors.Is(err, ErrRateLimited) {
+		g.governor.ObserveRateLimited(errs.RetryAfter(err), false)
+	}
+}
+
+// === 订单相关（最高优先级） ===
+
+func (g *governedExchange) PlaceOrder(ctx context.Context, req *OrderRequest) (*Order, error) {
+	if err := g.wait(ctx, "PlaceOrder", 1); err != nil {
+		return nil, err
+	}
+	order, err := g.IExchange.PlaceOrder(ctx, req)
+	g.observe(err)
+	return order, err
+}
+
+// BatchPlaceOrders 批量下单的逐单错误不通过返回值传出，由适配器经 errs.Report 上报，
+// 其中的限频错误同样暂停后续请求
+func (g *governedExchange) BatchPlaceOrders(ctx context.Context, orders []*OrderRequest) ([]*Order, bool) {
+	if err := g.wait(ctx, "BatchPlaceOrders", len(orders)); err != nil {
+		return nil, false
+	}
+	return g.IExchange.BatchPlaceOrders(errs.WithReporter(ctx, g.observe), orders)
+}
+
+func (g *governedExchange) CancelOrder(ctx context.Context, symbol string, orderID int64) error {
+	if err := g.wait(ctx, "CancelOrder", 1); err != nil {
+		return err
+	}
+	err := g.IExchange.CancelOrder(ctx, symbol, orderID)
+	g.observe(err)
+	return err
+}
+
+func (g *governedExchange) BatchCancelOrders(ctx context.Context, symbol string, orderIDs []int64) error {
+	if err := g.wait(ctx, "BatchCancelOrders", len(orderIDs)); err != nil {
+		return err
+	}
+	err := g.IExchange.BatchCancelOrders(ctx, symbol, orderIDs)
+	g.observe(err)
+	return err
+}
+
+func (g *governedExchange) AmendOrder(ctx context.Context, req *OrderRequest, orderID int64, newPrice, newQty float64) (*Order, error) {
+	if err := g.wait(ctx, "AmendOrder", 1); err != nil {
+		return nil, err
+	}
+	order, err := g.IExchange.AmendOrder(ctx, req, orderID, newPrice, newQty)
+	g.observe(err)
+	return order, err
+}
+
+func (g *governedExchange) CancelAllOrders(ctx context.Context, symbol string) error {
+	if err := g.wait(ctx, "CancelAllOrders", 1); err != nil {
+		return err
+	}
+	err := g.IExchange.CancelAllOrders(ctx, symbol)
+	g.observe(err)
+	return err
+}
+
+// === 账户与订单查询 ===
+
+func (g *governedExchange) GetOrder(ctx context.Context, symbol string, orderID int64) (*Order, error) {
+	if err := g.wait(ctx, "GetOrder", 1); err != nil {
+		return nil, err
+	}
+	order, err := g.IExchange.GetOrder(ctx, symbol, orderID)
+	g.observe(err)
+	return order, err
+}
+
+func (g *governedExchange) GetOpenOrders(ctx context.Context, symbol string) ([]*Order, error) {
+	n := 1
+	if symbol == "" {
+		n = 0
+	}
+	if err := g.wait(ctx, "GetOpenOrders", n); err != nil {
+		return nil, err
+	}
+	orders, err := g.IExchange.GetOpenOrders(ctx, symbol)
+	g.observe(err)
+	return orders, err
+}
+
+func (g *governedExchange) GetMyTrades(ctx context.Context, symbol string, since time.Time, limit int) ([]*Trade, error) {
+	if err := g.wait(ctx, "GetMyTrades", 1); err != nil {
+		return nil, err
+	}
+	trades, err := g.IExchange.GetMyTrades(ctx, symbol, since, limit)
+	g.observe(err)
+	return trades, err
+}
+
+func (g *governedExchange) GetAccount(ctx context.Context) (*Account, error) {
+	if err := g.wait(ctx, "GetAccount", 1); err != nil {
+		return nil, err
+	}
+	account, err := g.IExchange.GetAccount(ctx)
+	g.observe(err)
+	return account, err
+}
+
+func (g *governedExchange) GetPositions(ctx context.Context, symbol string) ([]*Position, error) {
+	if err := g.wait(ctx, "GetPositions", 1); err != nil {
+		return nil, err
+	}
+	positions, err := g.IExchange.GetPositions(ctx, symbol)
+	g.observe(err)
+	return positions, err
+}
+
+func (g *governedExchange) GetBalance(ctx context.Context, asset string) (float64, error) {
+	if err := g.wait(ctx, "GetBalance", 1); err != nil {
+		return 0, err
+	}
+	balance, err := g.IExchange.GetBalance(ctx, asset)
+	g.observe(err)
+	return balance, err
+}
+
+func (g *governedExchange) GetFundingPayments(ctx context.Context, symbol string, since time.Time) ([]*FundingPayment, error) {
+	if err := g.wait(ctx, "GetFundingPayments", 1); err != nil {
+		return nil, err
+	}
+	payments, err := g.IExchange.GetFundingPayments(ctx, symbol, since)
+	g.observe(err)
+	return payments, err
+}
+
+// === 账户设置（启动时调用，按账户查询优先级） ===
+
+func (g *governedExchange) SetLeverage(ctx context.Context, symbol string, leverage int) error {
+	if err := g.wait(ctx, "SetLeverage", 1); err != nil {
+		return err
+	}
+	err := g.IExchange.SetLeverage(ctx, symbol, leverage)
+	g.observe(err)
+	return err
+}
+
+func (g *governedExchange) SetMarginType(ctx context.Context, symbol string, marginType MarginType) error {
+	if err := g.wait(ctx, "SetMarginType", 1); err != nil {
+		return err
+	}
+	err := g.IExchange.SetMarginType(ctx, symbol, marginType)
+	g.observe(err)
+	return err
+}
+
+func (g *governedExchange) SetPositionMode(ctx context.Context, mode PositionMode) error {
+	if err := g.wait(ctx, "SetPositionMode", 1); err != nil {
+		return err
+	}
+	err := g.IExchange.SetPositionMode(ctx, mode)
+	g.observe(err)
+	return err
+}
+
+// === 市场数据（最低优先级） ===
+
+func (g *governedExchange) GetMarkPrice(ctx context.Context, symbol string) (float64, error) {
+	if err := g.wait(ctx, "GetMarkPrice", 1); err != nil {
+		return 0, err
+	}
+	price, err := g.IExchange.GetMarkPrice(ctx, symbol)
+	g.observe(err)
+	return price, err
+}
+
+func (g *governedExchange) GetFundingRate(ctx context.Context, symbol string) (*FundingRate, error) {
+	if err := g.wait(ctx, "GetFundingRate", 1); err != nil {
+		return nil, err
+	}
+	rate, err := g.IExchange.GetFundingRate(ctx, symbol)
+	g.observe(err)
+	return rate, err
+}
+
+func (g *governedExchange) GetOrderBook(ctx context.Context, symbol string, depth int) (*OrderBook, error) {
+	if err := g.wait(ctx, "GetOrderBook", depth); err != nil {
+		return nil, err
+	}
+	book, err := g.IExchange.GetOrderBook(ctx, symbol, depth)
+	g.observe(err)
+	return book, err
+}
+
+func (g *governedExchange) GetHistoricalKlines(ctx context.Context, symbol string, interval string, limit int) ([]*Candle, error) {
+	if err := g.wait(ctx, "GetHistoricalKlines", limit); err != nil {
+		return nil, err
+	}
+	candles, err := g.IExchange.GetHistoricalKlines(ctx, symbol, interval, limit)
+	g.observe(err)
+	return candles, err
+}
+
+// GetHistoricalKlinesRange 按单次请求上限切分时间窗口，每个窗口单独等待额度，长区间回补不会挤占下单额度
+// 分页只在这里做一次：逐页调用 wrapper 的单页查询，而不是其自带分页的 GetHistoricalKlinesRange
+func (g *governedExchange) GetHistoricalKlinesRange(ctx context.Context, symbol string, interval string, start, end time.Time) ([]*Candle, error) {
+	pageSize := g.Capabilities().KlinesPerRequest
+	pager, ok := g.IExchange.(klinePager)
+	if !ok {
+		if err := g.wait(ctx, "GetHistoricalKlines", pageSize); err != nil {
+			return nil, err
+		}
+		candles, err := g.IExchange.GetHistoricalKlinesRange(ctx, symbol, interval, start, end)
+		g.observe(err)
+		return candles, err
+	}
+	return paginateKlines(ctx, interval, start, end, pageSize, func(ctx context.Context, from, to time.Time) ([]*Candle, error) {
+		if err := g.wait(ctx, "GetHistoricalKlines", pageSize); err != nil {
+			return nil, err
+		}
+		candles, err := pager.getKlinesPage(ctx, symbol, interval, from, to)
+		g.observe(err)
+		return candles, err
+	})
+}
+
+func (g *governedExchange) GetSymbolInfo(ctx context.Context, symbol string) (*SymbolInfo, error) {
+	if err := g.wait(ctx, "GetSymbolInfo", 1); err != nil {
+		return nil, err
+	}
+	info, err := g.IExchange.GetSymbolInfo(ctx, symbol)
+	g.observe(err)
+	return info, err
+}
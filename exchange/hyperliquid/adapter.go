@@ -0,0 +1,1112 @@
+package hyperliquid
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"opensqt/exchange/clock"
+	"opensqt/exchange/errs"
+	"opensqt/exchange/orderbook"
+	"opensqt/exchange/venue"
+	"opensqt/logger"
+	"opensqt/utils"
+)
+
+// 订单、持仓与行情类型与其他交易所共用（定义在 venue 包中，避免与 exchange 包循环导入）
+type (
+	Side                = venue.Side
+	OrderType           = venue.OrderType
+	OrderStatus         = venue.OrderStatus
+	TimeInForce         = venue.TimeInForce
+	OrderRequest        = venue.OrderRequest
+	Order               = venue.Order
+	Position            = venue.Position
+	Account             = venue.Account
+	SymbolInfo          = venue.SymbolInfo
+	OrderUpdate         = venue.OrderUpdate
+	OrderUpdateCallback = venue.OrderUpdateCallback
+	Candle              = venue.Candle
+)
+
+const (
+	SideBuy  = venue.SideBuy
+	SideSell = venue.SideSell
+
+	OrderTypeLimit  = venue.OrderTypeLimit
+	OrderTypeMarket = venue.OrderTypeMarket
+
+	OrderStatusNew             = venue.OrderStatusNew
+	OrderStatusPartiallyFilled = venue.OrderStatusPartiallyFilled
+	OrderStatusFilled          = venue.OrderStatusFilled
+	OrderStatusCanceled        = venue.OrderStatusCanceled
+	OrderStatusRejected        = venue.OrderStatusRejected
+	OrderStatusExpired         = venue.OrderStatusExpired
+
+	TimeInForceGTC = venue.TimeInForceGTC
+)
+
+const (
+	perpMaxDecimals        = 6    // 永续合约价格最大小数位（实际为 6 - szDecimals）
+	perpSigFigs            = 5    // 价格最多 5 位有效数字（整数价格不受限制）
+	marketSlippage         = 0.05 // 市价单滑点（Hyperliquid 市价单以 IOC 限价单实现）
+	orderBatchSize         = 20   // 单个 action 最多携带的订单/撤单数量
+	hyperliquidQuote       = "USDC"
+	hyperliquidMinNotional = 10.0 // 订单最小名义价值（USDC）
+	errOrderNotExists      = "never placed, already canceled, or filled"
+)
+
+// HyperliquidAdapter Hyperliquid 永续合约适配器
+//
+// 认证方式：主账户地址（api_key）+ API/agent 钱包私钥（secret_key）
+// agent 钱包只能签名交易，查询账户、订单、持仓使用主账户地址
+type HyperliquidAdapter struct {
+	client         *Client
+	signer         *Signer
+	clock          *clock.Estimator // 服务器时间偏差（nonce 由它生成）
+	wsManager      *WebSocketManager
+	klineWSManager *KlineWebSocketManager
+	user           string // 主账户地址（查询与订单推送使用）
+	symbol         string // 标准交易对，如 ETHUSDT
+	coin           string // Hyperliquid 币种名，如 ETH
+
+	assetIndex       int     // 资产编号（meta.universe 中的下标）
+	szDecimals       int     // 数量小数位
+	maxLeverage      int     // 最大杠杆
+	priceDecimals    int     // 价格小数位（结合 5 位有效数字推导）
+	quantityDecimals int     // 数量小数位
+	lastMidPrice     float64 // 初始化时的中间价（用于推导价格精度）
+}
+
+// NewHyperliquidAdapter 创建 Hyperliquid 适配器
+// cfg 键 environment 选择 mainnet/testnet；可选键 base_url / ws_url 用于指向自定义网关，
+// base_url 指向测试网时使用测试网签名（phantom agent source = "b"）
+func NewHyperliquidAdapter(cfg map[string]string, symbol string) (*HyperliquidAdapter, error) {
+	user := strings.ToLower(strings.TrimSpace(cfg["api_key"]))
+	privateKey := cfg["secret_key"]
+	if privateKey == "" {
+		return nil, fmt.Errorf("hyperliquid API 配置不完整")
+	}
+
+	baseURL := HyperliquidMainnetURL
+	switch env := cfg["environment"]; env {
+	case "", "mainnet":
+	case "testnet":
+		baseURL = HyperliquidTestnetURL
+		logger.Info("ℹ️ [Hyperliquid] 使用测试网")
+	default:
+		return nil, fmt.Errorf("hyperliquid 不支持 environment=%s（可选 mainnet/testnet）", env)
+	}
+	if u := cfg["base_url"]; u != "" {
+		baseURL = strings.TrimRight(u, "/")
+	}
+	isMainnet := !strings.Contains(baseURL, "testnet")
+
+	signer, err := NewSigner(privateKey, isMainnet)
+	if err != nil {
+		return nil, fmt.Errorf("hyperliquid 私钥无效: %w", err)
+	}
+	if user == "" {
+		// 未配置主账户地址时，认为直接使用主钱包私钥签名
+		user = signer.GetAddress()
+	}
+
+	wsURL := HyperliquidWSMainnet
+	if !isMainnet {
+		wsURL = HyperliquidWSTestnet
+	}
+	if u := cfg["ws_url"]; u != "" {
+		wsURL = u
+	}
+
+	adapter := &HyperliquidAdapter{
+		client: NewClient(signer, baseURL),
+		signer: signer,
+		user:   user,
+		symbol: strings.ToUpper(symbol),
+		coin:   convertToCoin(symbol),
+	}
+	adapter.wsManager = NewWebSocketManager(wsURL, adapter)
+	adapter.klineWSManager = NewKlineWebSocketManager(wsURL)
+	adapter.startClock()
+
+	// 初始化获取资产信息（资产编号是下单必需参数）
+	ctxInit, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := adapter.fetchMeta(ctxInit); err != nil {
+		return nil, fmt.Errorf("获取 Hyperliquid 资产信息失败: %w", err)
+	}
+
+	if user != signer.GetAddress() {
+		logger.Info("ℹ️ [Hyperliquid] 使用 API 钱包 %s 代理账户 %s 交易", signer.GetAddress(), user)
+	}
+
+	return adapter, nil
+}
+
+// GetName 获取交易所名称
+func (h *HyperliquidAdapter) GetName() string {
+	return "Hyperliquid"
+}
+
+// startClock 创建对时器并注入签名器，立即对时一次后定期对时
+// nonce（毫秒时间戳）的有效窗口为前 2 天到后 1 天，对时失败时退回本地时间也不影响下单
+func (h *HyperliquidAdapter) startClock() {
+	h.clock = clock.NewEstimator("Hyperliquid", h.client.ServerTime)
+	h.signer.clock = h.clock
+	h.clock.Start(context.Background(), clock.DefaultSyncInterval)
+}
+
+// ClockSkew 本地时钟相对服务器的偏差（服务器时间 - 本地时间）
+func (h *HyperliquidAdapter) ClockSkew() time.Duration {
+	return h.clock.Offset()
+}
+
+// SyncServerTime 立即与服务器对时
+func (h *HyperliquidAdapter) SyncServerTime(ctx context.Context) error {
+	return h.clock.Sync(ctx)
+}
+
+// hyperliquidAsset 永续资产元数据（metaAndAssetCtxs 中的一项）
+type hyperliquidAsset struct {
+	Index       int
+	Name        string
+	SzDecimals  int
+	MaxLeverage int
+	MidPrice    float64
+}
+
+// queryAsset 查询单个资产的编号、精度与当前中间价
+func (h *HyperliquidAdapter) queryAsset(ctx context.Context, coin string) (*hyperliquidAsset, error) {
+	var metaAndCtxs []json.RawMessage
+	if err := h.client.Info(ctx, map[string]string{"type": "metaAndAssetCtxs"}, &metaAndCtxs); err != nil {
+		return nil, err
+	}
+	if len(metaAndCtxs) < 2 {
+		return nil, fmt.Errorf("资产信息格式错误")
+	}
+
+	var meta struct {
+		Universe []struct {
+			Name        string `json:"name"`
+			SzDecimals  int    `json:"szDecimals"`
+			MaxLeverage int    `json:"maxLeverage"`
+			IsDelisted  bool   `json:"isDelisted"`
+		} `json:"universe"`
+	}
+	var assetCtxs []struct {
+		MidPx  string `json:"midPx"`
+		MarkPx string `json:"markPx"`
+	}
+	if err := json.Unmarshal(metaAndCtxs[0], &meta); err != nil {
+		return nil, fmt.Errorf("解析资产信息失败: %w", err)
+	}
+	if err := json.Unmarshal(metaAndCtxs[1], &assetCtxs); err != nil {
+		return nil, fmt.Errorf("解析资产行情失败: %w", err)
+	}
+
+	for i, asset := range meta.Universe {
+		if asset.Name != coin {
+			continue
+		}
+		if asset.IsDelisted {
+			return nil, fmt.Errorf("%s 已下架", coin)
+		}
+
+		result := &hyperliquidAsset{
+			Index:       i,
+			Name:        asset.Name,
+			SzDecimals:  asset.SzDecimals,
+			MaxLeverage: asset.MaxLeverage,
+		}
+		if i < len(assetCtxs) {
+			price, _ := strconv.ParseFloat(assetCtxs[i].MidPx, 64)
+			if price == 0 {
+				price, _ = strconv.ParseFloat(assetCtxs[i].MarkPx, 64)
+			}
+			result.MidPrice = price
+		}
+		return result, nil
+	}
+
+	return nil, fmt.Errorf("未找到资产 %s", coin)
+}
+
+// fetchMeta 获取资产编号与精度
+func (h *HyperliquidAdapter) fetchMeta(ctx context.Context) error {
+	asset, err := h.queryAsset(ctx, h.coin)
+	if err != nil {
+		return err
+	}
+
+	h.assetIndex = asset.Index
+	h.szDecimals = asset.SzDecimals
+	h.quantityDecimals = asset.SzDecimals
+	h.maxLeverage = asset.MaxLeverage
+	h.lastMidPrice = asset.MidPrice
+	h.priceDecimals = priceDecimalsFor(h.lastMidPrice, h.szDecimals)
+
+	logger.Info("ℹ️ [Hyperliquid 资产信息] %s, 资产编号:%d, 数量精度:%d, 价格精度:%d, 最大杠杆:%dx",
+		h.coin, h.assetIndex, h.quantityDecimals, h.priceDecimals, h.maxLeverage)
+	return nil
+}
+
+// GetSymbolInfo 获取合约交易规则
+// Hyperliquid 价格按 5 位有效数字取整，TickSize 由当前中间价推算，价格跨数量级后会变化
+func (h *HyperliquidAdapter) GetSymbolInfo(ctx context.Context, symbol string) (*SymbolInfo, error) {
+	coin := convertToCoin(symbol)
+	asset, err := h.queryAsset(ctx, coin)
+	if err != nil {
+		return nil, err
+	}
+
+	priceDecimals := priceDecimalsFor(asset.MidPrice, asset.SzDecimals)
+	step := math.Pow10(-asset.SzDecimals)
+	return &SymbolInfo{
+		Symbol:             h.symbolOf(coin),
+		BaseAsset:          coin,
+		QuoteAsset:         hyperliquidQuote,
+		TickSize:           math.Pow10(-priceDecimals),
+		StepSize:           step,
+		MinQty:             step,
+		MinNotional:        hyperliquidMinNotional,
+		ContractMultiplier: 1,
+		MaxLeverage:        asset.MaxLeverage,
+		PriceDecimals:      priceDecimals,
+		QuantityDecimals:   asset.SzDecimals,
+	}, nil
+}
+
+// PlaceOrder 下单
+func (h *HyperliquidAdapter) PlaceOrder(ctx context.Context, req *OrderRequest) (*Order, error) {
+	wire, err := h.buildOrderWire(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses, err := h.client.Exchange(ctx, buildOrderAction([]msgMap{wire}))
+	if err != nil {
+		return nil, classifyError(err)
+	}
+	if len(statuses) == 0 {
+		return nil, fmt.Errorf("下单响应为空")
+	}
+
+	order, err := h.statusToOrder(req, statuses[0])
+	if err != nil {
+		return nil, classifyError(err)
+	}
+	return order, nil
+}
+
+// buildOrderAction 构造下单 action（字段顺序与官方 SDK 一致，影响签名）
+func buildOrderAction(orders []msgMap) msgMap {
+	return msgMap{
+		{"type", "order"},
+		{"orders", orders},
+		{"grouping", "na"},
+	}
+}
+
+// buildOrderWire 构造单个订单参数
+// 字段: a=资产编号, b=是否买入, p=价格, s=数量, r=只减仓, t=订单类型, c=cloid（可选）
+func (h *HyperliquidAdapter) buildOrderWire(ctx context.Context, req *OrderRequest) (msgMap, error) {
+	size := roundDown(req.Quantity, h.szDecimals)
+	if size <= 0 {
+		return nil, fmt.Errorf("下单数量 %.8f 小于最小精度 %d 位小数", req.Quantity, h.szDecimals)
+	}
+
+	tif := "Gtc"
+	price := req.Price
+	if req.Type == OrderTypeMarket {
+		// Hyperliquid 没有真正的市价单，使用带滑点的 IOC 限价单
+		tif = "Ioc"
+		mid, err := h.GetLatestPrice(ctx, h.symbol)
+		if err != nil {
+			return nil, fmt.Errorf("获取市价失败: %w", err)
+		}
+		if req.Side == SideBuy {
+			price = mid * (1 + marketSlippage)
+		} else {
+			price = mid * (1 - marketSlippage)
+		}
+	} else if req.PostOnly {
+		tif = "Alo" // Add Liquidity Only - 只做 Maker
+	}
+
+	wire := msgMap{
+		{"a", h.assetIndex},
+		{"b", req.Side == SideBuy},
+		{"p", floatToWire(roundPrice(price, h.szDecimals))},
+		{"s", floatToWire(size)},
+		{"r", req.ReduceOnly},
+		{"t", msgMap{{"limit", msgMap{{"tif", tif}}}}},
+	}
+	if req.ClientOrderID != "" {
+		wire = append(wire, msgField{"c", utils.AddBrokerPrefix("hyperliquid", req.ClientOrderID)})
+	}
+	return wire, nil
+}
+
+// statusToOrder 解析单笔下单结果
+// 可能的结果: {"resting":{"oid":1}}、{"filled":{"totalSz":"0.1","avgPx":"3000","oid":1}}、{"error":"..."}
+func (h *HyperliquidAdapter) statusToOrder(req *OrderRequest, raw json.RawMessage) (*Order, error) {
+	var status struct {
+		Resting *struct {
+			Oid   int64  `json:"oid"`
+			Cloid string `json:"cloid"`
+		} `json:"resting"`
+		Filled *struct {
+			TotalSz string `json:"totalSz"`
+			AvgPx   string `json:"avgPx"`
+			Oid     int64  `json:"oid"`
+			Cloid   string `json:"cloid"`
+		} `json:"filled"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(raw, &status); err != nil {
+		return nil, fmt.Errorf("解析下单响应失败: %s", string(raw))
+	}
+
+	order := &Order{
+		ClientOrderID: req.ClientOrderID,
+		Symbol:        req.Symbol,
+		Side:          req.Side,
+		Type:          req.Type,
+		Price:         req.Price,
+		Quantity:      req.Quantity,
+		Status:        OrderStatusNew,
+		CreatedAt:     time.Now(),
+	}
+
+	switch {
+	case status.Error != "":
+		return nil, &APIError{Msg: status.Error}
+	case status.Resting != nil:
+		order.OrderID = status.Resting.Oid
+	case status.Filled != nil:
+		order.OrderID = status.Filled.Oid
+		order.ExecutedQty, _ = strconv.ParseFloat(status.Filled.TotalSz, 64)
+		order.AvgPrice, _ = strconv.ParseFloat(status.Filled.AvgPx, 64)
+		order.Status = OrderStatusFilled
+	default:
+		return nil, fmt.Errorf("未知的下单响应: %s", string(raw))
+	}
+
+	if order.OrderID == 0 {
+		return nil, fmt.Errorf("下单响应中oid为空: %s", string(raw))
+	}
+	return order, nil
+}
+
+// BatchPlaceOrders 批量下单（单个 action 携带多个订单，每次最多20个）
+func (h *HyperliquidAdapter) BatchPlaceOrders(ctx context.Context, orders []*OrderRequest) ([]*Order, bool) {
+	placedOrders := make([]*Order, 0, len(orders))
+	hasMarginError := false
+
+	for i := 0; i < len(orders); i += orderBatchSize {
+		end := i + orderBatchSize
+		if end > len(orders) {
+			end = len(orders)
+		}
+
+		// 构造批量请求，跳过本地校验失败的订单
+		var batchReqs []*OrderRequest
+		var wires []msgMap
+		for _, req := range orders[i:end] {
+			wire, err := h.buildOrderWire(ctx, req)
+			if err != nil {
+				logger.Warn("⚠️ [Hyperliquid] 下单失败 %.*f %s: %v", h.priceDecimals, req.Price, req.Side, err)
+				continue
+			}
+			batchReqs = append(batchReqs, req)
+			wires = append(wires, wire)
+		}
+		if len(wires) == 0 {
+			continue
+		}
+
+		statuses, err := h.client.Exchange(ctx, buildOrderAction(wires))
+		if err != nil {
+			logger.Warn("⚠️ [Hyperliquid] 批量下单请求失败 (共%d个): %v", len(wires), err)
+			errs.Report(ctx, err)
+			if isInsufficientMarginError(err) {
+				hasMarginError = true
+			}
+			continue
+		}
+
+		// 按请求顺序返回结果
+		for j, raw := range statuses {
+			if j >= len(batchReqs) {
+				break
+			}
+			req := batchReqs[j]
+			order, err := h.statusToOrder(req, raw)
+			if err != nil {
+				logger.Warn("⚠️ [Hyperliquid] 下单失败 %.*f %s: %v", h.priceDecimals, req.Price, req.Side, err)
+				if isInsufficientMarginError(err) {
+					hasMarginError = true
+				}
+				continue
+			}
+			placedOrders = append(placedOrders, order)
+		}
+	}
+
+	return placedOrders, hasMarginError
+}
+
+// buildCancelAction 构造撤单 action
+func (h *HyperliquidAdapter) buildCancelAction(orderIDs []int64) msgMap {
+	cancels := make([]msgMap, len(orderIDs))
+	for i, id := range orderIDs {
+		cancels[i] = msgMap{
+			{"a", h.assetIndex},
+			{"o", id},
+		}
+	}
+	return msgMap{
+		{"type", "cancel"},
+		{"cancels", cancels},
+	}
+}
+
+// CancelOrder 取消订单
+func (h *HyperliquidAdapter) CancelOrder(ctx context.Context, symbol string, orderID int64) error {
+	statuses, err := h.client.Exchange(ctx, h.buildCancelAction([]int64{orderID}))
+	if err != nil {
+		return fmt.Errorf("取消订单失败: %w", err)
+	}
+
+	if len(statuses) > 0 {
+		if msg := cancelStatusError(statuses[0]); msg != "" {
+			// 订单不存在不算错误
+			if strings.Contains(msg, errOrderNotExists) {
+				logger.Info("ℹ️ [Hyperliquid] 订单 %d 已不存在，跳过取消", orderID)
+				return nil
+			}
+			return fmt.Errorf("取消订单失败: %w", &APIError{Msg: msg})
+		}
+	}
+
+	logger.Info("✅ [Hyperliquid] 取消订单成功: %d", orderID)
+	return nil
+}
+
+// BatchCancelOrders 批量取消订单（单个 action 携带多个撤单，每次最多20个）
+func (h *HyperliquidAdapter) BatchCancelOrders(ctx context.Context, symbol string, orderIDs []int64) error {
+	if len(orderIDs) == 0 {
+		return nil
+	}
+
+	for i := 0; i < len(orderIDs); i += orderBatchSize {
+		end := i + orderBatchSize
+		if end > len(orderIDs) {
+			end = len(orderIDs)
+		}
+		batch := orderIDs[i:end]
+
+		statuses, err := h.client.Exchange(ctx, h.buildCancelAction(batch))
+		if err != nil {
+			logger.Warn("⚠️ [Hyperliquid] 批量撤单失败 (共%d个): %v", len(batch), err)
+			continue
+		}
+
+		failed := 0
+		for j, raw := range statuses {
+			if msg := cancelStatusError(raw); msg != "" && !strings.Contains(msg, errOrderNotExists) {
+				failed++
+				if j < len(batch) {
+					logger.Warn("⚠️ [Hyperliquid] 撤单失败 %d: %s", batch[j], msg)
+				}
+			}
+		}
+		logger.Info("✅ [Hyperliquid] 批量撤单完成: %d 个订单, 失败 %d 个", len(batch), failed)
+	}
+
+	return nil
+}
+
+// cancelStatusError 解析撤单结果，成功为 "success"，失败为 {"error":"..."}
+func cancelStatusError(raw json.RawMessage) string {
+	var status struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(raw, &status) == nil {
+		return status.Error
+	}
+	return ""
+}
+
+// CancelAllOrders 撤销交易对的所有订单
+// Hyperliquid 没有一键全撤接口，使用"查询挂单 + 批量撤单"实现
+func (h *HyperliquidAdapter) CancelAllOrders(ctx context.Context, symbol string) error {
+	orders, err := h.GetOpenOrders(ctx, symbol)
+	if err != nil {
+		return fmt.Errorf("查询未完成订单失败: %w", err)
+	}
+	if len(orders) == 0 {
+		logger.Info("✅ [Hyperliquid] 没有需要撤销的订单")
+		return nil
+	}
+
+	orderIDs := make([]int64, len(orders))
+	for i, ord := range orders {
+		orderIDs[i] = ord.OrderID
+	}
+
+	logger.Info("🔄 [Hyperliquid] 撤销 %d 个未完成订单", len(orderIDs))
+	return h.BatchCancelOrders(ctx, symbol, orderIDs)
+}
+
+// hlOrder Hyperliquid 订单结构（REST 与 WebSocket 共用）
+type hlOrder struct {
+	Coin      string `json:"coin"`
+	Side      string `json:"side"` // B=买 A=卖
+	LimitPx   string `json:"limitPx"`
+	Sz        string `json:"sz"`     // 剩余数量
+	OrigSz    string `json:"origSz"` // 原始数量
+	Oid       int64  `json:"oid"`
+	Timestamp int64  `json:"timestamp"`
+	Cloid     string `json:"cloid"`
+	OrderType string `json:"orderType"`
+}
+
+// toOrder 转换为通用订单结构
+func (h *HyperliquidAdapter) toOrder(item *hlOrder, status string, updateTime int64) *Order {
+	price, _ := strconv.ParseFloat(item.LimitPx, 64)
+	remaining, _ := strconv.ParseFloat(item.Sz, 64)
+	quantity, _ := strconv.ParseFloat(item.OrigSz, 64)
+	if quantity == 0 {
+		quantity = remaining
+	}
+	executed := roundDown(quantity-remaining, h.szDecimals)
+
+	orderType := OrderTypeLimit
+	if item.OrderType == "Market" {
+		orderType = OrderTypeMarket
+	}
+	if updateTime == 0 {
+		updateTime = item.Timestamp
+	}
+
+	return &Order{
+		OrderID:       item.Oid,
+		ClientOrderID: utils.RemoveBrokerPrefix("hyperliquid", item.Cloid),
+		Symbol:        h.symbolOf(item.Coin),
+		Side:          convertSide(item.Side),
+		Type:          orderType,
+		Price:         price,
+		Quantity:      quantity,
+		ExecutedQty:   executed,
+		AvgPrice:      price, // 订单接口不返回成交均价，限价单以挂单价近似
+		Status:        convertStatus(status, executed),
+		CreatedAt:     time.UnixMilli(item.Timestamp),
+		UpdateTime:    updateTime,
+	}
+}
+
+// GetOrder 查询订单
+func (h *HyperliquidAdapter) GetOrder(ctx context.Context, symbol string, orderID int64) (*Order, error) {
+	var resp struct {
+		Status string `json:"status"` // order / unknownOid
+		Order  struct {
+			Order           hlOrder `json:"order"`
+			Status          string  `json:"status"`
+			StatusTimestamp int64   `json:"statusTimestamp"`
+		} `json:"order"`
+	}
+	req := map[string]interface{}{"type": "orderStatus", "user": h.user, "oid": orderID}
+	if err := h.client.Info(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Status != "order" {
+		return nil, fmt.Errorf("订单不存在: %d", orderID)
+	}
+
+	return h.toOrder(&resp.Order.Order, resp.Order.Status, resp.Order.StatusTimestamp), nil
+}
+
+// GetOpenOrders 查询未完成订单
+func (h *HyperliquidAdapter) GetOpenOrders(ctx context.Context, symbol string) ([]*Order, error) {
+	var dataList []hlOrder
+	req := map[string]string{"type": "frontendOpenOrders", "user": h.user}
+	if err := h.client.Info(ctx, req, &dataList); err != nil {
+		return nil, err
+	}
+
+	coin := convertToCoin(symbol)
+	orders := make([]*Order, 0, len(dataList))
+	for i := range dataList {
+		if dataList[i].Coin != coin {
+			continue
+		}
+		orders = append(orders, h.toOrder(&dataList[i], "open", 0))
+	}
+	return orders, nil
+}
+
+// clearinghouseState 账户状态
+type clearinghouseState struct {
+	MarginSummary struct {
+		AccountValue    string `json:"accountValue"`
+		TotalNtlPos     string `json:"totalNtlPos"`
+		TotalRawUsd     string `json:"totalRawUsd"`
+		TotalMarginUsed string `json:"totalMarginUsed"`
+	} `json:"marginSummary"`
+	Withdrawable   string `json:"withdrawable"`
+	AssetPositions []struct {
+		Position struct {
+			Coin          string `json:"coin"`
+			Szi           string `json:"szi"` // 带符号持仓数量，负数为空仓
+			EntryPx       string `json:"entryPx"`
+			PositionValue string `json:"positionValue"`
+			UnrealizedPnl string `json:"unrealizedPnl"`
+			MarginUsed    string `json:"marginUsed"`
+			Leverage      struct {
+				Type  string `json:"type"` // cross / isolated
+				Value int    `json:"value"`
+			} `json:"leverage"`
+		} `json:"position"`
+	} `json:"assetPositions"`
+}
+
+func (h *HyperliquidAdapter) fetchClearinghouseState(ctx context.Context) (*clearinghouseState, error) {
+	var state clearinghouseState
+	req := map[string]string{"type": "clearinghouseState", "user": h.user}
+	if err := h.client.Info(ctx, req, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// GetAccount 获取账户信息
+func (h *HyperliquidAdapter) GetAccount(ctx context.Context) (*Account, error) {
+	state, err := h.fetchClearinghouseState(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	account := &Account{}
+	account.TotalMarginBalance, _ = strconv.ParseFloat(state.MarginSummary.AccountValue, 64)
+	account.AvailableBalance, _ = strconv.ParseFloat(state.Withdrawable, 64)
+
+	positions := h.convertPositions(state, "")
+	unrealized := 0.0
+	for _, pos := range positions {
+		unrealized += pos.UnrealizedPNL
+		if pos.Symbol == h.symbol {
+			account.AccountLeverage = pos.Leverage
+		}
+	}
+	// 钱包余额 = 账户权益 - 未实现盈亏
+	account.TotalWalletBalance = account.TotalMarginBalance - unrealized
+	account.Positions = positions
+
+	return account, nil
+}
+
+// GetPositions 获取持仓信息（空仓为负数）
+func (h *HyperliquidAdapter) GetPositions(ctx context.Context, symbol string) ([]*Position, error) {
+	state, err := h.fetchClearinghouseState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return h.convertPositions(state, symbol), nil
+}
+
+// convertPositions 转换持仓，symbol 为空时返回全部
+func (h *HyperliquidAdapter) convertPositions(state *clearinghouseState, symbol string) []*Position {
+	coin := ""
+	if symbol != "" {
+		coin = convertToCoin(symbol)
+	}
+
+	positions := make([]*Position, 0, len(state.AssetPositions))
+	for _, item := range state.AssetPositions {
+		p := item.Position
+		if coin != "" && p.Coin != coin {
+			continue
+		}
+		size, _ := strconv.ParseFloat(p.Szi, 64)
+		if size == 0 {
+			continue // 跳过空持仓
+		}
+
+		entryPrice, _ := strconv.ParseFloat(p.EntryPx, 64)
+		positionValue, _ := strconv.ParseFloat(p.PositionValue, 64)
+		upl, _ := strconv.ParseFloat(p.UnrealizedPnl, 64)
+		marginUsed, _ := strconv.ParseFloat(p.MarginUsed, 64)
+
+		position := &Position{
+			Symbol:        h.symbolOf(p.Coin),
+			Size:          size,
+			EntryPrice:    entryPrice,
+			MarkPrice:     math.Abs(positionValue / size), // 持仓价值按标记价格计算
+			UnrealizedPNL: upl,
+			Leverage:      p.Leverage.Value,
+			MarginType:    p.Leverage.Type,
+		}
+		if p.Leverage.Type == "isolated" {
+			position.IsolatedMargin = marginUsed
+		}
+		positions = append(positions, position)
+	}
+	return positions
+}
+
+// GetBalance 获取余额
+func (h *HyperliquidAdapter) GetBalance(ctx context.Context, asset string) (float64, error) {
+	account, err := h.GetAccount(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return account.AvailableBalance, nil
+}
+
+// StartOrderStream 启动订单流（WebSocket orderUpdates + userFills）
+func (h *HyperliquidAdapter) StartOrderStream(ctx context.Context, callback func(interface{})) error {
+	logger.Debug("🔗 [Hyperliquid] 启动订单流 WebSocket（orderUpdates/userFills）")
+
+	wrappedCallback := func(update OrderUpdate) {
+		genericUpdate := struct {
+			OrderID       int64
+			ClientOrderID string
+			Symbol        string
+			Side          string
+			Type          string
+			Status        string
+			Price         float64
+			Quantity      float64
+			ExecutedQty   float64
+			AvgPrice      float64
+			UpdateTime    int64
+		}{
+			OrderID:       update.OrderID,
+			ClientOrderID: update.ClientOrderID,
+			Symbol:        update.Symbol,
+			Side:          string(update.Side),
+			Type:          string(update.Type),
+			Status:        string(update.Status),
+			Price:         update.Price,
+			Quantity:      update.Quantity,
+			ExecutedQty:   update.ExecutedQty,
+			AvgPrice:      update.AvgPrice,
+			UpdateTime:    update.UpdateTime,
+		}
+		callback(genericUpdate)
+	}
+
+	return h.wsManager.StartPrivate(ctx, h.user, wrappedCallback)
+}
+
+// StopOrderStream 停止订单流
+func (h *HyperliquidAdapter) StopOrderStream() error {
+	h.wsManager.Stop()
+	return nil
+}
+
+// GetLatestPrice 获取最新价格（优先 WebSocket 缓存，未就绪时使用 REST 中间价）
+func (h *HyperliquidAdapter) GetLatestPrice(ctx context.Context, symbol string) (float64, error) {
+	coin := convertToCoin(symbol)
+	if coin == h.coin {
+		if price := h.wsManager.GetLatestPrice(); price > 0 {
+			return price, nil
+		}
+	}
+
+	var mids map[string]string
+	if err := h.client.Info(ctx, map[string]string{"type": "allMids"}, &mids); err != nil {
+		return 0, err
+	}
+	priceStr, ok := mids[coin]
+	if !ok {
+		return 0, fmt.Errorf("未获取到 %s 的行情", coin)
+	}
+	return strconv.ParseFloat(priceStr, 64)
+}
+
+// StartPriceStream 启动价格流（WebSocket allMids 频道）
+func (h *HyperliquidAdapter) StartPriceStream(ctx context.Context, symbol string, callback func(price float64)) error {
+	return h.wsManager.StartPublic(ctx, convertToCoin(symbol), callback)
+}
+
+// StartBookTickerStream 启动盘口最优价推送（WebSocket bbo 频道）
+func (h *HyperliquidAdapter) StartBookTickerStream(ctx context.Context, symbol string, callback func(ticker *orderbook.Ticker)) error {
+	return h.wsManager.StartBookTicker(ctx, convertToCoin(symbol), callback)
+}
+
+// StartKlineStream 启动K线流
+func (h *HyperliquidAdapter) StartKlineStream(ctx context.Context, symbols []string, interval string, callback func(candle interface{})) error {
+	return h.klineWSManager.Start(ctx, symbols, interval, callback)
+}
+
+// RegisterKlineCallback 注册K线回调函数（支持多个组件共享K线流）
+func (h *HyperliquidAdapter) RegisterKlineCallback(componentName string, callback func(candle interface{})) error {
+	return h.klineWSManager.RegisterCallback(componentName, callback)
+}
+
+// StopKlineStream 停止K线流
+func (h *HyperliquidAdapter) StopKlineStream() error {
+	h.klineWSManager.Stop()
+	return nil
+}
+
+// ForceReconnectKlineStream 强制重新连接K线流
+func (h *HyperliquidAdapter) ForceReconnectKlineStream() error {
+	return h.klineWSManager.ForceReconnect()
+}
+
+// GetHistoricalKlines 获取历史K线数据
+// candleSnapshot 按时间范围查询（单次最多 5000 根），按 limit 倒推起始时间
+func (h *HyperliquidAdapter) GetHistoricalKlines(ctx context.Context, symbol string, interval string, limit int) ([]*Candle, error) {
+	intervalMs := intervalToMillis(interval)
+	if intervalMs == 0 {
+		return nil, fmt.Errorf("不支持的K线周期: %s", interval)
+	}
+
+	now := time.Now().UnixMilli()
+	req := map[string]interface{}{
+		"type": "candleSnapshot",
+		"req": map[string]interface{}{
+			"coin":      convertToCoin(symbol),
+			"interval":  interval,
+			"startTime": now - int64(limit)*intervalMs,
+			"endTime":   now,
+		},
+	}
+
+	var dataList []hlCandle
+	if err := h.client.Info(ctx, req, &dataList); err != nil {
+		return nil, fmt.Errorf("获取历史K线失败: %w", err)
+	}
+
+	// 返回结果按时间升序，只保留最近 limit 根
+	if len(dataList) > limit {
+		dataList = dataList[len(dataList)-limit:]
+	}
+
+	candles := make([]*Candle, 0, len(dataList))
+	for i := range dataList {
+		candle := dataList[i].toCandle(symbol)
+		candle.IsClosed = dataList[i].CloseTime < now
+		candles = append(candles, candle)
+	}
+	return candles, nil
+}
+
+// GetKlinesBetween 获取开盘时间在 [start, end]（毫秒）内的K线，按时间升序
+// candleSnapshot 单次最多返回 5000 根，且只保留最近 5000 根，更早的区间返回空；
+// 接口没有数量参数，超过 limit 时只保留最早的 limit 根（limit <= 0 不截断）
+func (h *HyperliquidAdapter) GetKlinesBetween(ctx context.Context, symbol string, interval string, start, end int64, limit int) ([]*Candle, error) {
+	req := map[string]interface{}{
+		"type": "candleSnapshot",
+		"req": map[string]interface{}{
+			"coin":      convertToCoin(symbol),
+			"interval":  interval,
+			"startTime": start,
+			"endTime":   end,
+		},
+	}
+
+	var dataList []hlCandle
+	if err := h.client.Info(ctx, req, &dataList); err != nil {
+		return nil, fmt.Errorf("获取历史K线失败: %w", err)
+	}
+
+	now := time.Now().UnixMilli()
+	candles := make([]*Candle, 0, len(dataList))
+	for i := range dataList {
+		candle := dataList[i].toCandle(symbol)
+		candle.IsClosed = dataList[i].CloseTime < now
+		candles = append(candles, candle)
+	}
+	if limit > 0 && len(candles) > limit {
+		candles = candles[:limit]
+	}
+	return candles, nil
+}
+
+// GetPriceDecimals 获取价格精度（小数位数）
+func (h *HyperliquidAdapter) GetPriceDecimals() int {
+	return h.priceDecimals
+}
+
+// GetQuantityDecimals 获取数量精度（小数位数）
+func (h *HyperliquidAdapter) GetQuantityDecimals() int {
+	return h.quantityDecimals
+}
+
+// GetBaseAsset 获取基础资产（交易币种）
+func (h *HyperliquidAdapter) GetBaseAsset() string {
+	return h.coin
+}
+
+// GetQuoteAsset 获取计价资产（结算币种）
+// Hyperliquid 永续合约统一以 USDC 结算
+func (h *HyperliquidAdapter) GetQuoteAsset() string {
+	return hyperliquidQuote
+}
+
+// symbolOf 将币种名转换为标准交易对（当前交易对保持配置中的写法）
+func (h *HyperliquidAdapter) symbolOf(coin string) string {
+	if coin == h.coin {
+		return h.symbol
+	}
+	return coin + hyperliquidQuote
+}
+
+// isInsufficientMarginError 判断是否为保证金不足错误
+func isInsufficientMarginError(err error) bool {
+	return strings.Contains(strings.ToLower(err.Error()), "insufficient margin")
+}
+
+// classifyError 将 Hyperliquid 错误信息映射为统一错误分类（Hyperliquid 无错误码，只能按信息匹配）
+func classifyError(err error) error {
+	msg := strings.ToLower(err.Error())
+	switch {
+	case isInsufficientMarginError(err):
+		return errs.Wrap(errs.ErrInsufficientMargin, "hyperliquid", "", err)
+	case strings.Contains(msg, "post only order would have immediately matched"):
+		return errs.Wrap(errs.ErrPostOnlyWouldCross, "hyperliquid", "", err)
+	case strings.Contains(msg, "minimum value of $10"):
+		return errs.Wrap(errs.ErrMinNotional, "hyperliquid", "", err)
+	case strings.Contains(msg, "http 429"):
+		return errs.Wrap(errs.ErrRateLimited, "hyperliquid", "429", err)
+	}
+	return err
+}
+
+// convertSide 转换订单方向（B=买 A=卖）
+func convertSide(side string) Side {
+	if side == "A" {
+		return SideSell
+	}
+	return SideBuy
+}
+
+// convertStatus 转换订单状态
+func convertStatus(status string, executedQty float64) OrderStatus {
+	switch status {
+	case "open", "triggered":
+		if executedQty > 0 {
+			return OrderStatusPartiallyFilled
+		}
+		return OrderStatusNew
+	case "filled":
+		return OrderStatusFilled
+	case "rejected":
+		return OrderStatusRejected
+	default:
+		// canceled / marginCanceled / reduceOnlyCanceled / selfTradeCanceled 等各类撤单原因
+		if strings.HasSuffix(strings.ToLower(status), "canceled") {
+			return OrderStatusCanceled
+		}
+		return OrderStatus(strings.ToUpper(status))
+	}
+}
+
+// convertToCoin 将标准交易对转换为 Hyperliquid 币种名（ETHUSDT / ETHUSDC / ETH-USD -> ETH）
+func convertToCoin(symbol string) string {
+	symbol = strings.ToUpper(symbol)
+	symbol = strings.TrimSuffix(symbol, "-PERP")
+	symbol = strings.ReplaceAll(symbol, "-", "")
+	for _, quote := range []string{"USDT", "USDC", "USD"} {
+		if strings.HasSuffix(symbol, quote) && len(symbol) > len(quote) {
+			return strings.TrimSuffix(symbol, quote)
+		}
+	}
+	return symbol
+}
+
+// priceDecimalsFor 按 Hyperliquid 价格规则推导小数位
+// 规则: 小数位不超过 6 - szDecimals，且有效数字不超过 5 位
+func priceDecimalsFor(price float64, szDecimals int) int {
+	maxDecimals := perpMaxDecimals - szDecimals
+	if maxDecimals < 0 {
+		maxDecimals = 0
+	}
+	if price <= 0 {
+		return maxDecimals
+	}
+	intDigits := int(math.Floor(math.Log10(price))) + 1
+	decimals := perpSigFigs - intDigits
+	if decimals < 0 {
+		decimals = 0
+	}
+	if decimals > maxDecimals {
+		decimals = maxDecimals
+	}
+	return decimals
+}
+
+// roundPrice 按 Hyperliquid 价格规则取整（5 位有效数字，整数价格不受限制）
+func roundPrice(price float64, szDecimals int) float64 {
+	if price >= math.Pow(10, perpSigFigs) {
+		return math.Round(price)
+	}
+	sig, _ := strconv.ParseFloat(strconv.FormatFloat(price, 'g', perpSigFigs, 64), 64)
+	maxDecimals := perpMaxDecimals - szDecimals
+	multiplier := math.Pow(10, float64(maxDecimals))
+	return math.Round(sig*multiplier) / multiplier
+}
+
+// roundDown 向下截断到指定小数位
+func roundDown(value float64, decimals int) float64 {
+	multiplier := math.Pow(10, float64(decimals))
+	// 加上微小偏移，避免浮点误差导致少一个步长
+	return math.Floor(value*multiplier+1e-9) / multiplier
+}
+
+// floatToWire 数值转为签名使用的字符串（与官方 SDK float_to_wire 一致：最多 8 位小数，去掉末尾 0）
+func floatToWire(x float64) string {
+	s := strconv.FormatFloat(x, 'f', 8, 64)
+	s = strings.TrimRight(s, "0")
+	s = strings.TrimSuffix(s, ".")
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+// intervalToMillis K线周期转换为毫秒
+func intervalToMillis(interval string) int64 {
+	switch interval {
+	case "1m":
+		return 60 * 1000
+	case "3m":
+		return 3 * 60 * 1000
+	case "5m":
+		return 5 * 60 * 1000
+	case "15m":
+		return 15 * 60 * 1000
+	case "30m":
+		return 30 * 60 * 1000
+	case "1h":
+		return 60 * 60 * 1000
+	case "2h":
+		return 2 * 60 * 60 * 1000
+	case "4h":
+		return 4 * 60 * 60 * 1000
+	case "8h":
+		return 8 * 60 * 60 * 1000
+	case "12h":
+		return 12 * 60 * 60 * 1000
+	case "1d":
+		return 24 * 60 * 60 * 1000
+	case "3d":
+		return 3 * 24 * 60 * 60 * 1000
+	case "1w":
+		return 7 * 24 * 60 * 60 * 1000
+	case "1M":
+		return 30 * 24 * 60 * 60 * 1000
+	default:
+		return 0
+	}
+}
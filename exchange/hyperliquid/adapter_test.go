@@ -0,0 +1,589 @@
+package hyperliquid
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"opensqt/exchange/errs"
+	"opensqt/utils"
+
+	"github.com/gorilla/websocket"
+)
+
+// ===== 本地模拟 Hyperliquid 服务 =====
+
+const (
+	testAgentKey = "0x0123456789012345678901234567890123456789012345678901234567890123"
+	testUser     = "0x1111111111111111111111111111111111111111"
+)
+
+// decodeOrdered 按原始字段顺序解码 JSON（用于在模拟服务端重算 action 哈希）
+func decodeOrdered(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch v := tok.(type) {
+	case json.Delim:
+		if v == '{' {
+			m := msgMap{}
+			for dec.More() {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				value, err := decodeOrdered(dec)
+				if err != nil {
+					return nil, err
+				}
+				m = append(m, msgField{keyTok.(string), value})
+			}
+			dec.Token() // }
+			return m, nil
+		}
+		list := []interface{}{}
+		for dec.More() {
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		dec.Token() // ]
+		return list, nil
+	case json.Number:
+		return v.Int64()
+	default:
+		return v, nil
+	}
+}
+
+// mockHyperliquidServer 模拟 /info 与 /exchange，校验 /exchange 的签名
+type mockHyperliquidServer struct {
+	t        *testing.T
+	mu       sync.Mutex
+	info     map[string]func(req map[string]interface{}) string
+	exchange func(action msgMap) string
+	actions  []msgMap
+}
+
+func newMockServer(t *testing.T) (*mockHyperliquidServer, *httptest.Server) {
+	m := &mockHyperliquidServer{
+		t:    t,
+		info: make(map[string]func(req map[string]interface{}) string),
+	}
+	m.info["metaAndAssetCtxs"] = func(map[string]interface{}) string {
+		return `[{"universe":[{"name":"BTC","szDecimals":5,"maxLeverage":40},{"name":"ETH","szDecimals":4,"maxLeverage":25}]},
+			[{"midPx":"65000.0","markPx":"65001.0"},{"midPx":"3000.5","markPx":"3000.4"}]]`
+	}
+	// 对时请求（读取响应头 Date）
+	m.info["allMids"] = func(map[string]interface{}) string {
+		return `{"BTC":"65000.0","ETH":"3000.5"}`
+	}
+	server := httptest.NewServer(http.HandlerFunc(m.serve))
+	t.Cleanup(server.Close)
+	return m, server
+}
+
+func (m *mockHyperliquidServer) serve(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	switch r.URL.Path {
+	case "/info":
+		var req map[string]interface{}
+		json.Unmarshal(body, &req)
+		m.mu.Lock()
+		handler, ok := m.info[req["type"].(string)]
+		m.mu.Unlock()
+		if !ok {
+			m.t.Errorf("未预期的 info 请求: %s", string(body))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(handler(req)))
+
+	case "/exchange":
+		dec := json.NewDecoder(strings.NewReader(string(body)))
+		dec.UseNumber()
+		decoded, err := decodeOrdered(dec)
+		if err != nil {
+			m.t.Fatalf("解析请求失败: %v", err)
+		}
+		payload := decoded.(msgMap)
+		var action msgMap
+		var nonce int64
+		var sig msgMap
+		for _, f := range payload {
+			switch f.Key {
+			case "action":
+				action = f.Value.(msgMap)
+			case "nonce":
+				nonce = f.Value.(int64)
+			case "signature":
+				sig = f.Value.(msgMap)
+			}
+		}
+
+		// 用 action 重算摘要，恢复签名者地址
+		hash, _ := actionHash(action, "", nonce)
+		digest := agentDigest("a", hash)
+		v := int(sig[2].Value.(int64))
+		addr, err := RecoverAddress(digest, hexToBig(sig[0].Value.(string)), hexToBig(sig[1].Value.(string)), v)
+		agentKey, _ := ParsePrivateKey(testAgentKey)
+		expected := PrivateKeyToAddress(agentKey)
+		if err != nil || addr != expected {
+			m.t.Errorf("签名校验失败: addr=%s, want=%s, err=%v", addr, expected, err)
+			w.Write([]byte(`{"status":"err","response":"invalid signature"}`))
+			return
+		}
+
+		m.mu.Lock()
+		m.actions = append(m.actions, action)
+		handler := m.exchange
+		m.mu.Unlock()
+		w.Write([]byte(handler(action)))
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (m *mockHyperliquidServer) lastAction() msgMap {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.actions) == 0 {
+		return nil
+	}
+	return m.actions[len(m.actions)-1]
+}
+
+// field 读取有序 map 中的字段
+func field(m msgMap, key string) interface{} {
+	for _, f := range m {
+		if f.Key == key {
+			return f.Value
+		}
+	}
+	return nil
+}
+
+func newTestAdapter(t *testing.T, baseURL string, extra map[string]string) *HyperliquidAdapter {
+	cfg := map[string]string{
+		"api_key":    testUser,
+		"secret_key": testAgentKey,
+		"base_url":   baseURL,
+	}
+	for k, v := range extra {
+		cfg[k] = v
+	}
+	adapter, err := NewHyperliquidAdapter(cfg, "ETHUSDT")
+	if err != nil {
+		t.Fatalf("创建适配器失败: %v", err)
+	}
+	return adapter
+}
+
+// ===== 精度与格式 =====
+
+func TestPriceRules(t *testing.T) {
+	cases := []struct {
+		price      float64
+		szDecimals int
+		want       string
+	}{
+		{3000.126, 4, "3000.1"},     // 5 位有效数字
+		{0.123456789, 0, "0.12346"}, // 最多 6 - szDecimals 位小数
+		{123456.7, 5, "123457"},     // 整数价格不受有效数字限制
+		{1.23456, 4, "1.23"},        // 6 - 4 = 2 位小数
+	}
+	for _, c := range cases {
+		if got := floatToWire(roundPrice(c.price, c.szDecimals)); got != c.want {
+			t.Errorf("价格取整错误: price=%v, szDecimals=%d, got %s, want %s", c.price, c.szDecimals, got, c.want)
+		}
+	}
+
+	if got := priceDecimalsFor(3000.5, 4); got != 1 {
+		t.Errorf("ETH 价格精度错误: %d", got)
+	}
+	if got := priceDecimalsFor(0.15, 0); got != 5 {
+		t.Errorf("低价币价格精度错误: %d", got)
+	}
+	if got := floatToWire(100); got != "100" {
+		t.Errorf("floatToWire 错误: %s", got)
+	}
+}
+
+func TestClientOrderIDRoundTrip(t *testing.T) {
+	cloid := utils.AddBrokerPrefix("hyperliquid", "30001_S_1702468800001")
+	if cloid != "0x000000000000030001a1702468800001" {
+		t.Errorf("cloid 编码错误: %s", cloid)
+	}
+	if len(cloid) != 34 {
+		t.Errorf("cloid 长度错误: %d", len(cloid))
+	}
+	if got := utils.RemoveBrokerPrefix("hyperliquid", cloid); got != "30001_S_1702468800001" {
+		t.Errorf("cloid 解码错误: %s", got)
+	}
+	// 非本程序订单原样返回
+	foreign := "0x1234567890abcdef1234567890abcdef"
+	if got := utils.RemoveBrokerPrefix("hyperliquid", foreign); got != foreign {
+		t.Errorf("外部 cloid 不应被改写: %s", got)
+	}
+}
+
+// ===== REST =====
+
+func TestNewHyperliquidAdapterLoadsMeta(t *testing.T) {
+	_, server := newMockServer(t)
+	adapter := newTestAdapter(t, server.URL, nil)
+
+	if adapter.assetIndex != 1 || adapter.GetQuantityDecimals() != 4 || adapter.GetPriceDecimals() != 1 {
+		t.Errorf("资产信息错误: index=%d, qty=%d, price=%d", adapter.assetIndex, adapter.GetQuantityDecimals(), adapter.GetPriceDecimals())
+	}
+	if adapter.GetBaseAsset() != "ETH" || adapter.GetQuoteAsset() != "USDC" {
+		t.Errorf("币种错误: %s/%s", adapter.GetBaseAsset(), adapter.GetQuoteAsset())
+	}
+}
+
+func TestPlaceOrderSignsAction(t *testing.T) {
+	mock, server := newMockServer(t)
+	mock.exchange = func(msgMap) string {
+		return `{"status":"ok","response":{"type":"order","data":{"statuses":[{"resting":{"oid":77738308}}]}}}`
+	}
+	adapter := newTestAdapter(t, server.URL, nil)
+
+	order, err := adapter.PlaceOrder(context.Background(), &OrderRequest{
+		Symbol:        "ETHUSDT",
+		Side:          SideBuy,
+		Type:          OrderTypeLimit,
+		Quantity:      0.12345,
+		Price:         3000.13,
+		PostOnly:      true,
+		ClientOrderID: "300013_B_1702468800001",
+	})
+	if err != nil {
+		t.Fatalf("下单失败: %v", err)
+	}
+	if order.OrderID != 77738308 || order.Status != OrderStatusNew || order.ClientOrderID != "300013_B_1702468800001" {
+		t.Errorf("订单信息错误: %+v", order)
+	}
+
+	action := mock.lastAction()
+	if field(action, "type") != "order" || field(action, "grouping") != "na" {
+		t.Fatalf("action 错误: %+v", action)
+	}
+	wire := field(action, "orders").([]interface{})[0].(msgMap)
+	if field(wire, "a") != int64(1) || field(wire, "b") != true || field(wire, "p") != "3000.1" || field(wire, "s") != "0.1234" {
+		t.Errorf("订单参数错误: %+v", wire)
+	}
+	tif := field(field(field(wire, "t").(msgMap), "limit").(msgMap), "tif")
+	if tif != "Alo" || field(wire, "c") != "0x000000000000300013b1702468800001" {
+		t.Errorf("tif/cloid 错误: tif=%v, cloid=%v", tif, field(wire, "c"))
+	}
+}
+
+// 批量下单在一个 order action 中提交，逐单状态为 resting / filled / error，
+// 失败的订单不影响其余订单的结果
+func TestBulkOrderStatuses(t *testing.T) {
+	mock, server := newMockServer(t)
+	mock.exchange = func(msgMap) string {
+		return `{"status":"ok","response":{"type":"order","data":{"statuses":[
+			{"resting":{"oid":1}},
+			{"error":"Insufficient margin to place order. asset=1"},
+			{"filled":{"totalSz":"0.1","avgPx":"2990.5","oid":3}}]}}}`
+	}
+	adapter := newTestAdapter(t, server.URL, nil)
+
+	orders, marginErr := adapter.BatchPlaceOrders(context.Background(), []*OrderRequest{
+		{Symbol: "ETHUSDT", Side: SideBuy, Type: OrderTypeLimit, Quantity: 0.1, Price: 3000, ClientOrderID: "30000_B_1"},
+		{Symbol: "ETHUSDT", Side: SideBuy, Type: OrderTypeLimit, Quantity: 0.1, Price: 2995, ClientOrderID: "29950_B_2"},
+		{Symbol: "ETHUSDT", Side: SideBuy, Type: OrderTypeLimit, Quantity: 0.1, Price: 2990, ClientOrderID: "29900_B_3"},
+	})
+	if !marginErr {
+		t.Error("应检测到保证金不足")
+	}
+	if len(orders) != 2 || orders[1].Status != OrderStatusFilled || orders[1].AvgPrice != 2990.5 {
+		t.Errorf("成功订单错误: %+v", orders)
+	}
+	if n := len(field(mock.lastAction(), "orders").([]interface{})); n != 3 {
+		t.Errorf("应在一个 action 中提交 3 个订单, 实际 %d", n)
+	}
+}
+
+// Hyperliquid 没有错误码，逐单状态中的错误信息按内容映射为统一错误分类；
+// 撤单时"订单从未下达、已撤销或已成交"视为成功
+func TestErrorMessages(t *testing.T) {
+	mock, server := newMockServer(t)
+	adapter := newTestAdapter(t, server.URL, nil)
+	ctx := context.Background()
+
+	cases := []struct {
+		msg  string
+		want error
+	}{
+		{"Insufficient margin to place order. asset=1", errs.ErrInsufficientMargin},
+		{"Post only order would have immediately matched, bbo was 3000.1@3000.2. asset=1", errs.ErrPostOnlyWouldCross},
+		{"Order must have minimum value of $10. asset=1", errs.ErrMinNotional},
+	}
+	for _, c := range cases {
+		mock.exchange = func(msgMap) string {
+			return `{"status":"ok","response":{"type":"order","data":{"statuses":[{"error":"` + c.msg + `"}]}}}`
+		}
+		_, err := adapter.PlaceOrder(ctx, &OrderRequest{Symbol: "ETHUSDT", Side: SideBuy, Type: OrderTypeLimit, Quantity: 0.1, Price: 3000})
+		if !errors.Is(err, c.want) {
+			t.Errorf("%q: err = %v, want %v", c.msg, err, c.want)
+		}
+	}
+
+	mock.exchange = func(msgMap) string {
+		return `{"status":"ok","response":{"type":"cancel","data":{"statuses":[
+			{"error":"Order was never placed, already canceled, or filled. asset=1"}]}}}`
+	}
+	if err := adapter.CancelOrder(ctx, "ETHUSDT", 42); err != nil {
+		t.Errorf("订单不存在应被忽略: %v", err)
+	}
+	cancel := field(mock.lastAction(), "cancels").([]interface{})[0].(msgMap)
+	if field(cancel, "a") != int64(1) || field(cancel, "o") != int64(42) {
+		t.Errorf("撤单参数错误: %+v", cancel)
+	}
+}
+
+func TestGetOpenOrdersFiltersCoin(t *testing.T) {
+	mock, server := newMockServer(t)
+	mock.info["frontendOpenOrders"] = func(req map[string]interface{}) string {
+		if req["user"] != testUser {
+			t.Errorf("应使用主账户地址查询: %v", req["user"])
+		}
+		return `[
+			{"coin":"ETH","side":"B","limitPx":"3000.0","sz":"0.06","origSz":"0.1","oid":1,"timestamp":1700000000000,
+			 "cloid":"0x000000000000030000b1702468800001","orderType":"Limit"},
+			{"coin":"BTC","side":"A","limitPx":"65000","sz":"0.01","origSz":"0.01","oid":2,"timestamp":1700000000000}]`
+	}
+	adapter := newTestAdapter(t, server.URL, nil)
+
+	orders, err := adapter.GetOpenOrders(context.Background(), "ETHUSDT")
+	if err != nil {
+		t.Fatalf("查询挂单失败: %v", err)
+	}
+	if len(orders) != 1 {
+		t.Fatalf("应过滤其他币种, 实际 %d 个", len(orders))
+	}
+	o := orders[0]
+	if o.Status != OrderStatusPartiallyFilled || o.ExecutedQty != 0.04 || o.Quantity != 0.1 ||
+		o.ClientOrderID != "30000_B_1702468800001" || o.Symbol != "ETHUSDT" {
+		t.Errorf("订单转换错误: %+v", o)
+	}
+}
+
+func TestGetAccountAndPositions(t *testing.T) {
+	mock, server := newMockServer(t)
+	mock.info["clearinghouseState"] = func(map[string]interface{}) string {
+		return `{"marginSummary":{"accountValue":"1012.5","totalNtlPos":"1500","totalRawUsd":"-487.5","totalMarginUsed":"75"},
+			"withdrawable":"900.25",
+			"assetPositions":[{"type":"oneWay","position":{"coin":"ETH","szi":"-0.5","entryPx":"3025","positionValue":"1487.5",
+				"unrealizedPnl":"12.5","marginUsed":"75","leverage":{"type":"cross","value":20}}}]}`
+	}
+	adapter := newTestAdapter(t, server.URL, nil)
+
+	account, err := adapter.GetAccount(context.Background())
+	if err != nil {
+		t.Fatalf("获取账户失败: %v", err)
+	}
+	if account.TotalMarginBalance != 1012.5 || account.TotalWalletBalance != 1000 || account.AvailableBalance != 900.25 || account.AccountLeverage != 20 {
+		t.Errorf("账户信息错误: %+v", account)
+	}
+	positions, _ := adapter.GetPositions(context.Background(), "ETHUSDT")
+	if len(positions) != 1 || positions[0].Size != -0.5 || positions[0].MarkPrice != 2975 || positions[0].MarginType != "cross" {
+		t.Errorf("持仓错误: %+v", positions)
+	}
+}
+
+func TestGetHistoricalKlines(t *testing.T) {
+	mock, server := newMockServer(t)
+	mock.info["candleSnapshot"] = func(req map[string]interface{}) string {
+		inner := req["req"].(map[string]interface{})
+		if inner["coin"] != "ETH" || inner["interval"] != "1h" {
+			t.Errorf("K线参数错误: %v", inner)
+		}
+		return `[
+			{"t":1000,"T":1999,"s":"ETH","i":"1h","o":"1","c":"2","h":"3","l":"0.5","v":"10","n":5},
+			{"t":2000,"T":2999,"s":"ETH","i":"1h","o":"2","c":"3","h":"4","l":"1.5","v":"11","n":6},
+			{"t":3000,"T":99999999999999,"s":"ETH","i":"1h","o":"3","c":"4","h":"5","l":"2.5","v":"12","n":7}]`
+	}
+	adapter := newTestAdapter(t, server.URL, nil)
+
+	candles, err := adapter.GetHistoricalKlines(context.Background(), "ETHUSDT", "1h", 2)
+	if err != nil {
+		t.Fatalf("获取历史K线失败: %v", err)
+	}
+	if len(candles) != 2 || candles[0].Timestamp != 2000 || !candles[0].IsClosed || candles[1].IsClosed {
+		t.Errorf("K线转换错误: %+v %+v", candles[0], candles[1])
+	}
+}
+
+// ===== WebSocket =====
+
+func TestOrderStreamMergesFills(t *testing.T) {
+	_, server := newMockServer(t)
+
+	upgrader := websocket.Upgrader{}
+	wsServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		subscribed := map[string]bool{}
+		for len(subscribed) < 2 {
+			var sub struct {
+				Method       string            `json:"method"`
+				Subscription map[string]string `json:"subscription"`
+			}
+			if err := conn.ReadJSON(&sub); err != nil {
+				return
+			}
+			if sub.Subscription["user"] != testUser {
+				t.Errorf("订阅用户错误: %v", sub.Subscription)
+			}
+			subscribed[sub.Subscription["type"]] = true
+		}
+		if !subscribed["orderUpdates"] || !subscribed["userFills"] {
+			t.Errorf("订阅频道错误: %v", subscribed)
+		}
+
+		order := `{"coin":"ETH","side":"B","limitPx":"3000","sz":"%s","origSz":"0.1","oid":9,"timestamp":1700000000000,"cloid":"0x000000000000030000b1702468800001"}`
+		send := func(s string) { conn.WriteMessage(websocket.TextMessage, []byte(s)) }
+
+		send(`{"channel":"userFills","data":{"isSnapshot":true,"user":"` + testUser + `","fills":[{"coin":"ETH","px":"1","sz":"1","oid":1,"time":1}]}}`)
+		send(`{"channel":"orderUpdates","data":[{"order":` + strings.Replace(order, "%s", "0.1", 1) + `,"status":"open","statusTimestamp":1700000000001}]}`)
+		send(`{"channel":"userFills","data":{"user":"` + testUser + `","fills":[{"coin":"ETH","px":"2999","sz":"0.04","oid":9,"time":1700000000002}]}}`)
+		send(`{"channel":"userFills","data":{"user":"` + testUser + `","fills":[{"coin":"ETH","px":"2998","sz":"0.06","oid":9,"time":1700000000003}]}}`)
+		send(`{"channel":"orderUpdates","data":[{"order":` + strings.Replace(order, "%s", "0", 1) + `,"status":"filled","statusTimestamp":1700000000004}]}`)
+
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	defer wsServer.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(wsServer.URL, "http")
+	adapter := newTestAdapter(t, server.URL, map[string]string{"ws_url": wsURL})
+
+	updates := make(chan OrderUpdate, 10)
+	err := adapter.StartOrderStream(context.Background(), func(u interface{}) {
+		raw, _ := json.Marshal(u)
+		var update OrderUpdate
+		json.Unmarshal(raw, &update)
+		updates <- update
+	})
+	if err != nil {
+		t.Fatalf("启动订单流失败: %v", err)
+	}
+	defer adapter.StopOrderStream()
+
+	var got []OrderUpdate
+	timeout := time.After(5 * time.Second)
+	for len(got) < 3 {
+		select {
+		case u := <-updates:
+			got = append(got, u)
+		case <-timeout:
+			t.Fatalf("订单推送数量不足: %+v", got)
+		}
+	}
+
+	// 快照成交被忽略；第二笔成交使订单完全成交，不单独推送，由 filled 状态推送
+	if got[0].Status != OrderStatusNew || got[0].ClientOrderID != "30000_B_1702468800001" || got[0].Symbol != "ETHUSDT" {
+		t.Errorf("挂单推送错误: %+v", got[0])
+	}
+	if got[1].Status != OrderStatusPartiallyFilled || got[1].ExecutedQty != 0.04 || got[1].AvgPrice != 2999 {
+		t.Errorf("部分成交推送错误: %+v", got[1])
+	}
+	if got[2].Status != OrderStatusFilled || got[2].ExecutedQty != 0.1 || got[2].AvgPrice != 2998.4 {
+		t.Errorf("完全成交推送错误: %+v", got[2])
+	}
+	select {
+	case u := <-updates:
+		t.Errorf("不应有多余推送: %+v", u)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestKlineStreamEmitsClosedCandle(t *testing.T) {
+	_, server := newMockServer(t)
+
+	upgrader := websocket.Upgrader{}
+	wsServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var sub struct {
+			Subscription map[string]string `json:"subscription"`
+		}
+		if err := conn.ReadJSON(&sub); err != nil || sub.Subscription["type"] != "candle" ||
+			sub.Subscription["coin"] != "ETH" || sub.Subscription["interval"] != "1m" {
+			t.Errorf("订阅消息错误: %v, err=%v", sub, err)
+			return
+		}
+		push := func(openTime, close string) {
+			conn.WriteMessage(websocket.TextMessage, []byte(`{"channel":"candle","data":{"t":`+openTime+`,"T":0,"s":"ETH","i":"1m",
+				"o":"1","c":"`+close+`","h":"2","l":"0.5","v":"3","n":1}}`))
+		}
+		push("60000", "1.5")
+		push("60000", "1.8")
+		push("120000", "1.9")
+
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	defer wsServer.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(wsServer.URL, "http")
+	adapter := newTestAdapter(t, server.URL, map[string]string{"ws_url": wsURL})
+
+	candles := make(chan *Candle, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := adapter.StartKlineStream(ctx, []string{"ETHUSDT"}, "1m", func(c interface{}) { candles <- c.(*Candle) }); err != nil {
+		t.Fatalf("启动K线流失败: %v", err)
+	}
+	defer adapter.StopKlineStream()
+
+	var got []*Candle
+	timeout := time.After(5 * time.Second)
+	for len(got) < 4 {
+		select {
+		case c := <-candles:
+			got = append(got, c)
+		case <-timeout:
+			t.Fatalf("K线推送数量不足: %d", len(got))
+		}
+	}
+
+	if got[0].IsClosed || got[1].IsClosed {
+		t.Error("同一周期内的更新不应标记为完结")
+	}
+	if !got[2].IsClosed || got[2].Timestamp != 60000 || got[2].Close != 1.8 || got[2].Symbol != "ETHUSDT" {
+		t.Errorf("完结K线错误: %+v", got[2])
+	}
+	if got[3].IsClosed || got[3].Timestamp != 120000 {
+		t.Errorf("新周期K线错误: %+v", got[3])
+	}
+}
@@ -0,0 +1,158 @@
+package hyperliquid
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	HyperliquidMainnetURL = "https://api.hyperliquid.xyz"
+	HyperliquidTestnetURL = "https://api.hyperliquid-testnet.xyz"
+)
+
+// Client Hyperliquid HTTP 客户端
+// 查询类接口统一走 POST /info（无需签名），交易类接口走 POST /exchange（L1 action 签名）
+type Client struct {
+	httpClient *http.Client
+	signer     *Signer
+	baseURL    string
+}
+
+// NewClient 创建 Hyperliquid 客户端
+func NewClient(signer *Signer, baseURL string) *Client {
+	return &Client{
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		signer:     signer,
+		baseURL:    baseURL,
+	}
+}
+
+// APIError Hyperliquid 业务错误（status=err 或单笔订单返回 error）
+type APIError struct {
+	Msg string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("Hyperliquid API 错误: %s", e.Msg)
+}
+
+// exchangeResponse /exchange 响应
+// 成功: {"status":"ok","response":{"type":"order","data":{"statuses":[...]}}}
+// 失败: {"status":"err","response":"错误信息"}
+type exchangeResponse struct {
+	Status   string          `json:"status"`
+	Response json.RawMessage `json:"response"`
+}
+
+// Info 查询接口
+func (c *Client) Info(ctx context.Context, request interface{}, result interface{}) error {
+	respBody, err := c.post(ctx, "/info", request)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(respBody, result); err != nil {
+		return fmt.Errorf("解析响应失败: %w, 响应体: %s", err, string(respBody))
+	}
+	return nil
+}
+
+// Exchange 签名并提交 L1 action，返回每个子请求的状态
+func (c *Client) Exchange(ctx context.Context, action msgMap) ([]json.RawMessage, error) {
+	nonce := c.signer.NextNonce()
+	signature, err := c.signer.SignL1Action(action, "", nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := msgMap{
+		{"action", action},
+		{"nonce", nonce},
+		{"signature", signature},
+		{"vaultAddress", nil},
+	}
+	respBody, err := c.post(ctx, "/exchange", payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp exchangeResponse
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w, 响应体: %s", err, string(respBody))
+	}
+	if resp.Status != "ok" {
+		var msg string
+		if json.Unmarshal(resp.Response, &msg) != nil {
+			msg = string(resp.Response)
+		}
+		return nil, &APIError{Msg: msg}
+	}
+
+	var result struct {
+		Type string `json:"type"`
+		Data struct {
+			Statuses []json.RawMessage `json:"statuses"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(resp.Response, &result); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w, 响应体: %s", err, string(respBody))
+	}
+	return result.Data.Statuses, nil
+}
+
+// ServerTime 查询服务器时间
+// Hyperliquid 没有服务器时间接口，取轻量 info 请求响应头中的 Date（秒级精度，
+// 加半秒作为截断误差的期望值）；nonce 的有效窗口以天计，秒级精度足够
+func (c *Client) ServerTime(ctx context.Context) (time.Time, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/info", strings.NewReader(`{"type":"allMids"}`))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	date, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("响应缺少有效的 Date 头: %w", err)
+	}
+	return date.Add(500 * time.Millisecond), nil
+}
+
+func (c *Client) post(ctx context.Context, path string, body interface{}) ([]byte, error) {
+	bodyBytes, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("序列化请求体失败: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewBuffer(bodyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取响应失败: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &APIError{Msg: fmt.Sprintf("HTTP %d: %s", resp.StatusCode, string(respBody))}
+	}
+	return respBody, nil
+}
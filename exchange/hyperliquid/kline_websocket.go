@@ -0,0 +1,375 @@
+package hyperliquid
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"opensqt/logger"
+
+	"github.com/gorilla/websocket"
+)
+
+// hlCandle Hyperliquid K线结构（REST 与 WebSocket 共用）
+type hlCandle struct {
+	OpenTime  int64  `json:"t"`
+	CloseTime int64  `json:"T"`
+	Coin      string `json:"s"`
+	Interval  string `json:"i"`
+	Open      string `json:"o"`
+	Close     string `json:"c"`
+	High      string `json:"h"`
+	Low       string `json:"l"`
+	Volume    string `json:"v"` // 成交量（基础币）
+}
+
+func (c *hlCandle) toCandle(symbol string) *Candle {
+	open, _ := strconv.ParseFloat(c.Open, 64)
+	high, _ := strconv.ParseFloat(c.High, 64)
+	low, _ := strconv.ParseFloat(c.Low, 64)
+	close, _ := strconv.ParseFloat(c.Close, 64)
+	volume, _ := strconv.ParseFloat(c.Volume, 64)
+
+	return &Candle{
+		Symbol:    symbol,
+		Open:      open,
+		High:      high,
+		Low:       low,
+		Close:     close,
+		Volume:    volume,
+		Timestamp: c.OpenTime,
+	}
+}
+
+// KlineWebSocketManager Hyperliquid K线WebSocket管理器
+// Hyperliquid 的K线推送不带"是否完结"标记：收到新周期的K线时，将上一根缓存的K线以 IsClosed=true 补发
+type KlineWebSocketManager struct {
+	wsURL          string
+	conn           *websocket.Conn
+	mu             sync.RWMutex
+	writeMu        sync.Mutex
+	done           chan struct{}
+	callbacks      map[string]func(candle interface{}) // 支持多个回调函数，key为组件名称
+	symbols        []string
+	interval       string
+	reconnectDelay time.Duration
+	pingInterval   time.Duration
+	isRunning      bool
+
+	coinToSymbol map[string]string  // 币种名 -> 订阅时使用的交易对
+	lastCandles  map[string]*Candle // 每个交易对最近一根未完结K线
+}
+
+// NewKlineWebSocketManager 创建K线WebSocket管理器
+func NewKlineWebSocketManager(wsURL string) *KlineWebSocketManager {
+	if wsURL == "" {
+		wsURL = HyperliquidWSMainnet
+	}
+	return &KlineWebSocketManager{
+		wsURL:          wsURL,
+		done:           make(chan struct{}),
+		callbacks:      make(map[string]func(candle interface{})),
+		reconnectDelay: 5 * time.Second,
+		pingInterval:   30 * time.Second,
+		coinToSymbol:   make(map[string]string),
+		lastCandles:    make(map[string]*Candle),
+	}
+}
+
+// Start 启动K线流（带自动重连）
+func (k *KlineWebSocketManager) Start(ctx context.Context, symbols []string, interval string, callback func(candle interface{})) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if k.isRunning {
+		// 如果K线流已经在运行，只注册回调函数
+		k.callbacks["default"] = callback
+		return nil
+	}
+
+	k.callbacks["default"] = callback
+	k.symbols = symbols
+	k.interval = interval
+	k.isRunning = true
+
+	go k.connectLoop(ctx)
+
+	return nil
+}
+
+// RegisterCallback 注册回调函数（支持多个组件共享K线流）
+func (k *KlineWebSocketManager) RegisterCallback(componentName string, callback func(candle interface{})) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if !k.isRunning {
+		return fmt.Errorf("K线流未启动，请先调用Start")
+	}
+
+	k.callbacks[componentName] = callback
+	logger.Info("✅ [Hyperliquid K线] 已注册回调函数: %s", componentName)
+	return nil
+}
+
+// connectLoop 连接循环（自动重连）
+func (k *KlineWebSocketManager) connectLoop(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("✅ Hyperliquid K线WebSocket已停止（上下文取消）")
+			return
+		case <-k.done:
+			logger.Info("✅ Hyperliquid K线WebSocket已停止")
+			return
+		default:
+		}
+
+		logger.Info("🔗 正在连接 Hyperliquid K线WebSocket...")
+		conn, _, err := websocket.DefaultDialer.DialContext(ctx, k.wsURL, nil)
+		if err == nil {
+			k.mu.Lock()
+			k.conn = conn
+			k.mu.Unlock()
+			if err = k.subscribe(k.symbols, k.interval); err != nil {
+				conn.Close()
+			}
+		}
+		if err != nil {
+			logger.Error("❌ Hyperliquid K线WebSocket连接失败: %v，%v后重试", err, k.reconnectDelay)
+			select {
+			case <-ctx.Done():
+				return
+			case <-k.done:
+				return
+			case <-time.After(k.reconnectDelay):
+			}
+			continue
+		}
+
+		logger.Info("✅ Hyperliquid K线WebSocket已连接")
+
+		go k.pingLoop(ctx, conn)
+		k.readLoop(conn)
+
+		k.mu.Lock()
+		if k.conn == conn {
+			k.conn = nil
+		}
+		k.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			logger.Info("✅ Hyperliquid K线WebSocket已停止（上下文取消）")
+			return
+		case <-k.done:
+			logger.Info("✅ Hyperliquid K线WebSocket已停止")
+			return
+		default:
+		}
+
+		logger.Warn("⚠️ Hyperliquid K线WebSocket连接断开，%v后重连...", k.reconnectDelay)
+		select {
+		case <-ctx.Done():
+			return
+		case <-k.done:
+			return
+		case <-time.After(k.reconnectDelay):
+		}
+	}
+}
+
+// subscribe 订阅K线（每个币种一条订阅消息）
+// 订阅格式: {"method": "subscribe", "subscription": {"type": "candle", "coin": "ETH", "interval": "1m"}}
+func (k *KlineWebSocketManager) subscribe(symbols []string, interval string) error {
+	k.mu.Lock()
+	conn := k.conn
+	for _, symbol := range symbols {
+		k.coinToSymbol[convertToCoin(symbol)] = symbol
+	}
+	k.mu.Unlock()
+	if conn == nil {
+		return fmt.Errorf("WebSocket连接未建立")
+	}
+
+	k.writeMu.Lock()
+	defer k.writeMu.Unlock()
+	for _, symbol := range symbols {
+		msg := subscribeMessage(map[string]string{
+			"type":     "candle",
+			"coin":     convertToCoin(symbol),
+			"interval": interval,
+		})
+		if err := conn.WriteJSON(msg); err != nil {
+			return fmt.Errorf("发送订阅消息失败: %w", err)
+		}
+	}
+
+	logger.Debug("已发送K线订阅请求: %d个币种", len(symbols))
+	return nil
+}
+
+// Stop 停止K线流
+func (k *KlineWebSocketManager) Stop() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if !k.isRunning {
+		return
+	}
+
+	k.isRunning = false
+	close(k.done)
+
+	if k.conn != nil {
+		k.conn.Close()
+		k.conn = nil
+	}
+
+	logger.Info("✅ Hyperliquid K线WebSocket已停止")
+}
+
+// pingLoop ping循环
+func (k *KlineWebSocketManager) pingLoop(ctx context.Context, conn *websocket.Conn) {
+	ticker := time.NewTicker(k.pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-k.done:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			k.mu.RLock()
+			currentConn := k.conn
+			k.mu.RUnlock()
+			if currentConn != conn {
+				return
+			}
+
+			k.writeMu.Lock()
+			conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			err := conn.WriteJSON(map[string]string{"method": "ping"})
+			k.writeMu.Unlock()
+			if err != nil {
+				logger.Warn("⚠️ Hyperliquid K线WebSocket发送Ping失败: %v", err)
+				conn.Close()
+				return
+			}
+		}
+	}
+}
+
+// readLoop 读取消息循环
+func (k *KlineWebSocketManager) readLoop(conn *websocket.Conn) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("❌ Hyperliquid K线WebSocket读取协程panic: %v", r)
+		}
+		conn.Close()
+	}()
+
+	readTimeout := 2 * k.pingInterval
+	conn.SetReadDeadline(time.Now().Add(readTimeout))
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				logger.Warn("⚠️ Hyperliquid K线WebSocket异常关闭: %v", err)
+			} else {
+				logger.Debug("Hyperliquid K线WebSocket读取错误: %v", err)
+			}
+			return
+		}
+		conn.SetReadDeadline(time.Now().Add(readTimeout))
+
+		// 数据格式: {"channel":"candle","data":{"t":...,"T":...,"s":"ETH","i":"1m","o":"..","c":"..","h":"..","l":"..","v":"..","n":..}}
+		var msg struct {
+			Channel string          `json:"channel"`
+			Data    json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(message, &msg); err != nil {
+			logger.Debug("解析K线消息失败: %v", err)
+			continue
+		}
+
+		// 跳过 pong 和订阅确认
+		if msg.Channel != "candle" {
+			continue
+		}
+
+		var item hlCandle
+		if err := json.Unmarshal(msg.Data, &item); err != nil {
+			logger.Debug("解析K线数据失败: %v", err)
+			continue
+		}
+
+		k.mu.RLock()
+		symbol, ok := k.coinToSymbol[item.Coin]
+		k.mu.RUnlock()
+		if !ok {
+			symbol = item.Coin + hyperliquidQuote
+		}
+
+		for _, c := range k.trackCandle(item.toCandle(symbol)) {
+			k.dispatch(c)
+		}
+	}
+}
+
+// trackCandle 记录最新K线，返回需要派发的K线（新周期开始时先补发上一根已完结的K线）
+func (k *KlineWebSocketManager) trackCandle(candle *Candle) []*Candle {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	last := k.lastCandles[candle.Symbol]
+	if last != nil && candle.Timestamp < last.Timestamp {
+		return nil // 过期推送
+	}
+	k.lastCandles[candle.Symbol] = candle
+
+	if last != nil && candle.Timestamp > last.Timestamp {
+		closed := *last
+		closed.IsClosed = true
+		return []*Candle{&closed, candle}
+	}
+	return []*Candle{candle}
+}
+
+// dispatch 调用所有回调
+func (k *KlineWebSocketManager) dispatch(candle *Candle) {
+	k.mu.RLock()
+	callbacks := make([]func(candle interface{}), 0, len(k.callbacks))
+	for _, cb := range k.callbacks {
+		callbacks = append(callbacks, cb)
+	}
+	k.mu.RUnlock()
+
+	for _, callback := range callbacks {
+		if callback != nil {
+			callback(candle)
+		}
+	}
+}
+
+// ForceReconnect 强制重新连接K线流
+// 关闭当前连接后由 connectLoop 自动重连并重新订阅
+func (k *KlineWebSocketManager) ForceReconnect() error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if !k.isRunning {
+		return fmt.Errorf("K线流未启动，无法重新连接")
+	}
+
+	logger.Info("🔄 [Hyperliquid K线] 正在强制重新连接...")
+	if k.conn != nil {
+		k.conn.Close()
+		k.conn = nil
+	}
+	return nil
+}
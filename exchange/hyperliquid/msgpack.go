@@ -0,0 +1,166 @@
+package hyperliquid
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+)
+
+// msgField 有序字段
+type msgField struct {
+	Key   string
+	Value interface{}
+}
+
+// msgMap 保持字段顺序的 map
+// Hyperliquid 的 action 哈希基于 msgpack 序列化结果，字段顺序必须与官方 SDK 一致
+type msgMap []msgField
+
+// MarshalJSON 按字段顺序输出 JSON
+func (m msgMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range m {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(f.Key)
+		buf.Write(key)
+		buf.WriteByte(':')
+		value, err := json.Marshal(f.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// packMsgpack 按 msgpack 规范序列化（与 Python msgpack.packb 相同，整数使用最短编码）
+// 只支持 action 中会出现的类型：msgMap、[]interface{}、[]msgMap、string、bool、整数、nil
+func packMsgpack(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeMsgpack(&buf, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeMsgpack(buf *bytes.Buffer, v interface{}) error {
+	switch val := v.(type) {
+	case nil:
+		buf.WriteByte(0xc0)
+	case bool:
+		if val {
+			buf.WriteByte(0xc3)
+		} else {
+			buf.WriteByte(0xc2)
+		}
+	case int:
+		writeMsgpackInt(buf, int64(val))
+	case int64:
+		writeMsgpackInt(buf, val)
+	case uint64:
+		writeMsgpackUint(buf, val)
+	case string:
+		writeMsgpackString(buf, val)
+	case msgMap:
+		writeMsgpackHeader(buf, len(val), 0x80, 0xde, 0xdf)
+		for _, f := range val {
+			writeMsgpackString(buf, f.Key)
+			if err := writeMsgpack(buf, f.Value); err != nil {
+				return err
+			}
+		}
+	case []msgMap:
+		writeMsgpackHeader(buf, len(val), 0x90, 0xdc, 0xdd)
+		for _, item := range val {
+			if err := writeMsgpack(buf, item); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		writeMsgpackHeader(buf, len(val), 0x90, 0xdc, 0xdd)
+		for _, item := range val {
+			if err := writeMsgpack(buf, item); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("msgpack 不支持的类型: %T", v)
+	}
+	return nil
+}
+
+func writeMsgpackUint(buf *bytes.Buffer, n uint64) {
+	switch {
+	case n < 1<<7:
+		buf.WriteByte(byte(n))
+	case n < 1<<8:
+		buf.Write([]byte{0xcc, byte(n)})
+	case n < 1<<16:
+		buf.WriteByte(0xcd)
+		binary.Write(buf, binary.BigEndian, uint16(n))
+	case n < 1<<32:
+		buf.WriteByte(0xce)
+		binary.Write(buf, binary.BigEndian, uint32(n))
+	default:
+		buf.WriteByte(0xcf)
+		binary.Write(buf, binary.BigEndian, n)
+	}
+}
+
+func writeMsgpackInt(buf *bytes.Buffer, n int64) {
+	if n >= 0 {
+		writeMsgpackUint(buf, uint64(n))
+		return
+	}
+	switch {
+	case n >= -32:
+		buf.WriteByte(byte(n))
+	case n >= -1<<7:
+		buf.Write([]byte{0xd0, byte(n)})
+	case n >= -1<<15:
+		buf.WriteByte(0xd1)
+		binary.Write(buf, binary.BigEndian, int16(n))
+	case n >= -1<<31:
+		buf.WriteByte(0xd2)
+		binary.Write(buf, binary.BigEndian, int32(n))
+	default:
+		buf.WriteByte(0xd3)
+		binary.Write(buf, binary.BigEndian, n)
+	}
+}
+
+func writeMsgpackString(buf *bytes.Buffer, s string) {
+	n := len(s)
+	switch {
+	case n < 32:
+		buf.WriteByte(0xa0 | byte(n))
+	case n < 1<<8:
+		buf.Write([]byte{0xd9, byte(n)})
+	case n < 1<<16:
+		buf.WriteByte(0xda)
+		binary.Write(buf, binary.BigEndian, uint16(n))
+	default:
+		buf.WriteByte(0xdb)
+		binary.Write(buf, binary.BigEndian, uint32(n))
+	}
+	buf.WriteString(s)
+}
+
+// writeMsgpackHeader 写入 map/array 头（fix / 16位 / 32位长度）
+func writeMsgpackHeader(buf *bytes.Buffer, n int, fixPrefix, prefix16, prefix32 byte) {
+	switch {
+	case n < 16:
+		buf.WriteByte(fixPrefix | byte(n))
+	case n < 1<<16:
+		buf.WriteByte(prefix16)
+		binary.Write(buf, binary.BigEndian, uint16(n))
+	default:
+		buf.WriteByte(prefix32)
+		binary.Write(buf, binary.BigEndian, uint32(n))
+	}
+}
@@ -0,0 +1,101 @@
+package hyperliquid
+
+/*
+以太坊签名原语（secp256k1 ECDSA + Keccak256）
+
+曲线运算、RFC6979 确定性 k 与 low-S 规范化均交给 decred secp256k1（常量时间实现，
+go-ethereum 同样基于该库），这里只负责以太坊侧的格式：
+v = 27 + recid，地址 = keccak256(公钥 x||y) 的后 20 字节
+*/
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"golang.org/x/crypto/sha3"
+)
+
+// keccak256 以太坊使用的 Keccak256（非标准 SHA3）
+func keccak256(data ...[]byte) []byte {
+	hasher := sha3.NewLegacyKeccak256()
+	for _, d := range data {
+		hasher.Write(d)
+	}
+	return hasher.Sum(nil)
+}
+
+// ParsePrivateKey 解析十六进制私钥（可带 0x 前缀）
+func ParsePrivateKey(privateKeyHex string) (*secp256k1.PrivateKey, error) {
+	raw := strings.TrimPrefix(strings.TrimSpace(privateKeyHex), "0x")
+	if len(raw) == 0 || len(raw) > 64 {
+		return nil, fmt.Errorf("私钥长度无效")
+	}
+	if len(raw)%2 == 1 {
+		raw = "0" + raw
+	}
+	keyBytes, err := hex.DecodeString(raw)
+	if err != nil {
+		return nil, fmt.Errorf("私钥不是有效的十六进制")
+	}
+
+	var scalar secp256k1.ModNScalar
+	if overflow := scalar.SetByteSlice(keyBytes); overflow || scalar.IsZero() {
+		return nil, fmt.Errorf("私钥超出曲线范围")
+	}
+	return secp256k1.NewPrivateKey(&scalar), nil
+}
+
+// PrivateKeyToAddress 由私钥推导以太坊地址（0x 开头的小写十六进制）
+func PrivateKeyToAddress(privateKey *secp256k1.PrivateKey) string {
+	return publicKeyToAddress(privateKey.PubKey())
+}
+
+func publicKeyToAddress(pub *secp256k1.PublicKey) string {
+	// 去掉非压缩公钥的 0x04 前缀，只对 x||y 做哈希
+	hash := keccak256(pub.SerializeUncompressed()[1:])
+	return "0x" + hex.EncodeToString(hash[12:])
+}
+
+// Sign 对 32 字节摘要签名，返回 r、s 与恢复标识 v（27/28，r 溢出 N 时为 29/30）
+func Sign(digest []byte, privateKey *secp256k1.PrivateKey) (r, s *big.Int, v int, err error) {
+	if len(digest) != 32 {
+		return nil, nil, 0, fmt.Errorf("摘要长度必须为32字节")
+	}
+	// 紧凑格式: [27+recid][R 32字节][S 32字节]，s 已规范到低半区
+	sig := ecdsa.SignCompact(privateKey, digest, false)
+	r = new(big.Int).SetBytes(sig[1:33])
+	s = new(big.Int).SetBytes(sig[33:65])
+	return r, s, int(sig[0]), nil
+}
+
+// RecoverAddress 由签名恢复签名者地址（用于校验签名）
+func RecoverAddress(digest []byte, r, s *big.Int, v int) (string, error) {
+	if v < 27 {
+		v += 27
+	}
+	if v < 27 || v > 30 {
+		return "", fmt.Errorf("无效的恢复标识 v=%d", v)
+	}
+	if r.Sign() <= 0 || r.BitLen() > 256 || s.Sign() <= 0 || s.BitLen() > 256 {
+		return "", fmt.Errorf("签名超出曲线范围")
+	}
+
+	sig := make([]byte, 65)
+	sig[0] = byte(v)
+	r.FillBytes(sig[1:33])
+	s.FillBytes(sig[33:65])
+	pub, _, err := ecdsa.RecoverCompact(sig, digest)
+	if err != nil {
+		return "", fmt.Errorf("恢复公钥失败: %w", err)
+	}
+	return publicKeyToAddress(pub), nil
+}
+
+// intToBytes32 大整数转为 32 字节大端序
+func intToBytes32(x *big.Int) []byte {
+	return x.FillBytes(make([]byte, 32))
+}
@@ -0,0 +1,136 @@
+package hyperliquid
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"opensqt/exchange/clock"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// Signature Hyperliquid 请求签名（r/s 为 0x 前缀的十六进制，v 为 27/28）
+type Signature struct {
+	R string `json:"r"`
+	S string `json:"s"`
+	V int    `json:"v"`
+}
+
+// Signer Hyperliquid L1 action 签名器
+//
+// 签名流程（与官方 Python SDK sign_l1_action 一致）:
+//  1. connectionId = keccak256(msgpack(action) || nonce(8字节大端) || vault标记)
+//  2. 构造 phantom agent {source: "a"(主网)/"b"(测试网), connectionId}
+//  3. 按 EIP-712（domain: Exchange/1/chainId 1337/零地址）签名 Agent 结构
+type Signer struct {
+	privateKey *secp256k1.PrivateKey
+	address    string // 签名钱包地址（API/agent 钱包）
+	isMainnet  bool
+	clock      *clock.Estimator // 服务器时间（nonce 使用），nil 时使用本地时间
+
+	mu        sync.Mutex
+	lastNonce int64
+}
+
+var (
+	eip712DomainTypeHash = keccak256([]byte("EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)"))
+	agentTypeHash        = keccak256([]byte("Agent(string source,bytes32 connectionId)"))
+	exchangeDomainHash   = keccak256(
+		eip712DomainTypeHash,
+		keccak256([]byte("Exchange")),
+		keccak256([]byte("1")),
+		intToBytes32(big.NewInt(1337)),
+		make([]byte, 32), // verifyingContract = 0x0000000000000000000000000000000000000000
+	)
+)
+
+// NewSigner 创建签名器
+func NewSigner(privateKeyHex string, isMainnet bool) (*Signer, error) {
+	privateKey, err := ParsePrivateKey(privateKeyHex)
+	if err != nil {
+		return nil, err
+	}
+	return &Signer{
+		privateKey: privateKey,
+		address:    PrivateKeyToAddress(privateKey),
+		isMainnet:  isMainnet,
+	}, nil
+}
+
+// GetAddress 获取签名钱包地址
+func (s *Signer) GetAddress() string {
+	return s.address
+}
+
+// NextNonce 生成 nonce（校正后的毫秒时间戳，保证严格递增，避免同一毫秒内重复）
+func (s *Signer) NextNonce() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	nonce := s.clock.Now().UnixMilli()
+	if nonce <= s.lastNonce {
+		nonce = s.lastNonce + 1
+	}
+	s.lastNonce = nonce
+	return nonce
+}
+
+// SignL1Action 对 L1 action（下单、撤单等）签名
+// vaultAddress 为空表示以账户本身交易
+func (s *Signer) SignL1Action(action msgMap, vaultAddress string, nonce int64) (*Signature, error) {
+	connectionID, err := actionHash(action, vaultAddress, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	source := "b"
+	if s.isMainnet {
+		source = "a"
+	}
+	digest := agentDigest(source, connectionID)
+
+	r, sv, v, err := Sign(digest, s.privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("签名失败: %w", err)
+	}
+	return &Signature{
+		R: "0x" + r.Text(16),
+		S: "0x" + sv.Text(16),
+		V: v,
+	}, nil
+}
+
+// actionHash 计算 action 的 connectionId
+func actionHash(action msgMap, vaultAddress string, nonce int64) ([]byte, error) {
+	data, err := packMsgpack(action)
+	if err != nil {
+		return nil, fmt.Errorf("序列化 action 失败: %w", err)
+	}
+
+	nonceBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(nonceBytes, uint64(nonce))
+	data = append(data, nonceBytes...)
+
+	if vaultAddress == "" {
+		data = append(data, 0x00)
+	} else {
+		vault, err := hex.DecodeString(strings.TrimPrefix(vaultAddress, "0x"))
+		if err != nil || len(vault) != 20 {
+			return nil, fmt.Errorf("无效的 vault 地址: %s", vaultAddress)
+		}
+		data = append(data, 0x01)
+		data = append(data, vault...)
+	}
+
+	return keccak256(data), nil
+}
+
+// agentDigest EIP-712 签名摘要: keccak256(0x1901 || domainSeparator || hashStruct(Agent))
+func agentDigest(source string, connectionID []byte) []byte {
+	structHash := keccak256(agentTypeHash, keccak256([]byte(source)), connectionID)
+	return keccak256([]byte{0x19, 0x01}, exchangeDomainHash, structHash)
+}
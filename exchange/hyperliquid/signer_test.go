@@ -0,0 +1,189 @@
+package hyperliquid
+
+import (
+	"context"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"opensqt/exchange/clock"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// 以下向量取自 Hyperliquid 官方 Python SDK 的签名测试（tests/signing_test.py）
+
+const sdkTestPrivateKey = "0x0123456789012345678901234567890123456789012345678901234567890123"
+
+func hexToBig(s string) *big.Int {
+	n, _ := new(big.Int).SetString(strings.TrimPrefix(s, "0x"), 16)
+	return n
+}
+
+func TestPrivateKeyToAddress(t *testing.T) {
+	key, err := ParsePrivateKey("0x1")
+	if err != nil {
+		t.Fatalf("解析私钥失败: %v", err)
+	}
+	if got := PrivateKeyToAddress(key); got != "0x7e5f4552091a69125d5dfcb7b8c2659029395bdf" {
+		t.Errorf("地址错误: %s", got)
+	}
+	if _, err := ParsePrivateKey("0x00"); err == nil {
+		t.Error("私钥为 0 时应返回错误")
+	}
+	if _, err := ParsePrivateKey(secp256k1.Params().N.Text(16)); err == nil {
+		t.Error("私钥不小于 N 时应返回错误")
+	}
+}
+
+// 官方 SDK test_phantom_agent_creation_matches_production：
+// 以真实下单请求核对 msgpack 序列化与 connectionId
+func TestActionHashMatchesProduction(t *testing.T) {
+	action := buildOrderAction([]msgMap{{
+		{"a", 4},
+		{"b", true},
+		{"p", "1670.1"},
+		{"s", "0.0147"},
+		{"r", false},
+		{"t", msgMap{{"limit", msgMap{{"tif", "Ioc"}}}}},
+	}})
+
+	hash, err := actionHash(action, "", 1677777606040)
+	if err != nil {
+		t.Fatalf("计算 action 哈希失败: %v", err)
+	}
+	if got := "0x" + hex.EncodeToString(hash); got != "0x0fcbeda5ae3c4950a548021552a4fea2226858c4453571bf3f24ba017eac2908" {
+		t.Errorf("connectionId 错误: %s", got)
+	}
+}
+
+func TestMsgpackEncoding(t *testing.T) {
+	data, err := packMsgpack(msgMap{
+		{"type", "dummy"},
+		{"num", int64(100000000000)},
+		{"list", []interface{}{true, false, nil, -1, 200}},
+	})
+	if err != nil {
+		t.Fatalf("序列化失败: %v", err)
+	}
+	want := "83" + "a474797065" + "a564756d6d79" + "a36e756d" + "cf000000174876e800" +
+		"a46c697374" + "95" + "c3" + "c2" + "c0" + "ff" + "ccc8"
+	if got := hex.EncodeToString(data); got != want {
+		t.Errorf("msgpack 编码错误:\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestSignL1ActionKnownVectors(t *testing.T) {
+	action := msgMap{
+		{"type", "dummy"},
+		{"num", int64(100000000000)}, // float_to_int_for_hashing(1000)
+	}
+
+	cases := []struct {
+		isMainnet bool
+		vault     string
+		r, s      string
+		v         int
+	}{
+		{true, "", "0x53749d5b30552aeb2fca34b530185976545bb22d0b3ce6f62e31be961a59298", "0x755c40ba9bf05223521753995abb2f73ab3229be8ec921f350cb447e384d8ed8", 27},
+		{false, "", "0x542af61ef1f429707e3c76c5293c80d01f74ef853e34b76efffcb57e574f9510", "0x17b8b32f086e8cdede991f1e2c529f5dd5297cbe8128500e00cbaf766204a613", 28},
+		// test_l1_action_signing_matches_with_vault
+		{true, "0x1719884eb866cb12b2287399b15f7db5e7d775ea", "0x3c548db75e479f8012acf3000ca3a6b05606bc2ec0c29c50c515066a326239", "0x4d402be7396ce74fbba3795769cda45aec00dc3125a984f2a9f23177b190da2c", 28},
+	}
+
+	for _, c := range cases {
+		signer, err := NewSigner(sdkTestPrivateKey, c.isMainnet)
+		if err != nil {
+			t.Fatalf("创建签名器失败: %v", err)
+		}
+		sig, err := signer.SignL1Action(action, c.vault, 0)
+		if err != nil {
+			t.Fatalf("签名失败: %v", err)
+		}
+		if sig.R != c.r || sig.S != c.s || sig.V != c.v {
+			t.Errorf("签名错误(mainnet=%v, vault=%q): got %+v", c.isMainnet, c.vault, sig)
+		}
+
+		// v 必须能恢复出签名钱包地址
+		source := "b"
+		if c.isMainnet {
+			source = "a"
+		}
+		hash, _ := actionHash(action, c.vault, 0)
+		addr, err := RecoverAddress(agentDigest(source, hash), hexToBig(sig.R), hexToBig(sig.S), sig.V)
+		if err != nil || addr != signer.GetAddress() {
+			t.Errorf("恢复地址不一致(mainnet=%v, vault=%q): got %s, err=%v", c.isMainnet, c.vault, addr, err)
+		}
+	}
+}
+
+func TestSignL1ActionOrderKnownVectors(t *testing.T) {
+	action := buildOrderAction([]msgMap{{
+		{"a", 1},
+		{"b", true},
+		{"p", "100"},
+		{"s", "100"},
+		{"r", false},
+		{"t", msgMap{{"limit", msgMap{{"tif", "Gtc"}}}}},
+	}})
+
+	cases := []struct {
+		isMainnet bool
+		r, s      string
+		v         int
+	}{
+		{true, "0xd65369825a9df5d80099e513cce430311d7d26ddf477f5b3a33d2806b100d78e", "0x2b54116ff64054968aa237c20ca9ff68000f977c93289157748a3162b6ea940e", 28},
+		{false, "0x82b2ba28e76b3d761093aaded1b1cdad4960b3af30212b343fb2e6cdfa4e3d54", "0x6b53878fc99d26047f4d7e8c90eb98955a109f44209163f52d8dc4278cbbd9f5", 27},
+	}
+
+	for _, c := range cases {
+		signer, _ := NewSigner(sdkTestPrivateKey, c.isMainnet)
+		sig, err := signer.SignL1Action(action, "", 0)
+		if err != nil {
+			t.Fatalf("签名失败: %v", err)
+		}
+		if sig.R != c.r || sig.S != c.s || sig.V != c.v {
+			t.Errorf("订单签名错误(mainnet=%v): got %+v", c.isMainnet, sig)
+		}
+	}
+}
+
+func TestRecoverAddressRoundTrip(t *testing.T) {
+	signer, _ := NewSigner(sdkTestPrivateKey, true)
+	digest := keccak256([]byte("opensqt"))
+
+	r, s, v, err := Sign(digest, signer.privateKey)
+	if err != nil {
+		t.Fatalf("签名失败: %v", err)
+	}
+	if s.Cmp(new(big.Int).Rsh(secp256k1.Params().N, 1)) > 0 {
+		t.Error("s 应规范化到低半区")
+	}
+	addr, err := RecoverAddress(digest, r, s, v)
+	if err != nil {
+		t.Fatalf("恢复地址失败: %v", err)
+	}
+	if addr != signer.GetAddress() {
+		t.Errorf("恢复地址不一致: got %s, want %s", addr, signer.GetAddress())
+	}
+}
+
+func TestNextNonceUsesServerClock(t *testing.T) {
+	signer, _ := NewSigner(sdkTestPrivateKey, true)
+	signer.clock = clock.NewEstimator("Test", func(ctx context.Context) (time.Time, error) {
+		return time.Now().Add(time.Hour), nil
+	})
+	if err := signer.clock.Sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	first := signer.NextNonce()
+	if skew := time.UnixMilli(first).Sub(time.Now()); skew < 59*time.Minute || skew > 61*time.Minute {
+		t.Errorf("nonce 应按服务器时间生成, 偏差 %v", skew)
+	}
+	if second := signer.NextNonce(); second <= first {
+		t.Errorf("nonce 应严格递增: %d <= %d", second, first)
+	}
+}
@@ -0,0 +1,576 @@
+package hyperliquid
+
+/*
+Hyperliquid WebSocket 架构说明：
+
+1. 所有频道共用一个地址 (wss://api.hyperliquid.xyz/ws)，订阅无需签名
+2. **行情连接**：订阅 allMids，取当前币种中间价作为价格推送；启用盘口最优价时额外订阅 bbo
+3. **订单连接**：订阅 orderUpdates（订单状态）与 userFills（逐笔成交），按主账户地址过滤
+   - orderUpdates 是订单状态的权威来源（NEW/PARTIALLY_FILLED/FILLED/CANCELED）
+   - userFills 用于累计成交均价，并在订单仍挂着时推送部分成交
+4. K线推送见 kline_websocket.go
+5. 心跳为 {"method":"ping"}，服务端回复 {"channel":"pong"}，60秒无消息会断开
+*/
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"opensqt/exchange/orderbook"
+	"opensqt/logger"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	HyperliquidWSMainnet = "wss://api.hyperliquid.xyz/ws"
+	HyperliquidWSTestnet = "wss://api.hyperliquid-testnet.xyz/ws"
+)
+
+// fillAggregate 单个订单的累计成交
+type fillAggregate struct {
+	qty   float64
+	value float64
+}
+
+// WebSocketManager Hyperliquid WebSocket 管理器
+type WebSocketManager struct {
+	wsURL string
+
+	// adapter 用于订单结构转换（由适配器注入）
+	adapter *HyperliquidAdapter
+
+	mu          sync.RWMutex
+	writeMu     sync.Mutex
+	publicConn  *websocket.Conn
+	privateConn *websocket.Conn
+
+	// 回调函数
+	orderCallback func(OrderUpdate)
+	priceCallback func(float64)
+	bookCallback  func(*orderbook.Ticker)
+
+	// 控制
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	publicStarted  bool
+	privateStarted bool
+	coin           string
+	user           string
+
+	// 价格缓存
+	latestPrice float64
+	priceMu     sync.RWMutex
+
+	// 订单成交跟踪（oid -> 累计成交 / 原始数量）
+	fillMu     sync.Mutex
+	fills      map[int64]*fillAggregate
+	openOrders map[int64]*hlOrder
+
+	reconnectDelay time.Duration
+	pingInterval   time.Duration
+}
+
+// NewWebSocketManager 创建 WebSocket 管理器
+func NewWebSocketManager(wsURL string, adapter *HyperliquidAdapter) *WebSocketManager {
+	return &WebSocketManager{
+		wsURL:          wsURL,
+		adapter:        adapter,
+		fills:          make(map[int64]*fillAggregate),
+		openOrders:     make(map[int64]*hlOrder),
+		reconnectDelay: 5 * time.Second,
+		pingInterval:   30 * time.Second, // 60秒无消息会断开，30秒发送一次 ping
+	}
+}
+
+// ensureContext 初始化内部 context（首次启动时）
+func (w *WebSocketManager) ensureContext(ctx context.Context) {
+	if w.ctx == nil || w.ctx.Err() != nil {
+		w.ctx, w.cancel = context.WithCancel(ctx)
+	}
+}
+
+// StartPublic 启动行情连接（价格推送）
+func (w *WebSocketManager) StartPublic(ctx context.Context, coin string, callback func(float64)) error {
+	w.mu.Lock()
+	w.priceCallback = callback
+	w.coin = coin
+	if w.publicStarted {
+		w.mu.Unlock()
+		logger.Debug("✅ [Hyperliquid] 价格流回调已注册（WebSocket已在运行）")
+		return nil
+	}
+	w.ensureContext(ctx)
+	w.publicStarted = true
+	w.mu.Unlock()
+
+	w.wg.Add(1)
+	go w.connectLoop("行情", w.onPublicConnected, w.handlePublicMessage)
+
+	logger.Info("✅ [Hyperliquid WebSocket] 启动成功，将订阅 %s 的价格更新", coin)
+	return nil
+}
+
+// StartBookTicker 注册盘口最优价回调并订阅 bbo，行情连接未运行时一并启动
+func (w *WebSocketManager) StartBookTicker(ctx context.Context, coin string, callback func(*orderbook.Ticker)) error {
+	w.mu.Lock()
+	if w.publicStarted && w.coin != coin {
+		w.mu.Unlock()
+		return fmt.Errorf("行情连接已订阅 %s，不支持同时订阅 %s", w.coin, coin)
+	}
+	w.bookCallback = callback
+	w.coin = coin
+	if w.publicStarted {
+		conn := w.publicConn
+		w.mu.Unlock()
+		// 已连接时立即订阅，否则等待 onPublicConnected 订阅
+		if conn != nil {
+			if err := w.writeJSON(conn, subscribeMessage(map[string]string{"type": "bbo", "coin": coin})); err != nil {
+				logger.Warn("⚠️ [Hyperliquid WebSocket] 订阅 bbo 失败: %v，重连后重试", err)
+			}
+		}
+		return nil
+	}
+	w.ensureContext(ctx)
+	w.publicStarted = true
+	w.mu.Unlock()
+
+	w.wg.Add(1)
+	go w.connectLoop("行情", w.onPublicConnected, w.handlePublicMessage)
+
+	logger.Info("✅ [Hyperliquid WebSocket] 启动成功，将订阅 %s 的盘口最优价", coin)
+	return nil
+}
+
+// StartPrivate 启动订单连接（订单与成交推送）
+func (w *WebSocketManager) StartPrivate(ctx context.Context, user string, callback func(OrderUpdate)) error {
+	w.mu.Lock()
+	w.orderCallback = callback
+	w.user = user
+	if w.privateStarted {
+		w.mu.Unlock()
+		return nil
+	}
+	w.ensureContext(ctx)
+	w.privateStarted = true
+	w.mu.Unlock()
+
+	w.wg.Add(1)
+	go w.connectLoop("订单", w.onPrivateConnected, w.handlePrivateMessage)
+
+	logger.Info("✅ [Hyperliquid WebSocket] 启动成功，将订阅账户 %s 的订单更新", user)
+	return nil
+}
+
+// Stop 停止 WebSocket
+func (w *WebSocketManager) Stop() {
+	w.mu.Lock()
+	if w.cancel != nil {
+		w.cancel()
+	}
+	if w.publicConn != nil {
+		w.publicConn.Close()
+	}
+	if w.privateConn != nil {
+		w.privateConn.Close()
+	}
+	w.publicStarted = false
+	w.privateStarted = false
+	w.mu.Unlock()
+
+	// 等待所有 goroutine 退出（不能持有锁，避免死锁）
+	w.wg.Wait()
+	logger.Info("✅ [Hyperliquid WebSocket] 已停止")
+}
+
+// GetLatestPrice 获取缓存的最新价格
+func (w *WebSocketManager) GetLatestPrice() float64 {
+	w.priceMu.RLock()
+	defer w.priceMu.RUnlock()
+	return w.latestPrice
+}
+
+// connectLoop 连接循环（自动重连）
+// onConnected: 连接建立后执行订阅，handler: 处理每条消息
+func (w *WebSocketManager) connectLoop(name string, onConnected func(*websocket.Conn) error, handler func([]byte)) {
+	defer w.wg.Done()
+
+	for {
+		select {
+		case <-w.ctx.Done():
+			logger.Info("✅ [Hyperliquid WS%s] 停止连接循环", name)
+			return
+		default:
+		}
+
+		logger.Info("🔗 [Hyperliquid WS%s] 正在连接...", name)
+		conn, _, err := websocket.DefaultDialer.DialContext(w.ctx, w.wsURL, nil)
+		if err == nil {
+			if err = onConnected(conn); err != nil {
+				conn.Close()
+			}
+		}
+		if err != nil {
+			logger.Error("❌ [Hyperliquid WS%s] 连接失败: %v，%v后重试", name, err, w.reconnectDelay)
+			select {
+			case <-w.ctx.Done():
+				logger.Info("✅ [Hyperliquid WS%s] 停止连接循环", name)
+				return
+			case <-time.After(w.reconnectDelay):
+			}
+			continue
+		}
+
+		logger.Info("✅ [Hyperliquid WS%s] 已连接", name)
+
+		// 启动心跳，读取循环阻塞直到连接断开
+		pingDone := make(chan struct{})
+		go w.pingLoop(conn, pingDone)
+		w.readLoop(conn, handler)
+		close(pingDone)
+
+		w.mu.Lock()
+		if w.publicConn == conn {
+			w.publicConn = nil
+		}
+		if w.privateConn == conn {
+			w.privateConn = nil
+		}
+		w.mu.Unlock()
+		conn.Close()
+
+		select {
+		case <-w.ctx.Done():
+			logger.Info("✅ [Hyperliquid WS%s] 停止连接循环", name)
+			return
+		default:
+		}
+
+		logger.Warn("⚠️ [Hyperliquid WS%s] 连接断开，%v后重连...", name, w.reconnectDelay)
+		select {
+		case <-w.ctx.Done():
+			logger.Info("✅ [Hyperliquid WS%s] 停止连接循环", name)
+			return
+		case <-time.After(w.reconnectDelay):
+		}
+	}
+}
+
+// subscribeMessage 构造订阅消息
+func subscribeMessage(subscription map[string]string) map[string]interface{} {
+	return map[string]interface{}{
+		"method":       "subscribe",
+		"subscription": subscription,
+	}
+}
+
+// onPublicConnected 行情连接建立后订阅 allMids（已注册盘口回调时同时订阅 bbo）
+func (w *WebSocketManager) onPublicConnected(conn *websocket.Conn) error {
+	w.mu.Lock()
+	w.publicConn = conn
+	coin := w.coin
+	withBBO := w.bookCallback != nil
+	w.mu.Unlock()
+
+	if err := w.writeJSON(conn, subscribeMessage(map[string]string{"type": "allMids"})); err != nil {
+		return err
+	}
+	if withBBO {
+		return w.writeJSON(conn, subscribeMessage(map[string]string{"type": "bbo", "coin": coin}))
+	}
+	return nil
+}
+
+// onPrivateConnected 订单连接建立后订阅 orderUpdates 与 userFills
+func (w *WebSocketManager) onPrivateConnected(conn *websocket.Conn) error {
+	w.mu.Lock()
+	w.privateConn = conn
+	user := w.user
+	w.mu.Unlock()
+
+	for _, channel := range []string{"orderUpdates", "userFills"} {
+		if err := w.writeJSON(conn, subscribeMessage(map[string]string{"type": channel, "user": user})); err != nil {
+			return fmt.Errorf("订阅 %s 失败: %w", channel, err)
+		}
+	}
+	return nil
+}
+
+// writeJSON 串行写入（gorilla/websocket 不支持并发写）
+func (w *WebSocketManager) writeJSON(conn *websocket.Conn, v interface{}) error {
+	w.writeMu.Lock()
+	defer w.writeMu.Unlock()
+	return conn.WriteJSON(v)
+}
+
+// pingLoop 心跳循环
+func (w *WebSocketManager) pingLoop(conn *websocket.Conn, done chan struct{}) {
+	ticker := time.NewTicker(w.pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-w.ctx.Done():
+			return
+		case <-ticker.C:
+			w.writeMu.Lock()
+			conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			err := conn.WriteJSON(map[string]string{"method": "ping"})
+			w.writeMu.Unlock()
+			if err != nil {
+				logger.Warn("⚠️ [Hyperliquid WebSocket] 发送 Ping 失败: %v", err)
+				conn.Close()
+				return
+			}
+		}
+	}
+}
+
+// readLoop 读取消息循环
+func (w *WebSocketManager) readLoop(conn *websocket.Conn, handler func([]byte)) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("❌ [Hyperliquid WebSocket] 读取协程panic: %v", r)
+		}
+	}()
+
+	readTimeout := 2 * w.pingInterval
+	conn.SetReadDeadline(time.Now().Add(readTimeout))
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				logger.Warn("⚠️ [Hyperliquid WebSocket] 异常关闭: %v", err)
+			} else {
+				logger.Debug("Hyperliquid WebSocket 读取错误: %v", err)
+			}
+			return
+		}
+		conn.SetReadDeadline(time.Now().Add(readTimeout))
+		handler(message)
+	}
+}
+
+// wsMessage Hyperliquid WebSocket 推送消息
+type wsMessage struct {
+	Channel string          `json:"channel"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// handlePublicMessage 处理行情消息
+func (w *WebSocketManager) handlePublicMessage(message []byte) {
+	var msg wsMessage
+	if err := json.Unmarshal(message, &msg); err != nil {
+		logger.Debug("解析 Hyperliquid 行情消息失败: %v", err)
+		return
+	}
+	if msg.Channel == "bbo" {
+		w.handleBBO(msg.Data)
+		return
+	}
+	if msg.Channel != "allMids" {
+		return // pong / subscriptionResponse
+	}
+
+	var data struct {
+		Mids map[string]string `json:"mids"`
+	}
+	if err := json.Unmarshal(msg.Data, &data); err != nil {
+		return
+	}
+
+	w.mu.RLock()
+	coin := w.coin
+	callback := w.priceCallback
+	w.mu.RUnlock()
+
+	price, err := strconv.ParseFloat(data.Mids[coin], 64)
+	if err != nil || price <= 0 {
+		return
+	}
+
+	w.priceMu.Lock()
+	w.latestPrice = price
+	w.priceMu.Unlock()
+
+	if callback != nil {
+		callback(price)
+	}
+}
+
+// handleBBO 处理盘口最优价推送：bbo 为 [买一, 卖一]，一侧无挂单时为 null
+func (w *WebSocketManager) handleBBO(data json.RawMessage) {
+	var bbo struct {
+		Coin string `json:"coin"`
+		Time int64  `json:"time"`
+		BBO  []*struct {
+			Px string `json:"px"`
+			Sz string `json:"sz"`
+		} `json:"bbo"`
+	}
+	if err := json.Unmarshal(data, &bbo); err != nil || len(bbo.BBO) < 2 || bbo.BBO[0] == nil || bbo.BBO[1] == nil {
+		return
+	}
+
+	w.mu.RLock()
+	coin := w.coin
+	callback := w.bookCallback
+	w.mu.RUnlock()
+	if callback == nil || bbo.Coin != coin {
+		return
+	}
+
+	ticker := &orderbook.Ticker{Symbol: bbo.Coin, Time: bbo.Time}
+	ticker.BidPrice, _ = strconv.ParseFloat(bbo.BBO[0].Px, 64)
+	ticker.BidQty, _ = strconv.ParseFloat(bbo.BBO[0].Sz, 64)
+	ticker.AskPrice, _ = strconv.ParseFloat(bbo.BBO[1].Px, 64)
+	ticker.AskQty, _ = strconv.ParseFloat(bbo.BBO[1].Sz, 64)
+	if ticker.BidPrice <= 0 || ticker.AskPrice <= 0 {
+		return
+	}
+	callback(ticker)
+}
+
+// handlePrivateMessage 处理订单连接消息
+func (w *WebSocketManager) handlePrivateMessage(message []byte) {
+	var msg wsMessage
+	if err := json.Unmarshal(message, &msg); err != nil {
+		logger.Debug("解析 Hyperliquid 订单消息失败: %v", err)
+		return
+	}
+
+	switch msg.Channel {
+	case "orderUpdates":
+		w.handleOrderUpdates(msg.Data)
+	case "userFills":
+		w.handleUserFills(msg.Data)
+	case "error":
+		logger.Warn("⚠️ [Hyperliquid WS订单] 错误: %s", string(msg.Data))
+	}
+}
+
+// handleOrderUpdates 处理订单状态推送
+func (w *WebSocketManager) handleOrderUpdates(data json.RawMessage) {
+	var items []struct {
+		Order           hlOrder `json:"order"`
+		Status          string  `json:"status"`
+		StatusTimestamp int64   `json:"statusTimestamp"`
+	}
+	if err := json.Unmarshal(data, &items); err != nil {
+		logger.Warn("⚠️ [Hyperliquid] 解析订单推送失败: %v", err)
+		return
+	}
+
+	for i := range items {
+		item := &items[i]
+		order := w.adapter.toOrder(&item.Order, item.Status, item.StatusTimestamp)
+
+		w.fillMu.Lock()
+		if agg, ok := w.fills[order.OrderID]; ok && agg.qty > 0 {
+			order.AvgPrice = agg.value / agg.qty
+		}
+		if order.Status == OrderStatusNew || order.Status == OrderStatusPartiallyFilled {
+			w.openOrders[order.OrderID] = &item.Order
+		} else {
+			// 终态：清理跟踪数据
+			delete(w.openOrders, order.OrderID)
+			delete(w.fills, order.OrderID)
+		}
+		w.fillMu.Unlock()
+
+		w.emit(order)
+	}
+}
+
+// handleUserFills 处理逐笔成交推送
+// 仅对仍在挂单中的订单推送部分成交，终态由 orderUpdates 推送，避免重复的 FILLED
+func (w *WebSocketManager) handleUserFills(data json.RawMessage) {
+	var payload struct {
+		IsSnapshot bool `json:"isSnapshot"`
+		Fills      []struct {
+			Coin string `json:"coin"`
+			Px   string `json:"px"`
+			Sz   string `json:"sz"`
+			Oid  int64  `json:"oid"`
+			Time int64  `json:"time"`
+		} `json:"fills"`
+	}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		logger.Warn("⚠️ [Hyperliquid] 解析成交推送失败: %v", err)
+		return
+	}
+	// 订阅时推送的历史成交快照不再处理
+	if payload.IsSnapshot {
+		return
+	}
+
+	for _, fill := range payload.Fills {
+		price, _ := strconv.ParseFloat(fill.Px, 64)
+		qty, _ := strconv.ParseFloat(fill.Sz, 64)
+		if qty <= 0 {
+			continue
+		}
+
+		w.fillMu.Lock()
+		agg, ok := w.fills[fill.Oid]
+		if !ok {
+			agg = &fillAggregate{}
+			w.fills[fill.Oid] = agg
+		}
+		agg.qty += qty
+		agg.value += price * qty
+		tracked, isOpen := w.openOrders[fill.Oid]
+		filledQty, avgPrice := agg.qty, agg.value/agg.qty
+		w.fillMu.Unlock()
+
+		if !isOpen {
+			continue
+		}
+
+		order := w.adapter.toOrder(tracked, "open", fill.Time)
+		if filledQty >= order.Quantity {
+			continue // 完全成交，等待 orderUpdates 的 filled 状态
+		}
+		order.ExecutedQty = roundDown(filledQty, w.adapter.szDecimals)
+		order.AvgPrice = avgPrice
+		order.Status = OrderStatusPartiallyFilled
+		w.emit(order)
+	}
+}
+
+// emit 推送订单更新
+func (w *WebSocketManager) emit(order *Order) {
+	w.mu.RLock()
+	callback := w.orderCallback
+	w.mu.RUnlock()
+	if callback == nil {
+		return
+	}
+
+	update := OrderUpdate{
+		OrderID:       order.OrderID,
+		ClientOrderID: order.ClientOrderID,
+		Symbol:        order.Symbol,
+		Side:          order.Side,
+		Type:          order.Type,
+		Status:        order.Status,
+		Price:         order.Price,
+		Quantity:      order.Quantity,
+		ExecutedQty:   order.ExecutedQty,
+		AvgPrice:      order.AvgPrice,
+		UpdateTime:    order.UpdateTime,
+	}
+
+	logger.Debug("🔍 [Hyperliquid] 订单推送: ID=%d, ClientOID=%s, Status=%s, 成交=%.6f",
+		update.OrderID, update.ClientOrderID, update.Status, update.ExecutedQty)
+	callback(update)
+}
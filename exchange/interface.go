@@ -1,12 +1,18 @@
 package exchange
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // IExchange 交易所接口（所有交易所必须实现）
 type IExchange interface {
 	// GetName 获取交易所名称
 	GetName() string
 
+	// Capabilities 获取交易所能力描述（批量大小、一键全撤、改单、PostOnly 方式等）
+	Capabilities() Capabilities
+
 	// === 订单相关 ===
 
 	// PlaceOrder 下单
@@ -22,6 +28,12 @@ type IExchange interface {
 	// BatchCancelOrders 批量取消订单
 	BatchCancelOrders(ctx context.Context, symbol string, orderIDs []int64) error
 
+	// AmendOrder 改单（修改挂单的价格/数量）
+	// req 为原订单的下单请求，newQty 为改单后的订单总数量，<= 0 表示保持原数量；
+	// 不支持原生改单的交易所以撤单重下实现（订单ID会变化，沿用 req 的全部属性），以返回的订单为准，
+	// 重新下单失败时返回 ErrAmendReplaceFailed
+	AmendOrder(ctx context.Context, req *OrderRequest, orderID int64, newPrice, newQty float64) (*Order, error)
+
 	// CancelAllOrders 取消所有订单（退出时使用）
 	// 各交易所根据自己的能力实现：
 	// - Bitget: 使用一键全撤API
@@ -34,6 +46,10 @@ type IExchange interface {
 	// GetOpenOrders 查询未完成订单
 	GetOpenOrders(ctx context.Context, symbol string) ([]*Order, error)
 
+	// GetMyTrades 获取 since 之后本账户在该交易对的成交记录（按时间升序），不支持时返回错误
+	// limit 为最多返回条数，<= 0 或超过交易所上限时使用交易所上限
+	GetMyTrades(ctx context.Context, symbol string, since time.Time, limit int) ([]*Trade, error)
+
 	// === 账户与持仓 ===
 
 	// GetAccount 获取账户信息
@@ -45,6 +61,17 @@ type IExchange interface {
 	// GetBalance 获取余额
 	GetBalance(ctx context.Context, asset string) (float64, error)
 
+	// === 账户设置 ===
+
+	// SetLeverage 设置交易对杠杆倍数
+	SetLeverage(ctx context.Context, symbol string, leverage int) error
+
+	// SetMarginType 设置交易对保证金模式（全仓/逐仓），已是目标模式时视为成功
+	SetMarginType(ctx context.Context, symbol string, marginType MarginType) error
+
+	// SetPositionMode 设置持仓模式（单向/双向），交易所在有持仓或挂单时可能拒绝
+	SetPositionMode(ctx context.Context, mode PositionMode) error
+
 	// === WebSocket ===
 
 	// StartOrderStream 启动订单流（WebSocket）
@@ -52,9 +79,17 @@ type IExchange interface {
 	// 实际传递的是 OrderUpdate 类型
 	StartOrderStream(ctx context.Context, callback func(interface{})) error
 
-	// StopOrderStream 停止订单流
+	// SubscribeOrderStream 按交易对订阅订单流（只推送该交易对的订单更新）
+	// 同一账户的所有订阅共用一条订单流连接，可在一个 API Key 上运行多个交易对的网格
+	SubscribeOrderStream(ctx context.Context, symbol string, callback func(interface{})) error
+
+	// StopOrderStream 停止订单流（同时清空所有订阅）
+	// NewExchange 返回的同账户实例只停止自己的订阅，最后一个订阅者停止时才断开共用的连接
 	StopOrderStream() error
 
+	// StartAccountStream 启动账户推送（余额与持仓变化），不支持时返回错误
+	StartAccountStream(ctx context.Context, callback AccountUpdateCallback) error
+
 	// === 市场数据（如果需要） ===
 
 	// GetLatestPrice 获取最新价格
@@ -63,6 +98,29 @@ type IExchange interface {
 	// StartPriceStream 启动价格流（WebSocket）
 	StartPriceStream(ctx context.Context, symbol string, callback func(price float64)) error
 
+	// GetMarkPrice 获取标记价格
+	GetMarkPrice(ctx context.Context, symbol string) (float64, error)
+
+	// StartMarkPriceStream 启动标记价格流（推送同时携带资金费率）
+	StartMarkPriceStream(ctx context.Context, symbol string, callback MarkPriceCallback) error
+
+	// StartBookTickerStream 启动盘口最优价推送（买一/卖一），不支持时返回错误
+	StartBookTickerStream(ctx context.Context, symbol string, callback BookTickerCallback) error
+
+	// GetOrderBook 获取订单簿快照（REST），depth 为每侧档数
+	GetOrderBook(ctx context.Context, symbol string, depth int) (*OrderBook, error)
+
+	// StartDepthStream 启动深度推送，本地维护订单簿并在每次更新后回调，不支持时返回错误
+	StartDepthStream(ctx context.Context, symbol string, callback OrderBookCallback) error
+
+	// === 资金费 ===
+
+	// GetFundingRate 获取当期资金费率
+	GetFundingRate(ctx context.Context, symbol string) (*FundingRate, error)
+
+	// GetFundingPayments 获取 since 之后的资金费收付记录
+	GetFundingPayments(ctx context.Context, symbol string, since time.Time) ([]*FundingPayment, error)
+
 	// StartKlineStream 启动K线流（WebSocket）
 	// symbols: 交易对列表，interval: K线周期（如 "1m"），callback: K线更新回调
 	StartKlineStream(ctx context.Context, symbols []string, interval string, callback CandleUpdateCallback) error
@@ -81,8 +139,15 @@ type IExchange interface {
 	// GetHistoricalKlines 获取历史K线数据
 	GetHistoricalKlines(ctx context.Context, symbol string, interval string, limit int) ([]*Candle, error)
 
+	// GetHistoricalKlinesRange 获取开盘时间在 [start, end] 内的K线（按时间升序）
+	// 按交易所单次请求上限（Capabilities().KlinesPerRequest）分页查询，窗口边界重复的K线已去重
+	GetHistoricalKlinesRange(ctx context.Context, symbol string, interval string, start, end time.Time) ([]*Candle, error)
+
 	// === 合约信息 ===
 
+	// GetSymbolInfo 获取合约交易规则（价格/数量步长、最小下单量、最小名义价值、合约乘数、最大杠杆）
+	GetSymbolInfo(ctx context.Context, symbol string) (*SymbolInfo, error)
+
 	// GetPriceDecimals 获取价格精度（小数位数）
 	GetPriceDecimals() int
 
@@ -95,5 +160,15 @@ type IExchange interface {
 
 	// GetQuoteAsset 获取计价资产（结算币种）
 	// 例如: BTCUSDT -> USDT, ETHUSDT -> USDT, BTCUSD_PERP -> USD
+	// 币本位合约（Capabilities().Inverse）的保证金与盈亏以基础资产结算
 	GetQuoteAsset() string
+
+	// === 时间同步 ===
+
+	// GetClockSkew 获取本地时钟相对交易所服务器的偏差（服务器时间 - 本地时间）
+	// 签名时间戳已按该偏差校正，偏差过大说明系统时钟需要同步
+	GetClockSkew() time.Duration
+
+	// SyncServerTime 立即与交易所服务器对时（适配器创建后也会定期自动对时）
+	SyncServerTime(ctx context.Context) error
 }
@@ -0,0 +1,94 @@
+package exchange
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// klineIntervalDuration 解析K线周期（1m/3m/5m/15m/30m/1h/2h/4h/6h/8h/12h/1d/3d/1w/1M）
+// 1M 按 28 天计：按时长切分的窗口只会少于、不会多于单次请求上限
+func klineIntervalDuration(interval string) (time.Duration, error) {
+	if len(interval) < 2 {
+		return 0, fmt.Errorf("无效的K线周期: %s", interval)
+	}
+	n, err := strconv.Atoi(interval[:len(interval)-1])
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("无效的K线周期: %s", interval)
+	}
+	var unit time.Duration
+	switch interval[len(interval)-1] {
+	case 'm':
+		unit = time.Minute
+	case 'h', 'H':
+		unit = time.Hour
+	case 'd', 'D':
+		unit = 24 * time.Hour
+	case 'w', 'W':
+		unit = 7 * 24 * time.Hour
+	case 'M':
+		unit = 28 * 24 * time.Hour
+	default:
+		return 0, fmt.Errorf("无效的K线周期: %s", interval)
+	}
+	return time.Duration(n) * unit, nil
+}
+
+// klinePageFetcher 查询开盘时间在 [from, to] 内的K线（按时间升序）
+type klinePageFetcher func(ctx context.Context, from, to time.Time) ([]*Candle, error)
+
+// klinePager 可按单页查询区间K线的交易所（各交易所 wrapper 实现）
+// 受额度管理的实例用它在自己的分页循环中逐页等待额度，避免在 wrapper 的分页外再套一层分页
+type klinePager interface {
+	getKlinesPage(ctx context.Context, symbol string, interval string, from, to time.Time) ([]*Candle, error)
+}
+
+// klinesRange 按每页 pageSize 根K线分页查询 [start, end] 内的K线
+func klinesRange(ctx context.Context, pager klinePager, symbol string, interval string, start, end time.Time, pageSize int) ([]*Candle, error) {
+	return paginateKlines(ctx, interval, start, end, pageSize, func(ctx context.Context, from, to time.Time) ([]*Candle, error) {
+		return pager.getKlinesPage(ctx, symbol, interval, from, to)
+	})
+}
+
+// paginateKlines 将 [start, end] 按每页 pageSize 根K线切分为时间窗口依次查询并拼接，
+// 窗口边界重复返回的K线按开盘时间去重，区间外的K线丢弃；end 为零值时查询到当前时间
+func paginateKlines(ctx context.Context, interval string, start, end time.Time, pageSize int, fetch klinePageFetcher) ([]*Candle, error) {
+	if pageSize <= 0 {
+		return nil, fmt.Errorf("不支持按时间区间查询K线")
+	}
+	step, err := klineIntervalDuration(interval)
+	if err != nil {
+		return nil, err
+	}
+	if end.IsZero() {
+		end = time.Now()
+	}
+	if start.After(end) {
+		return nil, fmt.Errorf("K线起始时间 %s 晚于结束时间 %s", start.Format(time.RFC3339), end.Format(time.RFC3339))
+	}
+
+	startMs, endMs := start.UnixMilli(), end.UnixMilli()
+	window := step * time.Duration(pageSize)
+	candles := make([]*Candle, 0, int(end.Sub(start)/step)+1)
+	lastTimestamp := int64(-1)
+	for from := start; !from.After(end); {
+		to := from.Add(window - time.Millisecond)
+		if to.After(end) {
+			to = end
+		}
+		page, err := fetch(ctx, from, to)
+		if err != nil {
+			return nil, fmt.Errorf("获取 %s ~ %s 的K线失败: %w", from.Format(time.RFC3339), to.Format(time.RFC3339), err)
+		}
+		for _, c := range page {
+			if c.Timestamp <= lastTimestamp || c.Timestamp < startMs || c.Timestamp > endMs {
+				continue
+			}
+			candles = append(candles, c)
+			lastTimestamp = c.Timestamp
+		}
+		from = to.Add(time.Millisecond)
+	}
+	return candles, nil
+}
@@ -0,0 +1,58 @@
+package exchange
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPaginateKlines(t *testing.T) {
+	start := time.UnixMilli(0).Add(30 * time.Second) // 起点不对齐周期
+	end := time.UnixMilli(0).Add(10 * time.Minute)
+
+	var windows [][2]int64
+	fetch := func(ctx context.Context, from, to time.Time) ([]*Candle, error) {
+		windows = append(windows, [2]int64{from.UnixMilli(), to.UnixMilli()})
+		// 交易所按整分钟返回，且包含窗口前一根（边界重复）
+		var candles []*Candle
+		for ts := from.Truncate(time.Minute).Add(-time.Minute); !ts.After(to); ts = ts.Add(time.Minute) {
+			candles = append(candles, &Candle{Timestamp: ts.UnixMilli()})
+		}
+		return candles, nil
+	}
+
+	candles, err := paginateKlines(context.Background(), "1m", start, end, 4, fetch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 每个窗口 4 分钟：[0:30, 4:30)、[4:30, 8:30)、[8:30, 10:00]
+	if len(windows) != 3 {
+		t.Fatalf("窗口数 = %d, want 3: %v", len(windows), windows)
+	}
+	if windows[1][0] != windows[0][1]+1 || windows[2][1] != end.UnixMilli() {
+		t.Errorf("窗口应首尾相接并截止到 end: %v", windows)
+	}
+	// 开盘时间 1m ~ 10m 共 10 根，0m 早于 start 被丢弃，边界重复的K线去重
+	if len(candles) != 10 {
+		t.Fatalf("K线数 = %d, want 10", len(candles))
+	}
+	for i, c := range candles {
+		if want := int64(i+1) * 60000; c.Timestamp != want {
+			t.Fatalf("第 %d 根开盘时间 = %d, want %d", i, c.Timestamp, want)
+		}
+	}
+}
+
+func TestPaginateKlinesInvalid(t *testing.T) {
+	fetch := func(ctx context.Context, from, to time.Time) ([]*Candle, error) { return nil, nil }
+	now := time.Now()
+	if _, err := paginateKlines(context.Background(), "1m", now, now.Add(-time.Hour), 100, fetch); err == nil {
+		t.Error("起始时间晚于结束时间应返回错误")
+	}
+	if _, err := paginateKlines(context.Background(), "1m", now.Add(-time.Hour), now, 0, fetch); err == nil {
+		t.Error("不支持区间查询时应返回错误")
+	}
+	if _, err := paginateKlines(context.Background(), "7x", now.Add(-time.Hour), now, 100, fetch); err == nil {
+		t.Error("无效周期应返回错误")
+	}
+}
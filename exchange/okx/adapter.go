@@ -0,0 +1,1248 @@
+package okx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"opensqt/exchange/clock"
+	"opensqt/exchange/errs"
+	"opensqt/exchange/orderbook"
+	"opensqt/exchange/venue"
+	"opensqt/logger"
+	"opensqt/utils"
+)
+
+// 订单、持仓与行情类型与其他交易所共用（定义在 venue 包中，避免与 exchange 包循环导入）
+type (
+	Side                = venue.Side
+	OrderType           = venue.OrderType
+	OrderStatus         = venue.OrderStatus
+	TimeInForce         = venue.TimeInForce
+	OrderRequest        = venue.OrderRequest
+	Order               = venue.Order
+	Position            = venue.Position
+	Account             = venue.Account
+	SymbolInfo          = venue.SymbolInfo
+	OrderUpdate         = venue.OrderUpdate
+	OrderUpdateCallback = venue.OrderUpdateCallback
+	Candle              = venue.Candle
+)
+
+const (
+	SideBuy  = venue.SideBuy
+	SideSell = venue.SideSell
+
+	OrderTypeLimit  = venue.OrderTypeLimit
+	OrderTypeMarket = venue.OrderTypeMarket
+
+	OrderStatusNew             = venue.OrderStatusNew
+	OrderStatusPartiallyFilled = venue.OrderStatusPartiallyFilled
+	OrderStatusFilled          = venue.OrderStatusFilled
+	OrderStatusCanceled        = venue.OrderStatusCanceled
+	OrderStatusRejected        = venue.OrderStatusRejected
+	OrderStatusExpired         = venue.OrderStatusExpired
+
+	TimeInForceGTC = venue.TimeInForceGTC
+)
+
+// OKX 业务错误码（sCode）
+const (
+	sCodeInsufficientBalance = "51008" // 保证金/余额不足
+	sCodeCancelFailed        = "51400" // 订单已成交、已撤销或不存在
+	sCodeAlreadyCanceled     = "51401" // 订单已撤销
+	sCodeAlreadyCompleted    = "51402" // 订单已完成
+	sCodeOrderNotExist       = "51603" // 订单不存在
+
+	codeTimestampExpired = "50102" // 请求时间戳过期（本地时钟与服务器不同步）
+)
+
+// OKXAdapter OKX 永续合约（SWAP）适配器
+// OKX 以"张"为下单单位，每张合约对应 ctVal 个基础币，适配器对外统一使用基础币数量
+type OKXAdapter struct {
+	client         *Client
+	wsManager      *WebSocketManager
+	klineWSManager *KlineWebSocketManager
+	symbol         string           // 标准交易对，如 ETHUSDT
+	instID         string           // OKX 合约ID，如 ETH-USDT-SWAP
+	clock          *clock.Estimator // 服务器时间偏差（校正签名时间戳）
+	limiter        RequestLimiter   // 账户级请求额度（内部发起的 REST 请求使用），nil 表示不限制
+
+	posMode          string  // 持仓模式：long_short_mode（双向）或 net_mode（单向）
+	mgnMode          string  // 保证金模式：cross（全仓）或 isolated（逐仓），OKX 在下单时通过 tdMode 指定
+	ctVal            float64 // 合约面值（每张对应的基础币数量）
+	lotSz            float64 // 下单数量步长（张）
+	minSz            float64 // 最小下单数量（张）
+	tickSz           float64 // 价格步长
+	maxLeverage      float64 // 最大杠杆
+	priceDecimals    int     // 价格小数位
+	quantityDecimals int     // 基础币数量小数位（由 lotSz*ctVal 推导）
+	baseAsset        string  // 基础资产（交易币种），如 ETH
+	quoteAsset       string  // 计价资产（结算币种），如 USDT
+}
+
+// NewOKXAdapter 创建 OKX 适配器
+// cfg 键 environment 选择 mainnet/demo（OKX 没有独立测试网，模拟盘需使用模拟盘 API Key）；
+// 可选键 base_url / ws_public_url / ws_private_url / ws_business_url 用于指向自定义网关，优先于 environment
+func NewOKXAdapter(cfg map[string]string, symbol string) (*OKXAdapter, error) {
+	apiKey := cfg["api_key"]
+	secretKey := cfg["secret_key"]
+	passphrase := cfg["passphrase"]
+
+	if apiKey == "" || secretKey == "" || passphrase == "" {
+		return nil, fmt.Errorf("okx API 配置不完整")
+	}
+
+	client := NewClient(apiKey, secretKey, passphrase)
+	wsManager := NewWebSocketManager(client.signer)
+	businessURL := OKXWSBusiness
+
+	switch env := cfg["environment"]; env {
+	case "", "mainnet":
+	case "demo":
+		client.simulated = true
+		wsManager.publicURL = OKXWSPublicDemo
+		wsManager.privateURL = OKXWSPrivateDemo
+		businessURL = OKXWSBusinessDemo
+		logger.Info("ℹ️ [OKX] 使用模拟盘（x-simulated-trading）")
+	default:
+		return nil, fmt.Errorf("okx 不支持 environment=%s（可选 mainnet/demo）", env)
+	}
+
+	if baseURL := cfg["base_url"]; baseURL != "" {
+		client.baseURL = strings.TrimRight(baseURL, "/")
+	}
+	if u := cfg["ws_public_url"]; u != "" {
+		wsManager.publicURL = u
+	}
+	if u := cfg["ws_private_url"]; u != "" {
+		wsManager.privateURL = u
+	}
+
+	instID := convertToOKXInstID(symbol)
+	adapter := &OKXAdapter{
+		client:    client,
+		wsManager: wsManager,
+		symbol:    strings.ToUpper(symbol),
+		instID:    instID,
+		mgnMode:   "cross",
+	}
+	wsManager.adapter = adapter
+	adapter.startClock()
+
+	if u := cfg["ws_business_url"]; u != "" {
+		businessURL = u
+	}
+	adapter.klineWSManager = NewKlineWebSocketManager(businessURL)
+
+	// 初始化获取合约信息和持仓模式
+	ctxInit, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// 1. 获取合约信息（合约面值、步长）
+	if err := adapter.fetchInstrumentInfo(ctxInit); err != nil {
+		logger.Warn("⚠️ [OKX] 获取合约信息失败: %v", err)
+		// 使用默认值：1张=1个基础币
+		adapter.ctVal = 1
+		adapter.lotSz = 1
+		adapter.minSz = 1
+		adapter.tickSz = 0.01
+		adapter.priceDecimals = 2
+		adapter.quantityDecimals = 0
+		adapter.quoteAsset = "USDT"
+	}
+
+	// 2. 获取持仓模式
+	if err := adapter.fetchAccountConfig(ctxInit); err != nil {
+		logger.Warn("⚠️ [OKX] 获取账户配置失败: %v", err)
+		adapter.posMode = "net_mode" // 默认单向持仓
+	}
+
+	return adapter, nil
+}
+
+// GetName 获取交易所名称
+func (o *OKXAdapter) GetName() string {
+	return "OKX"
+}
+
+// startClock 创建对时器并注入签名器（REST 与 WebSocket 共用），立即对时一次后定期对时
+func (o *OKXAdapter) startClock() {
+	o.clock = clock.NewEstimator("OKX", o.client.ServerTime)
+	o.client.signer.clock = o.clock
+	o.clock.Start(context.Background(), clock.DefaultSyncInterval)
+}
+
+// ClockSkew 本地时钟相对服务器的偏差（服务器时间 - 本地时间）
+func (o *OKXAdapter) ClockSkew() time.Duration {
+	return o.clock.Offset()
+}
+
+// SyncServerTime 立即与服务器对时
+func (o *OKXAdapter) SyncServerTime(ctx context.Context) error {
+	return o.clock.Sync(ctx)
+}
+
+// okxInstrument 合约信息（/api/v5/public/instruments）
+type okxInstrument struct {
+	InstID   string `json:"instId"`
+	CtVal    string `json:"ctVal"`    // 合约面值
+	CtValCcy string `json:"ctValCcy"` // 面值计价币种
+	CtType   string `json:"ctType"`   // linear / inverse
+	SettleCy string `json:"settleCcy"`
+	TickSz   string `json:"tickSz"`
+	LotSz    string `json:"lotSz"`
+	MinSz    string `json:"minSz"`
+	Lever    string `json:"lever"`
+}
+
+// queryInstrument 查询永续合约信息
+func (o *OKXAdapter) queryInstrument(ctx context.Context, instID string) (*okxInstrument, error) {
+	path := fmt.Sprintf("/api/v5/public/instruments?instType=SWAP&instId=%s", instID)
+	resp, err := o.client.DoRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var dataList []okxInstrument
+	if err := json.Unmarshal(resp.Data, &dataList); err != nil {
+		return nil, fmt.Errorf("解析合约信息失败: %w", err)
+	}
+	if len(dataList) == 0 {
+		return nil, fmt.Errorf("未找到合约信息: %s", instID)
+	}
+	return &dataList[0], nil
+}
+
+// fetchInstrumentInfo 获取合约信息（合约面值、步长等）
+func (o *OKXAdapter) fetchInstrumentInfo(ctx context.Context) error {
+	info, err := o.queryInstrument(ctx, o.instID)
+	if err != nil {
+		return err
+	}
+
+	if info.CtType == "inverse" {
+		logger.Warn("⚠️ [OKX] %s 为币本位合约，数量换算按 ctVal=%s %s 处理", o.instID, info.CtVal, info.CtValCcy)
+	}
+
+	o.ctVal, _ = strconv.ParseFloat(info.CtVal, 64)
+	o.lotSz, _ = strconv.ParseFloat(info.LotSz, 64)
+	o.minSz, _ = strconv.ParseFloat(info.MinSz, 64)
+	o.tickSz, _ = strconv.ParseFloat(info.TickSz, 64)
+	o.maxLeverage, _ = strconv.ParseFloat(info.Lever, 64)
+	if o.ctVal <= 0 {
+		o.ctVal = 1
+	}
+	o.priceDecimals = countDecimalPlaces(info.TickSz)
+	o.quantityDecimals = countDecimalPlaces(multiplyDecimal(info.LotSz, info.CtVal))
+
+	parts := strings.Split(o.instID, "-")
+	if len(parts) >= 2 {
+		o.baseAsset = parts[0]
+	}
+	o.quoteAsset = info.SettleCy
+
+	logger.Info("ℹ️ [OKX 合约信息] %s, 合约面值:%s %s, 张数步长:%s, 价格精度:%d, 数量精度:%d, 结算币种:%s",
+		o.instID, info.CtVal, info.CtValCcy, info.LotSz, o.priceDecimals, o.quantityDecimals, o.quoteAsset)
+
+	return nil
+}
+
+// GetSymbolInfo 获取合约交易规则（数量已按合约面值换算为币数量）
+func (o *OKXAdapter) GetSymbolInfo(ctx context.Context, symbol string) (*SymbolInfo, error) {
+	instID := convertToOKXInstID(symbol)
+	inst, err := o.queryInstrument(ctx, instID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctVal, _ := strconv.ParseFloat(inst.CtVal, 64)
+	if ctVal <= 0 {
+		ctVal = 1
+	}
+	minSz, _ := strconv.ParseFloat(inst.MinSz, 64)
+	tickSz, _ := strconv.ParseFloat(inst.TickSz, 64)
+	maxLeverage, _ := strconv.ParseFloat(inst.Lever, 64)
+
+	step := multiplyDecimal(inst.LotSz, inst.CtVal)
+	stepSize, _ := strconv.ParseFloat(step, 64)
+
+	info := &SymbolInfo{
+		Symbol:             symbol,
+		QuoteAsset:         inst.SettleCy,
+		TickSize:           tickSz,
+		StepSize:           stepSize,
+		MinQty:             minSz * ctVal,
+		ContractMultiplier: ctVal,
+		MaxLeverage:        int(maxLeverage),
+		PriceDecimals:      countDecimalPlaces(inst.TickSz),
+		QuantityDecimals:   countDecimalPlaces(step),
+	}
+	if parts := strings.Split(instID, "-"); len(parts) >= 2 {
+		info.BaseAsset = parts[0]
+	}
+	return info, nil
+}
+
+// fetchAccountConfig 获取账户配置（持仓模式）
+func (o *OKXAdapter) fetchAccountConfig(ctx context.Context) error {
+	resp, err := o.client.DoRequest(ctx, "GET", "/api/v5/account/config", nil)
+	if err != nil {
+		return err
+	}
+
+	var dataList []struct {
+		PosMode string `json:"posMode"`
+	}
+	if err := json.Unmarshal(resp.Data, &dataList); err != nil {
+		return fmt.Errorf("解析账户配置失败: %w", err)
+	}
+	if len(dataList) == 0 {
+		return fmt.Errorf("账户配置为空")
+	}
+
+	o.posMode = dataList[0].PosMode
+	posModeDesc := "双向持仓"
+	if o.posMode == "net_mode" {
+		posModeDesc = "单向持仓"
+	}
+	logger.Info("ℹ️ [OKX] 持仓模式: %s (%s)", posModeDesc, o.posMode)
+	return nil
+}
+
+// PlaceOrder 下单（使用 REST API）
+func (o *OKXAdapter) PlaceOrder(ctx context.Context, req *OrderRequest) (*Order, error) {
+	body, err := o.buildOrderBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := o.client.DoRequest(ctx, "POST", "/api/v5/trade/order", body)
+	if err != nil {
+		if isInsufficientMarginError(err) {
+			return nil, errs.Wrap(errs.ErrInsufficientMargin, "okx", "", err)
+		}
+		if isClockSkewError(err) {
+			return nil, errs.Wrap(errs.ErrClockSkew, "okx", codeTimestampExpired, err)
+		}
+		return nil, err
+	}
+
+	var dataList []okxOrderAck
+	if err := json.Unmarshal(resp.Data, &dataList); err != nil || len(dataList) == 0 {
+		return nil, fmt.Errorf("解析下单响应失败: %s", string(resp.Data))
+	}
+
+	return o.ackToOrder(req, &dataList[0])
+}
+
+// okxOrderAck 下单确认
+type okxOrderAck struct {
+	OrdID   string `json:"ordId"`
+	ClOrdID string `json:"clOrdId"`
+	SCode   string `json:"sCode"`
+	SMsg    string `json:"sMsg"`
+}
+
+// ackToOrder 根据下单确认构造订单
+func (o *OKXAdapter) ackToOrder(req *OrderRequest, ack *okxOrderAck) (*Order, error) {
+	orderID, _ := strconv.ParseInt(ack.OrdID, 10, 64)
+	if orderID == 0 {
+		return nil, fmt.Errorf("下单响应中ordId为空或无效: %s", ack.OrdID)
+	}
+
+	return &Order{
+		OrderID:       orderID,
+		ClientOrderID: utils.RemoveBrokerPrefix("okx", ack.ClOrdID),
+		Symbol:        req.Symbol,
+		Side:          req.Side,
+		Type:          req.Type,
+		Price:         req.Price,
+		Quantity:      req.Quantity,
+		Status:        OrderStatusNew,
+		CreatedAt:     time.Now(),
+	}, nil
+}
+
+// buildOrderBody 构造 OKX 下单参数
+func (o *OKXAdapter) buildOrderBody(req *OrderRequest) (map[string]interface{}, error) {
+	contracts := o.toContracts(req.Quantity)
+	if contracts < o.minSz || contracts <= 0 {
+		return nil, fmt.Errorf("下单数量 %.8f 换算后为 %s 张，小于最小下单张数 %s",
+			req.Quantity, o.formatContracts(contracts), o.formatContracts(o.minSz))
+	}
+
+	ordType := "limit"
+	if req.Type == OrderTypeMarket {
+		ordType = "market"
+	} else if req.PostOnly {
+		ordType = "post_only" // Post Only - 只做 Maker
+	}
+
+	body := map[string]interface{}{
+		"instId":  o.instID,
+		"tdMode":  o.mgnMode,
+		"side":    strings.ToLower(string(req.Side)),
+		"ordType": ordType,
+		"sz":      o.formatContracts(contracts),
+	}
+	if ordType != "market" {
+		priceDecimals := o.priceDecimals
+		if req.PriceDecimals > 0 {
+			priceDecimals = req.PriceDecimals
+		}
+		body["px"] = strconv.FormatFloat(alignToTickSize(req.Price, o.tickSz, priceDecimals), 'f', priceDecimals, 64)
+	}
+	if req.ClientOrderID != "" {
+		body["clOrdId"] = utils.AddBrokerPrefix("okx", req.ClientOrderID)
+	}
+
+	// 🔥 OKX 双向持仓：用 posSide 指定仓位方向，不能使用 reduceOnly
+	// 开多：buy + long，平多：sell + long，开空：sell + short，平空：buy + short
+	// 指定了持仓方向时直接使用，否则按买卖方向与是否只减仓推断
+	if o.posMode == "long_short_mode" {
+		if req.PositionSide != "" {
+			body["posSide"] = strings.ToLower(req.PositionSide)
+		} else if (req.Side == SideBuy) != req.ReduceOnly {
+			body["posSide"] = "long"
+		} else {
+			body["posSide"] = "short"
+		}
+	} else if req.ReduceOnly {
+		body["reduceOnly"] = true
+	}
+
+	return body, nil
+}
+
+// BatchPlaceOrders 批量下单（OKX 原生批量接口，每次最多20个）
+func (o *OKXAdapter) BatchPlaceOrders(ctx context.Context, orders []*OrderRequest) ([]*Order, bool) {
+	placedOrders := make([]*Order, 0, len(orders))
+	hasMarginError := false
+
+	batchSize := 20
+	for i := 0; i < len(orders); i += batchSize {
+		end := i + batchSize
+		if end > len(orders) {
+			end = len(orders)
+		}
+
+		// 构造批量请求，跳过本地校验失败的订单
+		var batchReqs []*OrderRequest
+		var bodies []map[string]interface{}
+		for _, req := range orders[i:end] {
+			body, err := o.buildOrderBody(req)
+			if err != nil {
+				logger.Warn("⚠️ [OKX] 下单失败 %.*f %s: %v", o.priceDecimals, req.Price, req.Side, err)
+				continue
+			}
+			batchReqs = append(batchReqs, req)
+			bodies = append(bodies, body)
+		}
+		if len(bodies) == 0 {
+			continue
+		}
+
+		resp, err := o.client.DoBatchRequest(ctx, "POST", "/api/v5/trade/batch-orders", bodies)
+		if err != nil {
+			logger.Warn("⚠️ [OKX] 批量下单请求失败 (共%d个): %v", len(bodies), err)
+			errs.Report(ctx, err)
+			continue
+		}
+
+		var acks []okxOrderAck
+		if err := json.Unmarshal(resp.Data, &acks); err != nil || len(acks) == 0 {
+			logger.Warn("⚠️ [OKX] 批量下单失败: code=%s, msg=%s", resp.Code, resp.Msg)
+			continue
+		}
+
+		// OKX 按请求顺序返回结果，逐条检查 sCode
+		for j, ack := range acks {
+			if j >= len(batchReqs) {
+				break
+			}
+			req := batchReqs[j]
+			if ack.SCode != "0" {
+				logger.Warn("⚠️ [OKX] 下单失败 %.*f %s: sCode=%s, sMsg=%s",
+					o.priceDecimals, req.Price, req.Side, ack.SCode, ack.SMsg)
+				if ack.SCode == sCodeInsufficientBalance {
+					hasMarginError = true
+				}
+				continue
+			}
+			order, err := o.ackToOrder(req, &ack)
+			if err != nil {
+				logger.Warn("⚠️ [OKX] %v", err)
+				continue
+			}
+			placedOrders = append(placedOrders, order)
+		}
+	}
+
+	return placedOrders, hasMarginError
+}
+
+// CancelOrder 取消订单
+func (o *OKXAdapter) CancelOrder(ctx context.Context, symbol string, orderID int64) error {
+	body := map[string]interface{}{
+		"instId": o.instID,
+		"ordId":  strconv.FormatInt(orderID, 10),
+	}
+
+	_, err := o.client.DoRequest(ctx, "POST", "/api/v5/trade/cancel-order", body)
+	if err != nil {
+		// 订单不存在不算错误
+		if isOrderNotFoundError(err) {
+			logger.Info("ℹ️ [OKX] 订单 %d 已不存在，跳过取消", orderID)
+			return nil
+		}
+		return fmt.Errorf("取消订单失败: %w", err)
+	}
+
+	logger.Info("✅ [OKX] 取消订单成功: %d", orderID)
+	return nil
+}
+
+// BatchCancelOrders 批量取消订单（OKX 原生批量撤单，每次最多20个）
+func (o *OKXAdapter) BatchCancelOrders(ctx context.Context, symbol string, orderIDs []int64) error {
+	if len(orderIDs) == 0 {
+		return nil
+	}
+
+	batchSize := 20
+	for i := 0; i < len(orderIDs); i += batchSize {
+		end := i + batchSize
+		if end > len(orderIDs) {
+			end = len(orderIDs)
+		}
+		batch := orderIDs[i:end]
+
+		bodies := make([]map[string]string, len(batch))
+		for j, id := range batch {
+			bodies[j] = map[string]string{
+				"instId": o.instID,
+				"ordId":  strconv.FormatInt(id, 10),
+			}
+		}
+
+		resp, err := o.client.DoBatchRequest(ctx, "POST", "/api/v5/trade/cancel-batch-orders", bodies)
+		if err != nil {
+			logger.Warn("⚠️ [OKX] 批量撤单失败 (共%d个): %v", len(batch), err)
+			continue
+		}
+
+		var acks []okxOrderAck
+		_ = json.Unmarshal(resp.Data, &acks)
+		failed := 0
+		for _, ack := range acks {
+			if ack.SCode != "0" && !isOrderGoneSCode(ack.SCode) {
+				failed++
+				logger.Warn("⚠️ [OKX] 撤单失败 %s: sCode=%s, sMsg=%s", ack.OrdID, ack.SCode, ack.SMsg)
+			}
+		}
+		logger.Info("✅ [OKX] 批量撤单完成: %d 个订单, 失败 %d 个", len(batch), failed)
+
+		// 避免限频
+		if i+batchSize < len(orderIDs) {
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+
+	return nil
+}
+
+// CancelAllOrders 撤销交易对的所有订单
+// OKX 的 mass-cancel 接口仅支持期权，永续合约使用"查询挂单 + 原生批量撤单"实现
+func (o *OKXAdapter) CancelAllOrders(ctx context.Context, symbol string) error {
+	orders, err := o.GetOpenOrders(ctx, symbol)
+	if err != nil {
+		return fmt.Errorf("查询未完成订单失败: %w", err)
+	}
+	if len(orders) == 0 {
+		logger.Info("✅ [OKX] 没有需要撤销的订单")
+		return nil
+	}
+
+	orderIDs := make([]int64, len(orders))
+	for i, ord := range orders {
+		orderIDs[i] = ord.OrderID
+	}
+
+	logger.Info("🔄 [OKX] 撤销 %d 个未完成订单", len(orderIDs))
+	return o.BatchCancelOrders(ctx, symbol, orderIDs)
+}
+
+// okxOrder OKX 订单结构（REST 与 WebSocket 共用）
+type okxOrder struct {
+	InstID    string `json:"instId"`
+	OrdID     string `json:"ordId"`
+	ClOrdID   string `json:"clOrdId"`
+	Side      string `json:"side"`
+	OrdType   string `json:"ordType"`
+	Px        string `json:"px"`
+	Sz        string `json:"sz"`
+	AccFillSz string `json:"accFillSz"`
+	AvgPx     string `json:"avgPx"`
+	State     string `json:"state"`
+	CTime     string `json:"cTime"`
+	UTime     string `json:"uTime"`
+}
+
+// toOrder 转换为通用订单结构（张数换算为基础币数量）
+func (o *OKXAdapter) toOrder(item *okxOrder) *Order {
+	orderID, _ := strconv.ParseInt(item.OrdID, 10, 64)
+	price, _ := strconv.ParseFloat(item.Px, 64)
+	avgPrice, _ := strconv.ParseFloat(item.AvgPx, 64)
+	createdTime, _ := strconv.ParseInt(item.CTime, 10, 64)
+	updateTime, _ := strconv.ParseInt(item.UTime, 10, 64)
+
+	orderType := OrderTypeLimit
+	if item.OrdType == "market" {
+		orderType = OrderTypeMarket
+	}
+
+	return &Order{
+		OrderID:       orderID,
+		ClientOrderID: utils.RemoveBrokerPrefix("okx", item.ClOrdID),
+		Symbol:        convertFromOKXInstID(item.InstID),
+		Side:          convertSide(item.Side),
+		Type:          orderType,
+		Price:         price,
+		Quantity:      o.fromContracts(item.Sz),
+		ExecutedQty:   o.fromContracts(item.AccFillSz),
+		AvgPrice:      avgPrice,
+		Status:        convertStatus(item.State),
+		CreatedAt:     time.UnixMilli(createdTime),
+		UpdateTime:    updateTime,
+	}
+}
+
+// GetOrder 查询订单
+func (o *OKXAdapter) GetOrder(ctx context.Context, symbol string, orderID int64) (*Order, error) {
+	path := fmt.Sprintf("/api/v5/trade/order?instId=%s&ordId=%d", o.instID, orderID)
+	resp, err := o.client.DoRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var dataList []okxOrder
+	if err := json.Unmarshal(resp.Data, &dataList); err != nil {
+		return nil, fmt.Errorf("解析订单详情失败: %w", err)
+	}
+	if len(dataList) == 0 {
+		return nil, fmt.Errorf("订单不存在: %d", orderID)
+	}
+
+	return o.toOrder(&dataList[0]), nil
+}
+
+// GetOpenOrders 查询未完成订单（自动翻页，每页最多100条）
+func (o *OKXAdapter) GetOpenOrders(ctx context.Context, symbol string) ([]*Order, error) {
+	orders := make([]*Order, 0)
+	after := ""
+
+	for page := 0; page < 20; page++ {
+		path := fmt.Sprintf("/api/v5/trade/orders-pending?instType=SWAP&instId=%s&limit=100", o.instID)
+		if after != "" {
+			path += "&after=" + after
+		}
+
+		resp, err := o.client.DoRequest(ctx, "GET", path, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var dataList []okxOrder
+		if err := json.Unmarshal(resp.Data, &dataList); err != nil {
+			return nil, fmt.Errorf("解析订单列表失败: %w", err)
+		}
+
+		for i := range dataList {
+			orders = append(orders, o.toOrder(&dataList[i]))
+		}
+
+		if len(dataList) < 100 {
+			break
+		}
+		after = dataList[len(dataList)-1].OrdID
+	}
+
+	return orders, nil
+}
+
+// GetAccount 获取账户信息
+func (o *OKXAdapter) GetAccount(ctx context.Context) (*Account, error) {
+	path := "/api/v5/account/balance?ccy=" + o.quoteAsset
+	resp, err := o.client.DoRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var dataList []struct {
+		TotalEq string `json:"totalEq"`
+		Details []struct {
+			Ccy      string `json:"ccy"`
+			Eq       string `json:"eq"`
+			CashBal  string `json:"cashBal"`
+			AvailEq  string `json:"availEq"`
+			AvailBal string `json:"availBal"`
+		} `json:"details"`
+	}
+	if err := json.Unmarshal(resp.Data, &dataList); err != nil {
+		return nil, fmt.Errorf("解析账户信息失败: %w", err)
+	}
+	if len(dataList) == 0 {
+		return nil, fmt.Errorf("账户信息为空")
+	}
+
+	account := &Account{PosMode: o.posMode}
+	for _, d := range dataList[0].Details {
+		if d.Ccy != o.quoteAsset {
+			continue
+		}
+		account.TotalWalletBalance, _ = strconv.ParseFloat(d.CashBal, 64)
+		account.TotalMarginBalance, _ = strconv.ParseFloat(d.Eq, 64)
+		// 单币种保证金模式下 availEq 为空，使用 availBal
+		availStr := d.AvailEq
+		if availStr == "" {
+			availStr = d.AvailBal
+		}
+		account.AvailableBalance, _ = strconv.ParseFloat(availStr, 64)
+	}
+
+	positions, err := o.GetPositions(ctx, o.symbol)
+	if err != nil {
+		logger.Warn("⚠️ [OKX] 获取持仓信息失败: %v", err)
+		positions = []*Position{}
+	}
+	account.Positions = positions
+
+	// 读取当前保证金模式下的杠杆设置
+	leveragePath := fmt.Sprintf("/api/v5/account/leverage-info?instId=%s&mgnMode=%s", o.instID, o.mgnMode)
+	if levResp, err := o.client.DoRequest(ctx, "GET", leveragePath, nil); err == nil {
+		var levList []struct {
+			Lever string `json:"lever"`
+		}
+		if json.Unmarshal(levResp.Data, &levList) == nil && len(levList) > 0 {
+			lever, _ := strconv.ParseFloat(levList[0].Lever, 64)
+			account.AccountLeverage = int(lever)
+		}
+	}
+
+	return account, nil
+}
+
+// GetPositions 获取持仓信息（张数换算为基础币数量，空仓为负数）
+func (o *OKXAdapter) GetPositions(ctx context.Context, symbol string) ([]*Position, error) {
+	path := fmt.Sprintf("/api/v5/account/positions?instType=SWAP&instId=%s", o.instID)
+	resp, err := o.client.DoRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var dataList []struct {
+		InstID  string `json:"instId"`
+		Pos     string `json:"pos"`     // 持仓张数（单向持仓时带符号）
+		PosSide string `json:"posSide"` // long / short / net
+		AvgPx   string `json:"avgPx"`
+		MarkPx  string `json:"markPx"`
+		Upl     string `json:"upl"`
+		Lever   string `json:"lever"`
+		MgnMode string `json:"mgnMode"`
+		Margin  string `json:"margin"`
+		Imr     string `json:"imr"`
+	}
+	if err := json.Unmarshal(resp.Data, &dataList); err != nil {
+		return nil, fmt.Errorf("解析持仓信息失败: %w", err)
+	}
+
+	positions := make([]*Position, 0, len(dataList))
+	for _, item := range dataList {
+		size := o.fromContracts(item.Pos)
+		if size == 0 {
+			continue // 跳过空持仓
+		}
+		if item.PosSide == "short" && size > 0 {
+			size = -size
+		}
+
+		entryPrice, _ := strconv.ParseFloat(item.AvgPx, 64)
+		markPrice, _ := strconv.ParseFloat(item.MarkPx, 64)
+		upl, _ := strconv.ParseFloat(item.Upl, 64)
+		lever, _ := strconv.ParseFloat(item.Lever, 64)
+		margin, _ := strconv.ParseFloat(item.Margin, 64)
+		if margin == 0 {
+			margin, _ = strconv.ParseFloat(item.Imr, 64)
+		}
+
+		positions = append(positions, &Position{
+			Symbol:         convertFromOKXInstID(item.InstID),
+			Size:           size,
+			EntryPrice:     entryPrice,
+			MarkPrice:      markPrice,
+			UnrealizedPNL:  upl,
+			Leverage:       int(lever),
+			MarginType:     item.MgnMode,
+			IsolatedMargin: margin,
+			Side:           hedgePositionSide(item.PosSide),
+		})
+	}
+
+	return positions, nil
+}
+
+// GetBalance 获取余额
+func (o *OKXAdapter) GetBalance(ctx context.Context, asset string) (float64, error) {
+	account, err := o.GetAccount(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return account.AvailableBalance, nil
+}
+
+// SetLeverage 设置合约在当前保证金模式下的杠杆倍数
+// 双向持仓的逐仓杠杆按多空方向分别设置，这里两个方向设为相同倍数
+func (o *OKXAdapter) SetLeverage(ctx context.Context, symbol string, leverage int) error {
+	posSides := []string{""}
+	if o.mgnMode == "isolated" && o.posMode == "long_short_mode" {
+		posSides = []string{"long", "short"}
+	}
+	for _, posSide := range posSides {
+		body := map[string]interface{}{
+			"instId":  o.instID,
+			"lever":   strconv.Itoa(leverage),
+			"mgnMode": o.mgnMode,
+		}
+		if posSide != "" {
+			body["posSide"] = posSide
+		}
+		if _, err := o.client.DoRequest(ctx, "POST", "/api/v5/account/set-leverage", body); err != nil {
+			return err
+		}
+	}
+	logger.Info("✅ [OKX] %s 杠杆倍数已设置为 %dx (%s)", o.instID, leverage, o.mgnMode)
+	return nil
+}
+
+// SetMarginType 设置保证金模式（逐仓/全仓）
+// OKX 没有按合约切换保证金模式的接口，下单时通过 tdMode 指定，这里只记录后续下单与杠杆设置使用的模式
+func (o *OKXAdapter) SetMarginType(ctx context.Context, symbol string, isolated bool) error {
+	o.mgnMode = "cross"
+	if isolated {
+		o.mgnMode = "isolated"
+	}
+	logger.Info("✅ [OKX] 保证金模式: %s", o.mgnMode)
+	return nil
+}
+
+// SetPositionMode 设置持仓模式（单向/双向，账户级别），有持仓或挂单时交易所会拒绝
+func (o *OKXAdapter) SetPositionMode(ctx context.Context, hedge bool) error {
+	posMode := "net_mode"
+	if hedge {
+		posMode = "long_short_mode"
+	}
+	body := map[string]interface{}{
+		"posMode": posMode,
+	}
+	if _, err := o.client.DoRequest(ctx, "POST", "/api/v5/account/set-position-mode", body); err != nil {
+		return err
+	}
+	o.posMode = posMode
+	logger.Info("✅ [OKX] 持仓模式: %s", posMode)
+	return nil
+}
+
+// StartOrderStream 启动订单流（WebSocket 私有频道 orders）
+func (o *OKXAdapter) StartOrderStream(ctx context.Context, callback func(interface{})) error {
+	logger.Debug("🔗 [OKX] 启动订单流 WebSocket（私有频道）")
+
+	wrappedCallback := func(update OrderUpdate) {
+		genericUpdate := struct {
+			OrderID       int64
+			ClientOrderID string
+			Symbol        string
+			Side          string
+			Type          string
+			Status        string
+			Price         float64
+			Quantity      float64
+			ExecutedQty   float64
+			AvgPrice      float64
+			UpdateTime    int64
+		}{
+			OrderID:       update.OrderID,
+			ClientOrderID: update.ClientOrderID,
+			Symbol:        update.Symbol,
+			Side:          string(update.Side),
+			Type:          string(update.Type),
+			Status:        string(update.Status),
+			Price:         update.Price,
+			Quantity:      update.Quantity,
+			ExecutedQty:   update.ExecutedQty,
+			AvgPrice:      update.AvgPrice,
+			UpdateTime:    update.UpdateTime,
+		}
+		callback(genericUpdate)
+	}
+
+	return o.wsManager.StartPrivate(ctx, o.instID, wrappedCallback)
+}
+
+// StopOrderStream 停止订单流
+func (o *OKXAdapter) StopOrderStream() error {
+	o.wsManager.Stop()
+	return nil
+}
+
+// GetLatestPrice 获取最新价格（优先 WebSocket 缓存，未就绪时使用 REST）
+func (o *OKXAdapter) GetLatestPrice(ctx context.Context, symbol string) (float64, error) {
+	if price := o.wsManager.GetLatestPrice(); price > 0 {
+		return price, nil
+	}
+
+	resp, err := o.client.DoRequest(ctx, "GET", "/api/v5/market/ticker?instId="+o.instID, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var dataList []struct {
+		Last string `json:"last"`
+	}
+	if err := json.Unmarshal(resp.Data, &dataList); err != nil {
+		return 0, fmt.Errorf("解析行情失败: %w", err)
+	}
+	if len(dataList) == 0 {
+		return 0, fmt.Errorf("未获取到 %s 的行情", o.instID)
+	}
+
+	return strconv.ParseFloat(dataList[0].Last, 64)
+}
+
+// StartPriceStream 启动价格流（WebSocket 公共频道 tickers）
+func (o *OKXAdapter) StartPriceStream(ctx context.Context, symbol string, callback func(price float64)) error {
+	return o.wsManager.StartPublic(ctx, o.instID, callback)
+}
+
+// StartBookTickerStream 启动盘口最优价推送（复用公共频道 tickers 的 bidPx/askPx，仅支持当前交易对）
+func (o *OKXAdapter) StartBookTickerStream(ctx context.Context, symbol string, callback func(ticker *orderbook.Ticker)) error {
+	return o.wsManager.StartBookTicker(ctx, o.instID, callback)
+}
+
+// StartKlineStream 启动K线流（WebSocket business 频道）
+func (o *OKXAdapter) StartKlineStream(ctx context.Context, symbols []string, interval string, callback func(candle interface{})) error {
+	return o.klineWSManager.Start(ctx, symbols, interval, callback)
+}
+
+// RegisterKlineCallback 注册K线回调函数（支持多个组件共享K线流）
+func (o *OKXAdapter) RegisterKlineCallback(componentName string, callback func(candle interface{})) error {
+	return o.klineWSManager.RegisterCallback(componentName, callback)
+}
+
+// StopKlineStream 停止K线流
+func (o *OKXAdapter) StopKlineStream() error {
+	o.klineWSManager.Stop()
+	return nil
+}
+
+// ForceReconnectKlineStream 强制重新连接K线流
+func (o *OKXAdapter) ForceReconnectKlineStream() error {
+	return o.klineWSManager.ForceReconnect()
+}
+
+// GetHistoricalKlines 获取历史K线数据
+// OKX 单次最多返回 300 根，超过时使用 after 参数向前翻页
+func (o *OKXAdapter) GetHistoricalKlines(ctx context.Context, symbol string, interval string, limit int) ([]*Candle, error) {
+	instID := convertToOKXInstID(symbol)
+	bar := convertToOKXInterval(interval)
+
+	candles := make([]*Candle, 0, limit)
+	after := ""
+	for len(candles) < limit {
+		pageLimit := limit - len(candles)
+		if pageLimit > 300 {
+			pageLimit = 300
+		}
+
+		path := fmt.Sprintf("/api/v5/market/candles?instId=%s&bar=%s&limit=%d", instID, bar, pageLimit)
+		if after != "" {
+			// 第一页的额度已由调用方占用，后续分页单独等待
+			if err := o.waitRequest(ctx, "GetHistoricalKlines", pageLimit); err != nil {
+				return nil, err
+			}
+			path += "&after=" + after
+		}
+
+		resp, err := o.client.DoRequest(ctx, "GET", path, nil)
+		if err != nil {
+			return nil, fmt.Errorf("获取历史K线失败: %w", err)
+		}
+
+		// OKX 返回格式: [[ts, o, h, l, c, vol(张), volCcy(币), volCcyQuote, confirm], ...]，最新的在前
+		var dataList [][]string
+		if err := json.Unmarshal(resp.Data, &dataList); err != nil {
+			return nil, fmt.Errorf("解析K线数据失败: %w", err)
+		}
+		if len(dataList) == 0 {
+			break
+		}
+
+		for _, item := range dataList {
+			if candle := parseOKXCandle(symbol, item); candle != nil {
+				candles = append(candles, candle)
+			}
+		}
+
+		if len(dataList) < pageLimit {
+			break
+		}
+		after = dataList[len(dataList)-1][0]
+	}
+
+	// OKX 返回的K线是倒序的（最新的在前），需要反转
+	for i, j := 0, len(candles)-1; i < j; i, j = i+1, j-1 {
+		candles[i], candles[j] = candles[j], candles[i]
+	}
+
+	return candles, nil
+}
+
+// GetKlinesBetween 获取开盘时间在 [start, end]（毫秒）内的K线，按时间升序
+// 使用 history-candles（可查询更早的数据，单次最多 100 根）：after 返回早于该时间的K线，before 返回晚于该时间的K线
+func (o *OKXAdapter) GetKlinesBetween(ctx context.Context, symbol string, interval string, start, end int64, limit int) ([]*Candle, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 100
+	}
+
+	path := fmt.Sprintf("/api/v5/market/history-candles?instId=%s&bar=%s&after=%d&before=%d&limit=%d",
+		convertToOKXInstID(symbol), convertToOKXInterval(interval), end+1, start-1, limit)
+	resp, err := o.client.DoRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("获取历史K线失败: %w", err)
+	}
+
+	var dataList [][]string
+	if err := json.Unmarshal(resp.Data, &dataList); err != nil {
+		return nil, fmt.Errorf("解析K线数据失败: %w", err)
+	}
+
+	// 倒序返回（最新的在前），逆序遍历转为升序
+	candles := make([]*Candle, 0, len(dataList))
+	for i := len(dataList) - 1; i >= 0; i-- {
+		if candle := parseOKXCandle(symbol, dataList[i]); candle != nil {
+			candles = append(candles, candle)
+		}
+	}
+	return candles, nil
+}
+
+// parseOKXCandle 解析 OKX K线数组
+func parseOKXCandle(symbol string, item []string) *Candle {
+	if len(item) < 7 {
+		return nil
+	}
+
+	timestamp, _ := strconv.ParseInt(item[0], 10, 64)
+	open, _ := strconv.ParseFloat(item[1], 64)
+	high, _ := strconv.ParseFloat(item[2], 64)
+	low, _ := strconv.ParseFloat(item[3], 64)
+	close, _ := strconv.ParseFloat(item[4], 64)
+	volume, _ := strconv.ParseFloat(item[6], 64) // 使用币数量而不是张数
+
+	isClosed := true
+	if len(item) >= 9 {
+		isClosed = item[8] == "1"
+	}
+
+	return &Candle{
+		Symbol:    symbol,
+		Open:      open,
+		High:      high,
+		Low:       low,
+		Close:     close,
+		Volume:    volume,
+		Timestamp: timestamp,
+		IsClosed:  isClosed,
+	}
+}
+
+// GetPriceDecimals 获取价格精度（小数位数）
+func (o *OKXAdapter) GetPriceDecimals() int {
+	return o.priceDecimals
+}
+
+// GetQuantityDecimals 获取数量精度（基础币小数位数）
+func (o *OKXAdapter) GetQuantityDecimals() int {
+	return o.quantityDecimals
+}
+
+// GetBaseAsset 获取基础资产（交易币种）
+func (o *OKXAdapter) GetBaseAsset() string {
+	return o.baseAsset
+}
+
+// GetQuoteAsset 获取计价资产（结算币种）
+func (o *OKXAdapter) GetQuoteAsset() string {
+	return o.quoteAsset
+}
+
+// toContracts 基础币数量 -> 张数（向下对齐到 lotSz）
+func (o *OKXAdapter) toContracts(quantity float64) float64 {
+	contracts := quantity / o.ctVal
+	if o.lotSz > 0 {
+		// 加上微小偏移，避免浮点误差导致少一个步长
+		contracts = math.Floor(contracts/o.lotSz+1e-9) * o.lotSz
+	}
+	return contracts
+}
+
+// fromContracts 张数字符串 -> 基础币数量
+func (o *OKXAdapter) fromContracts(sz string) float64 {
+	contracts, _ := strconv.ParseFloat(sz, 64)
+	if contracts == 0 {
+		return 0
+	}
+	multiplier := math.Pow(10, float64(o.quantityDecimals))
+	return math.Round(contracts*o.ctVal*multiplier) / multiplier
+}
+
+// formatContracts 按 lotSz 精度格式化张数
+func (o *OKXAdapter) formatContracts(contracts float64) string {
+	decimals := countDecimalPlaces(strconv.FormatFloat(o.lotSz, 'f', -1, 64))
+	return strconv.FormatFloat(contracts, 'f', decimals, 64)
+}
+
+// isInsufficientMarginError 判断是否为保证金不足错误
+func isInsufficientMarginError(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.SCode == sCodeInsufficientBalance {
+		return true
+	}
+	return strings.Contains(strings.ToLower(err.Error()), "insufficient")
+}
+
+// isClockSkewError 判断是否为时间戳不同步错误
+func isClockSkewError(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Code == codeTimestampExpired
+}
+
+// isOrderNotFoundError 判断是否为订单不存在错误
+func isOrderNotFoundError(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return isOrderGoneSCode(apiErr.SCode)
+	}
+	return false
+}
+
+// isOrderGoneSCode 订单已成交、已撤销或不存在
+func isOrderGoneSCode(sCode string) bool {
+	switch sCode {
+	case sCodeCancelFailed, sCodeAlreadyCanceled, sCodeAlreadyCompleted, sCodeOrderNotExist:
+		return true
+	}
+	return false
+}
+
+// hedgePositionSide 双向持仓模式下将 posSide 转换为 LONG/SHORT，单向持仓（net）返回空
+func hedgePositionSide(posSide string) string {
+	if posSide == "long" || posSide == "short" {
+		return strings.ToUpper(posSide)
+	}
+	return ""
+}
+
+// convertSide 转换订单方向
+func convertSide(side string) Side {
+	if side == "sell" {
+		return SideSell
+	}
+	return SideBuy
+}
+
+// convertStatus 转换订单状态
+func convertStatus(state string) OrderStatus {
+	switch state {
+	case "live":
+		return OrderStatusNew
+	case "partially_filled":
+		return OrderStatusPartiallyFilled
+	case "filled":
+		return OrderStatusFilled
+	case "canceled", "mmp_canceled":
+		return OrderStatusCanceled
+	default:
+		return OrderStatus(strings.ToUpper(state))
+	}
+}
+
+// convertToOKXInstID 将标准符号转换为 OKX 永续合约ID
+// ETHUSDT -> ETH-USDT-SWAP，BTCUSD -> BTC-USD-SWAP；已是 OKX 格式时原样返回
+func convertToOKXInstID(symbol string) string {
+	symbol = strings.ToUpper(symbol)
+	if strings.Contains(symbol, "-") {
+		return symbol
+	}
+	for _, quote := range []string{"USDT", "USDC", "USD"} {
+		if strings.HasSuffix(symbol, quote) && len(symbol) > len(quote) {
+			return strings.TrimSuffix(symbol, quote) + "-" + quote + "-SWAP"
+		}
+	}
+	return symbol + "-USDT-SWAP"
+}
+
+// convertFromOKXInstID 将 OKX 合约ID 转换为标准符号（ETH-USDT-SWAP -> ETHUSDT）
+func convertFromOKXInstID(instID string) string {
+	return strings.ReplaceAll(strings.TrimSuffix(instID, "-SWAP"), "-", "")
+}
+
+// convertToOKXInterval 将标准K线周期转换为 OKX 格式
+// 输入: 1m, 3m, 5m, 15m, 30m, 1h, 2h, 4h, 6h, 12h, 1d, 1w, 1M
+// 输出: 1m, 3m, 5m, 15m, 30m, 1H, 2H, 4H, 6H, 12H, 1D, 1W, 1M
+func convertToOKXInterval(interval string) string {
+	switch interval {
+	case "1h", "2h", "4h", "6h", "12h", "1d", "1w":
+		return strings.ToUpper(interval)
+	default:
+		return interval
+	}
+}
+
+// multiplyDecimal 精确计算两个十进制字符串的乘积（如 "0.1" × "0.01" -> "0.001"），避免浮点误差影响小数位推导
+func multiplyDecimal(a, b string) string {
+	x, okA := new(big.Rat).SetString(a)
+	y, okB := new(big.Rat).SetString(b)
+	if !okA || !okB {
+		return a
+	}
+	result := new(big.Rat).Mul(x, y).FloatString(countDecimalPlaces(a) + countDecimalPlaces(b))
+	if strings.Contains(result, ".") {
+		result = strings.TrimRight(strings.TrimRight(result, "0"), ".")
+	}
+	return result
+}
+
+// countDecimalPlaces 计算步长字符串的小数位数（如 "0.010" -> 2）
+func countDecimalPlaces(step string) int {
+	if !strings.Contains(step, ".") {
+		return 0
+	}
+	step = strings.TrimRight(step, "0")
+	return len(step) - strings.Index(step, ".") - 1
+}
+
+// alignToTickSize 将价格对齐到价格步长
+func alignToTickSize(price, tickSize float64, decimals int) float64 {
+	if tickSize <= 0 {
+		return price
+	}
+	aligned := math.Round(price/tickSize) * tickSize
+	multiplier := math.Pow(10, float64(decimals))
+	return math.Round(aligned*multiplier) / multiplier
+}
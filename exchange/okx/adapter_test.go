@@ -0,0 +1,438 @@
+package okx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"opensqt/exchange/errs"
+	"opensqt/exchange/venue/venuetest"
+
+	"github.com/gorilla/websocket"
+)
+
+// ===== 本地模拟 OKX 服务 =====
+
+// newMockServer 模拟 OKX V5 REST 接口，校验签名（timestamp + method + requestPath(含查询串) + body）并记录请求
+func newMockServer(t *testing.T, posMode string) *venuetest.Server {
+	signer := NewSigner("test-key", "test-secret", "test-pass")
+	mock := venuetest.NewServer(t, func(r *http.Request, body []byte) bool {
+		return r.Header.Get("OK-ACCESS-KEY") == "test-key" && r.Header.Get("OK-ACCESS-PASSPHRASE") == "test-pass" &&
+			r.Header.Get("OK-ACCESS-SIGN") == signer.Sign(r.Header.Get("OK-ACCESS-TIMESTAMP"), r.Method, r.URL.RequestURI(), string(body))
+	}, `{"code":"50113","msg":"Invalid Sign","data":[]}`)
+
+	mock.HandlePublic("/api/v5/public/time", func(w http.ResponseWriter, body string) {
+		writeData(w, fmt.Sprintf(`[{"ts":"%d"}]`, time.Now().UnixMilli()))
+	})
+	// ETH-USDT-SWAP：1张 = 0.1 ETH，张数步长 0.01
+	mock.Handle("/api/v5/public/instruments", func(w http.ResponseWriter, body string) {
+		writeData(w, `[{"instId":"ETH-USDT-SWAP","ctVal":"0.1","ctValCcy":"ETH","ctType":"linear","settleCcy":"USDT",
+			"tickSz":"0.01","lotSz":"0.01","minSz":"0.01","lever":"100"}]`)
+	})
+	mock.Handle("/api/v5/account/config", func(w http.ResponseWriter, body string) {
+		writeData(w, `[{"posMode":"`+posMode+`"}]`)
+	})
+	return mock
+}
+
+func writeData(w http.ResponseWriter, data string) {
+	w.Write([]byte(`{"code":"0","msg":"","data":` + data + `}`))
+}
+
+func newTestAdapter(t *testing.T, baseURL string, extra map[string]string) *OKXAdapter {
+	cfg := map[string]string{
+		"api_key":    "test-key",
+		"secret_key": "test-secret",
+		"passphrase": "test-pass",
+		"base_url":   baseURL,
+	}
+	for k, v := range extra {
+		cfg[k] = v
+	}
+	adapter, err := NewOKXAdapter(cfg, "ETHUSDT")
+	if err != nil {
+		t.Fatalf("创建适配器失败: %v", err)
+	}
+	return adapter
+}
+
+// ===== 签名 =====
+
+func TestSignerKnownVector(t *testing.T) {
+	signer := NewSigner("key", "secret", "pass")
+
+	got := signer.Sign("2020-12-08T09:08:57.715Z", "GET", "/api/v5/account/balance?ccy=USDT", "")
+	want := "rU3eBbGNF6XZT9cVLJVmOKO/wndaidjMkElDI6kMkzY="
+	if got != want {
+		t.Errorf("签名错误: got %s, want %s", got, want)
+	}
+}
+
+func TestNewOKXAdapterRequiresPassphrase(t *testing.T) {
+	_, err := NewOKXAdapter(map[string]string{"api_key": "k", "secret_key": "s"}, "ETHUSDT")
+	if err == nil {
+		t.Fatal("缺少 passphrase 时应返回错误")
+	}
+}
+
+// ===== REST =====
+
+func TestContractConversion(t *testing.T) {
+	mock := newMockServer(t, "net_mode")
+	adapter := newTestAdapter(t, mock.URL, nil)
+
+	if adapter.instID != "ETH-USDT-SWAP" {
+		t.Errorf("instId 错误: %s", adapter.instID)
+	}
+	// lotSz(0.01) * ctVal(0.1) = 0.001 ETH
+	if adapter.GetPriceDecimals() != 2 || adapter.GetQuantityDecimals() != 3 {
+		t.Errorf("精度错误: price=%d, qty=%d", adapter.GetPriceDecimals(), adapter.GetQuantityDecimals())
+	}
+	if adapter.GetBaseAsset() != "ETH" || adapter.GetQuoteAsset() != "USDT" {
+		t.Errorf("币种错误: base=%s, quote=%s", adapter.GetBaseAsset(), adapter.GetQuoteAsset())
+	}
+
+	// 0.257 ETH -> 2.57 张
+	if got := adapter.formatContracts(adapter.toContracts(0.257)); got != "2.57" {
+		t.Errorf("张数换算错误: got %s, want 2.57", got)
+	}
+	// 0.2579 ETH -> 2.579 张，向下对齐到 2.57
+	if got := adapter.formatContracts(adapter.toContracts(0.2579)); got != "2.57" {
+		t.Errorf("张数对齐错误: got %s, want 2.57", got)
+	}
+	if got := adapter.fromContracts("2.57"); got != 0.257 {
+		t.Errorf("数量换算错误: got %v, want 0.257", got)
+	}
+}
+
+func TestGetSymbolInfo(t *testing.T) {
+	mock := newMockServer(t, "net_mode")
+	adapter := newTestAdapter(t, mock.URL, nil)
+
+	info, err := adapter.GetSymbolInfo(context.Background(), "ETHUSDT")
+	if err != nil {
+		t.Fatalf("获取合约信息失败: %v", err)
+	}
+	if info.TickSize != 0.01 || info.StepSize != 0.001 || info.MinQty != 0.001 ||
+		info.ContractMultiplier != 0.1 || info.MaxLeverage != 100 || info.QuantityDecimals != 3 {
+		t.Errorf("合约信息错误: %+v", info)
+	}
+	if info.BaseAsset != "ETH" || info.QuoteAsset != "USDT" {
+		t.Errorf("币种错误: %+v", info)
+	}
+}
+
+func TestPlaceOrderEncodesClientOrderID(t *testing.T) {
+	mock := newMockServer(t, "long_short_mode")
+	mock.Handle("/api/v5/trade/order", func(w http.ResponseWriter, body string) {
+		var req map[string]interface{}
+		json.Unmarshal([]byte(body), &req)
+		writeData(w, `[{"ordId":"612345678901234567","clOrdId":"`+req["clOrdId"].(string)+`","sCode":"0","sMsg":""}]`)
+	})
+	adapter := newTestAdapter(t, mock.URL, nil)
+
+	order, err := adapter.PlaceOrder(context.Background(), &OrderRequest{
+		Symbol:        "ETHUSDT",
+		Side:          SideSell,
+		Type:          OrderTypeLimit,
+		Quantity:      0.5,
+		Price:         3000.123,
+		ReduceOnly:    true,
+		PostOnly:      true,
+		ClientOrderID: "300012_S_1702468800001",
+	})
+	if err != nil {
+		t.Fatalf("下单失败: %v", err)
+	}
+	if order.OrderID != 612345678901234567 {
+		t.Errorf("订单ID错误: %d", order.OrderID)
+	}
+	if order.ClientOrderID != "300012_S_1702468800001" {
+		t.Errorf("自定义订单ID未还原: %s", order.ClientOrderID)
+	}
+
+	var body map[string]interface{}
+	json.Unmarshal([]byte(mock.LastRequest("/api/v5/trade/order")), &body)
+	if body["clOrdId"] != "300012S1702468800001" {
+		t.Errorf("clOrdId 编码错误: %v", body["clOrdId"])
+	}
+	if body["sz"] != "5.00" || body["px"] != "3000.12" || body["ordType"] != "post_only" {
+		t.Errorf("下单参数错误: %v", body)
+	}
+	// 双向持仓下平多：sell + long，不带 reduceOnly
+	if body["posSide"] != "long" || body["reduceOnly"] != nil {
+		t.Errorf("双向持仓参数错误: %v", body)
+	}
+	// 指定持仓方向时直接使用：做空网格买入平空
+	_, err = adapter.PlaceOrder(context.Background(), &OrderRequest{
+		Symbol: "ETHUSDT", Side: SideBuy, Type: OrderTypeLimit, Quantity: 0.5, Price: 2900,
+		ReduceOnly: true, PositionSide: "SHORT", ClientOrderID: "300013_B_1702468800002",
+	})
+	if err != nil {
+		t.Fatalf("下单失败: %v", err)
+	}
+	json.Unmarshal([]byte(mock.LastRequest("/api/v5/trade/order")), &body)
+	if body["posSide"] != "short" || body["reduceOnly"] != nil {
+		t.Errorf("指定持仓方向时参数错误: %v", body)
+	}
+}
+
+// 批量下单部分失败时整体 code 为 "2"，逐单结果带 sCode，成功的订单照常返回
+func TestBatchOrdersPartialFailure(t *testing.T) {
+	mock := newMockServer(t, "net_mode")
+	mock.Handle("/api/v5/trade/batch-orders", func(w http.ResponseWriter, body string) {
+		w.Write([]byte(`{"code":"2","msg":"","data":[
+			{"ordId":"101","clOrdId":"300000B1702468800001","sCode":"0","sMsg":""},
+			{"ordId":"","clOrdId":"299000B1702468800002","sCode":"51008","sMsg":"Insufficient margin"}]}`))
+	})
+	adapter := newTestAdapter(t, mock.URL, nil)
+
+	orders, marginErr := adapter.BatchPlaceOrders(context.Background(), []*OrderRequest{
+		{Symbol: "ETHUSDT", Side: SideBuy, Type: OrderTypeLimit, Quantity: 0.1, Price: 3000, ClientOrderID: "300000_B_1702468800001"},
+		{Symbol: "ETHUSDT", Side: SideBuy, Type: OrderTypeLimit, Quantity: 0.1, Price: 2990, ClientOrderID: "299000_B_1702468800002"},
+	})
+	if !marginErr {
+		t.Error("应检测到保证金不足")
+	}
+	if len(orders) != 1 || orders[0].OrderID != 101 || orders[0].ClientOrderID != "300000_B_1702468800001" {
+		t.Errorf("成功订单错误: %+v", orders)
+	}
+
+	var bodies []map[string]interface{}
+	json.Unmarshal([]byte(mock.LastRequest("/api/v5/trade/batch-orders")), &bodies)
+	if len(bodies) != 2 || bodies[0]["reduceOnly"] != nil {
+		t.Errorf("批量下单参数错误: %v", bodies)
+	}
+}
+
+func TestAccountSettings(t *testing.T) {
+	mock := newMockServer(t, "net_mode")
+	mock.Handle("/api/v5/account/set-position-mode", func(w http.ResponseWriter, body string) {
+		writeData(w, `[{"posMode":"long_short_mode"}]`)
+	})
+	var leverageBodies []map[string]interface{}
+	mock.Handle("/api/v5/account/set-leverage", func(w http.ResponseWriter, body string) {
+		var req map[string]interface{}
+		json.Unmarshal([]byte(body), &req)
+		leverageBodies = append(leverageBodies, req)
+		writeData(w, `[{"lever":"5"}]`)
+	})
+	mock.Handle("/api/v5/trade/order", func(w http.ResponseWriter, body string) {
+		writeData(w, `[{"ordId":"1","clOrdId":"","sCode":"0","sMsg":""}]`)
+	})
+	adapter := newTestAdapter(t, mock.URL, nil)
+	ctx := context.Background()
+
+	if err := adapter.SetPositionMode(ctx, true); err != nil {
+		t.Fatalf("设置持仓模式失败: %v", err)
+	}
+	if err := adapter.SetMarginType(ctx, "ETHUSDT", true); err != nil {
+		t.Fatalf("设置保证金模式失败: %v", err)
+	}
+	if err := adapter.SetLeverage(ctx, "ETHUSDT", 5); err != nil {
+		t.Fatalf("设置杠杆失败: %v", err)
+	}
+
+	if got := mock.LastRequest("/api/v5/account/set-position-mode"); got != `{"posMode":"long_short_mode"}` {
+		t.Errorf("持仓模式参数错误: %s", got)
+	}
+	// 双向持仓的逐仓杠杆按多空方向分别设置
+	if len(leverageBodies) != 2 || leverageBodies[0]["posSide"] != "long" || leverageBodies[1]["posSide"] != "short" ||
+		leverageBodies[0]["mgnMode"] != "isolated" || leverageBodies[0]["lever"] != "5" {
+		t.Errorf("杠杆参数错误: %v", leverageBodies)
+	}
+
+	// 后续下单使用设置的保证金模式
+	if _, err := adapter.PlaceOrder(ctx, &OrderRequest{Symbol: "ETHUSDT", Side: SideBuy, Type: OrderTypeLimit, Quantity: 0.5, Price: 3000}); err != nil {
+		t.Fatalf("下单失败: %v", err)
+	}
+	var body map[string]interface{}
+	json.Unmarshal([]byte(mock.LastRequest("/api/v5/trade/order")), &body)
+	if body["tdMode"] != "isolated" || body["posSide"] != "long" {
+		t.Errorf("下单参数错误: %v", body)
+	}
+}
+
+func TestCancelAllOrdersUsesPendingOrders(t *testing.T) {
+	mock := newMockServer(t, "net_mode")
+	mock.Handle("/api/v5/trade/orders-pending", func(w http.ResponseWriter, body string) {
+		writeData(w, `[{"instId":"ETH-USDT-SWAP","ordId":"11","state":"live","sz":"1"},
+			{"instId":"ETH-USDT-SWAP","ordId":"12","state":"partially_filled","sz":"2","accFillSz":"1"}]`)
+	})
+	mock.Handle("/api/v5/trade/cancel-batch-orders", func(w http.ResponseWriter, body string) {
+		writeData(w, `[{"ordId":"11","sCode":"0"},{"ordId":"12","sCode":"51402"}]`)
+	})
+	adapter := newTestAdapter(t, mock.URL, nil)
+
+	if err := adapter.CancelAllOrders(context.Background(), "ETHUSDT"); err != nil {
+		t.Fatalf("撤销所有订单失败: %v", err)
+	}
+
+	var bodies []map[string]string
+	json.Unmarshal([]byte(mock.LastRequest("/api/v5/trade/cancel-batch-orders")), &bodies)
+	if len(bodies) != 2 || bodies[0]["ordId"] != "11" || bodies[1]["ordId"] != "12" {
+		t.Errorf("批量撤单参数错误: %v", bodies)
+	}
+}
+
+// 错误码：下单失败时整体 code 为 "1"，原因在逐单的 sCode 中；时间戳过期为整体 code 50102。
+// 撤单时订单已成交、已撤销或不存在的 sCode 均视为成功
+func TestErrorCodes(t *testing.T) {
+	mock := newMockServer(t, "net_mode")
+	adapter := newTestAdapter(t, mock.URL, nil)
+	ctx := context.Background()
+
+	placeCases := []struct {
+		resp string
+		want error
+	}{
+		{`{"code":"1","msg":"","data":[{"ordId":"","sCode":"51008","sMsg":"Order failed. Insufficient USDT margin in account"}]}`, errs.ErrInsufficientMargin},
+		{`{"code":"50102","msg":"Timestamp request expired","data":[]}`, errs.ErrClockSkew},
+	}
+	for _, c := range placeCases {
+		mock.Handle("/api/v5/trade/order", venuetest.Reply(c.resp))
+		_, err := adapter.PlaceOrder(ctx, &OrderRequest{Symbol: "ETHUSDT", Side: SideBuy, Type: OrderTypeLimit, Quantity: 0.1, Price: 3000})
+		if !errors.Is(err, c.want) {
+			t.Errorf("%s: err = %v, want %v", c.resp, err, c.want)
+		}
+	}
+
+	for _, sCode := range []string{"51400", "51401", "51402", "51603"} {
+		mock.Handle("/api/v5/trade/cancel-order", venuetest.Reply(`{"code":"1","msg":"","data":[{"ordId":"11","sCode":"`+sCode+`","sMsg":""}]}`))
+		if err := adapter.CancelOrder(ctx, "ETHUSDT", 11); err != nil {
+			t.Errorf("sCode=%s 撤单应视为成功: %v", sCode, err)
+		}
+	}
+	mock.Handle("/api/v5/trade/cancel-order", venuetest.Reply(`{"code":"1","msg":"","data":[{"ordId":"11","sCode":"51000","sMsg":"Parameter ordId error"}]}`))
+	if err := adapter.CancelOrder(ctx, "ETHUSDT", 11); err == nil {
+		t.Error("其他撤单错误应返回")
+	}
+}
+
+func TestGetPositionsConvertsContracts(t *testing.T) {
+	mock := newMockServer(t, "long_short_mode")
+	mock.Handle("/api/v5/account/positions", func(w http.ResponseWriter, body string) {
+		writeData(w, `[{"instId":"ETH-USDT-SWAP","pos":"30","posSide":"short","avgPx":"3000","lever":"10","mgnMode":"cross"},
+			{"instId":"ETH-USDT-SWAP","pos":"0","posSide":"long"}]`)
+	})
+	adapter := newTestAdapter(t, mock.URL, nil)
+
+	positions, err := adapter.GetPositions(context.Background(), "ETHUSDT")
+	if err != nil {
+		t.Fatalf("获取持仓失败: %v", err)
+	}
+	if len(positions) != 1 || positions[0].Size != -3 || positions[0].Symbol != "ETHUSDT" || positions[0].Leverage != 10 ||
+		positions[0].Side != "SHORT" {
+		t.Errorf("持仓换算错误: %+v", positions)
+	}
+}
+
+func TestGetHistoricalKlinesPaginates(t *testing.T) {
+	mock := newMockServer(t, "net_mode")
+	calls := 0
+	mock.Handle("/api/v5/market/candles", func(w http.ResponseWriter, body string) {
+		calls++
+		// 倒序返回：第一页 300 根（上限），第二页按剩余的 150 根
+		count := int64(300)
+		if calls > 1 {
+			count = 150
+		}
+		rows := make([]string, 0, count)
+		start := int64(1000000 - (calls-1)*300)
+		for i := int64(0); i < count; i++ {
+			ts := (start - i) * 60000
+			rows = append(rows, `["`+strconv.FormatInt(ts, 10)+`","1","2","0.5","1.5","10","1","1.5","1"]`)
+		}
+		writeData(w, "["+strings.Join(rows, ",")+"]")
+	})
+	adapter := newTestAdapter(t, mock.URL, nil)
+
+	candles, err := adapter.GetHistoricalKlines(context.Background(), "ETHUSDT", "1h", 450)
+	if err != nil {
+		t.Fatalf("获取历史K线失败: %v", err)
+	}
+	if calls != 2 || len(candles) != 450 {
+		t.Fatalf("翻页错误: calls=%d, candles=%d", calls, len(candles))
+	}
+	for i := 1; i < len(candles); i++ {
+		if candles[i].Timestamp <= candles[i-1].Timestamp {
+			t.Fatalf("K线未按时间升序: %d <= %d", candles[i].Timestamp, candles[i-1].Timestamp)
+		}
+	}
+	if candles[0].Volume != 1 || !candles[0].IsClosed {
+		t.Errorf("K线字段错误: %+v", candles[0])
+	}
+}
+
+// ===== WebSocket =====
+
+func TestPrivateStreamLoginAndOrderUpdate(t *testing.T) {
+	mock := newMockServer(t, "net_mode")
+
+	upgrader := websocket.Upgrader{}
+	wsServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var login struct {
+			Op   string              `json:"op"`
+			Args []map[string]string `json:"args"`
+		}
+		if err := conn.ReadJSON(&login); err != nil || login.Op != "login" || len(login.Args) != 1 {
+			t.Errorf("登录消息错误: %+v, err=%v", login, err)
+			return
+		}
+		arg := login.Args[0]
+		signer := NewSigner("test-key", "test-secret", "test-pass")
+		if arg["passphrase"] != "test-pass" || arg["sign"] != signer.Sign(arg["timestamp"], "GET", "/users/self/verify", "") {
+			t.Errorf("登录签名错误: %v", arg)
+		}
+		conn.WriteJSON(map[string]string{"event": "login", "code": "0", "msg": ""})
+
+		var sub map[string]interface{}
+		conn.ReadJSON(&sub)
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"arg":{"channel":"orders","instType":"SWAP"},"data":[
+			{"instId":"ETH-USDT-SWAP","ordId":"555","clOrdId":"300000B1702468800001","side":"buy","ordType":"post_only",
+			 "px":"3000","sz":"10","accFillSz":"10","avgPx":"3000","state":"filled","uTime":"1702468800500"}]}`))
+
+		// 保持连接直到客户端关闭
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	defer wsServer.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(wsServer.URL, "http")
+	adapter := newTestAdapter(t, mock.URL, map[string]string{"ws_private_url": wsURL})
+
+	updates := make(chan interface{}, 1)
+	if err := adapter.StartOrderStream(context.Background(), func(u interface{}) { updates <- u }); err != nil {
+		t.Fatalf("启动订单流失败: %v", err)
+	}
+	defer adapter.StopOrderStream()
+
+	select {
+	case u := <-updates:
+		raw, _ := json.Marshal(u)
+		var update OrderUpdate
+		json.Unmarshal(raw, &update)
+		if update.OrderID != 555 || update.ClientOrderID != "300000_B_1702468800001" ||
+			update.Status != OrderStatusFilled || update.ExecutedQty != 1 || update.Symbol != "ETHUSDT" {
+			t.Errorf("订单推送转换错误: %+v", update)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("未收到订单推送")
+	}
+}
@@ -0,0 +1,167 @@
+package okx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	OKXBaseURL = "https://www.okx.com"
+)
+
+// Client OKX HTTP 客户端
+type Client struct {
+	httpClient *http.Client
+	signer     *Signer
+	baseURL    string
+	simulated  bool // 模拟盘：请求头携带 x-simulated-trading: 1
+}
+
+// NewClient 创建 OKX 客户端
+func NewClient(apiKey, secretKey, passphrase string) *Client {
+	return &Client{
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		signer:     NewSigner(apiKey, secretKey, passphrase),
+		baseURL:    OKXBaseURL,
+	}
+}
+
+// OKXResponse OKX V5 API 通用响应结构
+type OKXResponse struct {
+	Code string          `json:"code"`
+	Msg  string          `json:"msg"`
+	Data json.RawMessage `json:"data"`
+}
+
+// APIError OKX API 业务错误
+// 交易类接口的具体原因在 data[].sCode / sMsg 中
+type APIError struct {
+	Code  string
+	Msg   string
+	SCode string
+	SMsg  string
+}
+
+func (e *APIError) Error() string {
+	if e.SCode != "" {
+		return fmt.Sprintf("okx API 错误: code=%s, sCode=%s, msg=%s", e.Code, e.SCode, e.SMsg)
+	}
+	return fmt.Sprintf("okx API 错误: code=%s, msg=%s", e.Code, e.Msg)
+}
+
+// DoRequest 发送 HTTP 请求（带签名），code != "0" 时返回 *APIError
+// path 需包含查询参数
+func (c *Client) DoRequest(ctx context.Context, method, path string, body interface{}) (*OKXResponse, error) {
+	resp, err := c.DoBatchRequest(ctx, method, path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Code != "0" {
+		apiErr := &APIError{Code: resp.Code, Msg: resp.Msg}
+		var items []struct {
+			SCode string `json:"sCode"`
+			SMsg  string `json:"sMsg"`
+		}
+		if json.Unmarshal(resp.Data, &items) == nil {
+			for _, item := range items {
+				if item.SCode != "" && item.SCode != "0" {
+					apiErr.SCode = item.SCode
+					apiErr.SMsg = item.SMsg
+					break
+				}
+			}
+		}
+		return nil, apiErr
+	}
+
+	return resp, nil
+}
+
+// DoBatchRequest 发送 HTTP 请求，不检查业务 code
+// 用于批量接口：部分成功时 code="2"，需要由调用方逐条检查 sCode
+func (c *Client) DoBatchRequest(ctx context.Context, method, path string, body interface{}) (*OKXResponse, error) {
+	var bodyBytes []byte
+	var err error
+
+	if body != nil {
+		bodyBytes, err = json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("序列化请求体失败: %w", err)
+		}
+	}
+
+	timestamp := c.signer.GetTimestamp()
+	signature := c.signer.Sign(timestamp, method, path, string(bodyBytes))
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bytes.NewBuffer(bodyBytes))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+
+	// 添加 OKX 必需的请求头
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("OK-ACCESS-KEY", c.signer.GetAPIKey())
+	req.Header.Set("OK-ACCESS-SIGN", signature)
+	req.Header.Set("OK-ACCESS-TIMESTAMP", timestamp)
+	req.Header.Set("OK-ACCESS-PASSPHRASE", c.signer.GetPassphrase())
+	if c.simulated {
+		req.Header.Set("x-simulated-trading", "1")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取响应失败: %w", err)
+	}
+
+	var okxResp OKXResponse
+	if err := json.Unmarshal(respBody, &okxResp); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w, HTTP状态: %d, 响应体: %s", err, resp.StatusCode, string(respBody))
+	}
+
+	return &okxResp, nil
+}
+
+// ServerTime 查询服务器时间（公共接口，不签名，本地时钟偏差过大时也能对时）
+func (c *Client) ServerTime(ctx context.Context) (time.Time, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/api/v5/public/time", nil)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("创建请求失败: %w", err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Code string `json:"code"`
+		Msg  string `json:"msg"`
+		Data []struct {
+			Ts string `json:"ts"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return time.Time{}, fmt.Errorf("解析响应失败: %w", err)
+	}
+	if result.Code != "0" || len(result.Data) == 0 {
+		return time.Time{}, &APIError{Code: result.Code, Msg: result.Msg}
+	}
+	ms, err := strconv.ParseInt(result.Data[0].Ts, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("解析服务器时间失败: %w", err)
+	}
+	return time.UnixMilli(ms), nil
+}
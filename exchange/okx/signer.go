@@ -0,0 +1,57 @@
+package okx
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+
+	"opensqt/exchange/clock"
+)
+
+// Signer OKX API 签名器
+type Signer struct {
+	apiKey     string
+	secretKey  string
+	passphrase string
+	clock      *clock.Estimator // 服务器时间偏差，nil 时使用本地时间
+}
+
+// NewSigner 创建签名器
+func NewSigner(apiKey, secretKey, passphrase string) *Signer {
+	return &Signer{
+		apiKey:     apiKey,
+		secretKey:  secretKey,
+		passphrase: passphrase,
+	}
+}
+
+// Sign 生成签名
+// OKX 签名规则: Base64(HMAC_SHA256(timestamp + method + requestPath + body, secretKey))
+// requestPath 包含查询参数，例如 /api/v5/account/balance?ccy=USDT
+func (s *Signer) Sign(timestamp, method, requestPath, body string) string {
+	message := timestamp + method + requestPath + body
+	mac := hmac.New(sha256.New, []byte(s.secretKey))
+	mac.Write([]byte(message))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// GetTimestamp 获取 REST 时间戳（ISO8601 毫秒格式，如 2020-12-08T09:08:57.715Z）
+func (s *Signer) GetTimestamp() string {
+	return s.clock.Now().UTC().Format("2006-01-02T15:04:05.000Z")
+}
+
+// GetWSTimestamp 获取 WebSocket 登录时间戳（秒）
+func (s *Signer) GetWSTimestamp() string {
+	return fmt.Sprintf("%d", s.clock.Now().Unix())
+}
+
+// GetAPIKey 获取 API Key
+func (s *Signer) GetAPIKey() string {
+	return s.apiKey
+}
+
+// GetPassphrase 获取 Passphrase
+func (s *Signer) GetPassphrase() string {
+	return s.passphrase
+}
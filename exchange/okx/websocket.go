@@ -0,0 +1,507 @@
+package okx
+
+/*
+OKX WebSocket 架构说明：
+
+1. **公共频道** (wss://ws.okx.com:8443/ws/v5/public)：订阅 tickers 价格推送
+2. **私有频道** (wss://ws.okx.com:8443/ws/v5/private)：登录后订阅 orders（订单）
+3. **业务频道** (wss://ws.okx.com:8443/ws/v5/business)：K线推送，见 kline_websocket.go
+4. 各连接相互独立，各自带自动重连；心跳为文本 "ping"，服务端回复文本 "pong"，30秒无消息会断开
+*/
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"opensqt/exchange/orderbook"
+	"opensqt/logger"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// OKX V5 WebSocket 地址
+	OKXWSPublic   = "wss://ws.okx.com:8443/ws/v5/public"
+	OKXWSPrivate  = "wss://ws.okx.com:8443/ws/v5/private"
+	OKXWSBusiness = "wss://ws.okx.com:8443/ws/v5/business"
+
+	// 模拟盘 WebSocket 地址
+	OKXWSPublicDemo   = "wss://wspap.okx.com:8443/ws/v5/public"
+	OKXWSPrivateDemo  = "wss://wspap.okx.com:8443/ws/v5/private"
+	OKXWSBusinessDemo = "wss://wspap.okx.com:8443/ws/v5/business"
+)
+
+// WebSocketManager OKX WebSocket 管理器
+type WebSocketManager struct {
+	signer     *Signer
+	publicURL  string
+	privateURL string
+
+	// adapter 用于张数与基础币数量的换算（由适配器注入）
+	adapter *OKXAdapter
+
+	mu          sync.RWMutex
+	writeMu     sync.Mutex
+	publicConn  *websocket.Conn
+	privateConn *websocket.Conn
+
+	// 回调函数
+	orderCallback func(OrderUpdate)
+	priceCallback func(float64)
+	bookCallback  func(*orderbook.Ticker)
+
+	// 控制
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	publicStarted  bool
+	privateStarted bool
+	instID         string
+
+	// 价格缓存
+	latestPrice float64
+	priceMu     sync.RWMutex
+
+	reconnectDelay time.Duration
+	pingInterval   time.Duration
+}
+
+// NewWebSocketManager 创建 WebSocket 管理器
+func NewWebSocketManager(signer *Signer) *WebSocketManager {
+	return &WebSocketManager{
+		signer:         signer,
+		publicURL:      OKXWSPublic,
+		privateURL:     OKXWSPrivate,
+		reconnectDelay: 5 * time.Second,
+		pingInterval:   20 * time.Second, // OKX 30秒无消息会断开，20秒发送一次 ping
+	}
+}
+
+// ensureContext 初始化内部 context（首次启动时）
+func (w *WebSocketManager) ensureContext(ctx context.Context) {
+	if w.ctx == nil || w.ctx.Err() != nil {
+		w.ctx, w.cancel = context.WithCancel(ctx)
+	}
+}
+
+// StartPublic 启动公共频道（价格推送）
+func (w *WebSocketManager) StartPublic(ctx context.Context, instID string, callback func(float64)) error {
+	w.mu.Lock()
+	w.priceCallback = callback
+	w.instID = instID
+	if w.publicStarted {
+		w.mu.Unlock()
+		logger.Debug("✅ [OKX] 价格流回调已注册（WebSocket已在运行）")
+		return nil
+	}
+	w.ensureContext(ctx)
+	w.publicStarted = true
+	w.mu.Unlock()
+
+	w.wg.Add(1)
+	go w.connectLoop("公共", w.publicURL, w.onPublicConnected, w.handlePublicMessage)
+
+	logger.Info("✅ [OKX WebSocket] 启动成功，将订阅 %s 的价格更新", instID)
+	return nil
+}
+
+// StartBookTicker 注册盘口最优价回调（复用 tickers 推送的 bidPx/askPx），公共频道未运行时一并启动
+func (w *WebSocketManager) StartBookTicker(ctx context.Context, instID string, callback func(*orderbook.Ticker)) error {
+	w.mu.Lock()
+	w.bookCallback = callback
+	w.instID = instID
+	if w.publicStarted {
+		w.mu.Unlock()
+		return nil
+	}
+	w.ensureContext(ctx)
+	w.publicStarted = true
+	w.mu.Unlock()
+
+	w.wg.Add(1)
+	go w.connectLoop("公共", w.publicURL, w.onPublicConnected, w.handlePublicMessage)
+
+	logger.Info("✅ [OKX WebSocket] 启动成功，将订阅 %s 的盘口最优价", instID)
+	return nil
+}
+
+// StartPrivate 启动私有频道（订单推送）
+func (w *WebSocketManager) StartPrivate(ctx context.Context, instID string, callback func(OrderUpdate)) error {
+	w.mu.Lock()
+	w.orderCallback = callback
+	w.instID = instID
+	if w.privateStarted {
+		w.mu.Unlock()
+		return nil
+	}
+	w.ensureContext(ctx)
+	w.privateStarted = true
+	w.mu.Unlock()
+
+	w.wg.Add(1)
+	go w.connectLoop("私有", w.privateURL, w.onPrivateConnected, w.handlePrivateMessage)
+
+	logger.Info("✅ [OKX WebSocket] 启动成功，将订阅 %s 的订单更新", instID)
+	return nil
+}
+
+// Stop 停止 WebSocket
+func (w *WebSocketManager) Stop() {
+	w.mu.Lock()
+	if w.cancel != nil {
+		w.cancel()
+	}
+	if w.publicConn != nil {
+		w.publicConn.Close()
+	}
+	if w.privateConn != nil {
+		w.privateConn.Close()
+	}
+	w.publicStarted = false
+	w.privateStarted = false
+	w.mu.Unlock()
+
+	// 等待所有 goroutine 退出（不能持有锁，避免死锁）
+	w.wg.Wait()
+	logger.Info("✅ [OKX WebSocket] 已停止")
+}
+
+// GetLatestPrice 获取缓存的最新价格
+func (w *WebSocketManager) GetLatestPrice() float64 {
+	w.priceMu.RLock()
+	defer w.priceMu.RUnlock()
+	return w.latestPrice
+}
+
+// connectLoop 连接循环（自动重连）
+// onConnected: 连接建立后执行登录/订阅，handler: 处理每条消息
+func (w *WebSocketManager) connectLoop(name, wsURL string, onConnected func(*websocket.Conn) error, handler func([]byte)) {
+	defer w.wg.Done()
+
+	for {
+		select {
+		case <-w.ctx.Done():
+			logger.Info("✅ [OKX WS%s] 停止连接循环", name)
+			return
+		default:
+		}
+
+		logger.Info("🔗 [OKX WS%s] 正在连接...", name)
+		conn, _, err := websocket.DefaultDialer.DialContext(w.ctx, wsURL, nil)
+		if err == nil {
+			if err = onConnected(conn); err != nil {
+				conn.Close()
+			}
+		}
+		if err != nil {
+			logger.Error("❌ [OKX WS%s] 连接失败: %v，%v后重试", name, err, w.reconnectDelay)
+			select {
+			case <-w.ctx.Done():
+				logger.Info("✅ [OKX WS%s] 停止连接循环", name)
+				return
+			case <-time.After(w.reconnectDelay):
+			}
+			continue
+		}
+
+		logger.Info("✅ [OKX WS%s] 已连接", name)
+
+		// 启动心跳，读取循环阻塞直到连接断开
+		pingDone := make(chan struct{})
+		go w.pingLoop(conn, pingDone)
+		w.readLoop(conn, handler)
+		close(pingDone)
+
+		w.mu.Lock()
+		if w.publicConn == conn {
+			w.publicConn = nil
+		}
+		if w.privateConn == conn {
+			w.privateConn = nil
+		}
+		w.mu.Unlock()
+		conn.Close()
+
+		select {
+		case <-w.ctx.Done():
+			logger.Info("✅ [OKX WS%s] 停止连接循环", name)
+			return
+		default:
+		}
+
+		logger.Warn("⚠️ [OKX WS%s] 连接断开，%v后重连...", name, w.reconnectDelay)
+		select {
+		case <-w.ctx.Done():
+			logger.Info("✅ [OKX WS%s] 停止连接循环", name)
+			return
+		case <-time.After(w.reconnectDelay):
+		}
+	}
+}
+
+// onPublicConnected 公共频道连接建立后订阅 tickers
+func (w *WebSocketManager) onPublicConnected(conn *websocket.Conn) error {
+	w.mu.Lock()
+	w.publicConn = conn
+	instID := w.instID
+	w.mu.Unlock()
+
+	return w.writeJSON(conn, map[string]interface{}{
+		"op": "subscribe",
+		"args": []map[string]string{
+			{"channel": "tickers", "instId": instID},
+		},
+	})
+}
+
+// onPrivateConnected 私有频道连接建立后登录并订阅 orders
+// 登录签名: Base64(HMAC_SHA256(timestamp + "GET" + "/users/self/verify", secretKey))，timestamp 为秒
+func (w *WebSocketManager) onPrivateConnected(conn *websocket.Conn) error {
+	timestamp := w.signer.GetWSTimestamp()
+	loginMsg := map[string]interface{}{
+		"op": "login",
+		"args": []map[string]string{
+			{
+				"apiKey":     w.signer.GetAPIKey(),
+				"passphrase": w.signer.GetPassphrase(),
+				"timestamp":  timestamp,
+				"sign":       w.signer.Sign(timestamp, "GET", "/users/self/verify", ""),
+			},
+		},
+	}
+	if err := w.writeJSON(conn, loginMsg); err != nil {
+		return fmt.Errorf("发送登录消息失败: %w", err)
+	}
+
+	conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+	var resp struct {
+		Event string `json:"event"`
+		Code  string `json:"code"`
+		Msg   string `json:"msg"`
+	}
+	if err := conn.ReadJSON(&resp); err != nil {
+		return fmt.Errorf("读取登录响应失败: %w", err)
+	}
+	if resp.Event != "login" || resp.Code != "0" {
+		return fmt.Errorf("登录失败: code=%s, msg=%s", resp.Code, resp.Msg)
+	}
+	logger.Info("✅ [OKX WebSocket] 私有频道登录成功")
+
+	w.mu.Lock()
+	w.privateConn = conn
+	instID := w.instID
+	w.mu.Unlock()
+
+	return w.writeJSON(conn, map[string]interface{}{
+		"op": "subscribe",
+		"args": []map[string]string{
+			{"channel": "orders", "instType": "SWAP", "instId": instID},
+		},
+	})
+}
+
+// writeJSON 串行写入（gorilla/websocket 不支持并发写）
+func (w *WebSocketManager) writeJSON(conn *websocket.Conn, v interface{}) error {
+	w.writeMu.Lock()
+	defer w.writeMu.Unlock()
+	return conn.WriteJSON(v)
+}
+
+// pingLoop 心跳循环
+func (w *WebSocketManager) pingLoop(conn *websocket.Conn, done chan struct{}) {
+	ticker := time.NewTicker(w.pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-w.ctx.Done():
+			return
+		case <-ticker.C:
+			w.writeMu.Lock()
+			conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			err := conn.WriteMessage(websocket.TextMessage, []byte("ping"))
+			w.writeMu.Unlock()
+			if err != nil {
+				logger.Warn("⚠️ [OKX WebSocket] 发送 Ping 失败: %v", err)
+				conn.Close()
+				return
+			}
+		}
+	}
+}
+
+// readLoop 读取消息循环
+func (w *WebSocketManager) readLoop(conn *websocket.Conn, handler func([]byte)) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("❌ [OKX WebSocket] 读取协程panic: %v", r)
+		}
+	}()
+
+	readTimeout := 3 * w.pingInterval
+	conn.SetReadDeadline(time.Now().Add(readTimeout))
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				logger.Warn("⚠️ [OKX WebSocket] 异常关闭: %v", err)
+			} else {
+				logger.Debug("OKX WebSocket 读取错误: %v", err)
+			}
+			return
+		}
+		conn.SetReadDeadline(time.Now().Add(readTimeout))
+
+		// 心跳响应
+		if string(message) == "pong" {
+			continue
+		}
+		handler(message)
+	}
+}
+
+// wsMessage OKX WebSocket 推送消息
+type wsMessage struct {
+	Event string `json:"event"`
+	Code  string `json:"code"`
+	Msg   string `json:"msg"`
+	Arg   struct {
+		Channel string `json:"channel"`
+		InstID  string `json:"instId"`
+	} `json:"arg"`
+	Data json.RawMessage `json:"data"`
+}
+
+// handlePublicMessage 处理公共频道消息
+func (w *WebSocketManager) handlePublicMessage(message []byte) {
+	var msg wsMessage
+	if err := json.Unmarshal(message, &msg); err != nil {
+		logger.Debug("解析 OKX 公共消息失败: %v", err)
+		return
+	}
+
+	if msg.Event != "" {
+		if msg.Event == "error" {
+			logger.Warn("⚠️ [OKX WS公共] 错误: code=%s, msg=%s", msg.Code, msg.Msg)
+		}
+		return
+	}
+
+	if msg.Arg.Channel != "tickers" {
+		return
+	}
+
+	var tickers []struct {
+		InstID string `json:"instId"`
+		Last   string `json:"last"`
+		BidPx  string `json:"bidPx"`
+		BidSz  string `json:"bidSz"`
+		AskPx  string `json:"askPx"`
+		AskSz  string `json:"askSz"`
+		Ts     string `json:"ts"`
+	}
+	if err := json.Unmarshal(msg.Data, &tickers); err != nil || len(tickers) == 0 {
+		return
+	}
+
+	w.mu.RLock()
+	bookCallback := w.bookCallback
+	w.mu.RUnlock()
+	if bookCallback != nil {
+		bid, _ := strconv.ParseFloat(tickers[0].BidPx, 64)
+		ask, _ := strconv.ParseFloat(tickers[0].AskPx, 64)
+		if bid > 0 && ask > 0 {
+			ts, _ := strconv.ParseInt(tickers[0].Ts, 10, 64)
+			// 数量单位为张，换算为基础币数量
+			bookCallback(&orderbook.Ticker{
+				Symbol:   tickers[0].InstID,
+				BidPrice: bid,
+				BidQty:   w.adapter.fromContracts(tickers[0].BidSz),
+				AskPrice: ask,
+				AskQty:   w.adapter.fromContracts(tickers[0].AskSz),
+				Time:     ts,
+			})
+		}
+	}
+
+	price, err := strconv.ParseFloat(tickers[0].Last, 64)
+	if err != nil || price <= 0 {
+		return
+	}
+
+	w.priceMu.Lock()
+	w.latestPrice = price
+	w.priceMu.Unlock()
+
+	w.mu.RLock()
+	callback := w.priceCallback
+	w.mu.RUnlock()
+	if callback != nil {
+		callback(price)
+	}
+}
+
+// handlePrivateMessage 处理私有频道消息
+func (w *WebSocketManager) handlePrivateMessage(message []byte) {
+	var msg wsMessage
+	if err := json.Unmarshal(message, &msg); err != nil {
+		logger.Debug("解析 OKX 私有消息失败: %v", err)
+		return
+	}
+
+	if msg.Event != "" {
+		if msg.Event == "error" {
+			logger.Warn("⚠️ [OKX WS私有] 错误: code=%s, msg=%s", msg.Code, msg.Msg)
+		}
+		return
+	}
+
+	if msg.Arg.Channel == "orders" {
+		w.handleOrderUpdate(msg.Data)
+	}
+}
+
+// handleOrderUpdate 处理订单推送
+func (w *WebSocketManager) handleOrderUpdate(data json.RawMessage) {
+	var items []okxOrder
+	if err := json.Unmarshal(data, &items); err != nil {
+		logger.Warn("⚠️ [OKX] 解析订单推送失败: %v", err)
+		return
+	}
+
+	w.mu.RLock()
+	callback := w.orderCallback
+	w.mu.RUnlock()
+	if callback == nil || w.adapter == nil {
+		return
+	}
+
+	for i := range items {
+		order := w.adapter.toOrder(&items[i])
+		update := OrderUpdate{
+			OrderID:       order.OrderID,
+			ClientOrderID: order.ClientOrderID,
+			Symbol:        order.Symbol,
+			Side:          order.Side,
+			Type:          order.Type,
+			Status:        order.Status,
+			Price:         order.Price,
+			Quantity:      order.Quantity,
+			ExecutedQty:   order.ExecutedQty,
+			AvgPrice:      order.AvgPrice,
+			UpdateTime:    order.UpdateTime,
+		}
+
+		logger.Debug("🔍 [OKX] 订单推送: ID=%d, ClientOID=%s, Status=%s, 成交=%.6f",
+			update.OrderID, update.ClientOrderID, update.Status, update.ExecutedQty)
+		callback(update)
+	}
+}
@@ -0,0 +1,88 @@
+package exchange
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// orderStreamFanout 订单流分发器
+// 同一账户只建立一条订单流连接，按交易对把订单更新分发给各订阅者，
+// 多个网格共用一个 API Key 时不会重复建立连接
+type orderStreamFanout struct {
+	mu          sync.Mutex
+	started     bool
+	subscribers map[string][]func(interface{}) // 规范化交易对 -> 回调，"" 表示所有交易对
+}
+
+// subscribe 注册订阅者，首个订阅者负责通过 start 启动底层订单流
+func (f *orderStreamFanout) subscribe(ctx context.Context, symbol string, callback func(interface{}),
+	start func(ctx context.Context, callback func(interface{})) error) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.subscribers == nil {
+		f.subscribers = make(map[string][]func(interface{}))
+	}
+	key := normalizeSymbol(symbol)
+	f.subscribers[key] = append(f.subscribers[key], callback)
+	if f.started {
+		return nil
+	}
+
+	// 底层回调在独立协程中触发，dispatch 会等待这里释放锁
+	if err := start(ctx, f.dispatch); err != nil {
+		subs := f.subscribers[key]
+		f.subscribers[key] = subs[:len(subs)-1]
+		return err
+	}
+	f.started = true
+	return nil
+}
+
+// reset 停止订单流后清空订阅者，下次订阅时重新建立连接
+func (f *orderStreamFanout) reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.started = false
+	f.subscribers = nil
+}
+
+// dispatch 将订单更新分发给订阅了全部交易对与该交易对的回调
+func (f *orderStreamFanout) dispatch(update interface{}) {
+	key := normalizeSymbol(updateSymbol(update))
+
+	f.mu.Lock()
+	callbacks := make([]func(interface{}), 0, len(f.subscribers[""])+len(f.subscribers[key]))
+	callbacks = append(callbacks, f.subscribers[""]...)
+	if key != "" {
+		callbacks = append(callbacks, f.subscribers[key]...)
+	}
+	f.mu.Unlock()
+
+	for _, callback := range callbacks {
+		callback(update)
+	}
+}
+
+// updateSymbol 从子包的订单更新（匿名结构体或本地 OrderUpdate）中提取交易对
+func updateSymbol(update interface{}) string {
+	v := reflect.ValueOf(update)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
+	field := v.FieldByName("Symbol")
+	if !field.IsValid() || field.Kind() != reflect.String {
+		return ""
+	}
+	return field.String()
+}
+
+// normalizeSymbol 统一交易对格式（BTC_USDT / BTC-USDT / btcusdt -> BTCUSDT）
+func normalizeSymbol(symbol string) string {
+	return strings.NewReplacer("_", "", "-", "", "/", "").Replace(strings.ToUpper(symbol))
+}
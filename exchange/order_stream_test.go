@@ -0,0 +1,76 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestOrderStreamFanout(t *testing.T) {
+	var fanout orderStreamFanout
+	var push func(interface{})
+	starts := 0
+	start := func(ctx context.Context, callback func(interface{})) error {
+		starts++
+		push = callback
+		return nil
+	}
+
+	var all, btc, eth []string
+	record := func(dst *[]string) func(interface{}) {
+		return func(update interface{}) {
+			*dst = append(*dst, updateSymbol(update))
+		}
+	}
+
+	ctx := context.Background()
+	if err := fanout.subscribe(ctx, "", record(&all), start); err != nil {
+		t.Fatal(err)
+	}
+	if err := fanout.subscribe(ctx, "BTCUSDT", record(&btc), start); err != nil {
+		t.Fatal(err)
+	}
+	if err := fanout.subscribe(ctx, "ETH-USDT", record(&eth), start); err != nil {
+		t.Fatal(err)
+	}
+	if starts != 1 {
+		t.Fatalf("底层订单流应只启动一次, 实际 %d 次", starts)
+	}
+
+	// 子包推送的订单更新为匿名结构体或本地类型（值或指针）
+	push(struct{ Symbol string }{Symbol: "BTC_USDT"})
+	push(&OrderUpdate{Symbol: "ETHUSDT"})
+	push(struct{ Symbol string }{Symbol: "SOLUSDT"})
+
+	if len(all) != 3 {
+		t.Errorf("订阅全部交易对应收到 3 条更新, 实际 %v", all)
+	}
+	if len(btc) != 1 || btc[0] != "BTC_USDT" {
+		t.Errorf("BTCUSDT 订阅者应只收到 BTC 更新, 实际 %v", btc)
+	}
+	if len(eth) != 1 || eth[0] != "ETHUSDT" {
+		t.Errorf("ETH-USDT 订阅者应只收到 ETH 更新, 实际 %v", eth)
+	}
+
+	// 停止后重新订阅会再次建立连接
+	fanout.reset()
+	if err := fanout.subscribe(ctx, "BTCUSDT", record(&btc), start); err != nil {
+		t.Fatal(err)
+	}
+	if starts != 2 {
+		t.Errorf("重置后应重新启动订单流, 实际启动 %d 次", starts)
+	}
+}
+
+func TestOrderStreamFanoutStartError(t *testing.T) {
+	var fanout orderStreamFanout
+	failing := func(ctx context.Context, callback func(interface{})) error {
+		return errors.New("连接失败")
+	}
+	if err := fanout.subscribe(context.Background(), "BTCUSDT", func(interface{}) {}, failing); err == nil {
+		t.Fatal("底层订单流启动失败时应返回错误")
+	}
+	if fanout.started || len(fanout.subscribers["BTCUSDT"]) != 0 {
+		t.Error("启动失败后不应保留订阅者")
+	}
+}
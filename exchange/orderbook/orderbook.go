@@ -0,0 +1,227 @@
+// Package orderbook 本地 L2 订单簿
+// 交易所推送的增量深度（价位 -> 数量，数量为 0 表示删除该价位）按序列号应用到 REST 快照上，
+// 序列号不连续时返回 ErrSequenceGap，由调用方重新拉取快照。
+// 独立成包是为了让 exchange 与各交易所子包共用同一份订单簿类型而不产生循环依赖。
+package orderbook
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrSequenceGap 增量更新的序列号不连续（丢包），需要重新同步快照
+var ErrSequenceGap = errors.New("订单簿序列号不连续")
+
+// Level 盘口价位
+type Level struct {
+	Price    float64
+	Quantity float64
+}
+
+// Snapshot 订单簿快照：买盘按价格从高到低，卖盘按价格从低到高
+type Snapshot struct {
+	Symbol   string
+	Bids     []Level
+	Asks     []Level
+	UpdateID int64     // 交易所序列号（最后一次更新）
+	Time     time.Time // 本地更新时间
+}
+
+// BestBid 买一价，无买盘时返回 0
+func (s *Snapshot) BestBid() float64 {
+	if s == nil || len(s.Bids) == 0 {
+		return 0
+	}
+	return s.Bids[0].Price
+}
+
+// BestAsk 卖一价，无卖盘时返回 0
+func (s *Snapshot) BestAsk() float64 {
+	if s == nil || len(s.Asks) == 0 {
+		return 0
+	}
+	return s.Asks[0].Price
+}
+
+// Mid 中间价，买卖盘任一侧为空时返回 0
+func (s *Snapshot) Mid() float64 {
+	bid, ask := s.BestBid(), s.BestAsk()
+	if bid <= 0 || ask <= 0 {
+		return 0
+	}
+	return (bid + ask) / 2
+}
+
+// Ticker 盘口最优价（买一/卖一），由 bookTicker 类推送提供
+type Ticker struct {
+	Symbol   string
+	BidPrice float64
+	BidQty   float64
+	AskPrice float64
+	AskQty   float64
+	Time     int64 // 交易所时间（毫秒），未提供时为本地时间
+}
+
+// Mid 中间价，买卖价任一侧无效时返回 0
+func (t *Ticker) Mid() float64 {
+	if t == nil || t.BidPrice <= 0 || t.AskPrice <= 0 {
+		return 0
+	}
+	return (t.BidPrice + t.AskPrice) / 2
+}
+
+// Diff 一次增量更新，覆盖序列号区间 [FirstID, LastID]
+type Diff struct {
+	FirstID int64
+	LastID  int64
+	// PrevLastID 上一次更新的 LastID（Binance 合约的 pu），
+	// 为 0 时按 FirstID == 上一次 LastID + 1 校验连续性
+	PrevLastID int64
+	Bids       []Level
+	Asks       []Level
+}
+
+// Book 本地维护的订单簿（快照 + 增量），并发安全
+type Book struct {
+	symbol string
+
+	mu       sync.RWMutex
+	bids     map[float64]float64
+	asks     map[float64]float64
+	updateID int64
+	synced   bool // 已加载快照
+	first    bool // 快照之后尚未应用过增量
+	updated  time.Time
+}
+
+// NewBook 创建空订单簿，需先调用 Reset 加载快照
+func NewBook(symbol string) *Book {
+	return &Book{
+		symbol: symbol,
+		bids:   make(map[float64]float64),
+		asks:   make(map[float64]float64),
+	}
+}
+
+// Reset 用快照重建订单簿，updateID 为快照对应的序列号
+func (b *Book) Reset(bids, asks []Level, updateID int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.bids = make(map[float64]float64, len(bids))
+	b.asks = make(map[float64]float64, len(asks))
+	setLevels(b.bids, bids)
+	setLevels(b.asks, asks)
+	b.updateID = updateID
+	b.synced = true
+	b.first = true
+	b.updated = time.Now()
+}
+
+// Synced 是否已加载快照（序列号断档后为 false，需重新 Reset）
+func (b *Book) Synced() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.synced
+}
+
+// ApplyDiff 按序列号应用增量更新
+// 早于快照的更新直接丢弃；快照后的第一条更新必须覆盖快照序列号，
+// 之后每条更新必须与上一条首尾相接，否则返回 ErrSequenceGap 并标记为未同步
+func (b *Book) ApplyDiff(d *Diff) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.synced {
+		return fmt.Errorf("%w: 尚未加载快照", ErrSequenceGap)
+	}
+	if d.LastID < b.updateID {
+		return nil
+	}
+
+	if b.first {
+		if d.FirstID > b.updateID+1 {
+			b.synced = false
+			return fmt.Errorf("%w: 快照 %d, 首条更新 [%d, %d]", ErrSequenceGap, b.updateID, d.FirstID, d.LastID)
+		}
+	} else if d.PrevLastID != 0 {
+		if d.PrevLastID != b.updateID {
+			b.synced = false
+			return fmt.Errorf("%w: 期望 pu=%d, 实际 %d", ErrSequenceGap, b.updateID, d.PrevLastID)
+		}
+	} else if d.FirstID != b.updateID+1 {
+		b.synced = false
+		return fmt.Errorf("%w: 期望 U=%d, 实际 %d", ErrSequenceGap, b.updateID+1, d.FirstID)
+	}
+
+	setLevels(b.bids, d.Bids)
+	setLevels(b.asks, d.Asks)
+	b.updateID = d.LastID
+	b.first = false
+	b.updated = time.Now()
+	return nil
+}
+
+// Snapshot 获取前 depth 档快照（depth <= 0 时返回全部价位）
+func (b *Book) Snapshot(depth int) *Snapshot {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return &Snapshot{
+		Symbol:   b.symbol,
+		Bids:     topLevels(b.bids, depth, true),
+		Asks:     topLevels(b.asks, depth, false),
+		UpdateID: b.updateID,
+		Time:     b.updated,
+	}
+}
+
+// setLevels 更新价位，数量为 0 时删除
+func setLevels(side map[float64]float64, levels []Level) {
+	for _, l := range levels {
+		if l.Quantity == 0 {
+			delete(side, l.Price)
+		} else {
+			side[l.Price] = l.Quantity
+		}
+	}
+}
+
+// topLevels 按价格排序取前 depth 档，desc 为 true 时从高到低（买盘）
+func topLevels(side map[float64]float64, depth int, desc bool) []Level {
+	levels := make([]Level, 0, len(side))
+	for price, qty := range side {
+		levels = append(levels, Level{Price: price, Quantity: qty})
+	}
+	sort.Slice(levels, func(i, j int) bool {
+		if desc {
+			return levels[i].Price > levels[j].Price
+		}
+		return levels[i].Price < levels[j].Price
+	})
+	if depth > 0 && len(levels) > depth {
+		levels = levels[:depth]
+	}
+	return levels
+}
+
+// ParseLevels 解析 [["价格","数量"], ...] 格式的价位（Binance、Bitget、Bybit 等），忽略无法解析的价位
+func ParseLevels(raw [][]string) []Level {
+	levels := make([]Level, 0, len(raw))
+	for _, item := range raw {
+		if len(item) < 2 {
+			continue
+		}
+		price, err1 := strconv.ParseFloat(item[0], 64)
+		qty, err2 := strconv.ParseFloat(item[1], 64)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		levels = append(levels, Level{Price: price, Quantity: qty})
+	}
+	return levels
+}
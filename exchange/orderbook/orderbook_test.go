@@ -0,0 +1,76 @@
+package orderbook
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBookApplyDiff(t *testing.T) {
+	book := NewBook("BTCUSDT")
+	if err := book.ApplyDiff(&Diff{FirstID: 1, LastID: 2}); !errors.Is(err, ErrSequenceGap) {
+		t.Fatalf("未加载快照时 err = %v, want ErrSequenceGap", err)
+	}
+
+	book.Reset([]Level{{100, 1}, {99, 2}}, []Level{{101, 1}, {102, 2}}, 10)
+
+	// 早于快照的更新直接丢弃
+	if err := book.ApplyDiff(&Diff{FirstID: 5, LastID: 9, Bids: []Level{{100, 5}}}); err != nil {
+		t.Fatal(err)
+	}
+	if snap := book.Snapshot(0); snap.Bids[0].Quantity != 1 {
+		t.Errorf("早于快照的更新不应生效: %+v", snap.Bids[0])
+	}
+
+	// 首条更新覆盖快照序列号，删除买一并新增卖一
+	if err := book.ApplyDiff(&Diff{FirstID: 8, LastID: 12, Bids: []Level{{100, 0}}, Asks: []Level{{100.5, 3}}}); err != nil {
+		t.Fatal(err)
+	}
+	snap := book.Snapshot(1)
+	if snap.BestBid() != 99 || snap.BestAsk() != 100.5 || len(snap.Asks) != 1 || snap.UpdateID != 12 {
+		t.Errorf("snapshot = %+v", snap)
+	}
+	if mid := snap.Mid(); mid != 99.75 {
+		t.Errorf("mid = %v, want 99.75", mid)
+	}
+
+	// 连续更新（U == 上一条 u + 1）
+	if err := book.ApplyDiff(&Diff{FirstID: 13, LastID: 15}); err != nil {
+		t.Fatal(err)
+	}
+	// 断档后标记为未同步
+	if err := book.ApplyDiff(&Diff{FirstID: 17, LastID: 18}); !errors.Is(err, ErrSequenceGap) {
+		t.Fatalf("err = %v, want ErrSequenceGap", err)
+	}
+	if book.Synced() {
+		t.Error("断档后应标记为未同步")
+	}
+}
+
+func TestBookApplyDiffPrevLastID(t *testing.T) {
+	book := NewBook("BTCUSDT")
+	book.Reset(nil, nil, 100)
+
+	// 合约推送按 pu 校验连续性，U 可以不等于上一条 u + 1
+	if err := book.ApplyDiff(&Diff{FirstID: 95, LastID: 110, PrevLastID: 90}); err != nil {
+		t.Fatal(err)
+	}
+	if err := book.ApplyDiff(&Diff{FirstID: 115, LastID: 120, PrevLastID: 110}); err != nil {
+		t.Fatal(err)
+	}
+	if err := book.ApplyDiff(&Diff{FirstID: 125, LastID: 130, PrevLastID: 121}); !errors.Is(err, ErrSequenceGap) {
+		t.Fatalf("err = %v, want ErrSequenceGap", err)
+	}
+
+	// 首条更新与快照之间有缺口
+	book.Reset(nil, nil, 100)
+	if err := book.ApplyDiff(&Diff{FirstID: 105, LastID: 110}); !errors.Is(err, ErrSequenceGap) {
+		t.Fatalf("err = %v, want ErrSequenceGap", err)
+	}
+}
+
+func TestSnapshotNil(t *testing.T) {
+	var snap *Snapshot
+	if snap.BestBid() != 0 || snap.BestAsk() != 0 || snap.Mid() != 0 {
+		t.Error("nil 快照应返回 0")
+	}
+}
@@ -0,0 +1,331 @@
+package exchange
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"opensqt/logger"
+)
+
+// RequestPriority 请求优先级，额度紧张时低优先级请求先让路
+type RequestPriority int
+
+const (
+	PriorityOrder      RequestPriority = iota // 下单、撤单、改单
+	PriorityAccount                           // 账户、持仓、订单查询（对账、风控）
+	PriorityMarketData                        // 行情、K线、资金费率、合约信息
+)
+
+// priorityShare 各优先级可使用的额度比例，剩余部分留给更高优先级的请求
+var priorityShare = [...]float64{
+	PriorityOrder:      1.0,
+	PriorityAccount:    0.8,
+	PriorityMarketData: 0.6,
+}
+
+const (
+	// minRateBackoff 被限频（429）时的初始暂停时间，连续被限频时翻倍
+	minRateBackoff = time.Second
+	// maxRateBackoff 暂停时间上限
+	maxRateBackoff = 2 * time.Minute
+	// banBackoff 被封禁（418）且交易所未给出 Retry-After 时的暂停时间
+	banBackoff = 2 * time.Minute
+)
+
+// venueLimits 交易所的请求额度：window 时间窗口内权重之和不超过 limit
+type venueLimits struct {
+	window time.Duration
+	limit  int
+	// weight 接口权重，n 为接口相关的规模参数（K线条数、查询的交易对数等）
+	weight func(op string, n int) int
+}
+
+// defaultLimits 未单独配置的交易所：按次数计，每秒 20 次
+var defaultLimits = venueLimits{window: time.Second, limit: 20}
+
+// limitsByVenue 各交易所的请求额度（取官方限额并留出余量）
+var limitsByVenue = map[string]venueLimits{
+	// Binance U本位合约：IP 每分钟 2400 权重，响应头 X-MBX-USED-WEIGHT-1M 返回已用权重
+	"binance": {window: time.Minute, limit: 2400, weight: binanceWeight},
+	// Bitget：大部分私有接口每个 UID 每秒 10~20 次
+	"bitget": {window: time.Second, limit: 20},
+	// Gate.io：合约私有接口每 10 秒 200 次
+	"gate": {window: 10 * time.Second, limit: 150},
+	// Bybit：下单类接口每个 UID 每秒 10~20 次
+	"bybit": {window: time.Second, limit: 20},
+	// OKX：下单每 2 秒 60 次，账户类接口每 2 秒 10~20 次
+	"okx": {window: 2 * time.Second, limit: 40},
+	// Hyperliquid：IP 每分钟 1200 权重，交易请求权重 1，大部分 info 请求权重 20
+	"hyperliquid": {window: time.Minute, limit: 1200, weight: hyperliquidWeight},
+}
+
+// binanceWeight Binance U本位合约接口权重
+func binanceWeight(op string, n int) int {
+	switch op {
+	case "BatchPlaceOrders":
+		return 5
+	case "GetOpenOrders":
+		if n == 0 { // 不带交易对查询全部挂单
+			return 40
+		}
+		return 1
+	case "GetAccount", "GetPositions", "GetBalance":
+		return 5
+	case "GetFundingPayments":
+		return 30
+	case "GetMyTrades":
+		return 5
+	case "GetOrderBook":
+		switch {
+		case n <= 50:
+			return 2
+		case n <= 100:
+			return 5
+		case n <= 500:
+			return 10
+		default:
+			return 20
+		}
+	case "GetHistoricalKlines":
+		switch {
+		case n < 100:
+			return 1
+		case n < 500:
+			return 2
+		case n <= 1000:
+			return 5
+		default:
+			return 10
+		}
+	default:
+		return 1
+	}
+}
+
+// hyperliquidWeight Hyperliquid 请求权重（交易请求 1，info 请求大多为 20，部分为 2）
+func hyperliquidWeight(op string, n int) int {
+	switch op {
+	case "PlaceOrder", "BatchPlaceOrders", "CancelOrder", "BatchCancelOrders", "AmendOrder", "CancelAllOrders",
+		"SetLeverage", "SetMarginType":
+		return 1
+	case "GetAccount", "GetPositions", "GetBalance", "GetOrder", "GetMarkPrice":
+		return 2
+	default:
+		return 20
+	}
+}
+
+// rateEntry 窗口内的一次请求
+type rateEntry struct {
+	at     time.Time
+	weight int
+}
+
+// RateGovernor 账户级请求额度管理
+// 同一交易所同一 API Key 的所有请求（下单、对账、清理、监控、风控）共用一份额度，
+// 按优先级分配：行情轮询最多使用 60%，账户查询 80%，下单与撤单可用满额度；
+// 额度不足时高优先级的请求先排队，排队期间低优先级请求即使未达到比例上限也让路；
+// 交易所返回 429/418 时暂停全部请求直到 Retry-After，避免 IP 被封禁
+type RateGovernor struct {
+	name   string // 日志前缀中的交易所名称
+	limits venueLimits
+	now    func() time.Time // 测试时可替换
+
+	mu           sync.Mutex
+	entries      []rateEntry // 窗口内的请求（按时间排序）
+	serverUsed   int         // 交易所响应头报告的已用权重
+	serverUsedAt time.Time
+	blockedUntil time.Time // 被限频后的暂停截止时间
+	strikes      int       // 连续被限频次数（决定暂停时长）
+	lastLimited  time.Time
+	waiting      [len(priorityShare)]int // 各优先级正在排队等待额度的请求数
+}
+
+// NewRateGovernor 创建请求额度管理器，venue 为交易所配置名（如 binance）
+func NewRateGovernor(venue string) *RateGovernor {
+	limits, ok := limitsByVenue[venue]
+	if !ok {
+		limits = defaultLimits
+	}
+	return &RateGovernor{name: venue, limits: limits, now: time.Now}
+}
+
+var (
+	governorsMu sync.Mutex
+	governors   = make(map[string]*RateGovernor)
+)
+
+// governorFor 获取账户的请求额度管理器，同一交易所同一 API Key 共用一个
+func governorFor(venue, apiKey string) *RateGovernor {
+	governorsMu.Lock()
+	defer governorsMu.Unlock()
+
+	key := venue + "|" + apiKey
+	g, ok := governors[key]
+	if !ok {
+		g = NewRateGovernor(venue)
+		governors[key] = g
+	}
+	return g
+}
+
+// Weight 接口权重
+func (g *RateGovernor) Weight(op string, n int) int {
+	if g.limits.weight == nil {
+		return 1
+	}
+	return g.limits.weight(op, n)
+}
+
+// opPriority 接口所属的优先级
+func opPriority(op string) RequestPriority {
+	switch op {
+	case "PlaceOrder", "BatchPlaceOrders", "CancelOrder", "BatchCancelOrders", "AmendOrder", "CancelAllOrders":
+		return PriorityOrder
+	case "GetOrder", "GetOpenOrders", "GetMyTrades", "GetAccount", "GetPositions", "GetBalance", "GetFundingPayments",
+		"SetLeverage", "SetMarginType", "SetPositionMode":
+		return PriorityAccount
+	default:
+		return PriorityMarketData
+	}
+}
+
+// WaitRequest 按接口的优先级与权重等待额度，n 为接口的规模参数（见 venueLimits.weight）
+// 交易所包装层与适配器内部额外发出的 REST 请求（深度快照重同步、改单前查单等）都经此等待
+func (g *RateGovernor) WaitRequest(ctx context.Context, op string, n int) error {
+	return g.Wait(ctx, opPriority(op), g.Weight(op, n))
+}
+
+// Wait 等待额度后占用 weight，ctx 取消时返回错误
+func (g *RateGovernor) Wait(ctx context.Context, priority RequestPriority, weight int) error {
+	queued := false
+	defer func() {
+		if queued {
+			g.mu.Lock()
+			g.waiting[priority]--
+			g.mu.Unlock()
+		}
+	}()
+
+	for {
+		g.mu.Lock()
+		delay := g.reserveLocked(priority, weight)
+		if delay > 0 && !queued {
+			queued = true
+			g.waiting[priority]++
+		}
+		g.mu.Unlock()
+		if delay <= 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// reserveLocked 额度足够时占用并返回 0，否则返回需要等待的时间，调用方需持有锁
+func (g *RateGovernor) reserveLocked(priority RequestPriority, weight int) time.Duration {
+	now := g.now()
+	if now.Before(g.blockedUntil) {
+		return g.blockedUntil.Sub(now)
+	}
+
+	window := g.limits.window
+	cutoff := now.Add(-window)
+	i := 0
+	for i < len(g.entries) && !g.entries[i].at.After(cutoff) {
+		i++
+	}
+	g.entries = g.entries[i:]
+
+	used := 0
+	for _, e := range g.entries {
+		used += e.weight
+	}
+	// 交易所统计的是固定窗口内的权重，包含本进程之外（同 IP 其他程序）的请求
+	serverFresh := g.serverUsedAt.Truncate(window).Equal(now.Truncate(window))
+	if serverFresh && g.serverUsed > used {
+		used = g.serverUsed
+	}
+
+	if weight > g.limits.limit {
+		weight = g.limits.limit
+	}
+	// 更高优先级的请求在排队时不插队，额度恢复后先满足它们
+	yield := false
+	for p := PriorityOrder; p < priority; p++ {
+		if g.waiting[p] > 0 {
+			yield = true
+		}
+	}
+	allowed := int(float64(g.limits.limit) * priorityShare[priority])
+	if !yield && used+weight <= allowed {
+		g.entries = append(g.entries, rateEntry{at: now, weight: weight})
+		if serverFresh {
+			g.serverUsed += weight
+		}
+		return 0
+	}
+
+	// 等最早的请求移出窗口；本地记录不足以解释已用权重时等到下一个固定窗口
+	if len(g.entries) > 0 {
+		if wait := g.entries[0].at.Add(window).Sub(now); wait > 0 {
+			return wait
+		}
+	}
+	return now.Truncate(window).Add(window).Sub(now)
+}
+
+// ObserveUsedWeight 记录交易所响应头报告的当前窗口已用权重
+func (g *RateGovernor) ObserveUsedWeight(used int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.serverUsed = used
+	g.serverUsedAt = g.now()
+}
+
+// ObserveRateLimited 交易所返回 429（banned=false）或 418（banned=true）时暂停全部请求
+// retryAfter 为交易所给出的等待时间，<= 0 时按连续被限频次数退避
+func (g *RateGovernor) ObserveRateLimited(retryAfter time.Duration, banned bool) {
+	g.mu.Lock()
+	now := g.now()
+	if now.Sub(g.lastLimited) > g.limits.window+maxRateBackoff {
+		g.strikes = 0
+	}
+	g.strikes++
+	g.lastLimited = now
+
+	backoff := retryAfter
+	if backoff <= 0 {
+		if banned {
+			backoff = banBackoff
+		} else {
+			backoff = minRateBackoff << (g.strikes - 1)
+			if backoff > maxRateBackoff || backoff <= 0 {
+				backoff = maxRateBackoff
+			}
+		}
+	}
+	until := now.Add(backoff)
+	extended := until.After(g.blockedUntil)
+	if extended {
+		g.blockedUntil = until
+	}
+	g.mu.Unlock()
+
+	if !extended {
+		return
+	}
+	if banned {
+		logger.Error("🔥 [%s 限频] IP 已被交易所封禁，暂停所有请求 %v", g.name, backoff.Round(time.Second))
+	} else {
+		logger.Warn("⚠️ [%s 限频] 触发速率限制，暂停所有请求 %v", g.name, backoff.Round(time.Millisecond))
+	}
+}
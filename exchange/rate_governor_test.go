@@ -0,0 +1,230 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"opensqt/exchange/errs"
+)
+
+// newTestGovernor 创建使用固定时钟的额度管理器：每秒 10 权重
+func newTestGovernor(now *time.Time) *RateGovernor {
+	g := NewRateGovernor("test")
+	g.limits = venueLimits{window: time.Second, limit: 10}
+	g.now = func() time.Time { return *now }
+	return g
+}
+
+func TestRateGovernorPriorityShare(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	g := newTestGovernor(&now)
+
+	// 行情请求最多使用 60% 额度
+	for i := 0; i < 6; i++ {
+		if d := g.reserveLocked(PriorityMarketData, 1); d != 0 {
+			t.Fatalf("第%d个行情请求不应等待, got %v", i+1, d)
+		}
+	}
+	if d := g.reserveLocked(PriorityMarketData, 1); d <= 0 {
+		t.Errorf("行情请求超过 60%% 额度后应等待")
+	}
+	// 账户查询可用到 80%，下单可用满额度
+	for i := 0; i < 2; i++ {
+		if d := g.reserveLocked(PriorityAccount, 1); d != 0 {
+			t.Fatalf("账户查询不应等待, got %v", d)
+		}
+	}
+	if d := g.reserveLocked(PriorityAccount, 1); d <= 0 {
+		t.Errorf("账户查询超过 80%% 额度后应等待")
+	}
+	for i := 0; i < 2; i++ {
+		if d := g.reserveLocked(PriorityOrder, 1); d != 0 {
+			t.Fatalf("下单应使用预留额度, got %v", d)
+		}
+	}
+	if d := g.reserveLocked(PriorityOrder, 1); d != time.Second {
+		t.Errorf("额度用尽后应等最早的请求移出窗口, got %v", d)
+	}
+
+	// 窗口滑过后额度恢复
+	now = now.Add(time.Second)
+	if d := g.reserveLocked(PriorityMarketData, 1); d != 0 {
+		t.Errorf("窗口滑过后不应等待, got %v", d)
+	}
+}
+
+func TestRateGovernorServerWeight(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	g := newTestGovernor(&now)
+
+	// 响应头报告的已用权重（含同 IP 其他程序）高于本地记录时以交易所为准
+	g.ObserveUsedWeight(7)
+	if d := g.reserveLocked(PriorityMarketData, 1); d <= 0 {
+		t.Errorf("交易所已用权重超过行情额度时应等待")
+	}
+	if d := g.reserveLocked(PriorityOrder, 1); d != 0 {
+		t.Errorf("下单不应等待, got %v", d)
+	}
+
+	// 下一个窗口不再参考上一窗口的已用权重
+	now = now.Add(time.Second)
+	if d := g.reserveLocked(PriorityMarketData, 1); d != 0 {
+		t.Errorf("新窗口不应等待, got %v", d)
+	}
+}
+
+func TestRateGovernorBackoff(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	g := newTestGovernor(&now)
+
+	// 429 未给出 Retry-After：按连续次数翻倍退避，下单也暂停
+	g.ObserveRateLimited(0, false)
+	if d := g.reserveLocked(PriorityOrder, 1); d != minRateBackoff {
+		t.Errorf("首次限频应暂停 %v, got %v", minRateBackoff, d)
+	}
+	now = now.Add(minRateBackoff)
+	g.ObserveRateLimited(0, false)
+	if d := g.reserveLocked(PriorityOrder, 1); d != 2*minRateBackoff {
+		t.Errorf("连续限频应翻倍暂停, got %v", d)
+	}
+
+	// 418 按 Retry-After 暂停
+	g.ObserveRateLimited(30*time.Second, true)
+	if d := g.reserveLocked(PriorityOrder, 1); d != 30*time.Second {
+		t.Errorf("封禁应按 Retry-After 暂停, got %v", d)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := g.Wait(ctx, PriorityOrder, 1); !errors.Is(err, context.Canceled) {
+		t.Errorf("暂停期间 ctx 取消应返回错误, got %v", err)
+	}
+}
+
+func TestRateGovernorHigherPriorityFirst(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	g := newTestGovernor(&now)
+	for i := 0; i < 10; i++ {
+		g.reserveLocked(PriorityOrder, 1)
+	}
+
+	// 额度用尽时下单排队；ctx 超时返回后不再占位
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	queued := make(chan error)
+	go func() { queued <- g.Wait(ctx, PriorityOrder, 1) }()
+	for i := 0; i < 100; i++ {
+		g.mu.Lock()
+		n := g.waiting[PriorityOrder]
+		g.mu.Unlock()
+		if n == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// 窗口滑过后额度恢复，但下单仍在排队：行情请求让路，下单直接获得额度
+	g.mu.Lock()
+	now = now.Add(time.Second)
+	if d := g.reserveLocked(PriorityMarketData, 1); d <= 0 {
+		t.Error("高优先级请求排队时低优先级请求应让路")
+	}
+	if d := g.reserveLocked(PriorityOrder, 1); d != 0 {
+		t.Errorf("同优先级请求不应让路, got %v", d)
+	}
+	g.mu.Unlock()
+
+	if err := <-queued; !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want DeadlineExceeded", err)
+	}
+	if d := g.reserveLocked(PriorityMarketData, 1); d != 0 {
+		t.Errorf("排队的请求返回后行情请求不应再让路, got %v", d)
+	}
+}
+
+// batchRateLimitedExchange 批量下单时逐单报告限频错误
+type batchRateLimitedExchange struct {
+	IExchange
+}
+
+func (e *batchRateLimitedExchange) BatchPlaceOrders(ctx context.Context, orders []*OrderRequest) ([]*Order, bool) {
+	errs.Report(ctx, errs.WithRetryAfter(errs.Wrap(ErrRateLimited, "test", "429", errors.New("too many requests")), 5*time.Second))
+	return nil, false
+}
+
+func TestGovernedBatchPlaceOrdersObservesRateLimit(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	g := newTestGovernor(&now)
+	ex := newGovernedExchange(&batchRateLimitedExchange{}, g)
+
+	ex.BatchPlaceOrders(context.Background(), []*OrderRequest{{Symbol: "ETHUSDT"}})
+	if d := g.reserveLocked(PriorityOrder, 1); d != 5*time.Second {
+		t.Errorf("批量下单中的限频错误应按 Retry-After 暂停, got %v", d)
+	}
+}
+
+func TestBinanceWeight(t *testing.T) {
+	g := NewRateGovernor("binance")
+	cases := []struct {
+		op   string
+		n    int
+		want int
+	}{
+		{"PlaceOrder", 1, 1},
+		{"GetOpenOrders", 1, 1},
+		{"GetOpenOrders", 0, 40},
+		{"GetAccount", 1, 5},
+		{"GetHistoricalKlines", 99, 1},
+		{"GetHistoricalKlines", 500, 5},
+		{"GetHistoricalKlines", 1500, 10},
+	}
+	for _, c := range cases {
+		if got := g.Weight(c.op, c.n); got != c.want {
+			t.Errorf("Weight(%s, %d) = %d, want %d", c.op, c.n, got, c.want)
+		}
+	}
+}
+
+// pagedKlineExchange 按单页返回K线，记录每页查询的时间窗口
+type pagedKlineExchange struct {
+	IExchange
+	t     *testing.T
+	pages int
+}
+
+func (e *pagedKlineExchange) Capabilities() Capabilities {
+	return Capabilities{KlinesPerRequest: 2}
+}
+
+func (e *pagedKlineExchange) GetHistoricalKlinesRange(ctx context.Context, symbol string, interval string, start, end time.Time) ([]*Candle, error) {
+	e.t.Error("额度管理层已分页，不应再调用 wrapper 自带分页的区间查询")
+	return nil, nil
+}
+
+func (e *pagedKlineExchange) getKlinesPage(ctx context.Context, symbol string, interval string, from, to time.Time) ([]*Candle, error) {
+	e.pages++
+	var candles []*Candle
+	for ts := from; !ts.After(to); ts = ts.Add(time.Minute) {
+		candles = append(candles, &Candle{Symbol: symbol, Timestamp: ts.UnixMilli()})
+	}
+	return candles, nil
+}
+
+func TestGovernedKlinesRangePaginatesOnce(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	g := newTestGovernor(&now)
+	inner := &pagedKlineExchange{t: t}
+	ex := newGovernedExchange(inner, g)
+
+	start := time.UnixMilli(1700000000000)
+	candles, err := ex.GetHistoricalKlinesRange(context.Background(), "ETHUSDT", "1m", start, start.Add(4*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 5 根K线，每页 2 根：3 页，每页各占一次额度
+	if len(candles) != 5 || inner.pages != 3 || len(g.entries) != 3 {
+		t.Errorf("K线数 = %d, 页数 = %d, 额度占用 = %d", len(candles), inner.pages, len(g.entries))
+	}
+}
@@ -0,0 +1,248 @@
+package exchange
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"opensqt/config"
+)
+
+// multiSymbolVenues 适配器按请求中的交易对下单与查询（按需加载并缓存合约信息）的交易所，
+// 同一账户的多个交易对共用一个交易实例；其余交易所的适配器以构造时的交易对下单，按交易对分别创建
+var multiSymbolVenues = map[string]bool{"binance": true, "bitget": true, "gate": true}
+
+// exchangeRegistry 按账户凭证共用交易所实例
+// 下单、订单流、账户推送与持仓走账户共用的交易实例；行情、K线与精度等按交易对区分的数据走该交易对的行情实例
+// （交易实例的默认交易对直接使用交易实例，其他交易对另建实例，只用于行情，不建立私有频道连接）
+type exchangeRegistry struct {
+	mu       sync.Mutex
+	build    func(cfg *config.Config, symbol string) (IExchange, error)
+	accounts map[string]*sharedAccount
+}
+
+var defaultRegistry = newExchangeRegistry(newExchange)
+
+func newExchangeRegistry(build func(cfg *config.Config, symbol string) (IExchange, error)) *exchangeRegistry {
+	return &exchangeRegistry{build: build, accounts: make(map[string]*sharedAccount)}
+}
+
+// sharedAccount 同一账户凭证共用的交易实例
+type sharedAccount struct {
+	trading IExchange
+	markets map[string]IExchange // 规范化交易对 -> 行情实例
+
+	mu               sync.Mutex
+	orderStreamRefs  int                     // 订阅了订单流且尚未停止的视图数量
+	accountCallbacks []AccountUpdateCallback // 账户推送订阅者（共用一条账户推送）
+}
+
+// accountKey 账户凭证键：交易所、市场类型、交易环境与 API Key 相同即为同一账户
+// 单交易对适配器的交易所键中还包含交易对
+func accountKey(cfg *config.Config) string {
+	name := cfg.App.CurrentExchange
+	exchangeCfg := cfg.Exchanges[name]
+	key := strings.Join([]string{name, cfg.Trading.MarketType, exchangeCfg.Environment, exchangeCfg.APIKey}, "|")
+	if !multiSymbolVenues[name] {
+		key += "|" + normalizeSymbol(cfg.Trading.Symbol)
+	}
+	return key
+}
+
+// get 获取配置交易对的交易所视图，账户的首个调用者创建共用的交易实例
+func (r *exchangeRegistry) get(cfg *config.Config) (IExchange, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	symbol := normalizeSymbol(cfg.Trading.Symbol)
+	key := accountKey(cfg)
+	account, ok := r.accounts[key]
+	if !ok {
+		trading, err := r.build(cfg, cfg.Trading.Symbol)
+		if err != nil {
+			return nil, err
+		}
+		account = &sharedAccount{trading: trading, markets: map[string]IExchange{symbol: trading}}
+		r.accounts[key] = account
+	}
+
+	market, ok := account.markets[symbol]
+	if !ok {
+		var err error
+		if market, err = r.build(cfg, cfg.Trading.Symbol); err != nil {
+			return nil, err
+		}
+		account.markets[symbol] = market
+	}
+	return &accountView{IExchange: account.trading, market: market, account: account}, nil
+}
+
+// dispatchAccount 将账户推送分发给所有订阅者
+func (a *sharedAccount) dispatchAccount(update *AccountUpdate) {
+	a.mu.Lock()
+	callbacks := append([]AccountUpdateCallback(nil), a.accountCallbacks...)
+	a.mu.Unlock()
+
+	for _, callback := range callbacks {
+		callback(update)
+	}
+}
+
+// accountView 共用交易实例上某个交易对的视图（每次 NewExchange 返回一个）
+type accountView struct {
+	IExchange           // 账户共用的交易实例
+	market    IExchange // 本交易对的行情实例
+	account   *sharedAccount
+
+	subscriptions []*atomic.Bool // 本视图的订单流订阅，停止后置为 false 不再回调（受 account.mu 保护）
+}
+
+// === 订单流与账户推送：同一账户共用一条连接 ===
+
+func (v *accountView) StartOrderStream(ctx context.Context, callback func(interface{})) error {
+	return v.subscribeOrders(callback, func(callback func(interface{})) error {
+		return v.IExchange.StartOrderStream(ctx, callback)
+	})
+}
+
+func (v *accountView) SubscribeOrderStream(ctx context.Context, symbol string, callback func(interface{})) error {
+	return v.subscribeOrders(callback, func(callback func(interface{})) error {
+		return v.IExchange.SubscribeOrderStream(ctx, symbol, callback)
+	})
+}
+
+// subscribeOrders 通过共用实例订阅订单流，回调在本视图停止订单流后不再触发
+func (v *accountView) subscribeOrders(callback func(interface{}), subscribe func(func(interface{})) error) error {
+	active := new(atomic.Bool)
+	active.Store(true)
+
+	v.account.mu.Lock()
+	defer v.account.mu.Unlock()
+	err := subscribe(func(update interface{}) {
+		if active.Load() {
+			callback(update)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if len(v.subscriptions) == 0 {
+		v.account.orderStreamRefs++
+	}
+	v.subscriptions = append(v.subscriptions, active)
+	return nil
+}
+
+// StopOrderStream 停止本视图的订单流订阅，最后一个订阅者停止时才断开共用的订单流连接
+func (v *accountView) StopOrderStream() error {
+	v.account.mu.Lock()
+	defer v.account.mu.Unlock()
+
+	if len(v.subscriptions) == 0 {
+		return nil
+	}
+	for _, active := range v.subscriptions {
+		active.Store(false)
+	}
+	v.subscriptions = nil
+	v.account.orderStreamRefs--
+	if v.account.orderStreamRefs > 0 {
+		return nil
+	}
+	return v.IExchange.StopOrderStream()
+}
+
+// StartAccountStream 订阅账户推送，同一账户只建立一条推送连接
+func (v *accountView) StartAccountStream(ctx context.Context, callback AccountUpdateCallback) error {
+	v.account.mu.Lock()
+	defer v.account.mu.Unlock()
+
+	// 底层回调在独立协程中触发，dispatchAccount 会等待这里释放锁
+	if len(v.account.accountCallbacks) == 0 {
+		if err := v.IExchange.StartAccountStream(ctx, v.account.dispatchAccount); err != nil {
+			return err
+		}
+	}
+	v.account.accountCallbacks = append(v.account.accountCallbacks, callback)
+	return nil
+}
+
+// === 行情、K线与交易对精度：使用本交易对的行情实例 ===
+
+func (v *accountView) GetLatestPrice(ctx context.Context, symbol string) (float64, error) {
+	return v.market.GetLatestPrice(ctx, symbol)
+}
+
+func (v *accountView) StartPriceStream(ctx context.Context, symbol string, callback func(price float64)) error {
+	return v.market.StartPriceStream(ctx, symbol, callback)
+}
+
+func (v *accountView) GetMarkPrice(ctx context.Context, symbol string) (float64, error) {
+	return v.market.GetMarkPrice(ctx, symbol)
+}
+
+func (v *accountView) StartMarkPriceStream(ctx context.Context, symbol string, callback MarkPriceCallback) error {
+	return v.market.StartMarkPriceStream(ctx, symbol, callback)
+}
+
+func (v *accountView) StartBookTickerStream(ctx context.Context, symbol string, callback BookTickerCallback) error {
+	return v.market.StartBookTickerStream(ctx, symbol, callback)
+}
+
+func (v *accountView) GetOrderBook(ctx context.Context, symbol string, depth int) (*OrderBook, error) {
+	return v.market.GetOrderBook(ctx, symbol, depth)
+}
+
+func (v *accountView) StartDepthStream(ctx context.Context, symbol string, callback OrderBookCallback) error {
+	return v.market.StartDepthStream(ctx, symbol, callback)
+}
+
+func (v *accountView) GetFundingRate(ctx context.Context, symbol string) (*FundingRate, error) {
+	return v.market.GetFundingRate(ctx, symbol)
+}
+
+func (v *accountView) StartKlineStream(ctx context.Context, symbols []string, interval string, callback CandleUpdateCallback) error {
+	return v.market.StartKlineStream(ctx, symbols, interval, callback)
+}
+
+func (v *accountView) RegisterKlineCallback(componentName string, callback func(candle interface{})) error {
+	return v.market.RegisterKlineCallback(componentName, callback)
+}
+
+func (v *accountView) StopKlineStream() error {
+	return v.market.StopKlineStream()
+}
+
+func (v *accountView) ForceReconnectKlineStream() error {
+	return v.market.ForceReconnectKlineStream()
+}
+
+func (v *accountView) GetHistoricalKlines(ctx context.Context, symbol string, interval string, limit int) ([]*Candle, error) {
+	return v.market.GetHistoricalKlines(ctx, symbol, interval, limit)
+}
+
+func (v *accountView) GetHistoricalKlinesRange(ctx context.Context, symbol string, interval string, start, end time.Time) ([]*Candle, error) {
+	return v.market.GetHistoricalKlinesRange(ctx, symbol, interval, start, end)
+}
+
+func (v *accountView) GetSymbolInfo(ctx context.Context, symbol string) (*SymbolInfo, error) {
+	return v.market.GetSymbolInfo(ctx, symbol)
+}
+
+func (v *accountView) GetPriceDecimals() int {
+	return v.market.GetPriceDecimals()
+}
+
+func (v *accountView) GetQuantityDecimals() int {
+	return v.market.GetQuantityDecimals()
+}
+
+func (v *accountView) GetBaseAsset() string {
+	return v.market.GetBaseAsset()
+}
+
+func (v *accountView) GetQuoteAsset() string {
+	return v.market.GetQuoteAsset()
+}
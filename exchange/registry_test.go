@@ -0,0 +1,109 @@
+package exchange
+
+import (
+	"context"
+	"testing"
+
+	"opensqt/config"
+)
+
+// registryExchange 记录构造交易对与订单流启停次数的交易所桩
+type registryExchange struct {
+	IExchange
+	symbol      string
+	fanout      orderStreamFanout
+	push        func(interface{})
+	orderStarts int
+	orderStops  int
+}
+
+func (e *registryExchange) GetBaseAsset() string { return e.symbol[:3] }
+
+func (e *registryExchange) SubscribeOrderStream(ctx context.Context, symbol string, callback func(interface{})) error {
+	return e.fanout.subscribe(ctx, symbol, callback, func(ctx context.Context, callback func(interface{})) error {
+		e.orderStarts++
+		e.push = callback
+		return nil
+	})
+}
+
+func (e *registryExchange) StopOrderStream() error {
+	e.orderStops++
+	e.fanout.reset()
+	return nil
+}
+
+func registryConfig(venue, apiKey, symbol string) *config.Config {
+	cfg := &config.Config{Exchanges: map[string]config.ExchangeConfig{venue: {APIKey: apiKey}}}
+	cfg.App.CurrentExchange = venue
+	cfg.Trading.Symbol = symbol
+	return cfg
+}
+
+func TestRegistrySharesAccountConnection(t *testing.T) {
+	var built []*registryExchange
+	registry := newExchangeRegistry(func(cfg *config.Config, symbol string) (IExchange, error) {
+		ex := &registryExchange{symbol: symbol}
+		built = append(built, ex)
+		return ex, nil
+	})
+
+	btc, _ := registry.get(registryConfig("binance", "key1", "BTCUSDT"))
+	eth, _ := registry.get(registryConfig("binance", "key1", "ETHUSDT"))
+	other, _ := registry.get(registryConfig("binance", "key2", "BTCUSDT"))
+	if _, err := registry.get(registryConfig("binance", "key1", "ETH-USDT")); err != nil {
+		t.Fatal(err)
+	}
+	// key1 的交易实例 + ETH 行情实例 + key2 的交易实例
+	if len(built) != 3 {
+		t.Fatalf("创建实例数 = %d, want 3", len(built))
+	}
+	trading := built[0]
+	if btc.GetBaseAsset() != "BTC" || eth.GetBaseAsset() != "ETH" || other.GetBaseAsset() != "BTC" {
+		t.Errorf("交易对精度与币种应来自本交易对的行情实例")
+	}
+
+	ctx := context.Background()
+	var btcUpdates, ethUpdates int
+	btc.SubscribeOrderStream(ctx, "BTCUSDT", func(interface{}) { btcUpdates++ })
+	eth.SubscribeOrderStream(ctx, "ETHUSDT", func(interface{}) { ethUpdates++ })
+	if trading.orderStarts != 1 || built[1].orderStarts != 0 {
+		t.Fatalf("同一账户应只建立一条订单流: 交易实例 %d 次, 行情实例 %d 次", trading.orderStarts, built[1].orderStarts)
+	}
+
+	trading.push(struct{ Symbol string }{"ETHUSDT"})
+	trading.push(struct{ Symbol string }{"BTCUSDT"})
+	if btcUpdates != 1 || ethUpdates != 1 {
+		t.Errorf("订单更新分发错误: btc=%d eth=%d", btcUpdates, ethUpdates)
+	}
+
+	// 其他网格仍在订阅时不断开共用连接
+	btc.StopOrderStream()
+	if trading.orderStops != 0 {
+		t.Fatal("仍有订阅者时不应停止共用的订单流")
+	}
+	trading.push(struct{ Symbol string }{"BTCUSDT"})
+	if btcUpdates != 1 {
+		t.Error("已停止的视图不应再收到订单更新")
+	}
+	eth.StopOrderStream()
+	if trading.orderStops != 1 {
+		t.Errorf("最后一个订阅者停止时应断开订单流, stops = %d", trading.orderStops)
+	}
+}
+
+// 单交易对适配器的交易所按交易对分别创建实例
+func TestRegistrySingleSymbolVenue(t *testing.T) {
+	builds := 0
+	registry := newExchangeRegistry(func(cfg *config.Config, symbol string) (IExchange, error) {
+		builds++
+		return &registryExchange{symbol: symbol}, nil
+	})
+
+	registry.get(registryConfig("bybit", "key1", "BTCUSDT"))
+	registry.get(registryConfig("bybit", "key1", "BTCUSDT"))
+	registry.get(registryConfig("bybit", "key1", "ETHUSDT"))
+	if builds != 2 {
+		t.Errorf("创建实例数 = %d, want 2", builds)
+	}
+}
@@ -1,6 +1,10 @@
 package exchange
 
-import "time"
+import (
+	"time"
+
+	"opensqt/exchange/orderbook"
+)
 
 // Side 交易方向
 type Side string
@@ -47,11 +51,12 @@ type OrderRequest struct {
 	Type          OrderType
 	TimeInForce   TimeInForce
 	Quantity      float64
-	Price         float64 // 市价单可为0
-	ReduceOnly    bool    // 是否只减仓
-	PostOnly      bool    // 是否只做 Maker（Post Only）
-	PriceDecimals int     // 价格精度（用于格式化）
-	ClientOrderID string  // 自定义订单ID
+	Price         float64      // 市价单可为0
+	ReduceOnly    bool         // 是否只减仓
+	PositionSide  PositionSide // 持仓方向（双向持仓模式下必填，单向持仓为空）
+	PostOnly      bool         // 是否只做 Maker（Post Only）
+	PriceDecimals int          // 价格精度（用于格式化）
+	ClientOrderID string       // 自定义订单ID
 }
 
 // Order 订单信息（通用）
@@ -70,6 +75,30 @@ type Order struct {
 	UpdateTime    int64
 }
 
+// MarginType 保证金模式
+type MarginType string
+
+const (
+	MarginTypeCross    MarginType = "cross"    // 全仓
+	MarginTypeIsolated MarginType = "isolated" // 逐仓
+)
+
+// PositionMode 持仓模式
+type PositionMode string
+
+const (
+	PositionModeOneWay PositionMode = "one_way" // 单向持仓
+	PositionModeHedge  PositionMode = "hedge"   // 双向持仓
+)
+
+// PositionSide 双向持仓模式下的持仓方向
+type PositionSide string
+
+const (
+	PositionSideLong  PositionSide = "LONG"  // 多头方向（开多/平多）
+	PositionSideShort PositionSide = "SHORT" // 空头方向（开空/平空）
+)
+
 // Position 持仓信息（通用）
 type Position struct {
 	Symbol         string
@@ -80,6 +109,7 @@ type Position struct {
 	Leverage       int
 	MarginType     string
 	IsolatedMargin float64
+	Side           PositionSide // 持仓方向（双向持仓模式下为 LONG/SHORT，单向持仓为空）
 }
 
 // Account 账户信息（通用）
@@ -104,11 +134,95 @@ type OrderUpdate struct {
 	ExecutedQty   float64
 	AvgPrice      float64
 	UpdateTime    int64
+	Fill          *FillEvent // 本次更新对应的成交明细，无新成交时为 nil
+}
+
+// FillEvent 单笔成交明细（随订单更新推送）
+// 部分交易所（Gate.io）成交明细单独推送，此时 OrderUpdate.Status 为空，只携带 Fill
+type FillEvent struct {
+	TradeID         string  // 成交ID（用于去重，断线重连后可能重复推送）
+	Price           float64 // 成交价格
+	Quantity        float64 // 成交数量（币数量）
+	Commission      float64 // 手续费（正数为支出，负数为返佣）
+	CommissionAsset string  // 手续费币种
+	IsMaker         bool    // 是否为 Maker 成交
+	Time            int64   // 成交时间（毫秒）
 }
 
 // OrderUpdateCallback 订单更新回调函数
 type OrderUpdateCallback func(update OrderUpdate)
 
+// BalanceUpdate 资产余额变化
+type BalanceUpdate struct {
+	Asset            string  // 资产（如 USDT）
+	WalletBalance    float64 // 钱包余额
+	AvailableBalance float64 // 可用余额（HasAvailable 为 false 时无效）
+	HasAvailable     bool    // 交易所是否推送了可用余额（Binance、Gate.io 只推送钱包余额）
+}
+
+// AccountUpdate 账户推送（余额与持仓变化）
+type AccountUpdate struct {
+	Balances          []BalanceUpdate
+	Positions         []*Position // 发生变化的持仓，Size 为 0 表示已平仓
+	PositionsSnapshot bool        // Positions 是否为全部持仓快照（未出现的交易对视为无持仓）
+	UpdateTime        int64       // 推送时间（毫秒）
+}
+
+// AccountUpdateCallback 账户推送回调函数
+type AccountUpdateCallback func(update *AccountUpdate)
+
+// FundingRate 资金费率与标记价格
+type FundingRate struct {
+	Symbol          string
+	FundingRate     float64 // 当期资金费率（正数表示多头支付空头）
+	MarkPrice       float64 // 标记价格
+	IndexPrice      float64 // 指数价格
+	NextFundingTime int64   // 下次结算时间（毫秒），0 表示未知
+	Time            int64   // 数据时间（毫秒）
+}
+
+// MarkPriceCallback 标记价格推送回调函数（推送同时携带资金费率）
+type MarkPriceCallback func(update *FundingRate)
+
+// PriceLevel 盘口价位，数量与下单数量口径一致（币本位合约为张数，其余为基础币种数量）
+type PriceLevel = orderbook.Level
+
+// OrderBook 订单簿快照（买盘从高到低，卖盘从低到高），BestBid/BestAsk/Mid 可在 nil 上调用
+type OrderBook = orderbook.Snapshot
+
+// OrderBookCallback 订单簿推送回调函数
+type OrderBookCallback func(book *OrderBook)
+
+// BookTicker 盘口最优价推送（买一/卖一价与数量）
+type BookTicker = orderbook.Ticker
+
+// BookTickerCallback 盘口最优价推送回调函数
+type BookTickerCallback func(ticker *BookTicker)
+
+// FundingPayment 资金费收付记录
+type FundingPayment struct {
+	ID     string  // 记录ID（用于去重）
+	Symbol string  // 交易对
+	Amount float64 // 金额（正数为收入，负数为支出）
+	Asset  string  // 结算币种
+	Time   int64   // 结算时间（毫秒）
+}
+
+// Trade 账户成交记录
+type Trade struct {
+	TradeID         string  // 成交ID（与订单推送 FillEvent.TradeID 一致，用于去重）
+	OrderID         int64   // 订单ID
+	ClientOrderID   string  // 自定义订单ID（Binance、Bitget 成交记录不返回，为空）
+	Symbol          string  // 交易对
+	Side            Side    // 成交方向
+	Price           float64 // 成交价格
+	Quantity        float64 // 成交数量（与订单数量口径一致）
+	Commission      float64 // 手续费（正数为支出，负数为返佣）
+	CommissionAsset string  // 手续费币种
+	IsMaker         bool    // 是否为 Maker 成交
+	Time            int64   // 成交时间（毫秒）
+}
+
 // Candle K线数据
 type Candle struct {
 	Symbol    string
@@ -123,3 +237,51 @@ type Candle struct {
 
 // CandleUpdateCallback K线更新回调函数
 type CandleUpdateCallback func(candle *Candle)
+
+// SymbolInfo 合约交易规则
+// 数量口径与 OrderRequest.Quantity 一致（币数量），按张下单的交易所已用合约乘数换算；
+// 币本位合约（ContractValue > 0）的数量口径为张数
+type SymbolInfo struct {
+	Symbol             string
+	BaseAsset          string
+	QuoteAsset         string
+	TickSize           float64 // 价格最小变动单位
+	StepSize           float64 // 数量最小变动单位
+	MinQty             float64 // 最小下单数量
+	MinNotional        float64 // 最小名义价值（计价币种），0 表示交易所无此限制
+	ContractMultiplier float64 // 合约乘数（每张合约对应的币数量），按币下单的交易所为 1
+	ContractValue      float64 // 币本位合约面值（每张合约对应的计价币种金额，如 BTCUSD_PERP 为 100 USD），此时数量口径为张数；U本位与现货为 0
+	MaxLeverage        int     // 最大杠杆倍数，0 表示未知
+	PriceDecimals      int     // 价格小数位数（由 TickSize 推导）
+	QuantityDecimals   int     // 数量小数位数（由 StepSize 推导）
+}
+
+// PostOnlyStyle 交易所表达 PostOnly（只做 Maker）的方式
+type PostOnlyStyle string
+
+const (
+	PostOnlyNone        PostOnlyStyle = ""              // 不支持 PostOnly
+	PostOnlyTimeInForce PostOnlyStyle = "TIME_IN_FORCE" // 通过有效期参数指定（Binance GTX、Gate poc、Bitget force、Bybit/edgeX POST_ONLY、Hyperliquid Alo）
+	PostOnlyOrderType   PostOnlyStyle = "ORDER_TYPE"    // 通过订单类型指定（OKX ordType=post_only、Binance 现货 LIMIT_MAKER）
+)
+
+// Capabilities 交易所能力描述
+// 执行器、仓位管理器、订单清理器根据能力选择策略，而不是按交易所名称硬编码
+type Capabilities struct {
+	NativeBatchSize       int           // 原生批量下单单次最大数量，0 表示不支持（逐单下单）
+	NativeBatchCancelSize int           // 原生批量撤单单次最大数量，0 表示不支持（逐单撤单）
+	NativeCancelAll       bool          // 是否支持一键全撤（否则查询挂单后批量撤销）
+	WSOrderEntry          bool          // 是否支持通过 WebSocket 下单
+	AmendOrder            bool          // 是否支持改单
+	AccountStream         bool          // 是否支持账户推送（余额与持仓）
+	DepthStream           bool          // 是否支持深度推送（本地维护订单簿）
+	BookTicker            bool          // 是否支持盘口最优价推送（买一/卖一）
+	KlinesPerRequest      int           // 按时间区间查询K线时单次请求最多返回的根数，0 表示不支持区间查询
+	MyTrades              bool          // 是否支持查询账户成交记录（断线期间遗漏的成交可据此补录）
+	HedgeMode             bool          // 是否支持双向持仓模式下单
+	AccountSettings       bool          // 是否支持通过 API 设置杠杆、保证金模式与持仓模式（否则启动时沿用交易所当前设置）
+	Spot                  bool          // 是否为现货市场（无杠杆与持仓，持仓为基础币种钱包余额）
+	Inverse               bool          // 是否为币本位（反向）合约：按张下单，保证金、余额与盈亏以基础币种计
+	PostOnlyStyle         PostOnlyStyle // PostOnly 的表达方式
+	MaxClientOrderIDLen   int           // 自定义订单ID最大长度（已扣除返佣前缀），0 表示不支持自定义ID
+}
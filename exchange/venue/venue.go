@@ -0,0 +1,127 @@
+// Package venue Bybit、OKX、edgeX、Hyperliquid 适配器共用的订单、持仓与行情类型
+// 字段与 exchange/types.go 中的通用类型一一对应，exchange 包用同一组转换函数包装这些适配器。
+// 独立成包是为了让各交易所子包共用同一份类型而不与 exchange 包产生循环依赖。
+package venue
+
+import "time"
+
+type Side string
+type OrderType string
+type OrderStatus string
+type TimeInForce string
+
+const (
+	SideBuy  Side = "BUY"
+	SideSell Side = "SELL"
+)
+
+const (
+	OrderTypeLimit  OrderType = "LIMIT"
+	OrderTypeMarket OrderType = "MARKET"
+)
+
+const (
+	OrderStatusNew             OrderStatus = "NEW"
+	OrderStatusPartiallyFilled OrderStatus = "PARTIALLY_FILLED"
+	OrderStatusFilled          OrderStatus = "FILLED"
+	OrderStatusCanceled        OrderStatus = "CANCELED"
+	OrderStatusRejected        OrderStatus = "REJECTED"
+	OrderStatusExpired         OrderStatus = "EXPIRED"
+)
+
+const (
+	TimeInForceGTC TimeInForce = "GTC"
+)
+
+type OrderRequest struct {
+	Symbol        string
+	Side          Side
+	Type          OrderType
+	TimeInForce   TimeInForce
+	Quantity      float64
+	Price         float64
+	ReduceOnly    bool
+	PositionSide  string // 持仓方向 LONG/SHORT（双向持仓模式），空表示单向持仓
+	PostOnly      bool   // 是否只做 Maker（Post Only）
+	PriceDecimals int
+	ClientOrderID string // 自定义订单ID
+}
+
+type Order struct {
+	OrderID       int64
+	ClientOrderID string
+	Symbol        string
+	Side          Side
+	Type          OrderType
+	Price         float64
+	Quantity      float64
+	ExecutedQty   float64
+	AvgPrice      float64
+	Status        OrderStatus
+	CreatedAt     time.Time
+	UpdateTime    int64
+}
+
+type Position struct {
+	Symbol         string
+	Size           float64
+	EntryPrice     float64
+	MarkPrice      float64
+	UnrealizedPNL  float64
+	Leverage       int
+	MarginType     string
+	IsolatedMargin float64
+	Side           string // 持仓方向 LONG/SHORT（双向持仓模式），单向持仓为空
+}
+
+type Account struct {
+	TotalWalletBalance float64
+	TotalMarginBalance float64
+	AvailableBalance   float64
+	Positions          []*Position
+	PosMode            string // 交易所原始持仓模式（仅 OKX 返回）
+	AccountLeverage    int    // 账户级别的杠杆倍数
+}
+
+// SymbolInfo 合约交易规则（数量口径为币数量）
+type SymbolInfo struct {
+	Symbol             string
+	BaseAsset          string
+	QuoteAsset         string
+	TickSize           float64 // 价格最小变动单位
+	StepSize           float64 // 数量最小变动单位
+	MinQty             float64 // 最小下单数量
+	MinNotional        float64 // 最小名义价值，0 表示无限制
+	ContractMultiplier float64 // 合约乘数（每张合约对应的币数量）
+	MaxLeverage        int     // 最大杠杆倍数，0 表示未知
+	PriceDecimals      int
+	QuantityDecimals   int
+}
+
+type OrderUpdate struct {
+	OrderID       int64
+	ClientOrderID string
+	Symbol        string
+	Side          Side
+	Type          OrderType
+	Status        OrderStatus
+	Price         float64
+	Quantity      float64
+	ExecutedQty   float64
+	AvgPrice      float64
+	UpdateTime    int64
+}
+
+type OrderUpdateCallback func(update OrderUpdate)
+
+// Candle K线数据
+type Candle struct {
+	Symbol    string
+	Open      float64
+	High      float64
+	Low       float64
+	Close     float64
+	Volume    float64
+	Timestamp int64
+	IsClosed  bool // K线是否完结
+}
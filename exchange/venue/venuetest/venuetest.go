@@ -0,0 +1,113 @@
+// Package venuetest 交易所适配器测试共用的模拟 REST 服务
+// 各交易所测试只需提供签名校验规则与接口响应，请求按路径分发并记录，供断言请求参数。
+package venuetest
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// Handler 接口响应，payload 为请求体（GET 请求为查询串）
+type Handler func(w http.ResponseWriter, payload string)
+
+// Verifier 校验请求签名，body 为原始请求体
+type Verifier func(r *http.Request, body []byte) bool
+
+// Server 模拟交易所 REST 服务：公共接口不校验签名，私有接口签名校验失败时返回 rejectBody
+type Server struct {
+	URL string
+
+	t          *testing.T
+	verify     Verifier
+	rejectBody string
+
+	mu       sync.Mutex
+	public   map[string]Handler
+	handlers map[string]Handler
+	requests map[string][]string // path -> 请求体/查询串
+}
+
+// NewServer 启动模拟服务，测试结束时自动关闭
+func NewServer(t *testing.T, verify Verifier, rejectBody string) *Server {
+	s := &Server{
+		t:          t,
+		verify:     verify,
+		rejectBody: rejectBody,
+		public:     make(map[string]Handler),
+		handlers:   make(map[string]Handler),
+		requests:   make(map[string][]string),
+	}
+	server := httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(server.Close)
+	s.URL = server.URL
+	return s
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	payload := string(body)
+	if r.Method == http.MethodGet {
+		payload = r.URL.RawQuery
+	}
+
+	s.mu.Lock()
+	public, isPublic := s.public[r.URL.Path]
+	s.mu.Unlock()
+	if isPublic {
+		public(w, payload)
+		return
+	}
+
+	if !s.verify(r, body) {
+		s.t.Errorf("签名校验失败: %s %s", r.Method, r.URL.Path)
+		w.Write([]byte(s.rejectBody))
+		return
+	}
+
+	s.mu.Lock()
+	s.requests[r.URL.Path] = append(s.requests[r.URL.Path], payload)
+	handler, ok := s.handlers[r.URL.Path]
+	s.mu.Unlock()
+
+	if !ok {
+		s.t.Errorf("未预期的请求: %s %s", r.Method, r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	handler(w, payload)
+}
+
+// HandlePublic 注册不需要签名的公共接口（服务器时间等）
+func (s *Server) HandlePublic(path string, handler Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.public[path] = handler
+}
+
+// Handle 注册需要签名的接口，重复注册时覆盖
+func (s *Server) Handle(path string, handler Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[path] = handler
+}
+
+// LastRequest 该路径最近一次签名请求的请求体（GET 请求为查询串），没有请求时返回空
+func (s *Server) LastRequest(path string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	reqs := s.requests[path]
+	if len(reqs) == 0 {
+		return ""
+	}
+	return reqs[len(reqs)-1]
+}
+
+// Reply 固定响应体
+func Reply(body string) Handler {
+	return func(w http.ResponseWriter, payload string) {
+		w.Write([]byte(body))
+	}
+}
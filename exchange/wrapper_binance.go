@@ -3,17 +3,73 @@ package exchange
 import (
 	"context"
 	"opensqt/exchange/binance"
+	"time"
 )
 
 // binanceWrapper 包装 Binance 适配器以实现 IExchange 接口
 type binanceWrapper struct {
-	adapter *binance.BinanceAdapter
+	adapter      *binance.BinanceAdapter
+	orderStreams orderStreamFanout
 }
 
 func (w *binanceWrapper) GetName() string {
 	return w.adapter.GetName()
 }
 
+// GetClockSkew 本地时钟相对服务器的偏差
+func (w *binanceWrapper) GetClockSkew() time.Duration {
+	return w.adapter.ClockSkew()
+}
+
+// SyncServerTime 立即与服务器对时
+func (w *binanceWrapper) SyncServerTime(ctx context.Context) error {
+	return w.adapter.SyncServerTime(ctx)
+}
+
+// Capabilities Binance 能力描述
+func (w *binanceWrapper) Capabilities() Capabilities {
+	if w.adapter.IsSpot() {
+		return Capabilities{
+			Spot:                true,
+			DepthStream:         true,
+			BookTicker:          true,
+			KlinesPerRequest:    1000,
+			MyTrades:            true,
+			PostOnlyStyle:       PostOnlyOrderType, // LIMIT_MAKER
+			MaxClientOrderIDLen: 26,
+		}
+	}
+	if w.adapter.IsInverse() {
+		// 币本位合约没有批量下单与改单接口，改单由撤单重下模拟
+		return Capabilities{
+			Inverse:             true,
+			AccountStream:       true,
+			DepthStream:         true,
+			BookTicker:          true,
+			HedgeMode:           true,
+			AccountSettings:     true,
+			KlinesPerRequest:    1000,
+			MyTrades:            true,
+			PostOnlyStyle:       PostOnlyTimeInForce,
+			MaxClientOrderIDLen: 26,
+		}
+	}
+	return Capabilities{
+		NativeBatchSize:       5,  // batchOrders 一次最多5个
+		NativeBatchCancelSize: 10, // 批量撤单一次最多10个
+		AmendOrder:            true,
+		AccountStream:         true,
+		DepthStream:           true,
+		BookTicker:            true,
+		HedgeMode:             true,
+		AccountSettings:       true,
+		KlinesPerRequest:      1000,
+		MyTrades:              true,
+		PostOnlyStyle:         PostOnlyTimeInForce,
+		MaxClientOrderIDLen:   26, // 36字符限制 - 返佣前缀 "x-zdfVM8vY"
+	}
+}
+
 func (w *binanceWrapper) PlaceOrder(ctx context.Context, req *OrderRequest) (*Order, error) {
 	binanceReq := &binance.OrderRequest{
 		Symbol:        req.Symbol,
@@ -23,6 +79,7 @@ func (w *binanceWrapper) PlaceOrder(ctx context.Context, req *OrderRequest) (*Or
 		Quantity:      req.Quantity,
 		Price:         req.Price,
 		ReduceOnly:    req.ReduceOnly,
+		PositionSide:  string(req.PositionSide),
 		PostOnly:      req.PostOnly,
 		PriceDecimals: req.PriceDecimals,
 		ClientOrderID: req.ClientOrderID,
@@ -60,6 +117,7 @@ func (w *binanceWrapper) BatchPlaceOrders(ctx context.Context, orders []*OrderRe
 			Quantity:      req.Quantity,
 			Price:         req.Price,
 			ReduceOnly:    req.ReduceOnly,
+			PositionSide:  string(req.PositionSide),
 			PostOnly:      req.PostOnly,
 			PriceDecimals: req.PriceDecimals,
 			ClientOrderID: req.ClientOrderID,
@@ -97,6 +155,31 @@ func (w *binanceWrapper) BatchCancelOrders(ctx context.Context, symbol string, o
 	return w.adapter.BatchCancelOrders(ctx, symbol, orderIDs)
 }
 
+func (w *binanceWrapper) AmendOrder(ctx context.Context, req *OrderRequest, orderID int64, newPrice, newQty float64) (*Order, error) {
+	if w.adapter.IsSpot() || w.adapter.IsInverse() {
+		return amendByCancelReplace(ctx, w, req, orderID, newPrice, newQty)
+	}
+	binanceOrder, err := w.adapter.AmendOrder(ctx, req.Symbol, orderID, newPrice, newQty)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Order{
+		OrderID:       binanceOrder.OrderID,
+		ClientOrderID: binanceOrder.ClientOrderID,
+		Symbol:        binanceOrder.Symbol,
+		Side:          Side(binanceOrder.Side),
+		Type:          OrderType(binanceOrder.Type),
+		Price:         binanceOrder.Price,
+		Quantity:      binanceOrder.Quantity,
+		ExecutedQty:   binanceOrder.ExecutedQty,
+		AvgPrice:      binanceOrder.AvgPrice,
+		Status:        OrderStatus(binanceOrder.Status),
+		CreatedAt:     binanceOrder.CreatedAt,
+		UpdateTime:    binanceOrder.UpdateTime,
+	}, nil
+}
+
 // CancelAllOrders 撤销所有订单（Binance实现）
 // 查询所有未完成订单后批量撤销
 func (w *binanceWrapper) CancelAllOrders(ctx context.Context, symbol string) error {
@@ -169,6 +252,31 @@ func (w *binanceWrapper) GetOpenOrders(ctx context.Context, symbol string) ([]*O
 	return orders, nil
 }
 
+func (w *binanceWrapper) GetMyTrades(ctx context.Context, symbol string, since time.Time, limit int) ([]*Trade, error) {
+	binanceTrades, err := w.adapter.GetMyTrades(ctx, symbol, since, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	trades := make([]*Trade, len(binanceTrades))
+	for i, t := range binanceTrades {
+		trades[i] = &Trade{
+			TradeID:         t.TradeID,
+			OrderID:         t.OrderID,
+			ClientOrderID:   t.ClientOrderID,
+			Symbol:          t.Symbol,
+			Side:            Side(t.Side),
+			Price:           t.Price,
+			Quantity:        t.Quantity,
+			Commission:      t.Commission,
+			CommissionAsset: t.CommissionAsset,
+			IsMaker:         t.IsMaker,
+			Time:            t.Time,
+		}
+	}
+	return trades, nil
+}
+
 func (w *binanceWrapper) GetAccount(ctx context.Context) (*Account, error) {
 	binanceAccount, err := w.adapter.GetAccount(ctx)
 	if err != nil {
@@ -186,6 +294,7 @@ func (w *binanceWrapper) GetAccount(ctx context.Context) (*Account, error) {
 			Leverage:       pos.Leverage,
 			MarginType:     pos.MarginType,
 			IsolatedMargin: pos.IsolatedMargin,
+			Side:           PositionSide(pos.Side),
 		}
 	}
 
@@ -214,6 +323,7 @@ func (w *binanceWrapper) GetPositions(ctx context.Context, symbol string) ([]*Po
 			Leverage:       pos.Leverage,
 			MarginType:     pos.MarginType,
 			IsolatedMargin: pos.IsolatedMargin,
+			Side:           PositionSide(pos.Side),
 		}
 	}
 
@@ -224,14 +334,62 @@ func (w *binanceWrapper) GetBalance(ctx context.Context, asset string) (float64,
 	return w.adapter.GetBalance(ctx, asset)
 }
 
+func (w *binanceWrapper) SetLeverage(ctx context.Context, symbol string, leverage int) error {
+	return w.adapter.SetLeverage(ctx, symbol, leverage)
+}
+
+func (w *binanceWrapper) SetMarginType(ctx context.Context, symbol string, marginType MarginType) error {
+	return w.adapter.SetMarginType(ctx, symbol, marginType == MarginTypeIsolated)
+}
+
+func (w *binanceWrapper) SetPositionMode(ctx context.Context, mode PositionMode) error {
+	return w.adapter.SetPositionMode(ctx, mode == PositionModeHedge)
+}
+
 func (w *binanceWrapper) StartOrderStream(ctx context.Context, callback func(interface{})) error {
-	return w.adapter.StartOrderStream(ctx, callback)
+	return w.orderStreams.subscribe(ctx, "", callback, w.adapter.StartOrderStream)
+}
+
+func (w *binanceWrapper) SubscribeOrderStream(ctx context.Context, symbol string, callback func(interface{})) error {
+	return w.orderStreams.subscribe(ctx, symbol, callback, w.adapter.StartOrderStream)
 }
 
 func (w *binanceWrapper) StopOrderStream() error {
+	w.orderStreams.reset()
 	return w.adapter.StopOrderStream()
 }
 
+func (w *binanceWrapper) StartAccountStream(ctx context.Context, callback AccountUpdateCallback) error {
+	return w.adapter.StartAccountStream(ctx, func(update *binance.AccountUpdate) {
+		account := &AccountUpdate{
+			PositionsSnapshot: update.PositionsSnapshot,
+			UpdateTime:        update.UpdateTime,
+		}
+		for _, b := range update.Balances {
+			account.Balances = append(account.Balances, BalanceUpdate{
+				Asset:            b.Asset,
+				WalletBalance:    b.WalletBalance,
+				AvailableBalance: b.AvailableBalance,
+				HasAvailable:     b.HasAvailable,
+			})
+		}
+		for _, pos := range update.Positions {
+			account.Positions = append(account.Positions, &Position{
+				Symbol:         pos.Symbol,
+				Size:           pos.Size,
+				EntryPrice:     pos.EntryPrice,
+				MarkPrice:      pos.MarkPrice,
+				UnrealizedPNL:  pos.UnrealizedPNL,
+				Leverage:       pos.Leverage,
+				MarginType:     pos.MarginType,
+				IsolatedMargin: pos.IsolatedMargin,
+				Side:           PositionSide(pos.Side),
+			})
+		}
+		callback(account)
+	})
+}
+
 func (w *binanceWrapper) GetLatestPrice(ctx context.Context, symbol string) (float64, error) {
 	return w.adapter.GetLatestPrice(ctx, symbol)
 }
@@ -240,6 +398,66 @@ func (w *binanceWrapper) StartPriceStream(ctx context.Context, symbol string, ca
 	return w.adapter.StartPriceStream(ctx, symbol, callback)
 }
 
+func (w *binanceWrapper) GetMarkPrice(ctx context.Context, symbol string) (float64, error) {
+	return w.adapter.GetMarkPrice(ctx, symbol)
+}
+
+func (w *binanceWrapper) StartMarkPriceStream(ctx context.Context, symbol string, callback MarkPriceCallback) error {
+	return w.adapter.StartMarkPriceStream(ctx, symbol, func(update *binance.FundingRate) {
+		callback(convertBinanceFundingRate(update))
+	})
+}
+
+func (w *binanceWrapper) StartBookTickerStream(ctx context.Context, symbol string, callback BookTickerCallback) error {
+	return w.adapter.StartBookTickerStream(ctx, symbol, callback)
+}
+
+func (w *binanceWrapper) GetOrderBook(ctx context.Context, symbol string, depth int) (*OrderBook, error) {
+	return w.adapter.GetOrderBook(ctx, symbol, depth)
+}
+
+func (w *binanceWrapper) StartDepthStream(ctx context.Context, symbol string, callback OrderBookCallback) error {
+	return w.adapter.StartDepthStream(ctx, symbol, callback)
+}
+
+func (w *binanceWrapper) GetFundingRate(ctx context.Context, symbol string) (*FundingRate, error) {
+	rate, err := w.adapter.GetFundingRate(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	return convertBinanceFundingRate(rate), nil
+}
+
+func (w *binanceWrapper) GetFundingPayments(ctx context.Context, symbol string, since time.Time) ([]*FundingPayment, error) {
+	binancePayments, err := w.adapter.GetFundingPayments(ctx, symbol, since)
+	if err != nil {
+		return nil, err
+	}
+
+	payments := make([]*FundingPayment, len(binancePayments))
+	for i, p := range binancePayments {
+		payments[i] = &FundingPayment{
+			ID:     p.ID,
+			Symbol: p.Symbol,
+			Amount: p.Amount,
+			Asset:  p.Asset,
+			Time:   p.Time,
+		}
+	}
+	return payments, nil
+}
+
+func convertBinanceFundingRate(rate *binance.FundingRate) *FundingRate {
+	return &FundingRate{
+		Symbol:          rate.Symbol,
+		FundingRate:     rate.FundingRate,
+		MarkPrice:       rate.MarkPrice,
+		IndexPrice:      rate.IndexPrice,
+		NextFundingTime: rate.NextFundingTime,
+		Time:            rate.Time,
+	}
+}
+
 func (w *binanceWrapper) StartKlineStream(ctx context.Context, symbols []string, interval string, callback CandleUpdateCallback) error {
 	return w.adapter.StartKlineStream(ctx, symbols, interval, func(candle interface{}) {
 		if c, ok := candle.(*binance.Candle); ok {
@@ -274,7 +492,24 @@ func (w *binanceWrapper) GetHistoricalKlines(ctx context.Context, symbol string,
 	if err != nil {
 		return nil, err
 	}
+	return convertBinanceCandles(candles), nil
+}
+
+func (w *binanceWrapper) GetHistoricalKlinesRange(ctx context.Context, symbol string, interval string, start, end time.Time) ([]*Candle, error) {
+	return klinesRange(ctx, w, symbol, interval, start, end, w.Capabilities().KlinesPerRequest)
+}
+
+// getKlinesPage 查询开盘时间在 [from, to] 内的一页K线
+func (w *binanceWrapper) getKlinesPage(ctx context.Context, symbol string, interval string, from, to time.Time) ([]*Candle, error) {
+	candles, err := w.adapter.GetKlinesBetween(ctx, symbol, interval, from.UnixMilli(), to.UnixMilli(), w.Capabilities().KlinesPerRequest)
+	if err != nil {
+		return nil, err
+	}
+	return convertBinanceCandles(candles), nil
+}
 
+// convertBinanceCandles 转换K线
+func convertBinanceCandles(candles []*binance.Candle) []*Candle {
 	result := make([]*Candle, len(candles))
 	for i, c := range candles {
 		result[i] = &Candle{
@@ -288,7 +523,29 @@ func (w *binanceWrapper) GetHistoricalKlines(ctx context.Context, symbol string,
 			IsClosed:  c.IsClosed,
 		}
 	}
-	return result, nil
+	return result
+}
+
+func (w *binanceWrapper) GetSymbolInfo(ctx context.Context, symbol string) (*SymbolInfo, error) {
+	info, err := w.adapter.GetSymbolInfo(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SymbolInfo{
+		Symbol:             info.Symbol,
+		BaseAsset:          info.BaseAsset,
+		QuoteAsset:         info.QuoteAsset,
+		TickSize:           info.TickSize,
+		StepSize:           info.StepSize,
+		MinQty:             info.MinQty,
+		MinNotional:        info.MinNotional,
+		ContractMultiplier: info.ContractMultiplier,
+		ContractValue:      info.ContractValue,
+		MaxLeverage:        info.MaxLeverage,
+		PriceDecimals:      info.PriceDecimals,
+		QuantityDecimals:   info.QuantityDecimals,
+	}, nil
 }
 
 func (w *binanceWrapper) GetPriceDecimals() int {
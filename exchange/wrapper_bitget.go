@@ -3,17 +3,60 @@ package exchange
 import (
 	"context"
 	"opensqt/exchange/bitget"
+	"time"
 )
 
 // bitgetWrapper 包装 Bitget 适配器以实现 IExchange 接口
 type bitgetWrapper struct {
-	adapter *bitget.BitgetAdapter
+	adapter      *bitget.BitgetAdapter
+	orderStreams orderStreamFanout
 }
 
 func (w *bitgetWrapper) GetName() string {
 	return w.adapter.GetName()
 }
 
+// GetClockSkew 本地时钟相对服务器的偏差
+func (w *bitgetWrapper) GetClockSkew() time.Duration {
+	return w.adapter.ClockSkew()
+}
+
+// SyncServerTime 立即与服务器对时
+func (w *bitgetWrapper) SyncServerTime(ctx context.Context) error {
+	return w.adapter.SyncServerTime(ctx)
+}
+
+// Capabilities Bitget 能力描述
+func (w *bitgetWrapper) Capabilities() Capabilities {
+	if w.adapter.IsSpot() {
+		return Capabilities{
+			Spot:                true,
+			NativeCancelAll:     true, // cancel-symbol-order
+			DepthStream:         true,
+			BookTicker:          true,
+			KlinesPerRequest:    200,
+			MyTrades:            true,
+			PostOnlyStyle:       PostOnlyTimeInForce,
+			MaxClientOrderIDLen: 50,
+		}
+	}
+	return Capabilities{
+		NativeBatchSize:       50,
+		NativeBatchCancelSize: 20,
+		NativeCancelAll:       true,
+		AmendOrder:            true,
+		AccountStream:         true,
+		DepthStream:           true,
+		BookTicker:            true,
+		HedgeMode:             true,
+		AccountSettings:       true,
+		KlinesPerRequest:      200,
+		MyTrades:              true,
+		PostOnlyStyle:         PostOnlyTimeInForce,
+		MaxClientOrderIDLen:   50,
+	}
+}
+
 func (w *bitgetWrapper) PlaceOrder(ctx context.Context, req *OrderRequest) (*Order, error) {
 	// 转换请求类型
 	bitgetReq := &bitget.OrderRequest{
@@ -24,6 +67,7 @@ func (w *bitgetWrapper) PlaceOrder(ctx context.Context, req *OrderRequest) (*Ord
 		Quantity:      req.Quantity,
 		Price:         req.Price,
 		ReduceOnly:    req.ReduceOnly,
+		PositionSide:  string(req.PositionSide),
 		PostOnly:      req.PostOnly,
 		PriceDecimals: req.PriceDecimals,
 		ClientOrderID: req.ClientOrderID,
@@ -62,6 +106,7 @@ func (w *bitgetWrapper) BatchPlaceOrders(ctx context.Context, orders []*OrderReq
 			Quantity:      req.Quantity,
 			Price:         req.Price,
 			ReduceOnly:    req.ReduceOnly,
+			PositionSide:  string(req.PositionSide),
 			PostOnly:      req.PostOnly,
 			PriceDecimals: req.PriceDecimals,
 			ClientOrderID: req.ClientOrderID,
@@ -99,6 +144,31 @@ func (w *bitgetWrapper) BatchCancelOrders(ctx context.Context, symbol string, or
 	return w.adapter.BatchCancelOrders(ctx, symbol, orderIDs)
 }
 
+func (w *bitgetWrapper) AmendOrder(ctx context.Context, req *OrderRequest, orderID int64, newPrice, newQty float64) (*Order, error) {
+	if w.adapter.IsSpot() {
+		return amendByCancelReplace(ctx, w, req, orderID, newPrice, newQty)
+	}
+	bitgetOrder, err := w.adapter.AmendOrder(ctx, req.Symbol, orderID, newPrice, newQty)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Order{
+		OrderID:       bitgetOrder.OrderID,
+		ClientOrderID: bitgetOrder.ClientOrderID,
+		Symbol:        bitgetOrder.Symbol,
+		Side:          Side(bitgetOrder.Side),
+		Type:          OrderType(bitgetOrder.Type),
+		Price:         bitgetOrder.Price,
+		Quantity:      bitgetOrder.Quantity,
+		ExecutedQty:   bitgetOrder.ExecutedQty,
+		AvgPrice:      bitgetOrder.AvgPrice,
+		Status:        OrderStatus(bitgetOrder.Status),
+		CreatedAt:     bitgetOrder.CreatedAt,
+		UpdateTime:    bitgetOrder.UpdateTime,
+	}, nil
+}
+
 // CancelAllOrders 撤销所有订单（Bitget实现）
 // 使用Bitget一键全撤API，更高效且可靠
 func (w *bitgetWrapper) CancelAllOrders(ctx context.Context, symbol string) error {
@@ -155,6 +225,31 @@ func (w *bitgetWrapper) GetOpenOrders(ctx context.Context, symbol string) ([]*Or
 	return orders, nil
 }
 
+func (w *bitgetWrapper) GetMyTrades(ctx context.Context, symbol string, since time.Time, limit int) ([]*Trade, error) {
+	bitgetTrades, err := w.adapter.GetMyTrades(ctx, symbol, since, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	trades := make([]*Trade, len(bitgetTrades))
+	for i, t := range bitgetTrades {
+		trades[i] = &Trade{
+			TradeID:         t.TradeID,
+			OrderID:         t.OrderID,
+			ClientOrderID:   t.ClientOrderID,
+			Symbol:          t.Symbol,
+			Side:            Side(t.Side),
+			Price:           t.Price,
+			Quantity:        t.Quantity,
+			Commission:      t.Commission,
+			CommissionAsset: t.CommissionAsset,
+			IsMaker:         t.IsMaker,
+			Time:            t.Time,
+		}
+	}
+	return trades, nil
+}
+
 func (w *bitgetWrapper) GetAccount(ctx context.Context) (*Account, error) {
 	bitgetAccount, err := w.adapter.GetAccount(ctx)
 	if err != nil {
@@ -172,6 +267,7 @@ func (w *bitgetWrapper) GetAccount(ctx context.Context) (*Account, error) {
 			Leverage:       pos.Leverage,
 			MarginType:     pos.MarginType,
 			IsolatedMargin: pos.IsolatedMargin,
+			Side:           PositionSide(pos.Side),
 		}
 	}
 
@@ -201,6 +297,7 @@ func (w *bitgetWrapper) GetPositions(ctx context.Context, symbol string) ([]*Pos
 			Leverage:       pos.Leverage,
 			MarginType:     pos.MarginType,
 			IsolatedMargin: pos.IsolatedMargin,
+			Side:           PositionSide(pos.Side),
 		}
 	}
 
@@ -211,14 +308,62 @@ func (w *bitgetWrapper) GetBalance(ctx context.Context, asset string) (float64,
 	return w.adapter.GetBalance(ctx, asset)
 }
 
+func (w *bitgetWrapper) SetLeverage(ctx context.Context, symbol string, leverage int) error {
+	return w.adapter.SetLeverage(ctx, symbol, leverage)
+}
+
+func (w *bitgetWrapper) SetMarginType(ctx context.Context, symbol string, marginType MarginType) error {
+	return w.adapter.SetMarginType(ctx, symbol, marginType == MarginTypeIsolated)
+}
+
+func (w *bitgetWrapper) SetPositionMode(ctx context.Context, mode PositionMode) error {
+	return w.adapter.SetPositionMode(ctx, mode == PositionModeHedge)
+}
+
 func (w *bitgetWrapper) StartOrderStream(ctx context.Context, callback func(interface{})) error {
-	return w.adapter.StartOrderStream(ctx, callback)
+	return w.orderStreams.subscribe(ctx, "", callback, w.adapter.StartOrderStream)
+}
+
+func (w *bitgetWrapper) SubscribeOrderStream(ctx context.Context, symbol string, callback func(interface{})) error {
+	return w.orderStreams.subscribe(ctx, symbol, callback, w.adapter.StartOrderStream)
 }
 
 func (w *bitgetWrapper) StopOrderStream() error {
+	w.orderStreams.reset()
 	return w.adapter.StopOrderStream()
 }
 
+func (w *bitgetWrapper) StartAccountStream(ctx context.Context, callback AccountUpdateCallback) error {
+	return w.adapter.StartAccountStream(ctx, func(update *bitget.AccountUpdate) {
+		account := &AccountUpdate{
+			PositionsSnapshot: update.PositionsSnapshot,
+			UpdateTime:        update.UpdateTime,
+		}
+		for _, b := range update.Balances {
+			account.Balances = append(account.Balances, BalanceUpdate{
+				Asset:            b.Asset,
+				WalletBalance:    b.WalletBalance,
+				AvailableBalance: b.AvailableBalance,
+				HasAvailable:     b.HasAvailable,
+			})
+		}
+		for _, pos := range update.Positions {
+			account.Positions = append(account.Positions, &Position{
+				Symbol:         pos.Symbol,
+				Size:           pos.Size,
+				EntryPrice:     pos.EntryPrice,
+				MarkPrice:      pos.MarkPrice,
+				UnrealizedPNL:  pos.UnrealizedPNL,
+				Leverage:       pos.Leverage,
+				MarginType:     pos.MarginType,
+				IsolatedMargin: pos.IsolatedMargin,
+				Side:           PositionSide(pos.Side),
+			})
+		}
+		callback(account)
+	})
+}
+
 func (w *bitgetWrapper) GetLatestPrice(ctx context.Context, symbol string) (float64, error) {
 	return w.adapter.GetLatestPrice(ctx, symbol)
 }
@@ -227,6 +372,66 @@ func (w *bitgetWrapper) StartPriceStream(ctx context.Context, symbol string, cal
 	return w.adapter.StartPriceStream(ctx, symbol, callback)
 }
 
+func (w *bitgetWrapper) GetMarkPrice(ctx context.Context, symbol string) (float64, error) {
+	return w.adapter.GetMarkPrice(ctx, symbol)
+}
+
+func (w *bitgetWrapper) StartMarkPriceStream(ctx context.Context, symbol string, callback MarkPriceCallback) error {
+	return w.adapter.StartMarkPriceStream(ctx, symbol, func(update *bitget.FundingRate) {
+		callback(convertBitgetFundingRate(update))
+	})
+}
+
+func (w *bitgetWrapper) StartBookTickerStream(ctx context.Context, symbol string, callback BookTickerCallback) error {
+	return w.adapter.StartBookTickerStream(ctx, symbol, callback)
+}
+
+func (w *bitgetWrapper) GetOrderBook(ctx context.Context, symbol string, depth int) (*OrderBook, error) {
+	return w.adapter.GetOrderBook(ctx, symbol, depth)
+}
+
+func (w *bitgetWrapper) StartDepthStream(ctx context.Context, symbol string, callback OrderBookCallback) error {
+	return w.adapter.StartDepthStream(ctx, symbol, callback)
+}
+
+func (w *bitgetWrapper) GetFundingRate(ctx context.Context, symbol string) (*FundingRate, error) {
+	rate, err := w.adapter.GetFundingRate(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	return convertBitgetFundingRate(rate), nil
+}
+
+func (w *bitgetWrapper) GetFundingPayments(ctx context.Context, symbol string, since time.Time) ([]*FundingPayment, error) {
+	bitgetPayments, err := w.adapter.GetFundingPayments(ctx, symbol, since)
+	if err != nil {
+		return nil, err
+	}
+
+	payments := make([]*FundingPayment, len(bitgetPayments))
+	for i, p := range bitgetPayments {
+		payments[i] = &FundingPayment{
+			ID:     p.ID,
+			Symbol: p.Symbol,
+			Amount: p.Amount,
+			Asset:  p.Asset,
+			Time:   p.Time,
+		}
+	}
+	return payments, nil
+}
+
+func convertBitgetFundingRate(rate *bitget.FundingRate) *FundingRate {
+	return &FundingRate{
+		Symbol:          rate.Symbol,
+		FundingRate:     rate.FundingRate,
+		MarkPrice:       rate.MarkPrice,
+		IndexPrice:      rate.IndexPrice,
+		NextFundingTime: rate.NextFundingTime,
+		Time:            rate.Time,
+	}
+}
+
 func (w *bitgetWrapper) StartKlineStream(ctx context.Context, symbols []string, interval string, callback CandleUpdateCallback) error {
 	return w.adapter.StartKlineStream(ctx, symbols, interval, func(candle interface{}) {
 		if c, ok := candle.(*bitget.Candle); ok {
@@ -261,7 +466,24 @@ func (w *bitgetWrapper) GetHistoricalKlines(ctx context.Context, symbol string,
 	if err != nil {
 		return nil, err
 	}
+	return convertBitgetCandles(candles), nil
+}
+
+func (w *bitgetWrapper) GetHistoricalKlinesRange(ctx context.Context, symbol string, interval string, start, end time.Time) ([]*Candle, error) {
+	return klinesRange(ctx, w, symbol, interval, start, end, w.Capabilities().KlinesPerRequest)
+}
+
+// getKlinesPage 查询开盘时间在 [from, to] 内的一页K线
+func (w *bitgetWrapper) getKlinesPage(ctx context.Context, symbol string, interval string, from, to time.Time) ([]*Candle, error) {
+	candles, err := w.adapter.GetKlinesBetween(ctx, symbol, interval, from.UnixMilli(), to.UnixMilli(), w.Capabilities().KlinesPerRequest)
+	if err != nil {
+		return nil, err
+	}
+	return convertBitgetCandles(candles), nil
+}
 
+// convertBitgetCandles 转换K线
+func convertBitgetCandles(candles []*bitget.Candle) []*Candle {
 	result := make([]*Candle, len(candles))
 	for i, c := range candles {
 		result[i] = &Candle{
@@ -275,7 +497,28 @@ func (w *bitgetWrapper) GetHistoricalKlines(ctx context.Context, symbol string,
 			IsClosed:  c.IsClosed,
 		}
 	}
-	return result, nil
+	return result
+}
+
+func (w *bitgetWrapper) GetSymbolInfo(ctx context.Context, symbol string) (*SymbolInfo, error) {
+	info, err := w.adapter.GetSymbolInfo(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SymbolInfo{
+		Symbol:             info.Symbol,
+		BaseAsset:          info.BaseAsset,
+		QuoteAsset:         info.QuoteAsset,
+		TickSize:           info.TickSize,
+		StepSize:           info.StepSize,
+		MinQty:             info.MinQty,
+		MinNotional:        info.MinNotional,
+		ContractMultiplier: info.ContractMultiplier,
+		MaxLeverage:        info.MaxLeverage,
+		PriceDecimals:      info.PriceDecimals,
+		QuantityDecimals:   info.QuantityDecimals,
+	}, nil
 }
 
 func (w *bitgetWrapper) GetPriceDecimals() int {
@@ -0,0 +1,20 @@
+package exchange
+
+import "opensqt/exchange/bybit"
+
+// newBybitWrapper 包装 Bybit 适配器以实现 IExchange 接口
+func newBybitWrapper(adapter *bybit.BybitAdapter) *venueWrapper {
+	return &venueWrapper{
+		adapter: adapter,
+		capabilities: Capabilities{
+			NativeBatchCancelSize: 20,
+			NativeCancelAll:       true, // 按交易对一键全撤
+			BookTicker:            true,
+			HedgeMode:             true,
+			AccountSettings:       true,
+			KlinesPerRequest:      1000,
+			PostOnlyStyle:         PostOnlyTimeInForce,
+			MaxClientOrderIDLen:   36,
+		},
+	}
+}
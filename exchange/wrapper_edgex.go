@@ -0,0 +1,18 @@
+package exchange
+
+import "opensqt/exchange/edgex"
+
+// newEdgeXWrapper 包装 edgeX 适配器以实现 IExchange 接口
+// 杠杆、保证金模式与持仓模式需在 edgeX 网页端设置
+func newEdgeXWrapper(adapter *edgex.EdgeXAdapter) *venueWrapper {
+	return &venueWrapper{
+		adapter: adapter,
+		capabilities: Capabilities{
+			NativeBatchCancelSize: 50,
+			NativeCancelAll:       true, // 按交易对一键全撤
+			KlinesPerRequest:      1000,
+			PostOnlyStyle:         PostOnlyTimeInForce,
+			MaxClientOrderIDLen:   32,
+		},
+	}
+}
@@ -4,17 +4,58 @@ import (
 	"context"
 	"opensqt/exchange/gate"
 	"opensqt/utils"
+	"time"
 )
 
 // gateWrapper 包装 Gate.io 适配器以实现 IExchange 接口
 type gateWrapper struct {
-	adapter *gate.GateAdapter
+	adapter      *gate.GateAdapter
+	orderStreams orderStreamFanout
 }
 
 func (w *gateWrapper) GetName() string {
 	return w.adapter.GetName()
 }
 
+// GetClockSkew 本地时钟相对服务器的偏差
+func (w *gateWrapper) GetClockSkew() time.Duration {
+	return w.adapter.ClockSkew()
+}
+
+// SyncServerTime 立即与服务器对时
+func (w *gateWrapper) SyncServerTime(ctx context.Context) error {
+	return w.adapter.SyncServerTime(ctx)
+}
+
+// Capabilities Gate.io 能力描述
+func (w *gateWrapper) Capabilities() Capabilities {
+	if w.adapter.IsSpot() {
+		return Capabilities{
+			Spot:                true,
+			DepthStream:         true,
+			BookTicker:          true,
+			KlinesPerRequest:    1000,
+			MyTrades:            true,
+			PostOnlyStyle:       PostOnlyTimeInForce,
+			MaxClientOrderIDLen: 28, // 30字符限制 - 返佣前缀 "t-"
+		}
+	}
+	return Capabilities{
+		Inverse:               w.adapter.IsInverse(),
+		NativeBatchCancelSize: 20,
+		AmendOrder:            true,
+		AccountStream:         true,
+		DepthStream:           true,
+		BookTicker:            true,
+		HedgeMode:             true,
+		AccountSettings:       true,
+		KlinesPerRequest:      2000,
+		MyTrades:              true,
+		PostOnlyStyle:         PostOnlyTimeInForce,
+		MaxClientOrderIDLen:   28, // 30字符限制 - 返佣前缀 "t-"
+	}
+}
+
 func (w *gateWrapper) PlaceOrder(ctx context.Context, req *OrderRequest) (*Order, error) {
 	// 转换请求类型
 	gateReq := &gate.OrderRequest{
@@ -25,6 +66,7 @@ func (w *gateWrapper) PlaceOrder(ctx context.Context, req *OrderRequest) (*Order
 		Quantity:      req.Quantity,
 		Price:         req.Price,
 		ReduceOnly:    req.ReduceOnly,
+		PositionSide:  string(req.PositionSide),
 		PostOnly:      req.PostOnly,
 		PriceDecimals: req.PriceDecimals,
 		ClientOrderID: req.ClientOrderID,
@@ -65,6 +107,7 @@ func (w *gateWrapper) BatchPlaceOrders(ctx context.Context, orders []*OrderReque
 			Quantity:      req.Quantity,
 			Price:         req.Price,
 			ReduceOnly:    req.ReduceOnly,
+			PositionSide:  string(req.PositionSide),
 			PostOnly:      req.PostOnly,
 			PriceDecimals: req.PriceDecimals,
 			ClientOrderID: req.ClientOrderID,
@@ -105,6 +148,31 @@ func (w *gateWrapper) BatchCancelOrders(ctx context.Context, symbol string, orde
 	return w.adapter.BatchCancelOrders(ctx, symbol, orderIDs)
 }
 
+func (w *gateWrapper) AmendOrder(ctx context.Context, req *OrderRequest, orderID int64, newPrice, newQty float64) (*Order, error) {
+	if w.adapter.IsSpot() {
+		return amendByCancelReplace(ctx, w, req, orderID, newPrice, newQty)
+	}
+	gateOrder, err := w.adapter.AmendOrder(ctx, req.Symbol, orderID, newPrice, newQty)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Order{
+		OrderID:       gateOrder.OrderID,
+		ClientOrderID: utils.RemoveBrokerPrefix("gate", gateOrder.ClientOrderID),
+		Symbol:        gateOrder.Symbol,
+		Side:          Side(gateOrder.Side),
+		Type:          OrderType(gateOrder.Type),
+		Price:         gateOrder.Price,
+		Quantity:      gateOrder.Quantity,
+		ExecutedQty:   gateOrder.ExecutedQty,
+		AvgPrice:      gateOrder.AvgPrice,
+		Status:        OrderStatus(gateOrder.Status),
+		CreatedAt:     gateOrder.CreatedAt,
+		UpdateTime:    gateOrder.UpdateTime,
+	}, nil
+}
+
 // CancelAllOrders 撤销所有订单（Gate.io实现）
 // 查询所有未完成订单后批量撤销
 func (w *gateWrapper) CancelAllOrders(ctx context.Context, symbol string) error {
@@ -183,6 +251,31 @@ func (w *gateWrapper) GetOpenOrders(ctx context.Context, symbol string) ([]*Orde
 	return orders, nil
 }
 
+func (w *gateWrapper) GetMyTrades(ctx context.Context, symbol string, since time.Time, limit int) ([]*Trade, error) {
+	gateTrades, err := w.adapter.GetMyTrades(ctx, symbol, since, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	trades := make([]*Trade, len(gateTrades))
+	for i, t := range gateTrades {
+		trades[i] = &Trade{
+			TradeID:         t.TradeID,
+			OrderID:         t.OrderID,
+			ClientOrderID:   t.ClientOrderID,
+			Symbol:          t.Symbol,
+			Side:            Side(t.Side),
+			Price:           t.Price,
+			Quantity:        t.Quantity,
+			Commission:      t.Commission,
+			CommissionAsset: t.CommissionAsset,
+			IsMaker:         t.IsMaker,
+			Time:            t.Time,
+		}
+	}
+	return trades, nil
+}
+
 func (w *gateWrapper) GetAccount(ctx context.Context) (*Account, error) {
 	gateAccount, err := w.adapter.GetAccount(ctx)
 	if err != nil {
@@ -200,6 +293,7 @@ func (w *gateWrapper) GetAccount(ctx context.Context) (*Account, error) {
 			Leverage:       pos.Leverage,
 			MarginType:     pos.MarginType,
 			IsolatedMargin: pos.IsolatedMargin,
+			Side:           PositionSide(pos.Side),
 		}
 	}
 
@@ -229,6 +323,7 @@ func (w *gateWrapper) GetPositions(ctx context.Context, symbol string) ([]*Posit
 			Leverage:       pos.Leverage,
 			MarginType:     pos.MarginType,
 			IsolatedMargin: pos.IsolatedMargin,
+			Side:           PositionSide(pos.Side),
 		}
 	}
 
@@ -239,14 +334,62 @@ func (w *gateWrapper) GetBalance(ctx context.Context, asset string) (float64, er
 	return w.adapter.GetBalance(ctx, asset)
 }
 
+func (w *gateWrapper) SetLeverage(ctx context.Context, symbol string, leverage int) error {
+	return w.adapter.SetLeverage(ctx, symbol, leverage)
+}
+
+func (w *gateWrapper) SetMarginType(ctx context.Context, symbol string, marginType MarginType) error {
+	return w.adapter.SetMarginType(ctx, symbol, marginType == MarginTypeIsolated)
+}
+
+func (w *gateWrapper) SetPositionMode(ctx context.Context, mode PositionMode) error {
+	return w.adapter.SetPositionMode(ctx, mode == PositionModeHedge)
+}
+
 func (w *gateWrapper) StartOrderStream(ctx context.Context, callback func(interface{})) error {
-	return w.adapter.StartOrderStream(ctx, callback)
+	return w.orderStreams.subscribe(ctx, "", callback, w.adapter.StartOrderStream)
+}
+
+func (w *gateWrapper) SubscribeOrderStream(ctx context.Context, symbol string, callback func(interface{})) error {
+	return w.orderStreams.subscribe(ctx, symbol, callback, w.adapter.StartOrderStream)
 }
 
 func (w *gateWrapper) StopOrderStream() error {
+	w.orderStreams.reset()
 	return w.adapter.StopOrderStream()
 }
 
+func (w *gateWrapper) StartAccountStream(ctx context.Context, callback AccountUpdateCallback) error {
+	return w.adapter.StartAccountStream(ctx, func(update *gate.AccountUpdate) {
+		account := &AccountUpdate{
+			PositionsSnapshot: update.PositionsSnapshot,
+			UpdateTime:        update.UpdateTime,
+		}
+		for _, b := range update.Balances {
+			account.Balances = append(account.Balances, BalanceUpdate{
+				Asset:            b.Asset,
+				WalletBalance:    b.WalletBalance,
+				AvailableBalance: b.AvailableBalance,
+				HasAvailable:     b.HasAvailable,
+			})
+		}
+		for _, pos := range update.Positions {
+			account.Positions = append(account.Positions, &Position{
+				Symbol:         pos.Symbol,
+				Size:           pos.Size,
+				EntryPrice:     pos.EntryPrice,
+				MarkPrice:      pos.MarkPrice,
+				UnrealizedPNL:  pos.UnrealizedPNL,
+				Leverage:       pos.Leverage,
+				MarginType:     pos.MarginType,
+				IsolatedMargin: pos.IsolatedMargin,
+				Side:           PositionSide(pos.Side),
+			})
+		}
+		callback(account)
+	})
+}
+
 func (w *gateWrapper) GetLatestPrice(ctx context.Context, symbol string) (float64, error) {
 	return w.adapter.GetLatestPrice(ctx, symbol)
 }
@@ -257,6 +400,66 @@ func (w *gateWrapper) StartPriceStream(ctx context.Context, symbol string, callb
 	})
 }
 
+func (w *gateWrapper) GetMarkPrice(ctx context.Context, symbol string) (float64, error) {
+	return w.adapter.GetMarkPrice(ctx, symbol)
+}
+
+func (w *gateWrapper) StartMarkPriceStream(ctx context.Context, symbol string, callback MarkPriceCallback) error {
+	return w.adapter.StartMarkPriceStream(ctx, symbol, func(update *gate.FundingRate) {
+		callback(convertGateFundingRate(update))
+	})
+}
+
+func (w *gateWrapper) StartBookTickerStream(ctx context.Context, symbol string, callback BookTickerCallback) error {
+	return w.adapter.StartBookTickerStream(ctx, symbol, callback)
+}
+
+func (w *gateWrapper) GetOrderBook(ctx context.Context, symbol string, depth int) (*OrderBook, error) {
+	return w.adapter.GetOrderBook(ctx, symbol, depth)
+}
+
+func (w *gateWrapper) StartDepthStream(ctx context.Context, symbol string, callback OrderBookCallback) error {
+	return w.adapter.StartDepthStream(ctx, symbol, callback)
+}
+
+func (w *gateWrapper) GetFundingRate(ctx context.Context, symbol string) (*FundingRate, error) {
+	rate, err := w.adapter.GetFundingRate(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	return convertGateFundingRate(rate), nil
+}
+
+func (w *gateWrapper) GetFundingPayments(ctx context.Context, symbol string, since time.Time) ([]*FundingPayment, error) {
+	gatePayments, err := w.adapter.GetFundingPayments(ctx, symbol, since)
+	if err != nil {
+		return nil, err
+	}
+
+	payments := make([]*FundingPayment, len(gatePayments))
+	for i, p := range gatePayments {
+		payments[i] = &FundingPayment{
+			ID:     p.ID,
+			Symbol: p.Symbol,
+			Amount: p.Amount,
+			Asset:  p.Asset,
+			Time:   p.Time,
+		}
+	}
+	return payments, nil
+}
+
+func convertGateFundingRate(rate *gate.FundingRate) *FundingRate {
+	return &FundingRate{
+		Symbol:          rate.Symbol,
+		FundingRate:     rate.FundingRate,
+		MarkPrice:       rate.MarkPrice,
+		IndexPrice:      rate.IndexPrice,
+		NextFundingTime: rate.NextFundingTime,
+		Time:            rate.Time,
+	}
+}
+
 func (w *gateWrapper) StartKlineStream(ctx context.Context, symbols []string, interval string, callback CandleUpdateCallback) error {
 	return w.adapter.StartKlineStream(ctx, symbols, interval, func(candle interface{}) {
 		if c, ok := candle.(*gate.Candle); ok {
@@ -288,27 +491,64 @@ func (w *gateWrapper) ForceReconnectKlineStream() error {
 }
 
 func (w *gateWrapper) GetHistoricalKlines(ctx context.Context, symbol string, interval string, limit int) ([]*Candle, error) {
-	gateCandles, err := w.adapter.GetHistoricalKlines(ctx, symbol, interval, limit)
+	candles, err := w.adapter.GetHistoricalKlines(ctx, symbol, interval, limit)
 	if err != nil {
 		return nil, err
 	}
+	return convertGateCandles(candles), nil
+}
+
+func (w *gateWrapper) GetHistoricalKlinesRange(ctx context.Context, symbol string, interval string, start, end time.Time) ([]*Candle, error) {
+	return klinesRange(ctx, w, symbol, interval, start, end, w.Capabilities().KlinesPerRequest)
+}
 
-	// 转换类型
-	candles := make([]*Candle, len(gateCandles))
-	for i, gc := range gateCandles {
-		candles[i] = &Candle{
-			Symbol:    gc.Symbol,
-			Open:      gc.Open,
-			High:      gc.High,
-			Low:       gc.Low,
-			Close:     gc.Close,
-			Volume:    gc.Volume,
-			Timestamp: gc.Timestamp,
-			IsClosed:  gc.IsClosed,
+// getKlinesPage 查询开盘时间在 [from, to] 内的一页K线
+func (w *gateWrapper) getKlinesPage(ctx context.Context, symbol string, interval string, from, to time.Time) ([]*Candle, error) {
+	candles, err := w.adapter.GetKlinesBetween(ctx, symbol, interval, from.UnixMilli(), to.UnixMilli())
+	if err != nil {
+		return nil, err
+	}
+	return convertGateCandles(candles), nil
+}
+
+// convertGateCandles 转换K线
+func convertGateCandles(candles []*gate.Candle) []*Candle {
+	result := make([]*Candle, len(candles))
+	for i, c := range candles {
+		result[i] = &Candle{
+			Symbol:    c.Symbol,
+			Open:      c.Open,
+			High:      c.High,
+			Low:       c.Low,
+			Close:     c.Close,
+			Volume:    c.Volume,
+			Timestamp: c.Timestamp,
+			IsClosed:  c.IsClosed,
 		}
 	}
+	return result
+}
 
-	return candles, nil
+func (w *gateWrapper) GetSymbolInfo(ctx context.Context, symbol string) (*SymbolInfo, error) {
+	info, err := w.adapter.GetSymbolInfo(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SymbolInfo{
+		Symbol:             info.Symbol,
+		BaseAsset:          info.BaseAsset,
+		QuoteAsset:         info.QuoteAsset,
+		TickSize:           info.TickSize,
+		StepSize:           info.StepSize,
+		MinQty:             info.MinQty,
+		MinNotional:        info.MinNotional,
+		ContractMultiplier: info.ContractMultiplier,
+		ContractValue:      info.ContractValue,
+		MaxLeverage:        info.MaxLeverage,
+		PriceDecimals:      info.PriceDecimals,
+		QuantityDecimals:   info.QuantityDecimals,
+	}, nil
 }
 
 func (w *gateWrapper) GetPriceDecimals() int {
@@ -323,10 +563,10 @@ func (w *gateWrapper) GetQuantityDecimals() int {
 
 func (w *gateWrapper) GetBaseAsset() string {
 	// 从交易对中提取基础资产
-	return ""
+	return w.adapter.GetBaseAsset()
 }
 
 func (w *gateWrapper) GetQuoteAsset() string {
 	// 从交易对中提取计价资产
-	return "USDT"
+	return w.adapter.GetQuoteAsset()
 }
@@ -0,0 +1,19 @@
+package exchange
+
+import "opensqt/exchange/hyperliquid"
+
+// newHyperliquidWrapper 包装 Hyperliquid 适配器以实现 IExchange 接口
+// Hyperliquid 没有一键全撤接口，适配器内部使用"查询挂单 + 批量撤单"实现
+func newHyperliquidWrapper(adapter *hyperliquid.HyperliquidAdapter) *venueWrapper {
+	return &venueWrapper{
+		adapter: adapter,
+		capabilities: Capabilities{
+			NativeBatchSize:       20, // 单个 action 最多携带20个订单
+			NativeBatchCancelSize: 20,
+			BookTicker:            true,
+			KlinesPerRequest:      5000,
+			PostOnlyStyle:         PostOnlyTimeInForce,
+			MaxClientOrderIDLen:   32, // cloid 为 16 字节十六进制，超长ID会退化为摘要
+		},
+	}
+}
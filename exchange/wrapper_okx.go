@@ -0,0 +1,21 @@
+package exchange
+
+import "opensqt/exchange/okx"
+
+// newOKXWrapper 包装 OKX 适配器以实现 IExchange 接口
+// OKX 永续合约没有一键全撤接口，由适配器查询挂单后批量撤销
+func newOKXWrapper(adapter *okx.OKXAdapter) *venueWrapper {
+	return &venueWrapper{
+		adapter: adapter,
+		capabilities: Capabilities{
+			NativeBatchSize:       20,
+			NativeBatchCancelSize: 20,
+			BookTicker:            true,
+			HedgeMode:             true,
+			AccountSettings:       true,
+			KlinesPerRequest:      100,
+			PostOnlyStyle:         PostOnlyOrderType,
+			MaxClientOrderIDLen:   32, // 只允许字母数字，下划线会被去掉
+		},
+	}
+}
@@ -0,0 +1,414 @@
+package exchange
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"opensqt/exchange/orderbook"
+	"opensqt/exchange/venue"
+)
+
+// venueAdapter 使用 venue 包公共类型的交易所适配器（Bybit、OKX、edgeX、Hyperliquid）
+type venueAdapter interface {
+	GetName() string
+	ClockSkew() time.Duration
+	SyncServerTime(ctx context.Context) error
+
+	PlaceOrder(ctx context.Context, req *venue.OrderRequest) (*venue.Order, error)
+	BatchPlaceOrders(ctx context.Context, orders []*venue.OrderRequest) ([]*venue.Order, bool)
+	CancelOrder(ctx context.Context, symbol string, orderID int64) error
+	BatchCancelOrders(ctx context.Context, symbol string, orderIDs []int64) error
+	CancelAllOrders(ctx context.Context, symbol string) error
+	GetOrder(ctx context.Context, symbol string, orderID int64) (*venue.Order, error)
+	GetOpenOrders(ctx context.Context, symbol string) ([]*venue.Order, error)
+
+	GetAccount(ctx context.Context) (*venue.Account, error)
+	GetPositions(ctx context.Context, symbol string) ([]*venue.Position, error)
+	GetBalance(ctx context.Context, asset string) (float64, error)
+
+	StartOrderStream(ctx context.Context, callback func(interface{})) error
+	StopOrderStream() error
+
+	GetLatestPrice(ctx context.Context, symbol string) (float64, error)
+	StartPriceStream(ctx context.Context, symbol string, callback func(price float64)) error
+
+	StartKlineStream(ctx context.Context, symbols []string, interval string, callback func(candle interface{})) error
+	RegisterKlineCallback(componentName string, callback func(candle interface{})) error
+	StopKlineStream() error
+	ForceReconnectKlineStream() error
+	GetHistoricalKlines(ctx context.Context, symbol string, interval string, limit int) ([]*venue.Candle, error)
+	GetKlinesBetween(ctx context.Context, symbol string, interval string, start, end int64, limit int) ([]*venue.Candle, error)
+
+	GetSymbolInfo(ctx context.Context, symbol string) (*venue.SymbolInfo, error)
+	GetPriceDecimals() int
+	GetQuantityDecimals() int
+	GetBaseAsset() string
+	GetQuoteAsset() string
+}
+
+// venueAccountSettings 支持通过 API 设置杠杆、保证金模式与持仓模式的适配器
+type venueAccountSettings interface {
+	SetLeverage(ctx context.Context, symbol string, leverage int) error
+	SetMarginType(ctx context.Context, symbol string, isolated bool) error
+	SetPositionMode(ctx context.Context, hedge bool) error
+}
+
+// venueBookTicker 支持盘口最优价推送的适配器
+type venueBookTicker interface {
+	StartBookTickerStream(ctx context.Context, symbol string, callback func(ticker *orderbook.Ticker)) error
+}
+
+// venueWrapper 包装 venueAdapter 以实现 IExchange 接口
+// 各交易所只在 wrapper_<交易所>.go 中声明能力描述；可选功能按适配器是否实现对应接口提供
+type venueWrapper struct {
+	adapter      venueAdapter
+	capabilities Capabilities
+	orderStreams orderStreamFanout
+}
+
+func (w *venueWrapper) GetName() string {
+	return w.adapter.GetName()
+}
+
+// GetClockSkew 本地时钟相对服务器的偏差
+func (w *venueWrapper) GetClockSkew() time.Duration {
+	return w.adapter.ClockSkew()
+}
+
+// SyncServerTime 立即与服务器对时
+func (w *venueWrapper) SyncServerTime(ctx context.Context) error {
+	return w.adapter.SyncServerTime(ctx)
+}
+
+func (w *venueWrapper) Capabilities() Capabilities {
+	return w.capabilities
+}
+
+func (w *venueWrapper) PlaceOrder(ctx context.Context, req *OrderRequest) (*Order, error) {
+	order, err := w.adapter.PlaceOrder(ctx, toVenueOrderRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return fromVenueOrder(order), nil
+}
+
+func (w *venueWrapper) BatchPlaceOrders(ctx context.Context, orders []*OrderRequest) ([]*Order, bool) {
+	reqs := make([]*venue.OrderRequest, len(orders))
+	for i, req := range orders {
+		reqs[i] = toVenueOrderRequest(req)
+	}
+	placed, hasMarginError := w.adapter.BatchPlaceOrders(ctx, reqs)
+	return fromVenueOrders(placed), hasMarginError
+}
+
+func (w *venueWrapper) CancelOrder(ctx context.Context, symbol string, orderID int64) error {
+	return w.adapter.CancelOrder(ctx, symbol, orderID)
+}
+
+func (w *venueWrapper) BatchCancelOrders(ctx context.Context, symbol string, orderIDs []int64) error {
+	return w.adapter.BatchCancelOrders(ctx, symbol, orderIDs)
+}
+
+// AmendOrder 适配器未接入原生改单接口，以撤单重下实现
+func (w *venueWrapper) AmendOrder(ctx context.Context, req *OrderRequest, orderID int64, newPrice, newQty float64) (*Order, error) {
+	return amendByCancelReplace(ctx, w, req, orderID, newPrice, newQty)
+}
+
+// CancelAllOrders 撤销所有订单
+// 有一键全撤接口的交易所（NativeCancelAll）直接调用，其余由适配器查询挂单后批量撤销
+func (w *venueWrapper) CancelAllOrders(ctx context.Context, symbol string) error {
+	return w.adapter.CancelAllOrders(ctx, symbol)
+}
+
+func (w *venueWrapper) GetOrder(ctx context.Context, symbol string, orderID int64) (*Order, error) {
+	order, err := w.adapter.GetOrder(ctx, symbol, orderID)
+	if err != nil {
+		return nil, err
+	}
+	return fromVenueOrder(order), nil
+}
+
+func (w *venueWrapper) GetOpenOrders(ctx context.Context, symbol string) ([]*Order, error) {
+	orders, err := w.adapter.GetOpenOrders(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	return fromVenueOrders(orders), nil
+}
+
+func (w *venueWrapper) GetMyTrades(ctx context.Context, symbol string, since time.Time, limit int) ([]*Trade, error) {
+	return nil, fmt.Errorf("%s 不支持查询成交记录", w.GetName())
+}
+
+func (w *venueWrapper) GetAccount(ctx context.Context) (*Account, error) {
+	account, err := w.adapter.GetAccount(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Account{
+		TotalWalletBalance: account.TotalWalletBalance,
+		TotalMarginBalance: account.TotalMarginBalance,
+		AvailableBalance:   account.AvailableBalance,
+		Positions:          fromVenuePositions(account.Positions),
+		AccountLeverage:    account.AccountLeverage,
+	}, nil
+}
+
+func (w *venueWrapper) GetPositions(ctx context.Context, symbol string) ([]*Position, error) {
+	positions, err := w.adapter.GetPositions(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	return fromVenuePositions(positions), nil
+}
+
+func (w *venueWrapper) GetBalance(ctx context.Context, asset string) (float64, error) {
+	return w.adapter.GetBalance(ctx, asset)
+}
+
+func (w *venueWrapper) SetLeverage(ctx context.Context, symbol string, leverage int) error {
+	settings, ok := w.adapter.(venueAccountSettings)
+	if !ok {
+		return fmt.Errorf("%s 不支持设置杠杆倍数", w.GetName())
+	}
+	return settings.SetLeverage(ctx, symbol, leverage)
+}
+
+func (w *venueWrapper) SetMarginType(ctx context.Context, symbol string, marginType MarginType) error {
+	settings, ok := w.adapter.(venueAccountSettings)
+	if !ok {
+		return fmt.Errorf("%s 不支持设置保证金模式", w.GetName())
+	}
+	return settings.SetMarginType(ctx, symbol, marginType == MarginTypeIsolated)
+}
+
+func (w *venueWrapper) SetPositionMode(ctx context.Context, mode PositionMode) error {
+	settings, ok := w.adapter.(venueAccountSettings)
+	if !ok {
+		return fmt.Errorf("%s 不支持设置持仓模式", w.GetName())
+	}
+	return settings.SetPositionMode(ctx, mode == PositionModeHedge)
+}
+
+func (w *venueWrapper) StartOrderStream(ctx context.Context, callback func(interface{})) error {
+	return w.orderStreams.subscribe(ctx, "", callback, w.adapter.StartOrderStream)
+}
+
+func (w *venueWrapper) SubscribeOrderStream(ctx context.Context, symbol string, callback func(interface{})) error {
+	return w.orderStreams.subscribe(ctx, symbol, callback, w.adapter.StartOrderStream)
+}
+
+func (w *venueWrapper) StopOrderStream() error {
+	w.orderStreams.reset()
+	return w.adapter.StopOrderStream()
+}
+
+func (w *venueWrapper) StartAccountStream(ctx context.Context, callback AccountUpdateCallback) error {
+	return fmt.Errorf("%s 不支持账户推送", w.GetName())
+}
+
+func (w *venueWrapper) GetLatestPrice(ctx context.Context, symbol string) (float64, error) {
+	return w.adapter.GetLatestPrice(ctx, symbol)
+}
+
+func (w *venueWrapper) StartPriceStream(ctx context.Context, symbol string, callback func(price float64)) error {
+	return w.adapter.StartPriceStream(ctx, symbol, callback)
+}
+
+func (w *venueWrapper) GetMarkPrice(ctx context.Context, symbol string) (float64, error) {
+	return 0, fmt.Errorf("%s 不支持查询标记价格", w.GetName())
+}
+
+func (w *venueWrapper) StartMarkPriceStream(ctx context.Context, symbol string, callback MarkPriceCallback) error {
+	return fmt.Errorf("%s 不支持标记价格推送", w.GetName())
+}
+
+func (w *venueWrapper) StartBookTickerStream(ctx context.Context, symbol string, callback BookTickerCallback) error {
+	ticker, ok := w.adapter.(venueBookTicker)
+	if !ok {
+		return fmt.Errorf("%s 不支持盘口最优价推送", w.GetName())
+	}
+	return ticker.StartBookTickerStream(ctx, symbol, callback)
+}
+
+func (w *venueWrapper) GetOrderBook(ctx context.Context, symbol string, depth int) (*OrderBook, error) {
+	return nil, fmt.Errorf("%s 不支持查询订单簿", w.GetName())
+}
+
+func (w *venueWrapper) StartDepthStream(ctx context.Context, symbol string, callback OrderBookCallback) error {
+	return fmt.Errorf("%s 不支持深度推送", w.GetName())
+}
+
+func (w *venueWrapper) GetFundingRate(ctx context.Context, symbol string) (*FundingRate, error) {
+	return nil, fmt.Errorf("%s 不支持查询资金费率", w.GetName())
+}
+
+func (w *venueWrapper) GetFundingPayments(ctx context.Context, symbol string, since time.Time) ([]*FundingPayment, error) {
+	return nil, fmt.Errorf("%s 不支持查询资金费记录", w.GetName())
+}
+
+func (w *venueWrapper) StartKlineStream(ctx context.Context, symbols []string, interval string, callback CandleUpdateCallback) error {
+	return w.adapter.StartKlineStream(ctx, symbols, interval, func(candle interface{}) {
+		if c, ok := candle.(*venue.Candle); ok {
+			callback(fromVenueCandle(c))
+		}
+	})
+}
+
+func (w *venueWrapper) RegisterKlineCallback(componentName string, callback func(candle interface{})) error {
+	return w.adapter.RegisterKlineCallback(componentName, callback)
+}
+
+func (w *venueWrapper) StopKlineStream() error {
+	return w.adapter.StopKlineStream()
+}
+
+func (w *venueWrapper) ForceReconnectKlineStream() error {
+	return w.adapter.ForceReconnectKlineStream()
+}
+
+func (w *venueWrapper) GetHistoricalKlines(ctx context.Context, symbol string, interval string, limit int) ([]*Candle, error) {
+	candles, err := w.adapter.GetHistoricalKlines(ctx, symbol, interval, limit)
+	if err != nil {
+		return nil, err
+	}
+	return fromVenueCandles(candles), nil
+}
+
+func (w *venueWrapper) GetHistoricalKlinesRange(ctx context.Context, symbol string, interval string, start, end time.Time) ([]*Candle, error) {
+	return klinesRange(ctx, w, symbol, interval, start, end, w.capabilities.KlinesPerRequest)
+}
+
+// getKlinesPage 查询开盘时间在 [from, to] 内的一页K线
+func (w *venueWrapper) getKlinesPage(ctx context.Context, symbol string, interval string, from, to time.Time) ([]*Candle, error) {
+	candles, err := w.adapter.GetKlinesBetween(ctx, symbol, interval, from.UnixMilli(), to.UnixMilli(), w.capabilities.KlinesPerRequest)
+	if err != nil {
+		return nil, err
+	}
+	return fromVenueCandles(candles), nil
+}
+
+func (w *venueWrapper) GetSymbolInfo(ctx context.Context, symbol string) (*SymbolInfo, error) {
+	info, err := w.adapter.GetSymbolInfo(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SymbolInfo{
+		Symbol:             info.Symbol,
+		BaseAsset:          info.BaseAsset,
+		QuoteAsset:         info.QuoteAsset,
+		TickSize:           info.TickSize,
+		StepSize:           info.StepSize,
+		MinQty:             info.MinQty,
+		MinNotional:        info.MinNotional,
+		ContractMultiplier: info.ContractMultiplier,
+		MaxLeverage:        info.MaxLeverage,
+		PriceDecimals:      info.PriceDecimals,
+		QuantityDecimals:   info.QuantityDecimals,
+	}, nil
+}
+
+func (w *venueWrapper) GetPriceDecimals() int {
+	return w.adapter.GetPriceDecimals()
+}
+
+func (w *venueWrapper) GetQuantityDecimals() int {
+	return w.adapter.GetQuantityDecimals()
+}
+
+func (w *venueWrapper) GetBaseAsset() string {
+	return w.adapter.GetBaseAsset()
+}
+
+func (w *venueWrapper) GetQuoteAsset() string {
+	return w.adapter.GetQuoteAsset()
+}
+
+// toVenueOrderRequest 转换下单请求
+func toVenueOrderRequest(req *OrderRequest) *venue.OrderRequest {
+	return &venue.OrderRequest{
+		Symbol:        req.Symbol,
+		Side:          venue.Side(req.Side),
+		Type:          venue.OrderType(req.Type),
+		TimeInForce:   venue.TimeInForce(req.TimeInForce),
+		Quantity:      req.Quantity,
+		Price:         req.Price,
+		ReduceOnly:    req.ReduceOnly,
+		PositionSide:  string(req.PositionSide),
+		PostOnly:      req.PostOnly,
+		PriceDecimals: req.PriceDecimals,
+		ClientOrderID: req.ClientOrderID,
+	}
+}
+
+// fromVenueOrder 转换订单
+func fromVenueOrder(ord *venue.Order) *Order {
+	return &Order{
+		OrderID:       ord.OrderID,
+		ClientOrderID: ord.ClientOrderID,
+		Symbol:        ord.Symbol,
+		Side:          Side(ord.Side),
+		Type:          OrderType(ord.Type),
+		Price:         ord.Price,
+		Quantity:      ord.Quantity,
+		ExecutedQty:   ord.ExecutedQty,
+		AvgPrice:      ord.AvgPrice,
+		Status:        OrderStatus(ord.Status),
+		CreatedAt:     ord.CreatedAt,
+		UpdateTime:    ord.UpdateTime,
+	}
+}
+
+// fromVenueOrders 转换订单列表
+func fromVenueOrders(orders []*venue.Order) []*Order {
+	result := make([]*Order, len(orders))
+	for i, ord := range orders {
+		result[i] = fromVenueOrder(ord)
+	}
+	return result
+}
+
+// fromVenuePositions 转换持仓列表
+func fromVenuePositions(positions []*venue.Position) []*Position {
+	result := make([]*Position, len(positions))
+	for i, pos := range positions {
+		result[i] = &Position{
+			Symbol:         pos.Symbol,
+			Size:           pos.Size,
+			EntryPrice:     pos.EntryPrice,
+			MarkPrice:      pos.MarkPrice,
+			UnrealizedPNL:  pos.UnrealizedPNL,
+			Leverage:       pos.Leverage,
+			MarginType:     pos.MarginType,
+			IsolatedMargin: pos.IsolatedMargin,
+			Side:           PositionSide(pos.Side),
+		}
+	}
+	return result
+}
+
+// fromVenueCandle 转换K线
+func fromVenueCandle(c *venue.Candle) *Candle {
+	return &Candle{
+		Symbol:    c.Symbol,
+		Open:      c.Open,
+		High:      c.High,
+		Low:       c.Low,
+		Close:     c.Close,
+		Volume:    c.Volume,
+		Timestamp: c.Timestamp,
+		IsClosed:  c.IsClosed,
+	}
+}
+
+// fromVenueCandles 转换K线列表
+func fromVenueCandles(candles []*venue.Candle) []*Candle {
+	result := make([]*Candle, len(candles))
+	for i, c := range candles {
+		result[i] = fromVenueCandle(c)
+	}
+	return result
+}
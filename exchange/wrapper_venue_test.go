@@ -0,0 +1,83 @@
+package exchange
+
+import (
+	"context"
+	"testing"
+
+	"opensqt/exchange/venue"
+)
+
+// fakeVenueAdapter 只实现测试用到的方法，其余方法调用时 panic
+type fakeVenueAdapter struct {
+	venueAdapter
+	lastReq *venue.OrderRequest
+}
+
+func (f *fakeVenueAdapter) GetName() string { return "Fake" }
+
+func (f *fakeVenueAdapter) PlaceOrder(ctx context.Context, req *venue.OrderRequest) (*venue.Order, error) {
+	f.lastReq = req
+	return &venue.Order{OrderID: 7, Symbol: req.Symbol, Side: req.Side, Status: venue.OrderStatusNew}, nil
+}
+
+func (f *fakeVenueAdapter) GetPositions(ctx context.Context, symbol string) ([]*venue.Position, error) {
+	return []*venue.Position{{Symbol: symbol, Size: -0.2, Side: "SHORT"}}, nil
+}
+
+// fakeSettingsAdapter 支持账户设置的适配器
+type fakeSettingsAdapter struct {
+	fakeVenueAdapter
+	leverage int
+}
+
+func (f *fakeSettingsAdapter) SetLeverage(ctx context.Context, symbol string, leverage int) error {
+	f.leverage = leverage
+	return nil
+}
+
+func (f *fakeSettingsAdapter) SetMarginType(ctx context.Context, symbol string, isolated bool) error {
+	return nil
+}
+
+func (f *fakeSettingsAdapter) SetPositionMode(ctx context.Context, hedge bool) error {
+	return nil
+}
+
+func TestVenueWrapperConvertsAndDetectsOptionalFeatures(t *testing.T) {
+	ctx := context.Background()
+	adapter := &fakeVenueAdapter{}
+	w := &venueWrapper{adapter: adapter}
+
+	order, err := w.PlaceOrder(ctx, &OrderRequest{Symbol: "ETHUSDT", Side: SideBuy, PositionSide: PositionSideShort, PostOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if adapter.lastReq.PositionSide != "SHORT" || !adapter.lastReq.PostOnly {
+		t.Errorf("下单请求转换错误: %+v", adapter.lastReq)
+	}
+	if order.OrderID != 7 || order.Side != SideBuy || order.Status != OrderStatusNew {
+		t.Errorf("订单转换错误: %+v", order)
+	}
+
+	positions, err := w.GetPositions(ctx, "ETHUSDT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(positions) != 1 || positions[0].Side != PositionSideShort || positions[0].Size != -0.2 {
+		t.Errorf("持仓转换错误: %+v", positions)
+	}
+
+	// 未实现的可选功能返回错误而不是 panic
+	if err := w.SetLeverage(ctx, "ETHUSDT", 5); err == nil {
+		t.Error("适配器不支持设置杠杆时应返回错误")
+	}
+	if err := w.StartBookTickerStream(ctx, "ETHUSDT", nil); err == nil {
+		t.Error("适配器不支持盘口推送时应返回错误")
+	}
+
+	settings := &fakeSettingsAdapter{}
+	w = &venueWrapper{adapter: settings}
+	if err := w.SetLeverage(ctx, "ETHUSDT", 5); err != nil || settings.leverage != 5 {
+		t.Errorf("设置杠杆应转发给适配器: err=%v, leverage=%d", err, settings.leverage)
+	}
+}
@@ -0,0 +1,114 @@
+package wsapi
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"opensqt/logger"
+)
+
+// 下单通道
+const (
+	PathWebSocket = "WebSocket"
+	PathREST      = "REST"
+)
+
+const (
+	// latencyWindow 每个通道保留最近多少笔下单确认延迟用于统计
+	latencyWindow = 200
+	// latencyReportEvery 每个通道每累计多少笔打印一次延迟统计
+	latencyReportEvery = 100
+)
+
+// LatencyStats 单个下单通道的确认延迟统计（最近 latencyWindow 笔）
+type LatencyStats struct {
+	Path  string
+	Count int // 累计笔数
+	Avg   time.Duration
+	P50   time.Duration
+	P99   time.Duration
+	Max   time.Duration
+}
+
+// LatencyRecorder 按下单通道统计下单请求从发出到收到交易所确认的耗时
+type LatencyRecorder struct {
+	name string // 日志前缀中的交易所名称
+
+	mu      sync.Mutex
+	samples map[string][]time.Duration // 通道 -> 最近的延迟（环形缓冲）
+	counts  map[string]int             // 通道 -> 累计笔数
+}
+
+// NewLatencyRecorder 创建延迟统计器
+func NewLatencyRecorder(name string) *LatencyRecorder {
+	return &LatencyRecorder{
+		name:    name,
+		samples: make(map[string][]time.Duration),
+		counts:  make(map[string]int),
+	}
+}
+
+// Record 记录一笔下单确认延迟，每累计 latencyReportEvery 笔打印一次统计（nil 时忽略）
+func (r *LatencyRecorder) Record(path string, d time.Duration) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	count := r.counts[path]
+	if samples := r.samples[path]; len(samples) < latencyWindow {
+		r.samples[path] = append(samples, d)
+	} else {
+		samples[count%latencyWindow] = d
+	}
+	r.counts[path] = count + 1
+	report := (count+1)%latencyReportEvery == 0
+	var stats LatencyStats
+	if report {
+		stats = r.statsLocked(path)
+	}
+	r.mu.Unlock()
+
+	if report {
+		logger.Info("ℹ️ [%s 下单延迟] %s: 累计 %d 笔, 平均 %v, P50 %v, P99 %v, 最大 %v",
+			r.name, stats.Path, stats.Count, stats.Avg, stats.P50, stats.P99, stats.Max)
+	}
+}
+
+// Stats 获取各下单通道的延迟统计
+func (r *LatencyRecorder) Stats() []LatencyStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	paths := make([]string, 0, len(r.samples))
+	for path := range r.samples {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	stats := make([]LatencyStats, 0, len(paths))
+	for _, path := range paths {
+		stats = append(stats, r.statsLocked(path))
+	}
+	return stats
+}
+
+// statsLocked 计算单个通道的统计，调用方需持有锁
+func (r *LatencyRecorder) statsLocked(path string) LatencyStats {
+	sorted := append([]time.Duration(nil), r.samples[path]...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	stats := LatencyStats{Path: path, Count: r.counts[path]}
+	if len(sorted) == 0 {
+		return stats
+	}
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	stats.Avg = (total / time.Duration(len(sorted))).Round(time.Microsecond)
+	stats.P50 = sorted[len(sorted)/2].Round(time.Microsecond)
+	stats.P99 = sorted[(len(sorted)*99)/100].Round(time.Microsecond)
+	stats.Max = sorted[len(sorted)-1].Round(time.Microsecond)
+	return stats
+}
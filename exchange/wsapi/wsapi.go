@@ -0,0 +1,117 @@
+// Package wsapi WebSocket 交易接口（下单）的公共部分：请求/响应关联与下单确认延迟统计
+// 连接、签名与报文格式由各交易所子包负责，这里只负责把响应按请求ID交还给等待方。
+// 独立成包是为了让各交易所子包共用而不产生循环依赖（同 errs 包）。
+package wsapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrNotConnected 连接未建立/未认证或请求未能发出，调用方可以安全地改用 REST 下单
+	ErrNotConnected = errors.New("WebSocket 交易连接不可用")
+	// ErrTimeout 请求已发出但未在超时时间内收到响应，订单状态未知，不能直接改用 REST 重下
+	ErrTimeout = errors.New("WebSocket 请求超时")
+	// ErrDisconnected 请求已发出但连接在响应前断开，订单状态未知
+	ErrDisconnected = errors.New("WebSocket 连接已断开")
+)
+
+const (
+	// DefaultTimeout 单个请求等待响应的默认超时
+	DefaultTimeout = 5 * time.Second
+	// PingInterval 交易连接主动发送 ping 的间隔
+	PingInterval = 20 * time.Second
+	// ReadTimeout 交易连接的读超时：超过该时间未收到任何消息（含 pong）视为半开连接，断开重连
+	ReadTimeout = 3 * PingInterval
+)
+
+// Result 请求的响应：Data 为交易所返回的结果部分，Err 为已分类的交易所错误
+type Result struct {
+	Data []byte
+	Err  error
+}
+
+// Pending 等待响应的请求表，零值可用
+type Pending struct {
+	seq uint64
+
+	mu      sync.Mutex
+	waiters map[string]chan Result
+}
+
+// NextID 生成进程内唯一的请求ID
+func (p *Pending) NextID() string {
+	return strconv.FormatUint(atomic.AddUint64(&p.seq, 1), 10)
+}
+
+// Do 登记请求并通过 send 发出，等待对应ID的响应
+// send 失败时返回 ErrNotConnected；ctx 取消或超时返回 ErrTimeout；连接断开返回 ErrDisconnected
+func (p *Pending) Do(ctx context.Context, timeout time.Duration, send func(id string) error) ([]byte, error) {
+	id := p.NextID()
+	ch := make(chan Result, 1)
+
+	p.mu.Lock()
+	if p.waiters == nil {
+		p.waiters = make(map[string]chan Result)
+	}
+	p.waiters[id] = ch
+	p.mu.Unlock()
+
+	if err := send(id); err != nil {
+		p.remove(id)
+		return nil, fmt.Errorf("%w: %v", ErrNotConnected, err)
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case result := <-ch:
+		return result.Data, result.Err
+	case <-timer.C:
+		p.remove(id)
+		return nil, fmt.Errorf("%w: 请求 %s 超过 %v 未响应", ErrTimeout, id, timeout)
+	case <-ctx.Done():
+		p.remove(id)
+		return nil, fmt.Errorf("%w: %v", ErrTimeout, ctx.Err())
+	}
+}
+
+// Resolve 将响应交给等待中的请求，请求已超时或ID未知时返回 false
+func (p *Pending) Resolve(id string, data []byte, err error) bool {
+	p.mu.Lock()
+	ch, ok := p.waiters[id]
+	delete(p.waiters, id)
+	p.mu.Unlock()
+
+	if !ok {
+		return false
+	}
+	ch <- Result{Data: data, Err: err}
+	return true
+}
+
+// FailAll 连接断开时让所有等待中的请求以 err 结束
+func (p *Pending) FailAll(err error) {
+	p.mu.Lock()
+	waiters := p.waiters
+	p.waiters = nil
+	p.mu.Unlock()
+
+	for _, ch := range waiters {
+		ch <- Result{Err: err}
+	}
+}
+
+// remove 移除等待中的请求（超时或发送失败）
+func (p *Pending) remove(id string) {
+	p.mu.Lock()
+	delete(p.waiters, id)
+	p.mu.Unlock()
+}
@@ -0,0 +1,85 @@
+package wsapi
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPendingResolvesMatchingID(t *testing.T) {
+	var p Pending
+
+	data, err := p.Do(context.Background(), time.Second, func(id string) error {
+		// 先到达的无关响应不应被交给当前请求
+		if p.Resolve("unknown", []byte("other"), nil) {
+			t.Errorf("未登记的请求ID不应被处理")
+		}
+		go p.Resolve(id, []byte("ok"), nil)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Do 返回错误: %v", err)
+	}
+	if string(data) != "ok" {
+		t.Errorf("data = %q, want ok", data)
+	}
+}
+
+func TestPendingSendFailureIsNotConnected(t *testing.T) {
+	var p Pending
+
+	_, err := p.Do(context.Background(), time.Second, func(id string) error {
+		return errors.New("连接未建立")
+	})
+	if !errors.Is(err, ErrNotConnected) {
+		t.Fatalf("err = %v, want ErrNotConnected", err)
+	}
+}
+
+func TestPendingTimeoutAndDisconnect(t *testing.T) {
+	var p Pending
+
+	var sentID string
+	_, err := p.Do(context.Background(), 10*time.Millisecond, func(id string) error {
+		sentID = id
+		return nil
+	})
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("err = %v, want ErrTimeout", err)
+	}
+	if p.Resolve(sentID, []byte("late"), nil) {
+		t.Errorf("超时后到达的响应不应再被处理")
+	}
+
+	_, err = p.Do(context.Background(), time.Second, func(id string) error {
+		go p.FailAll(ErrDisconnected)
+		return nil
+	})
+	if !errors.Is(err, ErrDisconnected) {
+		t.Fatalf("err = %v, want ErrDisconnected", err)
+	}
+}
+
+func TestLatencyRecorderStats(t *testing.T) {
+	r := NewLatencyRecorder("Test")
+	for i := 1; i <= 10; i++ {
+		r.Record(PathWebSocket, time.Duration(i)*time.Millisecond)
+	}
+	r.Record(PathREST, 50*time.Millisecond)
+
+	stats := r.Stats()
+	if len(stats) != 2 {
+		t.Fatalf("len(stats) = %d, want 2", len(stats))
+	}
+	rest, ws := stats[0], stats[1]
+	if rest.Path != PathREST || rest.Count != 1 || rest.Max != 50*time.Millisecond {
+		t.Errorf("REST stats = %+v", rest)
+	}
+	if ws.Count != 10 || ws.Max != 10*time.Millisecond || ws.P50 != 6*time.Millisecond {
+		t.Errorf("WebSocket stats = %+v", ws)
+	}
+	if ws.Avg != 5500*time.Microsecond {
+		t.Errorf("WebSocket avg = %v, want 5.5ms", ws.Avg)
+	}
+}
@@ -20,8 +20,9 @@ import (
 // Config holds the configuration from config.yaml
 type Config struct {
 	Binance struct {
-		APIKey    string `yaml:"api_key"`
-		SecretKey string `yaml:"secret_key"`
+		APIKey      string `yaml:"api_key"`
+		SecretKey   string `yaml:"secret_key"`
+		Environment string `yaml:"environment"` // mainnet 或 testnet
 	} `yaml:"binance"`
 	Trading struct {
 		Symbol string `yaml:"symbol"`
@@ -148,7 +149,13 @@ func main() {
 	go hub.run()
 
 	// Initialize Binance Futures Client (U本位合约)
-	futures.UseTestnet = false // 设置为 true 如果使用测试网
+	switch config.Binance.Environment {
+	case "", "mainnet":
+	case "testnet":
+		futures.UseTestnet = true
+	default:
+		log.Fatalf("Unsupported binance environment %q (mainnet or testnet)", config.Binance.Environment)
+	}
 	client := binance.NewFuturesClient(config.Binance.APIKey, config.Binance.SecretKey)
 
 	// Function to fetch and send initial account info and open orders (Futures)
@@ -61,6 +61,7 @@ func main() {
 		cfg.Trading.Symbol,
 		cfg.Timing.PriceSendInterval,
 	)
+	priceMonitor.SetPriceSource(cfg.Trading.PriceSource) // last / mid / bid_ask
 
 	// 4. 启动价格监控（WebSocket 必须成功）
 	logger.Info("🔗 启动 WebSocket 价格流...")
@@ -92,6 +93,53 @@ func main() {
 	logger.Info("ℹ️ 交易精度 - 价格精度:%d, 数量精度:%d", priceDecimals, quantityDecimals)
 	logger.Debug("📊 当前价格: %.*f", priceDecimals, currentPrice)
 
+	// 从交易所获取交易规则（价格/数量步长、最小名义价值），失败时仅按精度取整
+	symbolCtx, symbolCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	symbolInfo, err := ex.GetSymbolInfo(symbolCtx, cfg.Trading.Symbol)
+	symbolCancel()
+	if err != nil {
+		logger.Warn("⚠️ 获取交易规则失败，将仅按精度取整: %v", err)
+	} else {
+		logger.Info("ℹ️ 交易规则 - 价格步长:%g, 数量步长:%g, 最小数量:%g, 最小名义价值:%g, 合约乘数:%g, 最大杠杆:%dx",
+			symbolInfo.TickSize, symbolInfo.StepSize, symbolInfo.MinQty, symbolInfo.MinNotional,
+			symbolInfo.ContractMultiplier, symbolInfo.MaxLeverage)
+	}
+
+	// 现货网格：无杠杆、保证金模式与持仓模式，跳过账户设置
+	spotMode := cfg.Trading.MarketType == "spot"
+	if spotMode && !ex.Capabilities().Spot {
+		logger.Fatalf("❌ %s 不支持现货网格，请将 trading.market_type 设置为 futures", ex.GetName())
+	}
+
+	// 币本位合约按张下单，必须获取到合约面值才能换算每单张数
+	if cfg.Trading.MarketType == "inverse" {
+		if !ex.Capabilities().Inverse {
+			logger.Fatalf("❌ %s 不支持币本位合约，请将 trading.market_type 设置为 futures", ex.GetName())
+		}
+		if symbolInfo == nil || symbolInfo.ContractValue <= 0 {
+			logger.Fatalf("❌ 未获取到 %s 的合约面值，无法按张下单", cfg.Trading.Symbol)
+		}
+		logger.Info("ℹ️ 币本位合约：每张面值 %g USD，每单 %.0f USD（%g 张），保证金与盈亏以 %s 计",
+			symbolInfo.ContractValue, cfg.Trading.OrderQuantity,
+			cfg.Trading.OrderQuantity/symbolInfo.ContractValue, ex.GetBaseAsset())
+	}
+
+	// 按配置设置杠杆、保证金模式与持仓模式（新账户无需手动在交易所设置）
+	if cfg.Trading.PositionMode == string(exchange.PositionModeHedge) && !ex.Capabilities().HedgeMode {
+		logger.Fatalf("❌ %s 不支持双向持仓模式下单，请将 trading.position_mode 设置为 one_way", ex.GetName())
+	}
+	if !spotMode {
+		if err := safety.ConfigureAccount(
+			ex,
+			cfg.Trading.Symbol,
+			cfg.Trading.Leverage,
+			cfg.Trading.MarginType,
+			cfg.Trading.PositionMode,
+		); err != nil {
+			logger.Fatalf("❌ %v", err)
+		}
+	}
+
 	// 6. 持仓安全性检查（必须在开始交易之前执行）
 	requiredPositions := cfg.Trading.PositionSafetyCheck
 	if requiredPositions <= 0 {
@@ -128,8 +176,21 @@ func main() {
 	executorAdapter := &exchangeExecutorAdapter{executor: exchangeExecutor}
 
 	// 创建交易所适配器（匹配 position.IExchange 接口）
-	exchangeAdapter := &positionExchangeAdapter{exchange: ex}
+	// 可用余额与持仓通过本地账户缓存查询（支持账户推送的交易所由推送更新）
+	accountCache := exchange.NewAccountCache(ex)
+	exchangeAdapter := &positionExchangeAdapter{exchange: ex, account: accountCache}
 	superPositionManager := position.NewSuperPositionManager(cfg, executorAdapter, exchangeAdapter, priceDecimals, quantityDecimals)
+	superPositionManager.SetMaxClientOrderIDLen(ex.Capabilities().MaxClientOrderIDLen)
+	superPositionManager.SetPriceMonitor(priceMonitor) // 盘口买一/卖一价（支持深度推送的交易所）
+	if symbolInfo != nil {
+		superPositionManager.SetSymbolRules(position.SymbolRules{
+			TickSize:      symbolInfo.TickSize,
+			StepSize:      symbolInfo.StepSize,
+			MinQty:        symbolInfo.MinQty,
+			MinNotional:   symbolInfo.MinNotional,
+			ContractValue: symbolInfo.ContractValue,
+		})
+	}
 
 	// === 新增：初始化动态网格计算器（如果启用）===
 	var atrCalculator *monitor.ATRCalculator
@@ -193,6 +254,41 @@ func main() {
 	reconciler.SetPauseChecker(func() bool {
 		return riskMonitor.IsTriggered()
 	})
+	// 成交补录：订单流断线期间遗漏的成交，在对账时按成交记录补录（交易所支持查询成交记录时）
+	if ex.Capabilities().MyTrades {
+		reconciler.SetTradeBackfill(ex, superPositionManager.HasSeenTrade, func(trades []*exchange.Trade, order *exchange.Order) {
+			for i, trade := range trades {
+				update := position.OrderUpdate{
+					OrderID:       trade.OrderID,
+					ClientOrderID: trade.ClientOrderID,
+					Symbol:        trade.Symbol,
+					Side:          string(trade.Side),
+					Fill: &position.FillEvent{
+						TradeID:         trade.TradeID,
+						Price:           trade.Price,
+						Quantity:        trade.Quantity,
+						Commission:      trade.Commission,
+						CommissionAsset: trade.CommissionAsset,
+						IsMaker:         trade.IsMaker,
+						Time:            trade.Time,
+					},
+				}
+				if update.ClientOrderID == "" {
+					update.ClientOrderID = order.ClientOrderID // Binance、Bitget 成交记录不返回自定义订单ID
+				}
+				// 最后一笔成交携带订单最新状态推进槽位，之前的成交只记录成交明细
+				if i == len(trades)-1 {
+					update.Status = string(order.Status)
+					update.Type = string(order.Type)
+					update.Price = order.Price
+					update.ExecutedQty = order.ExecutedQty
+					update.AvgPrice = order.AvgPrice
+					update.UpdateTime = order.UpdateTime
+				}
+				superPositionManager.BackfillOrderUpdate(update)
+			}
+		})
+	}
 
 	// 9. 启动组件
 	ctx, cancel := context.WithCancel(context.Background())
@@ -203,8 +299,9 @@ func main() {
 	// 架构说明：
 	// - 订单流与价格流共用同一个 WebSocket 连接（对于支持的交易所）
 	// - 订单更新通过回调函数实时推送给 SuperPositionManager
+	// - 按交易对订阅，同一账户的其他交易对订单不会推送到本网格
 	//logger.Info("🔗 启动 WebSocket 订单流...")
-	if err := ex.StartOrderStream(ctx, func(updateInterface interface{}) {
+	if err := ex.SubscribeOrderStream(ctx, cfg.Trading.Symbol, func(updateInterface interface{}) {
 		// 使用反射提取字段（兼容匿名结构体）
 		v := reflect.ValueOf(updateInterface)
 		if v.Kind() != reflect.Struct {
@@ -249,6 +346,7 @@ func main() {
 			Side:          getStringField("Side"),
 			Type:          getStringField("Type"),
 			UpdateTime:    getInt64Field("UpdateTime"),
+			Fill:          extractFill(v.FieldByName("Fill")),
 		}
 
 		logger.Debug("🔍 [main.go] 收到订单更新回调: ID=%d, ClientOID=%s, Price=%.2f, Status=%s",
@@ -260,6 +358,11 @@ func main() {
 		logger.Info("✅ [%s] 订单流已启动", ex.GetName())
 	}
 
+	// 启动账户推送（余额与持仓），供保证金检查与对账使用
+	if err := accountCache.Start(ctx); err != nil {
+		logger.Warn("⚠️ 启动账户缓存失败: %v (余额与持仓将直接通过 REST 查询)", err)
+	}
+
 	// 初始化超级仓位管理器（设置价格锚点并创建初始槽位）
 	// 注意：必须在订单流启动后再初始化，避免错过买单成交推送
 	if err := superPositionManager.Initialize(currentPrice, currentPriceStr); err != nil {
@@ -269,8 +372,22 @@ func main() {
 	// 启动持仓对账（使用独立的 Reconciler）
 	reconciler.Start(ctx)
 
+	// 启动资金费统计（资金费按持仓分摊到多仓槽位，计入网格收益；现货没有资金费）
+	if !spotMode {
+		fundingMonitor := monitor.NewFundingMonitor(ex, cfg.Trading.Symbol, func(payment *exchange.FundingPayment) {
+			superPositionManager.OnFundingPayment(position.FundingPayment{
+				ID:     payment.ID,
+				Amount: payment.Amount,
+				Asset:  payment.Asset,
+				Time:   payment.Time,
+			})
+		})
+		fundingMonitor.Start(ctx)
+	}
+
 	// === 创建订单清理器（从仓位管理器剥离） ===
 	orderCleaner := safety.NewOrderCleaner(cfg, exchangeExecutor, superPositionManager)
+	orderCleaner.SetCapabilities(ex.Capabilities())
 	// 启动订单清理协程
 	orderCleaner.Start(ctx)
 
@@ -337,7 +454,7 @@ func main() {
 			}
 
 			// 实时调整订单，不打印价格变化日志（避免日志过多）
-			if err := superPositionManager.AdjustOrders(priceChange.NewPrice); err != nil {
+			if err := superPositionManager.AdjustOrdersWithQuote(priceChange.NewPrice, priceChange.Bid, priceChange.Ask); err != nil {
 				logger.Error("❌ 调整订单失败: %v", err)
 			}
 		}
@@ -423,10 +540,11 @@ func main() {
 // positionExchangeAdapter 适配器，将 exchange.IExchange 转换为 position.IExchange
 type positionExchangeAdapter struct {
 	exchange exchange.IExchange
+	account  *exchange.AccountCache
 }
 
 func (a *positionExchangeAdapter) GetPositions(ctx context.Context, symbol string) (interface{}, error) {
-	positions, err := a.exchange.GetPositions(ctx, symbol)
+	positions, err := a.account.Positions(ctx, symbol)
 	if err != nil {
 		return nil, err
 	}
@@ -455,6 +573,10 @@ func (a *positionExchangeAdapter) GetBaseAsset() string {
 	return a.exchange.GetBaseAsset()
 }
 
+func (a *positionExchangeAdapter) GetQuoteAsset() string {
+	return a.exchange.GetQuoteAsset()
+}
+
 func (a *positionExchangeAdapter) GetName() string {
 	return a.exchange.GetName()
 }
@@ -464,11 +586,11 @@ func (a *positionExchangeAdapter) CancelAllOrders(ctx context.Context, symbol st
 }
 
 func (a *positionExchangeAdapter) GetAvailableBalance(ctx context.Context) (float64, error) {
-	account, err := a.exchange.GetAccount(ctx)
-	if err != nil {
-		return 0, err
-	}
-	return account.AvailableBalance, nil
+	return a.account.AvailableBalance(ctx)
+}
+
+func (a *positionExchangeAdapter) GetFreeBaseBalance(ctx context.Context) (float64, error) {
+	return a.account.FreeBaseBalance(ctx)
 }
 
 // exchangeExecutorAdapter 适配器，将 order.ExchangeOrderExecutor 转换为 position.OrderExecutorInterface
@@ -484,6 +606,7 @@ func (a *exchangeExecutorAdapter) PlaceOrder(req *position.OrderRequest) (*posit
 		Quantity:      req.Quantity,
 		PriceDecimals: req.PriceDecimals,
 		ReduceOnly:    req.ReduceOnly,
+		PositionSide:  req.PositionSide,
 		PostOnly:      req.PostOnly,      // 传递 PostOnly 参数
 		ClientOrderID: req.ClientOrderID, // 传递 ClientOrderID
 	}
@@ -513,6 +636,7 @@ func (a *exchangeExecutorAdapter) BatchPlaceOrders(orders []*position.OrderReque
 			Quantity:      req.Quantity,
 			PriceDecimals: req.PriceDecimals,
 			ReduceOnly:    req.ReduceOnly,
+			PositionSide:  req.PositionSide,
 			PostOnly:      req.PostOnly,      // 传递 PostOnly 参数
 			ClientOrderID: req.ClientOrderID, // 传递 ClientOrderID
 		}
@@ -537,3 +661,38 @@ func (a *exchangeExecutorAdapter) BatchPlaceOrders(orders []*position.OrderReque
 func (a *exchangeExecutorAdapter) BatchCancelOrders(orderIDs []int64) error {
 	return a.executor.BatchCancelOrders(orderIDs)
 }
+
+// extractFill 从子包订单更新的 Fill 字段（*FillEvent 指针）中提取成交明细
+func extractFill(field reflect.Value) *position.FillEvent {
+	if !field.IsValid() || field.Kind() != reflect.Ptr || field.IsNil() {
+		return nil
+	}
+	f := field.Elem()
+	if f.Kind() != reflect.Struct {
+		return nil
+	}
+
+	fill := &position.FillEvent{}
+	if v := f.FieldByName("TradeID"); v.IsValid() && v.Kind() == reflect.String {
+		fill.TradeID = v.String()
+	}
+	if v := f.FieldByName("Price"); v.IsValid() && v.CanFloat() {
+		fill.Price = v.Float()
+	}
+	if v := f.FieldByName("Quantity"); v.IsValid() && v.CanFloat() {
+		fill.Quantity = v.Float()
+	}
+	if v := f.FieldByName("Commission"); v.IsValid() && v.CanFloat() {
+		fill.Commission = v.Float()
+	}
+	if v := f.FieldByName("CommissionAsset"); v.IsValid() && v.Kind() == reflect.String {
+		fill.CommissionAsset = v.String()
+	}
+	if v := f.FieldByName("IsMaker"); v.IsValid() && v.Kind() == reflect.Bool {
+		fill.IsMaker = v.Bool()
+	}
+	if v := f.FieldByName("Time"); v.IsValid() && v.CanInt() {
+		fill.Time = v.Int()
+	}
+	return fill
+}
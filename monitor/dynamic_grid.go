@@ -49,7 +49,7 @@ func (d *DynamicGridCalculator) CalculateDynamicInterval(currentPrice float64) f
 	if minProfitRate <= 0 {
 		minProfitRate = 0.001 // 默认0.1%
 	}
-	breakEvenInterval := currentPrice * (feeRate*2 + minProfitRate)
+	breakEvenInterval := d.breakEvenInterval(currentPrice, feeRate, minProfitRate)
 
 	// 3. ATR动态间距 = ATR × 系数
 	atrMultiplier := d.cfg.Trading.DynamicGrid.ATRMultiplier
@@ -112,7 +112,7 @@ func (d *DynamicGridCalculator) GetIntervalComponents(currentPrice float64) (bas
 	if minProfitRate <= 0 {
 		minProfitRate = 0.001
 	}
-	breakEven = currentPrice * (feeRate*2 + minProfitRate)
+	breakEven = d.breakEvenInterval(currentPrice, feeRate, minProfitRate)
 
 	atrMultiplier := d.cfg.Trading.DynamicGrid.ATRMultiplier
 	if atrMultiplier <= 0 {
@@ -136,6 +136,18 @@ func (d *DynamicGridCalculator) GetIntervalComponents(currentPrice float64) (bas
 	return
 }
 
+// breakEvenInterval 保本间距
+// U本位与现货：价格 × (手续费率 × 2 + 最小利润率)；
+// 币本位合约按张数下单，盈亏与手续费以基础币种计，每格利润 面值×(1/p - 1/(p+间距))，
+// 需满足 间距 ≥ 价格 × (手续费率 × 2 + 最小利润率) / (1 - 手续费率 - 最小利润率)
+func (d *DynamicGridCalculator) breakEvenInterval(currentPrice, feeRate, minProfitRate float64) float64 {
+	interval := currentPrice * (feeRate*2 + minProfitRate)
+	if d.cfg.Trading.MarketType == "inverse" && feeRate+minProfitRate < 1 {
+		interval /= 1 - feeRate - minProfitRate
+	}
+	return interval
+}
+
 // getExchangeFeeRate 获取当前交易所的手续费率
 func (d *DynamicGridCalculator) getExchangeFeeRate() float64 {
 	exchangeName := d.cfg.App.CurrentExchange
@@ -0,0 +1,72 @@
+package monitor
+
+import (
+	"context"
+	"time"
+
+	"opensqt/exchange"
+	"opensqt/logger"
+)
+
+// fundingPollInterval 资金费记录轮询间隔（资金费每 1~8 小时结算一次）
+const fundingPollInterval = 10 * time.Minute
+
+// FundingMonitor 资金费监控器
+// 定期拉取启动后的资金费收付记录并回调，同一记录可能被重复回调，由调用方按记录ID去重
+type FundingMonitor struct {
+	exchange  exchange.IExchange
+	symbol    string
+	since     time.Time
+	onPayment func(payment *exchange.FundingPayment)
+}
+
+// NewFundingMonitor 创建资金费监控器
+func NewFundingMonitor(ex exchange.IExchange, symbol string, onPayment func(payment *exchange.FundingPayment)) *FundingMonitor {
+	return &FundingMonitor{
+		exchange:  ex,
+		symbol:    symbol,
+		since:     time.Now(),
+		onPayment: onPayment,
+	}
+}
+
+// Start 启动资金费监控（交易所不支持资金费查询时直接返回）
+func (m *FundingMonitor) Start(ctx context.Context) {
+	rate, err := m.exchange.GetFundingRate(ctx, m.symbol)
+	if err != nil {
+		logger.Warn("⚠️ [资金费] 查询资金费率失败，不统计资金费: %v", err)
+		return
+	}
+	logger.Info("✅ [资金费] %s 当期资金费率: %.4f%%, 标记价格: %.4f", m.symbol, rate.FundingRate*100, rate.MarkPrice)
+
+	go func() {
+		ticker := time.NewTicker(fundingPollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.poll(ctx)
+			}
+		}
+	}()
+}
+
+// poll 拉取 since 之后的资金费记录
+func (m *FundingMonitor) poll(ctx context.Context) {
+	payments, err := m.exchange.GetFundingPayments(ctx, m.symbol, m.since)
+	if err != nil {
+		logger.Warn("⚠️ [资金费] 查询资金费记录失败: %v", err)
+		return
+	}
+
+	for _, payment := range payments {
+		m.onPayment(payment)
+		// 下次从最后一条记录的时间开始查询（包含该时间点，重复记录由调用方去重）
+		if t := time.UnixMilli(payment.Time); t.After(m.since) {
+			m.since = t
+		}
+	}
+}
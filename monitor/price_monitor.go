@@ -3,6 +3,7 @@ package monitor
 import (
 	"context"
 	"fmt"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -27,16 +28,45 @@ PriceMonitor 架构说明：
    - 依赖 exchange.IExchange 接口
    - 通过 exchange.StartPriceStream() 启动 WebSocket
    - WebSocket 是唯一的价格来源
+
+4. **盘口**：
+   - 价格来源为 mid / bid_ask 且交易所支持深度推送（Capabilities().DepthStream）时同时启动 StartDepthStream
+   - 通过 GetBestBidAsk() / GetOrderBook() 获取本地维护的订单簿，深度流不可用时返回空值
+
+5. **价格来源**（SetPriceSource，需在 Start 之前调用）：
+   - last：最新成交价驱动（默认）
+   - mid / bid_ask：额外启动 StartBookTickerStream，NewPrice 为买一卖一中间价，盘口更新也会产生事件
+   - 盘口最优价推送不可用或超过 orderBookStaleAfter 未更新时回退为最新成交价
 */
 
+// orderBookStaleAfter 订单簿/盘口最优价超过该时间未更新视为失效（断线重连期间）
+const orderBookStaleAfter = 5 * time.Second
+
+// 价格来源
+const (
+	PriceSourceLast   = "last"    // 最新成交价
+	PriceSourceMid    = "mid"     // 买一卖一中间价
+	PriceSourceBidAsk = "bid_ask" // 买单锚定买一、卖单锚定卖一（NewPrice 为中间价）
+)
+
 // PriceChange 价格变化事件
+// NewPrice 为驱动挂单的价格（由价格来源决定），Bid/Ask 为盘口买一/卖一（不可用时为 0），Last 为最新成交价
 type PriceChange struct {
 	OldPrice  float64
 	NewPrice  float64
 	Change    float64
+	Bid       float64
+	Ask       float64
+	Last      float64
 	Timestamp time.Time
 }
 
+// bookQuote 盘口最优价及本地接收时间
+type bookQuote struct {
+	bid, ask   float64
+	receivedAt time.Time
+}
+
 // PriceMonitor 价格监控器
 type PriceMonitor struct {
 	symbol        string
@@ -44,6 +74,15 @@ type PriceMonitor struct {
 	lastPrice     atomic.Value       // float64
 	lastPriceStr  atomic.Value       // string - 原始价格字符串（用于检测小数位数）
 	lastPriceTime atomic.Value       // time.Time
+	orderBook     atomic.Value       // *exchange.OrderBook - 深度推送维护的最新订单簿
+	bookQuote     atomic.Value       // bookQuote - 盘口最优价推送的买一/卖一
+	priceSource   string             // 价格来源：last/mid/bid_ask
+
+	// publishMu 串行化成交价与盘口两路推送生成事件
+	publishMu   sync.Mutex
+	drivenPrice float64 // 上一次事件的 NewPrice
+	drivenBid   float64
+	drivenAsk   float64
 
 	priceChangeCh     chan PriceChange
 	latestPriceChange atomic.Value // *PriceChange - 保存最新的价格更新（不阻塞）
@@ -69,14 +108,30 @@ func NewPriceMonitor(ex exchange.IExchange, symbol string, priceSendInterval int
 		ctx:               ctx,
 		cancel:            cancel,
 		priceSendInterval: time.Duration(priceSendInterval) * time.Millisecond,
+		priceSource:       PriceSourceLast,
 	}
 	pm.lastPrice.Store(0.0)
 	pm.lastPriceStr.Store("")
 	pm.lastPriceTime.Store(time.Time{})
 	pm.latestPriceChange.Store((*PriceChange)(nil))
+	pm.orderBook.Store((*exchange.OrderBook)(nil))
+	pm.bookQuote.Store(bookQuote{})
 	return pm
 }
 
+// SetPriceSource 设置驱动挂单的价格来源（last/mid/bid_ask，空值为 last），需在 Start 之前调用
+func (pm *PriceMonitor) SetPriceSource(source string) {
+	if source == "" {
+		source = PriceSourceLast
+	}
+	pm.priceSource = source
+}
+
+// PriceSource 当前生效的价格来源（盘口最优价推送不可用时为 last）
+func (pm *PriceMonitor) PriceSource() string {
+	return pm.priceSource
+}
+
 // Start 启动价格监控
 func (pm *PriceMonitor) Start() error {
 	if pm.isRunning.Load() {
@@ -85,6 +140,11 @@ func (pm *PriceMonitor) Start() error {
 
 	pm.isRunning.Store(true)
 
+	// 盘口最优价流先于价格流启动，回退价格来源时不会与推送回调并发
+	if pm.priceSource != PriceSourceLast {
+		pm.startBookTicker()
+	}
+
 	// 首先尝试启动价格流（WebSocket）
 	logger.Info("🔗 [价格监控] 尝试启动 WebSocket 价格流...")
 	err := pm.exchange.StartPriceStream(pm.ctx, pm.symbol, func(price float64) {
@@ -121,10 +181,40 @@ func (pm *PriceMonitor) Start() error {
 		logger.Info("✅ [价格监控] WebSocket 价格流已启动")
 	}
 
+	// 深度流只服务于盘口价格来源，失败不影响价格监控，仅无法提供盘口
+	if pm.priceSource != PriceSourceLast && pm.exchange.Capabilities().DepthStream {
+		err := pm.exchange.StartDepthStream(pm.ctx, pm.symbol, func(book *exchange.OrderBook) {
+			pm.orderBook.Store(book)
+		})
+		if err != nil {
+			logger.Warn("⚠️ [价格监控] 深度流启动失败: %v，盘口数据不可用", err)
+		} else {
+			logger.Info("✅ [价格监控] 深度流已启动")
+		}
+	}
+
 	go pm.periodicPriceSender() // 启动定期发送协程
 	return nil
 }
 
+// startBookTicker 启动盘口最优价推送，不支持或启动失败时回退为最新成交价驱动
+func (pm *PriceMonitor) startBookTicker() {
+	if !pm.exchange.Capabilities().BookTicker {
+		logger.Warn("⚠️ [价格监控] %s 不支持盘口最优价推送，价格来源 %s 回退为 last", pm.exchange.GetName(), pm.priceSource)
+		pm.priceSource = PriceSourceLast
+		return
+	}
+	err := pm.exchange.StartBookTickerStream(pm.ctx, pm.symbol, func(ticker *exchange.BookTicker) {
+		pm.updateQuote(ticker)
+	})
+	if err != nil {
+		logger.Warn("⚠️ [价格监控] 盘口最优价流启动失败: %v，价格来源 %s 回退为 last", err, pm.priceSource)
+		pm.priceSource = PriceSourceLast
+		return
+	}
+	logger.Info("✅ [价格监控] 盘口最优价流已启动，价格来源: %s", pm.priceSource)
+}
+
 // fallbackPolling REST API 轮询模式（备用方案）
 func (pm *PriceMonitor) fallbackPolling() {
 	ticker := time.NewTicker(1 * time.Second) // 1秒轮询一次
@@ -163,20 +253,57 @@ func (pm *PriceMonitor) updatePrice(newPrice float64) {
 		return
 	}
 
-	oldPrice := pm.GetLastPrice()
-
 	// 存储新价格
 	pm.lastPrice.Store(newPrice)
 	pm.lastPriceStr.Store(fmt.Sprintf("%f", newPrice)) // 简单转换，精度由后续逻辑处理
 	pm.lastPriceTime.Store(time.Now())
 
+	pm.publish()
+}
+
+// updateQuote 更新盘口最优价，非 last 模式下生成事件
+func (pm *PriceMonitor) updateQuote(ticker *exchange.BookTicker) {
+	if ticker == nil || ticker.BidPrice <= 0 || ticker.AskPrice <= 0 || ticker.BidPrice > ticker.AskPrice {
+		return
+	}
+	pm.bookQuote.Store(bookQuote{bid: ticker.BidPrice, ask: ticker.AskPrice, receivedAt: time.Now()})
+	if pm.priceSource != PriceSourceLast {
+		pm.publish()
+	}
+}
+
+// publish 按价格来源计算驱动价格，与上一次事件相比有变化时生成事件
+// last 模式只在成交价变化时产生事件；mid/bid_ask 模式下买一/卖一变化也会产生事件
+func (pm *PriceMonitor) publish() {
+	last := pm.GetLastPrice()
+	if last <= 0 {
+		return
+	}
+	bid, ask := pm.GetBestBidAsk()
+	newPrice := last
+	if pm.priceSource != PriceSourceLast && bid > 0 && ask > 0 {
+		newPrice = (bid + ask) / 2
+	}
+
+	pm.publishMu.Lock()
+	defer pm.publishMu.Unlock()
+
+	oldPrice := pm.drivenPrice
+	changed := newPrice != oldPrice
+	if pm.priceSource != PriceSourceLast {
+		changed = changed || bid != pm.drivenBid || ask != pm.drivenAsk
+	}
+	pm.drivenPrice, pm.drivenBid, pm.drivenAsk = newPrice, bid, ask
+
 	// 如果价格有变化，生成事件
-	if oldPrice > 0 && newPrice != oldPrice {
-		change := newPrice - oldPrice
+	if oldPrice > 0 && changed {
 		event := &PriceChange{
 			OldPrice:  oldPrice,
 			NewPrice:  newPrice,
-			Change:    change,
+			Change:    newPrice - oldPrice,
+			Bid:       bid,
+			Ask:       ask,
+			Last:      last,
 			Timestamp: time.Now(),
 		}
 		pm.latestPriceChange.Store(event)
@@ -241,6 +368,24 @@ func (pm *PriceMonitor) GetLastPriceString() string {
 	return ""
 }
 
+// GetOrderBook 获取最新订单簿，深度流未启动或超过 orderBookStaleAfter 未更新时返回 nil
+func (pm *PriceMonitor) GetOrderBook() *exchange.OrderBook {
+	book, _ := pm.orderBook.Load().(*exchange.OrderBook)
+	if book == nil || time.Since(book.Time) > orderBookStaleAfter {
+		return nil
+	}
+	return book
+}
+
+// GetBestBidAsk 获取买一价与卖一价，优先使用盘口最优价推送，其次为深度流订单簿，均不可用时返回 0
+func (pm *PriceMonitor) GetBestBidAsk() (bid, ask float64) {
+	if q, _ := pm.bookQuote.Load().(bookQuote); q.bid > 0 && time.Since(q.receivedAt) <= orderBookStaleAfter {
+		return q.bid, q.ask
+	}
+	book := pm.GetOrderBook()
+	return book.BestBid(), book.BestAsk()
+}
+
 // Subscribe 订阅价格变化
 func (pm *PriceMonitor) Subscribe() <-chan PriceChange {
 	outCh := make(chan PriceChange, 10)
@@ -2,13 +2,13 @@ package order
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"opensqt/exchange"
 	"opensqt/logger"
+	"opensqt/utils"
 	"strings"
 	"time"
-
-	"golang.org/x/time/rate"
 )
 
 // OrderRequest 订单请求
@@ -19,6 +19,7 @@ type OrderRequest struct {
 	Quantity      float64
 	PriceDecimals int    // 价格小数位数（用于格式化价格字符串）
 	ReduceOnly    bool   // 是否只减仓（平仓单）
+	PositionSide  string // 持仓方向 LONG/SHORT（双向持仓模式），空表示单向持仓
 	PostOnly      bool   // 是否只做 Maker（Post Only）
 	ClientOrderID string // 自定义订单ID
 }
@@ -37,69 +38,45 @@ type Order struct {
 
 // ExchangeOrderExecutor 基于 exchange.IExchange 的订单执行器
 type ExchangeOrderExecutor struct {
-	exchange    exchange.IExchange
-	symbol      string
-	rateLimiter *rate.Limiter
+	exchange     exchange.IExchange
+	capabilities exchange.Capabilities
+	symbol       string
 
 	// 时间配置
 	rateLimitRetryDelay time.Duration
 	orderRetryDelay     time.Duration
+	postOnlyRetryDelay  time.Duration
 }
 
 // NewExchangeOrderExecutor 创建基于交易所接口的订单执行器
 func NewExchangeOrderExecutor(ex exchange.IExchange, symbol string, rateLimitRetryDelay, orderRetryDelay int) *ExchangeOrderExecutor {
 	return &ExchangeOrderExecutor{
 		exchange:            ex,
+		capabilities:        ex.Capabilities(),
 		symbol:              symbol,
-		rateLimiter:         rate.NewLimiter(rate.Limit(25), 30), // 25单/秒，突发30
 		rateLimitRetryDelay: time.Duration(rateLimitRetryDelay) * time.Second,
 		orderRetryDelay:     time.Duration(orderRetryDelay) * time.Millisecond,
+		postOnlyRetryDelay:  500 * time.Millisecond,
 	}
 }
 
-// isPostOnlyError 检查是否为PostOnly错误
-func isPostOnlyError(err error) bool {
-	if err == nil {
-		return false
-	}
-	errStr := err.Error()
-	// Binance: code=-5022, Bitget: Post Only order will be rejected, Gate.io: ORDER_POC_IMMEDIATE
-	return strings.Contains(errStr, "-5022") ||
-		strings.Contains(errStr, "Post Only") ||
-		strings.Contains(errStr, "post_only") ||
-		strings.Contains(errStr, "would immediately match") ||
-		strings.Contains(errStr, "ORDER_POC_IMMEDIATE")
-}
-
 // PlaceOrder 下单（带重试）
+// 限流由交易所实例的账户级请求额度管理负责（与对账、监控等请求共用额度，下单优先）
 func (oe *ExchangeOrderExecutor) PlaceOrder(req *OrderRequest) (*Order, error) {
-	// 限流
-	if err := oe.rateLimiter.Wait(context.Background()); err != nil {
-		return nil, fmt.Errorf("速率限制等待失败: %v", err)
-	}
-
 	maxRetries := 5 // 增加重试次数:3次PostOnly + 1次降级 + 1次保险
+	// 交易所不支持 PostOnly 时直接下普通单，避免无意义的重试
+	postOnly := req.PostOnly && oe.capabilities.PostOnlyStyle != exchange.PostOnlyNone
 	var lastErr error
 	postOnlyFailCount := 0
 	degraded := false // 是否已降级为普通单
+	resynced := false // 是否已因时间戳不同步重新对时
 
 	for i := 0; i <= maxRetries; i++ {
-		// 转换为通用订单请求
-		exchangeReq := &exchange.OrderRequest{
-			Symbol:        req.Symbol,
-			Side:          exchange.Side(req.Side),
-			Type:          exchange.OrderTypeLimit,
-			TimeInForce:   exchange.TimeInForceGTC,
-			Quantity:      req.Quantity,
-			Price:         req.Price,
-			PriceDecimals: req.PriceDecimals,
-			ReduceOnly:    req.ReduceOnly,
-			PostOnly:      req.PostOnly && !degraded, // 如果已降级，强制为普通单
-			ClientOrderID: req.ClientOrderID,         // 传递自定义订单ID
-		}
+		// 转换为通用订单请求（如果已降级，强制为普通单）
+		exchangeReq := oe.toExchangeRequest(req, postOnly && !degraded)
 
 		// 🔥 如果PostOnly已失败3次，降级为普通限价单
-		if postOnlyFailCount >= 3 && req.PostOnly && !degraded {
+		if postOnlyFailCount >= 3 && postOnly && !degraded {
 			degraded = true
 			logger.Warn("⚠️ [%s] PostOnly已失败3次，降级为普通限价单: %s %.2f",
 				oe.exchange.GetName(), req.Side, req.Price)
@@ -133,39 +110,42 @@ func (oe *ExchangeOrderExecutor) PlaceOrder(req *OrderRequest) (*Order, error) {
 
 		lastErr = err
 
-		// 判断错误类型
-		errStr := err.Error()
-		if strings.Contains(errStr, "-4061") {
+		// 判断错误类型（各交易所适配器已将原生错误码映射为 exchange.ErrXxx）
+		if errors.Is(err, exchange.ErrPositionModeMismatch) {
 			// 持仓模式不匹配：双向持仓 vs 单向持仓
-			logger.Fatalf("❌ 下单失败，请在交易所将双向持仓改为单向持仓。错误码: -4061")
-			return nil, fmt.Errorf("持仓模式不匹配: %w", err)
-		} else if strings.Contains(errStr, "-1003") || strings.Contains(errStr, "rate limit") {
+			logger.Error("❌ 下单失败，持仓模式不匹配，请配置 trading.position_mode: one_way 由程序启动时自动设置: %v", err)
+			return nil, err
+		} else if errors.Is(err, exchange.ErrRateLimited) {
 			// 速率限制，等待后重试
 			logger.Warn("⚠️ 触发速率限制，等待后重试...")
 			time.Sleep(oe.rateLimitRetryDelay)
 			continue
-		} else if isPostOnlyError(err) && !degraded {
-			// 🔥 PostOnly错误：价格会立即成交，记录失败次数(必须放在其他检查之前!)
+		} else if errors.Is(err, exchange.ErrPostOnlyWouldCross) && !degraded {
+			// 🔥 PostOnly错误：价格会立即成交，记录失败次数
 			postOnlyFailCount++
-			logger.Warn("⚠️ [%s] PostOnly被拒(%d/3): %s %.2f, 等待500ms后重试",
-				oe.exchange.GetName(), postOnlyFailCount, req.Side, req.Price)
-
-			// 如果还没达到3次，继续重试PostOnly
-			if postOnlyFailCount < 3 {
-				time.Sleep(500 * time.Millisecond)
-				continue
-			}
+			logger.Warn("⚠️ [%s] PostOnly被拒(%d/3): %s %.2f, 等待%v后重试",
+				oe.exchange.GetName(), postOnlyFailCount, req.Side, req.Price, oe.postOnlyRetryDelay)
 			// 达到3次后，下一轮循环会触发降级
-			time.Sleep(500 * time.Millisecond)
+			time.Sleep(oe.postOnlyRetryDelay)
 			continue
-		} else if strings.Contains(errStr, "-4061") {
-			// 持仓模式不匹配（已在前面处理，这里保留以防万一）
-			return nil, err
-		} else if strings.Contains(errStr, "-2019") || strings.Contains(errStr, "保证金不足") || strings.Contains(errStr, "insufficient") {
-			// 保证金不足，不重试
-			return nil, err
-		} else if strings.Contains(errStr, "-1021") {
-			// 时间戳不同步，不重试
+		} else if errors.Is(err, exchange.ErrClockSkew) {
+			// 时间戳不同步：立即与服务器对时后重试一次，仍被拒说明时钟偏差无法校正
+			if resynced {
+				logger.Error("❌ [%s] 对时后仍提示时间戳不同步（当前偏差 %v），请检查系统时钟: %v",
+					oe.exchange.GetName(), oe.exchange.GetClockSkew(), err)
+				return nil, err
+			}
+			resynced = true
+			if syncErr := oe.exchange.SyncServerTime(context.Background()); syncErr != nil {
+				logger.Error("❌ [%s] 时间戳不同步且对时失败: %v", oe.exchange.GetName(), syncErr)
+				return nil, err
+			}
+			logger.Warn("⚠️ [%s] 时间戳不同步，已重新对时（偏差 %v），重试下单",
+				oe.exchange.GetName(), oe.exchange.GetClockSkew())
+			continue
+		} else if errors.Is(err, exchange.ErrInsufficientMargin) ||
+			errors.Is(err, exchange.ErrMinNotional) {
+			// 保证金不足/名义价值过小，重试无意义
 			return nil, err
 		}
 
@@ -178,23 +158,39 @@ func (oe *ExchangeOrderExecutor) PlaceOrder(req *OrderRequest) (*Order, error) {
 	return nil, fmt.Errorf("下单失败（重试%d次）: %w", maxRetries, lastErr)
 }
 
+// toExchangeRequest 转换为通用订单请求
+func (oe *ExchangeOrderExecutor) toExchangeRequest(req *OrderRequest, postOnly bool) *exchange.OrderRequest {
+	return &exchange.OrderRequest{
+		Symbol:        req.Symbol,
+		Side:          exchange.Side(req.Side),
+		Type:          exchange.OrderTypeLimit,
+		TimeInForce:   exchange.TimeInForceGTC,
+		Quantity:      req.Quantity,
+		Price:         req.Price,
+		PriceDecimals: req.PriceDecimals,
+		ReduceOnly:    req.ReduceOnly,
+		PositionSide:  exchange.PositionSide(req.PositionSide),
+		PostOnly:      postOnly,
+		ClientOrderID: req.ClientOrderID, // 传递自定义订单ID
+	}
+}
+
 // BatchPlaceOrders 批量下单
+// 交易所支持原生批量下单时走批量接口，否则逐单下单
 // 返回：成功下单的订单列表，以及是否出现保证金不足错误
 func (oe *ExchangeOrderExecutor) BatchPlaceOrders(orders []*OrderRequest) ([]*Order, bool) {
+	if oe.capabilities.NativeBatchSize > 1 && len(orders) > 1 {
+		return oe.batchPlaceOrdersNative(orders, oe.capabilities.NativeBatchSize)
+	}
+
 	placedOrders := make([]*Order, 0, len(orders))
 	hasMarginError := false
 
 	for _, orderReq := range orders {
-		order, err := oe.PlaceOrder(orderReq)
+		order, err := oe.placeOrderInBatch(orderReq)
 		if err != nil {
-			logger.Warn("⚠️ [%s] 下单失败 %.2f %s: %v",
-				oe.exchange.GetName(), orderReq.Price, orderReq.Side, err)
-
-			// 检查是否是保证金不足错误
-			errStr := err.Error()
-			if strings.Contains(errStr, "保证金不足") || strings.Contains(errStr, "-2019") || strings.Contains(errStr, "insufficient") {
+			if errors.Is(err, exchange.ErrInsufficientMargin) {
 				hasMarginError = true
-				logger.Error("❌ [保证金不足] 订单 %.2f %s 因保证金不足失败", orderReq.Price, orderReq.Side)
 			}
 			continue
 		}
@@ -204,18 +200,88 @@ func (oe *ExchangeOrderExecutor) BatchPlaceOrders(orders []*OrderRequest) ([]*Or
 	return placedOrders, hasMarginError
 }
 
-// CancelOrder 取消订单
-func (oe *ExchangeOrderExecutor) CancelOrder(orderID int64) error {
-	// 限流
-	if err := oe.rateLimiter.Wait(context.Background()); err != nil {
-		return fmt.Errorf("速率限制等待失败: %v", err)
+// batchPlaceOrdersNative 使用交易所原生批量接口下单
+// 批量中被拒的订单（按 ClientOrderID 识别）回退到 PlaceOrder 逐单重试，沿用 PostOnly 降级等逻辑；
+// 出现保证金不足时不再回退，没有 ClientOrderID 的订单无法识别，也不回退
+func (oe *ExchangeOrderExecutor) batchPlaceOrdersNative(orders []*OrderRequest, batchSize int) ([]*Order, bool) {
+	placedOrders := make([]*Order, 0, len(orders))
+	hasMarginError := false
+	exchangeName := strings.ToLower(oe.exchange.GetName())
+
+	for i := 0; i < len(orders); i += batchSize {
+		end := i + batchSize
+		if end > len(orders) {
+			end = len(orders)
+		}
+		batch := orders[i:end]
+
+		exchangeReqs := make([]*exchange.OrderRequest, len(batch))
+		for j, req := range batch {
+			exchangeReqs[j] = oe.toExchangeRequest(req, req.PostOnly && oe.capabilities.PostOnlyStyle != exchange.PostOnlyNone)
+		}
+
+		placed, marginErr := oe.exchange.BatchPlaceOrders(context.Background(), exchangeReqs)
+		placedIDs := make(map[string]bool, len(placed))
+		for _, o := range placed {
+			placedIDs[utils.RemoveBrokerPrefix(exchangeName, o.ClientOrderID)] = true
+			placedOrders = append(placedOrders, &Order{
+				OrderID:       o.OrderID,
+				ClientOrderID: o.ClientOrderID,
+				Symbol:        o.Symbol,
+				Side:          string(o.Side),
+				Price:         o.Price,
+				Quantity:      o.Quantity,
+				Status:        string(o.Status),
+				CreatedAt:     time.Now(),
+			})
+		}
+		logger.Info("✅ [%s] 批量下单: 成功 %d/%d", oe.exchange.GetName(), len(placed), len(batch))
+
+		if marginErr {
+			hasMarginError = true
+			logger.Error("❌ [保证金不足] 批量下单出现保证金不足，本批失败订单不再重试")
+			continue
+		}
+
+		for _, req := range batch {
+			if req.ClientOrderID == "" || placedIDs[req.ClientOrderID] {
+				continue
+			}
+			order, err := oe.placeOrderInBatch(req)
+			if err != nil {
+				if errors.Is(err, exchange.ErrInsufficientMargin) {
+					hasMarginError = true
+				}
+				continue
+			}
+			placedOrders = append(placedOrders, order)
+		}
+	}
+
+	return placedOrders, hasMarginError
+}
+
+// placeOrderInBatch 批量下单中的单笔下单，失败时打印日志
+func (oe *ExchangeOrderExecutor) placeOrderInBatch(orderReq *OrderRequest) (*Order, error) {
+	order, err := oe.PlaceOrder(orderReq)
+	if err != nil {
+		logger.Warn("⚠️ [%s] 下单失败 %.2f %s: %v",
+			oe.exchange.GetName(), orderReq.Price, orderReq.Side, err)
+
+		// 检查是否是保证金不足错误
+		if errors.Is(err, exchange.ErrInsufficientMargin) {
+			logger.Error("❌ [保证金不足] 订单 %.2f %s 因保证金不足失败", orderReq.Price, orderReq.Side)
+		}
 	}
+	return order, err
+}
 
+// CancelOrder 取消订单
+func (oe *ExchangeOrderExecutor) CancelOrder(orderID int64) error {
 	err := oe.exchange.CancelOrder(context.Background(), oe.symbol, orderID)
 	if err != nil {
-		// 如果是"Unknown order"错误，说明订单已经不存在（可能已成交或已取消），不算错误
-		errStr := err.Error()
-		if strings.Contains(errStr, "-2011") || strings.Contains(errStr, "Unknown order") || strings.Contains(errStr, "does not exist") {
+		// 订单已经不存在（可能已成交或已取消），不算错误
+		if errors.Is(err, exchange.ErrOrderNotFound) {
 			logger.Info("ℹ️ [%s] 订单 %d 已不存在（可能已成交或已取消），跳过取消", oe.exchange.GetName(), orderID)
 			return nil
 		}
@@ -226,6 +292,36 @@ func (oe *ExchangeOrderExecutor) CancelOrder(orderID int64) error {
 	return nil
 }
 
+// AmendOrder 改单（修改挂单的价格/数量），用于间距变化时原地调整挂单价格
+// req 为原订单的下单请求，newQty <= 0 表示保持原数量。支持原生改单的交易所保留订单ID与排队位置，
+// 其他交易所以撤单重下实现（沿用 req 的 ReduceOnly/PositionSide/PostOnly/ClientOrderID），
+// 返回的订单ID会变化，调用方需以返回值更新映射；
+// 返回错误满足 errors.Is(err, exchange.ErrAmendReplaceFailed) 时原订单已撤销，调用方需释放或重建槽位
+func (oe *ExchangeOrderExecutor) AmendOrder(req *OrderRequest, orderID int64, newPrice, newQty float64) (*Order, error) {
+	postOnly := req.PostOnly && oe.capabilities.PostOnlyStyle != exchange.PostOnlyNone
+	amended, err := oe.exchange.AmendOrder(context.Background(), oe.toExchangeRequest(req, postOnly), orderID, newPrice, newQty)
+	if err != nil {
+		return nil, fmt.Errorf("改单失败: %w", err)
+	}
+
+	if amended.OrderID != orderID {
+		logger.Info("✅ [%s] 改单成功: 订单 %d -> %d, 新价格 %.8f", oe.exchange.GetName(), orderID, amended.OrderID, amended.Price)
+	} else {
+		logger.Info("✅ [%s] 改单成功: 订单 %d, 新价格 %.8f", oe.exchange.GetName(), orderID, amended.Price)
+	}
+
+	return &Order{
+		OrderID:       amended.OrderID,
+		ClientOrderID: amended.ClientOrderID,
+		Symbol:        amended.Symbol,
+		Side:          string(amended.Side),
+		Price:         amended.Price,
+		Quantity:      amended.Quantity,
+		Status:        string(amended.Status),
+		CreatedAt:     time.Now(),
+	}, nil
+}
+
 // BatchCancelOrders 批量撤单
 func (oe *ExchangeOrderExecutor) BatchCancelOrders(orderIDs []int64) error {
 	if len(orderIDs) == 0 {
@@ -0,0 +1,208 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"opensqt/exchange"
+	"opensqt/exchange/errs"
+)
+
+// fakeExchange 按顺序返回预设错误的交易所桩，未实现的方法由内嵌接口兜底（调用即 panic）
+type fakeExchange struct {
+	exchange.IExchange
+	caps      exchange.Capabilities
+	placeErrs []error
+	requests  []*exchange.OrderRequest
+	cancelErr error
+	syncCalls int
+
+	batchCalls    [][]*exchange.OrderRequest
+	batchRejected map[string]bool // 批量下单中被拒的 ClientOrderID
+}
+
+func (f *fakeExchange) GetName() string { return "Fake" }
+
+func (f *fakeExchange) Capabilities() exchange.Capabilities {
+	caps := f.caps
+	if caps.PostOnlyStyle == exchange.PostOnlyNone {
+		caps.PostOnlyStyle = exchange.PostOnlyTimeInForce
+	}
+	return caps
+}
+
+func (f *fakeExchange) BatchPlaceOrders(ctx context.Context, reqs []*exchange.OrderRequest) ([]*exchange.Order, bool) {
+	f.batchCalls = append(f.batchCalls, reqs)
+	var placed []*exchange.Order
+	for _, req := range reqs {
+		if f.batchRejected[req.ClientOrderID] {
+			continue
+		}
+		placed = append(placed, &exchange.Order{ClientOrderID: req.ClientOrderID, Side: req.Side, Price: req.Price, Status: exchange.OrderStatusNew})
+	}
+	return placed, false
+}
+
+func (f *fakeExchange) PlaceOrder(ctx context.Context, req *exchange.OrderRequest) (*exchange.Order, error) {
+	f.requests = append(f.requests, req)
+	if n := len(f.requests); n <= len(f.placeErrs) && f.placeErrs[n-1] != nil {
+		return nil, f.placeErrs[n-1]
+	}
+	return &exchange.Order{OrderID: int64(len(f.requests)), Status: exchange.OrderStatusNew}, nil
+}
+
+func (f *fakeExchange) SyncServerTime(ctx context.Context) error {
+	f.syncCalls++
+	return nil
+}
+
+func (f *fakeExchange) GetClockSkew() time.Duration { return 0 }
+
+func (f *fakeExchange) CancelOrder(ctx context.Context, symbol string, orderID int64) error {
+	return f.cancelErr
+}
+
+func newTestExecutor(ex exchange.IExchange) *ExchangeOrderExecutor {
+	oe := NewExchangeOrderExecutor(ex, "BTCUSDT", 0, 0)
+	oe.postOnlyRetryDelay = 0
+	return oe
+}
+
+func TestPlaceOrderPostOnlyDegrade(t *testing.T) {
+	postOnlyErr := errs.Wrap(errs.ErrPostOnlyWouldCross, "fake", "-5022", errors.New("post only rejected"))
+	ex := &fakeExchange{placeErrs: []error{postOnlyErr, postOnlyErr, postOnlyErr}}
+	oe := newTestExecutor(ex)
+
+	order, err := oe.PlaceOrder(&OrderRequest{Symbol: "BTCUSDT", Side: "BUY", Price: 100, Quantity: 1, PostOnly: true})
+	if err != nil {
+		t.Fatalf("降级后应下单成功: %v", err)
+	}
+	if order.OrderID != 4 || len(ex.requests) != 4 {
+		t.Fatalf("应在3次PostOnly失败后第4次成功, 实际请求 %d 次", len(ex.requests))
+	}
+	for i, req := range ex.requests {
+		if want := i < 3; req.PostOnly != want {
+			t.Errorf("第%d次请求 PostOnly=%v, 期望 %v", i+1, req.PostOnly, want)
+		}
+	}
+}
+
+func TestPlaceOrderNoRetryErrors(t *testing.T) {
+	for _, kind := range []error{exchange.ErrInsufficientMargin, exchange.ErrMinNotional} {
+		ex := &fakeExchange{placeErrs: []error{errs.Wrap(kind, "fake", "", errors.New("rejected"))}}
+		oe := newTestExecutor(ex)
+
+		_, err := oe.PlaceOrder(&OrderRequest{Symbol: "BTCUSDT", Side: "BUY", Price: 100, Quantity: 1})
+		if !errors.Is(err, kind) {
+			t.Errorf("错误分类丢失: got %v, want %v", err, kind)
+		}
+		if len(ex.requests) != 1 {
+			t.Errorf("%v 不应重试, 实际请求 %d 次", kind, len(ex.requests))
+		}
+	}
+}
+
+func TestPlaceOrderClockSkewResync(t *testing.T) {
+	skewErr := errs.Wrap(exchange.ErrClockSkew, "fake", "-1021", errors.New("timestamp outside of recvWindow"))
+
+	// 对时后重试成功
+	ex := &fakeExchange{placeErrs: []error{skewErr}}
+	if _, err := newTestExecutor(ex).PlaceOrder(&OrderRequest{Symbol: "BTCUSDT", Side: "BUY", Price: 100, Quantity: 1}); err != nil {
+		t.Fatalf("对时后应下单成功: %v", err)
+	}
+	if ex.syncCalls != 1 || len(ex.requests) != 2 {
+		t.Errorf("应对时1次并重试1次, got sync=%d requests=%d", ex.syncCalls, len(ex.requests))
+	}
+
+	// 对时后仍不同步则放弃
+	ex = &fakeExchange{placeErrs: []error{skewErr, skewErr, skewErr}}
+	_, err := newTestExecutor(ex).PlaceOrder(&OrderRequest{Symbol: "BTCUSDT", Side: "BUY", Price: 100, Quantity: 1})
+	if !errors.Is(err, exchange.ErrClockSkew) {
+		t.Errorf("错误分类丢失: got %v", err)
+	}
+	if ex.syncCalls != 1 || len(ex.requests) != 2 {
+		t.Errorf("只应对时重试一次, got sync=%d requests=%d", ex.syncCalls, len(ex.requests))
+	}
+}
+
+func TestPlaceOrderRetryRateLimited(t *testing.T) {
+	ex := &fakeExchange{placeErrs: []error{
+		errs.Wrap(exchange.ErrRateLimited, "fake", "429", errors.New("too many requests")),
+		fmt.Errorf("网络错误"),
+	}}
+	oe := newTestExecutor(ex)
+
+	if _, err := oe.PlaceOrder(&OrderRequest{Symbol: "BTCUSDT", Side: "SELL", Price: 100, Quantity: 1}); err != nil {
+		t.Fatalf("重试后应下单成功: %v", err)
+	}
+	if len(ex.requests) != 3 {
+		t.Errorf("应重试至第3次成功, 实际请求 %d 次", len(ex.requests))
+	}
+}
+
+func TestBatchPlaceOrdersMarginFlag(t *testing.T) {
+	marginErr := fmt.Errorf("下单失败: %w", errs.Wrap(exchange.ErrInsufficientMargin, "fake", "-2019", errors.New("margin is insufficient")))
+	ex := &fakeExchange{placeErrs: []error{nil, marginErr}}
+	oe := newTestExecutor(ex)
+
+	placed, marginError := oe.BatchPlaceOrders([]*OrderRequest{
+		{Symbol: "BTCUSDT", Side: "BUY", Price: 100, Quantity: 1},
+		{Symbol: "BTCUSDT", Side: "BUY", Price: 99, Quantity: 1},
+	})
+	if len(placed) != 1 || !marginError {
+		t.Errorf("应成功1单并标记保证金不足, got placed=%d marginError=%v", len(placed), marginError)
+	}
+}
+
+func TestCancelOrderNotFound(t *testing.T) {
+	ex := &fakeExchange{cancelErr: errs.Wrap(exchange.ErrOrderNotFound, "fake", "-2011", errors.New("unknown order"))}
+	if err := newTestExecutor(ex).CancelOrder(1); err != nil {
+		t.Errorf("订单不存在不应返回错误: %v", err)
+	}
+
+	ex.cancelErr = errors.New("网络错误")
+	if err := newTestExecutor(ex).CancelOrder(1); err == nil {
+		t.Error("其他错误应返回")
+	}
+}
+
+func TestBatchPlaceOrdersNative(t *testing.T) {
+	ex := &fakeExchange{
+		caps:          exchange.Capabilities{NativeBatchSize: 2},
+		batchRejected: map[string]bool{"100_B_2": true},
+	}
+	oe := newTestExecutor(ex)
+
+	var reqs []*OrderRequest
+	for i := 1; i <= 3; i++ {
+		reqs = append(reqs, &OrderRequest{Symbol: "BTCUSDT", Side: "BUY", Price: float64(100 - i), Quantity: 1,
+			PostOnly: true, ClientOrderID: fmt.Sprintf("100_B_%d", i)})
+	}
+	placed, marginError := oe.BatchPlaceOrders(reqs)
+	if marginError || len(placed) != 3 {
+		t.Fatalf("应全部下单成功, got placed=%d marginError=%v", len(placed), marginError)
+	}
+	if len(ex.batchCalls) != 2 || len(ex.batchCalls[0]) != 2 || len(ex.batchCalls[1]) != 1 {
+		t.Errorf("应按原生批量大小拆分为 2+1, got %d 批", len(ex.batchCalls))
+	}
+	// 批量中被拒的订单回退为单笔下单
+	if len(ex.requests) != 1 || ex.requests[0].ClientOrderID != "100_B_2" || !ex.requests[0].PostOnly {
+		t.Errorf("被拒订单应回退到单笔 PostOnly 下单, got %+v", ex.requests)
+	}
+}
+
+func TestPlaceOrderPostOnlyUnsupported(t *testing.T) {
+	ex := &fakeExchange{}
+	oe := newTestExecutor(ex)
+	oe.capabilities.PostOnlyStyle = exchange.PostOnlyNone
+
+	if _, err := oe.PlaceOrder(&OrderRequest{Symbol: "BTCUSDT", Side: "BUY", Price: 100, Quantity: 1, PostOnly: true}); err != nil {
+		t.Fatalf("下单失败: %v", err)
+	}
+	if ex.requests[0].PostOnly {
+		t.Error("交易所不支持 PostOnly 时应直接下普通单")
+	}
+}
@@ -28,6 +28,26 @@ type OrderUpdate struct {
 	Side          string
 	Type          string
 	UpdateTime    int64
+	Fill          *FillEvent // 本次成交明细，无新成交时为 nil；Status 为空表示仅推送成交明细
+}
+
+// FillEvent 单笔成交明细（避免循环导入，对应 exchange.FillEvent）
+type FillEvent struct {
+	TradeID         string
+	Price           float64 // 成交价格
+	Quantity        float64 // 成交数量
+	Commission      float64 // 手续费（正数为支出，负数为返佣）
+	CommissionAsset string
+	IsMaker         bool
+	Time            int64
+}
+
+// FundingPayment 资金费收付记录（避免循环导入，对应 exchange.FundingPayment）
+type FundingPayment struct {
+	ID     string
+	Amount float64 // 金额（正数为收入，负数为支出）
+	Asset  string
+	Time   int64
 }
 
 // OrderExecutorInterface 订单执行器接口（避免循环导入）
@@ -45,10 +65,20 @@ type OrderRequest struct {
 	Quantity      float64
 	PriceDecimals int    // 价格小数位数（用于格式化价格字符串）
 	ReduceOnly    bool   // 是否只减仓（平仓单）
+	PositionSide  string // 持仓方向 LONG/SHORT（双向持仓模式），空表示单向持仓
 	PostOnly      bool   // 是否只做 Maker（Post Only）
 	ClientOrderID string // 自定义订单ID
 }
 
+// SymbolRules 交易对下单规则（避免循环导入，对应 exchange.SymbolInfo 的子集，0 表示未知）
+type SymbolRules struct {
+	TickSize      float64 // 价格步长
+	StepSize      float64 // 数量步长
+	MinQty        float64 // 最小下单数量
+	MinNotional   float64 // 最小名义价值
+	ContractValue float64 // 币本位合约面值（USD/张），大于 0 时数量口径为张数，盈亏以基础币种计
+}
+
 // Order 订单信息（避免循环导入）
 type Order struct {
 	OrderID       int64
@@ -112,6 +142,12 @@ type InventorySlot struct {
 	// PostOnly失败计数（连续失败3次后降级为普通单）
 	PostOnlyFailCount int
 
+	// 成本与手续费（按实际成交记录）
+	CostBasis float64 // 当前持仓成本（成交额之和，空仓为开空成交额；币本位合约以基础币种计；恢复的持仓成本未知时为0）
+	Fees      float64 // 累计手续费（正数为支出）
+	FeeAsset  string  // 手续费币种
+	Funding   float64 // 持仓期间分摊的资金费（正数为收入，负数为支出），卖出时按比例结转
+
 	mu sync.RWMutex // 槽位级别的锁（细粒度锁）
 }
 
@@ -130,8 +166,10 @@ type IExchange interface {
 	GetOpenOrders(ctx context.Context, symbol string) (interface{}, error)
 	GetOrder(ctx context.Context, symbol string, orderID int64) (interface{}, error)
 	GetBaseAsset() string                                     // 获取基础资产（交易币种）
+	GetQuoteAsset() string                                    // 获取计价资产（手续费换算使用）
 	CancelAllOrders(ctx context.Context, symbol string) error // 取消所有订单
 	GetAvailableBalance(ctx context.Context) (float64, error) // 获取可用保证金
+	GetFreeBaseBalance(ctx context.Context) (float64, error)  // 获取基础资产可用余额（现货模式下卖单的上限）
 }
 
 // SuperPositionManager 超级仓位管理器
@@ -148,6 +186,10 @@ type SuperPositionManager struct {
 	priceDecimals int
 	// 数量精度（从交易所获取）
 	quantityDecimals int
+	// 自定义订单ID最大长度（来自交易所能力描述，0 表示不限制）
+	maxClientOrderIDLen int
+	// 交易对下单规则（来自交易所，未设置时仅按精度取整）
+	symbolRules SymbolRules
 
 	// 库存槽位：价格 -> 槽位
 	slots sync.Map // map[float64]*InventorySlot
@@ -168,12 +210,27 @@ type SuperPositionManager struct {
 	// 暴跌检测器
 	crashDetector *monitor.CrashDetector
 
+	// 价格监控（提供盘口买一/卖一价，交易所不支持深度推送时为空）
+	priceMonitor *monitor.PriceMonitor
+
 	// 统计（注意：以下字段被 safety.Reconciler 和 PrintPositions 使用，不可删除）
 	totalBuyQty       atomic.Value // float64 - 累计买入数量
 	totalSellQty      atomic.Value // float64 - 累计卖出数量
 	reconcileCount    atomic.Int64 // 对账次数
 	lastReconcileTime atomic.Value // time.Time - 最后对账时间
 
+	// 盈亏统计（不同槽位的成交并发结转，读改写需持有 pnlMu）
+	pnlMu        sync.Mutex
+	realizedPnL  float64            // 按实际成交价计算的已实现盈亏（含已结转的资金费，不含手续费）
+	totalFees    float64            // 累计手续费（已换算为盈亏币种）
+	otherFees    map[string]float64 // 无法换算的手续费（如 BNB 抵扣），按币种累计
+	totalFunding float64            // 累计资金费（正数为收入）
+
+	// 已处理的成交ID（去重，断线重连后交易所可能重复推送同一笔成交）
+	seenTrades     map[string]struct{}
+	seenTradeOrder []string
+	tradeMu        sync.Mutex
+
 	// 初始化标志
 	isInitialized atomic.Bool
 
@@ -223,6 +280,31 @@ func (spm *SuperPositionManager) SetCrashDetector(detector *monitor.CrashDetecto
 	spm.crashDetector = detector
 }
 
+// SetPriceMonitor 设置价格监控，挂单时用盘口买一/卖一价过滤会立即成交（PostOnly 会被拒绝）的价格
+func (spm *SuperPositionManager) SetPriceMonitor(pm *monitor.PriceMonitor) {
+	spm.priceMonitor = pm
+}
+
+// bestBidAsk 当前买一价与卖一价，盘口不可用时返回 0
+func (spm *SuperPositionManager) bestBidAsk() (bid, ask float64) {
+	if spm.priceMonitor == nil {
+		return 0, 0
+	}
+	return spm.priceMonitor.GetBestBidAsk()
+}
+
+// SetMaxClientOrderIDLen 设置交易所允许的自定义订单ID最大长度
+// 生成的ID超长时不再携带自定义ID，改为依赖订单ID映射，避免被交易所截断后无法解析
+func (spm *SuperPositionManager) SetMaxClientOrderIDLen(maxLen int) {
+	spm.maxClientOrderIDLen = maxLen
+}
+
+// SetSymbolRules 设置交易所返回的交易对下单规则
+// 设置后挂单价格按 TickSize 对齐、数量按 StepSize 向下取整，最小订单价值不低于交易所要求
+func (spm *SuperPositionManager) SetSymbolRules(rules SymbolRules) {
+	spm.symbolRules = rules
+}
+
 // GetDowntrendDetector 获取阴跌检测器
 func (spm *SuperPositionManager) GetDowntrendDetector() *monitor.DowntrendDetector {
 	return spm.downtrendDetector
@@ -305,7 +387,13 @@ func (spm *SuperPositionManager) Initialize(initialPrice float64, initialPriceSt
 // side: B=Buy, S=Sell
 func (spm *SuperPositionManager) generateClientOrderID(price float64, side string) string {
 	// 使用统一的 utils 包生成紧凑ID
-	return utils.GenerateOrderID(price, side, spm.priceDecimals)
+	clientOID := utils.GenerateOrderID(price, side, spm.priceDecimals)
+	if spm.maxClientOrderIDLen > 0 && len(clientOID) > spm.maxClientOrderIDLen {
+		logger.Debug("ℹ️ [%s] 自定义订单ID %s 超过长度限制 %d，不使用自定义ID",
+			spm.exchange.GetName(), clientOID, spm.maxClientOrderIDLen)
+		return ""
+	}
+	return clientOID
 }
 
 // parseClientOrderID 解析 ClientOrderID
@@ -343,8 +431,15 @@ func (spm *SuperPositionManager) placeInitialBuyOrders() error {
 	return nil
 }
 
-// AdjustOrders 调整订单（交易入口）
+// AdjustOrders 调整订单（交易入口），盘口买一/卖一取自 PriceMonitor
 func (spm *SuperPositionManager) AdjustOrders(currentPrice float64) error {
+	return spm.AdjustOrdersWithQuote(currentPrice, 0, 0)
+}
+
+// AdjustOrdersWithQuote 按价格事件携带的买一/卖一调整订单，bid/ask 无效时回退为 PriceMonitor 的盘口
+// 盘口可用时只挂单保护按真实价差计算（买单不高于买一、卖单不低于卖一），
+// price_source=bid_ask 时买单网格锚定买一、卖单窗口锚定卖一
+func (spm *SuperPositionManager) AdjustOrdersWithQuote(currentPrice, bid, ask float64) error {
 	// 🔥 移除初始化检查：现在完全由 AdjustOrders 控制所有下单
 	// 初始化只负责恢复持仓状态，不再下单
 
@@ -358,11 +453,24 @@ func (spm *SuperPositionManager) AdjustOrders(currentPrice float64) error {
 	}
 
 	// 对当前价格进行精度处理
-	currentPrice = roundPrice(currentPrice, spm.priceDecimals)
+	currentPrice = spm.alignPrice(currentPrice)
 
 	// 更新最后市场价格（用于打印状态）
 	spm.lastMarketPrice.Store(currentPrice)
 
+	// 盘口买一/卖一价（无盘口数据时为 0，只挂单保护回退为 PriceInterval * 0.1）
+	bestBid, bestAsk := bid, ask
+	if bestBid <= 0 || bestAsk < bestBid {
+		bestBid, bestAsk = spm.bestBidAsk()
+	}
+	hasQuote := bestBid > 0 && bestAsk >= bestBid
+
+	// 买单与卖单窗口的锚定价格
+	buyAnchor, sellAnchor := currentPrice, currentPrice
+	if hasQuote && spm.config.Trading.PriceSource == "bid_ask" {
+		buyAnchor, sellAnchor = spm.alignPrice(bestBid), spm.alignPrice(bestAsk)
+	}
+
 	// 检查保证金不足状态
 	if spm.insufficientMargin {
 		if time.Since(spm.marginLockTime) >= spm.marginLockDuration {
@@ -397,7 +505,7 @@ func (spm *SuperPositionManager) AdjustOrders(currentPrice float64) error {
 	priceInterval := spm.GetCurrentPriceInterval(currentPrice)
 
 	// 动态计算网格价格（使用动态间距）
-	currentGridPrice := spm.findNearestGridPriceWithInterval(currentPrice, priceInterval)
+	currentGridPrice := spm.findNearestGridPriceWithInterval(buyAnchor, priceInterval)
 	// logger.Debug("🔄 [实时调整] 当前价格: %s, 网格价格: %s, 买单窗口: %d, 卖单窗口: %d",
 	// 	formatPrice(currentPrice, spm.priceDecimals), formatPrice(currentGridPrice, spm.priceDecimals), buyWindowSize, sellWindowSize)
 
@@ -484,25 +592,30 @@ func (spm *SuperPositionManager) AdjustOrders(currentPrice float64) error {
 
 		if shouldCreateBuyOrder {
 			// 安全检查：买单价格不应高于当前价格
-			safetyBuffer := spm.config.Trading.PriceInterval * 0.1
-			if price >= currentPrice-safetyBuffer {
-				slot.mu.Unlock()
-				continue
+			if hasQuote {
+				// 高于买一的买单会吃单（PostOnly 会被拒绝）
+				if price > bestBid {
+					slot.mu.Unlock()
+					continue
+				}
+			} else {
+				safetyBuffer := spm.config.Trading.PriceInterval * 0.1
+				if price >= currentPrice-safetyBuffer {
+					slot.mu.Unlock()
+					continue
+				}
 			}
 
-			quantity := spm.config.Trading.OrderQuantity / price
+			quantity := spm.orderQuantity(price)
 			// 🔥 阴跌检测：应用买入数量乘数
 			quantity = quantity * buyMultiplier
-			// 使用从交易所获取的数量精度
-			quantity = roundPrice(quantity, spm.quantityDecimals)
-
-			// 🔥 最小名义价值检查（Binance 要求 >= 5 USDT）
-			orderValue := price * quantity
-			minValue := spm.config.Trading.MinOrderValue
-			if minValue <= 0 {
-				minValue = 6.0 // 默认6U，略高于Binance的5U要求
-			}
-			if orderValue < minValue {
+			// 按交易所数量步长/精度取整
+			quantity = spm.alignQuantity(quantity)
+
+			// 🔥 最小名义价值检查（以交易所要求为准）
+			orderValue := spm.orderNotional(price, quantity)
+			minValue := spm.minOrderValue()
+			if orderValue < minValue || quantity < spm.symbolRules.MinQty {
 				logger.Debug("⏭️ [跳过买单] 价格 %s 名义价值 %.2f < %.2f，不满足最小订单要求",
 					formatPrice(price, spm.priceDecimals), orderValue, minValue)
 				slot.SlotStatus = SlotStatusFree // 释放槽位锁
@@ -525,6 +638,7 @@ func (spm *SuperPositionManager) AdjustOrders(currentPrice float64) error {
 				Price:         price,
 				Quantity:      quantity,
 				PriceDecimals: spm.priceDecimals,
+				PositionSide:  spm.positionSide(false),
 				PostOnly:      usePostOnly,
 				ClientOrderID: clientOID,
 			})
@@ -535,8 +649,8 @@ func (spm *SuperPositionManager) AdjustOrders(currentPrice float64) error {
 	}
 
 	// 2. 处理卖单
-	sellWindowMaxPrice := currentPrice + float64(sellWindowSize)*priceInterval
-	sellWindowMaxPrice = roundPrice(sellWindowMaxPrice, spm.priceDecimals)
+	sellWindowMaxPrice := sellAnchor + float64(sellWindowSize)*priceInterval
+	sellWindowMaxPrice = spm.alignPrice(sellWindowMaxPrice)
 
 	type sellCandidate struct {
 		SlotPrice     float64 // 槽位价格 (买入价)
@@ -560,7 +674,7 @@ func (spm *SuperPositionManager) AdjustOrders(currentPrice float64) error {
 			slot.ClientOID == "" {
 
 			sellPrice := slotPrice + priceInterval
-			sellPrice = roundPrice(sellPrice, spm.priceDecimals)
+			sellPrice = spm.alignPrice(sellPrice)
 
 			// 窗口检查
 			if slotPrice > sellWindowMaxPrice {
@@ -568,11 +682,8 @@ func (spm *SuperPositionManager) AdjustOrders(currentPrice float64) error {
 			}
 
 			// 最小名义价值检查
-			orderValue := sellPrice * slot.PositionQty
-			minValue := spm.config.Trading.MinOrderValue
-			if minValue <= 0 {
-				minValue = 6.0
-			}
+			orderValue := spm.orderNotional(sellPrice, slot.PositionQty)
+			minValue := spm.minOrderValue()
 
 			if orderValue >= minValue {
 				distance := math.Abs(slotPrice - currentPrice)
@@ -603,6 +714,19 @@ func (spm *SuperPositionManager) AdjustOrders(currentPrice float64) error {
 		allowedNewSellOrders = remainingOrdersForSell
 	}
 
+	// 现货模式：卖出数量受基础资产可用余额限制（已挂卖单冻结的部分不计入可用余额）
+	// 余额来自账户缓存（推送维护或短时缓存，查询失败时沿用上次余额），不会每个价格变动都请求交易所；
+	// 只有从未获取成功时才本轮跳过卖单
+	freeBase := math.MaxFloat64
+	if spm.spotMode() && len(sellCandidates) > 0 {
+		balance, err := spm.exchange.GetFreeBaseBalance(context.Background())
+		if err != nil {
+			logger.Warn("⚠️ [现货卖单] 获取 %s 可用余额失败，本轮跳过卖单: %v", spm.exchange.GetBaseAsset(), err)
+			balance = 0
+		}
+		freeBase = balance
+	}
+
 	// 生成卖单请求
 	sellOrdersToCreate := 0
 	// 🔥 调试日志: 显示订单配额计算详情（包含买卖单分布）
@@ -630,23 +754,41 @@ func (spm *SuperPositionManager) AdjustOrders(currentPrice float64) error {
 				continue
 			}
 
+			// 卖单价格不高于买一价会立即成交（PostOnly 会被拒绝），上调至卖一价挂单
+			if hasQuote && candidate.SellPrice <= bestBid {
+				candidate.SellPrice = spm.alignPrice(bestAsk)
+			}
+
+			// 现货可用余额不足（如手动转出或被其他订单占用）时跳过该卖单
+			if candidate.Quantity > freeBase+0.0000001 {
+				slot.mu.Unlock()
+				logger.Debug("⏭️ [跳过卖单] 槽位 %s 卖出数量 %.6f 超过 %s 可用余额 %.6f",
+					formatPrice(candidate.SlotPrice, spm.priceDecimals), candidate.Quantity, spm.exchange.GetBaseAsset(), freeBase)
+				continue
+			}
+			freeBase -= candidate.Quantity
+
 			// 🔥 立即锁定槽位：标记为PENDING状态，防止并发操作
 			slot.SlotStatus = SlotStatusPending
 			// 检查PostOnly失败计数，失败3次后不再使用PostOnly
 			usePostOnly := slot.PostOnlyFailCount < 3
 			slot.mu.Unlock()
 
-			// 检查真实持仓以决定是否设置ReduceOnly
-			actualPosition := spm.getExistingPosition()
-			// 🔥 关键修复：只有当槽位有多单且交易所确实有多头持仓时才设置ReduceOnly
-			finalReduceOnly := false
-			if currentQty > 0 && actualPosition > 0.0000001 {
-				finalReduceOnly = true
+			// 双向持仓模式下卖单只平多头仓位，一律只减仓（不会与空头持仓抵消）
+			// 现货没有持仓概念，卖单不设置只减仓
+			finalReduceOnly := spm.hedgeMode()
+			if !finalReduceOnly && !spm.spotMode() {
+				// 检查真实持仓以决定是否设置ReduceOnly
+				actualPosition := spm.getExistingPosition()
+				// 🔥 关键修复：只有当槽位有多单且交易所确实有多头持仓时才设置ReduceOnly
+				if currentQty > 0 && actualPosition > 0.0000001 {
+					finalReduceOnly = true
+				}
+
+				logger.Debug("🔍 [ReduceOnly检查] 槽位持仓: %.4f, 交易所持仓: %.4f, ReduceOnly: %t",
+					currentQty, actualPosition, finalReduceOnly)
 			}
 			
-			logger.Debug("🔍 [ReduceOnly检查] 槽位持仓: %.4f, 交易所持仓: %.4f, ReduceOnly: %t", 
-				currentQty, actualPosition, finalReduceOnly)
-			
 			// 生成 ClientOrderID (注意：使用 SlotPrice 即买入价作为标识)
 			clientOID := spm.generateClientOrderID(candidate.SlotPrice, "SELL")
 
@@ -657,6 +799,7 @@ func (spm *SuperPositionManager) AdjustOrders(currentPrice float64) error {
 				Quantity:      candidate.Quantity,
 				PriceDecimals: spm.priceDecimals,
 				ReduceOnly:    finalReduceOnly, // Only set ReduceOnly if there's both slot position and actual exchange position
+				PositionSide:  spm.positionSide(false),
 				PostOnly:      usePostOnly,
 				ClientOrderID: clientOID, // 🔥
 			})
@@ -664,15 +807,15 @@ func (spm *SuperPositionManager) AdjustOrders(currentPrice float64) error {
 		}
 	}
 
-	// 3. 处理做空网格（在锚点1.2倍~3倍区域挂空单）
+	// 3. 处理做空网格（在锚点1.2倍~3倍区域挂空单，现货无法做空）
 	shortOrdersCreated := 0
-	if spm.crashDetector != nil && spm.crashDetector.IsEnabled() && spm.crashDetector.ShouldOpenShort() {
+	if !spm.spotMode() && spm.crashDetector != nil && spm.crashDetector.IsEnabled() && spm.crashDetector.ShouldOpenShort() {
 		shortOrdersCreated = spm.handleShortGrid(currentPrice, priceInterval, remainingOrders-buyOrdersToCreate-sellOrdersToCreate, &ordersToPlace)
 	}
 
 	// 4. 处理平空仓（买入平仓）
 	closeShortOrdersCreated := 0
-	if spm.crashDetector != nil && spm.crashDetector.IsEnabled() {
+	if !spm.spotMode() && spm.crashDetector != nil && spm.crashDetector.IsEnabled() {
 		closeShortOrdersCreated = spm.handleCloseShort(currentPrice, priceInterval, remainingOrders-buyOrdersToCreate-sellOrdersToCreate-shortOrdersCreated, &ordersToPlace)
 	}
 
@@ -795,6 +938,59 @@ func (spm *SuperPositionManager) OnOrderUpdate(update OrderUpdate) {
 	slot.mu.Lock()
 	defer slot.mu.Unlock()
 
+	spm.applyOrderUpdate(slot, price, side, update)
+}
+
+// BackfillOrderUpdate 补录订单流断线期间遗漏的成交（由对账器按成交记录与订单查询结果构造）
+// 订单已不是槽位的当前订单时（订单状态已由推送处理，或槽位已重新挂单）只记录成交明细，不再推进订单状态
+func (spm *SuperPositionManager) BackfillOrderUpdate(update OrderUpdate) {
+	price, side, valid := spm.parseClientOrderID(update.ClientOrderID)
+	if !valid {
+		logger.Debug("⏳ [忽略] 无法识别的补录成交: ID=%d, ClientOID=%s", update.OrderID, update.ClientOrderID)
+		return
+	}
+
+	slot := spm.getOrCreateSlot(price)
+	slot.mu.Lock()
+	defer slot.mu.Unlock()
+
+	if update.Status != "" && slot.ClientOID != update.ClientOrderID {
+		update.Status = ""
+	}
+	spm.applyOrderUpdate(slot, price, side, update)
+}
+
+// HasSeenTrade 成交是否已处理过（对账补录时跳过已由订单流处理的成交）
+func (spm *SuperPositionManager) HasSeenTrade(tradeID string) bool {
+	spm.tradeMu.Lock()
+	defer spm.tradeMu.Unlock()
+	_, seen := spm.seenTrades[tradeID]
+	return seen
+}
+
+// applyOrderUpdate 按订单更新推进槽位状态（调用方持有槽位锁）
+func (spm *SuperPositionManager) applyOrderUpdate(slot *InventorySlot, price float64, side string, update OrderUpdate) {
+	// 成交明细：按成交ID去重后记录手续费（手续费属于该价格槽位，与订单是否仍是当前订单无关）
+	// 同一笔成交重复推送（如断线重连后重放）时整条更新忽略，避免成交后槽位已重置再次累加持仓
+	fill := update.Fill
+	if fill != nil && !spm.markTradeSeen(fill.TradeID) {
+		logger.Debug("⏳ [忽略] 重复的成交推送: TradeID=%s, OrderID=%d", fill.TradeID, update.OrderID)
+		return
+	}
+	if fill != nil && fill.Commission != 0 {
+		slot.Fees += fill.Commission
+		slot.FeeAsset = fill.CommissionAsset
+		spm.addFee(fill)
+		// 现货买入以基础资产扣手续费时，实际到账数量少于成交数量，从槽位持仓中扣除
+		if spm.spotMode() && side == "BUY" && strings.EqualFold(fill.CommissionAsset, spm.exchange.GetBaseAsset()) {
+			slot.PositionQty -= fill.Commission
+		}
+	}
+	if update.Status == "" {
+		// 仅成交明细（如 Gate.io 单独推送），订单状态由订单推送处理
+		return
+	}
+
 	// 校验：确保这个更新属于当前的订单 (防止旧订单的延迟推送干扰新订单)
 	// 优先使用 ClientOrderID 匹配 (某些交易所如 Gate.io 的 OrderID 可能略有差异)
 	if slot.ClientOID != "" && slot.ClientOID != update.ClientOrderID {
@@ -831,11 +1027,22 @@ func (spm *SuperPositionManager) OnOrderUpdate(update OrderUpdate) {
 		}
 
 		slot.OrderFilledQty = update.ExecutedQty
+		fillPrice := spm.fillPrice(update, fill, price)
 
 		// 根据方向更新持仓
 		if side == "BUY" {
 			if deltaQty > 0 {
-				slot.PositionQty += deltaQty
+				if slot.PositionQty < -0.000001 && !spm.spotMode() {
+					// 买入平空：按开空成交额结转盈亏
+					spm.realizeCover(slot, deltaQty, fillPrice)
+					slot.PositionQty += deltaQty
+					if slot.PositionQty > 0 {
+						slot.PositionQty = 0
+					}
+				} else {
+					slot.CostBasis += spm.tradeValue(deltaQty, fillPrice)
+					slot.PositionQty += deltaQty
+				}
 				// 累加统计
 				oldTotal := spm.totalBuyQty.Load().(float64)
 				spm.totalBuyQty.Store(oldTotal + deltaQty)
@@ -854,6 +1061,11 @@ func (spm *SuperPositionManager) OnOrderUpdate(update OrderUpdate) {
 					slot.PositionStatus = PositionStatusFilled
 					logger.Info("✅ [买单成交] 价格: %s, 持仓: %.4f (多仓)",
 						formatPrice(price, spm.priceDecimals), slot.PositionQty)
+				} else if slot.PositionQty < -0.000001 {
+					// 平空后仍有剩余空仓
+					slot.PositionStatus = PositionStatusFilled
+					logger.Info("✅ [平空成交] 价格: %s, 剩余持仓: %.4f (空仓)",
+						formatPrice(price, spm.priceDecimals), slot.PositionQty)
 				} else {
 					// 持仓为0或负数 = 空仓位
 					slot.PositionStatus = PositionStatusEmpty
@@ -871,9 +1083,16 @@ func (spm *SuperPositionManager) OnOrderUpdate(update OrderUpdate) {
 
 		} else { // SELL
 			if deltaQty > 0 {
-				slot.PositionQty -= deltaQty
-				if slot.PositionQty < 0 {
-					slot.PositionQty = 0
+				if slot.IsShortGrid && slot.PositionQty <= 0.000001 {
+					// 做空网格开空：空仓记为负数，成本记开空成交额
+					slot.CostBasis += spm.tradeValue(deltaQty, fillPrice)
+					slot.PositionQty -= deltaQty
+				} else {
+					spm.realizeSell(slot, deltaQty, fillPrice)
+					slot.PositionQty -= deltaQty
+					if slot.PositionQty < 0 {
+						slot.PositionQty = 0
+					}
 				}
 				// 累加统计
 				oldTotal := spm.totalSellQty.Load().(float64)
@@ -893,6 +1112,11 @@ func (spm *SuperPositionManager) OnOrderUpdate(update OrderUpdate) {
 					slot.PositionStatus = PositionStatusFilled
 					logger.Info("✅ [卖单成交] 价格: %s, 剩余持仓: %.4f (多仓)",
 						formatPrice(price, spm.priceDecimals), slot.PositionQty)
+				} else if slot.PositionQty < -0.000001 {
+					// 负数持仓 = 空仓（做空网格开空成交）
+					slot.PositionStatus = PositionStatusFilled
+					logger.Info("✅ [开空成交] 价格: %s, 持仓: %.4f (空仓)",
+						formatPrice(price, spm.priceDecimals), slot.PositionQty)
 				} else {
 					// 持仓为0或负数 = 空仓位（平仓完成）
 					slot.PositionStatus = PositionStatusEmpty
@@ -916,7 +1140,13 @@ func (spm *SuperPositionManager) OnOrderUpdate(update OrderUpdate) {
 		// 🔥 核心修复：根据订单方向和成交情况处理槽位状态
 		if side == "BUY" {
 			// 买单被取消/拒绝
-			if slot.PositionQty > 0 || slot.OrderFilledQty > 0 {
+			if slot.PositionQty < -0.000001 {
+				// 平空单被取消：仍持有空仓，等待重挂
+				logger.Info("🔄 [平空单取消] 价格: %s, 保持空仓: %.4f, 等待重挂",
+					formatPrice(price, spm.priceDecimals), slot.PositionQty)
+				slot.PositionStatus = PositionStatusFilled
+				slot.SlotStatus = SlotStatusFree
+			} else if slot.PositionQty > 0 || slot.OrderFilledQty > 0 {
 				// 部分成交后被取消：保留持仓，允许后续挂卖单
 				logger.Info("💡 [买单部分成交后取消] 价格: %s, 持仓: %.4f, 转为多仓状态",
 					formatPrice(price, spm.priceDecimals), slot.PositionQty)
@@ -936,6 +1166,9 @@ func (spm *SuperPositionManager) OnOrderUpdate(update OrderUpdate) {
 				logger.Info("🔄 [空单取消] 价格: %s, 保持做空标记（防止重复下单）",
 					formatPrice(price, spm.priceDecimals))
 				slot.PositionStatus = PositionStatusEmpty
+				if slot.PositionQty < -0.000001 {
+					slot.PositionStatus = PositionStatusFilled // 部分成交后取消，保留空仓等待平空
+				}
 				slot.SlotStatus = SlotStatusFree
 				// 🔥 不重置 IsShortGrid，这样下次不会重复创建空单
 			} else if slot.PositionQty > 0 {
@@ -964,6 +1197,215 @@ func (spm *SuperPositionManager) OnOrderUpdate(update OrderUpdate) {
 	}
 }
 
+// markTradeSeen 记录成交ID，返回 false 表示该成交已处理过（没有成交ID时不去重）
+func (spm *SuperPositionManager) markTradeSeen(tradeID string) bool {
+	if tradeID == "" {
+		return true
+	}
+
+	spm.tradeMu.Lock()
+	defer spm.tradeMu.Unlock()
+
+	if spm.seenTrades == nil {
+		spm.seenTrades = make(map[string]struct{})
+	}
+	if _, seen := spm.seenTrades[tradeID]; seen {
+		return false
+	}
+	spm.seenTrades[tradeID] = struct{}{}
+	spm.seenTradeOrder = append(spm.seenTradeOrder, tradeID)
+
+	// 只保留最近的成交ID，重复推送只会发生在短时间内
+	const maxSeenTrades = 10000
+	if len(spm.seenTradeOrder) > maxSeenTrades {
+		evict := spm.seenTradeOrder[:len(spm.seenTradeOrder)-maxSeenTrades]
+		for _, id := range evict {
+			delete(spm.seenTrades, id)
+		}
+		spm.seenTradeOrder = append([]string(nil), spm.seenTradeOrder[len(evict):]...)
+	}
+	return true
+}
+
+// fillPrice 本次成交的实际价格：优先使用成交明细，其次订单成交均价，最后用挂单价
+func (spm *SuperPositionManager) fillPrice(update OrderUpdate, fill *FillEvent, slotPrice float64) float64 {
+	if fill != nil && fill.Price > 0 {
+		return fill.Price
+	}
+	if update.AvgPrice > 0 {
+		return update.AvgPrice
+	}
+	if update.Price > 0 {
+		return update.Price
+	}
+	return slotPrice
+}
+
+// realizeSell 卖出减仓时按持仓平均成本结转已实现盈亏（调用方持有 slot.mu）
+// 成本未知（如启动时恢复的持仓）时不计入盈亏
+func (spm *SuperPositionManager) realizeSell(slot *InventorySlot, qty, price float64) {
+	if slot.PositionQty <= 0 {
+		return
+	}
+	if qty > slot.PositionQty {
+		qty = slot.PositionQty
+	}
+	ratio := qty / slot.PositionQty
+	closed := qty >= slot.PositionQty-1e-12
+
+	// 持仓期间分摊的资金费随卖出按比例结转
+	pnl := slot.Funding * ratio
+	slot.Funding -= pnl
+	if closed {
+		slot.Funding = 0
+	}
+
+	if slot.CostBasis > 0 {
+		cost := slot.CostBasis * ratio
+		slot.CostBasis -= cost
+		if closed {
+			slot.CostBasis = 0
+		}
+		pnl += spm.closePnL(cost, spm.tradeValue(qty, price), true)
+	}
+	spm.addRealizedPnL(pnl)
+}
+
+// realizeCover 买入平空时按开空成交额结转已实现盈亏（调用方持有 slot.mu）
+// 成本未知（如启动时恢复的持仓）时不计入盈亏
+func (spm *SuperPositionManager) realizeCover(slot *InventorySlot, qty, price float64) {
+	shortQty := -slot.PositionQty
+	if shortQty <= 0 || slot.CostBasis <= 0 {
+		return
+	}
+	if qty > shortQty {
+		qty = shortQty
+	}
+
+	proceeds := slot.CostBasis * qty / shortQty
+	slot.CostBasis -= proceeds
+	if qty >= shortQty-1e-12 {
+		slot.CostBasis = 0
+	}
+	spm.addRealizedPnL(spm.closePnL(proceeds, spm.tradeValue(qty, price), false))
+}
+
+// OnFundingPayment 处理资金费收付，按持仓数量分摊到持有多仓的槽位
+// 分摊的资金费在槽位卖出时计入已实现盈亏，没有多仓时直接计入已实现盈亏
+func (spm *SuperPositionManager) OnFundingPayment(payment FundingPayment) {
+	if payment.Amount == 0 || !spm.markTradeSeen(fundingTradeKey(payment.ID)) {
+		return
+	}
+	spm.pnlMu.Lock()
+	spm.totalFunding += payment.Amount
+	spm.pnlMu.Unlock()
+
+	var longSlots []*InventorySlot
+	totalQty := 0.0
+	spm.slots.Range(func(key, value interface{}) bool {
+		slot := value.(*InventorySlot)
+		slot.mu.RLock()
+		if slot.PositionStatus == PositionStatusFilled && slot.PositionQty > 0 {
+			longSlots = append(longSlots, slot)
+			totalQty += slot.PositionQty
+		}
+		slot.mu.RUnlock()
+		return true
+	})
+
+	if totalQty <= 0 {
+		spm.addRealizedPnL(payment.Amount)
+		logger.Info("💸 [资金费] %.6f %s（无多仓，直接计入已实现盈亏）", payment.Amount, payment.Asset)
+		return
+	}
+
+	for _, slot := range longSlots {
+		slot.mu.Lock()
+		if slot.PositionQty > 0 {
+			slot.Funding += payment.Amount * slot.PositionQty / totalQty
+		}
+		slot.mu.Unlock()
+	}
+	logger.Info("💸 [资金费] %.6f %s，已分摊到 %d 个持仓槽位（总持仓 %.4f）",
+		payment.Amount, payment.Asset, len(longSlots), totalQty)
+}
+
+// fundingTradeKey 资金费记录的去重键（与成交ID共用去重表）
+func fundingTradeKey(id string) string {
+	if id == "" {
+		return ""
+	}
+	return "funding:" + id
+}
+
+// addRealizedPnL 累加已实现盈亏
+func (spm *SuperPositionManager) addRealizedPnL(pnl float64) {
+	spm.pnlMu.Lock()
+	spm.realizedPnL += pnl
+	spm.pnlMu.Unlock()
+}
+
+// addFee 累加成交手续费
+// 以盈亏币种（U本位/现货为计价币种，币本位为基础币种）收取的直接累计；以另一侧币种收取的按成交价换算；
+// 其他币种（如币安 BNB 抵扣）没有可用的汇率，按币种单独累计
+func (spm *SuperPositionManager) addFee(fill *FillEvent) {
+	base, quote := spm.exchange.GetBaseAsset(), spm.exchange.GetQuoteAsset()
+	profitAsset, otherAsset := quote, base
+	if spm.inverseMode() {
+		profitAsset, otherAsset = base, quote
+	}
+
+	fee, converted := fill.Commission, true
+	switch {
+	case fill.CommissionAsset == "" || strings.EqualFold(fill.CommissionAsset, profitAsset):
+	case strings.EqualFold(fill.CommissionAsset, otherAsset) && fill.Price > 0:
+		if spm.inverseMode() {
+			fee = fill.Commission / fill.Price
+		} else {
+			fee = fill.Commission * fill.Price
+		}
+	default:
+		converted = false
+	}
+
+	spm.pnlMu.Lock()
+	defer spm.pnlMu.Unlock()
+	if converted {
+		spm.totalFees += fee
+		return
+	}
+	if spm.otherFees == nil {
+		spm.otherFees = make(map[string]float64)
+	}
+	spm.otherFees[strings.ToUpper(fill.CommissionAsset)] += fill.Commission
+}
+
+// GetFundingPnL 获取累计资金费（正数为收入）
+func (spm *SuperPositionManager) GetFundingPnL() float64 {
+	spm.pnlMu.Lock()
+	defer spm.pnlMu.Unlock()
+	return spm.totalFunding
+}
+
+// GetRealizedPnL 获取按实际成交价计算的已实现盈亏与累计手续费（均为盈亏币种，
+// 无法换算的手续费见 GetOtherFees）
+func (spm *SuperPositionManager) GetRealizedPnL() (pnl float64, fees float64) {
+	spm.pnlMu.Lock()
+	defer spm.pnlMu.Unlock()
+	return spm.realizedPnL, spm.totalFees
+}
+
+// GetOtherFees 获取无法换算为盈亏币种的手续费（币种 -> 累计数量）
+func (spm *SuperPositionManager) GetOtherFees() map[string]float64 {
+	spm.pnlMu.Lock()
+	defer spm.pnlMu.Unlock()
+	fees := make(map[string]float64, len(spm.otherFees))
+	for asset, amount := range spm.otherFees {
+		fees[asset] = amount
+	}
+	return fees
+}
+
 // getOrCreateSlot 获取或创建槽位
 func (spm *SuperPositionManager) getOrCreateSlot(price float64) *InventorySlot {
 	if slot, exists := spm.slots.Load(price); exists {
@@ -1003,7 +1445,7 @@ func (spm *SuperPositionManager) findNearestGridPriceWithInterval(currentPrice f
 	// 计算最近的网格价格
 	gridPrice := spm.anchorPrice + intervals*priceInterval
 	// 使用检测到的价格精度进行舍入
-	return roundPrice(gridPrice, spm.priceDecimals)
+	return spm.alignPrice(gridPrice)
 }
 
 // calculateSlotPrices 计算槽位价格列表（统一的网格计算方法）
@@ -1039,7 +1481,7 @@ func (spm *SuperPositionManager) calculateSlotPricesWithInterval(gridPrice float
 			price = gridPrice + float64(i)*priceInterval
 		}
 		// 使用检测到的价格精度进行舍入
-		price = roundPrice(price, spm.priceDecimals)
+		price = spm.alignPrice(price)
 		prices = append(prices, price)
 	}
 
@@ -1122,6 +1564,26 @@ func (spm *SuperPositionManager) GetPriceInterval() float64 {
 	return spm.config.Trading.PriceInterval
 }
 
+// EstimatedProfit 按累计卖出数量与价格间距估算网格盈利，返回盈利与计价单位
+// U本位与现货：累计卖出数量 × 价格间距（U）；
+// 币本位：每张每格盈利 面值×(1/p - 1/(p+间距))，以基础币种计，p 取最近市场价格
+func (spm *SuperPositionManager) EstimatedProfit(priceInterval float64) (float64, string) {
+	totalSellQty := spm.totalSellQty.Load().(float64)
+	if !spm.inverseMode() {
+		return totalSellQty * priceInterval, "U"
+	}
+
+	price, ok := spm.lastMarketPrice.Load().(float64)
+	if !ok || price <= 0 {
+		price = spm.anchorPrice
+	}
+	if price <= 0 {
+		return 0, spm.exchange.GetBaseAsset()
+	}
+	cv := spm.symbolRules.ContractValue
+	return totalSellQty * cv * priceInterval / (price * (price + priceInterval)), spm.exchange.GetBaseAsset()
+}
+
 // ===== 订单清理功能已迁移到 safety.OrderCleaner =====
 // StartOrderCleanup 和 cleanupOrders 方法已移至 safety/order_cleaner.go
 
@@ -1244,6 +1706,10 @@ func (spm *SuperPositionManager) getExistingPosition() float64 {
 		// PositionInfo 切片（简化版）
 		for _, pos := range positions {
 			if pos != nil && pos.Symbol == spm.config.Trading.Symbol {
+				// 双向持仓模式下只取多头持仓（空头持仓属于做空网格）
+				if spm.hedgeMode() && pos.Size < 0 {
+					continue
+				}
 				// 🔥 关键修复：只有持仓不为0时才认为有持仓
 				if math.Abs(pos.Size) > 0.0000001 {
 					logger.Debug("🔍 [持仓恢复] 找到持仓 (PositionInfo): %.4f", pos.Size)
@@ -1301,7 +1767,7 @@ func (spm *SuperPositionManager) initializeSellSlotsFromPosition(totalPosition f
 	// 使用锚点价格作为参考价格，使用从交易所获取的数量精度
 
 	// 每单的理论数量 = 目标金额 / 锚点价格
-	theoryQtyPerSlot := spm.config.Trading.OrderQuantity / spm.anchorPrice
+	theoryQtyPerSlot := spm.orderQuantity(spm.anchorPrice)
 	theoryQtyPerSlot = roundPrice(theoryQtyPerSlot, spm.quantityDecimals)
 
 	// 2. 计算需要创建的总槽位数
@@ -1328,7 +1794,7 @@ func (spm *SuperPositionManager) initializeSellSlotsFromPosition(totalPosition f
 	var totalTheoryQty float64
 	theoryQtys := make([]float64, len(sellPrices))
 	for i, price := range sellPrices {
-		theoryQty := spm.config.Trading.OrderQuantity / price
+		theoryQty := spm.orderQuantity(price)
 		theoryQty = roundPrice(theoryQty, spm.quantityDecimals)
 		theoryQtys[i] = theoryQty
 		totalTheoryQty += theoryQty
@@ -1371,6 +1837,8 @@ func (spm *SuperPositionManager) initializeSellSlotsFromPosition(totalPosition f
 		// 设置为多仓状态
 		slot.PositionStatus = PositionStatusFilled
 		slot.PositionQty = slotQty
+		slot.CostBasis = 0 // 恢复的持仓没有成交记录，成本未知
+		slot.Funding = 0
 
 		// 清空订单信息，但设置方向为SELL（因为这是恢复的持仓，将来要挂卖单）
 		slot.OrderID = 0
@@ -1495,10 +1963,20 @@ func (spm *SuperPositionManager) PrintPositions() {
 	// 获取当前有效的价格间距
 	currentInterval := spm.GetCurrentPriceInterval(lastPrice)
 
-	// 预计盈利 = 累计卖出数量 × 价格间距（每笔盈利 = 价格间距 × 数量）
-	estimatedProfit := totalSellQty * currentInterval
-	logger.Info("累计买入: %.2f, 累计卖出: %.2f, 预计盈利: %.2f U",
-		totalBuyQty, totalSellQty, estimatedProfit)
+	estimatedProfit, profitUnit := spm.EstimatedProfit(currentInterval)
+	logger.Info("累计买入: %.2f, 累计卖出: %.2f, 预计盈利: %s %s",
+		totalBuyQty, totalSellQty, spm.formatProfit(estimatedProfit, 2), profitUnit)
+	if pnl, fees := spm.GetRealizedPnL(); pnl != 0 || fees != 0 {
+		logger.Info("已实现盈亏(按成交价，含已结转资金费): %s %s, 累计手续费: %s %s",
+			spm.formatProfit(pnl, 4), profitUnit, spm.formatProfit(fees, 4), profitUnit)
+	}
+	for asset, amount := range spm.GetOtherFees() {
+		logger.Info("累计手续费(%s 抵扣): %.8f %s", asset, amount, asset)
+	}
+	if funding := spm.GetFundingPnL(); funding != 0 {
+		logger.Info("累计资金费: %s %s, 扣除资金费后预计盈利: %s %s",
+			spm.formatProfit(funding, 4), profitUnit, spm.formatProfit(estimatedProfit+funding, 2), profitUnit)
+	}
 
 	// 打印动态网格信息（如果启用）
 	if spm.dynamicGridCalc != nil && spm.dynamicGridCalc.IsEnabled() {
@@ -1633,6 +2111,106 @@ func roundPrice(price float64, decimals int) float64 {
 	return math.Round(price*multiplier) / multiplier
 }
 
+// alignPrice 价格按精度取整，已知 TickSize 时再对齐到最近的价格步长
+func (spm *SuperPositionManager) alignPrice(price float64) float64 {
+	price = roundPrice(price, spm.priceDecimals)
+	if tick := spm.symbolRules.TickSize; tick > 0 {
+		price = roundPrice(math.Round(price/tick)*tick, spm.priceDecimals)
+	}
+	return price
+}
+
+// alignQuantity 数量按精度取整，已知 StepSize 时向下取整到步长的整数倍（避免超出预算与交易所拒单）
+func (spm *SuperPositionManager) alignQuantity(quantity float64) float64 {
+	if step := spm.symbolRules.StepSize; step > 0 {
+		quantity = math.Floor(quantity/step+1e-9) * step
+	}
+	return roundPrice(quantity, spm.quantityDecimals)
+}
+
+// minOrderValue 最小订单价值：取配置值与交易所最小名义价值中的较大者，都未知时默认6U
+func (spm *SuperPositionManager) minOrderValue() float64 {
+	minValue := spm.config.Trading.MinOrderValue
+	if spm.symbolRules.MinNotional > minValue {
+		minValue = spm.symbolRules.MinNotional
+	}
+	if minValue <= 0 {
+		minValue = 6.0 // 默认6U，略高于Binance的5U要求
+	}
+	return minValue
+}
+
+// hedgeMode 是否为双向持仓模式（多头网格与做空网格分别在 LONG/SHORT 方向持仓，互不抵消）
+func (spm *SuperPositionManager) hedgeMode() bool {
+	return spm.config.Trading.PositionMode == "hedge"
+}
+
+// spotMode 是否为现货网格（无杠杆、无做空，卖单受基础资产可用余额限制）
+func (spm *SuperPositionManager) spotMode() bool {
+	return spm.config.Trading.MarketType == "spot"
+}
+
+// inverseMode 是否为币本位合约（按张下单，保证金与盈亏以基础币种计）
+func (spm *SuperPositionManager) inverseMode() bool {
+	return spm.symbolRules.ContractValue > 0
+}
+
+// orderQuantity 每单下单数量：U本位与现货为 每单金额/价格（币数量），币本位为 每单面值/合约面值（张数）
+func (spm *SuperPositionManager) orderQuantity(price float64) float64 {
+	if spm.inverseMode() {
+		return spm.config.Trading.OrderQuantity / spm.symbolRules.ContractValue
+	}
+	return spm.config.Trading.OrderQuantity / price
+}
+
+// orderNotional 订单名义价值（计价币种），用于最小订单价值检查；币本位为 张数×面值
+func (spm *SuperPositionManager) orderNotional(price, qty float64) float64 {
+	if spm.inverseMode() {
+		return qty * spm.symbolRules.ContractValue
+	}
+	return price * qty
+}
+
+// tradeValue 成交额，用于结转持仓成本与盈亏：U本位与现货为 数量×价格，币本位为 张数×面值/价格（基础币种）
+func (spm *SuperPositionManager) tradeValue(qty, price float64) float64 {
+	if spm.inverseMode() {
+		return qty * spm.symbolRules.ContractValue / price
+	}
+	return qty * price
+}
+
+// closePnL 平仓盈亏：entryValue 为开仓成交额，exitValue 为平仓成交额，long 表示平多
+// 币本位合约的成交额（基础币种）随价格上涨而减少，盈亏方向与U本位相反
+func (spm *SuperPositionManager) closePnL(entryValue, exitValue float64, long bool) float64 {
+	pnl := exitValue - entryValue
+	if spm.inverseMode() {
+		pnl = -pnl
+	}
+	if !long {
+		pnl = -pnl
+	}
+	return pnl
+}
+
+// formatProfit 格式化盈亏金额，币本位（基础币种）固定保留8位小数
+func (spm *SuperPositionManager) formatProfit(amount float64, decimals int) string {
+	if spm.inverseMode() {
+		decimals = 8
+	}
+	return fmt.Sprintf("%.*f", decimals, amount)
+}
+
+// positionSide 订单的持仓方向：双向持仓模式下做空网格为 SHORT、其余为 LONG，单向持仓为空
+func (spm *SuperPositionManager) positionSide(short bool) string {
+	if !spm.hedgeMode() {
+		return ""
+	}
+	if short {
+		return "SHORT"
+	}
+	return "LONG"
+}
+
 // formatPrice 格式化价格字符串，使用指定的小数位数
 func formatPrice(price float64, decimals int) string {
 	return fmt.Sprintf("%.*f", decimals, price)
@@ -1691,7 +2269,7 @@ func (spm *SuperPositionManager) handleShortGrid(currentPrice float64, priceInte
 
 	// 生成做空槽位价格
 	for price := shortZoneMin; price <= shortZoneMax && len(candidates) < allowedNewShorts; price += priceInterval {
-		slotPrice := roundPrice(price, spm.priceDecimals)
+		slotPrice := spm.alignPrice(price)
 
 		slot := spm.getOrCreateSlot(slotPrice)
 		slot.mu.Lock()
@@ -1704,16 +2282,13 @@ func (spm *SuperPositionManager) handleShortGrid(currentPrice float64, priceInte
 			slot.ClientOID == "" &&
 			!slot.IsShortGrid { // 🔥 排除已经标记为做空的槽位（防止重复下单）
 
-			quantity := spm.config.Trading.OrderQuantity / slotPrice
-			quantity = roundPrice(quantity, spm.quantityDecimals)
+			quantity := spm.orderQuantity(slotPrice)
+			quantity = spm.alignQuantity(quantity)
 
-			orderValue := slotPrice * quantity
-			minValue := spm.config.Trading.MinOrderValue
-			if minValue <= 0 {
-				minValue = 6.0
-			}
+			orderValue := spm.orderNotional(slotPrice, quantity)
+			minValue := spm.minOrderValue()
 
-			if orderValue >= minValue {
+			if orderValue >= minValue && quantity >= spm.symbolRules.MinQty {
 				candidates = append(candidates, shortCandidate{
 					SlotPrice: slotPrice,
 					Quantity:  quantity,
@@ -1747,6 +2322,7 @@ func (spm *SuperPositionManager) handleShortGrid(currentPrice float64, priceInte
 			Quantity:      candidate.Quantity,
 			PriceDecimals: spm.priceDecimals,
 			ReduceOnly:    false,
+			PositionSide:  spm.positionSide(true),
 			PostOnly:      usePostOnly,
 			ClientOrderID: clientOID,
 		})
@@ -1803,7 +2379,7 @@ func (spm *SuperPositionManager) handleCloseShort(currentPrice float64, priceInt
 				// 价格接近开空价，使用正常平仓价
 				closePrice = slotPrice - priceInterval
 			}
-			closePrice = roundPrice(closePrice, spm.priceDecimals)
+			closePrice = spm.alignPrice(closePrice)
 
 			profitRate := (slotPrice - closePrice) / slotPrice
 			if profitRate >= 0.001 { // 最小0.1%利润率
@@ -1829,12 +2405,12 @@ func (spm *SuperPositionManager) handleCloseShort(currentPrice float64, priceInt
 			break
 		}
 
-		orderValue := candidate.ClosePrice * candidate.Quantity
-		minValue := spm.config.Trading.MinOrderValue
-		if minValue <= 0 {
-			minValue = 6.0
-		}
-		if orderValue < minValue {
+		// 🔥 最小名义价值检查（以交易所要求为准）
+		orderValue := spm.orderNotional(candidate.ClosePrice, candidate.Quantity)
+		minValue := spm.minOrderValue()
+		if orderValue < minValue || candidate.Quantity < spm.symbolRules.MinQty {
+			logger.Debug("⏭️ [跳过平空单] 价格 %s 名义价值 %.2f < %.2f，不满足最小订单要求",
+				formatPrice(candidate.ClosePrice, spm.priceDecimals), orderValue, minValue)
 			continue
 		}
 
@@ -1848,17 +2424,20 @@ func (spm *SuperPositionManager) handleCloseShort(currentPrice float64, priceInt
 		usePostOnly := slot.PostOnlyFailCount < 3
 		slot.mu.Unlock()
 
-		// 检查真实持仓以决定是否设置ReduceOnly
-		actualPosition := spm.getExistingPosition()
-		// 🔥 关键修复：只有当槽位有空单且交易所确实有空头持仓时才设置ReduceOnly
-		finalReduceOnly := false
-		if math.Abs(candidate.Quantity) > 0.0000001 && actualPosition < -0.0000001 {
-			finalReduceOnly = true
+		// 双向持仓模式下平空单只平空头仓位，一律只减仓
+		finalReduceOnly := spm.hedgeMode()
+		if !finalReduceOnly {
+			// 检查真实持仓以决定是否设置ReduceOnly
+			actualPosition := spm.getExistingPosition()
+			// 🔥 关键修复：只有当槽位有空单且交易所确实有空头持仓时才设置ReduceOnly
+			if math.Abs(candidate.Quantity) > 0.0000001 && actualPosition < -0.0000001 {
+				finalReduceOnly = true
+			}
+
+			logger.Debug("🔍 [平空ReduceOnly检查] 槽位持仓: %.4f, 交易所持仓: %.4f, ReduceOnly: %t",
+				candidate.Quantity, actualPosition, finalReduceOnly)
 		}
-		
-		logger.Debug("🔍 [平空ReduceOnly检查] 槽位持仓: %.4f, 交易所持仓: %.4f, ReduceOnly: %t", 
-			candidate.Quantity, actualPosition, finalReduceOnly)
-		
+
 		clientOID := spm.generateClientOrderID(candidate.SlotPrice, "BUY")
 		*ordersToPlace = append(*ordersToPlace, &OrderRequest{
 			Symbol:        spm.config.Trading.Symbol,
@@ -1867,6 +2446,7 @@ func (spm *SuperPositionManager) handleCloseShort(currentPrice float64, priceInt
 			Quantity:      candidate.Quantity,
 			PriceDecimals: spm.priceDecimals,
 			ReduceOnly:    finalReduceOnly, // Only set ReduceOnly if there's an actual position to close
+			PositionSide:  spm.positionSide(true),
 			PostOnly:      usePostOnly,
 			ClientOrderID: clientOID,
 		})
@@ -3,8 +3,10 @@ package position
 import (
 	"context"
 	"fmt"
+	"math"
 	"opensqt/config"
 	"opensqt/monitor"
+	"opensqt/utils"
 	"sort"
 	"strings"
 	"sync"
@@ -91,7 +93,8 @@ func (m *MockOrderExecutor) ClearOrders() {
 
 // MockExchange 模拟交易所
 type MockExchange struct {
-	name string
+	name     string
+	freeBase float64 // 基础资产可用余额（现货模式）
 }
 
 func NewMockExchange() *MockExchange {
@@ -109,8 +112,12 @@ func (m *MockExchange) GetOrder(ctx context.Context, symbol string, orderID int6
 	return nil, nil
 }
 func (m *MockExchange) GetBaseAsset() string { return "DOGE" }
+func (m *MockExchange) GetQuoteAsset() string { return "USDT" }
 func (m *MockExchange) CancelAllOrders(ctx context.Context, symbol string) error { return nil }
 func (m *MockExchange) GetAvailableBalance(ctx context.Context) (float64, error) { return 10000, nil }
+func (m *MockExchange) GetFreeBaseBalance(ctx context.Context) (float64, error) {
+	return m.freeBase, nil
+}
 
 // MockCrashDetector 模拟开空检测器
 type MockCrashDetector struct {
@@ -2059,3 +2066,353 @@ func TestAlwaysEnableShortGrid(t *testing.T) {
 	fmt.Println("   - 修改了crash_detector.go中的shouldShort判断逻辑")
 	fmt.Println("   - 现在只要做空区域有效，就会允许挂空单")
 }
+
+// TestSymbolRulesAlignment 测试按交易所规则对齐价格/数量与最小名义价值
+func TestSymbolRulesAlignment(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Trading.MinOrderValue = 0
+	spm := NewSuperPositionManager(cfg, NewMockOrderExecutor(), NewMockExchange(), 4, 3)
+
+	// 未设置规则：仅按精度取整，最小订单价值默认6U
+	if got := spm.alignPrice(0.12346); got != 0.1235 {
+		t.Errorf("未设置规则时价格应为 0.1235, 实际 %v", got)
+	}
+	if got := spm.alignQuantity(12.3456); got != 12.346 {
+		t.Errorf("未设置规则时数量应为 12.346, 实际 %v", got)
+	}
+	if got := spm.minOrderValue(); got != 6.0 {
+		t.Errorf("未设置规则时最小订单价值应为 6, 实际 %v", got)
+	}
+
+	spm.SetSymbolRules(SymbolRules{TickSize: 0.0005, StepSize: 0.25, MinQty: 1, MinNotional: 10})
+
+	if got := spm.alignPrice(0.12346); got != 0.1235 {
+		t.Errorf("价格应对齐到 0.1235, 实际 %v", got)
+	}
+	if got := spm.alignPrice(0.12374); got != 0.1235 {
+		t.Errorf("价格应对齐到 0.1235, 实际 %v", got)
+	}
+	if got := spm.alignQuantity(12.3456); got != 12.25 {
+		t.Errorf("数量应向下取整到 12.25, 实际 %v", got)
+	}
+	if got := spm.alignQuantity(0.75); got != 0.75 {
+		t.Errorf("步长整数倍的数量不应改变, 实际 %v", got)
+	}
+	if got := spm.minOrderValue(); got != 10 {
+		t.Errorf("最小订单价值应取交易所要求 10, 实际 %v", got)
+	}
+
+	// 配置值高于交易所要求时以配置为准
+	cfg.Trading.MinOrderValue = 20
+	if got := spm.minOrderValue(); got != 20 {
+		t.Errorf("最小订单价值应取配置值 20, 实际 %v", got)
+	}
+}
+
+// TestCloseShortHonoursSymbolRules 平空单同样以交易所最小名义价值/最小数量为准
+func TestCloseShortHonoursSymbolRules(t *testing.T) {
+	cfg := createTestConfig() // 配置的最小订单价值 5U
+	spm := NewSuperPositionManager(cfg, NewMockOrderExecutor(), NewMockExchange(), 6, 0)
+	spm.SetSymbolRules(SymbolRules{TickSize: 0.001, StepSize: 1, MinQty: 80, MinNotional: 10})
+
+	for _, pos := range []struct {
+		price    float64
+		quantity float64
+	}{
+		{0.168, -40},  // 平仓价值 6.68U：高于配置值，低于交易所要求 10U
+		{0.169, -100}, // 平仓价值 16.8U
+		{0.170, -70},  // 平仓价值 11.83U，但数量低于交易所最小数量 80
+	} {
+		slot := spm.getOrCreateSlot(pos.price)
+		slot.PositionStatus = PositionStatusFilled
+		slot.PositionQty = pos.quantity
+		slot.SlotStatus = SlotStatusFree
+	}
+
+	var orders []*OrderRequest
+	created := spm.handleCloseShort(0.167, cfg.Trading.PriceInterval, 10, &orders)
+	if created != 1 || len(orders) != 1 {
+		t.Fatalf("应只生成 1 个满足交易所要求的平空单, 实际 %d: %+v", created, orders)
+	}
+	if orders[0].Side != "BUY" || orders[0].Quantity != 100 || math.Abs(orders[0].Price-0.168) > 1e-9 {
+		t.Errorf("平空单参数错误: %+v", orders[0])
+	}
+	for _, price := range []float64{0.168, 0.170} {
+		if status := spm.getOrCreateSlot(price).SlotStatus; status != SlotStatusFree {
+			t.Errorf("被跳过的槽位 %.3f 应保持空闲, 实际 %v", price, status)
+		}
+	}
+}
+
+func TestOnOrderUpdateRecordsFills(t *testing.T) {
+	cfg := createTestConfig()
+	spm := NewSuperPositionManager(cfg, NewMockOrderExecutor(), NewMockExchange(), 2, 3)
+
+	buyID := utils.GenerateOrderID(100, "BUY", 2)
+	buyFill := &FillEvent{TradeID: "t1", Price: 99.9, Quantity: 1, Commission: 0.02, CommissionAsset: "USDT", IsMaker: true}
+	spm.OnOrderUpdate(OrderUpdate{OrderID: 1, ClientOrderID: buyID, Status: "FILLED", ExecutedQty: 1, Price: 100, Side: "BUY", Fill: buyFill})
+	// 断线重连后重复推送同一笔成交：不重复计费
+	spm.OnOrderUpdate(OrderUpdate{OrderID: 1, ClientOrderID: buyID, Status: "FILLED", ExecutedQty: 1, Price: 100, Side: "BUY", Fill: buyFill})
+
+	slot := spm.getOrCreateSlot(100)
+	if slot.PositionQty != 1 || math.Abs(slot.CostBasis-99.9) > 1e-9 {
+		t.Errorf("买入后持仓/成本 = %v/%v, want 1/99.9", slot.PositionQty, slot.CostBasis)
+	}
+	if math.Abs(slot.Fees-0.02) > 1e-9 {
+		t.Errorf("重复成交不应重复计费, 手续费 = %v", slot.Fees)
+	}
+
+	// 仅成交明细的推送（Gate.io）只记录手续费，不改变持仓
+	sellID := utils.GenerateOrderID(100, "SELL", 2)
+	spm.OnOrderUpdate(OrderUpdate{OrderID: 2, ClientOrderID: sellID, Side: "SELL",
+		Fill: &FillEvent{TradeID: "t2", Price: 101, Quantity: 1, Commission: 0.01, CommissionAsset: "USDT"}})
+	if slot.PositionQty != 1 || math.Abs(slot.Fees-0.03) > 1e-9 {
+		t.Errorf("仅成交明细推送后持仓/手续费 = %v/%v, want 1/0.03", slot.PositionQty, slot.Fees)
+	}
+
+	// 订单推送不带成交明细时按成交均价结转盈亏
+	spm.OnOrderUpdate(OrderUpdate{OrderID: 2, ClientOrderID: sellID, Status: "FILLED", ExecutedQty: 1, Price: 100, AvgPrice: 101, Side: "SELL"})
+	pnl, fees := spm.GetRealizedPnL()
+	if math.Abs(pnl-1.1) > 1e-9 || math.Abs(fees-0.03) > 1e-9 {
+		t.Errorf("已实现盈亏/手续费 = %v/%v, want 1.1/0.03", pnl, fees)
+	}
+	if slot.PositionQty != 0 || slot.CostBasis != 0 {
+		t.Errorf("平仓后持仓/成本应清零, 实际 %v/%v", slot.PositionQty, slot.CostBasis)
+	}
+}
+
+func TestFeesConvertedToProfitAsset(t *testing.T) {
+	cfg := createTestConfig()
+	spm := NewSuperPositionManager(cfg, NewMockOrderExecutor(), NewMockExchange(), 4, 3)
+
+	// 并发成交（不同槽位）累加手续费不丢失
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			spm.addFee(&FillEvent{Price: 0.2, Commission: 0.01, CommissionAsset: "USDT"})
+		}()
+	}
+	wg.Wait()
+
+	// 以基础币种收取的手续费按成交价换算为计价币种，BNB 抵扣单独累计
+	spm.addFee(&FillEvent{Price: 0.2, Commission: 5, CommissionAsset: "DOGE"})
+	spm.addFee(&FillEvent{Price: 0.2, Commission: 0.0003, CommissionAsset: "BNB"})
+
+	if _, fees := spm.GetRealizedPnL(); math.Abs(fees-2) > 1e-9 {
+		t.Errorf("累计手续费 = %v, want 2 (1 USDT + 5 DOGE × 0.2)", fees)
+	}
+	if other := spm.GetOtherFees(); len(other) != 1 || math.Abs(other["BNB"]-0.0003) > 1e-12 {
+		t.Errorf("BNB 手续费应单独累计, got %v", other)
+	}
+}
+
+func TestOnFundingPaymentAttributesToLongSlots(t *testing.T) {
+	cfg := createTestConfig()
+	spm := NewSuperPositionManager(cfg, NewMockOrderExecutor(), NewMockExchange(), 2, 3)
+
+	spm.OnOrderUpdate(OrderUpdate{OrderID: 1, ClientOrderID: utils.GenerateOrderID(100, "BUY", 2), Status: "FILLED", ExecutedQty: 1, Price: 100, Side: "BUY"})
+	spm.OnOrderUpdate(OrderUpdate{OrderID: 2, ClientOrderID: utils.GenerateOrderID(99, "BUY", 2), Status: "FILLED", ExecutedQty: 3, Price: 99, Side: "BUY"})
+
+	// 资金费按持仓数量分摊，重复的记录只处理一次
+	payment := FundingPayment{ID: "f1", Amount: -0.4, Asset: "USDT"}
+	spm.OnFundingPayment(payment)
+	spm.OnFundingPayment(payment)
+
+	slot100, slot99 := spm.getOrCreateSlot(100), spm.getOrCreateSlot(99)
+	if math.Abs(slot100.Funding+0.1) > 1e-9 || math.Abs(slot99.Funding+0.3) > 1e-9 {
+		t.Errorf("资金费分摊 = %v/%v, want -0.1/-0.3", slot100.Funding, slot99.Funding)
+	}
+	if funding := spm.GetFundingPnL(); math.Abs(funding+0.4) > 1e-9 {
+		t.Errorf("累计资金费 = %v, want -0.4", funding)
+	}
+
+	// 卖出时分摊的资金费计入已实现盈亏：(101-100)×1 - 0.1
+	spm.OnOrderUpdate(OrderUpdate{OrderID: 3, ClientOrderID: utils.GenerateOrderID(100, "SELL", 2), Status: "FILLED", ExecutedQty: 1, Price: 100, AvgPrice: 101, Side: "SELL"})
+	if pnl, _ := spm.GetRealizedPnL(); math.Abs(pnl-0.9) > 1e-9 {
+		t.Errorf("已实现盈亏 = %v, want 0.9", pnl)
+	}
+	if slot100.Funding != 0 {
+		t.Errorf("平仓后槽位资金费应清零, 实际 %v", slot100.Funding)
+	}
+}
+
+func TestHedgeModeShortGridFillAccounting(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Trading.PositionMode = "hedge"
+	spm := NewSuperPositionManager(cfg, NewMockOrderExecutor(), NewMockExchange(), 2, 3)
+
+	if spm.positionSide(false) != "LONG" || spm.positionSide(true) != "SHORT" {
+		t.Fatalf("双向持仓模式下持仓方向错误: %q/%q", spm.positionSide(false), spm.positionSide(true))
+	}
+
+	// 做空网格开空成交：持仓记为负数，不会被当作卖出多仓清零
+	slot := spm.getOrCreateSlot(120)
+	slot.IsShortGrid = true
+	spm.OnOrderUpdate(OrderUpdate{OrderID: 1, ClientOrderID: utils.GenerateOrderID(120, "SELL", 2), Status: "FILLED", ExecutedQty: 2, Price: 120, Side: "SELL"})
+	if slot.PositionQty != -2 || slot.PositionStatus != PositionStatusFilled {
+		t.Fatalf("开空后持仓 = %v (%s), want -2 (FILLED)", slot.PositionQty, slot.PositionStatus)
+	}
+
+	// 买入平空按开空成交额结转盈亏：(120-119)×2
+	spm.OnOrderUpdate(OrderUpdate{OrderID: 2, ClientOrderID: utils.GenerateOrderID(120, "BUY", 2), Status: "FILLED", ExecutedQty: 2, Price: 119, Side: "BUY"})
+	if slot.PositionQty != 0 || slot.PositionStatus != PositionStatusEmpty {
+		t.Errorf("平空后持仓 = %v (%s), want 0 (EMPTY)", slot.PositionQty, slot.PositionStatus)
+	}
+	if pnl, _ := spm.GetRealizedPnL(); math.Abs(pnl-2) > 1e-9 {
+		t.Errorf("已实现盈亏 = %v, want 2", pnl)
+	}
+}
+
+func TestSpotModeSellsLimitedByFreeBase(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Trading.MarketType = "spot"
+	exchange := NewMockExchange()
+	exchange.freeBase = 150
+	executor := NewMockOrderExecutor()
+	spm := NewSuperPositionManager(cfg, executor, exchange, 4, 1)
+
+	// 买入手续费以基础资产扣除：槽位持仓为实际到账数量
+	spm.OnOrderUpdate(OrderUpdate{OrderID: 1, ClientOrderID: utils.GenerateOrderID(0.139, "BUY", 4), Status: "FILLED", ExecutedQty: 100, Price: 0.139, Side: "BUY",
+		Fill: &FillEvent{TradeID: "t1", Price: 0.139, Quantity: 100, Commission: 0.1, CommissionAsset: "DOGE"}})
+	spm.OnOrderUpdate(OrderUpdate{OrderID: 2, ClientOrderID: utils.GenerateOrderID(0.138, "BUY", 4), Status: "FILLED", ExecutedQty: 100, Price: 0.138, Side: "BUY"})
+	if qty := spm.getOrCreateSlot(0.139).PositionQty; math.Abs(qty-99.9) > 1e-9 {
+		t.Fatalf("扣除手续费后持仓 = %v, want 99.9", qty)
+	}
+
+	// 可用余额 150 只够卖出最近的槽位，另一槽位跳过；现货卖单不设置只减仓与持仓方向
+	if err := spm.AdjustOrders(0.1395); err != nil {
+		t.Fatal(err)
+	}
+	var sells []*OrderRequest
+	for _, req := range executor.GetPlacedOrders() {
+		if req.Side == "SELL" {
+			sells = append(sells, req)
+		}
+	}
+	if len(sells) != 1 {
+		t.Fatalf("卖单数量 = %d, want 1", len(sells))
+	}
+	if math.Abs(sells[0].Quantity-99.9) > 1e-9 || sells[0].ReduceOnly || sells[0].PositionSide != "" {
+		t.Errorf("现货卖单 = %+v, want 数量 99.9 且不只减仓", sells[0])
+	}
+}
+
+func TestInverseModeSizingAndCoinPnL(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Trading.MarketType = "inverse"
+	cfg.Trading.PriceInterval = 100
+	cfg.Trading.OrderQuantity = 200 // 每单面值 200 USD = 2 张
+	executor := NewMockOrderExecutor()
+	spm := NewSuperPositionManager(cfg, executor, NewMockExchange(), 1, 0)
+	spm.SetSymbolRules(SymbolRules{TickSize: 0.1, StepSize: 1, MinQty: 1, ContractValue: 100})
+	spm.anchorPrice = 50000
+	spm.lastMarketPrice.Store(50000.0)
+	spm.isInitialized.Store(true)
+
+	// 币本位按张数下单：每单数量与价格无关
+	if err := spm.AdjustOrders(50050); err != nil {
+		t.Fatal(err)
+	}
+	buys := 0
+	for _, req := range executor.GetPlacedOrders() {
+		if req.Side == "BUY" {
+			buys++
+			if req.Quantity != 2 {
+				t.Errorf("买单 %.1f 数量 = %v, want 2 张", req.Price, req.Quantity)
+			}
+		}
+	}
+	if buys == 0 {
+		t.Fatal("应挂出买单")
+	}
+
+	// 盈亏以基础币种计：2 × 100 × (1/40000 - 1/40100)
+	spm.OnOrderUpdate(OrderUpdate{OrderID: 1, ClientOrderID: utils.GenerateOrderID(40000, "BUY", 1), Status: "FILLED", ExecutedQty: 2, Price: 40000, Side: "BUY"})
+	slot := spm.getOrCreateSlot(40000)
+	if math.Abs(slot.CostBasis-0.005) > 1e-12 {
+		t.Errorf("持仓成本 = %v, want 0.005", slot.CostBasis)
+	}
+	spm.OnOrderUpdate(OrderUpdate{OrderID: 2, ClientOrderID: utils.GenerateOrderID(40000, "SELL", 1), Status: "FILLED", ExecutedQty: 2, Price: 40100, Side: "SELL"})
+	want := 200.0/40000 - 200.0/40100
+	if pnl, _ := spm.GetRealizedPnL(); math.Abs(pnl-want) > 1e-12 {
+		t.Errorf("已实现盈亏 = %v, want %v", pnl, want)
+	}
+	spm.lastMarketPrice.Store(40000.0)
+	if profit, unit := spm.EstimatedProfit(100); math.Abs(profit-want) > 1e-12 || unit != "DOGE" {
+		t.Errorf("预计盈利 = %v %s, want %v DOGE", profit, unit, want)
+	}
+}
+
+func TestAdjustOrdersWithQuoteSpreadSafety(t *testing.T) {
+	cfg := createTestConfig()
+	executor := NewMockOrderExecutor()
+	spm := NewSuperPositionManager(cfg, executor, NewMockExchange(), 4, 1)
+	spm.anchorPrice = 0.14
+	spm.lastMarketPrice.Store(0.14)
+	spm.isInitialized.Store(true)
+
+	slot := spm.getOrCreateSlot(0.137)
+	slot.PositionStatus = PositionStatusFilled
+	slot.PositionQty = 100
+
+	// 最新成交价 0.1395 高于盘口：按固定缓冲 0.139 可挂，但高于买一 0.1384 会吃掉卖盘
+	if err := spm.AdjustOrdersWithQuote(0.1395, 0.1384, 0.1386); err != nil {
+		t.Fatal(err)
+	}
+	var buys, sells int
+	for _, req := range executor.GetPlacedOrders() {
+		switch req.Side {
+		case "BUY":
+			buys++
+			if req.Price > 0.1384 {
+				t.Errorf("买单价格 %.4f 高于买一 0.1384", req.Price)
+			}
+		case "SELL":
+			sells++
+			// 目标卖价 0.138 不高于买一，上调至卖一
+			if math.Abs(req.Price-0.1386) > 1e-9 {
+				t.Errorf("卖单价格 = %.4f, want 0.1386", req.Price)
+			}
+		}
+	}
+	if buys == 0 || sells != 1 {
+		t.Fatalf("买单 %d 个、卖单 %d 个，want 买单 >0、卖单 1", buys, sells)
+	}
+}
+
+func TestBackfillOrderUpdate(t *testing.T) {
+	cfg := createTestConfig()
+	spm := NewSuperPositionManager(cfg, NewMockOrderExecutor(), NewMockExchange(), 2, 3)
+
+	buyID := utils.GenerateOrderID(100, "BUY", 2)
+	slot := spm.getOrCreateSlot(100)
+	slot.OrderID = 1
+	slot.ClientOID = buyID
+	slot.OrderSide = "BUY"
+	slot.OrderStatus = OrderStatusPlaced
+
+	// 订单流断线期间的两笔成交：前一笔只记录手续费，最后一笔携带订单最新状态
+	spm.BackfillOrderUpdate(OrderUpdate{OrderID: 1, ClientOrderID: buyID, Side: "BUY",
+		Fill: &FillEvent{TradeID: "t1", Price: 100, Quantity: 1, Commission: 0.02, CommissionAsset: "USDT"}})
+	spm.BackfillOrderUpdate(OrderUpdate{OrderID: 1, ClientOrderID: buyID, Status: "FILLED", ExecutedQty: 2, Price: 100, Side: "BUY",
+		Fill: &FillEvent{TradeID: "t2", Price: 100, Quantity: 1, Commission: 0.02, CommissionAsset: "USDT"}})
+	if slot.PositionQty != 2 || slot.PositionStatus != PositionStatusFilled || slot.ClientOID != "" {
+		t.Errorf("补录后持仓/状态/订单 = %v/%s/%q, want 2/FILLED/空", slot.PositionQty, slot.PositionStatus, slot.ClientOID)
+	}
+	if math.Abs(slot.Fees-0.04) > 1e-9 {
+		t.Errorf("补录手续费 = %v, want 0.04", slot.Fees)
+	}
+	if !spm.HasSeenTrade("t1") || !spm.HasSeenTrade("t2") || spm.HasSeenTrade("t3") {
+		t.Error("补录的成交应记为已处理")
+	}
+
+	// 订单已不是槽位的当前订单：只记录手续费，不再推进订单状态
+	sellID := utils.GenerateOrderID(100, "SELL", 2)
+	spm.BackfillOrderUpdate(OrderUpdate{OrderID: 2, ClientOrderID: sellID, Status: "FILLED", ExecutedQty: 2, Price: 100, Side: "SELL",
+		Fill: &FillEvent{TradeID: "t3", Price: 101, Quantity: 2, Commission: 0.01, CommissionAsset: "USDT"}})
+	if slot.PositionQty != 2 || math.Abs(slot.Fees-0.05) > 1e-9 {
+		t.Errorf("过期订单补录后持仓/手续费 = %v/%v, want 2/0.05", slot.PositionQty, slot.Fees)
+	}
+}
@@ -3,6 +3,7 @@ package safety
 import (
 	"context"
 	"opensqt/config"
+	"opensqt/exchange"
 	"opensqt/logger"
 	"reflect"
 	"sort"
@@ -35,6 +36,9 @@ type OrderCleaner struct {
 	cfg      *config.Config
 	executor IOrderExecutor
 	pm       IOrderCleanerPositionManager
+
+	// 交易所原生批量撤单大小（未配置清理批次时作为默认值，一次请求撤完一批）
+	nativeBatchCancelSize int
 }
 
 // NewOrderCleaner 创建订单清理器
@@ -46,6 +50,11 @@ func NewOrderCleaner(cfg *config.Config, executor IOrderExecutor, pm IOrderClean
 	}
 }
 
+// SetCapabilities 根据交易所能力调整清理策略
+func (oc *OrderCleaner) SetCapabilities(caps exchange.Capabilities) {
+	oc.nativeBatchCancelSize = caps.NativeBatchCancelSize
+}
+
 // Start 启动订单清理协程
 func (oc *OrderCleaner) Start(ctx context.Context) {
 	go func() {
@@ -152,6 +161,10 @@ func (oc *OrderCleaner) CleanupOrders() {
 	batchSize := oc.cfg.Trading.CleanupBatchSize
 	if batchSize <= 0 {
 		batchSize = 10
+		// 未配置时按交易所原生批量撤单大小清理，正好一次请求
+		if oc.nativeBatchCancelSize > 0 {
+			batchSize = oc.nativeBatchCancelSize
+		}
 	}
 
 	// 🔥 核心策略：达到阈值才清理，不提前
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"opensqt/config"
+	"opensqt/exchange"
 	"opensqt/logger"
 	"reflect"
 	"time"
@@ -16,6 +17,23 @@ type IExchange interface {
 	GetBaseAsset() string // 获取基础资产（交易币种）
 }
 
+// ITradeHistory 成交补录所需的交易所接口方法
+type ITradeHistory interface {
+	GetMyTrades(ctx context.Context, symbol string, since time.Time, limit int) ([]*exchange.Trade, error)
+	GetOrder(ctx context.Context, symbol string, orderID int64) (*exchange.Order, error)
+}
+
+const (
+	// tradeBackfillSettle 成交发生后等待订单流推送的时间，超过后仍未处理的成交视为遗漏
+	tradeBackfillSettle = 10 * time.Second
+	// tradeBackfillOverlap 每次查询向前多查的时间，覆盖交易所与本地的时钟偏差
+	tradeBackfillOverlap = time.Minute
+	// tradeBackfillPageSize 每页查询的成交条数（取各交易所上限的最小值，Bitget 为 100）
+	tradeBackfillPageSize = 100
+	// tradeBackfillMaxPages 单次对账最多翻页数，剩余成交留待下次对账继续
+	tradeBackfillMaxPages = 10
+)
+
 // SlotInfo 槽位信息（避免直接依赖 position 包的内部结构）
 type SlotInfo struct {
 	Price          float64
@@ -42,6 +60,8 @@ type IPositionManager interface {
 	// 获取配置信息
 	GetSymbol() string
 	GetPriceInterval() float64
+	// 按价格间距估算网格盈利，返回盈利与计价单位（U本位为 U，币本位为基础币种）
+	EstimatedProfit(priceInterval float64) (float64, string)
 }
 
 // Reconciler 持仓对账器
@@ -50,6 +70,13 @@ type Reconciler struct {
 	exchange     IExchange
 	pm           IPositionManager
 	pauseChecker func() bool
+
+	// 成交补录（订单流断线期间遗漏的成交），未设置时不补录
+	tradeHistory     ITradeHistory
+	tradeSeen        func(tradeID string) bool
+	applyTrades      func(trades []*exchange.Trade, order *exchange.Order)
+	tradeCursor      time.Time        // 已补录到的成交时间
+	backfilledTrades map[string]int64 // 已补录的成交ID -> 成交时间（查询区间重叠部分去重）
 }
 
 // NewReconciler 创建对账器
@@ -66,6 +93,16 @@ func (r *Reconciler) SetPauseChecker(checker func() bool) {
 	r.pauseChecker = checker
 }
 
+// SetTradeBackfill 设置成交补录：对账时查询成交记录，补录订单流断线期间遗漏的成交
+// seen 判断成交是否已由订单流处理；apply 补录同一订单的遗漏成交（按时间升序），order 为订单最新状态
+func (r *Reconciler) SetTradeBackfill(history ITradeHistory, seen func(tradeID string) bool, apply func(trades []*exchange.Trade, order *exchange.Order)) {
+	r.tradeHistory = history
+	r.tradeSeen = seen
+	r.applyTrades = apply
+	r.tradeCursor = time.Now() // 启动前的成交已体现在初始持仓中
+	r.backfilledTrades = make(map[string]int64)
+}
+
 // Start 启动对账协程
 func (r *Reconciler) Start(ctx context.Context) {
 	go func() {
@@ -102,6 +139,11 @@ func (r *Reconciler) Reconcile() error {
 
 	symbol := r.pm.GetSymbol()
 
+	// 0. 补录订单流断线期间遗漏的成交（先于本地统计，使本次对账包含补录结果）
+	if r.tradeHistory != nil {
+		r.backfillTrades(context.Background(), symbol)
+	}
+
 	// 1. 查询交易所持仓信息（使用通用接口）
 	positionsRaw, err := r.exchange.GetPositions(context.Background(), symbol)
 	if err != nil {
@@ -189,6 +231,20 @@ func (r *Reconciler) Reconcile() error {
 
 	// 5. 输出对账统计（从交易所接口获取基础币种，支持U本位和币本位合约）
 	baseCurrency := r.exchange.GetBaseAsset()
+
+	// 现货模式：槽位库存与钱包中的基础资产余额对比
+	// 钱包余额可能包含不参与网格的币，只有少于槽位库存时才告警
+	if r.cfg.Trading.MarketType == "spot" {
+		walletQty := sumPositionSize(positionsRaw)
+		if walletQty < localTotal-0.000001 {
+			logger.Warn("⚠️ [现货对账] 钱包余额 %.6f %s 少于槽位库存 %.6f，差额 %.6f（可能被手动卖出或转出）",
+				walletQty, baseCurrency, localTotal, localTotal-walletQty)
+		} else if walletQty > localTotal+0.000001 {
+			logger.Info("ℹ️ [现货对账] 钱包余额 %.6f %s 多于槽位库存 %.6f，多出的 %.6f 不参与网格",
+				walletQty, baseCurrency, localTotal, walletQty-localTotal)
+		}
+	}
+
 	logger.Info("✅ [对账完成] 本地持仓: %.4f %s, 挂单卖单: %d 个 (%.4f), 挂单买单: %d 个",
 		localTotal, baseCurrency, activeSellOrders, localPendingSellQty, activeBuyOrders)
 
@@ -196,10 +252,133 @@ func (r *Reconciler) Reconcile() error {
 
 	totalBuyQty := r.pm.GetTotalBuyQty()
 	totalSellQty := r.pm.GetTotalSellQty()
-	priceInterval := r.pm.GetPriceInterval()
-	estimatedProfit := totalSellQty * priceInterval
-	logger.Info("📊 [统计] 对账次数: %d, 累计买入: %.2f, 累计卖出: %.2f, 预计盈利: %.2f U",
-		r.pm.GetReconcileCount(), totalBuyQty, totalSellQty, estimatedProfit)
+	estimatedProfit, profitUnit := r.pm.EstimatedProfit(r.pm.GetPriceInterval())
+	profitDecimals := 2
+	if r.cfg.Trading.MarketType == "inverse" {
+		profitDecimals = 8 // 币本位盈利以基础币种计
+	}
+	logger.Info("📊 [统计] 对账次数: %d, 累计买入: %.2f, 累计卖出: %.2f, 预计盈利: %.*f %s",
+		r.pm.GetReconcileCount(), totalBuyQty, totalSellQty, profitDecimals, estimatedProfit, profitUnit)
 	logger.Debugln("🔍 ===== 对账完成 =====")
 	return nil
 }
+
+// backfillTrades 查询上次补录之后的成交记录，将订单流未处理的成交按订单分组补录
+// 只补录发生在 tradeBackfillSettle 之前的成交，避免与尚在途中的推送重复处理；订单查询失败时下次对账重试
+func (r *Reconciler) backfillTrades(ctx context.Context, symbol string) {
+	settled := time.Now().Add(-tradeBackfillSettle)
+	since := r.tradeCursor.Add(-tradeBackfillOverlap)
+	trades, cursor, err := r.fetchTrades(ctx, symbol, since, settled)
+	if err != nil {
+		logger.Warn("⚠️ [成交补录] 查询成交记录失败: %v", err)
+		return
+	}
+
+	// 按订单分组（保持成交时间顺序），跳过已处理和仍在等待推送的成交
+	var orderIDs []int64
+	missed := make(map[int64][]*exchange.Trade)
+	for _, trade := range trades {
+		if trade.Time > settled.UnixMilli() || r.tradeSeen(trade.TradeID) {
+			continue
+		}
+		if _, done := r.backfilledTrades[trade.TradeID]; done {
+			continue
+		}
+		if _, ok := missed[trade.OrderID]; !ok {
+			orderIDs = append(orderIDs, trade.OrderID)
+		}
+		missed[trade.OrderID] = append(missed[trade.OrderID], trade)
+	}
+
+	complete := true
+	for _, orderID := range orderIDs {
+		order, err := r.tradeHistory.GetOrder(ctx, symbol, orderID)
+		if err != nil {
+			logger.Warn("⚠️ [成交补录] 查询订单 %d 失败，下次对账重试: %v", orderID, err)
+			complete = false
+			continue
+		}
+
+		orderTrades := missed[orderID]
+		logger.Warn("⚠️ [成交补录] 订单 %d 有 %d 笔成交未收到推送，按成交记录补录 (状态: %s, 已成交: %.4f)",
+			orderID, len(orderTrades), order.Status, order.ExecutedQty)
+		r.applyTrades(orderTrades, order)
+		for _, trade := range orderTrades {
+			r.backfilledTrades[trade.TradeID] = trade.Time
+		}
+	}
+
+	if complete {
+		r.tradeCursor = cursor
+	}
+	// 清理已移出查询区间的成交ID
+	for tradeID, t := range r.backfilledTrades {
+		if t < since.UnixMilli() {
+			delete(r.backfilledTrades, tradeID)
+		}
+	}
+}
+
+// fetchTrades 从 since 开始按页拉取成交记录，直到返回不满一页为止
+// 返回的 cursor 为本次已完整覆盖到的时间：全部拉完时为 settled，
+// 达到翻页上限时为最后一条成交的时间，剩余部分下次对账继续
+func (r *Reconciler) fetchTrades(ctx context.Context, symbol string, since, settled time.Time) ([]*exchange.Trade, time.Time, error) {
+	var all []*exchange.Trade
+	fetched := make(map[string]bool)
+	from := since
+	for page := 0; page < tradeBackfillMaxPages; page++ {
+		trades, err := r.tradeHistory.GetMyTrades(ctx, symbol, from, tradeBackfillPageSize)
+		if err != nil {
+			return nil, since, err
+		}
+		for _, trade := range trades {
+			if !fetched[trade.TradeID] {
+				fetched[trade.TradeID] = true
+				all = append(all, trade)
+			}
+		}
+		if len(trades) < tradeBackfillPageSize {
+			return all, settled, nil
+		}
+
+		// 满页：从最后一条成交的时间继续翻页（同一毫秒的成交会重复返回，由成交ID去重）
+		last := time.UnixMilli(trades[len(trades)-1].Time)
+		if !last.After(from) {
+			// 同一毫秒内的成交超过一页，无法继续翻页
+			logger.Warn("⚠️ [成交补录] %s 同一时刻成交超过 %d 笔，部分成交可能无法补录",
+				last.Format("15:04:05.000"), tradeBackfillPageSize)
+			last = last.Add(time.Millisecond)
+		}
+		if last.After(settled) {
+			return all, settled, nil
+		}
+		from = last
+	}
+	return all, from, nil
+}
+
+// sumPositionSize 使用反射汇总持仓切片中的 Size 字段（现货模式下为基础资产余额）
+func sumPositionSize(positionsRaw interface{}) float64 {
+	v := reflect.ValueOf(positionsRaw)
+	if v.Kind() != reflect.Slice {
+		return 0
+	}
+
+	var total float64
+	for i := 0; i < v.Len(); i++ {
+		elem := v.Index(i)
+		for elem.Kind() == reflect.Ptr || elem.Kind() == reflect.Interface {
+			if elem.IsNil() {
+				break
+			}
+			elem = elem.Elem()
+		}
+		if elem.Kind() != reflect.Struct {
+			continue
+		}
+		if field := elem.FieldByName("Size"); field.IsValid() && field.CanFloat() {
+			total += field.Float()
+		}
+	}
+	return total
+}
@@ -3,6 +3,7 @@ package safety
 import (
 	"context"
 	"fmt"
+	"math"
 	"opensqt/exchange"
 	"opensqt/logger"
 )
@@ -25,7 +26,12 @@ func CheckAccountSafety(ex exchange.IExchange, symbol string, currentPrice, orde
 	logger.Info("🔒 ===== 开始持仓安全性检查 =====")
 
 	// 从交易所接口获取计价币种（支持U本位和币本位合约）
+	// 币本位合约的保证金与盈亏以基础币种计，余额与利润按基础币种显示
 	quoteCurrency := ex.GetQuoteAsset()
+	inverse := ex.Capabilities().Inverse
+	if inverse {
+		quoteCurrency = ex.GetBaseAsset()
+	}
 
 	// 1. 获取账户信息
 	ctx := context.Background()
@@ -38,46 +44,43 @@ func CheckAccountSafety(ex exchange.IExchange, symbol string, currentPrice, orde
 	var leverage int = 1 // 默认1倍杠杆
 	var positionAmt float64 = 0
 
-	// 尝试获取持仓信息
-	positions, err := ex.GetPositions(ctx, symbol)
-	if err == nil && positions != nil {
-		for _, p := range positions {
-			if p.Symbol == symbol {
-				positionAmt = p.Size
-				if p.Leverage > 0 {
-					leverage = p.Leverage
+	// 现货没有杠杆：按计价资产可用余额全额买入计算，已持有的基础资产不跳过检查
+	if ex.Capabilities().Spot {
+		logger.Info("ℹ️ 现货模式：按 %s 可用余额检查（无杠杆）", quoteCurrency)
+	} else {
+		// 尝试获取持仓信息
+		positions, err := ex.GetPositions(ctx, symbol)
+		if err == nil && positions != nil {
+			for _, p := range positions {
+				if p.Symbol == symbol {
+					positionAmt = p.Size
+					if p.Leverage > 0 {
+						leverage = p.Leverage
+					}
+					break
 				}
-				break
 			}
 		}
-	}
 
-	// 如果持仓中没有找到杠杆倍数，尝试从账户信息中获取
-	if leverage == 1 && account.AccountLeverage > 0 {
-		leverage = account.AccountLeverage
-		logger.Info("ℹ️ 从账户信息中获取杠杆倍数: %dx", leverage)
-	}
+		// 如果持仓中没有找到杠杆倍数，尝试从账户信息中获取
+		if leverage == 1 && account.AccountLeverage > 0 {
+			leverage = account.AccountLeverage
+			logger.Info("ℹ️ 从账户信息中获取杠杆倍数: %dx", leverage)
+		}
 
-	// 🔥 如果当前账户有持仓，跳过安全检查（认为用户知道风险）
-	if positionAmt != 0 {
-		logger.Info("⚠️ 检测到当前持仓: %.4f，跳过安全性检查", positionAmt)
-		logger.Info("🔒 ===== 持仓安全性检查完成（已跳过） =====")
-		return nil
+		// 🔥 如果当前账户有持仓，跳过安全检查（认为用户知道风险）
+		if positionAmt != 0 {
+			logger.Info("⚠️ 检测到当前持仓: %.4f，跳过安全性检查", positionAmt)
+			logger.Info("🔒 ===== 持仓安全性检查完成（已跳过） =====")
+			return nil
+		}
 	}
 	accountBalance := account.AvailableBalance
 	if accountBalance <= 0 {
 		return fmt.Errorf("账户余额不足，当前余额: %.2f %s", accountBalance, quoteCurrency)
 	}
 	logger.Info("💰 账户余额: %.2f %s (交易对: %s)", accountBalance, quoteCurrency, symbol)
-	// 如果是币安交易所，尝试获取更准确的杠杆信息
 	exchangeName := ex.GetName()
-	if leverage == 1 && exchangeName == "Binance" {
-		// 尝试通过币安特定的方法获取杠杆（如果获取失败也没关系，使用默认值）
-		if binanceLeverage := tryGetBinanceLeverage(ex, symbol); binanceLeverage > 0 {
-			leverage = binanceLeverage
-		}
-	}
-
 	logger.Info("📊 交易所: %s, 交易对: %s, 当前杠杆倍数: %dx, 当前持仓: %.4f", exchangeName, symbol, leverage, positionAmt)
 
 	// 3. 强制杠杆倍数检查（硬编码最多10倍）
@@ -85,6 +88,14 @@ func CheckAccountSafety(ex exchange.IExchange, symbol string, currentPrice, orde
 		return fmt.Errorf("您的账户杠杆倍率太高（%dx），风险太大，禁止开仓。最大允许杠杆倍数: %dx", leverage, MaxLeverage)
 	}
 
+	// 如果未设置小数位数，使用默认值2
+	if priceDecimals <= 0 {
+		priceDecimals = 2
+	}
+	if inverse {
+		return checkInverseSafety(ex, symbol, accountBalance, leverage, currentPrice, orderAmount, priceInterval, feeRate, requiredPositions, priceDecimals)
+	}
+
 	// 4. 计算最大可持有仓位
 	// 🔥 固定金额模式：orderAmount 是每笔交易的金额（USDT/USDC）
 	// 公式：最大可持有仓位 = (账户余额 * 杠杆倍数) / 每笔金额
@@ -94,11 +105,6 @@ func CheckAccountSafety(ex exchange.IExchange, symbol string, currentPrice, orde
 	costPerPosition := orderAmount // 每仓成本就是配置的金额
 	maxPositions := maxAvailableMargin / costPerPosition
 
-	// 如果未设置小数位数，使用默认值2
-	if priceDecimals <= 0 {
-		priceDecimals = 2
-	}
-
 	// 根据当前价格计算实际购买数量（用于显示）
 	orderQuantity := orderAmount / currentPrice
 
@@ -176,13 +182,107 @@ func CheckAccountSafety(ex exchange.IExchange, symbol string, currentPrice, orde
 	return nil
 }
 
-// tryGetBinanceLeverage 尝试获取币安的杠杆信息（可选功能，失败不影响主流程）
-func tryGetBinanceLeverage(ex exchange.IExchange, symbol string) int {
-	// 由于币安适配器可能有特定的方法，这里我们通过反射或类型断言来获取
-	// 如果失败，返回0表示无法获取
+// checkInverseSafety 币本位合约的持仓与手续费检查（保证金、利润与手续费均以基础币种计）
+// 每单张数 = 每单面值 / 合约面值，每仓保证金 = 张数 × 面值 / 价格，
+// 每笔利润 = 张数 × 面值 × (1/买入价 - 1/卖出价)
+func checkInverseSafety(ex exchange.IExchange, symbol string, accountBalance float64, leverage int, currentPrice, orderAmount, priceInterval, feeRate float64, requiredPositions, priceDecimals int) error {
+	baseCurrency := ex.GetBaseAsset()
+
+	info, err := ex.GetSymbolInfo(context.Background(), symbol)
+	if err != nil {
+		return fmt.Errorf("获取合约信息失败: %w", err)
+	}
+	if info.ContractValue <= 0 {
+		return fmt.Errorf("%s 不是币本位合约或合约面值未知", symbol)
+	}
+
+	contracts := orderAmount / info.ContractValue
+	if info.StepSize > 0 {
+		contracts = math.Floor(contracts/info.StepSize+1e-9) * info.StepSize
+	}
+	if contracts < 1 || contracts < info.MinQty {
+		return fmt.Errorf("每单面值 %.2f USD 不足 1 张合约（每张面值 %.0f USD），请调大 order_quantity", orderAmount, info.ContractValue)
+	}
+	faceValue := contracts * info.ContractValue
+
+	// 4. 计算最大可持有仓位（按当前价格折算为基础币种）
+	costPerPosition := faceValue / currentPrice
+	maxAvailableMargin := accountBalance * float64(leverage)
+	maxPositions := maxAvailableMargin / costPerPosition
+
+	logger.Info("📈 当前币价: %.*f, 每笔: %.0f 张 × %.0f USD, 每仓价值: %.8f %s",
+		priceDecimals, currentPrice, contracts, info.ContractValue, costPerPosition, baseCurrency)
+	logger.Info("💵 最大可用保证金: %.8f %s (余额 %.8f × 杠杆 %dx)", maxAvailableMargin, baseCurrency, accountBalance, leverage)
+	logger.Info("🎯 最大可持有仓位: %.0f 仓", maxPositions)
+	logger.Info("✅ 要求最少持有: %d 仓", requiredPositions)
+
+	// 5. 验证是否满足要求
+	if maxPositions < float64(requiredPositions) {
+		return fmt.Errorf("持仓安全检查失败：您的账户余额不足，请补充足够保证金或调整配置参数，最少足够向下购买持有 %d 仓。当前最大可持有: %.0f 仓", requiredPositions, maxPositions)
+	}
+	logger.Info("✅ 持仓安全性检查通过：可以安全持有至少 %d 仓", requiredPositions)
+
+	// 6. 手续费率安全检查（币本位手续费按成交价值的基础币种计收）
+	buyPrice := currentPrice
+	sellPrice := currentPrice + priceInterval
+	buyValue := faceValue / buyPrice
+	sellValue := faceValue / sellPrice
+	profitPerTrade := buyValue - sellValue
+	totalFee := (buyValue + sellValue) * feeRate
+	netProfit := profitPerTrade - totalFee
+
+	logger.Info("💰 每笔交易分析 (币本位，按张数):")
+	logger.Info("   买入价: %.*f, 卖出价: %.*f, 价格差: %.*f", priceDecimals, buyPrice, priceDecimals, sellPrice, priceDecimals, priceInterval)
+	logger.Info("   每笔利润: %.8f %s, 总手续费: %.8f %s (费率: %.4f%%)", profitPerTrade, baseCurrency, totalFee, baseCurrency, feeRate*2*100)
+	logger.Info("   净利润: %.8f %s", netProfit, baseCurrency)
+
+	if netProfit <= 0 {
+		logger.Error("❌ 错误：每笔净利润为负或为零 (%.8f %s)，无法盈利！", netProfit, baseCurrency)
+		logger.Error("   建议：增加价格间隔或降低手续费率")
+		return fmt.Errorf("每笔净利润为负或为零 (%.8f %s)，系统拒绝启动", netProfit, baseCurrency)
+	}
+
+	logger.Info("✅ 手续费率安全检查通过：每笔净利润 %.8f %s", netProfit, baseCurrency)
+	logger.Info("🔒 ===== 持仓安全性检查完成 =====")
+	return nil
+}
+
+// ConfigureAccount 按配置设置交易所账户（持仓模式 -> 保证金模式 -> 杠杆倍数）
+// 空值/0 表示沿用交易所当前设置；必须在持仓安全性检查之前执行，使检查读取到的是设置后的杠杆
+// 交易所不支持通过 API 设置时（Capabilities().AccountSettings 为 false）只做杠杆上限检查，沿用交易所当前设置
+func ConfigureAccount(ex exchange.IExchange, symbol string, leverage int, marginType, positionMode string) error {
+	ctx := context.Background()
 
-	// 这里可以根据实际情况实现，暂时返回0让其使用默认值
-	// 后续可以扩展：通过反射或扩展接口来获取特定交易所的杠杆信息
+	if leverage > MaxLeverage {
+		return fmt.Errorf("配置的杠杆倍数（%dx）超过最大允许杠杆倍数 %dx", leverage, MaxLeverage)
+	}
+	if !ex.Capabilities().AccountSettings {
+		if leverage > 0 || marginType != "" || positionMode != "" {
+			logger.Warn("⚠️ %s 不支持通过 API 设置杠杆/保证金模式/持仓模式，沿用交易所当前设置，请在交易所手动配置", ex.GetName())
+		}
+		return nil
+	}
 
-	return 0 // 表示无法获取，使用默认值
+	if positionMode != "" {
+		if err := ex.SetPositionMode(ctx, exchange.PositionMode(positionMode)); err != nil {
+			return fmt.Errorf("设置持仓模式 %s 失败（有持仓或挂单时交易所可能拒绝修改）: %w", positionMode, err)
+		}
+		logger.Info("✅ 持仓模式已设置为: %s", positionMode)
+	}
+
+	if marginType != "" {
+		if err := ex.SetMarginType(ctx, symbol, exchange.MarginType(marginType)); err != nil {
+			return fmt.Errorf("设置保证金模式 %s 失败（有持仓或挂单时交易所可能拒绝修改）: %w", marginType, err)
+		}
+		logger.Info("✅ %s 保证金模式已设置为: %s", symbol, marginType)
+	}
+
+	if leverage > 0 {
+		if err := ex.SetLeverage(ctx, symbol, leverage); err != nil {
+			return fmt.Errorf("设置杠杆倍数 %dx 失败: %w", leverage, err)
+		}
+		logger.Info("✅ %s 杠杆倍数已设置为: %dx", symbol, leverage)
+	}
+
+	return nil
 }
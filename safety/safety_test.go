@@ -0,0 +1,211 @@
+package safety
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"opensqt/exchange"
+)
+
+// fakeExchange 记录账户设置调用的交易所桩，未实现的方法由内嵌接口兜底（调用即 panic）
+type fakeExchange struct {
+	exchange.IExchange
+	caps   exchange.Capabilities
+	calls  []string
+	failOn string // 该设置调用返回错误
+	info   *exchange.SymbolInfo
+}
+
+var errRejected = errors.New("rejected")
+
+func (f *fakeExchange) GetName() string                     { return "Fake" }
+func (f *fakeExchange) GetBaseAsset() string                { return "BTC" }
+func (f *fakeExchange) Capabilities() exchange.Capabilities { return f.caps }
+
+func (f *fakeExchange) GetSymbolInfo(ctx context.Context, symbol string) (*exchange.SymbolInfo, error) {
+	return f.info, nil
+}
+
+func (f *fakeExchange) record(call string) error {
+	f.calls = append(f.calls, call)
+	if strings.HasPrefix(call, f.failOn+" ") {
+		return errRejected
+	}
+	return nil
+}
+
+func (f *fakeExchange) SetPositionMode(ctx context.Context, mode exchange.PositionMode) error {
+	return f.record(fmt.Sprintf("SetPositionMode %s", mode))
+}
+
+func (f *fakeExchange) SetMarginType(ctx context.Context, symbol string, marginType exchange.MarginType) error {
+	return f.record(fmt.Sprintf("SetMarginType %s %s", symbol, marginType))
+}
+
+func (f *fakeExchange) SetLeverage(ctx context.Context, symbol string, leverage int) error {
+	return f.record(fmt.Sprintf("SetLeverage %s %d", symbol, leverage))
+}
+
+func TestConfigureAccount(t *testing.T) {
+	tests := []struct {
+		name         string
+		settings     bool
+		leverage     int
+		marginType   string
+		positionMode string
+		failOn       string
+		wantCalls    []string
+		wantErr      string
+	}{
+		{
+			name:         "按持仓模式、保证金模式、杠杆的顺序设置",
+			settings:     true,
+			leverage:     5,
+			marginType:   "isolated",
+			positionMode: "hedge",
+			wantCalls:    []string{"SetPositionMode hedge", "SetMarginType ETHUSDT isolated", "SetLeverage ETHUSDT 5"},
+		},
+		{
+			name:      "空值沿用交易所当前设置",
+			settings:  true,
+			leverage:  3,
+			wantCalls: []string{"SetLeverage ETHUSDT 3"},
+		},
+		{
+			name:     "杠杆超过上限时拒绝且不调用交易所",
+			settings: true,
+			leverage: MaxLeverage + 1,
+			wantErr:  "超过最大允许杠杆倍数",
+		},
+		{
+			name:     "不支持账户设置时杠杆超限同样拒绝",
+			leverage: MaxLeverage + 1,
+			wantErr:  "超过最大允许杠杆倍数",
+		},
+		{
+			name:         "不支持账户设置时告警并跳过",
+			leverage:     5,
+			marginType:   "cross",
+			positionMode: "one_way",
+		},
+		{
+			name:         "交易所拒绝修改保证金模式时停止并返回错误",
+			settings:     true,
+			leverage:     5,
+			marginType:   "isolated",
+			positionMode: "hedge",
+			failOn:       "SetMarginType",
+			wantCalls:    []string{"SetPositionMode hedge", "SetMarginType ETHUSDT isolated"},
+			wantErr:      "设置保证金模式 isolated 失败",
+		},
+		{
+			name:      "交易所拒绝设置杠杆",
+			settings:  true,
+			leverage:  5,
+			failOn:    "SetLeverage",
+			wantCalls: []string{"SetLeverage ETHUSDT 5"},
+			wantErr:   "设置杠杆倍数 5x 失败",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ex := &fakeExchange{caps: exchange.Capabilities{AccountSettings: tt.settings}, failOn: tt.failOn}
+			err := ConfigureAccount(ex, "ETHUSDT", tt.leverage, tt.marginType, tt.positionMode)
+
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("不应返回错误: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("err = %v, want 包含 %q", err, tt.wantErr)
+			}
+			if tt.failOn != "" && !errors.Is(err, errRejected) {
+				t.Errorf("应保留交易所原始错误: %v", err)
+			}
+			if !reflect.DeepEqual(ex.calls, tt.wantCalls) {
+				t.Errorf("调用 = %v, want %v", ex.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+// 币本位：每单 250 USD 按 100 USD 面值取整为 2 张，每仓价值 200/50000 = 0.004 BTC，
+// 每笔利润 = 200 × (1/买入价 - 1/卖出价) BTC，手续费按成交价值的 BTC 计收
+func TestCheckInverseSafety(t *testing.T) {
+	tests := []struct {
+		name          string
+		info          exchange.SymbolInfo
+		balance       float64
+		orderAmount   float64
+		priceInterval float64
+		wantErr       string
+	}{
+		{
+			// 未取整（2.5 张）时每仓 0.005 BTC，0.045 BTC 不足 10 仓
+			name:          "按步长取整后满足持仓与利润要求",
+			info:          exchange.SymbolInfo{ContractValue: 100, StepSize: 1},
+			balance:       0.045,
+			orderAmount:   250,
+			priceInterval: 500,
+		},
+		{
+			// 利润 200 × 5/(50000 × 50005) ≈ 0.0000004 BTC，低于手续费 ≈ 0.0000016 BTC
+			name:          "净利润不为正时拒绝",
+			info:          exchange.SymbolInfo{ContractValue: 100, StepSize: 1},
+			balance:       0.045,
+			orderAmount:   250,
+			priceInterval: 5,
+			wantErr:       "每笔净利润为负或为零",
+		},
+		{
+			name:          "余额不足以持有要求的仓数",
+			info:          exchange.SymbolInfo{ContractValue: 100, StepSize: 1},
+			balance:       0.035,
+			orderAmount:   250,
+			priceInterval: 500,
+			wantErr:       "持仓安全检查失败",
+		},
+		{
+			name:          "每单不足 1 张合约",
+			info:          exchange.SymbolInfo{ContractValue: 100, StepSize: 1},
+			balance:       1,
+			orderAmount:   90,
+			priceInterval: 500,
+			wantErr:       "不足 1 张合约",
+		},
+		{
+			name:          "按步长向下取整后不足 1 张",
+			info:          exchange.SymbolInfo{ContractValue: 100, StepSize: 10},
+			balance:       1,
+			orderAmount:   250,
+			priceInterval: 500,
+			wantErr:       "不足 1 张合约",
+		},
+		{
+			name:          "合约面值未知",
+			info:          exchange.SymbolInfo{StepSize: 1},
+			balance:       1,
+			orderAmount:   250,
+			priceInterval: 500,
+			wantErr:       "不是币本位合约",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ex := &fakeExchange{info: &tt.info}
+			err := checkInverseSafety(ex, "BTCUSD_PERP", tt.balance, 1, 50000, tt.orderAmount, tt.priceInterval, 0.0002, 10, 1)
+
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("不应返回错误: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("err = %v, want 包含 %q", err, tt.wantErr)
+			}
+		})
+	}
+}
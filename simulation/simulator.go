@@ -40,6 +40,25 @@ func (m *MockExchange) GetName() string {
 	return "mock_exchange"
 }
 
+// GetClockSkew 模拟交易所使用本地时间，无偏差
+func (m *MockExchange) GetClockSkew() time.Duration {
+	return 0
+}
+
+// SyncServerTime 模拟交易所无需对时
+func (m *MockExchange) SyncServerTime(ctx context.Context) error {
+	return nil
+}
+
+func (m *MockExchange) Capabilities() exchange.Capabilities {
+	return exchange.Capabilities{
+		AmendOrder:          true,
+		AccountSettings:     true,
+		PostOnlyStyle:       exchange.PostOnlyTimeInForce,
+		MaxClientOrderIDLen: 36,
+	}
+}
+
 func (m *MockExchange) GetPositions(ctx context.Context, symbol string) ([]*exchange.Position, error) {
 	// 模拟持仓数据
 	return []*exchange.Position{}, nil
@@ -50,6 +69,10 @@ func (m *MockExchange) GetOpenOrders(ctx context.Context, symbol string) ([]*exc
 	return []*exchange.Order{}, nil
 }
 
+func (m *MockExchange) GetMyTrades(ctx context.Context, symbol string, since time.Time, limit int) ([]*exchange.Trade, error) {
+	return nil, fmt.Errorf("模拟交易所不支持查询成交记录")
+}
+
 func (m *MockExchange) GetOrder(ctx context.Context, symbol string, orderID int64) (*exchange.Order, error) {
 	// 模拟订单详情
 	return &exchange.Order{
@@ -76,6 +99,10 @@ func (m *MockExchange) GetAvailableBalance(ctx context.Context) (float64, error)
 	return 10000, nil // 模拟10000 USDT余额
 }
 
+func (m *MockExchange) GetHistoricalKlinesRange(ctx context.Context, symbol, interval string, start, end time.Time) ([]*exchange.Candle, error) {
+	return nil, fmt.Errorf("模拟交易所不支持按时间区间查询K线")
+}
+
 func (m *MockExchange) GetHistoricalKlines(ctx context.Context, symbol, interval string, limit int) ([]*exchange.Candle, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -158,6 +185,19 @@ func (m *MockExchange) ForceReconnectKlineStream() error {
 	return nil
 }
 
+func (m *MockExchange) GetSymbolInfo(ctx context.Context, symbol string) (*exchange.SymbolInfo, error) {
+	return &exchange.SymbolInfo{
+		Symbol:             symbol,
+		QuoteAsset:         "USDC",
+		TickSize:           0.000001,
+		StepSize:           0.0001,
+		MinQty:             0.0001,
+		ContractMultiplier: 1,
+		PriceDecimals:      6,
+		QuantityDecimals:   4,
+	}, nil
+}
+
 func (m *MockExchange) GetPriceDecimals() int {
 	return 6 // 6 decimal places for DOGE
 }
@@ -176,6 +216,35 @@ func (m *MockExchange) GetLatestPrice(ctx context.Context, symbol string) (float
 	return m.currentPrice, nil
 }
 
+func (m *MockExchange) GetMarkPrice(ctx context.Context, symbol string) (float64, error) {
+	return m.GetLatestPrice(ctx, symbol)
+}
+
+func (m *MockExchange) StartMarkPriceStream(ctx context.Context, symbol string, callback exchange.MarkPriceCallback) error {
+	return fmt.Errorf("模拟交易所不支持标记价格推送")
+}
+
+func (m *MockExchange) StartBookTickerStream(ctx context.Context, symbol string, callback exchange.BookTickerCallback) error {
+	return fmt.Errorf("模拟交易所不支持盘口最优价推送")
+}
+
+func (m *MockExchange) GetOrderBook(ctx context.Context, symbol string, depth int) (*exchange.OrderBook, error) {
+	return nil, fmt.Errorf("模拟交易所不支持查询订单簿")
+}
+
+func (m *MockExchange) StartDepthStream(ctx context.Context, symbol string, callback exchange.OrderBookCallback) error {
+	return fmt.Errorf("模拟交易所不支持深度推送")
+}
+
+func (m *MockExchange) GetFundingRate(ctx context.Context, symbol string) (*exchange.FundingRate, error) {
+	price, _ := m.GetLatestPrice(ctx, symbol)
+	return &exchange.FundingRate{Symbol: symbol, MarkPrice: price, IndexPrice: price, Time: time.Now().UnixMilli()}, nil
+}
+
+func (m *MockExchange) GetFundingPayments(ctx context.Context, symbol string, since time.Time) ([]*exchange.FundingPayment, error) {
+	return nil, nil
+}
+
 func (m *MockExchange) StartPriceStream(ctx context.Context, symbol string, callback func(price float64)) error {
 	// Start a goroutine to periodically push price updates
 	go func() {
@@ -201,10 +270,18 @@ func (m *MockExchange) StartOrderStream(ctx context.Context, callback func(inter
 	return nil
 }
 
+func (m *MockExchange) SubscribeOrderStream(ctx context.Context, symbol string, callback func(interface{})) error {
+	return nil
+}
+
 func (m *MockExchange) StopOrderStream() error {
 	return nil
 }
 
+func (m *MockExchange) StartAccountStream(ctx context.Context, callback exchange.AccountUpdateCallback) error {
+	return fmt.Errorf("模拟交易所不支持账户推送")
+}
+
 func (m *MockExchange) StopKlineStream() error {
 	return nil
 }
@@ -250,6 +327,22 @@ func (m *MockExchange) BatchCancelOrders(ctx context.Context, symbol string, ord
 	return nil
 }
 
+func (m *MockExchange) AmendOrder(ctx context.Context, req *exchange.OrderRequest, orderID int64, newPrice, newQty float64) (*exchange.Order, error) {
+	if newQty <= 0 {
+		newQty = 100
+	}
+	return &exchange.Order{
+		OrderID:   orderID,
+		Symbol:    req.Symbol,
+		Side:      req.Side,
+		Type:      exchange.OrderTypeLimit,
+		Price:     newPrice,
+		Quantity:  newQty,
+		Status:    exchange.OrderStatusNew,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
 func (m *MockExchange) GetAccount(ctx context.Context) (*exchange.Account, error) {
 	return &exchange.Account{
 		TotalWalletBalance: 10000,
@@ -263,6 +356,18 @@ func (m *MockExchange) GetBalance(ctx context.Context, asset string) (float64, e
 	return 10000, nil
 }
 
+func (m *MockExchange) SetLeverage(ctx context.Context, symbol string, leverage int) error {
+	return nil
+}
+
+func (m *MockExchange) SetMarginType(ctx context.Context, symbol string, marginType exchange.MarginType) error {
+	return nil
+}
+
+func (m *MockExchange) SetPositionMode(ctx context.Context, mode exchange.PositionMode) error {
+	return nil
+}
+
 // Simulator 仿真器
 type Simulator struct {
 	config     *config.Config
@@ -372,6 +477,10 @@ func (a *positionExchangeAdapter) GetAvailableBalance(ctx context.Context) (floa
 	return a.exchange.GetAvailableBalance(ctx)
 }
 
+func (a *positionExchangeAdapter) GetFreeBaseBalance(ctx context.Context) (float64, error) {
+	return a.exchange.GetBalance(ctx, a.exchange.GetBaseAsset())
+}
+
 func (a *positionExchangeAdapter) GetPositions(ctx context.Context, symbol string) (interface{}, error) {
 	return a.exchange.GetPositions(ctx, symbol)
 }
@@ -384,6 +493,10 @@ func (a *positionExchangeAdapter) GetBaseAsset() string {
 	return "DOGE"
 }
 
+func (a *positionExchangeAdapter) GetQuoteAsset() string {
+	return "USDT"
+}
+
 func (a *positionExchangeAdapter) GetName() string {
 	return "mock"
 }
@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"crypto/md5"
+	"encoding/hex"
 	"fmt"
 	"math"
 	"strconv"
@@ -112,6 +114,8 @@ func ParseOrderID(clientOrderID string, priceDecimals int) (float64, string, int
 // 交易所限制:
 //   - Binance: 36字符限制，返佣前缀 "x-zdfVM8vY" (10字符)
 //   - Gate.io: 30字符限制，返佣前缀 "t-" (2字符)
+//   - OKX: 32字符限制，clOrdId 只允许字母和数字，去掉 "_" 分隔符
+//   - Hyperliquid: cloid 必须是 16 字节十六进制（0x + 32位），方向编码为 b(买)/a(卖)
 func AddBrokerPrefix(exchange, clientOrderID string) string {
 	switch exchange {
 	case "binance":
@@ -148,6 +152,27 @@ func AddBrokerPrefix(exchange, clientOrderID string) string {
 		}
 		return result
 
+	case "okx":
+		// OKX 不允许下划线：65000_B_1702468800001 -> 65000B1702468800001
+		// 方向字符 B/S 本身就是分隔符，可以无歧义地还原
+		result := strings.ReplaceAll(clientOrderID, "_", "")
+		if len(result) > 32 {
+			result = result[:32]
+		}
+		return result
+
+	case "hyperliquid":
+		// 65000_B_1702468800001 -> 0x000000000000065000b1702468800001
+		// 数字本身是合法的十六进制字符，方向用 b/a 表示（与 Hyperliquid 的 B/A 方向一致）
+		compact := strings.ReplaceAll(clientOrderID, "_", "")
+		compact = strings.NewReplacer("B", "b", "S", "a").Replace(compact)
+		if len(compact) > 32 || strings.Trim(compact, "0123456789ab") != "" {
+			// 非本程序格式的ID无法无损编码，使用摘要保证唯一性
+			sum := md5.Sum([]byte(clientOrderID))
+			return "0x" + hex.EncodeToString(sum[:])
+		}
+		return "0x" + strings.Repeat("0", 32-len(compact)) + compact
+
 	default:
 		return clientOrderID
 	}
@@ -169,6 +194,34 @@ func RemoveBrokerPrefix(exchange, clientOrderID string) string {
 		}
 		return clientOrderID
 
+	case "okx":
+		// 已经是标准格式（或非本程序订单）时原样返回
+		if strings.Contains(clientOrderID, "_") {
+			return clientOrderID
+		}
+		// 65000B1702468800001 -> 65000_B_1702468800001
+		idx := strings.IndexAny(clientOrderID, "BS")
+		if idx <= 0 || idx == len(clientOrderID)-1 {
+			return clientOrderID
+		}
+		return clientOrderID[:idx] + "_" + clientOrderID[idx:idx+1] + "_" + clientOrderID[idx+1:]
+
+	case "hyperliquid":
+		// 0x000000000000065000b1702468800001 -> 65000_B_1702468800001
+		if !strings.HasPrefix(clientOrderID, "0x") || len(clientOrderID) != 34 {
+			return clientOrderID
+		}
+		compact := strings.TrimLeft(clientOrderID[2:], "0")
+		idx := strings.IndexAny(compact, "ba")
+		if idx <= 0 || idx == len(compact)-1 || strings.Trim(compact[idx+1:], "0123456789") != "" {
+			return clientOrderID
+		}
+		side := "B"
+		if compact[idx] == 'a' {
+			side = "S"
+		}
+		return compact[:idx] + "_" + side + "_" + compact[idx+1:]
+
 	default:
 		return clientOrderID
 	}